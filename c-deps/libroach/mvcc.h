@@ -59,6 +59,7 @@ template <bool reverse> class mvccScanner {
         txn_epoch_(txn.epoch),
         txn_sequence_(txn.sequence),
         txn_max_timestamp_(txn.max_timestamp),
+        txn_ignored_seqnums_(txn.ignored_seqnums),
         inconsistent_(inconsistent),
         tombstones_(tombstones),
         ignore_sequence_(ignore_sequence),
@@ -178,6 +179,11 @@ template <bool reverse> class mvccScanner {
            const cockroach::storage::engine::enginepb::MVCCMetadata_SequencedIntent& b) -> bool {
           return a.sequence() < b.sequence();
         });
+    // Skip over the intents written at sequence numbers which have been
+    // rolled back.
+    while (up != meta_.intent_history().begin() && seqNumIsIgnored((up - 1)->sequence())) {
+      --up;
+    }
     if (up == meta_.intent_history().begin()) {
       // It is possible that no intent exists such that the sequence is less
       // than the read sequence. In this case, we cannot read a value from the
@@ -192,6 +198,20 @@ template <bool reverse> class mvccScanner {
     return true;
   }
 
+  // seqNumIsIgnored returns true iff the sequence number is contained in
+  // one of the ignored ranges of the transaction.
+  bool seqNumIsIgnored(int32_t sequence) const {
+    // The ranges are sorted, so look for the last one that starts at or
+    // before the sequence number.
+    for (int i = txn_ignored_seqnums_.len - 1; i >= 0; i--) {
+      if (sequence < txn_ignored_seqnums_.ranges[i].start_seqnum) {
+        continue;
+      }
+      return sequence <= txn_ignored_seqnums_.ranges[i].end_seqnum;
+    }
+    return false;
+  }
+
   bool uncertaintyError(DBTimestamp ts) {
     results_.uncertainty_timestamp = ts;
     kvs_->Clear();
@@ -299,7 +319,8 @@ template <bool reverse> class mvccScanner {
     }
 
     if (txn_epoch_ == meta_.txn().epoch()) {
-      if ((ignore_sequence_) || (txn_sequence_ >= meta_.txn().sequence())) {
+      if ((ignore_sequence_) || (txn_sequence_ >= meta_.txn().sequence() &&
+                                 !seqNumIsIgnored(meta_.txn().sequence()))) {
         // 8. We're reading our own txn's intent at an equal or higher sequence.
         // Note that we read at the intent timestamp, not at our read timestamp
         // as the intent timestamp may have been pushed forward by another
@@ -307,12 +328,12 @@ template <bool reverse> class mvccScanner {
         return seekVersion(meta_timestamp, false);
       } else {
         // 9. We're reading our own txn's intent at a lower sequence than is
-        // currently present in the intent. This means the intent we're seeing
-        // was written at a higher sequence than the read and that there may or
-        // may not be earlier versions of the intent (with lower sequence
-        // numbers) that we should read. If there exists a value in the intent
-        // history that has a sequence number equal to or less than the read
-        // sequence, read that value.
+        // currently present in the intent, or the write of the intent has
+        // been rolled back. This means that there may or may not be earlier
+        // versions of the intent (with lower sequence numbers) that we should
+        // read. If there exists a value in the intent history that has a
+        // sequence number equal to or less than the read sequence and that
+        // was not rolled back, read that value.
         const bool found = getFromIntentHistory();
         if (found) {
           return advanceKey();
@@ -652,6 +673,7 @@ template <bool reverse> class mvccScanner {
   const uint32_t txn_epoch_;
   const int32_t txn_sequence_;
   const DBTimestamp txn_max_timestamp_;
+  const DBIgnoredSeqNums txn_ignored_seqnums_;
   const bool inconsistent_;
   const bool tombstones_;
   const bool ignore_sequence_;
@@ -21,6 +21,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/cli/cliflags"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/humanizeutil"
 	humanize "github.com/dustin/go-humanize"
 	"github.com/pkg/errors"
@@ -160,6 +161,11 @@ type StoreSpec struct {
 	Size       SizeSpec
 	InMemory   bool
 	Attributes roachpb.Attributes
+	// Engine is the implementation of the storage engine used by the store.
+	// It is only meaningful if EngineSpecified is set; stores that don't
+	// specify an engine use the node's --storage-engine.
+	Engine          enginepb.EngineType
+	EngineSpecified bool
 	// UseFileRegistry is true if the "file registry" store version is desired.
 	// This is set by CCL code when encryption-at-rest is in use.
 	UseFileRegistry bool
@@ -196,6 +202,9 @@ func (ss StoreSpec) String() string {
 		}
 		fmt.Fprintf(&buffer, ",")
 	}
+	if ss.EngineSpecified {
+		fmt.Fprintf(&buffer, "engine=%s,", &ss.Engine)
+	}
 	// Trim the extra comma from the end if it exists.
 	if l := buffer.Len(); l > 0 {
 		buffer.Truncate(l - 1)
@@ -219,7 +228,7 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 
 // NewStoreSpec parses the string passed into a --store flag and returns a
 // StoreSpec if it is correctly parsed.
-// There are four possible fields that can be passed in, comma separated:
+// There are five possible fields that can be passed in, comma separated:
 // - path=xxx The directory in which to the rocks db instance should be
 //   located, required unless using a in memory storage.
 // - type=mem This specifies that the store is an in memory storage instead of
@@ -233,6 +242,8 @@ var fractionRegex = regexp.MustCompile(`^([-]?([0-9]+\.[0-9]*|[0-9]*\.[0-9]+|[0-
 //   - 20%             -> 20% of the available space
 //   - 0.2             -> 20% of the available space
 // - attrs=xxx:yyy:zzz A colon separated list of optional attributes.
+// - engine=xxx The storage engine of the store, rocksdb or golsm. Defaults
+//   to the engine given by --storage-engine.
 // Note that commas are forbidden within any field name or value.
 func NewStoreSpec(value string) (StoreSpec, error) {
 	const pathField = "path"
@@ -306,6 +317,11 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 			} else {
 				return StoreSpec{}, fmt.Errorf("%s is not a valid store type", value)
 			}
+		case "engine":
+			if err := ss.Engine.Set(value); err != nil {
+				return StoreSpec{}, err
+			}
+			ss.EngineSpecified = true
 		case "rocksdb":
 			ss.RocksDBOptions = value
 		default:
@@ -323,6 +339,9 @@ func NewStoreSpec(value string) (StoreSpec, error) {
 	} else if ss.Path == "" {
 		return StoreSpec{}, fmt.Errorf("no path specified")
 	}
+	if ss.Engine == enginepb.EngineTypeGoLSM && ss.RocksDBOptions != "" {
+		return StoreSpec{}, fmt.Errorf("rocksdb options specified for a golsm store")
+	}
 	return ss, nil
 }
 
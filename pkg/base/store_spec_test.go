@@ -16,6 +16,7 @@ import (
 	"testing"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 )
 
@@ -115,6 +116,22 @@ func TestNewStoreSpec(t *testing.T) {
 		// RocksDB
 		{"path=/,rocksdb=key1=val1;key2=val2", "", StoreSpec{Path: "/", RocksDBOptions: "key1=val1;key2=val2"}},
 
+		// engine
+		{"path=/mnt/hda1,engine=rocksdb", "", StoreSpec{Path: "/mnt/hda1", EngineSpecified: true}},
+		{"path=/mnt/hda1,engine=golsm", "", StoreSpec{
+			Path:            "/mnt/hda1",
+			Engine:          enginepb.EngineTypeGoLSM,
+			EngineSpecified: true,
+		}},
+		{"type=mem,size=20GiB,engine=golsm", "", StoreSpec{
+			Size:            SizeSpec{InBytes: 21474836480},
+			InMemory:        true,
+			Engine:          enginepb.EngineTypeGoLSM,
+			EngineSpecified: true,
+		}},
+		{"path=/mnt/hda1,engine=leveldb", "invalid storage engine: leveldb (possible values: rocksdb, golsm)", StoreSpec{}},
+		{"path=/mnt/hda1,engine=golsm,rocksdb=key1=val1", "rocksdb options specified for a golsm store", StoreSpec{}},
+
 		// all together
 		{"path=/mnt/hda1,attrs=hdd:ssd,size=20GiB", "", StoreSpec{
 			Path:       "/mnt/hda1",
@@ -13,6 +13,7 @@ import (
 	"context"
 	"io/ioutil"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/build"
@@ -53,16 +54,21 @@ const (
 	// BackupDescriptorCheckpointName is the file name used to store the
 	// serialized BackupDescriptor proto while the backup is in progress.
 	BackupDescriptorCheckpointName = "BACKUP-CHECKPOINT"
+	// BackupEncryptionInfoName is the file name used to store the serialized
+	// EncryptionInfo proto of an encrypted backup.
+	BackupEncryptionInfoName = "ENCRYPTION-INFO"
 	// BackupFormatDescriptorTrackingVersion added tracking of complete DBs.
 	BackupFormatDescriptorTrackingVersion uint32 = 1
 )
 
 const (
 	backupOptRevisionHistory = "revision_history"
+	backupOptEncPassphrase   = "encryption_passphrase"
 )
 
 var backupOptionExpectValues = map[string]sql.KVStringOptValidate{
 	backupOptRevisionHistory: sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:   sql.KVStringOptRequireValue,
 }
 
 // BackupCheckpointInterval is the interval at which backup progress is saved
@@ -71,16 +77,20 @@ var BackupCheckpointInterval = time.Minute
 
 // ReadBackupDescriptorFromURI creates an export store from the given URI, then
 // reads and unmarshals a BackupDescriptor at the standard location in the
-// export storage.
+// export storage. The encryption options must be set if the backup is
+// encrypted.
 func ReadBackupDescriptorFromURI(
-	ctx context.Context, uri string, settings *cluster.Settings,
+	ctx context.Context,
+	uri string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
 	defer exportStore.Close()
-	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName)
+	backupDesc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName, encryption)
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
@@ -91,9 +101,12 @@ func ReadBackupDescriptorFromURI(
 }
 
 // readBackupDescriptor reads and unmarshals a BackupDescriptor from filename in
-// the provided export store.
+// the provided export store, decrypting it if encryption options are set.
 func readBackupDescriptor(
-	ctx context.Context, exportStore storageccl.ExportStorage, filename string,
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	filename string,
+	encryption *roachpb.FileEncryptionOptions,
 ) (BackupDescriptor, error) {
 	r, err := exportStore.ReadFile(ctx, filename)
 	if err != nil {
@@ -104,6 +117,15 @@ func readBackupDescriptor(
 	if err != nil {
 		return BackupDescriptor{}, err
 	}
+	if encryption != nil {
+		descBytes, err = storageccl.DecryptFile(descBytes, encryption.Key)
+		if err != nil {
+			return BackupDescriptor{}, err
+		}
+	} else if storageccl.AppearsEncrypted(descBytes) {
+		return BackupDescriptor{}, errors.Errorf(
+			"file appears encrypted -- try specifying %s", backupOptEncPassphrase)
+	}
 	var backupDesc BackupDescriptor
 	if err := protoutil.Unmarshal(descBytes, &backupDesc); err != nil {
 		return BackupDescriptor{}, err
@@ -455,6 +477,19 @@ func optsToKVOptions(opts map[string]string) tree.KVOptions {
 	return kvopts
 }
 
+// redactedOpts returns a copy of opts in which the values of the options that
+// must not be displayed, like passphrases, are replaced.
+func redactedOpts(opts map[string]string) map[string]string {
+	redacted := make(map[string]string, len(opts))
+	for k, v := range opts {
+		if k == backupOptEncPassphrase {
+			v = "redacted"
+		}
+		redacted[k] = v
+	}
+	return redacted
+}
+
 func backupJobDescription(
 	p sql.PlanHookState,
 	backup *tree.Backup,
@@ -464,7 +499,7 @@ func backupJobDescription(
 ) (string, error) {
 	b := &tree.Backup{
 		AsOf:    backup.AsOf,
-		Options: optsToKVOptions(opts),
+		Options: optsToKVOptions(redactedOpts(opts)),
 		Targets: backup.Targets,
 	}
 
@@ -513,6 +548,7 @@ func writeBackupDescriptor(
 	exportStore storageccl.ExportStorage,
 	filename string,
 	desc *BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 ) error {
 	sort.Sort(BackupFileDescriptors(desc.Files))
 
@@ -520,10 +556,142 @@ func writeBackupDescriptor(
 	if err != nil {
 		return err
 	}
+	if encryption != nil {
+		descBuf, err = storageccl.EncryptFile(descBuf, encryption.Key)
+		if err != nil {
+			return err
+		}
+	}
 
 	return exportStore.WriteFile(ctx, filename, bytes.NewReader(descBuf))
 }
 
+// readEncryptionInfo reads and unmarshals the EncryptionInfo of an encrypted
+// backup from the provided export store.
+func readEncryptionInfo(
+	ctx context.Context, exportStore storageccl.ExportStorage,
+) (EncryptionInfo, error) {
+	r, err := exportStore.ReadFile(ctx, BackupEncryptionInfoName)
+	if err != nil {
+		return EncryptionInfo{}, errors.Wrapf(err,
+			"%s was given but the backup does not appear to be encrypted", backupOptEncPassphrase)
+	}
+	defer r.Close()
+	infoBytes, err := ioutil.ReadAll(r)
+	if err != nil {
+		return EncryptionInfo{}, err
+	}
+	var info EncryptionInfo
+	if err := protoutil.Unmarshal(infoBytes, &info); err != nil {
+		return EncryptionInfo{}, err
+	}
+	return info, nil
+}
+
+func writeEncryptionInfo(
+	ctx context.Context, exportStore storageccl.ExportStorage, info *EncryptionInfo,
+) error {
+	infoBuf, err := protoutil.Marshal(info)
+	if err != nil {
+		return err
+	}
+	return exportStore.WriteFile(ctx, BackupEncryptionInfoName, bytes.NewReader(infoBuf))
+}
+
+// getEncryptionFromBase returns the encryption options of the backup at the
+// given URI, which is the base of all the backups that are read or written
+// along with it, for the given passphrase. All these backups share the salt
+// in the EncryptionInfo of the base, so that they're encrypted with the same
+// key.
+func getEncryptionFromBase(
+	ctx context.Context, baseURI string, passphrase string, settings *cluster.Settings,
+) (*roachpb.FileEncryptionOptions, EncryptionInfo, error) {
+	exportStore, err := storageccl.ExportStorageFromURI(ctx, baseURI, settings)
+	if err != nil {
+		return nil, EncryptionInfo{}, err
+	}
+	defer exportStore.Close()
+	info, err := readEncryptionInfo(ctx, exportStore)
+	if err != nil {
+		return nil, EncryptionInfo{}, err
+	}
+	key := storageccl.GenerateKey([]byte(passphrase), info.Salt)
+	return &roachpb.FileEncryptionOptions{Key: key}, info, nil
+}
+
+// jobEncryptionKeys holds the keys of the encrypted backup and restore jobs
+// that were started on this node, by the salt they were derived with, while
+// the statements that started them run.
+//
+// Only the salt is persisted in the job details. The key is as sensitive as
+// the passphrase it's derived from, and persisting it wrapped would need a
+// wrapping key that isn't itself stored in the cluster, which there is no
+// way to configure. Encrypted jobs are therefore not resumable: a job that is
+// adopted by a node that doesn't hold its key, because the node that started
+// it restarted or lost the job, or that is resumed after the statement that
+// started it returned, like after PAUSE JOB, fails as soon as it is resumed
+// with errEncryptedJobNotResumable, and cleans up like any failed job. The
+// statement must then be run again with the passphrase.
+var jobEncryptionKeys = struct {
+	syncutil.Mutex
+	m map[string]*jobEncryptionKey
+}{m: make(map[string]*jobEncryptionKey)}
+
+type jobEncryptionKey struct {
+	key []byte
+	// refs is the number of running jobs that use the key.
+	refs int
+}
+
+// addJobEncryptionKey makes the key with the given salt available to the
+// encrypted jobs started on this node until the returned function is called.
+// All the backups sharing a salt are encrypted with the same key, since the
+// passphrase is verified by decrypting their descriptors before a job starts.
+func addJobEncryptionKey(salt []byte, encryption *roachpb.FileEncryptionOptions) func() {
+	jobEncryptionKeys.Lock()
+	defer jobEncryptionKeys.Unlock()
+	k, ok := jobEncryptionKeys.m[string(salt)]
+	if !ok {
+		k = &jobEncryptionKey{key: encryption.Key}
+		jobEncryptionKeys.m[string(salt)] = k
+	}
+	k.refs++
+	return func() {
+		jobEncryptionKeys.Lock()
+		defer jobEncryptionKeys.Unlock()
+		if k.refs--; k.refs == 0 {
+			delete(jobEncryptionKeys.m, string(salt))
+		}
+	}
+}
+
+// getJobEncryption returns the encryption options of a job whose files are
+// encrypted with the key derived with the given salt, or nil if the salt is
+// empty. See jobEncryptionKeys for why the key may not be available.
+func getJobEncryption(job *jobs.Job, salt []byte) (*roachpb.FileEncryptionOptions, error) {
+	if len(salt) == 0 {
+		return nil, nil
+	}
+	jobEncryptionKeys.Lock()
+	defer jobEncryptionKeys.Unlock()
+	k, ok := jobEncryptionKeys.m[string(salt)]
+	if !ok {
+		return nil, errEncryptedJobNotResumable(job)
+	}
+	return &roachpb.FileEncryptionOptions{Key: k.key}, nil
+}
+
+// errEncryptedJobNotResumable is the error with which an encrypted job fails
+// when it is resumed without its key.
+func errEncryptedJobNotResumable(job *jobs.Job) error {
+	payload := job.Payload()
+	return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+		"encrypted %s job %d cannot be resumed, since its encryption key is only held "+
+			"in memory by the node that started it while the statement runs; "+
+			"run the statement again with %s",
+		strings.ToLower(payload.Type().String()), *job.ID(), backupOptEncPassphrase)
+}
+
 func loadAllDescs(
 	ctx context.Context, db *client.DB, asOf hlc.Timestamp,
 ) ([]sqlbase.Descriptor, error) {
@@ -584,6 +752,7 @@ func backup(
 	job *jobs.Job,
 	backupDesc *BackupDescriptor,
 	checkpointDesc *BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
 	resultsCh chan<- tree.Datums,
 ) (roachpb.BulkOpSummary, error) {
 	// TODO(dan): Figure out how permissions should work. #6713 is tracking this
@@ -696,6 +865,7 @@ func backup(
 					Storage:       exportStore.Conf(),
 					StartTime:     span.start,
 					MVCCFilter:    roachpb.MVCCFilter(backupDesc.MVCCFilter),
+					Encryption:    encryption,
 				}
 				rawRes, pErr := client.SendWrappedWith(ctx, db.NonTransactionalSender(), header, req)
 				if pErr != nil {
@@ -736,7 +906,7 @@ func backup(
 					checkpointMu.Lock()
 					backupDesc.Files = checkpointFiles
 					err := writeBackupDescriptor(
-						ctx, exportStore, BackupDescriptorCheckpointName, backupDesc,
+						ctx, exportStore, BackupDescriptorCheckpointName, backupDesc, encryption,
 					)
 					checkpointMu.Unlock()
 					if err != nil {
@@ -758,7 +928,9 @@ func backup(
 	backupDesc.Files = mu.files
 	backupDesc.EntryCounts = mu.exported
 
-	if err := writeBackupDescriptor(ctx, exportStore, BackupDescriptorName, backupDesc); err != nil {
+	if err := writeBackupDescriptor(
+		ctx, exportStore, BackupDescriptorName, backupDesc, encryption,
+	); err != nil {
 		return mu.exported, err
 	}
 
@@ -789,7 +961,7 @@ func VerifyUsableExportTarget(
 			readable, BackupDescriptorCheckpointName)
 	}
 	if err := writeBackupDescriptor(
-		ctx, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{},
+		ctx, exportStore, BackupDescriptorCheckpointName, &BackupDescriptor{}, nil, /* encryption */
 	); err != nil {
 		return errors.Wrapf(err, "cannot write to %s", readable)
 	}
@@ -880,6 +1052,29 @@ func backupPlanHook(
 			mvccFilter = MVCCFilter_All
 		}
 
+		// An encrypted incremental backup reuses the salt of the full backup it's
+		// based on, so that the whole chain can be restored with one key.
+		var encryption *roachpb.FileEncryptionOptions
+		var encryptionInfo *EncryptionInfo
+		if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+			if len(incrementalFrom) > 0 {
+				enc, info, err := getEncryptionFromBase(ctx, incrementalFrom[0], passphrase, p.ExecCfg().Settings)
+				if err != nil {
+					return err
+				}
+				encryption, encryptionInfo = enc, &info
+			} else {
+				salt, err := storageccl.GenerateSalt()
+				if err != nil {
+					return err
+				}
+				encryptionInfo = &EncryptionInfo{Salt: salt}
+				encryption = &roachpb.FileEncryptionOptions{
+					Key: storageccl.GenerateKey([]byte(passphrase), salt),
+				}
+			}
+		}
+
 		targetDescs, completeDBs, err := ResolveTargetsToDescriptors(ctx, p, endTime, backupStmt.Targets)
 		if err != nil {
 			return err
@@ -909,7 +1104,7 @@ func backupPlanHook(
 			clusterID := p.ExecCfg().ClusterID()
 			prevBackups = make([]BackupDescriptor, len(incrementalFrom))
 			for i, uri := range incrementalFrom {
-				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings)
+				desc, err := ReadBackupDescriptorFromURI(ctx, uri, p.ExecCfg().Settings, encryption)
 				if err != nil {
 					return errors.Wrapf(err, "failed to read backup from %q", uri)
 				}
@@ -1055,6 +1250,15 @@ func backupPlanHook(
 			return err
 		}
 
+		var encryptionSalt []byte
+		if encryptionInfo != nil {
+			if err := writeEncryptionInfo(ctx, exportStore, encryptionInfo); err != nil {
+				return err
+			}
+			encryptionSalt = encryptionInfo.Salt
+			defer addJobEncryptionKey(encryptionSalt, encryption)()
+		}
+
 		_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
 			Description: description,
 			Username:    p.User(),
@@ -1069,6 +1273,7 @@ func backupPlanHook(
 				EndTime:          endTime,
 				URI:              to,
 				BackupDescriptor: descBytes,
+				EncryptionSalt:   encryptionSalt,
 			},
 			Progress: jobspb.BackupProgress{},
 		})
@@ -1093,6 +1298,11 @@ func (b *backupResumer) Resume(
 	details := b.job.Details().(jobspb.BackupDetails)
 	p := phs.(sql.PlanHookState)
 
+	encryption, err := getJobEncryption(b.job, details.EncryptionSalt)
+	if err != nil {
+		return err
+	}
+
 	if len(details.BackupDescriptor) == 0 {
 		return errors.Newf("missing backup descriptor; cannot resume a backup from an older version")
 	}
@@ -1111,7 +1321,9 @@ func (b *backupResumer) Resume(
 		return errors.Wrapf(err, "make storage")
 	}
 	var checkpointDesc *BackupDescriptor
-	if desc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorCheckpointName); err == nil {
+	if desc, err := readBackupDescriptor(
+		ctx, exportStore, BackupDescriptorCheckpointName, encryption,
+	); err == nil {
 		// If the checkpoint is from a different cluster, it's meaningless to us.
 		// More likely though are dummy/lock-out checkpoints with no ClusterID.
 		if desc.ClusterID.Equal(p.ExecCfg().ClusterID()) {
@@ -1134,6 +1346,7 @@ func (b *backupResumer) Resume(
 		b.job,
 		&backupDesc,
 		checkpointDesc,
+		encryption,
 		resultsCh,
 	)
 	b.res = res
@@ -30,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
 	_ "github.com/cockroachdb/cockroach/pkg/ccl/partitionccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl/sampledataccl"
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -1567,6 +1568,140 @@ func TestBackupRestoreIncremental(t *testing.T) {
 	}
 }
 
+func TestBackupRestoreEncrypted(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, rawDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	full, inc := localFoo+"/full", localFoo+"/inc"
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abcdefg'`, full)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+	sqlDB.Exec(t,
+		`BACKUP DATABASE data TO $1 INCREMENTAL FROM $2 WITH encryption_passphrase = 'abcdefg'`,
+		inc, full,
+	)
+	expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+
+	// Everything but the salt is encrypted.
+	for _, dir := range []string{"full", "inc"} {
+		files, err := ioutil.ReadDir(filepath.Join(rawDir, "foo", dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, f := range files {
+			if f.Name() == backupccl.BackupEncryptionInfoName {
+				continue
+			}
+			contents, err := ioutil.ReadFile(filepath.Join(rawDir, "foo", dir, f.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !storageccl.AppearsEncrypted(contents) {
+				t.Fatalf("expected %s/%s to be encrypted", dir, f.Name())
+			}
+		}
+	}
+
+	// The passphrase doesn't end up in the job description.
+	var description string
+	sqlDB.QueryRow(t,
+		`SELECT description FROM [SHOW JOBS] WHERE job_type = 'BACKUP' ORDER BY created DESC LIMIT 1`,
+	).Scan(&description)
+	if strings.Contains(description, "abcdefg") || !strings.Contains(description, "redacted") {
+		t.Fatalf("expected passphrase to be redacted in %q", description)
+	}
+
+	// Neither does the key derived from it: the job details only hold the salt.
+	var payloadBytes []byte
+	sqlDB.QueryRow(t,
+		`SELECT payload FROM system.jobs WHERE id = (
+			SELECT job_id FROM [SHOW JOBS] WHERE job_type = 'BACKUP' ORDER BY created DESC LIMIT 1
+		)`,
+	).Scan(&payloadBytes)
+	var payload jobspb.Payload
+	if err := protoutil.Unmarshal(payloadBytes, &payload); err != nil {
+		t.Fatal(err)
+	}
+	salt := payload.GetBackup().EncryptionSalt
+	if len(salt) == 0 {
+		t.Fatal("expected the salt to be in the job details")
+	}
+	if key := storageccl.GenerateKey([]byte("abcdefg"), salt); bytes.Contains(payloadBytes, key) {
+		t.Fatal("expected the key to not be in the job details")
+	}
+
+	sqlDB.ExpectErr(t, "file appears encrypted -- try specifying encryption_passphrase",
+		`SHOW BACKUP $1`, full)
+	sqlDB.ExpectErr(t, "file could not be decrypted",
+		`SHOW BACKUP $1 WITH encryption_passphrase = 'wrong'`, full)
+	sqlDB.CheckQueryResults(t,
+		`SELECT table_name FROM [SHOW BACKUP $1 WITH encryption_passphrase = 'abcdefg']`,
+		[][]string{{"bank"}},
+	)
+
+	sqlDB.Exec(t, `DROP DATABASE data CASCADE`)
+	sqlDB.ExpectErr(t, "file appears encrypted -- try specifying encryption_passphrase",
+		`RESTORE DATABASE data FROM $1, $2`, full, inc)
+	sqlDB.ExpectErr(t, "file could not be decrypted",
+		`RESTORE DATABASE data FROM $1, $2 WITH encryption_passphrase = 'wrong'`, full, inc)
+	sqlDB.Exec(t, `RESTORE DATABASE data FROM $1, $2 WITH encryption_passphrase = 'abcdefg'`, full, inc)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank ORDER BY id`, expected)
+
+	// An unencrypted backup can't be read as an encrypted one.
+	sqlDB.Exec(t, `BACKUP DATABASE data TO $1`, localFoo+"/plain")
+	sqlDB.ExpectErr(t, "does not appear to be encrypted",
+		`SHOW BACKUP $1 WITH encryption_passphrase = 'abcdefg'`, localFoo+"/plain")
+}
+
+// TestBackupEncryptedNotResumable verifies that an encrypted backup job that
+// is resumed after the statement that started it returned, and with it the
+// key it was encrypted with, fails with a clear error.
+func TestBackupEncryptedNotResumable(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(oldInterval time.Duration) {
+		jobs.DefaultAdoptInterval = oldInterval
+	}(jobs.DefaultAdoptInterval)
+	jobs.DefaultAdoptInterval = 100 * time.Millisecond
+
+	var allowResponse chan struct{}
+	params := base.TestClusterArgs{}
+	params.ServerArgs.Knobs.Store = &storage.StoreTestingKnobs{
+		TestingResponseFilter: jobutils.BulkOpResponseFilter(&allowResponse),
+	}
+	const numAccounts = 100
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetupWithParams(
+		t, singleNode, numAccounts, initNone, params,
+	)
+	defer cleanupFn()
+	// Make sure the backup exports more than one range, so that it is still
+	// running after the first response.
+	sqlDB.Exec(t, `ALTER TABLE data.bank SPLIT AT VALUES (25), (50), (75)`)
+
+	jobID, err := jobutils.RunJob(t, sqlDB, &allowResponse, []string{"PAUSE"},
+		`BACKUP DATABASE data TO $1 WITH encryption_passphrase = 'abcdefg'`, localFoo)
+	if !testutils.IsError(err, "job paused") {
+		t.Fatalf("expected 'job paused' error, but got %+v", err)
+	}
+
+	sqlDB.Exec(t, `RESUME JOB $1`, jobID)
+	testutils.SucceedsSoon(t, func() error {
+		var status, jobErr string
+		sqlDB.QueryRow(t, `SELECT status, error FROM [SHOW JOBS] WHERE job_id = $1`, jobID).
+			Scan(&status, &jobErr)
+		if status != string(jobs.StatusFailed) {
+			return errors.Errorf("expected job %d to fail, found status %s", jobID, status)
+		}
+		expected := fmt.Sprintf("encrypted backup job %d cannot be resumed", jobID)
+		if !strings.Contains(jobErr, expected) {
+			return errors.Errorf("expected error %q, found %q", expected, jobErr)
+		}
+		return nil
+	})
+}
+
 // a bg worker is intended to write to the bank table concurrent with other
 // operations (writes, backups, restores), mutating the payload on rows-maxID.
 // it notified the `wake` channel (to allow ensuring bg activity has occurred)
@@ -2030,6 +2165,89 @@ func TestAsOfSystemTimeOnRestoredData(t *testing.T) {
 	}
 }
 
+func TestRestoreNewName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 10
+	_, _, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `CREATE TABLE data.child (id INT PRIMARY KEY, bank_id INT REFERENCES data.bank (id))`)
+	sqlDB.Exec(t, `INSERT INTO data.child VALUES (1, 1)`)
+	expected := sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`)
+	var ts string
+	sqlDB.QueryRow(t, `SELECT cluster_logical_timestamp()`).Scan(&ts)
+	sqlDB.Exec(t, `UPDATE data.bank SET balance = 0`)
+	sqlDB.Exec(t, `BACKUP data.bank, data.child TO $1 WITH revision_history`, localFoo)
+
+	// The table is restored as of before the update next to the live one.
+	sqlDB.Exec(t, fmt.Sprintf(
+		`RESTORE data.bank FROM $1 AS OF SYSTEM TIME %s WITH new_name = 'bank_restored'`, ts,
+	), localFoo)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data.bank_restored ORDER BY id`, expected)
+	sqlDB.CheckQueryResults(t, `SELECT DISTINCT balance FROM data.bank`, [][]string{{"0"}})
+
+	// The foreign key of the restored child table references the live table it
+	// referenced in the backup, but is unvalidated.
+	sqlDB.Exec(t, `RESTORE data.child FROM $1 WITH new_name = 'child_restored'`, localFoo)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data.child_restored`, [][]string{{"1", "1"}})
+	sqlDB.CheckQueryResults(t, `SELECT details, validated FROM [SHOW CONSTRAINTS FROM data.child_restored]
+WHERE constraint_type = 'FOREIGN KEY'`, [][]string{{"FOREIGN KEY (bank_id) REFERENCES bank(id)", "false"}})
+	sqlDB.ExpectErr(t, `foreign key violation`, `INSERT INTO data.child_restored VALUES (2, 1000)`)
+	sqlDB.Exec(t, `INSERT INTO data.child_restored VALUES (2, 2)`)
+	sqlDB.ExpectErr(t, `foreign key`, `DELETE FROM data.bank WHERE id = 2`)
+	sqlDB.Exec(t, `DELETE FROM data.bank_restored WHERE id = 1`)
+
+	// Several tables can be renamed, and foreign keys between them are kept.
+	sqlDB.Exec(t,
+		`RESTORE data.bank, data.child FROM $1 WITH new_name = 'bank = bank2, child = child2'`, localFoo,
+	)
+	sqlDB.CheckQueryResults(t, `SELECT * FROM data.child2`, [][]string{{"1", "1"}})
+	sqlDB.ExpectErr(t, `foreign key`, `DELETE FROM data.bank2 WHERE id = 1`)
+
+	// A foreign key to a table that is neither restored nor exists in the target
+	// database needs skip_missing_foreign_keys.
+	sqlDB.Exec(t, `CREATE DATABASE other`)
+	sqlDB.ExpectErr(t, `cannot restore table "child" without referenced table`,
+		`RESTORE data.child FROM $1 WITH into_db = 'other'`, localFoo)
+
+	sqlDB.ExpectErr(t, `relation "child" already exists`,
+		`RESTORE data.bank FROM $1 WITH new_name = 'child'`, localFoo)
+	sqlDB.ExpectErr(t, `"new_name" option can only be used when restoring a single table`,
+		`RESTORE data.* FROM $1 WITH new_name = 'foo'`, localFoo)
+	sqlDB.ExpectErr(t, `"new_name" option renames table "foo", which is not being restored`,
+		`RESTORE data.bank FROM $1 WITH new_name = 'foo=bar'`, localFoo)
+	sqlDB.ExpectErr(t, `"new_name" option renames more than one table to "foo"`,
+		`RESTORE data.* FROM $1 WITH new_name = 'bank=foo,child=foo'`, localFoo)
+}
+
+// TestRestoreRowLevelTTL verifies that a restored table with a row-level TTL
+// gets a row-level TTL job of its own instead of the job of the backed up
+// table.
+func TestRestoreRowLevelTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 1
+	_, tc, sqlDB, _, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+	kvDB := tc.Servers[0].DB()
+
+	sqlDB.Exec(t, `CREATE TABLE data.ttl (k INT PRIMARY KEY, ts TIMESTAMPTZ NOT NULL)`)
+	sqlDB.Exec(t, `ALTER TABLE data.ttl SET TTL '1 day' ON ts`)
+	sqlDB.Exec(t, `BACKUP data.ttl TO $1`, localFoo)
+	sqlDB.Exec(t, `CREATE DATABASE restored`)
+	sqlDB.Exec(t, `RESTORE data.ttl FROM $1 WITH into_db = 'restored'`, localFoo)
+
+	backedUp := sqlbase.GetTableDescriptor(kvDB, "data", "ttl").RowLevelTTL
+	restored := sqlbase.GetTableDescriptor(kvDB, "restored", "ttl").RowLevelTTL
+	if restored == nil || restored.JobID == 0 || restored.JobID == backedUp.JobID {
+		t.Fatalf("expected the restored table to have a new row-level TTL job, found %+v", restored)
+	}
+	sqlDB.CheckQueryResults(t, fmt.Sprintf(
+		`SELECT description, status FROM [SHOW JOBS] WHERE job_id = %d`, restored.JobID,
+	), [][]string{{"row-level TTL for table restored.public.ttl", "running"}})
+}
+
 func TestBackupRestoreChecksum(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -14,6 +14,7 @@ import (
 	"math"
 	"runtime"
 	"sort"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -50,25 +51,36 @@ import (
 // TableRewriteMap maps old table IDs to new table and parent IDs.
 type TableRewriteMap map[sqlbase.ID]*jobspb.RestoreDetails_TableRewrite
 
+// ForeignKeyTargetMap maps the IDs of tables in a backup that aren't restored
+// to the existing tables that foreign keys of the restored tables reference in
+// their place.
+type ForeignKeyTargetMap map[sqlbase.ID]*jobspb.RestoreDetails_ForeignKeyTarget
+
 const (
 	restoreOptIntoDB               = "into_db"
+	restoreOptNewName              = "new_name"
 	restoreOptSkipMissingFKs       = "skip_missing_foreign_keys"
 	restoreOptSkipMissingSequences = "skip_missing_sequences"
 )
 
 var restoreOptionExpectValues = map[string]sql.KVStringOptValidate{
 	restoreOptIntoDB:               sql.KVStringOptRequireValue,
+	restoreOptNewName:              sql.KVStringOptRequireValue,
 	restoreOptSkipMissingFKs:       sql.KVStringOptRequireNoValue,
 	restoreOptSkipMissingSequences: sql.KVStringOptRequireNoValue,
+	backupOptEncPassphrase:         sql.KVStringOptRequireValue,
 }
 
 func loadBackupDescs(
-	ctx context.Context, uris []string, settings *cluster.Settings,
+	ctx context.Context,
+	uris []string,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, error) {
 	backupDescs := make([]BackupDescriptor, len(uris))
 
 	for i, uri := range uris {
-		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings)
+		desc, err := ReadBackupDescriptorFromURI(ctx, uri, settings, encryption)
 		if err != nil {
 			return nil, errors.Wrapf(err, "failed to read backup descriptor")
 		}
@@ -185,19 +197,150 @@ func rewriteViewQueryDBNames(table *sqlbase.TableDescriptor, newDB string) error
 	return nil
 }
 
+// restoreNewNames returns the names that the new_name option, if specified,
+// gives to the tables in tablesByID, by their ID. The option is either a single
+// name, if a single table is restored, or a list that maps the names of
+// restored tables to new names, as in 'a=b,c=d'.
+func restoreNewNames(
+	opts map[string]string, restoringDBs bool, tablesByID map[sqlbase.ID]*sqlbase.TableDescriptor,
+) (map[sqlbase.ID]string, error) {
+	opt, ok := opts[restoreOptNewName]
+	if !ok {
+		return nil, nil
+	}
+	newNames := make(map[sqlbase.ID]string, len(tablesByID))
+	if !strings.Contains(opt, "=") {
+		if restoringDBs || len(tablesByID) != 1 {
+			return nil, errors.Errorf("%q option can only be used when restoring a single table, "+
+				"unless it maps table names to new names, as in 'a=b,c=d'", restoreOptNewName)
+		}
+		for id := range tablesByID {
+			newNames[id] = opt
+		}
+		return newNames, nil
+	}
+	if restoringDBs {
+		return nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptNewName)
+	}
+
+	idsByName := make(map[string]sqlbase.ID, len(tablesByID))
+	for id, table := range tablesByID {
+		idsByName[table.Name] = id
+	}
+	renamedTo := make(map[string]struct{})
+	for _, pair := range strings.Split(opt, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, errors.Errorf("invalid %q option %q: expected a list of old=new table names",
+				restoreOptNewName, opt)
+		}
+		oldName, newName := strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1])
+		id, ok := idsByName[oldName]
+		if !ok {
+			return nil, errors.Errorf("%q option renames table %q, which is not being restored",
+				restoreOptNewName, oldName)
+		}
+		if _, ok := newNames[id]; ok {
+			return nil, errors.Errorf("%q option renames table %q more than once",
+				restoreOptNewName, oldName)
+		}
+		if _, ok := renamedTo[newName]; ok {
+			return nil, errors.Errorf("%q option renames more than one table to %q",
+				restoreOptNewName, newName)
+		}
+		renamedTo[newName] = struct{}{}
+		newNames[id] = newName
+	}
+	return newNames, nil
+}
+
+// resolveForeignKeyTarget returns the existing table in the database with the
+// given ID that a foreign key can reference in place of backupTable, a table in
+// the backup that isn't restored, as well as the ID of the index of the table
+// that matches the index of backupTable with the given ID. That table has the
+// name of backupTable and a unique index on columns with the same names and
+// equivalent types. It returns a nil table if there is no such table.
+func resolveForeignKeyTarget(
+	ctx context.Context,
+	txn *client.Txn,
+	parentID sqlbase.ID,
+	backupTable *sqlbase.TableDescriptor,
+	backupIndexID sqlbase.IndexID,
+) (*sqlbase.TableDescriptor, sqlbase.IndexID, error) {
+	backupIndex, err := backupTable.FindIndexByID(backupIndexID)
+	if err != nil {
+		return nil, 0, err
+	}
+	res, err := txn.Get(ctx, sqlbase.MakeNameMetadataKey(parentID, backupTable.Name))
+	if err != nil || !res.Exists() {
+		return nil, 0, err
+	}
+	id, err := res.Value.GetInt()
+	if err != nil {
+		return nil, 0, err
+	}
+	table, err := sqlbase.GetTableDescFromID(ctx, txn, sqlbase.ID(id))
+	if err != nil {
+		if err == sqlbase.ErrDescriptorNotFound {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	if !table.IsTable() || table.Dropped() {
+		return nil, 0, nil
+	}
+
+	matches := func(index *sqlbase.IndexDescriptor) bool {
+		if !index.Unique || len(index.ColumnNames) != len(backupIndex.ColumnNames) {
+			return false
+		}
+		for i, name := range index.ColumnNames {
+			if name != backupIndex.ColumnNames[i] {
+				return false
+			}
+			col, err := table.FindActiveColumnByName(name)
+			if err != nil {
+				return false
+			}
+			backupCol, err := backupTable.FindActiveColumnByName(name)
+			if err != nil || !col.Type.Equivalent(&backupCol.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	if matches(&table.PrimaryIndex) {
+		return table, table.PrimaryIndex.ID, nil
+	}
+	for i := range table.Indexes {
+		if matches(&table.Indexes[i]) {
+			return table, table.Indexes[i].ID, nil
+		}
+	}
+	return nil, 0, nil
+}
+
 // allocateTableRewrites determines the new ID and parentID (a "TableRewrite")
 // for each table in sqlDescs and returns a mapping from old ID to said
 // TableRewrite. It first validates that the provided sqlDescs can be restored
 // into their original database (or the database specified in opst) to avoid
 // leaking table IDs if we can be sure the restore would fail.
+//
+// A foreign key of a table restored into an existing database that references
+// a table that isn't restored is resolved to the existing table with the name
+// the referenced table has in backupDescs, the descriptors of the backup, if
+// there is one with a matching unique index. The returned ForeignKeyTargetMap
+// maps the referenced tables to such existing tables.
 func allocateTableRewrites(
 	ctx context.Context,
 	p sql.PlanHookState,
 	sqlDescs []sqlbase.Descriptor,
 	restoreDBs []*sqlbase.DatabaseDescriptor,
+	backupDescs []sqlbase.Descriptor,
 	opts map[string]string,
-) (TableRewriteMap, error) {
+) (TableRewriteMap, ForeignKeyTargetMap, error) {
 	tableRewrites := make(TableRewriteMap)
+	fkTargets := make(ForeignKeyTargetMap)
 	overrideDB, renaming := opts[restoreOptIntoDB]
 
 	restoreDBNames := make(map[string]*sqlbase.DatabaseDescriptor, len(restoreDBs))
@@ -206,7 +349,7 @@ func allocateTableRewrites(
 	}
 
 	if len(restoreDBNames) > 0 && renaming {
-		return nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptIntoDB)
+		return nil, nil, errors.Errorf("cannot use %q option when restoring database(s)", restoreOptIntoDB)
 	}
 
 	databasesByID := make(map[sqlbase.ID]*sqlbase.DatabaseDescriptor)
@@ -219,37 +362,31 @@ func allocateTableRewrites(
 		}
 	}
 
+	newNames, err := restoreNewNames(opts, len(restoreDBNames) > 0, tablesByID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	backupTablesByID := make(map[sqlbase.ID]*sqlbase.TableDescriptor)
+	for _, desc := range backupDescs {
+		if tableDesc := desc.GetTable(); tableDesc != nil {
+			backupTablesByID[tableDesc.ID] = tableDesc
+		}
+	}
+
 	// The logic at the end of this function leaks table IDs, so fail fast if
 	// we can be certain the restore will fail.
 
 	// Fail fast if the tables to restore are incompatible with the specified
 	// options.
-	// Check that foreign key targets exist.
 	for _, table := range tablesByID {
-		if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
-			if index.ForeignKey.IsSet() {
-				to := index.ForeignKey.Table
-				if _, ok := tablesByID[to]; !ok {
-					if _, ok := opts[restoreOptSkipMissingFKs]; !ok {
-						return errors.Errorf(
-							"cannot restore table %q without referenced table %d (or %q option)",
-							table.Name, to, restoreOptSkipMissingFKs,
-						)
-					}
-				}
-			}
-			return nil
-		}); err != nil {
-			return nil, err
-		}
-
 		// Check that referenced sequences exist.
 		for i := range table.Columns {
 			col := &table.Columns[i]
 			for _, seqID := range col.UsesSequenceIds {
 				if _, ok := tablesByID[seqID]; !ok {
 					if _, ok := opts[restoreOptSkipMissingSequences]; !ok {
-						return nil, errors.Errorf(
+						return nil, nil, errors.Errorf(
 							"cannot restore table %q without referenced sequence %d (or %q option)",
 							table.Name, seqID, restoreOptSkipMissingSequences,
 						)
@@ -260,6 +397,7 @@ func allocateTableRewrites(
 	}
 
 	needsNewParentIDs := make(map[string][]sqlbase.ID)
+	restoredNames := make(map[string]struct{}, len(tablesByID))
 
 	// Fail fast if the necessary databases don't exist or are otherwise
 	// incompatible with this restore.
@@ -311,7 +449,48 @@ func allocateTableRewrites(
 
 				// Check that the table name is _not_ in use.
 				// This would fail the CPut later anyway, but this yields a prettier error.
-				if err := CheckTableExists(ctx, txn, parentID, table.Name); err != nil {
+				tableName := table.Name
+				if newName, ok := newNames[table.ID]; ok {
+					tableName = newName
+				}
+				if err := CheckTableExists(ctx, txn, parentID, tableName); err != nil {
+					return err
+				}
+				nameKey := string(sqlbase.MakeNameMetadataKey(parentID, tableName))
+				if _, ok := restoredNames[nameKey]; ok {
+					return sqlbase.NewRelationAlreadyExistsError(tableName)
+				}
+				restoredNames[nameKey] = struct{}{}
+
+				// Resolve the foreign keys that reference tables which aren't
+				// restored to existing tables in the database.
+				if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
+					to := index.ForeignKey.Table
+					if !index.ForeignKey.IsSet() || tablesByID[to] != nil || backupTablesByID[to] == nil {
+						return nil
+					}
+					live, liveIndexID, err := resolveForeignKeyTarget(
+						ctx, txn, parentID, backupTablesByID[to], index.ForeignKey.Index,
+					)
+					if err != nil || live == nil {
+						return err
+					}
+					target, ok := fkTargets[to]
+					if !ok {
+						target = &jobspb.RestoreDetails_ForeignKeyTarget{
+							TableID:  live.ID,
+							IndexIDs: make(map[sqlbase.IndexID]sqlbase.IndexID),
+						}
+						fkTargets[to] = target
+					} else if target.TableID != live.ID {
+						return errors.Errorf(
+							"cannot restore tables into different databases that reference table %q",
+							live.Name,
+						)
+					}
+					target.IndexIDs[index.ForeignKey.Index] = liveIndexID
+					return nil
+				}); err != nil {
 					return err
 				}
 
@@ -330,12 +509,41 @@ func allocateTableRewrites(
 				}
 				// Create the table rewrite with the new parent ID. We've done all the
 				// up-front validation that we can.
-				tableRewrites[table.ID] = &jobspb.RestoreDetails_TableRewrite{ParentID: parentID}
+				tableRewrites[table.ID] = &jobspb.RestoreDetails_TableRewrite{
+					ParentID: parentID,
+					NewName:  newNames[table.ID],
+				}
 			}
 		}
 		return nil
 	}); err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+
+	// Check that foreign key targets exist, either restored or existing.
+	if _, ok := opts[restoreOptSkipMissingFKs]; !ok {
+		for _, table := range tablesByID {
+			if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
+				if index.ForeignKey.IsSet() {
+					to := index.ForeignKey.Table
+					if _, ok := tablesByID[to]; ok {
+						return nil
+					}
+					if target, ok := fkTargets[to]; ok {
+						if _, ok := target.IndexIDs[index.ForeignKey.Index]; ok {
+							return nil
+						}
+					}
+					return errors.Errorf(
+						"cannot restore table %q without referenced table %d (or %q option)",
+						table.Name, to, restoreOptSkipMissingFKs,
+					)
+				}
+				return nil
+			}); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
 
 	// Allocate new IDs for each database and table.
@@ -354,7 +562,7 @@ func allocateTableRewrites(
 	for _, db := range restoreDBs {
 		newID, err := sql.GenerateUniqueDescID(ctx, p.ExecCfg().DB)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		tableRewrites[db.ID] = &jobspb.RestoreDetails_TableRewrite{TableID: newID}
 		for _, tableID := range needsNewParentIDs[db.Name] {
@@ -370,12 +578,12 @@ func allocateTableRewrites(
 	for _, table := range tables {
 		newTableID, err := sql.GenerateUniqueDescID(ctx, p.ExecCfg().DB)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		tableRewrites[table.ID].TableID = newTableID
 	}
 
-	return tableRewrites, nil
+	return tableRewrites, fkTargets, nil
 }
 
 // CheckTableExists returns an error if a table already exists with given
@@ -394,11 +602,16 @@ func CheckTableExists(
 	return nil
 }
 
-// RewriteTableDescs mutates tables to match the ID and privilege specified
-// in tableRewrites, as well as adjusting cross-table references to use the
-// new IDs. overrideDB can be specified to set database names in views.
+// RewriteTableDescs mutates tables to match the ID, name and privilege
+// specified in tableRewrites, as well as adjusting cross-table references to
+// use the new IDs. Foreign keys that reference tables in fkTargets are
+// adjusted to reference the existing tables. overrideDB can be specified to
+// set database names in views.
 func RewriteTableDescs(
-	tables []*sqlbase.TableDescriptor, tableRewrites TableRewriteMap, overrideDB string,
+	tables []*sqlbase.TableDescriptor,
+	tableRewrites TableRewriteMap,
+	fkTargets ForeignKeyTargetMap,
+	overrideDB string,
 ) error {
 	for _, table := range tables {
 		tableRewrite, ok := tableRewrites[table.ID]
@@ -419,6 +632,17 @@ func RewriteTableDescs(
 
 		table.ID = tableRewrite.TableID
 		table.ParentID = tableRewrite.ParentID
+		if tableRewrite.NewName != "" {
+			table.Name = tableRewrite.NewName
+		}
+
+		// The row-level TTL job of the backed up table doesn't delete the rows of
+		// the restored one, which gets its own job once it is published.
+		if table.RowLevelTTL != nil {
+			ttl := *table.RowLevelTTL
+			ttl.JobID = 0
+			table.RowLevelTTL = &ttl
+		}
 
 		if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
 			// Verify that for any interleaved index being restored, the interleave
@@ -449,16 +673,18 @@ func RewriteTableDescs(
 				to := index.ForeignKey.Table
 				if indexRewrite, ok := tableRewrites[to]; ok {
 					index.ForeignKey.Table = indexRewrite.TableID
+				} else if target, ok := fkTargets[to]; ok && target.IndexIDs[index.ForeignKey.Index] != 0 {
+					// The restored rows were never checked against the rows of the
+					// existing table, so the foreign key is unvalidated.
+					index.ForeignKey.Table = target.TableID
+					index.ForeignKey.Index = target.IndexIDs[index.ForeignKey.Index]
+					index.ForeignKey.Validity = sqlbase.ConstraintValidity_Unvalidated
 				} else {
-					// If indexRewrite doesn't exist, the user has specified
+					// If neither rewrite exists, the user has specified
 					// restoreOptSkipMissingFKs. Error checking in the case the user hasn't has
 					// already been done in allocateTableRewrites.
 					index.ForeignKey = sqlbase.ForeignKeyReference{}
 				}
-
-				// TODO(dt): if there is an existing (i.e. non-restoring) table with
-				// a db and name matching the one the FK pointed to at backup, should
-				// we update the FK to point to it?
 			}
 
 			origRefs := index.ReferencedBy
@@ -938,6 +1164,9 @@ func WriteTableDescs(
 			b.CPut(tables[i].GetDescMetadataKey(), sqlbase.WrapDescriptor(tables[i]), nil)
 			b.CPut(tables[i].GetNameMetadataKey(), tables[i].ID, nil)
 		}
+		if err := writeForeignKeyBackReferences(ctx, txn, b, tables); err != nil {
+			return err
+		}
 		for _, kv := range extra {
 			b.InitPut(kv.Key, &kv.Value, false)
 		}
@@ -959,12 +1188,69 @@ func WriteTableDescs(
 	return errors.Wrapf(err, "restoring table desc and namespace entries")
 }
 
+// writeForeignKeyBackReferences adds to b the descriptors of the existing
+// tables that foreign keys of tables reference, with back references to the
+// foreign keys.
+func writeForeignKeyBackReferences(
+	ctx context.Context, txn *client.Txn, b *client.Batch, tables []*sqlbase.TableDescriptor,
+) error {
+	restoring := make(map[sqlbase.ID]struct{}, len(tables))
+	for _, table := range tables {
+		restoring[table.ID] = struct{}{}
+	}
+	referenced := make(map[sqlbase.ID]*sqlbase.MutableTableDescriptor)
+	for _, table := range tables {
+		if err := table.ForeachNonDropIndex(func(index *sqlbase.IndexDescriptor) error {
+			fk := index.ForeignKey
+			if _, ok := restoring[fk.Table]; ok || !fk.IsSet() {
+				return nil
+			}
+			target, ok := referenced[fk.Table]
+			if !ok {
+				var err error
+				if target, err = sqlbase.GetMutableTableDescFromID(ctx, txn, fk.Table); err != nil {
+					return errors.Wrapf(err, "resolving table %d referenced by table %q", fk.Table, table.Name)
+				}
+				if target.Dropped() {
+					return errors.Errorf("table %q referenced by table %q was dropped", target.Name, table.Name)
+				}
+				referenced[fk.Table] = target
+			}
+			targetIndex, err := target.FindIndexByID(fk.Index)
+			if err != nil {
+				return errors.Wrapf(err, "resolving index of table %q referenced by table %q",
+					target.Name, table.Name)
+			}
+			targetIndex.ReferencedBy = append(targetIndex.ReferencedBy,
+				sqlbase.ForeignKeyReference{Table: table.ID, Index: index.ID})
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if len(referenced) == 0 {
+		return nil
+	}
+	// Changing the descriptors of existing tables needs the system config
+	// trigger, so that the new versions are gossiped.
+	if err := txn.SetSystemConfigTrigger(); err != nil {
+		return err
+	}
+	for _, target := range referenced {
+		if err := target.MaybeIncrementVersion(ctx, txn); err != nil {
+			return err
+		}
+		b.Put(sqlbase.MakeDescMetadataKey(target.ID), sqlbase.WrapDescriptor(target.TableDesc()))
+	}
+	return nil
+}
+
 func restoreJobDescription(
 	p sql.PlanHookState, restore *tree.Restore, from []string, opts map[string]string,
 ) (string, error) {
 	r := &tree.Restore{
 		AsOf:    restore.AsOf,
-		Options: optsToKVOptions(opts),
+		Options: optsToKVOptions(redactedOpts(opts)),
 		Targets: restore.Targets,
 		From:    make(tree.Exprs, len(restore.From)),
 	}
@@ -1035,7 +1321,9 @@ func restore(
 	endTime hlc.Timestamp,
 	sqlDescs []sqlbase.Descriptor,
 	tableRewrites TableRewriteMap,
+	fkTargets ForeignKeyTargetMap,
 	overrideDB string,
+	encryption *roachpb.FileEncryptionOptions,
 	job *jobs.Job,
 	resultsCh chan<- tree.Datums,
 ) (roachpb.BulkOpSummary, []*sqlbase.DatabaseDescriptor, []*sqlbase.TableDescriptor, error) {
@@ -1076,7 +1364,7 @@ func restore(
 
 	// Assign new IDs and privileges to the tables, and update all references to
 	// use the new IDs.
-	if err := RewriteTableDescs(tables, tableRewrites, overrideDB); err != nil {
+	if err := RewriteTableDescs(tables, tableRewrites, fkTargets, overrideDB); err != nil {
 		return mu.res, nil, nil, err
 	}
 
@@ -1201,6 +1489,7 @@ func restore(
 				Files:         readyForImportSpan.files,
 				EndTime:       endTime,
 				Rekeys:        rekeys,
+				Encryption:    encryption,
 			}
 
 			log.VEventf(restoreCtx, 1, "importing %d of %d", idx, len(importSpans))
@@ -1337,7 +1626,19 @@ func doRestorePlan(
 	opts map[string]string,
 	resultsCh chan<- tree.Datums,
 ) error {
-	backupDescs, err := loadBackupDescs(ctx, from, p.ExecCfg().Settings)
+	var encryption *roachpb.FileEncryptionOptions
+	var encryptionSalt []byte
+	if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+		var info EncryptionInfo
+		var err error
+		encryption, info, err = getEncryptionFromBase(ctx, from[0], passphrase, p.ExecCfg().Settings)
+		if err != nil {
+			return err
+		}
+		encryptionSalt = info.Salt
+	}
+
+	backupDescs, err := loadBackupDescs(ctx, from, p.ExecCfg().Settings, encryption)
 	if err != nil {
 		return err
 	}
@@ -1378,7 +1679,8 @@ func doRestorePlan(
 		return err
 	}
 
-	tableRewrites, err := allocateTableRewrites(ctx, p, sqlDescs, restoreDBs, opts)
+	allDescs, _ := loadSQLDescsFromBackupsAtTime(backupDescs, endTime)
+	tableRewrites, fkTargets, err := allocateTableRewrites(ctx, p, sqlDescs, restoreDBs, allDescs, opts)
 	if err != nil {
 		return err
 	}
@@ -1393,10 +1695,13 @@ func doRestorePlan(
 			tables = append(tables, tableDesc)
 		}
 	}
-	if err := RewriteTableDescs(tables, tableRewrites, opts[restoreOptIntoDB]); err != nil {
+	if err := RewriteTableDescs(tables, tableRewrites, fkTargets, opts[restoreOptIntoDB]); err != nil {
 		return err
 	}
 
+	if encryption != nil {
+		defer addJobEncryptionKey(encryptionSalt, encryption)()
+	}
 	_, errCh, err := p.ExecCfg().JobRegistry.StartJob(ctx, resultsCh, jobs.Record{
 		Description: description,
 		Username:    p.User(),
@@ -1407,11 +1712,13 @@ func doRestorePlan(
 			return sqlDescIDs
 		}(),
 		Details: jobspb.RestoreDetails{
-			EndTime:       endTime,
-			TableRewrites: tableRewrites,
-			URIs:          from,
-			TableDescs:    tables,
-			OverrideDB:    opts[restoreOptIntoDB],
+			EndTime:           endTime,
+			TableRewrites:     tableRewrites,
+			URIs:              from,
+			TableDescs:        tables,
+			OverrideDB:        opts[restoreOptIntoDB],
+			EncryptionSalt:    encryptionSalt,
+			ForeignKeyTargets: fkTargets,
 		},
 		Progress: jobspb.RestoreProgress{},
 	})
@@ -1422,9 +1729,12 @@ func doRestorePlan(
 }
 
 func loadBackupSQLDescs(
-	ctx context.Context, details jobspb.RestoreDetails, settings *cluster.Settings,
+	ctx context.Context,
+	details jobspb.RestoreDetails,
+	settings *cluster.Settings,
+	encryption *roachpb.FileEncryptionOptions,
 ) ([]BackupDescriptor, []sqlbase.Descriptor, error) {
-	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings)
+	backupDescs, err := loadBackupDescs(ctx, details.URIs, settings, encryption)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -1447,6 +1757,7 @@ type restoreResumer struct {
 	databases      []*sqlbase.DatabaseDescriptor
 	tables         []*sqlbase.TableDescriptor
 	statsRefresher *stats.Refresher
+	jobRegistry    *jobs.Registry
 }
 
 // Resume is part of the jobs.Resumer interface.
@@ -1456,7 +1767,11 @@ func (r *restoreResumer) Resume(
 	details := r.job.Details().(jobspb.RestoreDetails)
 	p := phs.(sql.PlanHookState)
 
-	backupDescs, sqlDescs, err := loadBackupSQLDescs(ctx, details, r.settings)
+	encryption, err := getJobEncryption(r.job, details.EncryptionSalt)
+	if err != nil {
+		return err
+	}
+	backupDescs, sqlDescs, err := loadBackupSQLDescs(ctx, details, r.settings, encryption)
 	if err != nil {
 		return err
 	}
@@ -1470,7 +1785,9 @@ func (r *restoreResumer) Resume(
 		details.EndTime,
 		sqlDescs,
 		details.TableRewrites,
+		details.ForeignKeyTargets,
 		details.OverrideDB,
+		encryption,
 		r.job,
 		resultsCh,
 	)
@@ -1478,6 +1795,7 @@ func (r *restoreResumer) Resume(
 	r.databases = databases
 	r.tables = tables
 	r.statsRefresher = p.ExecCfg().StatsRefresher
+	r.jobRegistry = p.ExecCfg().JobRegistry
 	return err
 }
 
@@ -1504,6 +1822,10 @@ func (r *restoreResumer) OnFailOrCancel(ctx context.Context, txn *client.Txn) er
 func (r *restoreResumer) OnSuccess(ctx context.Context, txn *client.Txn) error {
 	log.Event(ctx, "making tables live")
 
+	if err := r.createRowLevelTTLJobs(ctx, txn); err != nil {
+		return err
+	}
+
 	// Write the new TableDescriptors and flip the namespace entries over to
 	// them. After this call, any queries on a table will be served by the newly
 	// restored data.
@@ -1521,6 +1843,37 @@ func (r *restoreResumer) OnSuccess(ctx context.Context, txn *client.Txn) error {
 	return nil
 }
 
+// createRowLevelTTLJobs creates the row-level TTL jobs of the restored tables
+// which have a row-level TTL, in the transaction which publishes them.
+func (r *restoreResumer) createRowLevelTTLJobs(ctx context.Context, txn *client.Txn) error {
+	for _, table := range r.tables {
+		if table.RowLevelTTL == nil {
+			continue
+		}
+		var dbDesc *sqlbase.DatabaseDescriptor
+		for _, db := range r.databases {
+			if db.ID == table.ParentID {
+				dbDesc = db
+			}
+		}
+		if dbDesc == nil {
+			var err error
+			if dbDesc, err = sqlbase.GetDatabaseDescFromID(ctx, txn, table.ParentID); err != nil {
+				return err
+			}
+		}
+		tableName := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(table.Name))
+		jobID, err := sql.CreateRowLevelTTLJob(
+			ctx, r.jobRegistry, txn, r.job.Payload().Username, table, tableName.String(),
+		)
+		if err != nil {
+			return errors.Wrapf(err, "creating the row-level TTL job of table %q", table.Name)
+		}
+		table.RowLevelTTL.JobID = jobID
+	}
+	return nil
+}
+
 // OnTerminal is part of the jobs.Resumer interface.
 func (r *restoreResumer) OnTerminal(
 	ctx context.Context, status jobs.Status, resultsCh chan<- tree.Datums,
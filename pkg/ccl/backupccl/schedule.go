@@ -0,0 +1,403 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl
+
+import (
+	"context"
+	"net/url"
+	"path"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
+	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
+)
+
+// scheduledBackupExecutorType is the executor type of the schedules created
+// by CREATE SCHEDULE FOR BACKUP.
+const scheduledBackupExecutorType = "scheduled-backup"
+
+const scheduleOptRetention = "retention"
+
+var scheduleOptionExpectValues = map[string]sql.KVStringOptValidate{
+	scheduleOptRetention: sql.KVStringOptRequireValue,
+}
+
+// backupNameFormat is the format of the names of the directories that the
+// backups of a schedule are taken into. It sorts chronologically.
+const backupNameFormat = "20060102-150405.00"
+
+// scheduledBackupPlanHook implements PlanHookFn.
+func scheduledBackupPlanHook(
+	_ context.Context, stmt tree.Statement, p sql.PlanHookState,
+) (sql.PlanHookRowFn, sqlbase.ResultColumns, []sql.PlanNode, bool, error) {
+	schedStmt, ok := stmt.(*tree.ScheduledBackup)
+	if !ok {
+		return nil, nil, nil, false, nil
+	}
+
+	nameFn, err := p.TypeAsString(schedStmt.ScheduleName, "CREATE SCHEDULE FOR BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	toFn, err := p.TypeAsString(schedStmt.To, "CREATE SCHEDULE FOR BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	recurrenceFn, err := p.TypeAsString(schedStmt.Recurrence, "CREATE SCHEDULE FOR BACKUP")
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	var fullBackupFn func() (string, error)
+	if schedStmt.FullBackup != nil {
+		fullBackupFn, err = p.TypeAsString(schedStmt.FullBackup, "CREATE SCHEDULE FOR BACKUP")
+		if err != nil {
+			return nil, nil, nil, false, err
+		}
+	}
+	backupOptsFn, err := p.TypeAsStringOpts(schedStmt.BackupOptions, backupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+	scheduleOptsFn, err := p.TypeAsStringOpts(schedStmt.ScheduleOptions, scheduleOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	header := sqlbase.ResultColumns{
+		{Name: "schedule_id", Typ: types.Int},
+		{Name: "name", Typ: types.String},
+		{Name: "next_run", Typ: types.TimestampTZ},
+	}
+
+	fn := func(ctx context.Context, _ []sql.PlanNode, resultsCh chan<- tree.Datums) error {
+		// TODO(dan): Move this span into sql.
+		ctx, span := tracing.ChildSpan(ctx, stmt.StatementTag())
+		defer tracing.FinishSpan(span)
+
+		if err := utilccl.CheckEnterpriseEnabled(
+			p.ExecCfg().Settings, p.ExecCfg().ClusterID(), p.ExecCfg().Organization(),
+			"CREATE SCHEDULE FOR BACKUP",
+		); err != nil {
+			return err
+		}
+
+		if err := p.RequireSuperUser(ctx, "CREATE SCHEDULE FOR BACKUP"); err != nil {
+			return err
+		}
+
+		name, err := nameFn()
+		if err != nil {
+			return err
+		}
+		to, err := toFn()
+		if err != nil {
+			return err
+		}
+		if _, err := storageccl.ExportStorageConfFromURI(to); err != nil {
+			return err
+		}
+		recurrence, err := recurrenceFn()
+		if err != nil {
+			return err
+		}
+		if _, err := jobs.NextScheduledRun(recurrence, timeutil.Now()); err != nil {
+			return err
+		}
+
+		var args ScheduledBackupArgs
+		if fullBackupFn != nil {
+			if args.FullBackupExpr, err = fullBackupFn(); err != nil {
+				return err
+			}
+			if _, err := jobs.NextScheduledRun(args.FullBackupExpr, timeutil.Now()); err != nil {
+				return err
+			}
+		}
+
+		scheduleOpts, err := scheduleOptsFn()
+		if err != nil {
+			return err
+		}
+		if s, ok := scheduleOpts[scheduleOptRetention]; ok {
+			d, err := tree.ParseDInterval(s)
+			if err != nil {
+				return errors.Wrapf(err, "invalid %s", scheduleOptRetention)
+			}
+			if args.Retention = time.Duration(d.Nanos()); args.Retention <= 0 {
+				return errors.Errorf("%s must be positive", scheduleOptRetention)
+			}
+		}
+
+		backupOpts, err := backupOptsFn()
+		if err != nil {
+			return err
+		}
+		targets, err := qualifyBackupTargets(p, schedStmt.Targets)
+		if err != nil {
+			return err
+		}
+		backupStmt := &tree.Backup{Targets: targets, To: tree.NewDString(to)}
+		for k, v := range backupOpts {
+			opt := tree.KVOption{Key: tree.Name(k)}
+			if v != "" {
+				opt.Value = tree.NewDString(v)
+			}
+			backupStmt.Options = append(backupStmt.Options, opt)
+		}
+		sort.Slice(backupStmt.Options, func(i, j int) bool {
+			return backupStmt.Options[i].Key < backupStmt.Options[j].Key
+		})
+		args.BackupStatement = tree.AsStringWithFlags(backupStmt, tree.FmtParsable)
+
+		argBytes, err := protoutil.Marshal(&args)
+		if err != nil {
+			return err
+		}
+		id, nextRun, err := jobs.CreateSchedule(
+			ctx, p.ExecCfg().InternalExecutor, p.ExtendedEvalContext().Txn,
+			name, p.User(), recurrence, scheduledBackupExecutorType, argBytes,
+		)
+		if err != nil {
+			return err
+		}
+		ts := tree.MakeDTimestampTZ(nextRun, time.Microsecond)
+		resultsCh <- tree.Datums{tree.NewDInt(tree.DInt(id)), tree.NewDString(name), ts}
+		return nil
+	}
+	return fn, header, nil, false, nil
+}
+
+// qualifyBackupTargets returns a copy of the targets in which the tables that
+// don't name their database are qualified with the current database, since
+// the backups of the schedule don't run in the session that created it.
+func qualifyBackupTargets(p sql.PlanHookState, targets tree.TargetList) (tree.TargetList, error) {
+	if targets.Databases != nil {
+		return targets, nil
+	}
+	qualified := tree.TargetList{Tables: make(tree.TablePatterns, len(targets.Tables))}
+	for i, pattern := range targets.Tables {
+		pattern, err := pattern.NormalizeTablePattern()
+		if err != nil {
+			return tree.TargetList{}, err
+		}
+		var prefix *tree.TableNamePrefix
+		switch t := pattern.(type) {
+		case *tree.TableName:
+			tn := *t
+			prefix, pattern = &tn.TableNamePrefix, &tn
+		case *tree.AllTablesSelector:
+			at := *t
+			prefix, pattern = &at.TableNamePrefix, &at
+		}
+		if prefix != nil && !prefix.ExplicitSchema {
+			db := p.SessionData().Database
+			if db == "" {
+				return tree.TargetList{}, errors.Errorf(
+					"no database specified for %s", tree.AsString(pattern))
+			}
+			prefix.CatalogName, prefix.ExplicitCatalog = tree.Name(db), true
+			prefix.SchemaName, prefix.ExplicitSchema = tree.PublicSchemaName, true
+		}
+		qualified.Tables[i] = pattern
+	}
+	return qualified, nil
+}
+
+// scheduledBackupExecutor runs the backups of the schedules created by CREATE
+// SCHEDULE FOR BACKUP. Every backup is taken into a new directory of the
+// collection named after the time it was taken at: full backups directly in
+// the collection and incremental backups in the directory of the full backup
+// they're based on.
+type scheduledBackupExecutor struct{}
+
+var _ jobs.ScheduledJobExecutor = scheduledBackupExecutor{}
+
+// ExecuteJob implements the jobs.ScheduledJobExecutor interface.
+func (scheduledBackupExecutor) ExecuteJob(
+	ctx context.Context,
+	ex sqlutil.InternalExecutor,
+	settings *cluster.Settings,
+	schedule *jobs.ScheduledJob,
+) ([]byte, error) {
+	var args ScheduledBackupArgs
+	if err := protoutil.Unmarshal(schedule.ExecutionArgs, &args); err != nil {
+		return nil, err
+	}
+	parsed, err := parser.ParseOne(args.BackupStatement)
+	if err != nil {
+		return nil, err
+	}
+	backupStmt, ok := parsed.AST.(*tree.Backup)
+	if !ok {
+		return nil, errors.Errorf("unexpected statement %s", args.BackupStatement)
+	}
+	collection, ok := backupStmt.To.(*tree.StrVal)
+	if !ok {
+		return nil, errors.Errorf("unexpected destination %s", backupStmt.To)
+	}
+
+	now := timeutil.Now()
+	full := true
+	if n := len(args.Chains); n > 0 && args.FullBackupExpr != "" {
+		last := timeutil.Unix(0, args.Chains[n-1].FullBackupMicros*int64(time.Microsecond))
+		nextFull, err := jobs.NextScheduledRun(args.FullBackupExpr, last)
+		if err != nil {
+			return nil, err
+		}
+		full = !nextFull.After(now)
+	}
+
+	var to string
+	if full {
+		to, err = appendPathToURI(collection.RawString(), now.Format(backupNameFormat))
+	} else {
+		chain := &args.Chains[len(args.Chains)-1]
+		to, err = appendPathToURI(chain.URIs[0], "incremental-"+now.Format(backupNameFormat))
+		backupStmt.IncrementalFrom = make(tree.Exprs, len(chain.URIs))
+		for i, uri := range chain.URIs {
+			backupStmt.IncrementalFrom[i] = tree.NewDString(uri)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	backupStmt.To = tree.NewDString(to)
+
+	log.Infof(ctx, "schedule %d: backing up into %s", schedule.ID, to)
+	if _, err := ex.Query(
+		ctx, "scheduled-backup", nil, /* txn */
+		tree.AsStringWithFlags(backupStmt, tree.FmtParsable),
+	); err != nil {
+		return nil, err
+	}
+	if full {
+		args.Chains = append(args.Chains, ScheduledBackupArgs_Chain{
+			URIs:             []string{to},
+			FullBackupMicros: now.UnixNano() / int64(time.Microsecond),
+		})
+	} else {
+		chain := &args.Chains[len(args.Chains)-1]
+		chain.URIs = append(chain.URIs, to)
+	}
+
+	// Prune the chains that were superseded by a newer full backup longer than
+	// the retention ago. A chain that can't be pruned is kept so that pruning
+	// it is retried on the next run.
+	var pruneErr error
+	if args.Retention > 0 {
+		var passphrase string
+		for _, opt := range backupStmt.Options {
+			if string(opt.Key) == backupOptEncPassphrase {
+				if s, ok := opt.Value.(*tree.StrVal); ok {
+					passphrase = s.RawString()
+				}
+			}
+		}
+		for len(args.Chains) > 1 {
+			superseded := timeutil.Unix(0, args.Chains[1].FullBackupMicros*int64(time.Microsecond))
+			if now.Sub(superseded) < args.Retention {
+				break
+			}
+			if pruneErr = pruneBackupChain(ctx, settings, args.Chains[0], passphrase); pruneErr != nil {
+				break
+			}
+			args.Chains = args.Chains[1:]
+		}
+	}
+
+	argBytes, err := protoutil.Marshal(&args)
+	if err != nil {
+		return nil, err
+	}
+	return argBytes, pruneErr
+}
+
+// pruneBackupChain deletes the files of the backups of a chain, newest first,
+// so that no backup is left without the backups it's based on.
+func pruneBackupChain(
+	ctx context.Context,
+	settings *cluster.Settings,
+	chain ScheduledBackupArgs_Chain,
+	passphrase string,
+) error {
+	var encryption *roachpb.FileEncryptionOptions
+	if passphrase != "" {
+		var err error
+		encryption, _, err = getEncryptionFromBase(ctx, chain.URIs[0], passphrase, settings)
+		if err != nil {
+			return err
+		}
+	}
+	for i := len(chain.URIs) - 1; i >= 0; i-- {
+		if err := deleteBackup(ctx, settings, chain.URIs[i], encryption); err != nil {
+			return errors.Wrapf(err, "deleting backup %s", chain.URIs[i])
+		}
+	}
+	return nil
+}
+
+// deleteBackup deletes the data files and then the descriptor of a backup.
+func deleteBackup(
+	ctx context.Context,
+	settings *cluster.Settings,
+	uri string,
+	encryption *roachpb.FileEncryptionOptions,
+) error {
+	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return err
+	}
+	defer exportStore.Close()
+	desc, err := readBackupDescriptor(ctx, exportStore, BackupDescriptorName, encryption)
+	if err != nil {
+		return err
+	}
+	for _, f := range desc.Files {
+		if err := exportStore.Delete(ctx, f.Path); err != nil {
+			return err
+		}
+	}
+	if encryption != nil {
+		if err := exportStore.Delete(ctx, BackupEncryptionInfoName); err != nil {
+			return err
+		}
+	}
+	return exportStore.Delete(ctx, BackupDescriptorName)
+}
+
+// appendPathToURI returns the URI of a sub-directory of the directory of uri,
+// preserving its query parameters.
+func appendPathToURI(uri, elem string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, elem)
+	return u.String(), nil
+}
+
+func init() {
+	sql.AddPlanHook(scheduledBackupPlanHook)
+	jobs.RegisterScheduledJobExecutor(scheduledBackupExecutorType, scheduledBackupExecutor{})
+}
@@ -0,0 +1,149 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package backupccl_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/ccl/backupccl"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestScheduledBackup(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer func(oldInterval time.Duration) {
+		jobs.DefaultScheduleInterval = oldInterval
+	}(jobs.DefaultScheduleInterval)
+	jobs.DefaultScheduleInterval = 10 * time.Millisecond
+
+	const numAccounts = 10
+	_, _, sqlDB, rawDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	// createSchedule runs a CREATE SCHEDULE statement and returns the ID of
+	// the schedule.
+	createSchedule := func(stmt string) int64 {
+		var id int64
+		var name string
+		var nextRun time.Time
+		sqlDB.QueryRow(t, stmt).Scan(&id, &name, &nextRun)
+		return id
+	}
+	// runSchedule makes the schedule due and waits for the run it triggers to
+	// finish.
+	runSchedule := func(id int64) {
+		sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET next_run = now() - '1s'::INTERVAL
+WHERE schedule_id = $1`, id)
+		testutils.SucceedsSoon(t, func() error {
+			var done bool
+			var lastError string
+			sqlDB.QueryRow(t, `SELECT next_run > now() AND running_node IS NULL, IFNULL(last_error, '')
+FROM system.scheduled_jobs WHERE schedule_id = $1`, id).Scan(&done, &lastError)
+			if !done {
+				return errors.New("schedule still due or running")
+			}
+			if lastError != "" {
+				t.Fatalf("unexpected error: %s", lastError)
+			}
+			return nil
+		})
+	}
+	// subdirs returns the names of the sub-directories of a directory of the
+	// nodelocal storage.
+	subdirs := func(dir string) []string {
+		files, err := ioutil.ReadDir(filepath.Join(rawDir, dir))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, f := range files {
+			if f.IsDir() {
+				names = append(names, f.Name())
+			}
+		}
+		return names
+	}
+
+	t.Run("incremental", func(t *testing.T) {
+		id := createSchedule(`CREATE SCHEDULE 'incremental' FOR BACKUP TABLE data.bank
+INTO 'nodelocal:///incremental' RECURRING '@daily' FULL BACKUP '@yearly'`)
+
+		runSchedule(id)
+		full := subdirs("incremental")
+		if len(full) != 1 {
+			t.Fatalf("expected one full backup, got %v", full)
+		}
+		sqlDB.Exec(t, `UPDATE data.bank SET balance = balance + 1`)
+		runSchedule(id)
+		if dirs := subdirs("incremental"); len(dirs) != 1 {
+			t.Fatalf("expected one full backup, got %v", dirs)
+		}
+		inc := subdirs(filepath.Join("incremental", full[0]))
+		if len(inc) != 1 || !strings.HasPrefix(inc[0], "incremental-") {
+			t.Fatalf("expected one incremental backup, got %v", inc)
+		}
+
+		fullURI := "nodelocal:///incremental/" + full[0]
+		sqlDB.Exec(t, `CREATE DATABASE restored`)
+		sqlDB.Exec(t, `RESTORE data.bank FROM $1, $2 WITH into_db = 'restored'`,
+			fullURI, fullURI+"/"+inc[0])
+		sqlDB.CheckQueryResults(t, `SELECT * FROM restored.bank ORDER BY id`,
+			sqlDB.QueryStr(t, `SELECT * FROM data.bank ORDER BY id`))
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		id := createSchedule(`CREATE SCHEDULE 'retention' FOR BACKUP DATABASE data
+INTO 'nodelocal:///retention' RECURRING '@daily'
+WITH SCHEDULE OPTIONS retention = '1 microsecond'`)
+
+		runSchedule(id)
+		runSchedule(id)
+		// The first backup was superseded by the second one and pruned.
+		dirs := subdirs("retention")
+		if len(dirs) != 2 {
+			t.Fatalf("expected two backup directories, got %v", dirs)
+		}
+		for i, dir := range dirs {
+			_, err := os.Stat(filepath.Join(rawDir, "retention", dir, backupccl.BackupDescriptorName))
+			if pruned := os.IsNotExist(err); pruned != (i == 0) {
+				t.Fatalf("%s: expected pruned=%t, got %v", dir, i == 0, err)
+			}
+		}
+	})
+
+	t.Run("show", func(t *testing.T) {
+		sqlDB.CheckQueryResults(t, `SELECT name, executor_type, recurrence FROM [SHOW SCHEDULES]`,
+			[][]string{
+				{"incremental", "scheduled-backup", "@daily"},
+				{"retention", "scheduled-backup", "@daily"},
+			})
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		sqlDB.ExpectErr(t, `invalid schedule "nope"`,
+			`CREATE SCHEDULE 'e' FOR BACKUP DATABASE data INTO 'nodelocal:///e' RECURRING 'nope'`)
+		sqlDB.ExpectErr(t, `invalid schedule "nope"`,
+			`CREATE SCHEDULE 'e' FOR BACKUP DATABASE data INTO 'nodelocal:///e' RECURRING '@daily'
+FULL BACKUP 'nope'`)
+		sqlDB.ExpectErr(t, `invalid option "foo"`,
+			`CREATE SCHEDULE 'e' FOR BACKUP DATABASE data INTO 'nodelocal:///e' RECURRING '@daily'
+WITH SCHEDULE OPTIONS foo = 'bar'`)
+		sqlDB.ExpectErr(t, `retention must be positive`,
+			`CREATE SCHEDULE 'e' FOR BACKUP DATABASE data INTO 'nodelocal:///e' RECURRING '@daily'
+WITH SCHEDULE OPTIONS retention = '-1 hour'`)
+	})
+}
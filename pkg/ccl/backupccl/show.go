@@ -9,20 +9,33 @@
 package backupccl
 
 import (
+	"bytes"
 	"context"
+	"io/ioutil"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/ccl/storageccl"
 	"github.com/cockroachdb/cockroach/pkg/ccl/utilccl"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
+	"github.com/cockroachdb/errors"
 )
 
+const showBackupOptCheckFiles = "check_files"
+
+var showBackupOptionExpectValues = map[string]sql.KVStringOptValidate{
+	backupOptEncPassphrase:  sql.KVStringOptRequireValue,
+	showBackupOptCheckFiles: sql.KVStringOptRequireNoValue,
+}
+
 // showBackupPlanHook implements PlanHookFn.
 func showBackupPlanHook(
 	ctx context.Context, stmt tree.Statement, p sql.PlanHookState,
@@ -47,11 +60,30 @@ func showBackupPlanHook(
 		return nil, nil, nil, false, err
 	}
 
+	optsFn, err := p.TypeAsStringOpts(backup.Options, showBackupOptionExpectValues)
+	if err != nil {
+		return nil, nil, nil, false, err
+	}
+
+	// The option changes the columns of the result, so it's looked up before
+	// the options are evaluated.
+	checkFiles := false
+	for _, opt := range backup.Options {
+		if string(opt.Key) == showBackupOptCheckFiles {
+			checkFiles = true
+		}
+	}
+
 	var shower backupShower
-	switch backup.Details {
-	case tree.BackupRangeDetails:
+	switch {
+	case checkFiles && backup.Details != tree.BackupDefaultDetails:
+		return nil, nil, nil, false, errors.Errorf(
+			"%q option cannot be used with SHOW BACKUP RANGES or FILES", showBackupOptCheckFiles)
+	case checkFiles:
+		shower = backupShowerCheckFiles
+	case backup.Details == tree.BackupRangeDetails:
 		shower = backupShowerRanges
-	case tree.BackupFileDetails:
+	case backup.Details == tree.BackupFileDetails:
 		shower = backupShowerFiles
 	default:
 		shower = backupShowerDefault
@@ -66,12 +98,31 @@ func showBackupPlanHook(
 		if err != nil {
 			return err
 		}
-		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings)
+		opts, err := optsFn()
+		if err != nil {
+			return err
+		}
+
+		var encryption *roachpb.FileEncryptionOptions
+		if passphrase, ok := opts[backupOptEncPassphrase]; ok {
+			encryption, _, err = getEncryptionFromBase(ctx, str, passphrase, p.ExecCfg().Settings)
+			if err != nil {
+				return err
+			}
+		}
+
+		desc, err := ReadBackupDescriptorFromURI(ctx, str, p.ExecCfg().Settings, encryption)
 		if err != nil {
 			return err
 		}
 
-		for _, row := range shower.fn(desc) {
+		rows := shower.fn(desc)
+		if checkFiles {
+			if rows, err = checkBackupFiles(ctx, p.ExecCfg().Settings, str, desc, encryption); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
 			select {
 			case <-ctx.Done():
 				return ctx.Err()
@@ -193,6 +244,130 @@ var backupShowerFiles = backupShower{
 	},
 }
 
+// backupShowerCheckFiles is the header of SHOW BACKUP WITH check_files. Its
+// rows are produced by checkBackupFiles, which needs more than the descriptor.
+var backupShowerCheckFiles = backupShower{
+	header: sqlbase.ResultColumns{
+		{Name: "path", Typ: types.String},
+		{Name: "start_pretty", Typ: types.String},
+		{Name: "end_pretty", Typ: types.String},
+		{Name: "size_bytes", Typ: types.Int},
+		{Name: "status", Typ: types.String},
+		{Name: "error", Typ: types.String},
+	},
+
+	fn: func(BackupDescriptor) []tree.Datums { return nil },
+}
+
+// The statuses of the files of a backup reported by SHOW BACKUP WITH
+// check_files.
+const (
+	backupFileOK      = "ok"
+	backupFileMissing = "missing"
+	backupFileCorrupt = "corrupt"
+)
+
+// checkBackupFiles reads every file of a backup and returns a row per file
+// that reports whether the file can be read, matches its checksum and only
+// contains keys in its span. Missing or corrupt files are reported in their
+// rows; any other failure to read a file is returned as an error.
+func checkBackupFiles(
+	ctx context.Context,
+	settings *cluster.Settings,
+	uri string,
+	desc BackupDescriptor,
+	encryption *roachpb.FileEncryptionOptions,
+) ([]tree.Datums, error) {
+	exportStore, err := storageccl.ExportStorageFromURI(ctx, uri, settings)
+	if err != nil {
+		return nil, err
+	}
+	defer exportStore.Close()
+
+	rows := make([]tree.Datums, 0, len(desc.Files))
+	for _, file := range desc.Files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		size, status, checkErr, err := checkBackupFile(ctx, exportStore, file, encryption)
+		if err != nil {
+			return nil, err
+		}
+		errDatum := tree.DNull
+		if checkErr != nil {
+			errDatum = tree.NewDString(checkErr.Error())
+		}
+		rows = append(rows, tree.Datums{
+			tree.NewDString(file.Path),
+			tree.NewDString(file.Span.Key.String()),
+			tree.NewDString(file.Span.EndKey.String()),
+			tree.NewDInt(tree.DInt(size)),
+			tree.NewDString(status),
+			errDatum,
+		})
+	}
+	return rows, nil
+}
+
+// checkBackupFile reads one file of a backup and returns its size and status,
+// along with the error that explains the status, if it isn't ok. A file is
+// only reported as missing if the storage says it doesn't exist; other
+// errors reading it, which may well be transient, are returned as err.
+func checkBackupFile(
+	ctx context.Context,
+	exportStore storageccl.ExportStorage,
+	file BackupDescriptor_File,
+	encryption *roachpb.FileEncryptionOptions,
+) (size int64, status string, checkErr error, err error) {
+	r, err := exportStore.ReadFile(ctx, file.Path)
+	if err != nil {
+		if errors.Is(err, storageccl.ErrFileDoesNotExist) {
+			return 0, backupFileMissing, err, nil
+		}
+		return 0, "", nil, errors.Wrapf(err, "reading %s", file.Path)
+	}
+	data, err := ioutil.ReadAll(r)
+	r.Close()
+	if err != nil {
+		return 0, "", nil, errors.Wrapf(err, "reading %s", file.Path)
+	}
+	size = int64(len(data))
+
+	if encryption != nil {
+		if data, err = storageccl.DecryptFile(data, encryption.Key); err != nil {
+			return size, backupFileCorrupt, err, nil
+		}
+	}
+	if len(file.Sha512) > 0 {
+		checksum, err := storageccl.SHA512ChecksumData(data)
+		if err != nil {
+			return size, backupFileCorrupt, err, nil
+		}
+		if !bytes.Equal(checksum, file.Sha512) {
+			return size, backupFileCorrupt, errors.New("checksum mismatch"), nil
+		}
+	}
+
+	iter, err := engine.NewMemSSTIterator(data, false /* verify */)
+	if err != nil {
+		return size, backupFileCorrupt, err, nil
+	}
+	defer iter.Close()
+	for iter.Seek(engine.NilKey); ; iter.Next() {
+		ok, err := iter.Valid()
+		if err != nil {
+			return size, backupFileCorrupt, err, nil
+		}
+		if !ok {
+			break
+		}
+		if key := iter.UnsafeKey().Key; !file.Span.ContainsKey(key) {
+			return size, backupFileCorrupt, errors.Errorf("key %s outside of span %s", key, file.Span), nil
+		}
+	}
+	return size, backupFileOK, nil, nil
+}
+
 func init() {
 	sql.AddPlanHook(showBackupPlanHook)
 }
@@ -11,7 +11,11 @@ package backupccl_test
 import (
 	"database/sql/driver"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"regexp"
+	"strings"
 	"testing"
 	"time"
 
@@ -111,3 +115,52 @@ func TestShowBackup(t *testing.T) {
 		t.Fatalf("expected 2 files, but got %d", len(pathRows))
 	}
 }
+
+func TestShowBackupCheckFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	const numAccounts = 11
+	_, _, sqlDB, rawDir, cleanupFn := backupRestoreTestSetup(t, singleNode, numAccounts, initNone)
+	defer cleanupFn()
+
+	sqlDB.Exec(t, `BACKUP data.bank TO $1`, localFoo)
+	const query = `SELECT status, IFNULL(error, '') FROM [SHOW BACKUP $1 WITH check_files]`
+	rows := sqlDB.QueryStr(t,
+		`SELECT path, status, IFNULL(error, '') FROM [SHOW BACKUP $1 WITH check_files]`, localFoo)
+	if len(rows) == 0 {
+		t.Fatal("expected at least one file")
+	}
+	for _, row := range rows {
+		if row[1] != "ok" {
+			t.Fatalf("expected file %s to be ok, got %s: %s", row[0], row[1], row[2])
+		}
+	}
+
+	path := filepath.Join(rawDir, "foo", rows[0][0])
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data[len(data)/2] ^= 0xff
+	if err := ioutil.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus := func(expected, expectedErr string) {
+		t.Helper()
+		var status, checkErr string
+		sqlDB.QueryRow(t, query+` WHERE path = $2`, localFoo, rows[0][0]).Scan(&status, &checkErr)
+		if status != expected || !strings.Contains(checkErr, expectedErr) {
+			t.Fatalf("expected %s file with error %q, got %s file with error %q",
+				expected, expectedErr, status, checkErr)
+		}
+	}
+	checkStatus("corrupt", "checksum mismatch")
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	checkStatus("missing", "")
+
+	sqlDB.ExpectErr(t, `"check_files" option cannot be used with SHOW BACKUP RANGES or FILES`,
+		`SHOW BACKUP FILES $1 WITH check_files`, localFoo)
+}
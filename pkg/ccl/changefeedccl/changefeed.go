@@ -56,16 +56,88 @@ type emitEntry struct {
 // kvsToRows gets changed kvs from a closure and converts them into sql rows. It
 // returns a closure that may be repeatedly called to advance the changefeed.
 // The returned closure is not threadsafe.
+//
+// If needsPrevValues is true for the changefeed, every row is accompanied by its
+// previous value, which is provided along with the change by the rangefeed.
 func kvsToRows(
 	leaseMgr *sql.LeaseManager,
 	details jobspb.ChangefeedDetails,
 	inputFn func(context.Context) (bufferEntry, error),
 ) func(context.Context) ([]emitEntry, error) {
+	withDiff := needsPrevValues(details.Opts)
 	rfCache := newRowFetcherCache(leaseMgr)
 
 	var kvs row.SpanKVFetcher
+	decodeKV := func(
+		ctx context.Context, kv roachpb.KeyValue, schemaTimestamp hlc.Timestamp,
+	) (sqlbase.EncDatumRow, *sqlbase.TableDescriptor, error) {
+		// Reuse kvs to save allocations.
+		kvs.KVs = kvs.KVs[:0]
+
+		desc, err := rfCache.TableDescForKey(ctx, kv.Key, schemaTimestamp)
+		if err != nil {
+			return nil, nil, err
+		}
+		rf, err := rfCache.RowFetcherForTableDesc(desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		kvs.KVs = append(kvs.KVs, kv)
+		if err := rf.StartScanFrom(ctx, &kvs); err != nil {
+			return nil, nil, err
+		}
+		datums, tableDesc, _, err := rf.NextRow(ctx)
+		if err != nil || datums == nil {
+			return nil, nil, err
+		}
+		return append(sqlbase.EncDatumRow(nil), datums...), tableDesc, nil
+	}
+
+	// setPrevForKV sets the previous value of the row in kv on the given
+	// entries, all of which were decoded from kv.
+	setPrevForKV := func(
+		ctx context.Context,
+		entries []emitEntry,
+		kv roachpb.KeyValue,
+		prevVal roachpb.Value,
+		schemaTimestamp hlc.Timestamp,
+	) error {
+		var prevValue roachpb.Value
+		switch {
+		case schemaTimestamp == details.StatementTime:
+			// This kv is emitted by the initial scan, which only outputs the
+			// latest version of every row, so the row has no previous value.
+			return nil
+		case schemaTimestamp != kv.Value.Timestamp:
+			// This kv is emitted by a backfill after a schema change, so the row
+			// itself didn't change and its previous value is the same value
+			// interpreted with the table descriptor preceding the schema change.
+			prevValue = kv.Value
+		default:
+			prevValue = prevVal
+		}
+		if !prevValue.IsPresent() {
+			// The row didn't exist before this change.
+			return nil
+		}
+		prevKV := roachpb.KeyValue{Key: kv.Key, Value: prevValue}
+		prevDatums, prevTableDesc, err := decodeKV(ctx, prevKV, schemaTimestamp.Prev())
+		if err != nil {
+			return err
+		}
+		for i := range entries {
+			entries[i].row.prevDatums = prevDatums
+			entries[i].row.prevTableDesc = prevTableDesc
+		}
+		return nil
+	}
+
 	appendEmitEntryForKV := func(
-		ctx context.Context, output []emitEntry, kv roachpb.KeyValue, schemaTimestamp hlc.Timestamp,
+		ctx context.Context,
+		output []emitEntry,
+		kv roachpb.KeyValue,
+		prevVal roachpb.Value,
+		schemaTimestamp hlc.Timestamp,
 		bufferGetTimestamp time.Time,
 	) ([]emitEntry, error) {
 		// Reuse kvs to save allocations.
@@ -93,6 +165,7 @@ func kvsToRows(
 			return nil, err
 		}
 
+		firstEntry := len(output)
 		for {
 			var r emitEntry
 			r.bufferGetTimestamp = bufferGetTimestamp
@@ -108,6 +181,11 @@ func kvsToRows(
 			r.row.updated = schemaTimestamp
 			output = append(output, r)
 		}
+		if withDiff {
+			if err := setPrevForKV(ctx, output[firstEntry:], kv, prevVal, schemaTimestamp); err != nil {
+				return nil, err
+			}
+		}
 		return output, nil
 	}
 
@@ -129,7 +207,7 @@ func kvsToRows(
 					schemaTimestamp = input.schemaTimestamp
 				}
 				output, err = appendEmitEntryForKV(
-					ctx, output, input.kv, schemaTimestamp, input.bufferGetTimestamp)
+					ctx, output, input.kv, input.prevVal, schemaTimestamp, input.bufferGetTimestamp)
 				if err != nil {
 					return nil, err
 				}
@@ -147,6 +225,15 @@ func kvsToRows(
 	}
 }
 
+// needsPrevValues returns whether a changefeed with the given options needs the
+// previous value of every changed row, which is the case if it emits them with
+// the `diff` option or evaluates the `filter` option on them.
+func needsPrevValues(opts map[string]string) bool {
+	_, withDiff := opts[optDiff]
+	_, withFilter := opts[optFilter]
+	return withDiff || withFilter
+}
+
 // emitEntries connects to a sink, receives rows from a closure, and repeatedly
 // emits them to the sink. It returns a closure that may be repeatedly called to
 // advance the changefeed and which returns span-level resolved timestamp
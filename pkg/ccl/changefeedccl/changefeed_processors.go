@@ -52,6 +52,8 @@ type changeAggregator struct {
 
 	// encoder is the Encoder to use for key and value serialization.
 	encoder Encoder
+	// filter, if non-nil, restricts the columns and rows that are emitted.
+	filter *rowFilter
 	// sink is the Sink to write rows to. Resolved timestamps are never written
 	// by changeAggregator.
 	sink Sink
@@ -106,6 +108,9 @@ func newChangeAggregatorProcessor(
 	if ca.encoder, err = getEncoder(ca.spec.Feed.Opts); err != nil {
 		return nil, err
 	}
+	if ca.filter, err = makeRowFilter(ca.spec.Feed.Opts, flowCtx.NewEvalCtx()); err != nil {
+		return nil, err
+	}
 
 	return ca, nil
 }
@@ -179,6 +184,9 @@ func (ca *changeAggregator) Start(ctx context.Context) context.Context {
 		spans, ca.spec.Feed, initialHighWater, buf, leaseMgr, metrics, ca.pollerMemMon,
 	)
 	rowsFn := kvsToRows(leaseMgr, ca.spec.Feed, buf.Get)
+	if ca.filter != nil {
+		rowsFn = filterRows(ca.filter, rowsFn)
+	}
 
 	ca.tickFn = emitEntries(
 		ca.flowCtx.Settings, ca.spec.Feed, spans, ca.encoder, ca.sink, rowsFn, knobs, metrics)
@@ -48,9 +48,12 @@ type envelopeType string
 type formatType string
 
 const (
+	optColumns                 = `columns`
 	optConfluentSchemaRegistry = `confluent_schema_registry`
 	optCursor                  = `cursor`
+	optDiff                    = `diff`
 	optEnvelope                = `envelope`
+	optFilter                  = `filter`
 	optFormat                  = `format`
 	optKeyInValue              = `key_in_value`
 	optResolvedTimestamps      = `resolved`
@@ -61,17 +64,28 @@ const (
 	optEnvelopeDeprecatedRow envelopeType = `deprecated_row`
 	optEnvelopeWrapped       envelopeType = `wrapped`
 
-	optFormatJSON formatType = `json`
-	optFormatAvro formatType = `experimental_avro`
+	optFormatJSON     formatType = `json`
+	optFormatAvro     formatType = `experimental_avro`
+	optFormatProtobuf formatType = `protobuf`
+	optFormatCSV      formatType = `csv`
 
+	sinkParamBatchInterval    = `batch_interval`
+	sinkParamBatchSize        = `batch_size`
 	sinkParamCACert           = `ca_cert`
+	sinkParamClientCert       = `client_cert`
+	sinkParamClientKey        = `client_key`
 	sinkParamFileSize         = `file_size`
+	sinkParamInitialBackoff   = `initial_backoff`
+	sinkParamMaxBackoff       = `max_backoff`
+	sinkParamMaxRetries       = `max_retries`
+	sinkParamRequestTimeout   = `request_timeout`
 	sinkParamSchemaTopic      = `schema_topic`
 	sinkParamTLSEnabled       = `tls_enabled`
 	sinkParamTopicPrefix      = `topic_prefix`
 	sinkSchemeBuffer          = ``
 	sinkSchemeExperimentalSQL = `experimental-sql`
 	sinkSchemeKafka           = `kafka`
+	sinkSchemeWebhookHTTPS    = `webhook-https`
 	sinkParamSASLEnabled      = `sasl_enabled`
 	sinkParamSASLHandshake    = `sasl_handshake`
 	sinkParamSASLUser         = `sasl_user`
@@ -79,9 +93,12 @@ const (
 )
 
 var changefeedOptionExpectValues = map[string]sql.KVStringOptValidate{
+	optColumns:                 sql.KVStringOptRequireValue,
 	optConfluentSchemaRegistry: sql.KVStringOptRequireValue,
 	optCursor:                  sql.KVStringOptRequireValue,
+	optDiff:                    sql.KVStringOptRequireNoValue,
 	optEnvelope:                sql.KVStringOptRequireValue,
+	optFilter:                  sql.KVStringOptRequireValue,
 	optFormat:                  sql.KVStringOptRequireValue,
 	optKeyInValue:              sql.KVStringOptRequireNoValue,
 	optResolvedTimestamps:      sql.KVStringOptAny,
@@ -229,14 +246,15 @@ func changefeedPlanHook(
 		//   and `format` if the user didn't specify them.
 		// - Then `getEncoder` is run to return any configuration errors.
 		// - Then the changefeed is opted in to `optKeyInValue` for any cloud
-		//   storage sink. Kafka etc have a key and value field in each message but
-		//   cloud storage sinks don't have anywhere to put the key. So if the key
-		//   is not in the value, then for DELETEs there is no way to recover which
-		//   key was deleted. We could make the user explicitly pass this option for
-		//   every cloud storage sink and error if they don't, but that seems
-		//   user-hostile for insufficient reason. We can't do this any earlier,
-		//   because we might return errors about `key_in_value` being incompatible
-		//   which is confusing when the user didn't type that option.
+		//   storage sink with the JSON format. Kafka etc have a key and value
+		//   field in each message but cloud storage sinks don't have anywhere to
+		//   put the key. So if the key is not in the value, then for DELETEs there
+		//   is no way to recover which key was deleted. We could make the user
+		//   explicitly pass this option for every cloud storage sink and error if
+		//   they don't, but that seems user-hostile for insufficient reason. We
+		//   can't do this any earlier, because we might return errors about
+		//   `key_in_value` being incompatible which is confusing when the user
+		//   didn't type that option.
 		// - Finally, we create a "canary" sink to test sink configuration and
 		//   connectivity. This has to go last because it is strange to return sink
 		//   connectivity errors before we've finished validating all the other
@@ -257,7 +275,10 @@ func changefeedPlanHook(
 		if _, err := getEncoder(details.Opts); err != nil {
 			return err
 		}
-		if isCloudStorageSink(parsedSink) {
+		if err := validateRowFilter(p, details.Opts, targetDescs); err != nil {
+			return err
+		}
+		if isCloudStorageSink(parsedSink) && formatType(details.Opts[optFormat]) == optFormatJSON {
 			details.Opts[optKeyInValue] = ``
 		}
 
@@ -380,8 +401,16 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 		details.Opts[optEnvelope] = string(optEnvelopeRow)
 	case optEnvelopeKeyOnly:
 		details.Opts[optEnvelope] = string(optEnvelopeKeyOnly)
-	case ``, optEnvelopeWrapped:
+	case optEnvelopeWrapped:
 		details.Opts[optEnvelope] = string(optEnvelopeWrapped)
+	case ``:
+		// CSV records have nowhere to put the wrapping, so they default to
+		// envelope=row.
+		if formatType(details.Opts[optFormat]) == optFormatCSV {
+			details.Opts[optEnvelope] = string(optEnvelopeRow)
+		} else {
+			details.Opts[optEnvelope] = string(optEnvelopeWrapped)
+		}
 	default:
 		return jobspb.ChangefeedDetails{}, errors.Errorf(
 			`unknown %s: %s`, optEnvelope, details.Opts[optEnvelope])
@@ -390,7 +419,7 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	switch formatType(details.Opts[optFormat]) {
 	case ``, optFormatJSON:
 		details.Opts[optFormat] = string(optFormatJSON)
-	case optFormatAvro:
+	case optFormatAvro, optFormatProtobuf, optFormatCSV:
 		// No-op.
 	default:
 		return jobspb.ChangefeedDetails{}, errors.Errorf(
@@ -400,6 +429,25 @@ func validateDetails(details jobspb.ChangefeedDetails) (jobspb.ChangefeedDetails
 	return details, nil
 }
 
+// validateRowFilter checks that the `columns` and `filter` options, if any,
+// can be resolved for every target table.
+func validateRowFilter(
+	p sql.PlanHookState, opts map[string]string, targetDescs []sqlbase.Descriptor,
+) error {
+	f, err := makeRowFilter(opts, &p.ExtendedEvalContext().EvalContext)
+	if err != nil || f == nil {
+		return err
+	}
+	for _, desc := range targetDescs {
+		if tableDesc := desc.GetTable(); tableDesc != nil {
+			if _, err := f.forTable(tableDesc); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 func validateChangefeedTable(
 	targets jobspb.ChangefeedTargets, tableDesc *sqlbase.TableDescriptor,
 ) error {
@@ -127,6 +127,120 @@ func TestChangefeedEnvelope(t *testing.T) {
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
 
+func TestChangefeedDiff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, b STRING)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'initial')`)
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (0, 'updated')`)
+
+		foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH diff`)
+		defer closeFeed(t, foo)
+
+		// 'initial' is skipped because only the latest value ('updated') is
+		// emitted by the initial scan.
+		assertPayloads(t, foo, []string{
+			`foo: [0]->{"after": {"a": 0, "b": "updated"}, "before": null}`,
+		})
+
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'a')`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "a"}, "before": null}`,
+		})
+		sqlDB.Exec(t, `UPSERT INTO foo VALUES (1, 'b')`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "b"}, "before": {"a": 1, "b": "a"}}`,
+		})
+		sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": null, "before": {"a": 1, "b": "b"}}`,
+		})
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (1, 'c')`)
+		assertPayloads(t, foo, []string{
+			`foo: [1]->{"after": {"a": 1, "b": "c"}, "before": null}`,
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
+func TestChangefeedColumnsAndFilter(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	testFn := func(t *testing.T, db *gosql.DB, f cdctest.TestFeedFactory) {
+		sqlDB := sqlutils.MakeSQLRunner(db)
+		sqlDB.Exec(t, `CREATE TABLE foo (a INT PRIMARY KEY, region STRING, email STRING, b INT)`)
+		sqlDB.Exec(t, `INSERT INTO foo VALUES (0, 'eu', 'x@example.com', 0), (1, 'us', 'y@example.com', 1)`)
+
+		t.Run(`columns`, func(t *testing.T) {
+			// The primary key column is always emitted.
+			foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH columns='region, b'`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{
+				`foo: [0]->{"after": {"a": 0, "b": 0, "region": "eu"}}`,
+				`foo: [1]->{"after": {"a": 1, "b": 1, "region": "us"}}`,
+			})
+		})
+		t.Run(`filter`, func(t *testing.T) {
+			foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH filter=$1, columns='region'`,
+				`region = 'eu' AND b < 10`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{
+				`foo: [0]->{"after": {"a": 0, "region": "eu"}}`,
+			})
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (2, 'us', 'z@example.com', 2)`)
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (3, 'eu', 'w@example.com', 3)`)
+			sqlDB.Exec(t, `INSERT INTO foo VALUES (4, 'eu', NULL, 4)`)
+			assertPayloads(t, foo, []string{
+				`foo: [3]->{"after": {"a": 3, "region": "eu"}}`,
+				`foo: [4]->{"after": {"a": 4, "region": "eu"}}`,
+			})
+			// A row that stops matching is emitted as a deletion, while changes to
+			// rows that didn't match before aren't emitted at all.
+			sqlDB.Exec(t, `UPSERT INTO foo VALUES (0, 'eu', 'x@example.com', 10)`)
+			sqlDB.Exec(t, `UPDATE foo SET b = 12 WHERE a = 0`)
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 2`)
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 3`)
+			assertPayloads(t, foo, []string{
+				`foo: [0]->{"after": null}`,
+				`foo: [3]->{"after": null}`,
+			})
+			// A row that starts matching again is emitted.
+			sqlDB.Exec(t, `UPDATE foo SET b = 0 WHERE a = 0`)
+			assertPayloads(t, foo, []string{
+				`foo: [0]->{"after": {"a": 0, "region": "eu"}}`,
+			})
+		})
+		t.Run(`filter,diff`, func(t *testing.T) {
+			foo := feed(t, f, `CREATE CHANGEFEED FOR foo WITH diff, filter=$1, columns='b'`,
+				`region = 'eu'`)
+			defer closeFeed(t, foo)
+			assertPayloads(t, foo, []string{
+				`foo: [0]->{"after": {"a": 0, "b": 0}, "before": null}`,
+				`foo: [4]->{"after": {"a": 4, "b": 4}, "before": null}`,
+			})
+			sqlDB.Exec(t, `UPDATE foo SET b = 5 WHERE a = 4`)
+			assertPayloads(t, foo, []string{
+				`foo: [4]->{"after": {"a": 4, "b": 5}, "before": {"a": 4, "b": 4}}`,
+			})
+			// Deletions are filtered on their previous value.
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 1`)
+			sqlDB.Exec(t, `UPDATE foo SET region = 'us' WHERE a = 4`)
+			sqlDB.Exec(t, `DELETE FROM foo WHERE a = 0`)
+			assertPayloads(t, foo, []string{
+				`foo: [4]->{"after": null, "before": {"a": 4, "b": 5}}`,
+				`foo: [0]->{"after": null, "before": {"a": 0, "b": 0}}`,
+			})
+		})
+	}
+
+	t.Run(`sinkless`, sinklessTest(testFn))
+	t.Run(`enterprise`, enterpriseTest(testFn))
+}
+
 func TestChangefeedMultiTable(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -1428,12 +1542,29 @@ func TestChangefeedErrors(t *testing.T) {
 		`kafka://nope`,
 	)
 
+	// The csv format only supports envelope=row, which is its default.
+	sqlDB.ExpectErr(
+		t, `envelope=wrapped is not supported with format=csv`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='csv', envelope='wrapped'`,
+		`kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `WITH option confluent_schema_registry is required for format=protobuf`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='protobuf'`,
+		`kafka://nope`,
+	)
+
 	// The cloudStorageSink is particular about the options it will work with.
 	sqlDB.ExpectErr(
 		t, `this sink is incompatible with format=experimental_avro`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='experimental_avro', confluent_schema_registry=$2`,
 		`experimental-nodelocal:///bar`, `schemareg-nope`,
 	)
+	sqlDB.ExpectErr(
+		t, `this sink is incompatible with format=protobuf`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH format='protobuf', confluent_schema_registry=$2`,
+		`experimental-nodelocal:///bar`, `schemareg-nope`,
+	)
 	sqlDB.ExpectErr(
 		t, `this sink is incompatible with envelope=key_only`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH envelope='key_only'`,
@@ -1449,6 +1580,34 @@ func TestChangefeedErrors(t *testing.T) {
 		t, `key_in_value is only usable with envelope=wrapped`,
 		`CREATE CHANGEFEED FOR foo INTO $1 WITH key_in_value, envelope='row'`, `kafka://nope`,
 	)
+
+	// WITH columns and filter are resolved against every target table.
+	sqlDB.ExpectErr(
+		t, `table foo: column "nope" does not exist`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH columns='a, nope'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `columns must be a list of column names`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH columns='a + 1'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `table foo: argument of filter must be type bool, not type int`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH filter='a + 1'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `table foo: column "nope" does not exist`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH filter='nope > 1'`, `kafka://nope`,
+	)
+
+	// WITH diff requires envelope=wrapped
+	sqlDB.ExpectErr(
+		t, `diff is only usable with envelope=wrapped`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH diff, envelope='key_only'`, `kafka://nope`,
+	)
+	sqlDB.ExpectErr(
+		t, `diff is only usable with envelope=wrapped`,
+		`CREATE CHANGEFEED FOR foo INTO $1 WITH diff, envelope='row'`, `kafka://nope`,
+	)
 }
 
 func TestChangefeedPermissions(t *testing.T) {
@@ -47,6 +47,13 @@ type encodeRow struct {
 	// tableDesc is a TableDescriptor for the table containing `datums`.
 	// It's valid for interpreting the row at `updated`.
 	tableDesc *sqlbase.TableDescriptor
+	// prevDatums is the old value of a changed table row. It is only set if
+	// the previous values are needed (see needsPrevValues) and is nil if the row
+	// didn't exist before this change.
+	prevDatums sqlbase.EncDatumRow
+	// prevTableDesc is a TableDescriptor for the table containing `prevDatums`.
+	// It's valid for interpreting the row at `updated.Prev()`.
+	prevTableDesc *sqlbase.TableDescriptor
 }
 
 // Encoder turns a row into a serialized changefeed key, value, or resolved
@@ -74,6 +81,10 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 		return makeJSONEncoder(opts)
 	case optFormatAvro:
 		return newConfluentAvroEncoder(opts)
+	case optFormatProtobuf:
+		return newProtobufEncoder(opts)
+	case optFormatCSV:
+		return makeCSVEncoder(opts)
 	default:
 		return nil, errors.Errorf(`unknown %s: %s`, optFormat, opts[optFormat])
 	}
@@ -84,7 +95,7 @@ func getEncoder(opts map[string]string) (Encoder, error) {
 // to its value. Updated timestamps in rows and resolved timestamp payloads are
 // stored in a sub-object under the `__crdb__` key in the top-level JSON object.
 type jsonEncoder struct {
-	updatedField, wrapped, keyOnly, keyInValue bool
+	updatedField, beforeField, wrapped, keyOnly, keyInValue bool
 
 	alloc sqlbase.DatumAlloc
 	buf   bytes.Buffer
@@ -98,6 +109,11 @@ func makeJSONEncoder(opts map[string]string) (*jsonEncoder, error) {
 		wrapped: envelopeType(opts[optEnvelope]) == optEnvelopeWrapped,
 	}
 	_, e.updatedField = opts[optUpdatedTimestamps]
+	_, e.beforeField = opts[optDiff]
+	if e.beforeField && !e.wrapped {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			optDiff, optEnvelope, optEnvelopeWrapped)
+	}
 	_, e.keyInValue = opts[optKeyInValue]
 	if e.keyInValue && !e.wrapped {
 		return nil, errors.Errorf(`%s is only usable with %s=%s`,
@@ -150,19 +166,10 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 
 	var after map[string]interface{}
 	if !row.deleted {
-		columns := row.tableDesc.Columns
-		after = make(map[string]interface{}, len(columns))
-		for i := range columns {
-			col := &columns[i]
-			datum := row.datums[i]
-			if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
-				return nil, err
-			}
-			var err error
-			after[col.Name], err = tree.AsJSON(datum.Datum)
-			if err != nil {
-				return nil, err
-			}
+		var err error
+		after, err = e.encodeColumns(row.tableDesc, row.datums)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -173,6 +180,16 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		} else {
 			jsonEntries = map[string]interface{}{`after`: nil}
 		}
+		if e.beforeField {
+			jsonEntries[`before`] = nil
+			if row.prevDatums != nil {
+				before, err := e.encodeColumns(row.prevTableDesc, row.prevDatums)
+				if err != nil {
+					return nil, err
+				}
+				jsonEntries[`before`] = before
+			}
+		}
 		if e.keyInValue {
 			keyEntries, err := e.encodeKeyRaw(row)
 			if err != nil {
@@ -204,6 +221,28 @@ func (e *jsonEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 	return e.buf.Bytes(), nil
 }
 
+// encodeColumns returns a map of every column name in tableDesc to its value
+// in datums.
+func (e *jsonEncoder) encodeColumns(
+	tableDesc *sqlbase.TableDescriptor, datums sqlbase.EncDatumRow,
+) (map[string]interface{}, error) {
+	columns := tableDesc.Columns
+	entries := make(map[string]interface{}, len(columns))
+	for i := range columns {
+		col := &columns[i]
+		datum := datums[i]
+		if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
+			return nil, err
+		}
+		var err error
+		entries[col.Name], err = tree.AsJSON(datum.Datum)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
 // EncodeResolvedTimestamp implements the Encoder interface.
 func (e *jsonEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp) ([]byte, error) {
 	meta := map[string]interface{}{
@@ -224,11 +263,11 @@ func (e *jsonEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp)
 // JSON format. Keys are the primary key columns in a record. Values are all
 // columns in a record.
 type confluentAvroEncoder struct {
-	registryURL           string
-	updatedField, keyOnly bool
+	registryURL                        string
+	updatedField, beforeField, keyOnly bool
 
 	keyCache      map[tableIDAndVersion]confluentRegisteredKeySchema
-	valueCache    map[tableIDAndVersion]confluentRegisteredEnvelopeSchema
+	valueCache    map[tableIDAndVersionPair]confluentRegisteredEnvelopeSchema
 	resolvedCache map[string]confluentRegisteredEnvelopeSchema
 }
 
@@ -238,6 +277,11 @@ func makeTableIDAndVersion(id sqlbase.ID, version sqlbase.DescriptorVersion) tab
 	return tableIDAndVersion(id)<<32 + tableIDAndVersion(version)
 }
 
+// tableIDAndVersionPair identifies the table descriptors used to encode the
+// before and after values of a row. The first element is zero unless the
+// before value is encoded.
+type tableIDAndVersionPair [2]tableIDAndVersion
+
 type confluentRegisteredKeySchema struct {
 	schema     *avroDataRecord
 	registryID int32
@@ -262,6 +306,11 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 			optEnvelope, opts[optEnvelope], optFormat, optFormatAvro)
 	}
 	_, e.updatedField = opts[optUpdatedTimestamps]
+	_, e.beforeField = opts[optDiff]
+	if e.beforeField && e.keyOnly {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			optDiff, optEnvelope, optEnvelopeWrapped)
+	}
 
 	if _, ok := opts[optKeyInValue]; ok {
 		return nil, errors.Errorf(`%s is not supported with %s=%s`,
@@ -274,7 +323,7 @@ func newConfluentAvroEncoder(opts map[string]string) (*confluentAvroEncoder, err
 	}
 
 	e.keyCache = make(map[tableIDAndVersion]confluentRegisteredKeySchema)
-	e.valueCache = make(map[tableIDAndVersion]confluentRegisteredEnvelopeSchema)
+	e.valueCache = make(map[tableIDAndVersionPair]confluentRegisteredEnvelopeSchema)
 	e.resolvedCache = make(map[string]confluentRegisteredEnvelopeSchema)
 	return e, nil
 }
@@ -316,16 +365,37 @@ func (e *confluentAvroEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 		return nil, nil
 	}
 
-	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	// The before value of a newly inserted row has no table descriptor of its
+	// own, so the schema of the after value is used for it.
+	prevTableDesc := row.prevTableDesc
+	if prevTableDesc == nil {
+		prevTableDesc = row.tableDesc
+	}
+	var cacheKey tableIDAndVersionPair
+	if e.beforeField {
+		cacheKey[0] = makeTableIDAndVersion(prevTableDesc.ID, prevTableDesc.Version)
+	}
+	cacheKey[1] = makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
 	registered, ok := e.valueCache[cacheKey]
 	if !ok {
-		afterDataSchema, err := tableToAvroSchema(row.tableDesc)
+		var beforeDataSchema *avroDataRecord
+		if e.beforeField {
+			var err error
+			beforeDataSchema, err = tableToAvroSchema(prevTableDesc, `before`)
+			if err != nil {
+				return nil, err
+			}
+		}
+		afterDataSchema, err := tableToAvroSchema(row.tableDesc, avroSchemaNoSuffix)
 		if err != nil {
 			return nil, err
 		}
 
-		opts := avroEnvelopeOpts{afterField: true, updatedField: e.updatedField}
-		registered.schema, err = envelopeToAvroSchema(row.tableDesc.Name, opts, afterDataSchema)
+		opts := avroEnvelopeOpts{
+			afterField: true, beforeField: e.beforeField, updatedField: e.updatedField,
+		}
+		registered.schema, err = envelopeToAvroSchema(
+			row.tableDesc.Name, opts, beforeDataSchema, afterDataSchema)
 		if err != nil {
 			return nil, err
 		}
@@ -346,17 +416,20 @@ func (e *confluentAvroEncoder) EncodeValue(row encodeRow) ([]byte, error) {
 			`updated`: row.updated,
 		}
 	}
-	var datums sqlbase.EncDatumRow
+	var beforeDatums, datums sqlbase.EncDatumRow
 	if !row.deleted {
 		datums = row.datums
 	}
+	if e.beforeField {
+		beforeDatums = row.prevDatums
+	}
 	// https://docs.confluent.io/current/schema-registry/docs/serializer-formatter.html#wire-format
 	header := []byte{
 		confluentAvroWireFormatMagic,
 		0, 0, 0, 0, // Placeholder for the ID.
 	}
 	binary.BigEndian.PutUint32(header[1:5], uint32(registered.registryID))
-	return registered.schema.BinaryFromRow(header, meta, datums)
+	return registered.schema.BinaryFromRow(header, meta, beforeDatums, datums)
 }
 
 // EncodeResolvedTimestamp implements the Encoder interface.
@@ -367,7 +440,7 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(
 	if !ok {
 		opts := avroEnvelopeOpts{resolvedField: true}
 		var err error
-		registered.schema, err = envelopeToAvroSchema(topic, opts, nil /* after */)
+		registered.schema, err = envelopeToAvroSchema(topic, opts, nil /* before */, nil /* after */)
 		if err != nil {
 			return nil, err
 		}
@@ -394,29 +467,38 @@ func (e *confluentAvroEncoder) EncodeResolvedTimestamp(
 		0, 0, 0, 0, // Placeholder for the ID.
 	}
 	binary.BigEndian.PutUint32(header[1:5], uint32(registered.registryID))
-	return registered.schema.BinaryFromRow(header, meta, nil /* row */)
+	return registered.schema.BinaryFromRow(header, meta, nil /* beforeRow */, nil /* afterRow */)
 }
 
 func (e *confluentAvroEncoder) register(schema *avroRecord, subject string) (int32, error) {
+	return registerConfluentSchema(e.registryURL, subject, ``, schema.codec.Schema())
+}
+
+// registerConfluentSchema registers the given schema under the given subject
+// with the Confluent schema registry at registryURL and returns its ID. An
+// empty schemaType means an avro schema.
+func registerConfluentSchema(
+	registryURL, subject, schemaType, schemaStr string,
+) (int32, error) {
 	type confluentSchemaVersionRequest struct {
-		Schema string `json:"schema"`
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType,omitempty"`
 	}
 	type confluentSchemaVersionResponse struct {
 		ID int32 `json:"id"`
 	}
 
-	url, err := url.Parse(e.registryURL)
+	url, err := url.Parse(registryURL)
 	if err != nil {
 		return 0, err
 	}
 	url.Path = filepath.Join(url.EscapedPath(), `subjects`, subject, `versions`)
 
-	schemaStr := schema.codec.Schema()
 	if log.V(1) {
-		log.Infof(context.TODO(), "registering avro schema %s %s", url, schemaStr)
+		log.Infof(context.TODO(), "registering %s schema %s %s", schemaType, url, schemaStr)
 	}
 
-	req := confluentSchemaVersionRequest{Schema: schemaStr}
+	req := confluentSchemaVersionRequest{Schema: schemaStr, SchemaType: schemaType}
 	var buf bytes.Buffer
 	if err := gojson.NewEncoder(&buf).Encode(req); err != nil {
 		return 0, err
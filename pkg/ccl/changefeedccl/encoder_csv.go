@@ -0,0 +1,119 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding/csv"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/pkg/errors"
+)
+
+// csvEncoder encodes changefeed entries as CSV records, formatting every datum
+// the same way as EXPORT does and NULLs as empty fields. Keys are the primary
+// key columns in a record. Values are all columns in a record, in the order of
+// the table's columns, followed by the updated timestamp if the `updated`
+// option is set. Deleted rows have no value. Resolved timestamp payloads are a
+// record with only the resolved timestamp.
+//
+// The records don't contain the column names, so consumers have to rely on the
+// table schema, which sinks like the cloud storage sink version for them.
+type csvEncoder struct {
+	updatedField bool
+
+	alloc  sqlbase.DatumAlloc
+	buf    bytes.Buffer
+	record []string
+}
+
+var _ Encoder = &csvEncoder{}
+
+func makeCSVEncoder(opts map[string]string) (*csvEncoder, error) {
+	if envelopeType(opts[optEnvelope]) != optEnvelopeRow {
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatCSV)
+	}
+	for _, opt := range []string{optKeyInValue, optDiff} {
+		if _, ok := opts[opt]; ok {
+			return nil, errors.Errorf(`%s is not supported with %s=%s`, opt, optFormat, optFormatCSV)
+		}
+	}
+	e := &csvEncoder{}
+	_, e.updatedField = opts[optUpdatedTimestamps]
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *csvEncoder) EncodeKey(row encodeRow) ([]byte, error) {
+	e.record = e.record[:0]
+	colIdxByID := row.tableDesc.ColumnIdxMap()
+	for _, colID := range row.tableDesc.PrimaryIndex.ColumnIDs {
+		idx, ok := colIdxByID[colID]
+		if !ok {
+			return nil, errors.Errorf(`unknown column id: %d`, colID)
+		}
+		if err := e.appendDatum(row.datums[idx], &row.tableDesc.Columns[idx]); err != nil {
+			return nil, err
+		}
+	}
+	return e.writeRecord()
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *csvEncoder) EncodeValue(row encodeRow) ([]byte, error) {
+	if row.deleted {
+		return nil, nil
+	}
+	e.record = e.record[:0]
+	for i := range row.tableDesc.Columns {
+		if err := e.appendDatum(row.datums[i], &row.tableDesc.Columns[i]); err != nil {
+			return nil, err
+		}
+	}
+	if e.updatedField {
+		e.record = append(e.record, tree.TimestampToDecimal(row.updated).Decimal.String())
+	}
+	return e.writeRecord()
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *csvEncoder) EncodeResolvedTimestamp(_ string, resolved hlc.Timestamp) ([]byte, error) {
+	e.record = append(e.record[:0], tree.TimestampToDecimal(resolved).Decimal.String())
+	return e.writeRecord()
+}
+
+func (e *csvEncoder) appendDatum(datum sqlbase.EncDatum, col *sqlbase.ColumnDescriptor) error {
+	if err := datum.EnsureDecoded(&col.Type, &e.alloc); err != nil {
+		return err
+	}
+	if datum.Datum == tree.DNull {
+		e.record = append(e.record, ``)
+		return nil
+	}
+	e.record = append(e.record, tree.AsStringWithFlags(datum.Datum, tree.FmtExport))
+	return nil
+}
+
+// writeRecord encodes the accumulated record without the trailing newline,
+// which is left to the sinks.
+func (e *csvEncoder) writeRecord() ([]byte, error) {
+	e.buf.Reset()
+	w := csv.NewWriter(&e.buf)
+	if err := w.Write(e.record); err != nil {
+		return nil, err
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(e.buf.Bytes(), []byte{'\n'}), nil
+}
@@ -0,0 +1,453 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/gogo/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const confluentSchemaTypeProtobuf = `PROTOBUF`
+
+// The field numbers of the envelope message.
+const (
+	protobufEnvelopeFieldAfter    = 1
+	protobufEnvelopeFieldBefore   = 2
+	protobufEnvelopeFieldUpdated  = 3
+	protobufEnvelopeFieldResolved = 4
+)
+
+// protobufField is a field of a protobufMessage that holds a SQL column.
+type protobufField struct {
+	name   string
+	number int32
+	// typ is the protobuf scalar type of the field.
+	typ      string
+	colIdx   int
+	encodeFn func(*proto.Buffer, int32, tree.Datum) error
+}
+
+// protobufMessage is the schema of a protobuf message that holds a SQL row or
+// some of its columns.
+type protobufMessage struct {
+	name   string
+	fields []protobufField
+}
+
+// protobufEnvelope is the schema of the top-level protobuf message that wraps
+// a changed SQL row and some metadata. A nil before or after message means
+// that the corresponding field is not present.
+type protobufEnvelope struct {
+	name              string
+	before, after     *protobufMessage
+	updated, resolved bool
+}
+
+// columnToProtobufField converts a column descriptor into its corresponding
+// protobuf field. The field number is the column ID, which is never reused, so
+// that the messages of all versions of a table are compatible with each other.
+// Types without an equivalent protobuf scalar type are encoded as strings,
+// formatted the same way as EXPORT does.
+func columnToProtobufField(colIdx int, col *sqlbase.ColumnDescriptor) protobufField {
+	f := protobufField{
+		// Protobuf identifiers follow the same rules as avro names.
+		name:   SQLNameToAvroName(col.Name),
+		number: int32(col.ID),
+		colIdx: colIdx,
+	}
+	switch col.Type.Family() {
+	case types.IntFamily:
+		f.typ = `int64`
+		f.encodeFn = func(b *proto.Buffer, n int32, d tree.Datum) error {
+			if err := b.EncodeVarint(protobufTag(n, proto.WireVarint)); err != nil {
+				return err
+			}
+			return b.EncodeVarint(uint64(*d.(*tree.DInt)))
+		}
+	case types.BoolFamily:
+		f.typ = `bool`
+		f.encodeFn = func(b *proto.Buffer, n int32, d tree.Datum) error {
+			if err := b.EncodeVarint(protobufTag(n, proto.WireVarint)); err != nil {
+				return err
+			}
+			var v uint64
+			if *d.(*tree.DBool) {
+				v = 1
+			}
+			return b.EncodeVarint(v)
+		}
+	case types.FloatFamily:
+		f.typ = `double`
+		f.encodeFn = func(b *proto.Buffer, n int32, d tree.Datum) error {
+			if err := b.EncodeVarint(protobufTag(n, proto.WireFixed64)); err != nil {
+				return err
+			}
+			return b.EncodeFixed64(math.Float64bits(float64(*d.(*tree.DFloat))))
+		}
+	case types.StringFamily:
+		f.typ = `string`
+		f.encodeFn = func(b *proto.Buffer, n int32, d tree.Datum) error {
+			if err := b.EncodeVarint(protobufTag(n, proto.WireBytes)); err != nil {
+				return err
+			}
+			return b.EncodeStringBytes(string(*d.(*tree.DString)))
+		}
+	case types.BytesFamily:
+		f.typ = `bytes`
+		f.encodeFn = func(b *proto.Buffer, n int32, d tree.Datum) error {
+			if err := b.EncodeVarint(protobufTag(n, proto.WireBytes)); err != nil {
+				return err
+			}
+			return b.EncodeRawBytes([]byte(*d.(*tree.DBytes)))
+		}
+	default:
+		f.typ = `string`
+		f.encodeFn = func(b *proto.Buffer, n int32, d tree.Datum) error {
+			if err := b.EncodeVarint(protobufTag(n, proto.WireBytes)); err != nil {
+				return err
+			}
+			return b.EncodeStringBytes(tree.AsStringWithFlags(d, tree.FmtExport))
+		}
+	}
+	return f
+}
+
+// tableToProtobufMessage converts a table descriptor into the schema of a
+// message with all of its columns.
+func tableToProtobufMessage(tableDesc *sqlbase.TableDescriptor, name string) *protobufMessage {
+	m := &protobufMessage{name: name}
+	for colIdx := range tableDesc.Columns {
+		m.fields = append(m.fields, columnToProtobufField(colIdx, &tableDesc.Columns[colIdx]))
+	}
+	return m
+}
+
+// indexToProtobufMessage converts an index descriptor into the schema of a
+// message with all of its columns.
+func indexToProtobufMessage(
+	tableDesc *sqlbase.TableDescriptor, indexDesc *sqlbase.IndexDescriptor,
+) (*protobufMessage, error) {
+	m := &protobufMessage{name: SQLNameToAvroName(tableDesc.Name) + `_key`}
+	colIdxByID := tableDesc.ColumnIdxMap()
+	for _, colID := range indexDesc.ColumnIDs {
+		colIdx, ok := colIdxByID[colID]
+		if !ok {
+			return nil, errors.Errorf(`unknown column id: %d`, colID)
+		}
+		m.fields = append(m.fields, columnToProtobufField(colIdx, &tableDesc.Columns[colIdx]))
+	}
+	return m, nil
+}
+
+// protobufTag returns the tag of a field with the given number and wire type.
+func protobufTag(number int32, wireType int) uint64 {
+	return uint64(number)<<3 | uint64(wireType)
+}
+
+// writeSchema writes the definition of the message in the protobuf language.
+// Every field is optional, and absent if the column is NULL.
+func (m *protobufMessage) writeSchema(buf *bytes.Buffer, indent string) {
+	fmt.Fprintf(buf, "%smessage %s {\n", indent, m.name)
+	for _, f := range m.fields {
+		fmt.Fprintf(buf, "%s  optional %s %s = %d;\n", indent, f.typ, f.name, f.number)
+	}
+	fmt.Fprintf(buf, "%s}\n", indent)
+}
+
+// encode appends the fields of the message for the given row to b.
+func (m *protobufMessage) encode(
+	b *proto.Buffer, row sqlbase.EncDatumRow, cols []sqlbase.ColumnDescriptor, a *sqlbase.DatumAlloc,
+) error {
+	for _, f := range m.fields {
+		d := &row[f.colIdx]
+		if err := d.EnsureDecoded(&cols[f.colIdx].Type, a); err != nil {
+			return err
+		}
+		if d.Datum == tree.DNull {
+			continue
+		}
+		if err := f.encodeFn(b, f.number, d.Datum); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// schema returns the definition of the message in a standalone protobuf file.
+func (m *protobufMessage) schema() string {
+	var buf bytes.Buffer
+	buf.WriteString("syntax = \"proto2\";\n\n")
+	m.writeSchema(&buf, ``)
+	return buf.String()
+}
+
+// schema returns the definition of the envelope, with the row messages nested
+// in it, in a standalone protobuf file.
+func (e *protobufEnvelope) schema() string {
+	var buf bytes.Buffer
+	buf.WriteString("syntax = \"proto2\";\n\n")
+	fmt.Fprintf(&buf, "message %s {\n", e.name)
+	if e.after != nil {
+		e.after.writeSchema(&buf, `  `)
+	}
+	if e.before != nil {
+		e.before.writeSchema(&buf, `  `)
+	}
+	if e.after != nil {
+		fmt.Fprintf(&buf, "  optional %s after = %d;\n", e.after.name, protobufEnvelopeFieldAfter)
+	}
+	if e.before != nil {
+		fmt.Fprintf(&buf, "  optional %s before = %d;\n", e.before.name, protobufEnvelopeFieldBefore)
+	}
+	if e.updated {
+		fmt.Fprintf(&buf, "  optional string updated = %d;\n", protobufEnvelopeFieldUpdated)
+	}
+	if e.resolved {
+		fmt.Fprintf(&buf, "  optional string resolved = %d;\n", protobufEnvelopeFieldResolved)
+	}
+	buf.WriteString("}\n")
+	return buf.String()
+}
+
+// protobufEncoder encodes changefeed entries as protobuf messages in the
+// Confluent wire format. The schemas are generated for every version of every
+// table and registered with a Confluent schema registry, which is how they're
+// published to consumers. Keys are the primary key columns in a message.
+// Values are an envelope message with the row in its `after` field, the
+// previous row in its `before` field if the `diff` option is set and the
+// updated timestamp in its `updated` field if the `updated` option is set.
+type protobufEncoder struct {
+	registryURL                        string
+	updatedField, beforeField, keyOnly bool
+
+	keyCache      map[tableIDAndVersion]confluentRegisteredProtobufKeySchema
+	valueCache    map[tableIDAndVersionPair]confluentRegisteredProtobufEnvelopeSchema
+	resolvedCache map[string]confluentRegisteredProtobufEnvelopeSchema
+
+	alloc  sqlbase.DatumAlloc
+	buf    proto.Buffer
+	msgBuf proto.Buffer
+}
+
+type confluentRegisteredProtobufKeySchema struct {
+	schema     *protobufMessage
+	registryID int32
+}
+
+type confluentRegisteredProtobufEnvelopeSchema struct {
+	schema     *protobufEnvelope
+	registryID int32
+}
+
+var _ Encoder = &protobufEncoder{}
+
+func newProtobufEncoder(opts map[string]string) (*protobufEncoder, error) {
+	e := &protobufEncoder{registryURL: opts[optConfluentSchemaRegistry]}
+
+	switch opts[optEnvelope] {
+	case string(optEnvelopeKeyOnly):
+		e.keyOnly = true
+	case string(optEnvelopeWrapped):
+	default:
+		return nil, errors.Errorf(`%s=%s is not supported with %s=%s`,
+			optEnvelope, opts[optEnvelope], optFormat, optFormatProtobuf)
+	}
+	_, e.updatedField = opts[optUpdatedTimestamps]
+	_, e.beforeField = opts[optDiff]
+	if e.beforeField && e.keyOnly {
+		return nil, errors.Errorf(`%s is only usable with %s=%s`,
+			optDiff, optEnvelope, optEnvelopeWrapped)
+	}
+
+	if _, ok := opts[optKeyInValue]; ok {
+		return nil, errors.Errorf(`%s is not supported with %s=%s`,
+			optKeyInValue, optFormat, optFormatProtobuf)
+	}
+
+	if len(e.registryURL) == 0 {
+		return nil, errors.Errorf(`WITH option %s is required for %s=%s`,
+			optConfluentSchemaRegistry, optFormat, optFormatProtobuf)
+	}
+
+	e.keyCache = make(map[tableIDAndVersion]confluentRegisteredProtobufKeySchema)
+	e.valueCache = make(map[tableIDAndVersionPair]confluentRegisteredProtobufEnvelopeSchema)
+	e.resolvedCache = make(map[string]confluentRegisteredProtobufEnvelopeSchema)
+	return e, nil
+}
+
+// EncodeKey implements the Encoder interface.
+func (e *protobufEncoder) EncodeKey(row encodeRow) ([]byte, error) {
+	cacheKey := makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	registered, ok := e.keyCache[cacheKey]
+	if !ok {
+		var err error
+		registered.schema, err = indexToProtobufMessage(row.tableDesc, &row.tableDesc.PrimaryIndex)
+		if err != nil {
+			return nil, err
+		}
+
+		// NB: This uses the kafka name escaper because it has to match the name
+		// of the kafka topic.
+		subject := SQLNameToKafkaName(row.tableDesc.Name) + confluentSubjectSuffixKey
+		registered.registryID, err = registerConfluentSchema(
+			e.registryURL, subject, confluentSchemaTypeProtobuf, registered.schema.schema())
+		if err != nil {
+			return nil, err
+		}
+		// TODO(dan): Bound the size of this cache.
+		e.keyCache[cacheKey] = registered
+	}
+
+	e.startMessage(registered.registryID)
+	if err := registered.schema.encode(&e.buf, row.datums, row.tableDesc.Columns, &e.alloc); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// EncodeValue implements the Encoder interface.
+func (e *protobufEncoder) EncodeValue(row encodeRow) ([]byte, error) {
+	if e.keyOnly {
+		return nil, nil
+	}
+
+	// The before value of a newly inserted row has no table descriptor of its
+	// own, so the schema of the after value is used for it.
+	prevTableDesc := row.prevTableDesc
+	if prevTableDesc == nil {
+		prevTableDesc = row.tableDesc
+	}
+	var cacheKey tableIDAndVersionPair
+	if e.beforeField {
+		cacheKey[0] = makeTableIDAndVersion(prevTableDesc.ID, prevTableDesc.Version)
+	}
+	cacheKey[1] = makeTableIDAndVersion(row.tableDesc.ID, row.tableDesc.Version)
+	registered, ok := e.valueCache[cacheKey]
+	if !ok {
+		name := SQLNameToAvroName(row.tableDesc.Name)
+		registered.schema = &protobufEnvelope{
+			name:    name + `_envelope`,
+			after:   tableToProtobufMessage(row.tableDesc, name),
+			updated: e.updatedField,
+		}
+		if e.beforeField {
+			registered.schema.before = tableToProtobufMessage(prevTableDesc, name+`_before`)
+		}
+
+		// NB: This uses the kafka name escaper because it has to match the name
+		// of the kafka topic.
+		subject := SQLNameToKafkaName(row.tableDesc.Name) + confluentSubjectSuffixValue
+		var err error
+		registered.registryID, err = registerConfluentSchema(
+			e.registryURL, subject, confluentSchemaTypeProtobuf, registered.schema.schema())
+		if err != nil {
+			return nil, err
+		}
+		// TODO(dan): Bound the size of this cache.
+		e.valueCache[cacheKey] = registered
+	}
+
+	e.startMessage(registered.registryID)
+	if !row.deleted {
+		if err := e.encodeNested(
+			protobufEnvelopeFieldAfter, registered.schema.after, row.datums, row.tableDesc,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if e.beforeField && row.prevDatums != nil {
+		if err := e.encodeNested(
+			protobufEnvelopeFieldBefore, registered.schema.before, row.prevDatums, row.prevTableDesc,
+		); err != nil {
+			return nil, err
+		}
+	}
+	if e.updatedField {
+		if err := e.encodeTimestamp(protobufEnvelopeFieldUpdated, row.updated); err != nil {
+			return nil, err
+		}
+	}
+	return e.buf.Bytes(), nil
+}
+
+// EncodeResolvedTimestamp implements the Encoder interface.
+func (e *protobufEncoder) EncodeResolvedTimestamp(
+	topic string, resolved hlc.Timestamp,
+) ([]byte, error) {
+	registered, ok := e.resolvedCache[topic]
+	if !ok {
+		registered.schema = &protobufEnvelope{
+			name:     SQLNameToAvroName(topic) + `_envelope`,
+			resolved: true,
+		}
+
+		// NB: This uses the kafka name escaper because it has to match the name
+		// of the kafka topic.
+		subject := SQLNameToKafkaName(topic) + confluentSubjectSuffixValue
+		var err error
+		registered.registryID, err = registerConfluentSchema(
+			e.registryURL, subject, confluentSchemaTypeProtobuf, registered.schema.schema())
+		if err != nil {
+			return nil, err
+		}
+		// TODO(dan): Bound the size of this cache.
+		e.resolvedCache[topic] = registered
+	}
+
+	e.startMessage(registered.registryID)
+	if err := e.encodeTimestamp(protobufEnvelopeFieldResolved, resolved); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// startMessage resets the buffer to the header of a message in the Confluent
+// wire format.
+//
+// https://docs.confluent.io/current/schema-registry/serializer-formatter.html#wire-format
+func (e *protobufEncoder) startMessage(registryID int32) {
+	header := []byte{
+		confluentAvroWireFormatMagic,
+		0, 0, 0, 0, // Placeholder for the ID.
+		// The message indexes of the first message in the schema, which is the
+		// only top-level message in all the schemas of this encoder.
+		0,
+	}
+	binary.BigEndian.PutUint32(header[1:5], uint32(registryID))
+	e.buf.SetBuf(append(e.buf.Bytes()[:0], header...))
+}
+
+func (e *protobufEncoder) encodeNested(
+	number int32, m *protobufMessage, row sqlbase.EncDatumRow, tableDesc *sqlbase.TableDescriptor,
+) error {
+	e.msgBuf.Reset()
+	if err := m.encode(&e.msgBuf, row, tableDesc.Columns, &e.alloc); err != nil {
+		return err
+	}
+	if err := e.buf.EncodeVarint(protobufTag(number, proto.WireBytes)); err != nil {
+		return err
+	}
+	return e.buf.EncodeRawBytes(e.msgBuf.Bytes())
+}
+
+func (e *protobufEncoder) encodeTimestamp(number int32, ts hlc.Timestamp) error {
+	if err := e.buf.EncodeVarint(protobufTag(number, proto.WireBytes)); err != nil {
+		return err
+	}
+	return e.buf.EncodeStringBytes(ts.AsOfSystemTime())
+}
@@ -46,13 +46,14 @@ func TestEncoders(t *testing.T) {
 	ts := hlc.Timestamp{WallTime: 1, Logical: 2}
 
 	var opts []map[string]string
-	for _, f := range []string{string(optFormatJSON), string(optFormatAvro)} {
+	for _, f := range []string{string(optFormatJSON), string(optFormatAvro), string(optFormatCSV)} {
 		for _, e := range []string{
 			string(optEnvelopeKeyOnly), string(optEnvelopeRow), string(optEnvelopeWrapped),
 		} {
 			opts = append(opts,
 				map[string]string{optFormat: f, optEnvelope: e},
 				map[string]string{optFormat: f, optEnvelope: e, optUpdatedTimestamps: ``},
+				map[string]string{optFormat: f, optEnvelope: e, optDiff: ``},
 			)
 		}
 	}
@@ -74,6 +75,9 @@ func TestEncoders(t *testing.T) {
 			delete:   `[1]->`,
 			resolved: `{"__crdb__":{"resolved":"1.0000000002"}}`,
 		},
+		`format=json,envelope=key_only,diff`: {
+			err: `diff is only usable with envelope=wrapped`,
+		},
 		`format=json,envelope=row`: {
 			insert:   `[1]->{"a": 1, "b": "bar"}`,
 			delete:   `[1]->`,
@@ -84,6 +88,9 @@ func TestEncoders(t *testing.T) {
 			delete:   `[1]->`,
 			resolved: `{"__crdb__":{"resolved":"1.0000000002"}}`,
 		},
+		`format=json,envelope=row,diff`: {
+			err: `diff is only usable with envelope=wrapped`,
+		},
 		`format=json,envelope=wrapped`: {
 			insert:   `[1]->{"after": {"a": 1, "b": "bar"}}`,
 			delete:   `[1]->{"after": null}`,
@@ -94,6 +101,11 @@ func TestEncoders(t *testing.T) {
 			delete:   `[1]->{"after": null, "updated": "1.0000000002"}`,
 			resolved: `{"resolved":"1.0000000002"}`,
 		},
+		`format=json,envelope=wrapped,diff`: {
+			insert:   `[1]->{"after": {"a": 1, "b": "bar"}, "before": null}`,
+			delete:   `[1]->{"after": null, "before": {"a": 1, "b": "bar"}}`,
+			resolved: `{"resolved":"1.0000000002"}`,
+		},
 		`format=experimental_avro,envelope=key_only`: {
 			insert:   `{"a":{"long":1}}->`,
 			delete:   `{"a":{"long":1}}->`,
@@ -104,12 +116,18 @@ func TestEncoders(t *testing.T) {
 			delete:   `{"a":{"long":1}}->`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
+		`format=experimental_avro,envelope=key_only,diff`: {
+			err: `diff is only usable with envelope=wrapped`,
+		},
 		`format=experimental_avro,envelope=row`: {
 			err: `envelope=row is not supported with format=experimental_avro`,
 		},
 		`format=experimental_avro,envelope=row,updated`: {
 			err: `envelope=row is not supported with format=experimental_avro`,
 		},
+		`format=experimental_avro,envelope=row,diff`: {
+			err: `envelope=row is not supported with format=experimental_avro`,
+		},
 		`format=experimental_avro,envelope=wrapped`: {
 			insert: `{"a":{"long":1}}->` +
 				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}}}`,
@@ -123,6 +141,44 @@ func TestEncoders(t *testing.T) {
 			delete:   `{"a":{"long":1}}->{"after":null,"updated":{"string":"1.0000000002"}}`,
 			resolved: `{"resolved":{"string":"1.0000000002"}}`,
 		},
+		`format=experimental_avro,envelope=wrapped,diff`: {
+			insert: `{"a":{"long":1}}->` +
+				`{"after":{"foo":{"a":{"long":1},"b":{"string":"bar"}}},"before":null}`,
+			delete: `{"a":{"long":1}}->` +
+				`{"after":null,"before":{"foo_before":{"a":{"long":1},"b":{"string":"bar"}}}}`,
+			resolved: `{"resolved":{"string":"1.0000000002"}}`,
+		},
+		`format=csv,envelope=key_only`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=key_only,updated`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=key_only,diff`: {
+			err: `envelope=key_only is not supported with format=csv`,
+		},
+		`format=csv,envelope=row`: {
+			insert:   `1->1,bar`,
+			delete:   `1->`,
+			resolved: `1.0000000002`,
+		},
+		`format=csv,envelope=row,updated`: {
+			insert:   `1->1,bar,1.0000000002`,
+			delete:   `1->`,
+			resolved: `1.0000000002`,
+		},
+		`format=csv,envelope=row,diff`: {
+			err: `diff is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped,updated`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
+		`format=csv,envelope=wrapped,diff`: {
+			err: `envelope=wrapped is not supported with format=csv`,
+		},
 	}
 
 	for _, o := range opts {
@@ -130,13 +186,16 @@ func TestEncoders(t *testing.T) {
 		if _, ok := o[optUpdatedTimestamps]; ok {
 			name += `,updated`
 		}
+		if _, ok := o[optDiff]; ok {
+			name += `,diff`
+		}
 		t.Run(name, func(t *testing.T) {
 			expected := expecteds[name]
 
 			var rowStringFn func([]byte, []byte) string
 			var resolvedStringFn func([]byte) string
 			switch o[optFormat] {
-			case string(optFormatJSON):
+			case string(optFormatJSON), string(optFormatCSV):
 				rowStringFn = func(k, v []byte) string { return fmt.Sprintf(`%s->%s`, k, v) }
 				resolvedStringFn = func(r []byte) string { return string(r) }
 			case string(optFormatAvro):
@@ -174,10 +233,12 @@ func TestEncoders(t *testing.T) {
 			require.Equal(t, expected.insert, rowStringFn(keyInsert, valueInsert))
 
 			rowDelete := encodeRow{
-				datums:    row,
-				deleted:   true,
-				updated:   ts,
-				tableDesc: tableDesc,
+				datums:        row,
+				deleted:       true,
+				updated:       ts,
+				tableDesc:     tableDesc,
+				prevDatums:    row,
+				prevTableDesc: tableDesc,
 			}
 			keyDelete, err := e.EncodeKey(rowDelete)
 			require.NoError(t, err)
@@ -197,14 +258,16 @@ type testSchemaRegistry struct {
 	server *httptest.Server
 	mu     struct {
 		syncutil.Mutex
-		idAlloc int32
-		schemas map[int32]string
+		idAlloc     int32
+		schemas     map[int32]string
+		schemaTypes map[int32]string
 	}
 }
 
 func makeTestSchemaRegistry() *testSchemaRegistry {
 	r := &testSchemaRegistry{}
 	r.mu.schemas = make(map[int32]string)
+	r.mu.schemaTypes = make(map[int32]string)
 	r.server = httptest.NewServer(http.HandlerFunc(r.Register))
 	return r
 }
@@ -215,7 +278,8 @@ func (r *testSchemaRegistry) Close() {
 
 func (r *testSchemaRegistry) Register(hw http.ResponseWriter, hr *http.Request) {
 	type confluentSchemaVersionRequest struct {
-		Schema string `json:"schema"`
+		Schema     string `json:"schema"`
+		SchemaType string `json:"schemaType"`
 	}
 	type confluentSchemaVersionResponse struct {
 		ID int32 `json:"id"`
@@ -231,6 +295,7 @@ func (r *testSchemaRegistry) Register(hw http.ResponseWriter, hr *http.Request)
 		id := r.mu.idAlloc
 		r.mu.idAlloc++
 		r.mu.schemas[id] = req.Schema
+		r.mu.schemaTypes[id] = req.SchemaType
 		r.mu.Unlock()
 
 		res, err := gojson.Marshal(confluentSchemaVersionResponse{ID: id})
@@ -388,3 +453,104 @@ func TestAvroLedger(t *testing.T) {
 	t.Run(`sinkless`, sinklessTest(testFn))
 	t.Run(`enterprise`, enterpriseTest(testFn))
 }
+
+func TestProtobufEncoder(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	reg := makeTestSchemaRegistry()
+	defer reg.Close()
+
+	tableDesc, err := parseTableDesc(`CREATE TABLE foo (a INT PRIMARY KEY, b STRING, c INT)`)
+	require.NoError(t, err)
+	row := sqlbase.EncDatumRow{
+		sqlbase.EncDatum{Datum: tree.NewDInt(1)},
+		sqlbase.EncDatum{Datum: tree.NewDString(`bar`)},
+		sqlbase.EncDatum{Datum: tree.DNull},
+	}
+	ts := hlc.Timestamp{WallTime: 1, Logical: 2}
+
+	e, err := getEncoder(map[string]string{
+		optFormat:                  string(optFormatProtobuf),
+		optEnvelope:                string(optEnvelopeWrapped),
+		optUpdatedTimestamps:       ``,
+		optDiff:                    ``,
+		optConfluentSchemaRegistry: reg.server.URL,
+	})
+	require.NoError(t, err)
+
+	// The header of every message is the magic byte, the big-endian registry id
+	// and the message indexes.
+	header := func(id byte) []byte { return []byte{0, 0, 0, 0, id, 0} }
+	// The columns are encoded in fields numbered after their column ids, and
+	// NULLs are left out.
+	fooBytes := []byte{0x08, 0x01, 0x12, 0x03, 'b', 'a', 'r'}
+	tsBytes := append([]byte{0x0c}, `1.0000000002`...)
+
+	rowInsert := encodeRow{datums: row, updated: ts, tableDesc: tableDesc}
+	key, err := e.EncodeKey(rowInsert)
+	require.NoError(t, err)
+	require.Equal(t, append(header(0), 0x08, 0x01), key)
+	value, err := e.EncodeValue(rowInsert)
+	require.NoError(t, err)
+	expected := append(header(1), 0x0a, byte(len(fooBytes)))
+	expected = append(expected, fooBytes...)
+	expected = append(append(expected, 0x1a), tsBytes...)
+	require.Equal(t, expected, value)
+
+	rowDelete := encodeRow{
+		datums:        row,
+		deleted:       true,
+		updated:       ts,
+		tableDesc:     tableDesc,
+		prevDatums:    row,
+		prevTableDesc: tableDesc,
+	}
+	value, err = e.EncodeValue(rowDelete)
+	require.NoError(t, err)
+	expected = append(header(1), 0x12, byte(len(fooBytes)))
+	expected = append(expected, fooBytes...)
+	expected = append(append(expected, 0x1a), tsBytes...)
+	require.Equal(t, expected, value)
+
+	resolved, err := e.EncodeResolvedTimestamp(tableDesc.Name, ts)
+	require.NoError(t, err)
+	require.Equal(t, append(append(header(2), 0x22), tsBytes...), resolved)
+
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	require.Equal(t, map[int32]string{
+		0: confluentSchemaTypeProtobuf, 1: confluentSchemaTypeProtobuf, 2: confluentSchemaTypeProtobuf,
+	}, reg.mu.schemaTypes)
+	require.Equal(t, map[int32]string{
+		0: `syntax = "proto2";
+
+message foo_key {
+  optional int64 a = 1;
+}
+`,
+		1: `syntax = "proto2";
+
+message foo_envelope {
+  message foo {
+    optional int64 a = 1;
+    optional string b = 2;
+    optional int64 c = 3;
+  }
+  message foo_before {
+    optional int64 a = 1;
+    optional string b = 2;
+    optional int64 c = 3;
+  }
+  optional foo after = 1;
+  optional foo_before before = 2;
+  optional string updated = 3;
+}
+`,
+		2: `syntax = "proto2";
+
+message foo_envelope {
+  optional string resolved = 4;
+}
+`,
+	}, reg.mu.schemas)
+}
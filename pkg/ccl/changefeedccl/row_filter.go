@@ -0,0 +1,287 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/pkg/errors"
+)
+
+// rowFilter implements the `columns` and `filter` changefeed options, which
+// restrict the columns and the rows emitted by a changefeed.
+//
+// The projection given by `columns` is a comma-separated list of column names.
+// The primary key columns are always kept because they make up the key of
+// every message. The predicate given by `filter` is a boolean SQL expression
+// over the columns of the table, which is evaluated on both the new and the
+// previous value of every changed row (the previous values are always read when
+// there's a predicate, see needsPrevValues). A row that matches after the
+// change is emitted. A row that matched before the change but doesn't anymore,
+// which includes a matching row being deleted, is emitted as a deletion so that
+// consumers stop seeing it. Any other change is dropped.
+//
+// Both are resolved anew for every version of the table descriptor, so a
+// schema change that drops a referenced column causes the changefeed to fail.
+type rowFilter struct {
+	evalCtx *tree.EvalContext
+	// columns is nil if there's no projection.
+	columns tree.NameList
+	// filter is nil if there's no predicate.
+	filter tree.Expr
+
+	tables map[tableIDAndVersion]*tableRowFilter
+	alloc  sqlbase.DatumAlloc
+}
+
+// tableRowFilter is a rowFilter resolved for a version of a table descriptor.
+type tableRowFilter struct {
+	// projected is a copy of the table descriptor with only the projected
+	// columns, or nil if there's no projection.
+	projected *sqlbase.TableDescriptor
+	// colIdxs contains the index of every column of projected in the columns
+	// of the original table descriptor.
+	colIdxs []int
+	// filter is nil if there's no predicate.
+	filter tree.TypedExpr
+	ivars  *rowFilterIVarContainer
+}
+
+// makeRowFilter returns a rowFilter for the given changefeed options or nil
+// if neither of the options is set.
+func makeRowFilter(opts map[string]string, evalCtx *tree.EvalContext) (*rowFilter, error) {
+	columns, hasColumns := opts[optColumns]
+	filter, hasFilter := opts[optFilter]
+	if !hasColumns && !hasFilter {
+		return nil, nil
+	}
+	f := &rowFilter{
+		evalCtx: evalCtx,
+		tables:  make(map[tableIDAndVersion]*tableRowFilter),
+	}
+	if hasColumns {
+		var err error
+		if f.columns, err = parser.ParseNameList(columns); err != nil {
+			return nil, errors.Wrapf(err, `%s must be a list of column names`, optColumns)
+		}
+	}
+	if hasFilter {
+		var err error
+		if f.filter, err = parser.ParseExpr(filter); err != nil {
+			return nil, errors.Wrapf(err, `parsing %s`, optFilter)
+		}
+	}
+	return f, nil
+}
+
+// forTable returns the rowFilter resolved for the given table descriptor.
+func (f *rowFilter) forTable(tableDesc *sqlbase.TableDescriptor) (*tableRowFilter, error) {
+	cacheKey := makeTableIDAndVersion(tableDesc.ID, tableDesc.Version)
+	if t, ok := f.tables[cacheKey]; ok {
+		return t, nil
+	}
+
+	t := &tableRowFilter{}
+	if f.columns != nil {
+		keep := make(map[sqlbase.ColumnID]struct{}, len(f.columns))
+		for _, colID := range tableDesc.PrimaryIndex.ColumnIDs {
+			keep[colID] = struct{}{}
+		}
+		for _, name := range f.columns {
+			col, _, err := tableDesc.FindColumnByName(name)
+			if err != nil {
+				return nil, errors.Wrapf(err, `table %s`, tableDesc.Name)
+			}
+			keep[col.ID] = struct{}{}
+		}
+		projected := *tableDesc
+		projected.Columns = nil
+		for i := range tableDesc.Columns {
+			if _, ok := keep[tableDesc.Columns[i].ID]; ok {
+				projected.Columns = append(projected.Columns, tableDesc.Columns[i])
+				t.colIdxs = append(t.colIdxs, i)
+			}
+		}
+		t.projected = &projected
+	}
+
+	if f.filter != nil {
+		t.ivars = &rowFilterIVarContainer{cols: tableDesc.Columns, alloc: &f.alloc}
+		ivarHelper := tree.MakeIndexedVarHelper(t.ivars, len(tableDesc.Columns))
+		source := sqlbase.NewSourceInfoForSingleTable(
+			tree.MakeUnqualifiedTableName(tree.Name(tableDesc.Name)),
+			sqlbase.ResultColumnsFromColDescs(tableDesc.Columns),
+		)
+		expr, _, _, err := sqlbase.ResolveNames(
+			f.filter, sqlbase.MakeMultiSourceInfo(source), ivarHelper, f.evalCtx.SessionData.SearchPath,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, `table %s`, tableDesc.Name)
+		}
+		semaCtx := tree.MakeSemaContext()
+		semaCtx.IVarContainer = t.ivars
+		semaCtx.Properties.Require(optFilter, tree.RejectSpecial|tree.RejectSubqueries)
+		t.filter, err = tree.TypeCheckAndRequire(expr, &semaCtx, types.Bool, optFilter)
+		if err != nil {
+			return nil, errors.Wrapf(err, `table %s`, tableDesc.Name)
+		}
+	}
+
+	f.tables[cacheKey] = t
+	return t, nil
+}
+
+// apply evaluates the predicate on the given row and replaces the row with its
+// projection. A row that stopped matching the predicate is turned into a
+// deletion. It returns false if the row should not be emitted.
+func (f *rowFilter) apply(row *encodeRow) (bool, error) {
+	if f.filter != nil {
+		matches := false
+		if !row.deleted {
+			var err error
+			if matches, err = f.eval(row.datums, row.tableDesc); err != nil {
+				return false, err
+			}
+		}
+		if !matches {
+			if row.prevDatums == nil {
+				// The row didn't exist before the change, or it's emitted by the
+				// initial scan. Deletions of rows that didn't exist are kept, since
+				// they're harmless.
+				if !row.deleted {
+					return false, nil
+				}
+			} else if prevMatches, err := f.eval(row.prevDatums, row.prevTableDesc); err != nil {
+				return false, err
+			} else if !prevMatches {
+				return false, nil
+			}
+			row.deleted = true
+		}
+	}
+	if f.columns != nil {
+		var err error
+		if row.datums, row.tableDesc, err = f.project(row.datums, row.tableDesc); err != nil {
+			return false, err
+		}
+		if row.prevDatums != nil {
+			row.prevDatums, row.prevTableDesc, err = f.project(row.prevDatums, row.prevTableDesc)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+	return true, nil
+}
+
+func (f *rowFilter) eval(
+	datums sqlbase.EncDatumRow, tableDesc *sqlbase.TableDescriptor,
+) (bool, error) {
+	t, err := f.forTable(tableDesc)
+	if err != nil {
+		return false, err
+	}
+	return t.eval(f.evalCtx, datums)
+}
+
+func (f *rowFilter) project(
+	datums sqlbase.EncDatumRow, tableDesc *sqlbase.TableDescriptor,
+) (sqlbase.EncDatumRow, *sqlbase.TableDescriptor, error) {
+	t, err := f.forTable(tableDesc)
+	if err != nil {
+		return nil, nil, err
+	}
+	projected := make(sqlbase.EncDatumRow, len(t.colIdxs))
+	for i, colIdx := range t.colIdxs {
+		projected[i] = datums[colIdx]
+	}
+	return projected, t.projected, nil
+}
+
+// eval returns whether the predicate is true for the given row. A NULL result
+// counts as false, like in a WHERE clause.
+func (t *tableRowFilter) eval(evalCtx *tree.EvalContext, datums sqlbase.EncDatumRow) (bool, error) {
+	t.ivars.row = datums
+	evalCtx.PushIVarContainer(t.ivars)
+	defer evalCtx.PopIVarContainer()
+	d, err := t.filter.Eval(evalCtx)
+	if err != nil || d == tree.DNull {
+		return false, err
+	}
+	b, err := tree.GetBool(d)
+	return bool(b), err
+}
+
+// filterRows applies the rowFilter to the rows returned by the given closure,
+// which must be one created by kvsToRows. It returns a closure that may be
+// repeatedly called to advance the changefeed. The returned closure is not
+// threadsafe.
+func filterRows(
+	f *rowFilter, inputFn func(context.Context) ([]emitEntry, error),
+) func(context.Context) ([]emitEntry, error) {
+	return func(ctx context.Context) ([]emitEntry, error) {
+		for {
+			inputs, err := inputFn(ctx)
+			if err != nil {
+				return nil, err
+			}
+			// Filter in place, since the slice is reused by inputFn anyway.
+			output := inputs[:0]
+			for _, input := range inputs {
+				if input.row.datums != nil {
+					if ok, err := f.apply(&input.row); err != nil {
+						return nil, err
+					} else if !ok {
+						continue
+					}
+				}
+				output = append(output, input)
+			}
+			if len(output) > 0 {
+				return output, nil
+			}
+		}
+	}
+}
+
+// rowFilterIVarContainer is a tree.IndexedVarContainer over the columns of a
+// changed row.
+type rowFilterIVarContainer struct {
+	cols  []sqlbase.ColumnDescriptor
+	row   sqlbase.EncDatumRow
+	alloc *sqlbase.DatumAlloc
+}
+
+var _ tree.IndexedVarContainer = &rowFilterIVarContainer{}
+
+// IndexedVarEval implements the tree.IndexedVarContainer interface.
+func (c *rowFilterIVarContainer) IndexedVarEval(
+	idx int, _ *tree.EvalContext,
+) (tree.Datum, error) {
+	d := &c.row[idx]
+	if err := d.EnsureDecoded(&c.cols[idx].Type, c.alloc); err != nil {
+		return nil, err
+	}
+	return d.Datum, nil
+}
+
+// IndexedVarResolvedType implements the tree.IndexedVarContainer interface.
+func (c *rowFilterIVarContainer) IndexedVarResolvedType(idx int) *types.T {
+	return &c.cols[idx].Type
+}
+
+// IndexedVarNodeFormatter implements the tree.IndexedVarContainer interface.
+func (c *rowFilterIVarContainer) IndexedVarNodeFormatter(idx int) tree.NodeFormatter {
+	n := tree.Name(c.cols[idx].Name)
+	return &n
+}
@@ -174,6 +174,59 @@ func getSink(
 		makeSink = func() (Sink, error) {
 			return makeCloudStorageSink(u.String(), nodeID, fileSize, settings, opts)
 		}
+	case u.Scheme == sinkSchemeWebhookHTTPS:
+		cfg := webhookSinkConfig{
+			batchSize:      defaultWebhookBatchSize,
+			batchInterval:  defaultWebhookBatchInterval,
+			requestTimeout: defaultWebhookRequestTimeout,
+			retryOpts:      defaultWebhookRetryOptions,
+		}
+		if batchSizeParam := q.Get(sinkParamBatchSize); batchSizeParam != `` {
+			if cfg.batchSize, err = humanizeutil.ParseBytes(batchSizeParam); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, batchSizeParam)
+			}
+		}
+		q.Del(sinkParamBatchSize)
+		for param, dest := range map[string]*time.Duration{
+			sinkParamBatchInterval:  &cfg.batchInterval,
+			sinkParamRequestTimeout: &cfg.requestTimeout,
+			sinkParamInitialBackoff: &cfg.retryOpts.InitialBackoff,
+			sinkParamMaxBackoff:     &cfg.retryOpts.MaxBackoff,
+		} {
+			if v := q.Get(param); v != `` {
+				if *dest, err = time.ParseDuration(v); err != nil {
+					return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, v)
+				}
+				if *dest <= 0 {
+					return nil, errors.Errorf(`param %s must be positive: %s`, param, v)
+				}
+			}
+			q.Del(param)
+		}
+		if maxRetriesParam := q.Get(sinkParamMaxRetries); maxRetriesParam != `` {
+			if cfg.retryOpts.MaxRetries, err = strconv.Atoi(maxRetriesParam); err != nil {
+				return nil, pgerror.Wrapf(err, pgcode.Syntax, `parsing %s`, maxRetriesParam)
+			}
+			if cfg.retryOpts.MaxRetries < 0 {
+				return nil, errors.Errorf(`param %s must not be negative: %s`, sinkParamMaxRetries, maxRetriesParam)
+			}
+		}
+		q.Del(sinkParamMaxRetries)
+		for param, dest := range map[string]*[]byte{
+			sinkParamCACert:     &cfg.caCert,
+			sinkParamClientCert: &cfg.clientCert,
+			sinkParamClientKey:  &cfg.clientKey,
+		} {
+			if v := q.Get(param); v != `` {
+				if *dest, err = base64.StdEncoding.DecodeString(v); err != nil {
+					return nil, errors.Errorf(`param %s must be base 64 encoded: %s`, param, err)
+				}
+			}
+			q.Del(param)
+		}
+		makeSink = func() (Sink, error) {
+			return makeWebhookSink(u, cfg, opts)
+		}
 	case u.Scheme == sinkSchemeExperimentalSQL:
 		// Swap the changefeed prefix for the sql connection one that sqlSink
 		// expects.
@@ -78,14 +78,18 @@ type cloudStorageSinkFile struct {
 // cloudStorageSink in a running process and `<file_id>` is a unique id for each
 // file written by a given `<sink_id>`.
 //
-// `<ext>` implies the format of the file: currently the only option is
-// `ndjson`, which means a text file conforming to the "Newline Delimited JSON"
-// spec.
+// `<ext>` implies the format of the file: either `ndjson`, which means a text
+// file conforming to the "Newline Delimited JSON" spec, or `csv`, which means
+// a text file of RFC 4180 records without a header.
 //
-// Each record in the data files is a value, keys are not included, so the
-// `envelope` option must be set to `value_only`. Within a file, records are not
-// guaranteed to be sorted by timestamp. A duplicate of some records might exist
-// in a different file or even in the same file.
+// Each record in the data files is a value, keys are not included, so JSON
+// files require the `key_in_value` option. CSV records are always whole rows
+// with `envelope=row`, which have no room for deletions, so every CSV record
+// starts with an operation field: `U` followed by the row for a row that was
+// inserted or updated, or `D` followed by the primary key for a row that was
+// deleted. Within a file, records are not guaranteed to be sorted by
+// timestamp. A duplicate of some records might exist in a different
+// file or even in the same file.
 //
 // The resolved timestamp files are named `<timestamp>.RESOLVED`. This is
 // carefully done so that we can offer the following external guarantee: At any
@@ -105,6 +109,8 @@ type cloudStorageSink struct {
 
 	ext           string
 	recordDelimFn func(io.Writer) error
+	// csvOpField is set if records are prefixed with an operation field.
+	csvOpField bool
 
 	es     storageccl.ExportStorage
 	fileID int64
@@ -113,6 +119,12 @@ type cloudStorageSink struct {
 
 var cloudStorageSinkIDAtomic int64
 
+// Operation fields that prefix the CSV records written by cloudStorageSink.
+const (
+	csvOpUpsert = `U,`
+	csvOpDelete = `D,`
+)
+
 func makeCloudStorageSink(
 	baseURI string,
 	nodeID roachpb.NodeID,
@@ -134,29 +146,35 @@ func makeCloudStorageSink(
 		partitionFormat:   defaultPartitionFormat,
 	}
 
+	newlineDelimFn := func(w io.Writer) error {
+		_, err := w.Write([]byte{'\n'})
+		return err
+	}
 	switch formatType(opts[optFormat]) {
 	case optFormatJSON:
 		// TODO(dan): It seems like these should be on the encoder, but that
 		// would require a bit of refactoring.
 		s.ext = `.ndjson`
-		s.recordDelimFn = func(w io.Writer) error {
-			_, err := w.Write([]byte{'\n'})
-			return err
+		s.recordDelimFn = newlineDelimFn
+
+		switch envelopeType(opts[optEnvelope]) {
+		case optEnvelopeWrapped:
+		default:
+			return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+				optEnvelope, opts[optEnvelope])
 		}
-	default:
-		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
-			optFormat, opts[optFormat])
-	}
 
-	switch envelopeType(opts[optEnvelope]) {
-	case optEnvelopeWrapped:
+		if _, ok := opts[optKeyInValue]; !ok {
+			return nil, errors.Errorf(`this sink requires the WITH %s option`, optKeyInValue)
+		}
+	case optFormatCSV:
+		// The csv encoder only supports envelope=row, which it checks itself.
+		s.ext = `.csv`
+		s.recordDelimFn = newlineDelimFn
+		s.csvOpField = true
 	default:
 		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
-			optEnvelope, opts[optEnvelope])
-	}
-
-	if _, ok := opts[optKeyInValue]; !ok {
-		return nil, errors.Errorf(`this sink requires the WITH %s option`, optKeyInValue)
+			optFormat, opts[optFormat])
 	}
 
 	ctx := context.TODO()
@@ -170,7 +188,7 @@ func makeCloudStorageSink(
 
 // EmitRow implements the Sink interface.
 func (s *cloudStorageSink) EmitRow(
-	ctx context.Context, table *sqlbase.TableDescriptor, _, value []byte, updated hlc.Timestamp,
+	ctx context.Context, table *sqlbase.TableDescriptor, rowKey, value []byte, updated hlc.Timestamp,
 ) error {
 	if s.files == nil {
 		return errors.New(`cannot EmitRow on a closed sink`)
@@ -191,6 +209,17 @@ func (s *cloudStorageSink) EmitRow(
 		file.earliestTs = updated
 	}
 
+	if s.csvOpField {
+		// The csv encoder returns no value for deletions, so the primary key is
+		// written out instead.
+		op := csvOpUpsert
+		if value == nil {
+			op, value = csvOpDelete, rowKey
+		}
+		if _, err := file.buf.WriteString(op); err != nil {
+			return err
+		}
+	}
 	// TODO(dan): Memory monitoring for this
 	if _, err := file.buf.Write(value); err != nil {
 		return err
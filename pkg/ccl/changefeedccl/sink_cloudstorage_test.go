@@ -91,6 +91,38 @@ func TestCloudStorageSink(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, `{"resolved":"5.0000000000"}`, string(resolvedFile))
 	})
+	t.Run(`csv`, func(t *testing.T) {
+		t1 := &sqlbase.TableDescriptor{Name: `t1`}
+		csvOpts := map[string]string{
+			optFormat:   string(optFormatCSV),
+			optEnvelope: string(optEnvelopeRow),
+		}
+		csvEncoder, err := makeCSVEncoder(csvOpts)
+		require.NoError(t, err)
+
+		sinkDir := `csv`
+		s, err := makeCloudStorageSink(`nodelocal:///`+sinkDir, 1, unlimitedFileSize, settings, csvOpts)
+		require.NoError(t, err)
+		s.(*cloudStorageSink).sinkID = 7 // Force a deterministic sinkID.
+
+		// Deletions have no value in csv, so their key is written out with a
+		// delete marker.
+		require.NoError(t, s.EmitRow(ctx, t1, noKey, []byte(`1,a`), ts(1)))
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`2`), nil, ts(1)))
+		require.NoError(t, s.EmitRow(ctx, t1, noKey, []byte(`3,"b,c"`), ts(1)))
+		require.NoError(t, s.Flush(ctx))
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, csvEncoder, ts(5)))
+
+		dataFile, err := ioutil.ReadFile(filepath.Join(
+			dir, sinkDir, `1970-01-01`, `197001010000000000000010000000000-t1-0-1-7-0.csv`))
+		require.NoError(t, err)
+		require.Equal(t, "U,1,a\nD,2\nU,3,\"b,c\"\n", string(dataFile))
+
+		resolvedFile, err := ioutil.ReadFile(filepath.Join(
+			dir, sinkDir, `1970-01-01`, `197001010000000000000050000000000.RESOLVED`))
+		require.NoError(t, err)
+		require.Equal(t, `5.0000000000`, string(resolvedFile))
+	})
 	t.Run(`single-node`, func(t *testing.T) {
 		t1 := &sqlbase.TableDescriptor{Name: `t1`}
 		t2 := &sqlbase.TableDescriptor{Name: `t2`}
@@ -0,0 +1,411 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	defaultWebhookBatchSize      = 1 << 20 // 1MB
+	defaultWebhookBatchInterval  = time.Second
+	defaultWebhookRequestTimeout = 30 * time.Second
+)
+
+var defaultWebhookRetryOptions = retry.Options{
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     30 * time.Second,
+	Multiplier:     2,
+	MaxRetries:     10,
+}
+
+type webhookSinkConfig struct {
+	// batchSize is the number of bytes of buffered messages after which they
+	// are sent out without waiting for a Flush.
+	batchSize int64
+	// batchInterval is how long the oldest buffered message may wait before the
+	// buffered messages are sent out without waiting for a Flush.
+	batchInterval time.Duration
+	caCert        []byte
+	clientCert    []byte
+	clientKey     []byte
+	// requestTimeout bounds each attempt to send a request.
+	requestTimeout time.Duration
+	// retryOpts is the backoff between the attempts to send a request.
+	// retryOpts.MaxRetries is the number of attempts after the first one, so 0
+	// means that requests aren't retried.
+	retryOpts retry.Options
+}
+
+// webhookSinkMessage is a single row in the payload of a request sent by the
+// webhook sink.
+type webhookSinkMessage struct {
+	Topic string          `json:"topic"`
+	Key   json.RawMessage `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// webhookSinkPayload is the body of a request with rows sent by the webhook
+// sink.
+type webhookSinkPayload struct {
+	Payload []webhookSinkMessage `json:"payload"`
+	Length  int                  `json:"length"`
+}
+
+// webhookSink emits to an HTTPS endpoint.
+//
+// Rows are buffered and sent in batches, each batch in the body of a single
+// POST request of the form `{"payload":[{"topic":...,"key":...,"value":...},
+// ...],"length":N}`. A batch is sent once its encoded messages exceed the
+// configured size, once its oldest message is older than the configured
+// interval, or when the sink is flushed. The interval is enforced by a worker
+// goroutine, so that rows are sent out even if no more rows are emitted after
+// them. A failure to send a batch from the worker is only logged; the rows stay
+// buffered and the error is returned by the next Flush if it persists.
+// Resolved timestamps are sent on their own, in the form returned by the
+// encoder, only after all the rows buffered before them have been delivered.
+//
+// Rows are buffered while a batch is being sent, but once the buffered rows
+// reach the batch size, EmitRow sends them itself before buffering another
+// one, which waits for the batch being sent and returns its error if it fails.
+// This bounds the rows held in memory to those of the batch being sent and of
+// a full batch.
+//
+// A request is retried with exponential backoff until it is acknowledged with
+// a 2xx status code or the configured retries are exhausted. Since the
+// changefeed flushes the sink before checkpointing its span frontier, every row
+// is delivered at least once, but a row may be delivered more than once if a
+// request is retried after the endpoint has processed it or the changefeed
+// restarts from an earlier checkpoint.
+type webhookSink struct {
+	cfg    webhookSinkConfig
+	url    string
+	client *http.Client
+
+	// workerCtx is canceled, which interrupts any request sent by the worker,
+	// when the sink is closed.
+	workerCtx    context.Context
+	cancelWorker func()
+	worker       sync.WaitGroup
+	// batchStartedCh is signaled when a message is added to an empty batch.
+	batchStartedCh chan struct{}
+
+	// sendMu is held while a batch or a resolved timestamp is being sent, so
+	// that they are sent one at a time and in order. It must be acquired before
+	// mu.
+	sendMu syncutil.Mutex
+
+	// Synchronized between the client goroutine and the worker goroutine. It is
+	// never held while a request is being sent.
+	mu struct {
+		syncutil.Mutex
+		batch struct {
+			messages []webhookSinkMessage
+			bytes    int64
+			oldest   time.Time
+		}
+		closed bool
+	}
+}
+
+func makeWebhookSink(u *url.URL, cfg webhookSinkConfig, opts map[string]string) (Sink, error) {
+	switch formatType(opts[optFormat]) {
+	case optFormatJSON:
+	default:
+		return nil, errors.Errorf(`this sink is incompatible with %s=%s`,
+			optFormat, opts[optFormat])
+	}
+
+	tlsConfig := &tls.Config{}
+	if cfg.caCert != nil {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM(cfg.caCert) {
+			return nil, errors.Errorf(`param %s does not contain a valid certificate`, sinkParamCACert)
+		}
+		tlsConfig.RootCAs = caCertPool
+	}
+	if (cfg.clientCert == nil) != (cfg.clientKey == nil) {
+		return nil, errors.Errorf(`%s and %s must be provided together`, sinkParamClientCert, sinkParamClientKey)
+	}
+	if cfg.clientCert != nil {
+		cert, err := tls.X509KeyPair(cfg.clientCert, cfg.clientKey)
+		if err != nil {
+			return nil, errors.Wrapf(err, `invalid %s or %s`, sinkParamClientCert, sinkParamClientKey)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	sinkURL := *u
+	sinkURL.Scheme = `https`
+	// All the query parameters have been consumed by the sink configuration.
+	sinkURL.RawQuery = ``
+	sink := &webhookSink{
+		cfg: cfg,
+		url: sinkURL.String(),
+		client: &http.Client{
+			Timeout:   cfg.requestTimeout,
+			Transport: &http.Transport{TLSClientConfig: tlsConfig},
+		},
+		batchStartedCh: make(chan struct{}, 1),
+	}
+	sink.start()
+	return sink, nil
+}
+
+func (s *webhookSink) start() {
+	s.workerCtx, s.cancelWorker = context.WithCancel(context.Background())
+	s.worker.Add(1)
+	go s.workerLoop()
+}
+
+// workerLoop sends out the buffered rows once the oldest of them has been
+// buffered for the configured interval.
+func (s *webhookSink) workerLoop() {
+	defer s.worker.Done()
+
+	var timer timeutil.Timer
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		pending := len(s.mu.batch.messages) > 0
+		wait := s.cfg.batchInterval - timeutil.Since(s.mu.batch.oldest)
+		s.mu.Unlock()
+
+		if !pending {
+			select {
+			case <-s.workerCtx.Done():
+				return
+			case <-s.batchStartedCh:
+			}
+			continue
+		}
+		if wait > 0 {
+			timer.Reset(wait)
+			select {
+			case <-s.workerCtx.Done():
+				return
+			case <-timer.C:
+				timer.Read = true
+			}
+			continue
+		}
+
+		if err := s.flushBatch(s.workerCtx); err != nil {
+			if s.workerCtx.Err() != nil {
+				return
+			}
+			// The rows were buffered again, to be retried after another interval.
+			log.Warningf(s.workerCtx, `webhook sink failed to send a batch: %v`, err)
+		}
+	}
+}
+
+// EmitRow implements the Sink interface.
+func (s *webhookSink) EmitRow(
+	ctx context.Context, table *sqlbase.TableDescriptor, key, value []byte, _ hlc.Timestamp,
+) error {
+	s.mu.Lock()
+	if s.mu.closed {
+		s.mu.Unlock()
+		return errors.New(`cannot EmitRow on a closed sink`)
+	}
+	full := s.mu.batch.bytes >= s.cfg.batchSize
+	s.mu.Unlock()
+	if full {
+		// Apply backpressure instead of buffering rows without bound while a
+		// batch is being sent or the endpoint is unavailable.
+		if err := s.flushBatch(ctx); err != nil {
+			return err
+		}
+	}
+
+	// The key and value are only valid until the next call, so they're copied.
+	msg := webhookSinkMessage{Topic: table.Name}
+	if key != nil {
+		msg.Key = append(json.RawMessage(nil), key...)
+	}
+	if value != nil {
+		msg.Value = append(json.RawMessage(nil), value...)
+	}
+	s.mu.Lock()
+	s.addLocked([]webhookSinkMessage{msg}, int64(len(key)+len(value)))
+	full = s.mu.batch.bytes >= s.cfg.batchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.flushBatch(ctx)
+	}
+	return nil
+}
+
+// EmitResolvedTimestamp implements the Sink interface.
+func (s *webhookSink) EmitResolvedTimestamp(
+	ctx context.Context, encoder Encoder, resolved hlc.Timestamp,
+) error {
+	s.mu.Lock()
+	closed := s.mu.closed
+	s.mu.Unlock()
+	if closed {
+		return errors.New(`cannot EmitResolvedTimestamp on a closed sink`)
+	}
+	// The resolved timestamp must not overtake any of the rows emitted before
+	// it.
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	if err := s.flushBatchSendLocked(ctx); err != nil {
+		return err
+	}
+	var noTopic string
+	payload, err := encoder.EncodeResolvedTimestamp(noTopic, resolved)
+	if err != nil {
+		return err
+	}
+	return s.send(ctx, payload)
+}
+
+// Flush implements the Sink interface.
+func (s *webhookSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	closed := s.mu.closed
+	s.mu.Unlock()
+	if closed {
+		return errors.New(`cannot Flush on a closed sink`)
+	}
+	return s.flushBatch(ctx)
+}
+
+// Close implements the Sink interface.
+func (s *webhookSink) Close() error {
+	// Stop the worker first, interrupting any request it's sending.
+	s.cancelWorker()
+	s.worker.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.mu.closed = true
+	s.mu.batch.messages = nil
+	s.client.CloseIdleConnections()
+	return nil
+}
+
+// addLocked appends messages, of the given total size, to the buffered rows.
+// s.mu must be held.
+func (s *webhookSink) addLocked(messages []webhookSinkMessage, bytes int64) {
+	if len(s.mu.batch.messages) == 0 {
+		s.mu.batch.oldest = timeutil.Now()
+		select {
+		case s.batchStartedCh <- struct{}{}:
+		default:
+		}
+	}
+	s.mu.batch.messages = append(s.mu.batch.messages, messages...)
+	s.mu.batch.bytes += bytes
+}
+
+// flushBatch sends out all the buffered rows.
+func (s *webhookSink) flushBatch(ctx context.Context) error {
+	s.sendMu.Lock()
+	defer s.sendMu.Unlock()
+	return s.flushBatchSendLocked(ctx)
+}
+
+// flushBatchSendLocked sends out all the buffered rows. If they can't be sent,
+// they're buffered again, ahead of the rows emitted in the meantime. s.sendMu
+// must be held.
+func (s *webhookSink) flushBatchSendLocked(ctx context.Context) error {
+	s.mu.Lock()
+	messages, bytes := s.mu.batch.messages, s.mu.batch.bytes
+	s.mu.batch.messages, s.mu.batch.bytes = nil, 0
+	s.mu.Unlock()
+	if len(messages) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(webhookSinkPayload{
+		Payload: messages,
+		Length:  len(messages),
+	})
+	if err == nil {
+		err = s.send(ctx, body)
+	}
+	if err != nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if !s.mu.closed {
+			emitted, emittedBytes := s.mu.batch.messages, s.mu.batch.bytes
+			s.mu.batch.messages, s.mu.batch.bytes = nil, 0
+			// This also makes the worker wait for another interval before it
+			// tries again.
+			s.addLocked(append(messages, emitted...), bytes+emittedBytes)
+		}
+		return err
+	}
+	return nil
+}
+
+// send POSTs body to the endpoint, retrying until it's acknowledged or the
+// retries are exhausted.
+func (s *webhookSink) send(ctx context.Context, body []byte) error {
+	var err error
+	for r, attempt := retry.StartWithCtx(ctx, s.cfg.retryOpts), 0; r.Next(); attempt++ {
+		if err = s.sendOnce(ctx, body); err == nil {
+			return nil
+		}
+		// A MaxRetries of 0 would make the retry loop go on forever.
+		if attempt >= s.cfg.retryOpts.MaxRetries {
+			break
+		}
+		if log.V(1) {
+			log.Infof(ctx, `webhook sink request failed, retrying: %v`, err)
+		}
+	}
+	if err == nil {
+		// The context was canceled before the first attempt.
+		err = ctx.Err()
+	}
+	return err
+}
+
+func (s *webhookSink) sendOnce(ctx context.Context, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set(`Content-Type`, `application/json`)
+	resp, err := s.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return errors.Errorf(`webhook sink: %s: %s`, resp.Status, bytes.TrimSpace(msg))
+	}
+	// Drain the body so the connection can be reused.
+	_, err = io.Copy(ioutil.Discard, resp.Body)
+	return err
+}
@@ -0,0 +1,285 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package changefeedccl
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/retry"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookSink(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	var mu struct {
+		syncutil.Mutex
+		bodies []string
+		// failures is the number of the upcoming requests to be rejected.
+		failures int
+		// blockCh, if set, is waited on by the requests before they're handled.
+		blockCh chan struct{}
+	}
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		blockCh := mu.blockCh
+		mu.Unlock()
+		if blockCh != nil {
+			<-blockCh
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if mu.failures > 0 {
+			mu.failures--
+			http.Error(w, `try again`, http.StatusServiceUnavailable)
+			return
+		}
+		mu.bodies = append(mu.bodies, string(body))
+	}))
+	defer srv.Close()
+	bodies := func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		ret := mu.bodies
+		mu.bodies = nil
+		return ret
+	}
+
+	caCert := pem.EncodeToMemory(&pem.Block{Type: `CERTIFICATE`, Bytes: srv.Certificate().Raw})
+	srvURL, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+	sinkURI := func(params url.Values) string {
+		params.Set(sinkParamCACert, base64.StdEncoding.EncodeToString(caCert))
+		return fmt.Sprintf(`%s://%s/changes?%s`, sinkSchemeWebhookHTTPS, srvURL.Host, params.Encode())
+	}
+
+	settings := cluster.MakeTestingClusterSettings()
+	opts := map[string]string{
+		optFormat:   string(optFormatJSON),
+		optEnvelope: string(optEnvelopeWrapped),
+	}
+	e, err := makeJSONEncoder(opts)
+	require.NoError(t, err)
+	t1 := &sqlbase.TableDescriptor{Name: `t1`}
+	t2 := &sqlbase.TableDescriptor{Name: `t2`}
+	ts := func(i int64) hlc.Timestamp { return hlc.Timestamp{WallTime: i} }
+
+	t.Run(`batching`, func(t *testing.T) {
+		s, err := getSink(sinkURI(url.Values{sinkParamBatchInterval: {`1h`}}), 1, opts, nil, settings)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		// Empty flush sends nothing.
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string(nil), bodies())
+
+		// Rows are buffered until the sink is flushed.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"after":{"a":1}}`), ts(1)))
+		require.NoError(t, s.EmitRow(ctx, t2, []byte(`[2]`), []byte(`{"after":{"b":2}}`), ts(1)))
+		require.Equal(t, []string(nil), bodies())
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"after":{"a":1}}},` +
+				`{"topic":"t2","key":[2],"value":{"after":{"b":2}}}],"length":2}`,
+		}, bodies())
+
+		// A resolved timestamp is sent after the buffered rows.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[3]`), []byte(`{"after":{"a":3}}`), ts(2)))
+		require.NoError(t, s.EmitResolvedTimestamp(ctx, e, ts(5)))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[3],"value":{"after":{"a":3}}}],"length":1}`,
+			`{"resolved":"5.0000000000"}`,
+		}, bodies())
+	})
+
+	t.Run(`batch-size`, func(t *testing.T) {
+		s, err := getSink(sinkURI(url.Values{
+			sinkParamBatchSize: {`30B`}, sinkParamBatchInterval: {`1h`},
+		}), 1, opts, nil, settings)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"after":{"a":1}}`), ts(1)))
+		require.Equal(t, []string(nil), bodies())
+		// The second row makes the batch exceed its size.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"after":{"a":2}}`), ts(1)))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"after":{"a":1}}},` +
+				`{"topic":"t1","key":[2],"value":{"after":{"a":2}}}],"length":2}`,
+		}, bodies())
+	})
+
+	t.Run(`batch-interval`, func(t *testing.T) {
+		s, err := getSink(sinkURI(url.Values{sinkParamBatchInterval: {`10ms`}}), 1, opts, nil, settings)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		// The row is sent out once the interval elapses, even though no other row
+		// is emitted and the sink isn't flushed.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"after":{"a":1}}`), ts(1)))
+		var sent []string
+		testutils.SucceedsSoon(t, func() error {
+			sent = append(sent, bodies()...)
+			if len(sent) == 0 {
+				return errors.New(`batch not sent yet`)
+			}
+			return nil
+		})
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"after":{"a":1}}}],"length":1}`,
+		}, sent)
+	})
+
+	t.Run(`retries`, func(t *testing.T) {
+		s, err := getSink(sinkURI(url.Values{
+			sinkParamMaxRetries: {`2`}, sinkParamInitialBackoff: {`1ms`}, sinkParamMaxBackoff: {`1ms`},
+		}), 1, opts, nil, settings)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, s.Close()) }()
+		require.Equal(t, retry.Options{
+			InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, Multiplier: 2, MaxRetries: 2,
+		}, s.(*webhookSink).cfg.retryOpts)
+
+		// The request is retried until it succeeds.
+		mu.Lock()
+		mu.failures = 2
+		mu.Unlock()
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"after":{"a":1}}`), ts(1)))
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"after":{"a":1}}}],"length":1}`,
+		}, bodies())
+
+		// Once the retries are exhausted, the error is returned and the rows stay
+		// buffered for the next flush.
+		mu.Lock()
+		mu.failures = 3
+		mu.Unlock()
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"after":{"a":2}}`), ts(1)))
+		require.EqualError(t, s.Flush(ctx), `webhook sink: 503 Service Unavailable: try again`)
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[2],"value":{"after":{"a":2}}}],"length":1}`,
+		}, bodies())
+
+		// Without retries, a request is only attempted once.
+		s.(*webhookSink).cfg.retryOpts.MaxRetries = 0
+		mu.Lock()
+		mu.failures = 1
+		mu.Unlock()
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[3]`), []byte(`{"after":{"a":3}}`), ts(1)))
+		require.EqualError(t, s.Flush(ctx), `webhook sink: 503 Service Unavailable: try again`)
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[3],"value":{"after":{"a":3}}}],"length":1}`,
+		}, bodies())
+	})
+
+	t.Run(`backpressure`, func(t *testing.T) {
+		s, err := getSink(sinkURI(url.Values{
+			sinkParamBatchSize: {`30B`}, sinkParamBatchInterval: {`1ms`},
+		}), 1, opts, nil, settings)
+		require.NoError(t, err)
+		defer func() { require.NoError(t, s.Close()) }()
+
+		blockCh := make(chan struct{})
+		mu.Lock()
+		mu.blockCh = blockCh
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			mu.blockCh = nil
+			mu.Unlock()
+		}()
+
+		buffered := func(n int) func() error {
+			return func() error {
+				ws := s.(*webhookSink)
+				ws.mu.Lock()
+				defer ws.mu.Unlock()
+				if len(ws.mu.batch.messages) != n {
+					return errors.Errorf(`%d rows buffered, expected %d`, len(ws.mu.batch.messages), n)
+				}
+				return nil
+			}
+		}
+
+		// Wait for the worker to start sending the first row.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[1]`), []byte(`{"after":{"a":1}}`), ts(1)))
+		testutils.SucceedsSoon(t, buffered(0))
+
+		// Rows are still buffered while the batch is being sent, but once the
+		// buffered rows reach the batch size, EmitRow waits for the batch.
+		require.NoError(t, s.EmitRow(ctx, t1, []byte(`[2]`), []byte(`{"after":{"a":2}}`), ts(1)))
+		emitted := make(chan error, 1)
+		go func() {
+			emitted <- s.EmitRow(ctx, t1, []byte(`[3]`), []byte(`{"after":{"a":3}}`), ts(1))
+		}()
+		testutils.SucceedsSoon(t, buffered(2))
+		select {
+		case err := <-emitted:
+			t.Fatalf(`EmitRow returned while the buffer is full: %v`, err)
+		case <-time.After(10 * time.Millisecond):
+		}
+
+		close(blockCh)
+		require.NoError(t, <-emitted)
+		require.NoError(t, s.Flush(ctx))
+		require.Equal(t, []string{
+			`{"payload":[{"topic":"t1","key":[1],"value":{"after":{"a":1}}}],"length":1}`,
+			`{"payload":[{"topic":"t1","key":[2],"value":{"after":{"a":2}}},` +
+				`{"topic":"t1","key":[3],"value":{"after":{"a":3}}}],"length":2}`,
+		}, bodies())
+	})
+
+	t.Run(`errors`, func(t *testing.T) {
+		_, err := getSink(sinkURI(url.Values{sinkParamBatchSize: {`big`}}), 1, opts, nil, settings)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), `parsing big`)
+
+		_, err = getSink(sinkURI(url.Values{sinkParamMaxRetries: {`-1`}}), 1, opts, nil, settings)
+		require.EqualError(t, err, `param max_retries must not be negative: -1`)
+
+		_, err = getSink(sinkURI(url.Values{sinkParamRequestTimeout: {`0s`}}), 1, opts, nil, settings)
+		require.EqualError(t, err, `param request_timeout must be positive: 0s`)
+
+		_, err = getSink(sinkURI(url.Values{sinkParamClientCert: {`Zm9v`}}), 1, opts, nil, settings)
+		require.EqualError(t, err, `client_cert and client_key must be provided together`)
+
+		avroOpts := map[string]string{optFormat: string(optFormatAvro)}
+		_, err = getSink(sinkURI(url.Values{}), 1, avroOpts, nil, settings)
+		require.EqualError(t, err, `this sink is incompatible with format=experimental_avro`)
+
+		s, err := getSink(sinkURI(url.Values{}), 1, opts, nil, settings)
+		require.NoError(t, err)
+		require.NoError(t, s.Close())
+		require.EqualError(t, s.Flush(ctx), `cannot Flush on a closed sink`)
+	})
+}
@@ -11,7 +11,6 @@
 package followerreadsccl
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/base"
@@ -19,7 +18,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/kv"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
-	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan/replicaoracle"
@@ -31,39 +29,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
-// followerReadMultiple is the multiple of kv.closed_timestmap.target_duration
-// which the implementation of the follower read capable replica policy ought
-// to use to determine if a request can be used for reading.
-// FollowerReadMultiple is a hidden setting.
-var followerReadMultiple = func() *settings.FloatSetting {
-	s := settings.RegisterValidatedFloatSetting(
-		"kv.follower_read.target_multiple",
-		"if above 1, encourages the distsender to perform a read against the "+
-			"closest replica if a request is older than kv.closed_timestamp.target_duration"+
-			" * (1 + kv.closed_timestamp.close_fraction * this) less a clock uncertainty "+
-			"interval. This value also is used to create follower_timestamp().",
-		3,
-		func(v float64) error {
-			if v < 1 {
-				return fmt.Errorf("%v is not >= 1", v)
-			}
-			return nil
-		},
-	)
-	s.SetSensitive()
-	return s
-}()
-
 // getFollowerReadOffset returns the offset duration which should be used to as
 // the offset from now to request a follower read. The same value less the clock
 // uncertainty, then is used to determine at the kv layer if a query can use a
 // follower read.
 func getFollowerReadDuration(st *cluster.Settings) time.Duration {
-	targetMultiple := followerReadMultiple.Get(&st.SV)
-	targetDuration := closedts.TargetDuration.Get(&st.SV)
-	closeFraction := closedts.CloseFraction.Get(&st.SV)
-	return -1 * time.Duration(float64(targetDuration)*
-		(1+closeFraction*targetMultiple))
+	return -1 * closedts.FollowerReadLag(&st.SV)
 }
 
 func checkEnterpriseEnabled(clusterID uuid.UUID, st *cluster.Settings) error {
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlplan/replicaoracle"
 	"github.com/cockroachdb/cockroach/pkg/storage"
+	"github.com/cockroachdb/cockroach/pkg/storage/closedts"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/testutils"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -127,11 +128,11 @@ func TestFollowerReadMultipleValidation(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	defer func() {
 		if r := recover(); r == nil {
-			t.Fatalf("expected panic from setting followerReadMultiple to .1")
+			t.Fatalf("expected panic from setting FollowerReadMultiple to .1")
 		}
 	}()
 	st := cluster.MakeTestingClusterSettings()
-	followerReadMultiple.Override(&st.SV, .1)
+	closedts.FollowerReadMultiple.Override(&st.SV, .1)
 }
 
 // TestOracle tests the OracleFactory exposed by this package.
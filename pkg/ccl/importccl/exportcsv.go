@@ -12,6 +12,7 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
 
@@ -72,7 +73,13 @@ func exportPlanHook(
 		return nil, nil, nil, false, err
 	}
 
-	if exportStmt.FileFormat != "CSV" {
+	var format roachpb.IOFileFormat_FileFormat
+	switch exportStmt.FileFormat {
+	case "CSV":
+		format = roachpb.IOFileFormat_CSV
+	case "PARQUET":
+		format = roachpb.IOFileFormat_Parquet
+	default:
 		return nil, nil, nil, false, errors.Errorf("unsupported export format: %q", exportStmt.FileFormat)
 	}
 
@@ -114,6 +121,15 @@ func exportPlanHook(
 			return err
 		}
 
+		if format != roachpb.IOFileFormat_CSV {
+			for _, opt := range []string{exportOptionDelimiter, exportOptionNullAs} {
+				if _, ok := opts[opt]; ok {
+					return pgerror.Newf(pgcode.InvalidParameterValue,
+						"%s option is not supported for %s", opt, exportStmt.FileFormat)
+				}
+			}
+		}
+
 		csvOpts := roachpb.CSVOptions{}
 
 		if override, ok := opts[exportOptionDelimiter]; ok {
@@ -138,11 +154,17 @@ func exportPlanHook(
 			}
 		}
 
+		pattern := exportFilePatternDefault
+		if format == roachpb.IOFileFormat_Parquet {
+			pattern = exportFilePatternPart + ".parquet"
+		}
+
 		out := distsqlpb.ProcessorCoreUnion{CSVWriter: &distsqlpb.CSVWriterSpec{
 			Destination: file,
-			NamePattern: exportFilePatternDefault,
+			NamePattern: pattern,
 			Options:     csvOpts,
 			ChunkRows:   int64(chunk),
+			Format:      format,
 		}}
 
 		rows := rowcontainer.NewRowContainer(
@@ -215,26 +237,26 @@ func (sp *csvWriter) Run(ctx context.Context) {
 		input := distsqlrun.MakeNoMetadataRowSource(sp.input, sp.output)
 
 		alloc := &sqlbase.DatumAlloc{}
-
-		var buf bytes.Buffer
-		writer := csv.NewWriter(&buf)
-		if sp.spec.Options.Comma != 0 {
-			writer.Comma = sp.spec.Options.Comma
-		}
-		nullsAs := ""
-		if sp.spec.Options.NullEncoding != nil {
-			nullsAs = *sp.spec.Options.NullEncoding
-		}
 		f := tree.NewFmtCtx(tree.FmtExport)
 		defer f.Close()
 
-		csvRow := make([]string, len(typs))
+		var buf bytes.Buffer
+		newFile := func() (exportFileWriter, error) {
+			if sp.spec.Format == roachpb.IOFileFormat_Parquet {
+				return newParquetFileWriter(&buf, sp.spec.ColumnNames, typs, alloc, f)
+			}
+			return newCSVFileWriter(&buf, sp.spec.Options, typs, alloc, f), nil
+		}
 
 		chunk := 0
 		done := false
 		for {
 			var rows int64
 			buf.Reset()
+			w, err := newFile()
+			if err != nil {
+				return err
+			}
 			for {
 				if sp.spec.ChunkRows > 0 && rows >= sp.spec.ChunkRows {
 					break
@@ -249,26 +271,16 @@ func (sp *csvWriter) Run(ctx context.Context) {
 				}
 				rows++
 
-				for i, ed := range row {
-					if ed.IsNull() {
-						csvRow[i] = nullsAs
-						continue
-					}
-					if err := ed.EnsureDecoded(&typs[i], alloc); err != nil {
-						return err
-					}
-					ed.Datum.Format(f)
-					csvRow[i] = f.String()
-					f.Reset()
-				}
-				if err := writer.Write(csvRow); err != nil {
+				if err := w.writeRow(row); err != nil {
 					return err
 				}
 			}
 			if rows < 1 {
 				break
 			}
-			writer.Flush()
+			if err := w.finish(); err != nil {
+				return err
+			}
 
 			conf, err := storageccl.ExportStorageConfFromURI(sp.spec.Destination)
 			if err != nil {
@@ -325,6 +337,68 @@ func (sp *csvWriter) Run(ctx context.Context) {
 		ctx, sp.output, err, func(context.Context) {} /* pushTrailingMeta */, sp.input)
 }
 
+// exportFileWriter writes the rows of an exported file in some format.
+type exportFileWriter interface {
+	// writeRow appends a row to the file.
+	writeRow(row sqlbase.EncDatumRow) error
+	// finish writes out anything buffered, after which the file is complete.
+	finish() error
+}
+
+// csvFileWriter writes rows into a CSV file.
+type csvFileWriter struct {
+	w       *csv.Writer
+	nullsAs string
+	typs    []types.T
+	alloc   *sqlbase.DatumAlloc
+	f       *tree.FmtCtx
+	row     []string
+}
+
+var _ exportFileWriter = &csvFileWriter{}
+
+func newCSVFileWriter(
+	w io.Writer, opts roachpb.CSVOptions, typs []types.T, alloc *sqlbase.DatumAlloc, f *tree.FmtCtx,
+) *csvFileWriter {
+	c := &csvFileWriter{
+		w:     csv.NewWriter(w),
+		typs:  typs,
+		alloc: alloc,
+		f:     f,
+		row:   make([]string, len(typs)),
+	}
+	if opts.Comma != 0 {
+		c.w.Comma = opts.Comma
+	}
+	if opts.NullEncoding != nil {
+		c.nullsAs = *opts.NullEncoding
+	}
+	return c
+}
+
+// writeRow is part of the exportFileWriter interface.
+func (c *csvFileWriter) writeRow(row sqlbase.EncDatumRow) error {
+	for i, ed := range row {
+		if ed.IsNull() {
+			c.row[i] = c.nullsAs
+			continue
+		}
+		if err := ed.EnsureDecoded(&c.typs[i], c.alloc); err != nil {
+			return err
+		}
+		ed.Datum.Format(c.f)
+		c.row[i] = c.f.String()
+		c.f.Reset()
+	}
+	return c.w.Write(c.row)
+}
+
+// finish is part of the exportFileWriter interface.
+func (c *csvFileWriter) finish() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
 func init() {
 	sql.AddPlanHook(exportPlanHook)
 	distsqlrun.NewCSVWriterProcessor = newCSVWriterProcessor
@@ -13,6 +13,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -219,3 +220,46 @@ func TestExportShow(t *testing.T) {
 		t.Fatalf("expected %q, got %q", expected, got)
 	}
 }
+
+func TestExportImportParquet(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(context.Background())
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const schema = `(
+		i INT PRIMARY KEY, b BOOL, f FLOAT, d DECIMAL(10, 2), u DECIMAL, dt DATE,
+		ts TIMESTAMP, tz TIMESTAMPTZ, tm TIME, bs BYTES, s STRING, j JSONB
+	)`
+	sqlDB.Exec(t, `CREATE TABLE t `+schema)
+	sqlDB.Exec(t, `INSERT INTO t VALUES
+		(1, true, 1.5, -12.34, 1.23456789, '2019-03-01', '2019-03-01 12:34:56.789012',
+			'2019-03-01 12:34:56.789012+02', '12:34:56.789012', b'\x00\xff', 'a,b', '{"a": [1, 2]}'),
+		(2, false, -0.25, 99999999.99, -1e-10, '1900-01-01', '1900-01-01 00:00:00',
+			'2100-12-31 23:59:59+00', '00:00:00', b'', '✅', 'null'),
+		(3, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL, NULL)`)
+
+	var files []string
+	for _, row := range sqlDB.QueryStr(t,
+		`EXPORT INTO PARQUET 'nodelocal:///t' WITH chunk_rows = 2 FROM SELECT * FROM t`,
+	) {
+		files = append(files, row[0])
+	}
+	if expected := []string{"n1.0.parquet", "n1.1.parquet"}; !reflect.DeepEqual(expected, files) {
+		t.Fatalf("expected files %v, got %v", expected, files)
+	}
+
+	fileList := "'nodelocal:///t/" + strings.Join(files, "', 'nodelocal:///t/") + "'"
+	sqlDB.Exec(t, fmt.Sprintf(`IMPORT TABLE t2 %s PARQUET DATA (%s)`, schema, fileList))
+	sqlDB.CheckQueryResults(t,
+		`SELECT * FROM t2 ORDER BY i`, sqlDB.QueryStr(t, `SELECT * FROM t ORDER BY i`),
+	)
+
+	sqlDB.ExpectErr(t, `delimiter option is not supported for PARQUET`,
+		`EXPORT INTO PARQUET 'nodelocal:///t' WITH delimiter = '|' FROM SELECT * FROM t`)
+	sqlDB.ExpectErr(t, `duplicate column name "a" cannot be used in a Parquet file`,
+		`EXPORT INTO PARQUET 'nodelocal:///t' FROM SELECT i AS a, b AS a FROM t`)
+}
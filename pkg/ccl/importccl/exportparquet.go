@@ -0,0 +1,205 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
+	"github.com/pkg/errors"
+)
+
+// parquetFileWriter writes rows into a Parquet file. Every column is OPTIONAL
+// and is written with the logical type that best matches its SQL type; types
+// without an equivalent in Parquet are written as strings in the same format
+// as in exported CSV files.
+type parquetFileWriter struct {
+	w     *parquet.Writer
+	typs  []types.T
+	alloc *sqlbase.DatumAlloc
+	f     *tree.FmtCtx
+	row   []interface{}
+}
+
+var _ exportFileWriter = &parquetFileWriter{}
+
+func newParquetFileWriter(
+	w io.Writer, names []string, typs []types.T, alloc *sqlbase.DatumAlloc, f *tree.FmtCtx,
+) (*parquetFileWriter, error) {
+	if len(names) != len(typs) {
+		return nil, errors.Errorf("expected %d column names, got %d", len(typs), len(names))
+	}
+	cols, err := parquetColumns(names, typs)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := parquet.NewWriter(w, cols)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetFileWriter{
+		w:     pw,
+		typs:  typs,
+		alloc: alloc,
+		f:     f,
+		row:   make([]interface{}, len(typs)),
+	}, nil
+}
+
+// parquetColumns returns the columns of a Parquet file with the given column
+// names and types.
+func parquetColumns(names []string, typs []types.T) ([]parquet.Column, error) {
+	cols := make([]parquet.Column, len(typs))
+	seen := make(map[string]struct{}, len(names))
+	for i, name := range names {
+		if name == "" {
+			return nil, pgerror.Newf(pgcode.InvalidName,
+				"empty column name cannot be used in a Parquet file; use AS to rename it")
+		}
+		if _, ok := seen[name]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateColumn,
+				"duplicate column name %q cannot be used in a Parquet file; use AS to rename it", name)
+		}
+		seen[name] = struct{}{}
+
+		col := parquet.Column{
+			Path:          []string{name},
+			Type:          parquet.ByteArray,
+			Repetition:    parquet.Optional,
+			ConvertedType: parquet.ConvertedUTF8,
+		}
+		t := &typs[i]
+		switch t.Family() {
+		case types.BoolFamily:
+			col.Type, col.ConvertedType = parquet.Boolean, parquet.NoConvertedType
+		case types.IntFamily:
+			col.Type, col.ConvertedType = parquet.Int64, parquet.NoConvertedType
+		case types.FloatFamily:
+			col.Type, col.ConvertedType = parquet.Double, parquet.NoConvertedType
+		case types.DecimalFamily:
+			// Decimals without a precision have no fixed scale, which Parquet
+			// decimals require, so they are written as strings.
+			if t.Precision() > 0 {
+				col.ConvertedType = parquet.ConvertedDecimal
+				col.Scale, col.Precision = t.Scale(), t.Precision()
+			}
+		case types.DateFamily:
+			col.Type, col.ConvertedType = parquet.Int32, parquet.ConvertedDate
+		case types.TimestampFamily, types.TimestampTZFamily:
+			col.Type, col.ConvertedType = parquet.Int64, parquet.ConvertedTimestampMicros
+		case types.TimeFamily:
+			col.Type, col.ConvertedType = parquet.Int64, parquet.ConvertedTimeMicros
+		case types.BytesFamily:
+			col.ConvertedType = parquet.NoConvertedType
+		}
+		cols[i] = col
+	}
+	return cols, nil
+}
+
+// writeRow is part of the exportFileWriter interface.
+func (p *parquetFileWriter) writeRow(row sqlbase.EncDatumRow) error {
+	for i, ed := range row {
+		if ed.IsNull() {
+			p.row[i] = nil
+			continue
+		}
+		if err := ed.EnsureDecoded(&p.typs[i], p.alloc); err != nil {
+			return err
+		}
+		v, err := p.parquetValue(ed.Datum, &p.typs[i])
+		if err != nil {
+			return err
+		}
+		p.row[i] = v
+	}
+	return p.w.AddRow(p.row)
+}
+
+// finish is part of the exportFileWriter interface.
+func (p *parquetFileWriter) finish() error {
+	return p.w.Close()
+}
+
+// parquetValue returns the value of a datum with the Go type of the physical
+// type of its column, as chosen by parquetColumns.
+func (p *parquetFileWriter) parquetValue(d tree.Datum, t *types.T) (interface{}, error) {
+	switch d := d.(type) {
+	case *tree.DBool:
+		return bool(*d), nil
+	case *tree.DInt:
+		return int64(*d), nil
+	case *tree.DFloat:
+		return float64(*d), nil
+	case *tree.DDecimal:
+		if t.Precision() > 0 {
+			return parquetDecimalBytes(&d.Decimal, t.Scale())
+		}
+	case *tree.DDate:
+		if !d.IsFinite() {
+			return nil, errors.Errorf("cannot export infinite date %s to Parquet", d)
+		}
+		return int32(d.UnixEpochDays()), nil
+	case *tree.DTimestamp:
+		return unixMicros(d.Time), nil
+	case *tree.DTimestampTZ:
+		return unixMicros(d.Time), nil
+	case *tree.DTime:
+		return int64(*d), nil
+	case *tree.DBytes:
+		return []byte(*d), nil
+	}
+	d.Format(p.f)
+	b := []byte(p.f.String())
+	p.f.Reset()
+	return b, nil
+}
+
+// unixMicros returns the number of microseconds since the Unix epoch, without
+// the overflow of UnixNano for times more than 292 years away from it.
+func unixMicros(t time.Time) int64 {
+	return t.Unix()*1000000 + int64(t.Nanosecond()/1000)
+}
+
+// parquetDecimalBytes returns the unscaled value of a decimal at the given
+// scale as a big-endian two's complement integer.
+func parquetDecimalBytes(d *apd.Decimal, scale int32) ([]byte, error) {
+	if d.Form != apd.Finite {
+		return nil, errors.Errorf("cannot export %s decimal to Parquet", d.Form)
+	}
+	var q apd.Decimal
+	if _, err := tree.ExactCtx.Quantize(&q, d, -scale); err != nil {
+		return nil, err
+	}
+	unscaled := new(big.Int).Set(&q.Coeff)
+	if q.Negative {
+		unscaled.Neg(unscaled)
+	}
+	if unscaled.Sign() >= 0 {
+		b := unscaled.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+		return b, nil
+	}
+	// A negative value v is encoded as 2^(8n) + v, where n is the smallest
+	// number of bytes that leaves room for the sign bit.
+	n := uint(unscaled.BitLen()+8) / 8
+	var b big.Int
+	b.Lsh(big.NewInt(1), n*8).Add(&b, unscaled)
+	return b.Bytes(), nil
+}
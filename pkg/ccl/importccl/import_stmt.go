@@ -327,6 +327,12 @@ func importPlanHook(
 				maxRowSize = int32(sz)
 			}
 			format.PgDump.MaxRowSize = maxRowSize
+		case "AVRO":
+			telemetry.Count("import.format.avro")
+			format.Format = roachpb.IOFileFormat_Avro
+		case "PARQUET":
+			telemetry.Count("import.format.parquet")
+			format.Format = roachpb.IOFileFormat_Parquet
 		default:
 			return unimplemented.Newf("import.format", "unsupported import format: %q", importStmt.FileFormat)
 		}
@@ -412,6 +418,18 @@ func importPlanHook(
 			if err := p.CheckPrivilege(ctx, found, privilege.CREATE); err != nil {
 				return err
 			}
+			targetCols, err := importTargetColumns(found.TableDesc(), importStmt.IntoCols)
+			if err != nil {
+				return err
+			}
+			// Check that the rest of the columns can be filled in before starting
+			// the job, which would otherwise fail when its processors start.
+			evalCtx := &p.ExtendedEvalContext().EvalContext
+			if _, err := sql.NewRowConverter(
+				found.TableDesc(), importStmt.IntoCols, evalCtx, nil, /* kvCh */
+			); err != nil {
+				return err
+			}
 			// TODO(dt): Ensure no other schema changes can start during ingest.
 			importing := found.TableDescriptor
 			importing.Version++
@@ -435,8 +453,7 @@ func importPlanHook(
 			// will hopefully let it get a head start on propagating, plus the more we
 			// do in the job, the more that has automatic cleanup on rollback.
 
-			// TODO(dt): configure target cols from ImportStmt.IntoCols
-			tableDetails = []jobspb.ImportDetails_Table{{Desc: &importing, IsNew: false}}
+			tableDetails = []jobspb.ImportDetails_Table{{Desc: &importing, IsNew: false, TargetCols: targetCols}}
 		} else {
 			var tableDescs []*sqlbase.TableDescriptor
 			seqVals := make(map[sqlbase.ID]int64)
@@ -548,7 +565,7 @@ func importPlanHook(
 					}
 				}
 				seqVals = newSeqVals
-				if err := backupccl.RewriteTableDescs(tableDescs, tableRewrites, ""); err != nil {
+				if err := backupccl.RewriteTableDescs(tableDescs, tableRewrites, nil /* fkTargets */, ""); err != nil {
 					return err
 				}
 
@@ -620,6 +637,44 @@ func importPlanHook(
 	return fn, backupccl.RestoreHeader, nil, false, nil
 }
 
+// importTargetColumns checks that the named columns of an existing table can
+// be supplied by the input of an IMPORT INTO, and that all the other columns
+// can be filled in without it: they must be nullable, have a DEFAULT or be
+// computed. It returns the names of the columns.
+func importTargetColumns(
+	tableDesc *sqlbase.TableDescriptor, names tree.NameList,
+) ([]string, error) {
+	targetCols := make([]string, len(names))
+	isTarget := make(map[sqlbase.ColumnID]struct{}, len(names))
+	for i := range names {
+		col, err := tableDesc.FindActiveColumnByName(string(names[i]))
+		if err != nil {
+			return nil, err
+		}
+		if col.IsComputed() {
+			return nil, sqlbase.CannotWriteToComputedColError(col.Name)
+		}
+		if _, ok := isTarget[col.ID]; ok {
+			return nil, pgerror.Newf(pgcode.Syntax,
+				"multiple assignments to the same column %q", &names[i])
+		}
+		isTarget[col.ID] = struct{}{}
+		targetCols[i] = col.Name
+	}
+	for i := range tableDesc.Columns {
+		col := &tableDesc.Columns[i]
+		if _, ok := isTarget[col.ID]; ok {
+			continue
+		}
+		if !col.Nullable && col.DefaultExpr == nil && !col.IsComputed() {
+			return nil, pgerror.Newf(pgcode.NotNullViolation,
+				"column %q must be imported into: it is not nullable and has no default",
+				tree.ErrNameString(col.Name))
+		}
+	}
+	return targetCols, nil
+}
+
 func doDistributedCSVTransform(
 	ctx context.Context,
 	job *jobs.Job,
@@ -627,6 +682,7 @@ func doDistributedCSVTransform(
 	p sql.PlanHookState,
 	parentID sqlbase.ID,
 	tables map[string]*sqlbase.TableDescriptor,
+	targetCols []string,
 	format roachpb.IOFileFormat,
 	walltime int64,
 	sstSize int64,
@@ -634,7 +690,7 @@ func doDistributedCSVTransform(
 	ingestDirectly bool,
 ) (roachpb.BulkOpSummary, error) {
 	if ingestDirectly {
-		return sql.DistIngest(ctx, p, job, tables, files, format, walltime)
+		return sql.DistIngest(ctx, p, job, tables, targetCols, files, format, walltime)
 		// TODO(dt): check for errors in job records as is done below.
 	}
 
@@ -660,6 +716,7 @@ func doDistributedCSVTransform(
 		job,
 		sql.NewRowResultWriter(rows),
 		tables,
+		targetCols,
 		files,
 		format,
 		walltime,
@@ -743,9 +800,16 @@ func (r *importResumer) Resume(
 	}
 
 	tables := make(map[string]*sqlbase.TableDescriptor, len(details.Tables))
+	var targetCols []string
 	requiresSchemaChangeDelay := false
 	if details.Tables != nil {
 		for _, i := range details.Tables {
+			if len(i.TargetCols) > 0 {
+				if len(details.Tables) != 1 {
+					return errors.Errorf("target columns are only supported when importing a single table")
+				}
+				targetCols = i.TargetCols
+			}
 			if i.Name != "" {
 				tables[i.Name] = i.Desc
 			} else if i.Desc != nil {
@@ -785,7 +849,7 @@ func (r *importResumer) Resume(
 	}
 
 	res, err := doDistributedCSVTransform(
-		ctx, r.job, files, p, parentID, tables, format, walltime, sstSize, oversample, ingestDirectly,
+		ctx, r.job, files, p, parentID, tables, targetCols, format, walltime, sstSize, oversample, ingestDirectly,
 	)
 	if err != nil {
 		return err
@@ -15,6 +15,7 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -49,6 +50,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/linkedin/goavro"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 )
@@ -1344,6 +1346,69 @@ func TestImportCSVStmt(t *testing.T) {
 			fmt.Sprintf(`IMPORT INTO t(a, b) CSV DATA (%s)`, files[0]),
 		)
 	})
+
+	// Verify the columns not supplied by IMPORT INTO are filled in with their
+	// DEFAULT or computed values.
+	t.Run("import-into-target-columns", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "GET" {
+				_, _ = w.Write([]byte("1,a\n2,b\n"))
+			}
+		}))
+		defer srv.Close()
+
+		sqlDB.Exec(t, `CREATE DATABASE targetcols; USE targetcols`)
+		sqlDB.Exec(t, `CREATE TABLE t (
+			a INT8 PRIMARY KEY,
+			b STRING NOT NULL,
+			c STRING DEFAULT 'c',
+			d INT8 AS (a + 10) STORED,
+			e UUID NOT NULL DEFAULT gen_random_uuid(),
+			f INT8 NOT NULL DEFAULT unique_rowid(),
+			g INT8,
+			h INT8 NOT NULL DEFAULT unique_rowid()
+		)`)
+
+		sqlDB.Exec(t, `IMPORT INTO t (a, b) CSV DATA ($1)`, srv.URL)
+		sqlDB.CheckQueryResults(t, `SELECT a, b, c, d, g FROM t ORDER BY a`, [][]string{
+			{"1", "a", "c", "11", "NULL"},
+			{"2", "b", "c", "12", "NULL"},
+		})
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(DISTINCT e), count(DISTINCT f), count(DISTINCT h) FROM t`, [][]string{{"2", "2", "2"}},
+		)
+		// Columns that default to unique_rowid() don't get the same IDs.
+		sqlDB.CheckQueryResults(t, `SELECT count(*) FROM t WHERE f IN (SELECT h FROM t)`, [][]string{{"0"}})
+
+		sqlDB.ExpectErr(
+			t, `cannot write directly to computed column "d"`,
+			`IMPORT INTO t (a, d) CSV DATA ($1)`, srv.URL,
+		)
+		sqlDB.ExpectErr(
+			t, `multiple assignments to the same column "a"`,
+			`IMPORT INTO t (a, a) CSV DATA ($1)`, srv.URL,
+		)
+		sqlDB.ExpectErr(
+			t, `column "b" must be imported into: it is not nullable and has no default`,
+			`IMPORT INTO t (a, c) CSV DATA ($1)`, srv.URL,
+		)
+
+		// gen_random_uuid() is generated from the position of the row in the
+		// input, so it can be the DEFAULT of an index column.
+		sqlDB.Exec(t, `CREATE TABLE t2 (a INT8 PRIMARY KEY, b STRING, c UUID DEFAULT gen_random_uuid(), INDEX (c))`)
+		sqlDB.Exec(t, `IMPORT INTO t2 (a, b) CSV DATA ($1)`, srv.URL)
+		sqlDB.CheckQueryResults(t,
+			`SELECT count(DISTINCT c) FROM t2@t2_c_idx`, [][]string{{"2"}},
+		)
+
+		// Other volatile DEFAULT expressions would give the rows different index
+		// keys if the import is retried.
+		sqlDB.Exec(t, `CREATE TABLE t3 (a INT8 PRIMARY KEY, b STRING, c FLOAT8 DEFAULT random(), INDEX (c))`)
+		sqlDB.ExpectErr(
+			t, `column "c" must be imported into: it is part of an index and its DEFAULT expression calls the volatile function random`,
+			`IMPORT INTO t3 (a, b) CSV DATA ($1)`, srv.URL,
+		)
+	})
 }
 
 func BenchmarkImport(b *testing.B) {
@@ -2190,6 +2255,74 @@ func TestImportPgCopy(t *testing.T) {
 	}
 }
 
+func TestImportAvro(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanupDir := testutils.TempDir(t)
+	defer cleanupDir()
+
+	ctx := context.Background()
+	srv, db, _ := serverutils.StartServer(t, base.TestServerArgs{ExternalIODir: dir})
+	defer srv.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+
+	const schema = `{
+		"type": "record",
+		"name": "test",
+		"fields": [
+			{"name": "i", "type": "long"},
+			{"name": "s", "type": ["null", "string"]},
+			{"name": "d", "type": {"type": "bytes", "logicalType": "decimal", "precision": 10, "scale": 2}},
+			{"name": "dt", "type": {"type": "int", "logicalType": "date"}},
+			{"name": "ts", "type": ["null", {"type": "long", "logicalType": "timestamp-micros"}]}
+		]
+	}`
+	f, err := os.Create(filepath.Join(dir, "test.avro"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := goavro.NewOCFWriter(goavro.OCFConfig{W: f, Schema: schema})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := time.Date(2019, 3, 1, 12, 34, 56, 789012000, time.UTC)
+	if err := w.Append([]interface{}{
+		map[string]interface{}{
+			"i":  int64(1),
+			"s":  goavro.Union("string", "a,b"),
+			"d":  big.NewRat(-1234, 100),
+			"dt": time.Date(2019, 3, 1, 0, 0, 0, 0, time.UTC),
+			"ts": goavro.Union("long.timestamp-micros", ts),
+		},
+		map[string]interface{}{
+			"i":  int64(2),
+			"s":  nil,
+			"d":  big.NewRat(3, 2),
+			"dt": time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC),
+			"ts": nil,
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The column has no scale of its own, so the scale of the decimals is that of
+	// the Avro schema.
+	sqlDB.Exec(t, `IMPORT TABLE t (
+		i INT PRIMARY KEY, s STRING, d DECIMAL, dt DATE, ts TIMESTAMP, extra INT
+	) AVRO DATA ('nodelocal:///test.avro')`)
+	sqlDB.CheckQueryResults(t, `SELECT i, s, d, dt::STRING, ts::STRING, extra FROM t ORDER BY i`, [][]string{
+		{"1", "a,b", "-12.34", "2019-03-01", "2019-03-01 12:34:56.789012+00:00", "NULL"},
+		{"2", "NULL", "1.50", "1900-01-01", "NULL", "NULL"},
+	})
+
+	sqlDB.ExpectErr(t, `field "dt" does not match any column`,
+		`IMPORT TABLE t2 (i INT PRIMARY KEY, s STRING, d DECIMAL(10, 2), ts TIMESTAMP)
+		AVRO DATA ('nodelocal:///test.avro')`)
+}
+
 func TestImportPgDump(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -150,6 +150,7 @@ func MakeSimpleTableDescriptor(
 		st,
 		create,
 		parentID,
+		sqlbase.InvalidID, /* temporarySchemaID */
 		tableID,
 		hlc.Timestamp{WallTime: walltime},
 		sqlbase.NewDefaultPrivilegeDescriptor(),
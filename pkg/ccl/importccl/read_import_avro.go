@@ -0,0 +1,392 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"bufio"
+	"context"
+	gojson "encoding/json"
+	"io"
+	"math/big"
+	"time"
+
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeofday"
+	"github.com/cockroachdb/errors"
+	"github.com/linkedin/goavro"
+)
+
+// avroInputReader reads Avro object container files, which embed the schema of
+// the records they contain. Every record is a row, whose fields are matched to
+// the columns of the table by name. Columns without a field are NULL and
+// fields without a column are an error.
+type avroInputReader struct {
+	conv  sql.RowConverter
+	alloc sqlbase.DatumAlloc
+	// colIdxByName maps the name of each visible column to its index.
+	colIdxByName map[string]int
+}
+
+var _ inputConverter = &avroInputReader{}
+
+func newAvroInputReader(
+	kvCh chan []roachpb.KeyValue,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*avroInputReader, error) {
+	conv, err := sql.NewRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &avroInputReader{
+		conv:         *conv,
+		colIdxByName: visibleColIdxByName(conv),
+	}, nil
+}
+
+// visibleColIdxByName returns a map from the name of each visible column of the
+// RowConverter to its index.
+func visibleColIdxByName(conv *sql.RowConverter) map[string]int {
+	colIdxByName := make(map[string]int, len(conv.VisibleCols))
+	for i := range conv.VisibleCols {
+		colIdxByName[conv.VisibleCols[i].Name] = i
+	}
+	return colIdxByName
+}
+
+func (a *avroInputReader) start(ctx ctxgroup.Group) {
+}
+
+func (a *avroInputReader) inputFinished(ctx context.Context) {
+	close(a.conv.KvCh)
+}
+
+func (a *avroInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	format roachpb.IOFileFormat,
+	progressFn func(float32) error,
+	settings *cluster.Settings,
+) error {
+	return readInputFiles(ctx, dataFiles, format, a.readFile, progressFn, settings)
+}
+
+func (a *avroInputReader) readFile(
+	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	ocf, err := goavro.NewOCFReader(bufio.NewReaderSize(input, 64<<10))
+	if err != nil {
+		return errors.Wrap(err, "reading Avro object container file header")
+	}
+	fieldTypes, err := avroRecordFieldTypes(ocf.Codec().Schema())
+	if err != nil {
+		return err
+	}
+
+	var count int64 = 1
+	for ; ocf.Scan(); count++ {
+		native, err := ocf.Read()
+		if err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "decoding Avro record")
+		}
+		record, ok := native.(map[string]interface{})
+		if !ok {
+			return makeRowErr(inputName, count, pgcode.DatatypeMismatch,
+				"expected an Avro record, got %T", native)
+		}
+		for i := range a.conv.VisibleCols {
+			a.conv.Datums[i] = tree.DNull
+		}
+		for name, v := range record {
+			idx, ok := a.colIdxByName[name]
+			if !ok {
+				return makeRowErr(inputName, count, pgcode.UndefinedColumn,
+					"field %q does not match any column", name)
+			}
+			d, err := makeDatumFromNative(
+				&a.alloc, a.conv.VisibleColTypes[idx], a.conv.EvalCtx, avroNative(v, fieldTypes[name]),
+			)
+			if err != nil {
+				col := a.conv.VisibleCols[idx]
+				return wrapRowErr(err, inputName, count, pgcode.DatatypeMismatch,
+					"convert %q to %s", col.Name, col.Type.SQLString())
+			}
+			a.conv.Datums[idx] = d
+		}
+		if err := a.conv.Row(ctx, inputIdx, count); err != nil {
+			return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "")
+		}
+		if err := progressFn(false /* finished */); err != nil {
+			return err
+		}
+	}
+	if err := ocf.Err(); err != nil {
+		return wrapRowErr(err, inputName, count, pgcode.Uncategorized, "reading Avro record")
+	}
+	if err := progressFn(true /* finished */); err != nil {
+		return err
+	}
+	return a.conv.SendBatch(ctx)
+}
+
+// avroRecordFieldTypes returns the types of the fields of the record schema of
+// an object container file, as decoded from the JSON of the schema.
+func avroRecordFieldTypes(schemaJSON string) (map[string]interface{}, error) {
+	var schema struct {
+		Type   interface{} `json:"type"`
+		Fields []struct {
+			Name string      `json:"name"`
+			Type interface{} `json:"type"`
+		} `json:"fields"`
+	}
+	if err := gojson.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, errors.Wrap(err, "decoding Avro schema")
+	}
+	if schema.Type != "record" {
+		return nil, errors.Errorf("expected a schema of Avro records, got %s", schemaJSON)
+	}
+	fieldTypes := make(map[string]interface{}, len(schema.Fields))
+	for _, field := range schema.Fields {
+		fieldTypes[field.Name] = field.Type
+	}
+	return fieldTypes, nil
+}
+
+// avroNative strips the wrappers that goavro decodes the values of union types
+// into, so that the given value of the given Avro type has the same form as a
+// value of the member type would. Decimals are converted with the scale of
+// their type.
+func avroNative(native interface{}, avroType interface{}) interface{} {
+	switch t := avroType.(type) {
+	case []interface{}:
+		// goavro decodes non-null union values as a map from the name of the
+		// member type to the value.
+		union, ok := native.(map[string]interface{})
+		if !ok || len(union) != 1 {
+			return native
+		}
+		for name, v := range union {
+			for _, member := range t {
+				if avroTypeName(member) == name {
+					return avroNative(v, member)
+				}
+			}
+			return v
+		}
+	case map[string]interface{}:
+		if t["logicalType"] == "decimal" {
+			// goavro decodes decimals into rationals, which lose the scale of the
+			// decimal, e.g. 1.50 is 3/2, so it's restored from the schema.
+			if r, ok := native.(*big.Rat); ok {
+				scale, _ := t["scale"].(float64)
+				if d, ok := ratToDecimalWithScale(r, int32(scale)); ok {
+					return d
+				}
+			}
+		}
+		if t["type"] == "array" {
+			if items, ok := native.([]interface{}); ok {
+				for i := range items {
+					items[i] = avroNative(items[i], t["items"])
+				}
+			}
+		}
+	}
+	return native
+}
+
+// avroTypeName returns the name that goavro uses for the given Avro type in
+// union values.
+func avroTypeName(avroType interface{}) string {
+	switch t := avroType.(type) {
+	case string:
+		return t
+	case map[string]interface{}:
+		if name, ok := t["name"].(string); ok {
+			if namespace, ok := t["namespace"].(string); ok && namespace != "" {
+				return namespace + "." + name
+			}
+			return name
+		}
+		name, _ := t["type"].(string)
+		if logicalType, ok := t["logicalType"].(string); ok {
+			return name + "." + logicalType
+		}
+		return name
+	}
+	return ""
+}
+
+// makeDatumFromNative converts a value decoded from an Avro or Parquet file
+// into a datum of the given column type. The values have the Go types that
+// goavro decodes into: nil, bool, int32, int64, float32, float64, string,
+// []byte, time.Time, time.Duration, *big.Rat and []interface{} for arrays, as
+// well as *apd.Decimal. Other values can only be converted to JSON.
+func makeDatumFromNative(
+	alloc *sqlbase.DatumAlloc, hint *types.T, evalCtx *tree.EvalContext, native interface{},
+) (tree.Datum, error) {
+	if native == nil {
+		return tree.DNull, nil
+	}
+	if hint.Family() == types.JsonFamily {
+		if s, ok := native.(string); ok {
+			return tree.ParseDJSON(s)
+		}
+		raw, err := gojson.Marshal(native)
+		if err != nil {
+			return nil, err
+		}
+		return tree.ParseDJSON(string(raw))
+	}
+
+	switch v := native.(type) {
+	case int32:
+		native = int64(v)
+	case float32:
+		native = float64(v)
+	case *big.Rat:
+		dec, err := ratToDecimal(v)
+		if err != nil {
+			return nil, err
+		}
+		native = dec
+	}
+
+	switch v := native.(type) {
+	case bool:
+		if hint.Family() == types.BoolFamily {
+			return tree.MakeDBool(tree.DBool(v)), nil
+		}
+	case int64:
+		switch hint.Family() {
+		case types.IntFamily:
+			return alloc.NewDInt(tree.DInt(v)), nil
+		case types.FloatFamily:
+			return alloc.NewDFloat(tree.DFloat(v)), nil
+		case types.DecimalFamily:
+			return alloc.NewDDecimal(tree.DDecimal{Decimal: *apd.New(v, 0)}), nil
+		}
+	case float64:
+		switch hint.Family() {
+		case types.FloatFamily:
+			return alloc.NewDFloat(tree.DFloat(v)), nil
+		case types.DecimalFamily:
+			var d apd.Decimal
+			if _, err := d.SetFloat64(v); err != nil {
+				return nil, err
+			}
+			return alloc.NewDDecimal(tree.DDecimal{Decimal: d}), nil
+		}
+	case *apd.Decimal:
+		switch hint.Family() {
+		case types.DecimalFamily:
+			return alloc.NewDDecimal(tree.DDecimal{Decimal: *v}), nil
+		case types.FloatFamily:
+			f, err := v.Float64()
+			if err != nil {
+				return nil, err
+			}
+			return alloc.NewDFloat(tree.DFloat(f)), nil
+		}
+	case string:
+		if hint.Family() == types.StringFamily {
+			return alloc.NewDString(tree.DString(v)), nil
+		}
+		return tree.ParseDatumStringAs(hint, v, evalCtx)
+	case []byte:
+		switch hint.Family() {
+		case types.BytesFamily:
+			return alloc.NewDBytes(tree.DBytes(v)), nil
+		case types.StringFamily:
+			return alloc.NewDString(tree.DString(v)), nil
+		}
+		return tree.ParseDatumStringAs(hint, string(v), evalCtx)
+	case time.Time:
+		switch hint.Family() {
+		case types.TimestampFamily:
+			return tree.MakeDTimestamp(v, time.Microsecond), nil
+		case types.TimestampTZFamily:
+			return tree.MakeDTimestampTZ(v, time.Microsecond), nil
+		case types.DateFamily:
+			return tree.NewDDateFromTime(v)
+		}
+	case time.Duration:
+		switch hint.Family() {
+		case types.TimeFamily:
+			return tree.MakeDTime(timeofday.FromInt(int64(v / time.Microsecond))), nil
+		case types.IntervalFamily:
+			return &tree.DInterval{Duration: duration.MakeDuration(v.Nanoseconds(), 0, 0)}, nil
+		}
+	case []interface{}:
+		if hint.Family() == types.ArrayFamily {
+			arr := tree.NewDArray(hint.ArrayContents())
+			for _, elem := range v {
+				d, err := makeDatumFromNative(alloc, hint.ArrayContents(), evalCtx, elem)
+				if err != nil {
+					return nil, err
+				}
+				if err := arr.Append(d); err != nil {
+					return nil, err
+				}
+			}
+			return arr, nil
+		}
+	}
+	return nil, errors.Errorf("cannot convert %T to %s", native, hint.SQLString())
+}
+
+// ratToDecimal converts a rational, which goavro decodes Avro decimals into,
+// to a decimal with the smallest scale that represents it exactly. It is used
+// for decimals whose scale is not known from the schema.
+func ratToDecimal(r *big.Rat) (*apd.Decimal, error) {
+	const maxScale = 2000
+	ten := big.NewInt(10)
+	num := new(big.Int).Set(r.Num())
+	var coeff, rem big.Int
+	for scale := int32(0); scale <= maxScale; scale++ {
+		coeff.QuoRem(num, r.Denom(), &rem)
+		if rem.Sign() == 0 {
+			return makeDecimal(&coeff, scale), nil
+		}
+		num.Mul(num, ten)
+	}
+	return nil, errors.Errorf("%s cannot be represented as a decimal", r.RatString())
+}
+
+// ratToDecimalWithScale converts a rational to a decimal with the given scale,
+// if it can be represented exactly at that scale.
+func ratToDecimalWithScale(r *big.Rat, scale int32) (*apd.Decimal, bool) {
+	if scale < 0 {
+		return nil, false
+	}
+	num := new(big.Int).Mul(r.Num(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+	var coeff, rem big.Int
+	coeff.QuoRem(num, r.Denom(), &rem)
+	if rem.Sign() != 0 {
+		return nil, false
+	}
+	return makeDecimal(&coeff, scale), true
+}
+
+// makeDecimal returns the decimal with the given unscaled value and scale.
+func makeDecimal(unscaled *big.Int, scale int32) *apd.Decimal {
+	d := apd.NewWithBigInt(new(big.Int).Abs(unscaled), -scale)
+	d.Negative = unscaled.Sign() < 0
+	return d
+}
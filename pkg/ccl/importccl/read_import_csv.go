@@ -33,6 +33,7 @@ type csvInputReader struct {
 	batch        csvRecord
 	opts         roachpb.CSVOptions
 	tableDesc    *sqlbase.TableDescriptor
+	targetCols   tree.NameList
 	expectedCols int
 }
 
@@ -42,14 +43,20 @@ func newCSVInputReader(
 	kvCh chan []roachpb.KeyValue,
 	opts roachpb.CSVOptions,
 	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
 	evalCtx *tree.EvalContext,
 ) *csvInputReader {
+	expectedCols := len(targetCols)
+	if expectedCols == 0 {
+		expectedCols = len(tableDesc.VisibleColumns())
+	}
 	return &csvInputReader{
 		evalCtx:      evalCtx,
 		opts:         opts,
 		kvCh:         kvCh,
-		expectedCols: len(tableDesc.VisibleColumns()),
+		expectedCols: expectedCols,
 		tableDesc:    tableDesc,
+		targetCols:   targetCols,
 		recordCh:     make(chan csvRecord),
 		batchSize:    500,
 	}
@@ -162,7 +169,7 @@ func (c *csvInputReader) convertRecordWorker(ctx context.Context) error {
 	// Create a new evalCtx per converter so each go routine gets its own
 	// collationenv, which can't be accessed in parallel.
 	evalCtx := c.evalCtx.Copy()
-	conv, err := sql.NewRowConverter(c.tableDesc, evalCtx, c.kvCh)
+	conv, err := sql.NewRowConverter(c.tableDesc, c.targetCols, evalCtx, c.kvCh)
 	if err != nil {
 		return err
 	}
@@ -62,7 +62,7 @@ func newMysqldumpReader(
 			converters[name] = nil
 			continue
 		}
-		conv, err := sql.NewRowConverter(table, evalCtx, kvCh)
+		conv, err := sql.NewRowConverter(table, nil /* targetColNames */, evalCtx, kvCh)
 		if err != nil {
 			return nil, err
 		}
@@ -34,9 +34,10 @@ func newMysqloutfileReader(
 	kvCh chan []roachpb.KeyValue,
 	opts roachpb.MySQLOutfileOptions,
 	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
 	evalCtx *tree.EvalContext,
 ) (*mysqloutfileReader, error) {
-	conv, err := sql.NewRowConverter(tableDesc, evalCtx, kvCh)
+	conv, err := sql.NewRowConverter(tableDesc, targetCols, evalCtx, kvCh)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,268 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package importccl
+
+import (
+	"context"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/ctxgroup"
+	"github.com/cockroachdb/cockroach/pkg/util/parquet"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/cockroachdb/errors"
+)
+
+// parquetProgressInterval is the number of rows of a Parquet file read between
+// progress updates.
+const parquetProgressInterval = 1000
+
+// parquetInputReader reads Parquet files. Every column of a file is matched to
+// the column of the table with the same name and its values are interpreted
+// according to its logical type. Columns of the table without a column in the
+// file are NULL and columns of the file without a column in the table are an
+// error. Nested and repeated columns are not supported.
+//
+// The metadata of Parquet files is at their end, so every file is buffered in
+// a temporary file and its columns are read from there one page at a time.
+type parquetInputReader struct {
+	conv  sql.RowConverter
+	alloc sqlbase.DatumAlloc
+	// colIdxByName maps the name of each visible column to its index.
+	colIdxByName map[string]int
+}
+
+var _ inputConverter = &parquetInputReader{}
+
+func newParquetInputReader(
+	kvCh chan []roachpb.KeyValue,
+	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
+	evalCtx *tree.EvalContext,
+) (*parquetInputReader, error) {
+	conv, err := sql.NewRowConverter(tableDesc, targetCols, evalCtx, kvCh)
+	if err != nil {
+		return nil, err
+	}
+	return &parquetInputReader{
+		conv:         *conv,
+		colIdxByName: visibleColIdxByName(conv),
+	}, nil
+}
+
+func (p *parquetInputReader) start(ctx ctxgroup.Group) {
+}
+
+func (p *parquetInputReader) inputFinished(ctx context.Context) {
+	close(p.conv.KvCh)
+}
+
+func (p *parquetInputReader) readFiles(
+	ctx context.Context,
+	dataFiles map[int32]string,
+	format roachpb.IOFileFormat,
+	progressFn func(float32) error,
+	settings *cluster.Settings,
+) error {
+	return readInputFiles(ctx, dataFiles, format, p.readFile, progressFn, settings)
+}
+
+// parquetColumn is a column of a Parquet file matched to a column of the table.
+type parquetColumn struct {
+	col    *parquet.Column
+	colIdx int
+}
+
+func (p *parquetInputReader) readFile(
+	ctx context.Context, input io.Reader, inputIdx int32, inputName string, progressFn progressFn,
+) error {
+	// The reader needs random access to the file, which the input doesn't have.
+	f, err := ioutil.TempFile("", "cockroach-import-parquet")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	size, err := io.Copy(f, input)
+	if err != nil {
+		return errors.Wrap(err, "buffering Parquet file")
+	}
+	pr, err := parquet.NewReader(f, size)
+	if err != nil {
+		return errors.Wrap(err, "reading Parquet file metadata")
+	}
+
+	fileCols := pr.Columns()
+	cols := make([]parquetColumn, len(fileCols))
+	for i := range fileCols {
+		col := &fileCols[i]
+		name := strings.Join(col.Path, ".")
+		if len(col.Path) != 1 || col.Repetition == parquet.Repeated {
+			return errors.Errorf("nested or repeated column %q is not supported", name)
+		}
+		colIdx, ok := p.colIdxByName[name]
+		if !ok {
+			return errors.Errorf("column %q does not match any column", name)
+		}
+		cols[i] = parquetColumn{col: col, colIdx: colIdx}
+	}
+
+	var rowNum int64
+	readers := make([]*parquet.ColumnReader, len(cols))
+	for rowGroup := 0; rowGroup < pr.NumRowGroups(); rowGroup++ {
+		for i := range cols {
+			if readers[i], err = pr.ColumnReader(rowGroup, i); err != nil {
+				return err
+			}
+		}
+		for n := pr.RowGroupNumRows(rowGroup); n > 0; n-- {
+			rowNum++
+			for i := range p.conv.VisibleCols {
+				p.conv.Datums[i] = tree.DNull
+			}
+			for i, col := range cols {
+				v, err := readers[i].Next()
+				if err != nil {
+					return wrapRowErr(err, inputName, rowNum, pgcode.Uncategorized, "")
+				}
+				native, err := parquetNative(v, col.col)
+				if err == nil {
+					p.conv.Datums[col.colIdx], err = makeDatumFromNative(
+						&p.alloc, p.conv.VisibleColTypes[col.colIdx], p.conv.EvalCtx, native,
+					)
+				}
+				if err != nil {
+					c := p.conv.VisibleCols[col.colIdx]
+					return wrapRowErr(err, inputName, rowNum, pgcode.DatatypeMismatch,
+						"convert %q to %s", c.Name, c.Type.SQLString())
+				}
+			}
+			if err := p.conv.Row(ctx, inputIdx, rowNum); err != nil {
+				return wrapRowErr(err, inputName, rowNum, pgcode.Uncategorized, "")
+			}
+			if rowNum%parquetProgressInterval == 0 {
+				if err := progressFn(false /* finished */); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if err := progressFn(true /* finished */); err != nil {
+		return err
+	}
+	return p.conv.SendBatch(ctx)
+}
+
+// julianDayOfUnixEpoch is the Julian day number of 1970-01-01, which INT96
+// timestamps count days from.
+const julianDayOfUnixEpoch = 2440588
+
+// parquetNative converts a value read from a Parquet column, which has the Go
+// type of the physical type of the column, into one of the values accepted by
+// makeDatumFromNative, according to the logical type of the column.
+func parquetNative(v interface{}, col *parquet.Column) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	switch col.LogicalType {
+	case parquet.LogicalTimestamp:
+		ts := v.(int64)
+		switch col.TimeUnit {
+		case parquet.Millis:
+			return time.Unix(ts/1000, ts%1000*int64(time.Millisecond)).UTC(), nil
+		case parquet.Micros:
+			return time.Unix(ts/1000000, ts%1000000*int64(time.Microsecond)).UTC(), nil
+		case parquet.Nanos:
+			return time.Unix(0, ts).UTC(), nil
+		}
+	case parquet.LogicalUUID:
+		u, err := uuid.FromBytes(v.([]byte))
+		if err != nil {
+			return nil, err
+		}
+		return u.String(), nil
+	case parquet.LogicalString, parquet.LogicalEnum, parquet.LogicalJSON:
+		return string(v.([]byte)), nil
+	case parquet.LogicalDecimal:
+		return parquetDecimal(v, col.Scale)
+	}
+
+	switch col.ConvertedType {
+	case parquet.ConvertedUTF8, parquet.ConvertedEnum, parquet.ConvertedJSON:
+		return string(v.([]byte)), nil
+	case parquet.ConvertedDecimal:
+		return parquetDecimal(v, col.Scale)
+	case parquet.ConvertedDate:
+		return time.Unix(int64(v.(int32))*24*60*60, 0).UTC(), nil
+	case parquet.ConvertedTimeMillis:
+		return time.Duration(v.(int32)) * time.Millisecond, nil
+	case parquet.ConvertedTimeMicros:
+		return time.Duration(v.(int64)) * time.Microsecond, nil
+	case parquet.ConvertedTimestampMillis:
+		ts := v.(int64)
+		return time.Unix(ts/1000, ts%1000*int64(time.Millisecond)).UTC(), nil
+	case parquet.ConvertedTimestampMicros:
+		ts := v.(int64)
+		return time.Unix(ts/1000000, ts%1000000*int64(time.Microsecond)).UTC(), nil
+	case parquet.ConvertedUint32:
+		return int64(uint32(v.(int32))), nil
+	case parquet.ConvertedUint64:
+		if i := v.(int64); i < 0 {
+			return nil, errors.Errorf("%d is out of range for INT8", uint64(i))
+		}
+	}
+
+	if col.Type == parquet.Int96 {
+		// INT96 timestamps are the nanoseconds of the day followed by the Julian
+		// day number, both little-endian.
+		b := v.([]byte)
+		nanos := int64(binary.LittleEndian.Uint64(b[:8]))
+		days := int64(binary.LittleEndian.Uint32(b[8:])) - julianDayOfUnixEpoch
+		return time.Unix(days*24*60*60, nanos).UTC(), nil
+	}
+	// Byte arrays without a logical type are binary.
+	return v, nil
+}
+
+// parquetDecimal converts the unscaled value of a Parquet decimal into a
+// decimal. The unscaled value is an INT32 or INT64, or a big-endian two's
+// complement integer in a (FIXED_LEN_)BYTE_ARRAY.
+func parquetDecimal(v interface{}, scale int32) (interface{}, error) {
+	var unscaled big.Int
+	switch v := v.(type) {
+	case int32:
+		unscaled.SetInt64(int64(v))
+	case int64:
+		unscaled.SetInt64(v)
+	case []byte:
+		b := v
+		unscaled.SetBytes(b)
+		if len(b) > 0 && b[0]&0x80 != 0 {
+			var offset big.Int
+			unscaled.Sub(&unscaled, offset.Lsh(big.NewInt(1), uint(len(b))*8))
+		}
+	default:
+		return nil, errors.Errorf("unexpected physical type %T for a decimal", v)
+	}
+	return makeDecimal(&unscaled, scale), nil
+}
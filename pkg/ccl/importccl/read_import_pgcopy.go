@@ -42,9 +42,10 @@ func newPgCopyReader(
 	kvCh chan []roachpb.KeyValue,
 	opts roachpb.PgCopyOptions,
 	tableDesc *sqlbase.TableDescriptor,
+	targetCols tree.NameList,
 	evalCtx *tree.EvalContext,
 ) (*pgCopyReader, error) {
-	conv, err := sql.NewRowConverter(tableDesc, evalCtx, kvCh)
+	conv, err := sql.NewRowConverter(tableDesc, targetCols, evalCtx, kvCh)
 	if err != nil {
 		return nil, err
 	}
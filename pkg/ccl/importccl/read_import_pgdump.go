@@ -409,7 +409,7 @@ func newPgDumpReader(
 	converters := make(map[string]*sql.RowConverter, len(descs))
 	for name, desc := range descs {
 		if desc.IsTable() {
-			conv, err := sql.NewRowConverter(desc, evalCtx, kvCh)
+			conv, err := sql.NewRowConverter(desc, nil /* targetColNames */, evalCtx, kvCh)
 			if err != nil {
 				return nil, err
 			}
@@ -278,6 +278,11 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 		return errors.Errorf("%s only supports reading a single, pre-specified table", format.String())
 	}
 
+	targetCols := make(tree.NameList, len(cp.spec.TargetCols))
+	for i, col := range cp.spec.TargetCols {
+		targetCols[i] = tree.Name(col)
+	}
+
 	var conv inputConverter
 	var err error
 	switch cp.spec.Format.Format {
@@ -292,16 +297,20 @@ func (cp *readImportDataProcessor) doRun(ctx context.Context) error {
 		if isWorkload {
 			conv = newWorkloadReader(kvCh, singleTable, evalCtx)
 		} else {
-			conv = newCSVInputReader(kvCh, cp.spec.Format.Csv, singleTable, evalCtx)
+			conv = newCSVInputReader(kvCh, cp.spec.Format.Csv, singleTable, targetCols, evalCtx)
 		}
 	case roachpb.IOFileFormat_MysqlOutfile:
-		conv, err = newMysqloutfileReader(kvCh, cp.spec.Format.MysqlOut, singleTable, evalCtx)
+		conv, err = newMysqloutfileReader(kvCh, cp.spec.Format.MysqlOut, singleTable, targetCols, evalCtx)
 	case roachpb.IOFileFormat_Mysqldump:
 		conv, err = newMysqldumpReader(kvCh, cp.spec.Tables, evalCtx)
 	case roachpb.IOFileFormat_PgCopy:
-		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, evalCtx)
+		conv, err = newPgCopyReader(kvCh, cp.spec.Format.PgCopy, singleTable, targetCols, evalCtx)
 	case roachpb.IOFileFormat_PgDump:
 		conv, err = newPgDumpReader(kvCh, cp.spec.Format.PgDump, cp.spec.Tables, evalCtx)
+	case roachpb.IOFileFormat_Avro:
+		conv, err = newAvroInputReader(kvCh, singleTable, targetCols, evalCtx)
+	case roachpb.IOFileFormat_Parquet:
+		conv, err = newParquetInputReader(kvCh, singleTable, targetCols, evalCtx)
 	default:
 		err = errors.Errorf("Requested IMPORT format (%d) not supported by this node", cp.spec.Format.Format)
 	}
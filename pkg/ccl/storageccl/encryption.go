@@ -0,0 +1,116 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// The format of an encrypted file is the encryptionPreamble and a version
+// byte, followed by the GCM nonce and the AES-GCM sealed contents of the file.
+// The version leaves room to change the ciphers or add chunking later on.
+const (
+	encryptionPreamble        = "encrypt"
+	encryptionVersionIVPrefix = 1
+
+	// encryptionSaltSize is the size of the salt used to derive keys from
+	// passphrases.
+	encryptionSaltSize = 16
+	// encryptionKeyIterations is the number of PBKDF2 iterations used to
+	// derive keys from passphrases.
+	encryptionKeyIterations = 64000
+	// encryptionKeySize is the size of the derived keys, which selects AES-256.
+	encryptionKeySize = 32
+)
+
+// GenerateSalt returns a new random salt for GenerateKey.
+func GenerateSalt() ([]byte, error) {
+	salt := make([]byte, encryptionSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	return salt, nil
+}
+
+// GenerateKey derives an encryption key from the passphrase and the salt with
+// PBKDF2, so that the same passphrase and salt always yield the same key.
+func GenerateKey(passphrase, salt []byte) []byte {
+	return pbkdf2.Key(passphrase, salt, encryptionKeyIterations, encryptionKeySize, sha256.New)
+}
+
+// AppearsEncrypted returns true if the file looks like it was written by
+// EncryptFile.
+func AppearsEncrypted(text []byte) bool {
+	return bytes.HasPrefix(text, []byte(encryptionPreamble))
+}
+
+// EncryptFile encrypts and authenticates the contents of a file with AES-GCM
+// under the given key.
+func EncryptFile(plaintext, key []byte) ([]byte, error) {
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	headerSize := len(encryptionPreamble) + 1
+	ciphertext := make([]byte, 0, headerSize+len(nonce)+len(plaintext)+gcm.Overhead())
+	ciphertext = append(ciphertext, encryptionPreamble...)
+	ciphertext = append(ciphertext, encryptionVersionIVPrefix)
+	ciphertext = append(ciphertext, nonce...)
+	return gcm.Seal(ciphertext, nonce, plaintext, nil), nil
+}
+
+// DecryptFile decrypts a file written by EncryptFile. It returns an error if
+// the file was encrypted under a different key or has been tampered with.
+func DecryptFile(ciphertext, key []byte) ([]byte, error) {
+	if !AppearsEncrypted(ciphertext) {
+		return nil, errors.New("file does not appear to be encrypted")
+	}
+	ciphertext = ciphertext[len(encryptionPreamble):]
+	if len(ciphertext) < 1 {
+		return nil, errors.New("invalid encryption header")
+	}
+	if version := ciphertext[0]; version != encryptionVersionIVPrefix {
+		return nil, errors.Errorf("unexpected encryption scheme/config version %d", version)
+	}
+	ciphertext = ciphertext[1:]
+
+	gcm, err := aesgcm(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("invalid encryption header")
+	}
+	nonce := ciphertext[:gcm.NonceSize()]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "file could not be decrypted (wrong passphrase?)")
+	}
+	return plaintext, nil
+}
+
+func aesgcm(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,61 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Licensed as a CockroachDB Enterprise file under the Cockroach Community
+// License (the "License"); you may not use this file except in compliance with
+// the License. You may obtain a copy of the License at
+//
+//     https://github.com/cockroachdb/cockroach/blob/master/licenses/CCL.txt
+
+package storageccl
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEncryptDecrypt(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	salt, err := GenerateSalt()
+	require.NoError(t, err)
+	key := GenerateKey([]byte("passphrase"), salt)
+	require.Equal(t, key, GenerateKey([]byte("passphrase"), salt))
+
+	for _, plaintext := range [][]byte{
+		nil,
+		[]byte("a"),
+		bytes.Repeat([]byte("some file contents"), 1000),
+	} {
+		ciphertext, err := EncryptFile(plaintext, key)
+		require.NoError(t, err)
+		require.True(t, AppearsEncrypted(ciphertext))
+		require.False(t, len(plaintext) > 0 && bytes.Contains(ciphertext, plaintext))
+
+		decrypted, err := DecryptFile(ciphertext, key)
+		require.NoError(t, err)
+		require.Equal(t, string(plaintext), string(decrypted))
+
+		t.Run("wrong key", func(t *testing.T) {
+			wrongKey := GenerateKey([]byte("wrong"), salt)
+			_, err := DecryptFile(ciphertext, wrongKey)
+			require.EqualError(t, err, "file could not be decrypted (wrong passphrase?): "+
+				"cipher: message authentication failed")
+		})
+
+		t.Run("tampered", func(t *testing.T) {
+			tampered := append([]byte(nil), ciphertext...)
+			tampered[len(tampered)-1] ^= 1
+			_, err := DecryptFile(tampered, key)
+			require.Error(t, err)
+		})
+	}
+
+	t.Run("not encrypted", func(t *testing.T) {
+		require.False(t, AppearsEncrypted([]byte("plain")))
+		_, err := DecryptFile([]byte("plain"), key)
+		require.EqualError(t, err, "file does not appear to be encrypted")
+	})
+}
@@ -29,6 +29,7 @@ import (
 	gcs "cloud.google.com/go/storage"
 	"github.com/Azure/azure-storage-blob-go/azblob"
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/aws/aws-sdk-go/service/s3/s3manager"
@@ -245,7 +246,8 @@ type ExportStorage interface {
 	// this ExportStorage implementation.
 	Conf() roachpb.ExportStorage
 
-	// ReadFile should return a Reader for requested name.
+	// ReadFile should return a Reader for requested name. If the file does
+	// not exist, the returned error has ErrFileDoesNotExist as its cause.
 	ReadFile(ctx context.Context, basename string) (io.ReadCloser, error)
 
 	// WriteFile should write the content to requested name.
@@ -258,6 +260,17 @@ type ExportStorage interface {
 	Size(ctx context.Context, basename string) (int64, error)
 }
 
+// ErrFileDoesNotExist is the cause of the error returned by ReadFile when
+// the requested file does not exist, letting callers tell a missing file
+// apart from a transient failure to read it.
+var ErrFileDoesNotExist = errors.New("external_storage: file doesn't exist")
+
+// fileDoesNotExistError wraps err, an implementation-specific not-found
+// error, so that its cause is ErrFileDoesNotExist.
+func fileDoesNotExistError(err error) error {
+	return errors.Wrap(ErrFileDoesNotExist, err.Error())
+}
+
 var (
 	gcsDefault = settings.RegisterStringSetting(
 		cloudstorageGSDefaultKey,
@@ -347,7 +360,14 @@ func (l *localFileStorage) WriteFile(
 }
 
 func (l *localFileStorage) ReadFile(_ context.Context, basename string) (io.ReadCloser, error) {
-	return os.Open(filepath.Join(l.base, basename))
+	f, err := os.Open(filepath.Join(l.base, basename))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fileDoesNotExistError(err)
+		}
+		return nil, err
+	}
+	return f, nil
 }
 
 func (l *localFileStorage) Delete(_ context.Context, basename string) error {
@@ -522,7 +542,11 @@ func (h *httpStorage) req(
 	default:
 		body, _ := ioutil.ReadAll(resp.Body)
 		_ = resp.Body.Close()
-		return nil, errors.Errorf("error response from server: %s %q", resp.Status, body)
+		err := errors.Errorf("error response from server: %s %q", resp.Status, body)
+		if resp.StatusCode == http.StatusNotFound {
+			err = fileDoesNotExistError(err)
+		}
+		return nil, err
 	}
 	return resp, nil
 }
@@ -645,6 +669,9 @@ func (s *s3Storage) ReadFile(ctx context.Context, basename string) (io.ReadClose
 		Key:    aws.String(path.Join(s.prefix, basename)),
 	})
 	if err != nil {
+		if aerr, ok := err.(awserr.Error); ok && aerr.Code() == s3.ErrCodeNoSuchKey {
+			err = fileDoesNotExistError(err)
+		}
 		return nil, errors.Wrap(err, "failed to get s3 object")
 	}
 	return out.Body, nil
@@ -801,6 +828,9 @@ func (g *gcsStorage) ReadFile(ctx context.Context, basename string) (io.ReadClos
 		rc, readErr = g.bucket.Object(path.Join(g.prefix, basename)).NewReader(ctx)
 		return readErr
 	})
+	if err == gcs.ErrObjectNotExist {
+		return nil, fileDoesNotExistError(err)
+	}
 	return rc, err
 }
 
@@ -894,6 +924,9 @@ func (s *azureStorage) ReadFile(ctx context.Context, basename string) (io.ReadCl
 	blob := s.getBlob(basename)
 	get, err := blob.Download(ctx, 0, 0, azblob.BlobAccessConditions{}, false)
 	if err != nil {
+		if serr, ok := err.(azblob.StorageError); ok && serr.ServiceCode() == azblob.ServiceCodeBlobNotFound {
+			err = fileDoesNotExistError(err)
+		}
 		return nil, errors.Wrap(err, "failed to create azure reader")
 	}
 	reader := get.Body(azblob.RetryReaderOptions{MaxRetryRequests: 3})
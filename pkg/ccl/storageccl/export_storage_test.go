@@ -32,6 +32,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/workload"
 	"github.com/cockroachdb/cockroach/pkg/workload/bank"
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/oauth2/google"
@@ -157,6 +158,12 @@ func testExportStore(t *testing.T, storeURI string, skipSingleFile bool) {
 			t.Fatal(err)
 		}
 	})
+	t.Run("read-missing-file", func(t *testing.T) {
+		_, err := s.ReadFile(ctx, "missing-file")
+		if errors.Cause(err) != ErrFileDoesNotExist {
+			t.Fatalf("expected ErrFileDoesNotExist, got %+v", err)
+		}
+	})
 	if skipSingleFile {
 		return
 	}
@@ -2408,6 +2408,7 @@ writing ` + os.DevNull + `
   debug/nodes/1/ranges/18.json
   debug/nodes/1/ranges/19.json
   debug/nodes/1/ranges/20.json
+  debug/nodes/1/ranges/21.json
   debug/schema/defaultdb@details.json
   debug/schema/postgres@details.json
   debug/schema/system@details.json
@@ -2420,6 +2421,7 @@ writing ` + os.DevNull + `
   debug/schema/system/namespace.json
   debug/schema/system/rangelog.json
   debug/schema/system/role_members.json
+  debug/schema/system/scheduled_jobs.json
   debug/schema/system/settings.json
   debug/schema/system/table_statistics.json
   debug/schema/system/ui.json
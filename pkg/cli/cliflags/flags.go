@@ -201,6 +201,15 @@ If left unspecified, defaults to 128MiB. A percentage of physical memory
 can also be specified (e.g. .25).`,
 	}
 
+	StorageEngine = FlagInfo{
+		Name: "storage-engine",
+		Description: `
+Storage engine to use for the stores on this node that don't select one with
+the "engine" field of --store. Options are rocksdb, the default, and golsm,
+an experimental engine written in Go. Encryption and the RocksDB-specific
+store attributes are not supported by golsm.`,
+	}
+
 	ClientHost = FlagInfo{
 		Name:   "host",
 		EnvVar: "COCKROACH_HOST",
@@ -586,6 +595,15 @@ memory that the store may consume, for example:
   --store=type=mem,size=20GiB
   --store=type=mem,size=90%
 
+</PRE>
+The "engine" field selects the storage engine of a store: rocksdb or golsm,
+an experimental engine written in Go. It defaults to the engine given by
+--storage-engine. Encryption and the "rocksdb" field are not supported by
+golsm, for example:
+<PRE>
+
+  --store=path=/mnt/ssd01,engine=golsm
+
 </PRE>
 Commas are forbidden in all values, since they are used to separate fields.
 Also, if you use equal signs in the file path to a store, you must use the
@@ -337,6 +337,7 @@ func init() {
 
 		// Engine flags.
 		VarFlag(f, cacheSizeValue, cliflags.Cache)
+		VarFlag(f, &serverCfg.StorageEngine, cliflags.StorageEngine)
 		VarFlag(f, sqlSizeValue, cliflags.SQLMem)
 		// N.B. diskTempStorageSizeValue.ResolvePercentage() will be called after
 		// the stores flag has been parsed and the storage device that a percentage
@@ -505,6 +505,18 @@ var specs = []stmtSpec{
 			regexp.MustCompile("'OPTIONS'")},
 		unlink: []string{"table_name", "sink", "option", "value"},
 	},
+	{
+		name:   "create_schedule_for_backup_stmt",
+		inline: []string{"opt_with_options", "opt_full_backup_clause", "opt_with_schedule_options"},
+		replace: map[string]string{
+			"'SCHEDULE' string_or_placeholder":      "'SCHEDULE' schedule_name",
+			"'INTO' string_or_placeholder":          "'INTO' location",
+			"'RECURRING' string_or_placeholder":     "'RECURRING' crontab",
+			"'FULL' 'BACKUP' string_or_placeholder": "'FULL' 'BACKUP' crontab",
+			"targets":                               "( ( 'TABLE' | ) table_pattern ( ( ',' table_pattern ) )* | 'DATABASE' database_name ( ( ',' database_name ) )* )",
+		},
+		unlink: []string{"schedule_name", "location", "crontab", "database_name"},
+	},
 	{
 		name:    "create_index_stmt",
 		inline:  []string{"opt_unique", "opt_storing", "storing", "opt_name", "index_params", "index_elem", "opt_asc_desc"},
@@ -857,6 +869,10 @@ var specs = []stmtSpec{
 		replace: map[string]string{"	stmt": "	'CREATE' 'TABLE' table_name '(' ( column_def ( ',' column_def )* ) ( 'CONSTRAINT' name | ) 'PRIMARY KEY' '(' ( column_name ( ',' column_name )* ) ')' ( table_constraints | ) ')'"},
 		unlink: []string{"table_name", "column_name", "table_constraints"},
 	},
+	{
+		name: "refresh_materialized_view",
+		stmt: "refresh_stmt",
+	},
 	{
 		name:   "release_savepoint",
 		stmt:   "release_stmt",
@@ -1150,6 +1166,10 @@ var specs = []stmtSpec{
 		inline:  []string{"ranges_kw"},
 		exclude: []*regexp.Regexp{regexp.MustCompile("'TESTING_RANGES'")},
 	},
+	{
+		name: "show_schedules",
+		stmt: "show_schedules_stmt",
+	},
 	{
 		name: "show_schemas",
 		stmt: "show_schemas_stmt",
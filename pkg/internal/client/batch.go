@@ -179,7 +179,7 @@ func (b *Batch) fillResults(ctx context.Context) {
 			case *roachpb.PutRequest:
 				row := &result.Rows[k]
 				row.Key = []byte(req.Key)
-				if result.Err == nil {
+				if result.Err == nil && !req.LockOnly {
 					row.Value = &req.Value
 				}
 			case *roachpb.ConditionalPutRequest:
@@ -388,6 +388,24 @@ func (b *Batch) Put(key, value interface{}) {
 	b.put(key, value, false)
 }
 
+// Lock acquires an exclusive lock on a key for the transaction running the
+// batch without changing the key's value. The lock is held until the
+// transaction finishes. Locking a key outside of a transaction has no effect.
+//
+// A new result will be appended to the batch which will contain a single row
+// and Result.Err will indicate success or failure.
+//
+// key can be either a byte slice or a string.
+func (b *Batch) Lock(key interface{}) {
+	k, err := marshalKey(key)
+	if err != nil {
+		b.initResult(0, 1, notRaw, err)
+		return
+	}
+	b.appendReqs(roachpb.NewLock(k))
+	b.initResult(1, 1, notRaw, nil)
+}
+
 // PutInline sets the value for a key, but does not maintain
 // multi-version values. The most recent value is always overwritten.
 // Inline values cannot be mutated transactionally and should be used
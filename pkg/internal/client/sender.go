@@ -167,6 +167,23 @@ type TxnSender interface {
 	// like the transaction that merges ranges together.
 	DisablePipelining() error
 
+	// CreateSavepoint establishes a savepoint at the current point of the
+	// transaction. The returned token can be passed to RollbackToSavepoint or
+	// ReleaseSavepoint; it is only valid for the transaction epoch in which
+	// it was created.
+	CreateSavepoint(context.Context) (SavepointToken, error)
+
+	// RollbackToSavepoint rolls back all the writes performed by the
+	// transaction since the savepoint was created. The savepoint remains
+	// valid and can be rolled back to again. Any savepoints created after
+	// it must not be used any more.
+	RollbackToSavepoint(context.Context, SavepointToken) error
+
+	// ReleaseSavepoint releases the given savepoint. The writes performed
+	// since the savepoint was created become part of the enclosing
+	// savepoint, or of the transaction.
+	ReleaseSavepoint(context.Context, SavepointToken) error
+
 	// OrigTimestamp returns the transaction's starting timestamp.
 	// Note a transaction can be internally pushed forward in time before
 	// committing so this is not guaranteed to be the commit timestamp.
@@ -207,6 +224,11 @@ type TxnSender interface {
 	SerializeTxn() *roachpb.Transaction
 }
 
+// SavepointToken represents a savepoint established by a TxnSender. It is
+// opaque to the users of the TxnSender and only meaningful to the TxnSender
+// which created it.
+type SavepointToken interface{}
+
 // TxnStatusOpt represents options for TxnSender.GetMeta().
 type TxnStatusOpt int
 
@@ -366,6 +388,21 @@ func (m *MockTransactionalSender) UpdateStateOnRemoteRetryableErr(
 // DisablePipelining is part of the client.TxnSender interface.
 func (m *MockTransactionalSender) DisablePipelining() error { return nil }
 
+// CreateSavepoint is part of the client.TxnSender interface.
+func (m *MockTransactionalSender) CreateSavepoint(context.Context) (SavepointToken, error) {
+	panic("unimplemented")
+}
+
+// RollbackToSavepoint is part of the client.TxnSender interface.
+func (m *MockTransactionalSender) RollbackToSavepoint(context.Context, SavepointToken) error {
+	panic("unimplemented")
+}
+
+// ReleaseSavepoint is part of the client.TxnSender interface.
+func (m *MockTransactionalSender) ReleaseSavepoint(context.Context, SavepointToken) error {
+	panic("unimplemented")
+}
+
 // MockTxnSenderFactory is a TxnSenderFactory producing MockTxnSenders.
 type MockTxnSenderFactory struct {
 	senderFunc func(context.Context, *roachpb.Transaction, roachpb.BatchRequest) (
@@ -49,6 +49,9 @@ type Txn struct {
 	// systemConfigTrigger is set to true when modifying keys from the SystemConfig
 	// span. This sets the SystemConfigTrigger on EndTransactionRequest.
 	systemConfigTrigger bool
+	// boundedStaleness is set to true for bounded-staleness reads. This sets
+	// BoundedStaleness on all the batches sent through the transaction.
+	boundedStaleness bool
 
 	// mu holds fields that need to be synchronized for concurrent request execution.
 	mu struct {
@@ -278,6 +281,20 @@ func (txn *Txn) SetSystemConfigTrigger() error {
 	return txn.mu.sender.SetSystemConfigTrigger()
 }
 
+// SetBoundedStaleness marks the transaction as a bounded-staleness read, whose
+// fixed timestamp is negotiated with the nearest replicas of the ranges it
+// reads. This lets its batches be sent to the nearest replica of each range
+// rather than to the leaseholder. It must be called before any operations are
+// performed on the transaction.
+func (txn *Txn) SetBoundedStaleness() {
+	txn.boundedStaleness = true
+}
+
+// BoundedStaleness returns true if the transaction is a bounded-staleness read.
+func (txn *Txn) BoundedStaleness() bool {
+	return txn.boundedStaleness
+}
+
 // DisablePipelining instructs the transaction not to pipeline requests. It
 // should rarely be necessary to call this method. It is only recommended for
 // transactions that need extremely precise control over the request ordering,
@@ -291,6 +308,30 @@ func (txn *Txn) DisablePipelining() error {
 	return txn.mu.sender.DisablePipelining()
 }
 
+// CreateSavepoint establishes a savepoint at the current point of the
+// transaction. See TxnSender.CreateSavepoint.
+func (txn *Txn) CreateSavepoint(ctx context.Context) (SavepointToken, error) {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.CreateSavepoint(ctx)
+}
+
+// RollbackToSavepoint rolls back the writes performed by the transaction
+// since the savepoint was created. See TxnSender.RollbackToSavepoint.
+func (txn *Txn) RollbackToSavepoint(ctx context.Context, s SavepointToken) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.RollbackToSavepoint(ctx, s)
+}
+
+// ReleaseSavepoint releases the given savepoint. See
+// TxnSender.ReleaseSavepoint.
+func (txn *Txn) ReleaseSavepoint(ctx context.Context, s SavepointToken) error {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	return txn.mu.sender.ReleaseSavepoint(ctx, s)
+}
+
 // NewBatch creates and returns a new empty batch object for use with the Txn.
 func (txn *Txn) NewBatch() *Batch {
 	return &Batch{txn: txn}
@@ -781,6 +822,9 @@ func (txn *Txn) Send(
 	if txn.gatewayNodeID != 0 {
 		ba.Header.GatewayNodeID = txn.gatewayNodeID
 	}
+	if txn.boundedStaleness {
+		ba.Header.BoundedStaleness = true
+	}
 
 	txn.mu.Lock()
 	requestTxnID := txn.mu.ID
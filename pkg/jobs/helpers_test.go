@@ -26,6 +26,15 @@ func ResetConstructors() func() {
 	return func() { constructors = old }
 }
 
+// ResetScheduledJobExecutors resets the registered scheduled job executors.
+func ResetScheduledJobExecutors() func() {
+	old := make(map[string]ScheduledJobExecutor)
+	for k, v := range scheduledJobExecutors {
+		old[k] = v
+	}
+	return func() { scheduledJobExecutors = old }
+}
+
 // FakeResumer calls optional callbacks during the job lifecycle.
 type FakeResumer struct {
 	OnResume func() error
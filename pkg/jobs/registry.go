@@ -111,6 +111,9 @@ type Registry struct {
 		// propagated to jobs via the .Progressed call. This function should not be
 		// used to cancel a job in that way.
 		jobs map[int64]context.CancelFunc
+		// schedules holds the IDs of the schedules in system.scheduled_jobs that
+		// are currently being run by this registry.
+		schedules map[int64]struct{}
 	}
 }
 
@@ -154,6 +157,7 @@ func MakeRegistry(
 	}
 	r.mu.epoch = 1
 	r.mu.jobs = make(map[int64]context.CancelFunc)
+	r.mu.schedules = make(map[int64]struct{})
 	r.metrics.InitHooks(histogramWindowInterval)
 	return r
 }
@@ -224,6 +228,23 @@ func (r *Registry) StartJob(
 	return j, errCh, nil
 }
 
+// CreateAdoptableJobWithTxn creates a running job from record in the given
+// transaction and leases it to this node. Unlike StartJob, the job is not
+// resumed immediately: once the transaction commits, it is picked up by the
+// adoption loop of this node, or of any other node if this one dies.
+func (r *Registry) CreateAdoptableJobWithTxn(
+	ctx context.Context, record Record, txn *client.Txn,
+) (*Job, error) {
+	j := r.NewJob(record)
+	if err := j.WithTxn(txn).insert(ctx, r.makeJobID(), r.newLease()); err != nil {
+		return nil, err
+	}
+	if err := j.WithTxn(txn).Started(ctx); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
 // NewJob creates a new Job.
 func (r *Registry) NewJob(record Record) *Job {
 	job := &Job{
@@ -327,6 +348,20 @@ func (r *Registry) Start(
 			}
 		}
 	})
+
+	scheduleInterval := DefaultScheduleInterval
+	stopper.RunWorker(context.Background(), func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(scheduleInterval):
+				if err := r.maybeRunSchedules(ctx, nl); err != nil {
+					log.Errorf(ctx, "error while running schedules: %s", err)
+				}
+			case <-stopper.ShouldStop():
+				return
+			}
+		}
+	})
 	return nil
 }
 
@@ -0,0 +1,273 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/util/cron"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/pkg/errors"
+)
+
+var schedulerEnabledSetting = settings.RegisterBoolSetting(
+	"jobs.scheduler.enabled",
+	"if set, schedules in system.scheduled_jobs are run when they are due",
+	true,
+)
+
+// DefaultScheduleInterval is a reasonable interval at which to poll
+// system.scheduled_jobs for schedules that are due.
+//
+// DefaultScheduleInterval is mutable for testing. NB: Updates to this value
+// after Registry.Start has been called will not have any effect.
+var DefaultScheduleInterval = 30 * time.Second
+
+// ScheduledJob is a schedule stored in system.scheduled_jobs.
+type ScheduledJob struct {
+	ID    int64
+	Name  string
+	Owner string
+	// Expr is the cron expression that determines when the schedule runs.
+	Expr string
+	// ExecutionArgs is the state of the schedule, which is only interpreted by
+	// the executor of the schedule.
+	ExecutionArgs []byte
+}
+
+// ScheduledJobExecutor runs the schedules of one executor type.
+type ScheduledJobExecutor interface {
+	// ExecuteJob runs the schedule once. The returned execution arguments, if
+	// not nil, replace the ones of the schedule; they are persisted even if an
+	// error is returned, so that an executor can record partial progress. The
+	// error is recorded as the last error of the schedule.
+	ExecuteJob(
+		ctx context.Context,
+		ex sqlutil.InternalExecutor,
+		settings *cluster.Settings,
+		schedule *ScheduledJob,
+	) ([]byte, error)
+}
+
+var scheduledJobExecutors = make(map[string]ScheduledJobExecutor)
+
+// RegisterScheduledJobExecutor registers the executor of the schedules of a
+// certain executor type. Schedules of executor types that aren't registered
+// are never run.
+func RegisterScheduledJobExecutor(executorType string, ex ScheduledJobExecutor) {
+	scheduledJobExecutors[executorType] = ex
+}
+
+// NextScheduledRun returns the first time strictly after the given time that
+// matches the cron expression expr.
+func NextScheduledRun(expr string, after time.Time) (time.Time, error) {
+	e, err := cron.Parse(expr)
+	if err != nil {
+		return time.Time{}, errors.Wrapf(err, "invalid schedule %q", expr)
+	}
+	next := e.Next(after.UTC())
+	if next.IsZero() {
+		return time.Time{}, errors.Errorf("schedule %q never runs after %s", expr, after)
+	}
+	return next, nil
+}
+
+// CreateSchedule adds a schedule to system.scheduled_jobs. It returns the ID
+// of the schedule and the time of its first run.
+func CreateSchedule(
+	ctx context.Context,
+	ex sqlutil.InternalExecutor,
+	txn *client.Txn,
+	name, owner, expr, executorType string,
+	executionArgs []byte,
+) (int64, time.Time, error) {
+	nextRun, err := NextScheduledRun(expr, timeutil.Now())
+	if err != nil {
+		return 0, time.Time{}, err
+	}
+	row, err := ex.QueryRow(ctx, "create-schedule", txn,
+		`INSERT INTO system.scheduled_jobs
+		   (schedule_name, owner, schedule_expr, next_run, executor_type, execution_args)
+		 VALUES ($1, $2, $3, $4, $5, $6) RETURNING schedule_id`,
+		name, owner, expr, nextRun, executorType, executionArgs,
+	)
+	if err != nil {
+		return 0, time.Time{}, errors.Wrap(err, "creating schedule")
+	}
+	return int64(tree.MustBeDInt(row[0])), nextRun, nil
+}
+
+// maybeRunSchedules starts the executors of the schedules that are due and
+// that are not already running on a live node.
+func (r *Registry) maybeRunSchedules(ctx context.Context, nl NodeLiveness) error {
+	if !schedulerEnabledSetting.Get(&r.settings.SV) {
+		return nil
+	}
+
+	// As in maybeAdoptJob, other nodes are considered live for a while after
+	// their liveness expires, but this node only runs schedules if it's
+	// really live.
+	isLive := make(map[roachpb.NodeID]bool)
+	now, maxOffset := r.lenientNow(), r.clock.MaxOffset()
+	for _, liveness := range nl.GetLivenesses() {
+		isLive[liveness.NodeID] = liveness.IsLive(now, maxOffset)
+		if liveness.NodeID == r.nodeID.Get() && !liveness.IsLive(r.clock.Now(), maxOffset) {
+			return nil
+		}
+	}
+
+	const stmt = `SELECT schedule_id, executor_type FROM system.scheduled_jobs
+WHERE next_run <= $1 ORDER BY next_run`
+	rows, err := r.ex.Query(ctx, "find-scheduled-jobs", nil /* txn */, stmt, timeutil.Now())
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		id := int64(tree.MustBeDInt(row[0]))
+		executor, ok := scheduledJobExecutors[string(tree.MustBeDString(row[1]))]
+		if !ok {
+			if log.V(2) {
+				log.Infof(ctx, "schedule %d: skipping: unknown executor type %s", id, row[1])
+			}
+			continue
+		}
+		schedule, err := r.claimSchedule(ctx, id, isLive)
+		if err != nil {
+			log.Warningf(ctx, "schedule %d: unable to claim: %s", id, err)
+			continue
+		}
+		if schedule != nil {
+			r.runSchedule(executor, schedule)
+		}
+	}
+	return nil
+}
+
+// claimSchedule marks the schedule as running on this node and advances its
+// next run. It returns nil if the schedule isn't due anymore or is running on
+// another live node.
+func (r *Registry) claimSchedule(
+	ctx context.Context, id int64, isLive map[roachpb.NodeID]bool,
+) (*ScheduledJob, error) {
+	var schedule *ScheduledJob
+	err := r.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		schedule = nil
+		row, err := r.ex.QueryRow(ctx, "claim-schedule", txn,
+			`SELECT schedule_name, owner, schedule_expr, execution_args, next_run, running_node
+			 FROM system.scheduled_jobs WHERE schedule_id = $1`, id,
+		)
+		if err != nil || row == nil {
+			return err
+		}
+		now := timeutil.Now()
+		if row[4] == tree.DNull || tree.MustBeDTimestamp(row[4]).After(now) {
+			return nil
+		}
+		if row[5] != tree.DNull {
+			nodeID := roachpb.NodeID(tree.MustBeDInt(row[5]))
+			if nodeID == r.nodeID.Get() {
+				// We may have claimed the schedule before a restart, in which case
+				// nothing is running it anymore.
+				r.mu.Lock()
+				_, running := r.mu.schedules[id]
+				r.mu.Unlock()
+				if running {
+					return nil
+				}
+			} else if isLive[nodeID] {
+				return nil
+			}
+		}
+
+		s := &ScheduledJob{
+			ID:            id,
+			Name:          string(tree.MustBeDString(row[0])),
+			Owner:         string(tree.MustBeDString(row[1])),
+			Expr:          string(tree.MustBeDString(row[2])),
+			ExecutionArgs: []byte(tree.MustBeDBytes(row[3])),
+		}
+		nextRun, err := NextScheduledRun(s.Expr, now)
+		if err != nil {
+			return err
+		}
+		if _, err := r.ex.Exec(ctx, "claim-schedule", txn,
+			`UPDATE system.scheduled_jobs SET next_run = $2, last_run = $3, running_node = $4
+			 WHERE schedule_id = $1`,
+			id, nextRun, now, int64(r.nodeID.Get()),
+		); err != nil {
+			return err
+		}
+		schedule = s
+		return nil
+	})
+	return schedule, err
+}
+
+// runSchedule asynchronously runs a schedule claimed by this node and records
+// its outcome.
+func (r *Registry) runSchedule(executor ScheduledJobExecutor, schedule *ScheduledJob) {
+	r.mu.Lock()
+	r.mu.schedules[schedule.ID] = struct{}{}
+	r.mu.Unlock()
+	done := func() {
+		r.mu.Lock()
+		delete(r.mu.schedules, schedule.ID)
+		r.mu.Unlock()
+	}
+
+	ctx, cancel := r.stopper.WithCancelOnQuiesce(r.ac.AnnotateCtx(context.Background()))
+	taskName := fmt.Sprintf("schedule-%d", schedule.ID)
+	if err := r.stopper.RunAsyncTask(ctx, taskName, func(ctx context.Context) {
+		defer cancel()
+		defer done()
+		args, execErr := executor.ExecuteJob(ctx, r.ex, r.settings, schedule)
+		if execErr != nil {
+			log.Warningf(ctx, "schedule %d: %s", schedule.ID, execErr)
+		}
+		if err := r.finishSchedule(ctx, schedule.ID, args, execErr); err != nil {
+			log.Warningf(ctx, "schedule %d: unable to record outcome: %s", schedule.ID, err)
+		}
+	}); err != nil {
+		cancel()
+		done()
+		log.Warningf(ctx, "schedule %d: unable to run: %s", schedule.ID, err)
+	}
+}
+
+// finishSchedule records the outcome of a run of a schedule and releases it,
+// unless another node has claimed it in the meantime.
+func (r *Registry) finishSchedule(
+	ctx context.Context, id int64, executionArgs []byte, execErr error,
+) error {
+	var lastError interface{}
+	if execErr != nil {
+		lastError = execErr.Error()
+	}
+	stmt := `UPDATE system.scheduled_jobs SET last_error = $3, running_node = NULL
+WHERE schedule_id = $1 AND running_node = $2`
+	args := []interface{}{id, int64(r.nodeID.Get()), lastError}
+	if executionArgs != nil {
+		stmt = `UPDATE system.scheduled_jobs SET last_error = $3, running_node = NULL, execution_args = $4
+WHERE schedule_id = $1 AND running_node = $2`
+		args = append(args, executionArgs)
+	}
+	_, err := r.ex.Exec(ctx, "finish-schedule", nil /* txn */, stmt, args...)
+	return err
+}
@@ -0,0 +1,168 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlutil"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+)
+
+func TestNextScheduledRun(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	after := time.Date(2019, 6, 1, 10, 30, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		expr     string
+		expected time.Time
+		err      string
+	}{
+		{expr: "@hourly", expected: time.Date(2019, 6, 1, 11, 0, 0, 0, time.UTC)},
+		{expr: "@daily", expected: time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{expr: "*/10 * * * *", expected: time.Date(2019, 6, 1, 10, 40, 0, 0, time.UTC)},
+		{expr: "0 3 * * 1", expected: time.Date(2019, 6, 3, 3, 0, 0, 0, time.UTC)},
+		{expr: "0 0 1 1 * 2018", err: `schedule "0 0 1 1 \* 2018" never runs after`},
+		{expr: "not a schedule", err: `invalid schedule "not a schedule"`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			next, err := jobs.NextScheduledRun(tc.expr, after)
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if !next.Equal(tc.expected) {
+				t.Fatalf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+type fakeScheduledJobExecutor struct {
+	runs chan *jobs.ScheduledJob
+	mu   struct {
+		syncutil.Mutex
+		err error
+	}
+}
+
+func (e *fakeScheduledJobExecutor) setErr(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.mu.err = err
+}
+
+func (e *fakeScheduledJobExecutor) ExecuteJob(
+	_ context.Context, _ sqlutil.InternalExecutor, _ *cluster.Settings, s *jobs.ScheduledJob,
+) ([]byte, error) {
+	e.mu.Lock()
+	err := e.mu.err
+	e.mu.Unlock()
+	e.runs <- s
+	return append(s.ExecutionArgs, 'x'), err
+}
+
+func TestScheduledJobs(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	defer jobs.ResetScheduledJobExecutors()()
+	defer func(oldInterval time.Duration) {
+		jobs.DefaultScheduleInterval = oldInterval
+	}(jobs.DefaultScheduleInterval)
+	jobs.DefaultScheduleInterval = 10 * time.Millisecond
+
+	executor := &fakeScheduledJobExecutor{runs: make(chan *jobs.ScheduledJob, 1)}
+	jobs.RegisterScheduledJobExecutor("fake", executor)
+
+	ctx := context.Background()
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	ex := s.InternalExecutor().(sqlutil.InternalExecutor)
+
+	id, nextRun, err := jobs.CreateSchedule(
+		ctx, ex, nil /* txn */, "my-schedule", "root", "@daily", "fake", []byte("a"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if nextRun.Before(time.Now()) {
+		t.Fatalf("expected next run in the future, got %s", nextRun)
+	}
+
+	// makeDue makes the schedule due now, so that it's run on the next turn
+	// of the scheduler.
+	makeDue := func() {
+		sqlDB.Exec(t, `UPDATE system.scheduled_jobs SET next_run = now() - '1s'::INTERVAL
+WHERE schedule_id = $1`, id)
+	}
+	waitForRun := func(expectedArgs string) {
+		select {
+		case run := <-executor.runs:
+			if run.ID != id || run.Name != "my-schedule" || run.Expr != "@daily" {
+				t.Fatalf("unexpected schedule %+v", run)
+			}
+			if string(run.ExecutionArgs) != expectedArgs {
+				t.Fatalf("expected args %q, got %q", expectedArgs, run.ExecutionArgs)
+			}
+		case <-time.After(45 * time.Second):
+			t.Fatal("timed out waiting for the schedule to run")
+		}
+	}
+	waitForLastError := func(expected string) {
+		testutils.SucceedsSoon(t, func() error {
+			var lastError, args string
+			var running bool
+			sqlDB.QueryRow(t, `SELECT IFNULL(last_error, ''), execution_args, running_node IS NOT NULL
+FROM system.scheduled_jobs WHERE schedule_id = $1`, id).Scan(&lastError, &args, &running)
+			if running {
+				return errors.New("schedule still running")
+			}
+			if lastError != expected {
+				return errors.Errorf("expected last error %q, got %q", expected, lastError)
+			}
+			return nil
+		})
+	}
+
+	makeDue()
+	waitForRun("a")
+	waitForLastError("")
+	var nextRunAfter time.Time
+	sqlDB.QueryRow(t, `SELECT next_run FROM system.scheduled_jobs WHERE schedule_id = $1`,
+		id).Scan(&nextRunAfter)
+	if !nextRunAfter.After(time.Now()) {
+		t.Fatalf("expected next run in the future, got %s", nextRunAfter)
+	}
+
+	// Errors are recorded, as are the execution args returned alongside them.
+	executor.setErr(errors.New("boom"))
+	makeDue()
+	waitForRun("ax")
+	waitForLastError("boom")
+
+	// Schedules aren't run while the scheduler is disabled.
+	sqlDB.Exec(t, `SET CLUSTER SETTING jobs.scheduler.enabled = false`)
+	makeDue()
+	select {
+	case run := <-executor.runs:
+		t.Fatalf("unexpected run of schedule %d while disabled", run.ID)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
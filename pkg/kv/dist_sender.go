@@ -109,6 +109,16 @@ var CanSendToFollower = func(
 	return false
 }
 
+// canSendBoundedStalenessReadToFollower determines whether a batch of a
+// bounded-staleness read may be sent to the nearest replica of a range rather
+// than to its leaseholder. Unlike other follower reads, this doesn't require
+// an enterprise license. A replica whose closed timestamp is below the
+// timestamp of the batch can't serve it and redirects it to the leaseholder.
+func canSendBoundedStalenessReadToFollower(ba roachpb.BatchRequest) bool {
+	return ba.BoundedStaleness && ba.IsReadOnly() && ba.IsAllTransactional() &&
+		ba.Txn != nil && !ba.Txn.IsWriting()
+}
+
 var rangeDescriptorCacheSize = settings.RegisterIntSetting(
 	"kv.range_descriptor_cache.size",
 	"maximum number of entries in the range descriptor and leaseholder caches",
@@ -484,8 +494,8 @@ func (ds *DistSender) sendSingleRange(
 	// If this request needs to go to a lease holder and we know who that is, move
 	// it to the front.
 	var cachedLeaseHolder roachpb.ReplicaDescriptor
-	canSendToFollower := ds.clusterID != nil &&
-		CanSendToFollower(ds.clusterID.Get(), ds.st, ba)
+	canSendToFollower := canSendBoundedStalenessReadToFollower(ba) ||
+		(ds.clusterID != nil && CanSendToFollower(ds.clusterID.Get(), ds.st, ba))
 	if !canSendToFollower && ba.RequiresLeaseHolder() {
 		if storeID, ok := ds.leaseHolderCache.Lookup(ctx, desc.RangeID); ok {
 			if i := replicas.FindReplica(storeID); i >= 0 {
@@ -3033,6 +3033,34 @@ func TestCanSendToFollower(t *testing.T) {
 			roachpb.NewGet(roachpb.Key("a")),
 			2,
 		},
+		// Bounded-staleness reads are sent to followers regardless of
+		// CanSendToFollower.
+		{
+			false,
+			roachpb.Header{
+				Txn:              &roachpb.Transaction{},
+				BoundedStaleness: true,
+			},
+			roachpb.NewGet(roachpb.Key("a")),
+			1,
+		},
+		{
+			false,
+			roachpb.Header{
+				Txn:              &roachpb.Transaction{},
+				BoundedStaleness: true,
+			},
+			roachpb.NewPut(roachpb.Key("a"), roachpb.Value{}),
+			2,
+		},
+		{
+			false,
+			roachpb.Header{
+				BoundedStaleness: true,
+			},
+			roachpb.NewGet(roachpb.Key("a")),
+			2,
+		},
 	} {
 		sentTo = ReplicaInfo{}
 		canSend = c.canSendToFollower
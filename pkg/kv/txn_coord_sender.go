@@ -1088,6 +1088,16 @@ func (tc *TxnCoordSender) updateStateLocked(
 	}
 
 	// This is the non-retriable error case.
+
+	// A ConditionFailedError doesn't leave any ambiguity about the state of
+	// the transaction, so the transaction remains usable. In particular, this
+	// allows SQL to recover from constraint violations by rolling back to a
+	// savepoint.
+	if _, ok := pErr.GetDetail().(*roachpb.ConditionFailedError); ok {
+		tc.mu.txn.Update(pErr.GetTxn())
+		return pErr
+	}
+
 	if errTxn := pErr.GetTxn(); errTxn != nil {
 		tc.mu.txnState = txnError
 		tc.mu.storedErr = roachpb.NewError(&roachpb.TxnAlreadyEncounteredErrorError{
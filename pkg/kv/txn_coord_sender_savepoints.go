@@ -0,0 +1,128 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package kv
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
+	"github.com/pkg/errors"
+)
+
+// savepoint captures the state of the TxnCoordSender necessary to roll back
+// the transaction to the point where the savepoint was created.
+type savepoint struct {
+	// txnID and epoch identify the transaction attempt in which the savepoint
+	// was created. A savepoint cannot be used across transaction restarts.
+	txnID uuid.UUID
+	epoch enginepb.TxnEpoch
+
+	// seqNum is the sequence number of the last write performed before the
+	// savepoint was created. Rolling back to the savepoint ignores all of the
+	// writes with higher sequence numbers.
+	seqNum enginepb.TxnSeq
+}
+
+var _ client.SavepointToken = &savepoint{}
+
+// errSavepointAfterRestart is returned when a savepoint is used after the
+// transaction it was created in has been restarted.
+var errSavepointAfterRestart = errors.New(
+	"cannot use savepoint after a transaction restart")
+
+// CreateSavepoint is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) CreateSavepoint(ctx context.Context) (client.SavepointToken, error) {
+	if tc.typ != client.RootTxn {
+		return nil, errors.Errorf("cannot create savepoint in non-root txn")
+	}
+
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	if err := tc.assertSavepointUsableLocked(ctx); err != nil {
+		return nil, err
+	}
+	return &savepoint{
+		txnID:  tc.mu.txn.ID,
+		epoch:  tc.mu.txn.Epoch,
+		seqNum: tc.interceptorAlloc.txnSeqNumAllocator.seqGen,
+	}, nil
+}
+
+// RollbackToSavepoint is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) RollbackToSavepoint(ctx context.Context, s client.SavepointToken) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	sp, err := tc.checkSavepointLocked(ctx, s)
+	if err != nil {
+		return err
+	}
+
+	// Ignore all of the writes performed since the savepoint was created. The
+	// sequence number generator is not rewound, so later writes will not be
+	// covered by the ignored range.
+	seqGen := tc.interceptorAlloc.txnSeqNumAllocator.seqGen
+	if seqGen > sp.seqNum {
+		tc.mu.txn.AddIgnoredSeqNumRange(enginepb.IgnoredSeqNumRange{
+			Start: sp.seqNum + 1, End: seqGen,
+		})
+	}
+	return nil
+}
+
+// ReleaseSavepoint is part of the client.TxnSender interface.
+func (tc *TxnCoordSender) ReleaseSavepoint(ctx context.Context, s client.SavepointToken) error {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+
+	// Releasing a savepoint doesn't need to change any state; the writes
+	// performed since then simply remain part of the transaction.
+	_, err := tc.checkSavepointLocked(ctx, s)
+	return err
+}
+
+// assertSavepointUsableLocked returns an error if the transaction is in a
+// state that doesn't allow savepoint operations.
+func (tc *TxnCoordSender) assertSavepointUsableLocked(ctx context.Context) error {
+	if tc.mu.txnState == txnFinalized {
+		return errors.Errorf("cannot use savepoint in finalized txn: %s", tc.mu.txn)
+	}
+	if pErr := tc.maybeRejectClientLocked(ctx, nil /* ba */); pErr != nil {
+		return pErr.GoError()
+	}
+	return nil
+}
+
+// checkSavepointLocked verifies that the savepoint token was created by this
+// TxnCoordSender in the current epoch of the transaction and that the
+// transaction is still usable.
+func (tc *TxnCoordSender) checkSavepointLocked(
+	ctx context.Context, s client.SavepointToken,
+) (*savepoint, error) {
+	sp, ok := s.(*savepoint)
+	if !ok {
+		return nil, errors.Errorf("unexpected savepoint token type %T", s)
+	}
+	if err := tc.assertSavepointUsableLocked(ctx); err != nil {
+		return nil, err
+	}
+	if sp.txnID != tc.mu.txn.ID || sp.epoch != tc.mu.txn.Epoch {
+		return nil, errSavepointAfterRestart
+	}
+	if sp.seqNum > tc.interceptorAlloc.txnSeqNumAllocator.seqGen {
+		return nil, errors.Errorf("invalid savepoint: sequence number %d ahead of txn sequence %d",
+			sp.seqNum, tc.interceptorAlloc.txnSeqNumAllocator.seqGen)
+	}
+	return sp, nil
+}
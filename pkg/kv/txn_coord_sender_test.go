@@ -2245,3 +2245,106 @@ func TestAugmentTxnCoordMetaAbortedTxn(t *testing.T) {
 		t.Fatalf("expected PENDING txn, got: %s", txnProto.Status)
 	}
 }
+
+// TestTxnCoordSenderSavepoints verifies that rolling back to a savepoint
+// discards the writes performed after it, including after a
+// ConditionFailedError, and that the transaction can commit afterwards.
+func TestTxnCoordSenderSavepoints(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := createTestDB(t)
+	defer s.Stop()
+	ctx := context.Background()
+
+	expectValue := func(get func() (client.KeyValue, error), exp string) {
+		t.Helper()
+		kv, err := get()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if exp == "" {
+			if kv.Exists() {
+				t.Fatalf("%s: expected no value, got %q", kv.Key, kv.ValueBytes())
+			}
+		} else if !bytes.Equal(kv.ValueBytes(), []byte(exp)) {
+			t.Fatalf("%s: expected %q, got %q", kv.Key, exp, kv.ValueBytes())
+		}
+	}
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	txnGet := func(key string) func() (client.KeyValue, error) {
+		return func() (client.KeyValue, error) { return txn.Get(ctx, key) }
+	}
+	if err := txn.Put(ctx, "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "a", "2"); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Put(ctx, "b", "2"); err != nil {
+		t.Fatal(err)
+	}
+	expectValue(txnGet("a"), "2")
+
+	if err := txn.RollbackToSavepoint(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	expectValue(txnGet("a"), "1")
+	expectValue(txnGet("b"), "")
+
+	// A failed conditional put doesn't prevent the transaction from rolling
+	// back to a savepoint and continuing.
+	if err := txn.Put(ctx, "b", "3"); err != nil {
+		t.Fatal(err)
+	}
+	sp2, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := txn.CPut(ctx, "b", "4", []byte("wrong")).(*roachpb.ConditionFailedError); !ok {
+		t.Fatal("expected ConditionFailedError")
+	}
+	if err := txn.RollbackToSavepoint(ctx, sp2); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.ReleaseSavepoint(ctx, sp); err != nil {
+		t.Fatal(err)
+	}
+	if err := txn.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	dbGet := func(key string) func() (client.KeyValue, error) {
+		return func() (client.KeyValue, error) { return s.DB.Get(ctx, key) }
+	}
+	expectValue(dbGet("a"), "1")
+	expectValue(dbGet("b"), "3")
+}
+
+// TestTxnCoordSenderSavepointAfterRestart verifies that savepoints cannot be
+// used after the transaction has been restarted.
+func TestTxnCoordSenderSavepointAfterRestart(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s := createTestDB(t)
+	defer s.Stop()
+	ctx := context.Background()
+
+	txn := client.NewTxn(ctx, s.DB, 0 /* gatewayNodeID */, client.RootTxn)
+	if err := txn.Put(ctx, "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	sp, err := txn.CreateSavepoint(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn.ManualRestart(ctx, s.Clock.Now())
+	if err := txn.RollbackToSavepoint(ctx, sp); !testutils.IsError(err, "cannot use savepoint after a transaction restart") {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := txn.Rollback(ctx); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -334,6 +334,20 @@ func (r *AdminScatterResponse) combine(c combinable) error {
 
 var _ combinable = &AdminScatterResponse{}
 
+// combine implements the combinable interface.
+func (r *QueryResolvedTimestampResponse) combine(c combinable) error {
+	if r != nil {
+		otherR := c.(*QueryResolvedTimestampResponse)
+		if err := r.ResponseHeader.combine(otherR.Header()); err != nil {
+			return err
+		}
+		r.ResolvedTS.Backward(otherR.ResolvedTS)
+	}
+	return nil
+}
+
+var _ combinable = &QueryResolvedTimestampResponse{}
+
 // Header implements the Request interface.
 func (rh RequestHeader) Header() RequestHeader {
 	return rh
@@ -575,6 +589,9 @@ func (*SubsumeRequest) Method() Method { return Subsume }
 // Method implements the Request interface.
 func (*RangeStatsRequest) Method() Method { return RangeStats }
 
+// Method implements the Request interface.
+func (*QueryResolvedTimestampRequest) Method() Method { return QueryResolvedTimestamp }
+
 // ShallowCopy implements the Request interface.
 func (gr *GetRequest) ShallowCopy() Request {
 	shallowCopy := *gr
@@ -833,6 +850,12 @@ func (r *RangeStatsRequest) ShallowCopy() Request {
 	return &shallowCopy
 }
 
+// ShallowCopy implements the Request interface.
+func (r *QueryResolvedTimestampRequest) ShallowCopy() Request {
+	shallowCopy := *r
+	return &shallowCopy
+}
+
 // NewGet returns a Request initialized to get the value at key.
 func NewGet(key Key) Request {
 	return &GetRequest{
@@ -864,6 +887,17 @@ func NewPut(key Key, value Value) Request {
 	}
 }
 
+// NewLock returns a Request initialized to acquire an exclusive lock on key
+// without changing its value.
+func NewLock(key Key) Request {
+	return &PutRequest{
+		RequestHeader: RequestHeader{
+			Key: key,
+		},
+		LockOnly: true,
+	}
+}
+
 // NewPutInline returns a Request initialized to put the value at key
 // using an inline value.
 func NewPutInline(key Key, value Value) Request {
@@ -1106,6 +1140,11 @@ func (r *RefreshRangeRequest) flags() int {
 func (*SubsumeRequest) flags() int    { return isRead | isAlone | updatesReadTSCache }
 func (*RangeStatsRequest) flags() int { return isRead }
 
+// QueryResolvedTimestampRequest is sent in the transaction of a
+// bounded-staleness read, and doesn't read any keys or update the timestamp
+// cache.
+func (*QueryResolvedTimestampRequest) flags() int { return isRead | isTxn | isRange }
+
 // IsParallelCommit returns whether the EndTransaction request is attempting to
 // perform a parallel commit. See txn_interceptor_committer.go for a discussion
 // about parallel commits.
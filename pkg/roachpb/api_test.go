@@ -13,11 +13,13 @@ package roachpb
 import (
 	"reflect"
 	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
 // TestCombinable tests the correct behavior of some types that implement
-// the combinable interface, notably {Scan,DeleteRange}Response and
-// ResponseHeader.
+// the combinable interface, notably {Scan,DeleteRange,QueryResolvedTimestamp}Response
+// and ResponseHeader.
 func TestCombinable(t *testing.T) {
 	// Test that GetResponse doesn't have anything to do with combinable.
 	if _, ok := interface{}(&GetResponse{}).(combinable); ok {
@@ -87,6 +89,34 @@ func TestCombinable(t *testing.T) {
 	if !reflect.DeepEqual(dr1, wantedDR) {
 		t.Errorf("wanted %v, got %v", wantedDR, dr1)
 	}
+
+	// The resolved timestamp of a QueryResolvedTimestampResponse spanning
+	// multiple ranges is the minimum of those of the ranges.
+	qr1 := &QueryResolvedTimestampResponse{
+		ResolvedTS: hlc.Timestamp{WallTime: 3},
+	}
+	if _, ok := interface{}(qr1).(combinable); !ok {
+		t.Fatalf("QueryResolvedTimestampResponse does not implement combinable")
+	}
+	qr2 := &QueryResolvedTimestampResponse{
+		ResolvedTS: hlc.Timestamp{WallTime: 1, Logical: 2},
+	}
+	qr3 := &QueryResolvedTimestampResponse{
+		ResolvedTS: hlc.Timestamp{WallTime: 2},
+	}
+	wantedQR := &QueryResolvedTimestampResponse{
+		ResolvedTS: hlc.Timestamp{WallTime: 1, Logical: 2},
+	}
+	if err := qr1.combine(qr2); err != nil {
+		t.Fatal(err)
+	}
+	if err := qr1.combine(qr3); err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(qr1, wantedQR) {
+		t.Errorf("wanted %v, got %v", wantedQR, qr1)
+	}
 }
 
 // TestMustSetInner makes sure that calls to MustSetInner correctly reset the
@@ -934,6 +934,7 @@ func (t *Transaction) Restart(
 	t.UpgradePriority(upgradePriority)
 	t.WriteTooOld = false
 	t.Sequence = 0
+	t.IgnoredSeqNums = nil
 }
 
 // BumpEpoch increments the transaction's epoch, allowing for an in-place
@@ -991,8 +992,12 @@ func (t *Transaction) Update(o *Transaction) {
 		t.OrigTimestampWasObserved = t.OrigTimestampWasObserved || o.OrigTimestampWasObserved
 	}
 
+	// Ignored seqnum ranges only apply to the epoch they were created in.
 	if t.Epoch < o.Epoch {
 		t.Epoch = o.Epoch
+		t.IgnoredSeqNums = o.IgnoredSeqNums
+	} else if t.Epoch == o.Epoch && len(o.IgnoredSeqNums) > 0 {
+		t.IgnoredSeqNums = o.IgnoredSeqNums
 	}
 
 	t.Timestamp.Forward(o.Timestamp)
@@ -1034,6 +1039,25 @@ func (t *Transaction) UpgradePriority(minPriority enginepb.TxnPriority) {
 	}
 }
 
+// AddIgnoredSeqNumRange adds the given range to the transaction's list of
+// ignored seqnum ranges. The range must end at or after all of the existing
+// ranges; any existing range that overlaps it is merged into it. The list is
+// never modified in place, a new slice is allocated instead.
+func (t *Transaction) AddIgnoredSeqNumRange(newRange enginepb.IgnoredSeqNumRange) {
+	// Ranges are sorted, so find the first one that overlaps or follows
+	// the new range and drop it along with all the ones that follow it.
+	idx := sort.Search(len(t.IgnoredSeqNums), func(i int) bool {
+		return t.IgnoredSeqNums[i].End >= newRange.Start
+	})
+	if idx < len(t.IgnoredSeqNums) && t.IgnoredSeqNums[idx].Start < newRange.Start {
+		newRange.Start = t.IgnoredSeqNums[idx].Start
+	}
+	cpy := make([]enginepb.IgnoredSeqNumRange, idx+1)
+	copy(cpy, t.IgnoredSeqNums[:idx])
+	cpy[idx] = newRange
+	t.IgnoredSeqNums = cpy
+}
+
 // IsWriting returns whether the transaction has begun writing intents.
 // This method will never return true for a read-only transaction.
 func (t *Transaction) IsWriting() bool {
@@ -1060,6 +1084,9 @@ func (t Transaction) String() string {
 	if nw := len(t.InFlightWrites); t.Status != PENDING && nw > 0 {
 		fmt.Fprintf(&buf, " ifw=%d", nw)
 	}
+	if ni := len(t.IgnoredSeqNums); ni > 0 {
+		fmt.Fprintf(&buf, " isn=%d", ni)
+	}
 	return buf.String()
 }
 
@@ -1084,6 +1111,9 @@ func (t Transaction) SafeMessage() string {
 	if nw := len(t.InFlightWrites); t.Status != PENDING && nw > 0 {
 		fmt.Fprintf(&buf, " ifw=%d", nw)
 	}
+	if ni := len(t.IgnoredSeqNums); ni > 0 {
+		fmt.Fprintf(&buf, " isn=%d", ni)
+	}
 	return buf.String()
 }
 
@@ -1131,6 +1161,7 @@ func (t *Transaction) AsRecord() TransactionRecord {
 	tr.OrigTimestamp = t.OrigTimestamp
 	tr.IntentSpans = t.IntentSpans
 	tr.InFlightWrites = t.InFlightWrites
+	tr.IgnoredSeqNums = t.IgnoredSeqNums
 	return tr
 }
 
@@ -1145,6 +1176,7 @@ func (tr *TransactionRecord) AsTransaction() Transaction {
 	t.OrigTimestamp = tr.OrigTimestamp
 	t.IntentSpans = tr.IntentSpans
 	t.InFlightWrites = tr.InFlightWrites
+	t.IgnoredSeqNums = tr.IgnoredSeqNums
 	return t
 }
 
@@ -1483,9 +1515,10 @@ func AsIntents(spans []Span, txn *Transaction) []Intent {
 	ret := make([]Intent, len(spans))
 	for i := range spans {
 		ret[i] = Intent{
-			Span:   spans[i],
-			Txn:    txn.TxnMeta,
-			Status: txn.Status,
+			Span:           spans[i],
+			Txn:            txn.TxnMeta,
+			Status:         txn.Status,
+			IgnoredSeqNums: txn.IgnoredSeqNums,
 		}
 	}
 	return ret
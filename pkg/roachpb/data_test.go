@@ -490,6 +490,7 @@ var nonZeroTxn = Transaction{
 	InFlightWrites:           []SequencedWrite{{Key: []byte("c"), Sequence: 1}},
 	EpochZeroTimestamp:       makeTS(1, 1),
 	OrigTimestampWasObserved: true,
+	IgnoredSeqNums:           []enginepb.IgnoredSeqNumRange{{Start: 888, End: 999}},
 }
 
 func TestTransactionUpdate(t *testing.T) {
@@ -515,6 +516,32 @@ func TestTransactionUpdate(t *testing.T) {
 	}
 }
 
+func TestTransactionAddIgnoredSeqNumRange(t *testing.T) {
+	type r = enginepb.IgnoredSeqNumRange
+	testData := []struct {
+		list     []r
+		newRange r
+		exp      []r
+	}{
+		{nil, r{1, 2}, []r{{1, 2}}},
+		{[]r{{1, 2}}, r{4, 5}, []r{{1, 2}, {4, 5}}},
+		{[]r{{1, 2}, {4, 5}}, r{3, 6}, []r{{1, 2}, {3, 6}}},
+		{[]r{{1, 2}, {4, 5}}, r{1, 6}, []r{{1, 6}}},
+		{[]r{{1, 2}, {4, 7}}, r{5, 8}, []r{{1, 2}, {4, 8}}},
+	}
+	for _, tc := range testData {
+		txn := Transaction{IgnoredSeqNums: tc.list}
+		orig := append([]r(nil), tc.list...)
+		txn.AddIgnoredSeqNumRange(tc.newRange)
+		if !reflect.DeepEqual(tc.exp, txn.IgnoredSeqNums) {
+			t.Errorf("adding %v to %v: expected %v, got %v", tc.newRange, orig, tc.exp, txn.IgnoredSeqNums)
+		}
+		if !reflect.DeepEqual(orig, tc.list) && len(orig) > 0 {
+			t.Errorf("adding %v modified the original list %v: %v", tc.newRange, orig, tc.list)
+		}
+	}
+}
+
 func TestTransactionUpdateEpochZero(t *testing.T) {
 	txn := nonZeroTxn
 	var txn2 Transaction
@@ -583,6 +610,7 @@ func TestTransactionClone(t *testing.T) {
 	// listed below. If this test fails, please update the list below and/or
 	// Transaction.Clone().
 	expFields := []string{
+		"IgnoredSeqNums",
 		"InFlightWrites",
 		"InFlightWrites.Key",
 		"IntentSpans",
@@ -154,4 +154,7 @@ const (
 	Subsume
 	// RangeStats returns the MVCC statistics for a range.
 	RangeStats
+	// QueryResolvedTimestamp returns the closed timestamp of a replica of each
+	// range, below which the replica can serve reads on its own.
+	QueryResolvedTimestamp
 )
@@ -27,6 +27,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/storage"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
 	"github.com/cockroachdb/cockroach/pkg/ts"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/cockroach/pkg/util/envutil"
@@ -152,6 +153,10 @@ type Config struct {
 	// The value is split evenly between the stores if there are more than one.
 	CacheSize int64
 
+	// StorageEngine is the implementation of the storage engine used by the
+	// stores of this node that don't specify one in their store spec.
+	StorageEngine enginepb.EngineType
+
 	// TimeSeriesServerConfig contains configuration specific to the time series
 	// server.
 	TimeSeriesServerConfig ts.ServerConfig
@@ -449,6 +454,10 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 		cfg.TestingKnobs.Store.(*storage.StoreTestingKnobs).SkipMinSizeCheck
 	for i, spec := range cfg.Stores.Specs {
 		log.Eventf(ctx, "initializing %+v", spec)
+		storageEngine := cfg.StorageEngine
+		if spec.EngineSpecified {
+			storageEngine = spec.Engine
+		}
 		var sizeInBytes = spec.Size.InBytes
 		if spec.InMemory {
 			if spec.Size.Percent > 0 {
@@ -464,7 +473,7 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 			}
 			details = append(details, fmt.Sprintf("store %d: in-memory, size %s",
 				i, humanizeutil.IBytes(sizeInBytes)))
-			engines = append(engines, engine.NewInMem(spec.Attributes, sizeInBytes))
+			engines = append(engines, engine.NewInMemEngine(storageEngine, spec.Attributes, sizeInBytes))
 		} else {
 			if spec.Size.Percent > 0 {
 				fileSystemUsage := gosigar.FileSystemUsage{}
@@ -478,6 +487,27 @@ func (cfg *Config) CreateEngines(ctx context.Context) (Engines, error) {
 					spec.Size.Percent, spec.Path, humanizeutil.IBytes(sizeInBytes), humanizeutil.IBytes(base.MinimumStoreSize))
 			}
 
+			if storageEngine == enginepb.EngineTypeGoLSM {
+				if spec.UseFileRegistry || spec.RocksDBOptions != "" || len(spec.ExtraOptions) > 0 {
+					return Engines{}, errors.Errorf(
+						"store %d: encryption and RocksDB options are not supported by the golsm storage engine", i)
+				}
+				details = append(details, fmt.Sprintf("store %d: golsm, max size %s",
+					i, humanizeutil.IBytes(sizeInBytes)))
+				eng, err := engine.NewGoLSM(engine.GoLSMConfig{
+					Attrs:        spec.Attributes,
+					Dir:          spec.Path,
+					MaxSizeBytes: sizeInBytes,
+					CacheSize:    cfg.CacheSize / int64(physicalStores),
+					Settings:     cfg.Settings,
+				})
+				if err != nil {
+					return Engines{}, err
+				}
+				engines = append(engines, eng)
+				continue
+			}
+
 			details = append(details, fmt.Sprintf("store %d: RocksDB, max size %s, max open file limit %d",
 				i, humanizeutil.IBytes(sizeInBytes), openFileLimitPerStore))
 			rocksDBConfig := engine.RocksDBConfig{
@@ -1625,6 +1625,12 @@ func (s *Server) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Start the background thread for cleaning up temporary tables left
+	// behind by sessions on dead nodes.
+	sql.NewTemporaryObjectCleaner(
+		s.st, s.db, s.internalExecutor, s.status, s.nodeLiveness.IsLive,
+	).Start(ctx, s.stopper)
+
 	// Before serving SQL requests, we have to make sure the database is
 	// in an acceptable form for this version of the software.
 	// We have to do this after actually starting up the server to be able to
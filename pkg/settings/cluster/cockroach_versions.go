@@ -39,6 +39,8 @@ const (
 	VersionQueryTxnTimestamp
 	VersionStickyBit
 	VersionParallelCommits
+	VersionLockOnlyIntents
+	VersionSavepoints
 
 	// Add new versions here (step one of two).
 
@@ -477,6 +479,22 @@ var versionsSingleton = keyedVersions([]keyedVersion{
 		Key:     VersionParallelCommits,
 		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 4},
 	},
+	{
+		// VersionLockOnlyIntents enables lock-only intents, which are laid down
+		// by Put requests with the LockOnly flag to acquire row-level locks for
+		// SELECT ... FOR UPDATE. Older nodes would ignore the flag and write an
+		// empty value instead.
+		Key:     VersionLockOnlyIntents,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 5},
+	},
+	{
+		// VersionSavepoints enables regular SQL savepoints, whose rollbacks are
+		// recorded in the transaction as ranges of ignored sequence numbers.
+		// Older nodes would ignore these ranges and read or commit the writes
+		// that were rolled back.
+		Key:     VersionSavepoints,
+		Version: roachpb.Version{Major: 19, Minor: 1, Unstable: 6},
+	},
 
 	// Add new versions here (step two of two).
 
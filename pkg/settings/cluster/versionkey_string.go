@@ -16,15 +16,18 @@ func _() {
 	_ = x[VersionQueryTxnTimestamp-5]
 	_ = x[VersionStickyBit-6]
 	_ = x[VersionParallelCommits-7]
+	_ = x[VersionLockOnlyIntents-8]
+	_ = x[VersionSavepoints-9]
 }
 
-const _VersionKey_name = "Version2_1VersionUnreplicatedRaftTruncatedStateVersionSideloadedStorageNoReplicaIDVersion19_1VersionStart19_2VersionQueryTxnTimestampVersionStickyBitVersionParallelCommits"
+const _VersionKey_name = "Version2_1VersionUnreplicatedRaftTruncatedStateVersionSideloadedStorageNoReplicaIDVersion19_1VersionStart19_2VersionQueryTxnTimestampVersionStickyBitVersionParallelCommitsVersionLockOnlyIntentsVersionSavepoints"
 
-var _VersionKey_index = [...]uint8{0, 10, 47, 82, 93, 109, 133, 149, 171}
+var _VersionKey_index = [...]uint8{0, 10, 47, 82, 93, 109, 133, 149, 171, 193, 210}
 
 func (i VersionKey) String() string {
-	if i < 0 || i >= VersionKey(len(_VersionKey_index)-1) {
+	idx := int(i) - 0
+	if i < 0 || idx >= len(_VersionKey_index)-1 {
 		return "VersionKey(" + strconv.FormatInt(int64(i), 10) + ")"
 	}
-	return _VersionKey_name[_VersionKey_index[i]:_VersionKey_index[i+1]]
+	return _VersionKey_name[_VersionKey_index[idx]:_VersionKey_index[idx+1]]
 }
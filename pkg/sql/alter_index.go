@@ -13,6 +13,8 @@ package sql
 import (
 	"context"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -75,6 +77,29 @@ func (n *alterIndexNode) startExec(params runParams) error {
 				return err
 			}
 			n.indexDesc.Partitioning = partitioning
+		case *tree.AlterIndexSetTTL:
+			if n.indexDesc.IsPartial() {
+				return pgerror.Newf(pgcode.FeatureNotSupported,
+					"row-level TTL cannot use partial index %q", n.indexDesc.Name)
+			}
+			col, err := n.tableDesc.FindActiveColumnByID(n.indexDesc.ColumnIDs[0])
+			if err != nil {
+				return err
+			}
+			if err := params.p.setRowLevelTTL(params.ctx, n.tableDesc, t.TTL, col, n.indexDesc); err != nil {
+				return err
+			}
+			descriptorChanged = true
+		case *tree.AlterIndexResetTTL:
+			if ttl := n.tableDesc.RowLevelTTL; ttl != nil {
+				if ttl.IndexID != n.indexDesc.ID {
+					return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+						"the row-level TTL of table %q does not use index %q",
+						n.tableDesc.Name, n.indexDesc.Name)
+				}
+				n.tableDesc.RowLevelTTL = nil
+				descriptorChanged = true
+			}
 		default:
 			return errors.AssertionFailedf(
 				"unsupported alter command: %T", cmd)
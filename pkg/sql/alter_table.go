@@ -122,6 +122,9 @@ func (n *alterTableNode) startExec(params runParams) error {
 			if err != nil {
 				return err
 			}
+			if err := params.p.addTypeBackReference(params.ctx, n.tableDesc.ID, col); err != nil {
+				return err
+			}
 			// If the new column has a DEFAULT expression that uses a sequence, add references between
 			// its descriptor and this column descriptor.
 			if d.HasDefaultExpr() {
@@ -211,6 +214,14 @@ func (n *alterTableNode) startExec(params runParams) error {
 					}
 					idx.Partitioning = partitioning
 				}
+				if d.Predicate != nil {
+					predicate, err := MakePartialIndexPredicate(
+						params.ctx, n.tableDesc, d.Predicate, &params.p.semaCtx, *tn)
+					if err != nil {
+						return err
+					}
+					idx.Predicate = predicate
+				}
 				_, dropped, err := n.tableDesc.FindIndexByName(string(d.Name))
 				if err == nil {
 					if dropped {
@@ -307,6 +318,11 @@ func (n *alterTableNode) startExec(params runParams) error {
 				continue
 			}
 
+			if ttl := n.tableDesc.RowLevelTTL; ttl != nil && ttl.ColumnID == col.ID {
+				return pgerror.Newf(pgcode.DependentObjectsStillExist,
+					"column %q is in use by the row-level TTL of table %q", col.Name, n.tableDesc.Name)
+			}
+
 			// If the dropped column uses a sequence, remove references to it from that sequence.
 			if len(col.UsesSequenceIds) > 0 {
 				if err := removeSequenceDependencies(n.tableDesc, col, params); err != nil {
@@ -400,6 +416,17 @@ func (n *alterTableNode) startExec(params runParams) error {
 						containsThisColumn = true
 					}
 				}
+				// A partial index also depends on the columns referenced by its
+				// predicate.
+				predCols, err := idx.PredicateColumnIDs(n.tableDesc.TableDesc())
+				if err != nil {
+					return err
+				}
+				for _, id := range predCols {
+					if id == col.ID {
+						containsThisColumn = true
+					}
+				}
 
 				// Perform the DROP.
 				if containsThisColumn {
@@ -596,6 +623,22 @@ func (n *alterTableNode) startExec(params runParams) error {
 				return err
 			}
 
+		case *tree.AlterTableSetTTL:
+			col, err := n.tableDesc.FindActiveColumnByName(string(t.Column))
+			if err != nil {
+				return err
+			}
+			if err := params.p.setRowLevelTTL(params.ctx, n.tableDesc, t.TTL, col, nil /* index */); err != nil {
+				return err
+			}
+			descriptorChanged = true
+
+		case *tree.AlterTableResetTTL:
+			if n.tableDesc.RowLevelTTL != nil {
+				n.tableDesc.RowLevelTTL = nil
+				descriptorChanged = true
+			}
+
 		case *tree.AlterTableInjectStats:
 			sd, ok := n.statsData[i]
 			if !ok {
@@ -736,7 +779,10 @@ func applyColumnMutation(
 ) error {
 	switch t := mut.(type) {
 	case *tree.AlterTableAlterColumnType:
-		typ := t.ToType
+		typ, err := params.p.semaCtx.ResolveTypeReference(t.ToType)
+		if err != nil {
+			return err
+		}
 
 		// Special handling for STRING COLLATE xy to verify that we recognize the language.
 		if t.Collation != "" {
@@ -747,8 +793,7 @@ func applyColumnMutation(
 			}
 		}
 
-		err := sqlbase.ValidateColumnDefType(typ)
-		if err != nil {
+		if err := sqlbase.ValidateColumnDefType(typ); err != nil {
 			return err
 		}
 
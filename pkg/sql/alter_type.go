@@ -0,0 +1,192 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/errors"
+)
+
+type alterTypeNode struct {
+	n    *tree.AlterType
+	desc *sqlbase.TypeDescriptor
+}
+
+// AlterType alters a user-defined type.
+// Privileges: CREATE on type.
+//   notes: postgres requires ownership of the type.
+func (p *planner) AlterType(ctx context.Context, n *tree.AlterType) (planNode, error) {
+	tn := n.Type.ToTableName()
+	desc, err := p.resolveTypeDesc(ctx, &tn)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", tree.ErrString(&tn))
+	}
+
+	if err := p.CheckPrivilege(ctx, desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &alterTypeNode{n: n, desc: desc}, nil
+}
+
+func (n *alterTypeNode) startExec(params runParams) error {
+	switch t := n.n.Cmd.(type) {
+	case *tree.AlterTypeAddValue:
+		added, err := n.addEnumValue(t)
+		if err != nil || !added {
+			return err
+		}
+	default:
+		return errors.AssertionFailedf("unknown alter type cmd: %s", t)
+	}
+
+	updated, err := params.p.updateTypeReferences(params.ctx, n.desc)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		// No table uses the type, so no node can run into a label it does not
+		// know of.
+		n.desc.MakeEnumMembersWritable()
+	}
+	return params.p.writeTypeDesc(params.ctx, n.desc)
+}
+
+// addEnumValue adds a label to the enum described by n.desc. The new label is
+// given a physical representation that sorts between those of its neighbors,
+// so that the values already stored using the type do not need to be
+// rewritten. It returns false if the label already exists and IF NOT EXISTS
+// was specified.
+//
+// The label is added in a read-only state: nodes that still use the previous
+// versions of the tables that reference the type cannot decode it, so values
+// holding it cannot be written until the schema changer has made it writable.
+// See SchemaChanger.maybeMakeEnumMembersWritable.
+func (n *alterTypeNode) addEnumValue(cmd *tree.AlterTypeAddValue) (bool, error) {
+	members := n.desc.EnumMembers
+	for i := range members {
+		if members[i].LogicalRepresentation == cmd.NewVal {
+			if cmd.IfNotExists {
+				return false, nil
+			}
+			return false, pgerror.Newf(pgcode.DuplicateObject,
+				"enum label %q already exists", cmd.NewVal)
+		}
+	}
+
+	// Find the position of the new label. By default, it is added at the end.
+	pos := len(members)
+	if cmd.Placement != nil {
+		pos = -1
+		for i := range members {
+			if members[i].LogicalRepresentation == cmd.Placement.ExistingVal {
+				pos = i
+				break
+			}
+		}
+		if pos == -1 {
+			return false, pgerror.Newf(pgcode.InvalidParameterValue,
+				"%q is not an existing enum label", cmd.Placement.ExistingVal)
+		}
+		if !cmd.Placement.Before {
+			pos++
+		}
+	}
+
+	var prev, next []byte
+	if pos > 0 {
+		prev = members[pos-1].PhysicalRepresentation
+	}
+	if pos < len(members) {
+		next = members[pos].PhysicalRepresentation
+	}
+	physical, err := encoding.GenEnumPhysicalRepresentationBetween(prev, next)
+	if err != nil {
+		return false, errors.NewAssertionErrorWithWrappedErrf(err,
+			"cannot add enum label %q", cmd.NewVal)
+	}
+
+	members = append(members, sqlbase.TypeDescriptor_EnumMember{})
+	copy(members[pos+1:], members[pos:])
+	members[pos] = sqlbase.TypeDescriptor_EnumMember{
+		PhysicalRepresentation: physical,
+		LogicalRepresentation:  cmd.NewVal,
+		ReadOnly:               true,
+	}
+	n.desc.EnumMembers = members
+	return true, nil
+}
+
+func (*alterTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*alterTypeNode) Values() tree.Datums          { return tree.Datums{} }
+func (*alterTypeNode) Close(context.Context)        {}
+
+// updateTypeReferences rewrites the columns of the given user-defined type in
+// the tables that reference it, so that they carry the new definition of the
+// type. The table descriptors are written as schema changes, so that the new
+// versions are leased by all nodes before the statement returns. It returns
+// whether any table was rewritten.
+func (p *planner) updateTypeReferences(
+	ctx context.Context, typeDesc *sqlbase.TypeDescriptor,
+) (bool, error) {
+	typ := typeDesc.MakeTypesT()
+	updated := false
+	for _, id := range typeDesc.ReferencingDescriptorIDs {
+		tableDesc, err := p.Tables().getMutableTableVersionByID(ctx, id, p.txn)
+		if err != nil {
+			if err == sqlbase.ErrDescriptorNotFound {
+				// The table has since been dropped.
+				continue
+			}
+			return false, err
+		}
+		if tableDesc.Dropped() {
+			continue
+		}
+
+		changed := false
+		update := func(col *sqlbase.ColumnDescriptor) {
+			if col.Type.Family() == types.EnumFamily &&
+				sqlbase.ID(col.Type.StableTypeID()) == typeDesc.ID {
+				col.Type = *typ
+				changed = true
+			}
+		}
+		for i := range tableDesc.Columns {
+			update(&tableDesc.Columns[i])
+		}
+		for i := range tableDesc.Mutations {
+			if col := tableDesc.Mutations[i].GetColumn(); col != nil {
+				update(col)
+			}
+		}
+		// The table may no longer have a column of the type.
+		if !changed {
+			continue
+		}
+		if err := p.writeSchemaChange(ctx, tableDesc, sqlbase.InvalidMutationID); err != nil {
+			return false, err
+		}
+		updated = true
+	}
+	return updated, nil
+}
@@ -270,7 +270,9 @@ func (a *applyJoinNode) Next(params runParams) (bool, error) {
 		}
 		plan := p.(*planTop)
 
-		if err := a.runRightSidePlan(params, plan); err != nil {
+		a.run.curRightRow = 0
+		a.run.rightRows.Clear(params.ctx)
+		if err := runPlanInsidePlan(params, plan, a.run.rightRows); err != nil {
 			return false, err
 		}
 
@@ -279,15 +281,15 @@ func (a *applyJoinNode) Next(params runParams) (bool, error) {
 	}
 }
 
-// runRightSidePlan runs a planTop that's been generated based on the
-// re-optimized right hand side of the apply join, stashing the result in
-// a.run.rightRows, ready for retrieval. An error indicates that something went
-// wrong during execution of the right hand side of the join, and that we should
-// completely give up on the outer join.
-func (a *applyJoinNode) runRightSidePlan(params runParams, plan *planTop) error {
-	a.run.curRightRow = 0
-	a.run.rightRows.Clear(params.ctx)
-	rowResultWriter := NewRowResultWriter(a.run.rightRows)
+// runPlanInsidePlan runs a planTop that's been generated during the execution
+// of another plan (e.g. the re-optimized right hand side of an apply join, or
+// an iteration of a recursive CTE), stashing the result in rowContainer, ready
+// for retrieval. An error indicates that something went wrong during execution
+// of the inner plan, and that we should completely give up on the outer plan.
+func runPlanInsidePlan(
+	params runParams, plan *planTop, rowContainer *rowcontainer.RowContainer,
+) error {
+	rowResultWriter := NewRowResultWriter(rowContainer)
 	recv := MakeDistSQLReceiver(
 		params.ctx, rowResultWriter, tree.Rows,
 		params.extendedEvalCtx.ExecCfg.RangeDescriptorCache,
@@ -331,7 +333,6 @@ func (a *applyJoinNode) runRightSidePlan(params runParams, plan *planTop) error
 		return recv.commErr
 	}
 	return rowResultWriter.err
-
 }
 
 func (a *applyJoinNode) Values() tree.Datums {
@@ -59,6 +59,10 @@ const (
 	// over many ranges.
 	indexTxnBackfillChunkSize = 100
 
+	// materializedViewBackfillChunkSize is the maximum number of rows of the
+	// view query written per chunk during a materialized view backfill.
+	materializedViewBackfillChunkSize = 1000
+
 	// checkpointInterval is the interval after which a checkpoint of the
 	// schema change is posted.
 	checkpointInterval = 2 * time.Minute
@@ -142,6 +146,7 @@ func (sc *SchemaChanger) runBackfill(
 		tableDesc.Name, tableDesc.Version, sc.mutationID)
 
 	needColumnBackfill := false
+	var materializedViewRefresh *sqlbase.MaterializedViewRefresh
 	for _, m := range tableDesc.Mutations {
 		if m.MutationID != sc.mutationID {
 			break
@@ -174,6 +179,8 @@ func (sc *SchemaChanger) runBackfill(
 					constraintsToAddBeforeValidation = append(constraintsToAddBeforeValidation, *t.Constraint)
 					constraintsToValidate = append(constraintsToValidate, *t.Constraint)
 				}
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				materializedViewRefresh = t.MaterializedViewRefresh
 			default:
 				return errors.AssertionFailedf(
 					"unsupported mutation: %+v", m)
@@ -194,6 +201,9 @@ func (sc *SchemaChanger) runBackfill(
 						"trying to drop constraint through schema changer outside of a rollback: %+v", t)
 				}
 				// no-op
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				// Only possible during a rollback. The new indexes written by the
+				// refresh are garbage collected once the mutation completes.
 			default:
 				return errors.AssertionFailedf(
 					"unsupported mutation: %+v", m)
@@ -225,6 +235,15 @@ func (sc *SchemaChanger) runBackfill(
 		}
 	}
 
+	// Recompute the contents of a materialized view.
+	if materializedViewRefresh != nil {
+		if err := sc.refreshMaterializedView(
+			ctx, lease, tableDesc, materializedViewRefresh, evalCtx,
+		); err != nil {
+			return err
+		}
+	}
+
 	// Add check and foreign key constraints, publish the new version of the table descriptor,
 	// and wait until the entire cluster is on the new version. This is basically
 	// a state transition for the schema change, which must happen after the
@@ -1225,10 +1244,14 @@ func runSchemaChangesInTxn(
 				doneColumnBackfill = true
 
 			case *sqlbase.DescriptorMutation_Index:
-				if err := indexBackfillInTxn(ctx, txn, immutDesc, traceKV); err != nil {
+				if err := indexBackfillInTxn(ctx, txn, evalCtx, immutDesc, traceKV); err != nil {
 					return err
 				}
 
+			case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+				return errors.AssertionFailedf(
+					"materialized view refresh cannot be run in the transaction creating the view: %+v", m)
+
 			case *sqlbase.DescriptorMutation_Constraint:
 				switch t.Constraint.ConstraintType {
 				case sqlbase.ConstraintToUpdate_CHECK, sqlbase.ConstraintToUpdate_NOT_NULL:
@@ -1461,10 +1484,14 @@ func columnBackfillInTxn(
 }
 
 func indexBackfillInTxn(
-	ctx context.Context, txn *client.Txn, tableDesc *sqlbase.ImmutableTableDescriptor, traceKV bool,
+	ctx context.Context,
+	txn *client.Txn,
+	evalCtx *tree.EvalContext,
+	tableDesc *sqlbase.ImmutableTableDescriptor,
+	traceKV bool,
 ) error {
 	var backfiller backfill.IndexBackfiller
-	if err := backfiller.Init(tableDesc); err != nil {
+	if err := backfiller.Init(evalCtx, tableDesc); err != nil {
 		return err
 	}
 	sp := tableDesc.PrimaryIndexSpan()
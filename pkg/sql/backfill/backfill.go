@@ -42,6 +42,12 @@ func IndexMutationFilter(m sqlbase.DescriptorMutation) bool {
 	return m.GetIndex() != nil && m.Direction == sqlbase.DescriptorMutation_ADD
 }
 
+// MaterializedViewRefreshMutationFilter is a filter that allows mutations
+// that refresh a materialized view.
+func MaterializedViewRefreshMutationFilter(m sqlbase.DescriptorMutation) bool {
+	return m.GetMaterializedViewRefresh() != nil && m.Direction == sqlbase.DescriptorMutation_ADD
+}
+
 // backfiller is common to a ColumnBackfiller or an IndexBackfiller.
 type backfiller struct {
 	fetcher row.Fetcher
@@ -292,6 +298,9 @@ type IndexBackfiller struct {
 
 	types   []types.T
 	rowVals tree.Datums
+
+	// preds determines which rows are included in the added partial indexes.
+	preds *sqlbase.PartialIndexPredicates
 }
 
 // ContainsInvertedIndex returns true if backfilling an inverted index.
@@ -305,7 +314,9 @@ func (ib *IndexBackfiller) ContainsInvertedIndex() bool {
 }
 
 // Init initializes an IndexBackfiller.
-func (ib *IndexBackfiller) Init(desc *sqlbase.ImmutableTableDescriptor) error {
+func (ib *IndexBackfiller) Init(
+	evalCtx *tree.EvalContext, desc *sqlbase.ImmutableTableDescriptor,
+) error {
 	numCols := len(desc.Columns)
 	cols := desc.Columns
 	if len(desc.Mutations) > 0 {
@@ -335,9 +346,27 @@ func (ib *IndexBackfiller) Init(desc *sqlbase.ImmutableTableDescriptor) error {
 					valNeededForCol.Add(i)
 				}
 			}
+			// The columns referenced by the predicate of a partial index are
+			// needed to determine which rows it includes.
+			predCols, err := idx.PredicateColumnIDs(desc.TableDesc())
+			if err != nil {
+				return err
+			}
+			for _, id := range predCols {
+				for i := range cols {
+					if cols[i].ID == id {
+						valNeededForCol.Add(i)
+					}
+				}
+			}
 		}
 	}
 
+	var err error
+	if ib.preds, err = sqlbase.NewPartialIndexPredicates(desc.TableDesc(), ib.added, evalCtx); err != nil {
+		return err
+	}
+
 	ib.types = make([]types.T, len(cols))
 	for i := range cols {
 		ib.types[i] = cols[i].Type
@@ -413,10 +442,16 @@ func (ib *IndexBackfiller) BuildIndexEntriesChunk(
 		buffer = buffer[:len(ib.added)]
 		if buffer, err = sqlbase.EncodeSecondaryIndexes(
 			tableDesc.TableDesc(), ib.added, ib.colIdxMap,
-			ib.rowVals, buffer); err != nil {
+			ib.rowVals, ib.preds, buffer); err != nil {
 			return nil, nil, err
 		}
-		entries = append(entries, buffer...)
+		for i := range buffer {
+			if buffer[i].Key == nil {
+				// The row is not included in this partial index.
+				continue
+			}
+			entries = append(entries, buffer[i])
+		}
 	}
 	return entries, ib.fetcher.Key(), nil
 }
@@ -454,3 +489,95 @@ func (ib *IndexBackfiller) RunIndexBackfillChunk(
 	}
 	return key, nil
 }
+
+// MaterializedViewBackfiller is capable of writing the results of the view
+// query of a materialized view into a set of indexes of the view.
+//
+// Each row is identified by its position in the results of the view query,
+// which is used as the value of the hidden rowid column forming the primary
+// key of the view. A backfill that is interrupted can thus be resumed by
+// running the view query again as of the same timestamp and skipping the rows
+// before the first missing primary key, provided the results are returned in
+// the same order.
+type MaterializedViewBackfiller struct {
+	// desc is the descriptor of the view with the indexes that are written to
+	// in place of its own.
+	desc     *sqlbase.ImmutableTableDescriptor
+	rowIDIdx int
+	rowVals  tree.Datums
+
+	evalCtx *tree.EvalContext
+	alloc   sqlbase.DatumAlloc
+}
+
+// Init initializes a MaterializedViewBackfiller that writes to the given
+// primary and secondary indexes of the materialized view, which need not be
+// public.
+func (mb *MaterializedViewBackfiller) Init(
+	evalCtx *tree.EvalContext,
+	desc *sqlbase.TableDescriptor,
+	primaryIndex *sqlbase.IndexDescriptor,
+	indexes []sqlbase.IndexDescriptor,
+) error {
+	if !desc.MaterializedView() {
+		return errors.AssertionFailedf("%q is not a materialized view", desc.Name)
+	}
+	// The view query produces all of the columns except for the rowid column
+	// added by ensurePrimaryKey(), which comes last.
+	rowIDIdx := len(desc.Columns) - 1
+	if len(primaryIndex.ColumnIDs) != 1 || rowIDIdx < 0 ||
+		primaryIndex.ColumnIDs[0] != desc.Columns[rowIDIdx].ID || !desc.Columns[rowIDIdx].Hidden {
+		return errors.AssertionFailedf(
+			"materialized view %q is not keyed by its hidden rowid column", desc.Name)
+	}
+
+	target := *desc
+	target.PrimaryIndex = *primaryIndex
+	target.Indexes = indexes
+	target.Mutations = nil
+	*mb = MaterializedViewBackfiller{
+		desc:     sqlbase.NewImmutableTableDescriptor(target),
+		rowIDIdx: rowIDIdx,
+		rowVals:  make(tree.Datums, len(desc.Columns)),
+		evalCtx:  evalCtx,
+	}
+	return nil
+}
+
+// RunMaterializedViewBackfillChunk writes a chunk of rows produced by the view
+// query in txn. firstRowID is the position of the first of the rows in the
+// results of the view query.
+func (mb *MaterializedViewBackfiller) RunMaterializedViewBackfillChunk(
+	ctx context.Context, txn *client.Txn, rows []tree.Datums, firstRowID int64, traceKV bool,
+) error {
+	ri, err := row.MakeInserter(
+		txn,
+		mb.desc,
+		nil, /* fkTables */
+		mb.desc.Columns,
+		row.SkipFKs,
+		mb.evalCtx,
+		&mb.alloc,
+	)
+	if err != nil {
+		return err
+	}
+	b := txn.NewBatch()
+	for i, r := range rows {
+		if len(r) != mb.rowIDIdx {
+			return errors.AssertionFailedf(
+				"expected %d values from the view query, got %d", mb.rowIDIdx, len(r))
+		}
+		copy(mb.rowVals, r)
+		mb.rowVals[mb.rowIDIdx] = tree.NewDInt(tree.DInt(firstRowID + int64(i)))
+		if err := ri.InsertRow(
+			ctx, b, mb.rowVals, false /* overwrite */, row.SkipFKs, traceKV,
+		); err != nil {
+			return err
+		}
+	}
+	if err := txn.Run(ctx, b); err != nil {
+		return row.ConvertBatchError(ctx, mb.desc, b)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// negotiateBoundedStalenessTimestamp chooses the timestamp of a
+// bounded-staleness read, which has been planned at the present time, and
+// plans it again at that timestamp, as the schema it reads may have been
+// different then.
+//
+// The read is performed at the minimum of the closed timestamps of the nearest
+// replicas of the ranges the plan touches, which is the most recent timestamp
+// at which all of them can serve it. If this is older than the staleness
+// bound allows, the read is performed at the oldest timestamp the bound
+// allows instead, and the replicas that can't serve it redirect it to the
+// leaseholders.
+func (ex *connExecutor) negotiateBoundedStalenessTimestamp(
+	ctx context.Context, p *planner,
+) error {
+	spans, err := collectPlanSpans(ctx, &p.curPlan)
+	if err != nil {
+		return err
+	}
+	p.curPlan.close(ctx)
+
+	// The AS OF SYSTEM TIME clause evaluates to the oldest timestamp the bound
+	// allows. The replicas are queried at it, so that any replica whose closed
+	// timestamp is at least as recent can answer instead of the leaseholder.
+	ts := *p.semaCtx.AsOfTimestamp
+	ex.state.setHistoricalTimestamp(ctx, ts)
+	if len(spans) > 0 {
+		var b client.Batch
+		for _, span := range spans {
+			b.AddRawRequest(&roachpb.QueryResolvedTimestampRequest{
+				RequestHeader: roachpb.RequestHeaderFromSpan(span),
+			})
+		}
+		if err := p.txn.Run(ctx, &b); err != nil {
+			return err
+		}
+		var resolvedTS hlc.Timestamp
+		for i, ru := range b.RawResponse().Responses {
+			resp := ru.GetInner().(*roachpb.QueryResolvedTimestampResponse)
+			if i == 0 || resp.ResolvedTS.Less(resolvedTS) {
+				resolvedTS = resp.ResolvedTS
+			}
+		}
+		ts.Forward(resolvedTS)
+	}
+	log.VEventf(ctx, 2, "performing bounded-staleness read at %s", ts)
+
+	p.extendedEvalCtx.SetTxnTimestamp(ts.GoTime())
+	ex.state.setHistoricalTimestamp(ctx, ts)
+	return ex.makeExecPlan(ctx, p)
+}
+
+// collectPlanSpans returns the spans of the table data read by the plan and its
+// subqueries. Index, lookup and zigzag joins are assumed to read their entire
+// indexes.
+func collectPlanSpans(ctx context.Context, plan *planTop) (roachpb.Spans, error) {
+	var spans roachpb.Spans
+	observer := planObserver{
+		enterNode: func(ctx context.Context, _ string, plan planNode) (bool, error) {
+			switch n := plan.(type) {
+			case *scanNode:
+				spans = append(spans, n.spans...)
+			case *indexJoinNode:
+				spans = append(spans, n.table.desc.IndexSpan(n.table.index.ID))
+			case *lookupJoinNode:
+				spans = append(spans, n.table.desc.IndexSpan(n.table.index.ID))
+			case *zigzagJoinNode:
+				for _, side := range n.sides {
+					spans = append(spans, side.scan.desc.IndexSpan(side.scan.index.ID))
+				}
+			}
+			return true, nil
+		},
+	}
+	if err := walkPlan(ctx, plan.plan, observer); err != nil {
+		return nil, err
+	}
+	for i := range plan.subqueryPlans {
+		if err := walkPlan(ctx, plan.subqueryPlans[i].plan, observer); err != nil {
+			return nil, err
+		}
+	}
+	return spans, nil
+}
@@ -74,6 +74,9 @@ type scanBufferNode struct {
 	buffer *bufferNode
 
 	nextRowIdx int
+
+	// label is a string used to describe the node in an EXPLAIN output.
+	label string
 }
 
 func (n *scanBufferNode) startExec(runParams) error {
@@ -749,6 +749,14 @@ func (ex *connExecutor) close(ctx context.Context, closeType closeType) {
 		log.Warningf(ctx, "error while cleaning up connExecutor: %s", err)
 	}
 
+	if ex.sessionData.SearchPath.GetTemporarySchemaName() != "" {
+		if err := cleanupSessionTempObjects(
+			ctx, ex.server.cfg.DB, ex.server.cfg.InternalExecutor, ex.sessionID,
+		); err != nil {
+			log.Warningf(ctx, "error while cleaning up temporary objects: %s", err)
+		}
+	}
+
 	if closeType != panicClose {
 		// Close all statements and prepared portals.
 		ex.extraTxnState.prepStmtsNamespace.resetTo(ctx, prepStmtNamespace{})
@@ -1515,7 +1523,12 @@ func (ex *connExecutor) setTxnRewindPos(ctx context.Context, pos CmdPos) {
 // statement do not change with retries.
 func (ex *connExecutor) stmtDoesntNeedRetry(stmt tree.Statement) bool {
 	wrap := Statement{Statement: parser.Statement{AST: stmt}}
-	return isSavepoint(wrap) || isSetTransaction(wrap)
+	if isSavepoint(wrap) {
+		// Only the restart savepoint can be skipped; regular savepoints need to be
+		// re-established when the transaction is retried.
+		return ex.isRestartSavepoint(stmt.(*tree.Savepoint).Name)
+	}
+	return isSetTransaction(wrap)
 }
 
 func stateToTxnStatusIndicator(s fsm.State) TransactionStatusIndicator {
@@ -1835,6 +1848,7 @@ func (ex *connExecutor) resetEvalCtx(
 	evalCtx.Mon = ex.state.mon
 	evalCtx.PrepareOnly = false
 	evalCtx.SkipNormalize = false
+	evalCtx.SessionID = ex.sessionID
 }
 
 // getTransactionState retrieves a text representation of the given state.
@@ -1885,6 +1899,7 @@ func (ex *connExecutor) resetPlanner(
 	p.semaCtx = tree.MakeSemaContext()
 	p.semaCtx.Location = &ex.sessionData.DataConversion.Location
 	p.semaCtx.SearchPath = ex.sessionData.SearchPath
+	p.semaCtx.TypeResolver = p
 	p.semaCtx.AsOfTimestamp = nil
 	p.semaCtx.Annotations = tree.MakeAnnotations(numAnnotations)
 
@@ -2263,7 +2278,7 @@ func (sc *StatementCounters) incrementCount(ex *connExecutor, stmt tree.Statemen
 	case *tree.RollbackTransaction:
 		sc.TxnRollbackCount.Inc()
 	case *tree.Savepoint:
-		if err := ex.validateSavepointName(t.Name); err == nil {
+		if ex.isRestartSavepoint(t.Name) {
 			sc.RestartSavepointCount.Inc()
 		} else {
 			sc.SavepointCount.Inc()
@@ -232,6 +232,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		return ev, payload, nil
 
 	case *tree.ReleaseSavepoint:
+		if !ex.isRestartSavepoint(s.Savepoint) {
+			if err := ex.execReleaseSavepointInOpenState(ctx, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
+		}
 		if err := ex.validateSavepointName(s.Savepoint); err != nil {
 			return makeErrEvent(err)
 		}
@@ -250,6 +256,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		return ev, payload, nil
 
 	case *tree.Savepoint:
+		if !ex.isRestartSavepoint(s.Name) {
+			if err := ex.execSavepointInOpenState(ctx, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
+		}
 		// Ensure that the user isn't trying to run BEGIN; SAVEPOINT; SAVEPOINT;
 		if ex.state.activeSavepointName != "" {
 			err := unimplemented.NewWithIssueDetail(10735, "nested", "SAVEPOINT may not be nested")
@@ -278,6 +290,12 @@ func (ex *connExecutor) execStmtInOpenState(
 		return eventRetryIntentSet{}, nil /* payload */, nil
 
 	case *tree.RollbackToSavepoint:
+		if !ex.isRestartSavepoint(s.Savepoint) {
+			if err := ex.execRollbackToSavepoint(ctx, s); err != nil {
+				return makeErrEvent(err)
+			}
+			return nil, nil, nil
+		}
 		if err := ex.validateSavepointName(s.Savepoint); err != nil {
 			return makeErrEvent(err)
 		}
@@ -372,14 +390,26 @@ func (ex *connExecutor) execStmtInOpenState(
 		}
 		if asOfTs != nil {
 			p.semaCtx.AsOfTimestamp = asOfTs
-			p.extendedEvalCtx.SetTxnTimestamp(asOfTs.GoTime())
-			ex.state.setHistoricalTimestamp(ctx, *asOfTs)
+			if p.isBoundedStalenessAsOf(stmt.AST) {
+				// The timestamp of a bounded-staleness read is negotiated once
+				// the statement has been planned, see
+				// negotiateBoundedStalenessTimestamp. It is first planned at the
+				// present time, the most recent timestamp it may be performed at.
+				ex.state.mu.txn.SetBoundedStaleness()
+				ex.state.setHistoricalTimestamp(ctx, ex.server.cfg.Clock.Now())
+			} else {
+				p.extendedEvalCtx.SetTxnTimestamp(asOfTs.GoTime())
+				ex.state.setHistoricalTimestamp(ctx, *asOfTs)
+			}
 		}
 	} else {
 		// If we're in an explicit txn, we allow AOST but only if it matches with
 		// the transaction's timestamp. This is useful for running AOST statements
 		// using the InternalExecutor inside an external transaction; one might want
 		// to do that to force p.avoidCachedDescriptors to be set below.
+		if p.isBoundedStalenessAsOf(stmt.AST) {
+			return makeErrEvent(errBoundedStalenessInExplicitTxn)
+		}
 		ts, err := p.isAsOf(stmt.AST)
 		if err != nil {
 			return makeErrEvent(err)
@@ -677,6 +707,13 @@ func (ex *connExecutor) dispatchToExecutionEngine(
 		return nil
 	}
 
+	if ex.state.mu.txn.BoundedStaleness() {
+		if err := ex.negotiateBoundedStalenessTimestamp(ctx, planner); err != nil {
+			res.SetError(err)
+			return nil
+		}
+	}
+
 	var cols sqlbase.ResultColumns
 	if stmt.AST.StatementType() == tree.Rows {
 		cols = planColumns(planner.curPlan.plan)
@@ -938,6 +975,9 @@ func (ex *connExecutor) beginTransactionTimestampsAndReadMode(
 	}
 	p := &ex.planner
 	ex.resetPlanner(ctx, p, nil /* txn */, now.GoTime(), 0 /* numAnnotations */)
+	if tree.IsBoundedStalenessAsOf(s.Modes.AsOf, p.semaCtx.SearchPath) {
+		return 0, time.Time{}, nil, errBoundedStalenessInExplicitTxn
+	}
 	ts, err := p.EvalAsOfTimestamp(s.Modes.AsOf)
 	if err != nil {
 		return 0, time.Time{}, nil, err
@@ -1012,6 +1052,8 @@ func (ex *connExecutor) execStmtInNoTxnState(
 // - COMMIT / ROLLBACK: aborts the current transaction.
 // - ROLLBACK TO SAVEPOINT / SAVEPOINT: reopens the current transaction,
 //   allowing it to be retried.
+// - ROLLBACK TO SAVEPOINT of a regular savepoint: resumes the current
+//   transaction from the savepoint.
 func (ex *connExecutor) execStmtInAbortedState(
 	ctx context.Context, stmt Statement, res RestrictedCommandResult,
 ) (fsm.Event, fsm.EventPayload) {
@@ -1031,6 +1073,24 @@ func (ex *connExecutor) execStmtInAbortedState(
 
 		return eventTxnFinish{}, eventTxnFinishPayload{commit: false}
 	case *tree.RollbackToSavepoint, *tree.Savepoint:
+		// Regular savepoints can't be established in an aborted txn, but a
+		// ROLLBACK TO a regular savepoint resumes the transaction from the
+		// savepoint, provided that the KV txn was kept open.
+		switch n := s.(type) {
+		case *tree.RollbackToSavepoint:
+			if !inRestartWait && !ex.isRestartSavepoint(n.Savepoint) {
+				return ex.execRollbackToSavepointInAbortedState(ctx, n)
+			}
+		case *tree.Savepoint:
+			if !ex.isRestartSavepoint(n.Name) {
+				ev := eventNonRetriableErr{IsCommit: fsm.False}
+				payload := eventNonRetriableErrPayload{
+					err: sqlbase.NewTransactionAbortedError("" /* customMsg */),
+				}
+				return ev, payload
+			}
+		}
+
 		// We accept both the "ROLLBACK TO SAVEPOINT cockroach_restart" and the
 		// "SAVEPOINT cockroach_restart" commands to indicate client intent to
 		// retry a transaction in a RestartWait state.
@@ -1337,24 +1397,13 @@ func payloadHasError(payload fsm.EventPayload) bool {
 	return hasErr
 }
 
-// validateSavepointName validates that it is that the provided ident
-// matches the active savepoint name, begins with RestartSavepointName,
-// or that force_savepoint_restart==true. We accept everything with the
-// desired prefix because at least the C++ libpqxx appends sequence
-// numbers to the savepoint name specified by the user.
+// validateSavepointName validates that the provided restart savepoint ident
+// matches the active savepoint name, if there is one. See
+// isRestartSavepoint for the names that refer to the restart savepoint.
 func (ex *connExecutor) validateSavepointName(savepoint tree.Name) error {
-	if ex.state.activeSavepointName != "" {
-		if savepoint == ex.state.activeSavepointName {
-			return nil
-		}
+	if ex.state.activeSavepointName != "" && savepoint != ex.state.activeSavepointName {
 		return pgerror.Newf(pgcode.InvalidSavepointSpecification,
 			`SAVEPOINT %q is in use`, tree.ErrString(&ex.state.activeSavepointName))
 	}
-	if !ex.sessionData.ForceSavepointRestart && !strings.HasPrefix(string(savepoint), RestartSavepointName) {
-		return unimplemented.NewWithIssueHint(10735,
-			"SAVEPOINT not supported except for "+RestartSavepointName,
-			"Retryable transactions with arbitrary SAVEPOINT names can be enabled "+
-				"with SET force_savepoint_restart=true")
-	}
 	return nil
 }
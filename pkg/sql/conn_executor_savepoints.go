@@ -0,0 +1,168 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/fsm"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+// savepoint is a regular (non-restart) SQL savepoint.
+type savepoint struct {
+	name tree.Name
+	// kvToken is used to roll back or release the savepoint in the KV txn.
+	kvToken client.SavepointToken
+}
+
+// savepointStack is the stack of savepoints established in a transaction,
+// innermost last.
+type savepointStack []savepoint
+
+// find returns the index of the innermost savepoint with the given name, or
+// -1 if there is no such savepoint.
+func (s savepointStack) find(name tree.Name) int {
+	for i := len(s) - 1; i >= 0; i-- {
+		if s[i].name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// isRestartSavepoint returns true if the savepoint name refers to the special
+// cockroach_restart savepoint used for client-directed retries, as opposed to
+// a regular savepoint. We accept everything with the RestartSavepointName
+// prefix because at least the C++ libpqxx appends sequence numbers to the
+// savepoint name specified by the user. With force_savepoint_restart=true,
+// all savepoints are restart savepoints.
+func (ex *connExecutor) isRestartSavepoint(name tree.Name) bool {
+	if ex.state.activeSavepointName != "" && name == ex.state.activeSavepointName {
+		return true
+	}
+	return ex.sessionData.ForceSavepointRestart ||
+		strings.HasPrefix(string(name), RestartSavepointName)
+}
+
+// execSavepointInOpenState establishes a regular savepoint.
+func (ex *connExecutor) execSavepointInOpenState(ctx context.Context, s *tree.Savepoint) error {
+	// Nodes running older versions don't know about the sequence numbers that
+	// rolling back to a savepoint ignores.
+	if !ex.server.cfg.Settings.Version.IsActive(cluster.VersionSavepoints) {
+		return unimplemented.NewWithIssueHint(10735,
+			"SAVEPOINT not supported except for "+RestartSavepointName,
+			"Savepoints with other names require all nodes to be upgraded to "+
+				cluster.VersionByKey(cluster.VersionSavepoints).String())
+	}
+	token, err := ex.state.mu.txn.CreateSavepoint(ctx)
+	if err != nil {
+		return err
+	}
+	ex.state.savepoints = append(ex.state.savepoints, savepoint{name: s.Name, kvToken: token})
+	return nil
+}
+
+// execReleaseSavepointInOpenState releases a regular savepoint, along with all
+// the savepoints established after it.
+func (ex *connExecutor) execReleaseSavepointInOpenState(
+	ctx context.Context, s *tree.ReleaseSavepoint,
+) error {
+	idx, err := ex.findSavepoint(s.Savepoint)
+	if err != nil {
+		return err
+	}
+	if err := ex.state.mu.txn.ReleaseSavepoint(ctx, ex.state.savepoints[idx].kvToken); err != nil {
+		return err
+	}
+	ex.state.savepoints = ex.state.savepoints[:idx]
+	return nil
+}
+
+// execRollbackToSavepoint undoes the effects of the statements executed since
+// a regular savepoint was established. The savepoint itself remains in place,
+// but all the savepoints established after it are discarded.
+//
+// This is used both in the Open and in the Aborted state.
+func (ex *connExecutor) execRollbackToSavepoint(
+	ctx context.Context, s *tree.RollbackToSavepoint,
+) error {
+	idx, err := ex.findSavepoint(s.Savepoint)
+	if err != nil {
+		return err
+	}
+	// The descriptors and schema changes staged by DDL statements are not
+	// versioned with the KV writes, so we can't roll them back.
+	if ex.extraTxnState.tables.hasUncommittedTables() ||
+		len(ex.extraTxnState.schemaChangers.schemaChangers) > 0 {
+		return unimplemented.NewWithIssue(10735,
+			"ROLLBACK TO SAVEPOINT not supported after DDL statements")
+	}
+	if err := ex.state.mu.txn.RollbackToSavepoint(ctx, ex.state.savepoints[idx].kvToken); err != nil {
+		return err
+	}
+	ex.state.savepoints = ex.state.savepoints[:idx+1]
+	return nil
+}
+
+// execRollbackToSavepointInAbortedState handles a ROLLBACK TO SAVEPOINT for a
+// regular savepoint in the Aborted state. If successful, the transaction moves
+// back to the Open state.
+func (ex *connExecutor) execRollbackToSavepointInAbortedState(
+	ctx context.Context, s *tree.RollbackToSavepoint,
+) (fsm.Event, fsm.EventPayload) {
+	if err := ex.execRollbackToSavepoint(ctx, s); err != nil {
+		ev := eventNonRetriableErr{IsCommit: fsm.False}
+		payload := eventNonRetriableErrPayload{err: err}
+		return ev, payload
+	}
+	return eventSavepointRollback{}, nil
+}
+
+// findSavepoint returns the index of the innermost regular savepoint with the
+// given name.
+func (ex *connExecutor) findSavepoint(name tree.Name) (int, error) {
+	idx := ex.state.savepoints.find(name)
+	if idx == -1 {
+		return -1, pgerror.Newf(pgcode.InvalidSavepointSpecification,
+			"savepoint %q does not exist", tree.ErrString(&name))
+	}
+	return idx, nil
+}
+
+// cleanupOnErrorUnlessSavepoints cleans up the KV txn after an error, unless
+// regular savepoints have been established. In that case the KV txn is kept
+// open so that a later ROLLBACK TO SAVEPOINT can resume it.
+func (ts *txnState) cleanupOnErrorUnlessSavepoints(err error) {
+	if len(ts.savepoints) > 0 {
+		return
+	}
+	ts.mu.txn.CleanupOnError(ts.Ctx, err)
+}
+
+// rollbackKeptTxn rolls back a KV txn that was kept open by
+// cleanupOnErrorUnlessSavepoints, if any.
+func (ts *txnState) rollbackKeptTxn() {
+	if len(ts.savepoints) == 0 {
+		return
+	}
+	ts.savepoints = nil
+	if err := ts.mu.txn.Rollback(ts.Ctx); err != nil {
+		log.Warningf(ts.Ctx, "failed to rollback txn: %s", err)
+	}
+}
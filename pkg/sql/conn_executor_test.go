@@ -24,6 +24,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/base"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/server"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql"
 	"github.com/cockroachdb/cockroach/pkg/sql/tests"
 	"github.com/cockroachdb/cockroach/pkg/storage"
@@ -390,3 +392,59 @@ func TestAppNameStatisticsInitialization(t *testing.T) {
 		t.Fatalf("query was not counted properly: %+v", counts)
 	}
 }
+
+// TestSavepointsVersionGate verifies that savepoints other than
+// cockroach_restart are rejected until the cluster is upgraded to the version
+// that supports them.
+func TestSavepointsVersionGate(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	oldVersion := cluster.ClusterVersion{Version: cluster.VersionByKey(cluster.VersionSavepoints - 1)}
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{
+		Knobs: base.TestingKnobs{
+			Store:  &storage.StoreTestingKnobs{BootstrapVersion: &oldVersion},
+			Server: &server.TestingKnobs{DisableAutomaticVersionUpgrade: 1},
+		},
+	})
+	defer s.Stopper().Stop(ctx)
+	sqlDB := sqlutils.MakeSQLRunner(db)
+	sqlDB.Exec(t, `CREATE TABLE t (k INT PRIMARY KEY)`)
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`SAVEPOINT foo`); !testutils.IsError(err,
+		`SAVEPOINT not supported except for cockroach_restart`) {
+		t.Fatalf("expected savepoint to be rejected, got %v", err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The restart savepoint is always supported.
+	sqlDB.Exec(t, `BEGIN; SAVEPOINT cockroach_restart; INSERT INTO t VALUES (1);
+RELEASE SAVEPOINT cockroach_restart; COMMIT`)
+
+	sqlDB.Exec(t, `SET CLUSTER SETTING version = $1`,
+		cluster.VersionByKey(cluster.VersionSavepoints).String())
+	testutils.SucceedsSoon(t, func() error {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(`SAVEPOINT foo`); err != nil {
+			_ = tx.Rollback()
+			return err
+		}
+		if _, err := tx.Exec(`INSERT INTO t VALUES (2)`); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tx.Exec(`ROLLBACK TO SAVEPOINT foo`); err != nil {
+			t.Fatal(err)
+		}
+		return tx.Commit()
+	})
+	sqlDB.CheckQueryResults(t, `SELECT k FROM t`, [][]string{{"1"}})
+}
@@ -207,18 +207,23 @@ var _ payloadWithError = eventRetriableErrPayload{}
 // cockroach_restart. It moves the state to CommitWait.
 type eventTxnReleased struct{}
 
+// eventSavepointRollback is generated after a successful ROLLBACK TO SAVEPOINT
+// of a regular savepoint in the Aborted state. It moves the state back to Open.
+type eventSavepointRollback struct{}
+
 // payloadWithError is a common interface for the payloads that wrap an error.
 type payloadWithError interface {
 	errorCause() error
 }
 
-func (eventRetryIntentSet) Event()  {}
-func (eventTxnStart) Event()        {}
-func (eventTxnFinish) Event()       {}
-func (eventTxnRestart) Event()      {}
-func (eventNonRetriableErr) Event() {}
-func (eventRetriableErr) Event()    {}
-func (eventTxnReleased) Event()     {}
+func (eventRetryIntentSet) Event()    {}
+func (eventTxnStart) Event()          {}
+func (eventTxnFinish) Event()         {}
+func (eventTxnRestart) Event()        {}
+func (eventNonRetriableErr) Event()   {}
+func (eventRetriableErr) Event()      {}
+func (eventTxnReleased) Event()       {}
+func (eventSavepointRollback) Event() {}
 
 // TxnStateTransitions describe the transitions used by a connExecutor's
 // fsm.Machine. Args.Extended is a txnState, which is muted by the Actions.
@@ -298,6 +303,9 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Description: "Retriable err; will auto-retry",
 			Next:        stateOpen{ImplicitTxn: fsm.Var("implicitTxn"), RetryIntent: fsm.Var("retryIntent")},
 			Action: func(args fsm.Args) error {
+				// The savepoints will be established again by the statements being
+				// retried.
+				args.Extended.(*txnState).savepoints = nil
 				// The caller will call rewCap.rewindAndUnlock().
 				args.Extended.(*txnState).setAdvanceInfo(
 					rewind,
@@ -324,7 +332,9 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Next: stateAborted{RetryIntent: fsm.Var("retryIntent")},
 			Action: func(args fsm.Args) error {
 				ts := args.Extended.(*txnState)
-				ts.mu.txn.CleanupOnError(ts.Ctx, args.Payload.(payloadWithError).errorCause())
+				// If savepoints have been established, the KV txn is kept open so
+				// that the txn can be resumed through ROLLBACK TO SAVEPOINT.
+				ts.cleanupOnErrorUnlessSavepoints(args.Payload.(payloadWithError).errorCause())
 				ts.setAdvanceInfo(skipBatch, noRewind, txnAborted)
 				ts.txnAbortCount.Inc(1)
 				return nil
@@ -351,6 +361,9 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Next:        stateAborted{RetryIntent: fsm.False},
 			Action: func(args fsm.Args) error {
 				ts := args.Extended.(*txnState)
+				// The KV txn has been restarted, so the savepoints can't be used any
+				// more.
+				ts.savepoints = nil
 				ts.mu.txn.CleanupOnError(ts.Ctx, args.Payload.(payloadWithError).errorCause())
 				ts.setAdvanceInfo(skipBatch, noRewind, txnAborted)
 				ts.txnAbortCount.Inc(1)
@@ -363,7 +376,8 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Next: stateRestartWait{},
 			Action: func(args fsm.Args) error {
 				// Note: Preparing the KV txn for restart has already happened by this
-				// point.
+				// point. The savepoints can't be used across the restart.
+				args.Extended.(*txnState).savepoints = nil
 				args.Extended.(*txnState).setAdvanceInfo(skipBatch, noRewind, txnRestart)
 				return nil
 			},
@@ -388,6 +402,7 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 				// timestamp in that case. In the special case of the cockroach_restart
 				// savepoint, it's not clear to me what a user's expectation might be.
 				state.mu.txn.ManualRestart(args.Ctx, hlc.Timestamp{})
+				state.savepoints = nil
 				args.Extended.(*txnState).setAdvanceInfo(advanceOne, noRewind, txnRestart)
 				return nil
 			},
@@ -404,6 +419,7 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Next:        stateNoTxn{},
 			Action: func(args fsm.Args) error {
 				ts := args.Extended.(*txnState)
+				ts.rollbackKeptTxn()
 				ts.finishSQLTxn()
 				ts.setAdvanceInfo(
 					advanceOne, noRewind, args.Payload.(eventTxnFinishPayload).toEvent())
@@ -415,7 +431,23 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Description: "any other statement",
 			Next:        stateAborted{RetryIntent: fsm.Var("retryIntent")},
 			Action: func(args fsm.Args) error {
-				args.Extended.(*txnState).setAdvanceInfo(skipBatch, noRewind, noEvent)
+				ts := args.Extended.(*txnState)
+				if args.Event.(eventNonRetriableErr).IsCommit.Get() {
+					// The connExecutor is tearing down; don't leak a KV txn that was
+					// kept open for the savepoints.
+					ts.rollbackKeptTxn()
+				}
+				ts.setAdvanceInfo(skipBatch, noRewind, noEvent)
+				return nil
+			},
+		},
+		// ROLLBACK TO SAVEPOINT of a regular savepoint. The KV txn has already
+		// been rolled back to the savepoint by this point.
+		eventSavepointRollback{}: {
+			Description: "ROLLBACK TO SAVEPOINT",
+			Next:        stateOpen{ImplicitTxn: fsm.False, RetryIntent: fsm.Var("retryIntent")},
+			Action: func(args fsm.Args) error {
+				args.Extended.(*txnState).setAdvanceInfo(advanceOne, noRewind, noEvent)
 				return nil
 			},
 		},
@@ -429,6 +461,7 @@ var TxnStateTransitions = fsm.Compile(fsm.Pattern{
 			Next:        stateOpen{ImplicitTxn: fsm.False, RetryIntent: fsm.True},
 			Action: func(args fsm.Args) error {
 				ts := args.Extended.(*txnState)
+				ts.rollbackKeptTxn()
 				ts.finishSQLTxn()
 
 				payload := args.Payload.(eventTxnStartPayload)
@@ -365,6 +365,7 @@ func (c *copyMachine) addRow(ctx context.Context, line []byte) error {
 		switch t := c.resultColumns[i].Typ; t.Family() {
 		case types.BytesFamily,
 			types.DateFamily,
+			types.EnumFamily,
 			types.IntervalFamily,
 			types.INetFamily,
 			types.StringFamily,
@@ -341,6 +341,8 @@ CREATE TABLE crdb_internal.schema_changes (
 				case *sqlbase.DescriptorMutation_Constraint:
 					mutType = "CONSTRAINT VALIDATION"
 					targetName = tree.NewDString(d.Constraint.Name)
+				case *sqlbase.DescriptorMutation_MaterializedViewRefresh:
+					mutType = "MATERIALIZED VIEW REFRESH"
 				}
 				if err := addRow(
 					tableID,
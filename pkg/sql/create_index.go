@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 )
 
 type createIndexNode struct {
@@ -31,7 +32,7 @@ type createIndexNode struct {
 //          mysql requires INDEX on the table.
 func (p *planner) CreateIndex(ctx context.Context, n *tree.CreateIndex) (planNode, error) {
 	tableDesc, err := p.ResolveMutableTableDescriptor(
-		ctx, &n.Table, true /*required*/, ResolveRequireTableDesc,
+		ctx, &n.Table, true /*required*/, ResolveRequireTableOrMaterializedViewDesc,
 	)
 	if err != nil {
 		return nil, err
@@ -77,6 +78,40 @@ func MakeIndexDescriptor(n *tree.CreateIndex) (*sqlbase.IndexDescriptor, error)
 	return &indexDesc, nil
 }
 
+// MakePartialIndexPredicate validates the predicate of a partial index and
+// returns its serialized form, suitable for storing in the index descriptor.
+// The predicate must be a boolean expression that only references columns of
+// the table, and it cannot contain impure functions, since the index must
+// remain consistent with the rows it was computed from.
+func MakePartialIndexPredicate(
+	ctx context.Context,
+	desc *sqlbase.MutableTableDescriptor,
+	predicate tree.Expr,
+	semaCtx *tree.SemaContext,
+	tableName tree.TableName,
+) (string, error) {
+	// Replace column references with typed dummies to allow typechecking.
+	replacedExpr, _, err := replaceVars(desc, predicate)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := sqlbase.SanitizeVarFreeExpr(
+		replacedExpr, types.Bool, "index predicate", semaCtx, false, /* allowImpure */
+	); err != nil {
+		return "", err
+	}
+
+	sourceInfo := sqlbase.NewSourceInfoForSingleTable(
+		tableName, sqlbase.ResultColumnsFromColDescs(desc.TableDesc().AllNonDropColumns()),
+	)
+	expr, err := dequalifyColumnRefs(ctx, sqlbase.MakeMultiSourceInfo(sourceInfo), predicate)
+	if err != nil {
+		return "", err
+	}
+	return tree.Serialize(expr), nil
+}
+
 func (n *createIndexNode) startExec(params runParams) error {
 	_, dropped, err := n.tableDesc.FindIndexByName(string(n.n.Name))
 	if err == nil {
@@ -103,6 +138,15 @@ func (n *createIndexNode) startExec(params runParams) error {
 		indexDesc.Partitioning = partitioning
 	}
 
+	if n.n.Predicate != nil {
+		indexDesc.Predicate, err = MakePartialIndexPredicate(
+			params.ctx, n.tableDesc, n.n.Predicate, &params.p.semaCtx, n.n.Table,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
 	mutationIdx := len(n.tableDesc.Mutations)
 	if err := n.tableDesc.AddIndexMutation(indexDesc, sqlbase.DescriptorMutation_ADD); err != nil {
 		return err
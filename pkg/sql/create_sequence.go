@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 )
 
@@ -33,6 +34,9 @@ func (p *planner) CreateSequence(ctx context.Context, n *tree.CreateSequence) (p
 	if err != nil {
 		return nil, err
 	}
+	if isTemporarySchema(n.Name.Schema()) {
+		return nil, unimplemented.NewWithIssue(5807, "temporary sequences are not supported")
+	}
 
 	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
 		return nil, err
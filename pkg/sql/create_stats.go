@@ -146,7 +146,9 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 	var err error
 	switch t := n.Table.(type) {
 	case *tree.UnresolvedObjectName:
-		tableDesc, err = n.p.ResolveExistingObjectEx(ctx, t, true /*required*/, ResolveRequireTableDesc)
+		tableDesc, err = n.p.ResolveExistingObjectEx(
+			ctx, t, true /*required*/, ResolveRequireTableOrMaterializedViewDesc,
+		)
 		if err != nil {
 			return nil, err
 		}
@@ -172,7 +174,7 @@ func (n *createStatsNode) makeJobRecord(ctx context.Context) (*jobs.Record, erro
 		)
 	}
 
-	if tableDesc.IsView() {
+	if tableDesc.IsView() && !tableDesc.MaterializedView() {
 		return nil, pgerror.New(
 			pgcode.WrongObjectType, "cannot create statistics on views",
 		)
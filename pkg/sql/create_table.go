@@ -48,11 +48,17 @@ type createTableNode struct {
 // Privileges: CREATE on database.
 //   Notes: postgres/mysql require CREATE on database.
 func (p *planner) CreateTable(ctx context.Context, n *tree.CreateTable) (planNode, error) {
+	explicitPermanentSchema := n.Table.ExplicitSchema && n.Table.SchemaName == tree.PublicSchemaName
 	dbDesc, err := p.ResolveUncachedDatabase(ctx, &n.Table)
 	if err != nil {
 		return nil, err
 	}
 
+	n.Temporary, err = p.resolveTemporaryTableTarget(&n.Table, n.Temporary, explicitPermanentSchema)
+	if err != nil {
+		return nil, err
+	}
+
 	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
 		return nil, err
 	}
@@ -102,7 +108,21 @@ type createTableRun struct {
 }
 
 func (n *createTableNode) startExec(params runParams) error {
-	tKey := sqlbase.NewTableKey(n.dbDesc.ID, n.n.Table.Table())
+	// Temporary tables are recorded in system.namespace under their temporary
+	// schema, which is created along with the first temporary table of the
+	// session in the database.
+	nameParentID := n.dbDesc.ID
+	temporarySchemaID := sqlbase.InvalidID
+	if n.n.Temporary {
+		var err error
+		temporarySchemaID, err = params.p.getOrCreateTemporarySchema(params.ctx, n.dbDesc.ID)
+		if err != nil {
+			return err
+		}
+		nameParentID = temporarySchemaID
+	}
+
+	tKey := sqlbase.NewTableKey(nameParentID, n.n.Table.Table())
 	key := tKey.Key()
 	if exists, err := descExists(params.ctx, params.p.txn, key); err == nil && exists {
 		if n.n.IfNotExists {
@@ -146,7 +166,7 @@ func (n *createTableNode) startExec(params runParams) error {
 			asCols = asCols[:len(asCols)-1]
 		}
 		desc, err = makeTableDescIfAs(
-			n.n, n.dbDesc.ID, id, creationTime, asCols,
+			n.n, n.dbDesc.ID, temporarySchemaID, id, creationTime, asCols,
 			privs, &params.p.semaCtx, params.p.EvalContext())
 		if err != nil {
 			return err
@@ -159,7 +179,8 @@ func (n *createTableNode) startExec(params runParams) error {
 		}
 	} else {
 		affected = make(map[sqlbase.ID]*sqlbase.MutableTableDescriptor)
-		desc, err = makeTableDesc(params, n.n, n.dbDesc.ID, id, creationTime, privs, affected)
+		desc, err = makeTableDesc(
+			params, n.n, n.dbDesc.ID, temporarySchemaID, id, creationTime, privs, affected)
 		if err != nil {
 			return err
 		}
@@ -190,6 +211,12 @@ func (n *createTableNode) startExec(params runParams) error {
 		return err
 	}
 
+	for i := range desc.Columns {
+		if err := params.p.addTypeBackReference(params.ctx, desc.ID, &desc.Columns[i]); err != nil {
+			return err
+		}
+	}
+
 	for _, updated := range affected {
 		if err := params.p.writeSchemaChange(params.ctx, updated, sqlbase.InvalidMutationID); err != nil {
 			return err
@@ -240,6 +267,7 @@ func (n *createTableNode) startExec(params runParams) error {
 			nil,
 			desc.Columns,
 			row.SkipFKs,
+			params.EvalContext(),
 			&params.p.alloc)
 		if err != nil {
 			return err
@@ -358,10 +386,14 @@ const (
 
 // Referenced cols must be unique, thus referenced indexes must match exactly.
 // Referencing cols have no uniqueness requirement and thus may match a strict
-// prefix of an index.
+// prefix of an index. Partial indexes never match, since they do not contain
+// every row of the table.
 func matchesIndex(
 	cols []sqlbase.ColumnDescriptor, idx sqlbase.IndexDescriptor, exact indexMatch,
 ) bool {
+	if idx.IsPartial() {
+		return false
+	}
 	if len(cols) > len(idx.ColumnIDs) || (exact && len(cols) != len(idx.ColumnIDs)) {
 		return false
 	}
@@ -460,6 +492,14 @@ func ResolveFK(
 	if err != nil {
 		return err
 	}
+	if target.IsTemporary() != tbl.IsTemporary() {
+		if tbl.IsTemporary() {
+			return pgerror.New(pgcode.InvalidTableDefinition,
+				"constraints on temporary tables may reference only temporary tables")
+		}
+		return pgerror.New(pgcode.InvalidTableDefinition,
+			"constraints on permanent tables may reference only permanent tables")
+	}
 	if target.ID == tbl.ID {
 		// When adding a self-ref FK to an _existing_ table, we want to make sure
 		// we edit the same copy.
@@ -768,6 +808,10 @@ func addInterleave(
 	if err != nil {
 		return err
 	}
+	if parentTable.IsTemporary() != desc.IsTemporary() {
+		return pgerror.New(pgcode.InvalidSchemaDefinition,
+			"temporary and permanent tables cannot be interleaved with each other")
+	}
 	parentIndex := parentTable.PrimaryIndex
 
 	// typeOfIndex is used to give more informative error messages.
@@ -976,7 +1020,7 @@ func getFinalSourceQuery(source *tree.Select, evalCtx *tree.EvalContext) string
 // that is created with the CREATE AS format.
 func makeTableDescIfAs(
 	p *tree.CreateTable,
-	parentID, id sqlbase.ID,
+	parentID, temporarySchemaID, id sqlbase.ID,
 	creationTime hlc.Timestamp,
 	resultColumns []sqlbase.ResultColumn,
 	privileges *sqlbase.PrivilegeDescriptor,
@@ -984,6 +1028,7 @@ func makeTableDescIfAs(
 	evalContext *tree.EvalContext,
 ) (desc sqlbase.MutableTableDescriptor, err error) {
 	desc = InitTableDescriptor(id, parentID, p.Table.Table(), creationTime, privileges)
+	desc.TemporarySchemaID = temporarySchemaID
 	desc.CreateQuery = getFinalSourceQuery(p.AsSource, evalContext)
 
 	for i, colRes := range resultColumns {
@@ -1065,7 +1110,7 @@ func MakeTableDesc(
 	vt SchemaResolver,
 	st *cluster.Settings,
 	n *tree.CreateTable,
-	parentID, id sqlbase.ID,
+	parentID, temporarySchemaID, id sqlbase.ID,
 	creationTime hlc.Timestamp,
 	privileges *sqlbase.PrivilegeDescriptor,
 	affected map[sqlbase.ID]*sqlbase.MutableTableDescriptor,
@@ -1073,6 +1118,7 @@ func MakeTableDesc(
 	evalCtx *tree.EvalContext,
 ) (sqlbase.MutableTableDescriptor, error) {
 	desc := InitTableDescriptor(id, parentID, n.Table.Table(), creationTime, privileges)
+	desc.TemporarySchemaID = temporarySchemaID
 
 	for _, def := range n.Defs {
 		if d, ok := def.(*tree.ColumnTableDef); ok {
@@ -1166,6 +1212,13 @@ func MakeTableDesc(
 				}
 				idx.Partitioning = partitioning
 			}
+			if d.Predicate != nil {
+				predicate, err := MakePartialIndexPredicate(ctx, &desc, d.Predicate, semaCtx, n.Table)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = predicate
+			}
 			if err := desc.AddIndex(idx, false); err != nil {
 				return desc, err
 			}
@@ -1188,6 +1241,13 @@ func MakeTableDesc(
 				}
 				idx.Partitioning = partitioning
 			}
+			if d.Predicate != nil {
+				predicate, err := MakePartialIndexPredicate(ctx, &desc, d.Predicate, semaCtx, n.Table)
+				if err != nil {
+					return desc, err
+				}
+				idx.Predicate = predicate
+			}
 			if err := desc.AddIndex(idx, d.PrimaryKey); err != nil {
 				return desc, err
 			}
@@ -1315,7 +1375,7 @@ func MakeTableDesc(
 func makeTableDesc(
 	params runParams,
 	n *tree.CreateTable,
-	parentID, id sqlbase.ID,
+	parentID, temporarySchemaID, id sqlbase.ID,
 	creationTime hlc.Timestamp,
 	privileges *sqlbase.PrivilegeDescriptor,
 	affected map[sqlbase.ID]*sqlbase.MutableTableDescriptor,
@@ -1340,6 +1400,11 @@ func makeTableDesc(
 			return ret, err
 		}
 		if seqName != nil {
+			if temporarySchemaID != sqlbase.InvalidID {
+				// The sequence would outlive the temporary table.
+				return ret, unimplemented.NewWithIssuef(5807,
+					"cannot create a sequence for SERIAL column %q of temporary table %q", d.Name, n.Table.Table())
+			}
 			if err := doCreateSequence(params, n.String(), seqDbDesc, seqName, seqOpts); err != nil {
 				return ret, err
 			}
@@ -1362,6 +1427,7 @@ func makeTableDesc(
 			params.p.ExecCfg().Settings,
 			n,
 			parentID,
+			temporarySchemaID,
 			id,
 			creationTime,
 			privileges,
@@ -0,0 +1,136 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+)
+
+type createTypeNode struct {
+	n      *tree.CreateType
+	tn     ObjectName
+	dbDesc *sqlbase.DatabaseDescriptor
+}
+
+// CreateType creates a user-defined type. Only enum types are supported.
+// Privileges: CREATE on database.
+func (p *planner) CreateType(ctx context.Context, n *tree.CreateType) (planNode, error) {
+	tn := n.TypeName.ToTableName()
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, &tn)
+	if err != nil {
+		return nil, err
+	}
+	if isTemporarySchema(tn.Schema()) {
+		return nil, unimplemented.Newf("create.type.temporary", "temporary types are not supported")
+	}
+
+	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	// Check for duplicate labels up front, so that the error is reported
+	// before any descriptor ID is allocated.
+	seen := make(map[string]struct{}, len(n.EnumLabels))
+	for _, label := range n.EnumLabels {
+		if _, ok := seen[label]; ok {
+			return nil, pgerror.Newf(pgcode.DuplicateObject,
+				"enum label %q used more than once", label)
+		}
+		seen[label] = struct{}{}
+	}
+
+	return &createTypeNode{n: n, tn: tn, dbDesc: dbDesc}, nil
+}
+
+func (n *createTypeNode) startExec(params runParams) error {
+	existing, err := findTypeDesc(params.ctx, params.p.txn, n.dbDesc.ID, n.tn.Table())
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return pgerror.Newf(pgcode.DuplicateObject, "type %q already exists", n.tn.Table())
+	}
+	// Type names cannot shadow the names of built-in types, since the parser
+	// only produces references to user-defined types for names it does not
+	// recognize.
+	if _, ok, _ := types.TypeForNonKeywordTypeName(n.tn.Table()); ok {
+		return pgerror.Newf(pgcode.DuplicateObject, "type %q already exists", n.tn.Table())
+	}
+
+	id, err := GenerateUniqueDescID(params.ctx, params.extendedEvalCtx.ExecCfg.DB)
+	if err != nil {
+		return err
+	}
+
+	physical := encoding.GenEnumPhysicalRepresentations(len(n.n.EnumLabels))
+	members := make([]sqlbase.TypeDescriptor_EnumMember, len(n.n.EnumLabels))
+	for i, label := range n.n.EnumLabels {
+		members[i] = sqlbase.TypeDescriptor_EnumMember{
+			PhysicalRepresentation: physical[i],
+			LogicalRepresentation:  label,
+		}
+	}
+
+	// Inherit permissions from the database descriptor.
+	desc := &sqlbase.TypeDescriptor{
+		Name:        n.tn.Table(),
+		ID:          id,
+		ParentID:    n.dbDesc.ID,
+		Privileges:  n.dbDesc.GetPrivileges(),
+		EnumMembers: members,
+	}
+	return params.p.writeTypeDesc(params.ctx, desc)
+}
+
+func (*createTypeNode) Next(runParams) (bool, error) { return false, nil }
+func (*createTypeNode) Values() tree.Datums          { return tree.Datums{} }
+func (*createTypeNode) Close(context.Context)        {}
+
+// writeTypeDesc validates the given type descriptor and writes it to the
+// descriptor table.
+func (p *planner) writeTypeDesc(ctx context.Context, desc *sqlbase.TypeDescriptor) error {
+	if err := desc.Validate(); err != nil {
+		return err
+	}
+	descKey := sqlbase.MakeDescMetadataKey(desc.ID)
+	descDesc := sqlbase.WrapDescriptor(desc)
+	if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+		log.VEventf(ctx, 2, "Put %s -> %s", descKey, descDesc)
+	}
+	return p.txn.Put(ctx, descKey, descDesc)
+}
+
+// addTypeBackReference records in the descriptor of the user-defined type of
+// the given column, if it has one, that the table with the given ID uses the
+// type. See updateTypeReferences.
+func (p *planner) addTypeBackReference(
+	ctx context.Context, tableID sqlbase.ID, col *sqlbase.ColumnDescriptor,
+) error {
+	if col.Type.Family() != types.EnumFamily {
+		return nil
+	}
+	typeDesc := &sqlbase.TypeDescriptor{}
+	if err := getDescriptorByID(ctx, p.txn, sqlbase.ID(col.Type.StableTypeID()), typeDesc); err != nil {
+		return err
+	}
+	typeDesc.AddReferencingDescriptorID(tableID)
+	return p.writeTypeDesc(ctx, typeDesc)
+}
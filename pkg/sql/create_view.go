@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 )
@@ -43,6 +44,12 @@ func (p *planner) CreateView(ctx context.Context, n *tree.CreateView) (planNode,
 	if err != nil {
 		return nil, err
 	}
+	if isTemporarySchema(n.Name.Schema()) {
+		if n.Materialized {
+			return nil, unimplemented.NewWithIssue(5807, "temporary materialized views are not supported")
+		}
+		return nil, unimplemented.NewWithIssue(5807, "temporary views are not supported")
+	}
 
 	if err := p.CheckPrivilege(ctx, dbDesc, privilege.CREATE); err != nil {
 		return nil, err
@@ -141,6 +148,13 @@ func (n *createViewNode) startExec(params runParams) error {
 		desc.DependsOn = append(desc.DependsOn, backrefID)
 	}
 
+	// A materialized view is populated by the schema changer, which runs the
+	// view query as of the creation time of the view and makes it public once
+	// the results have been written, like it does for CREATE TABLE AS.
+	if n.n.Materialized {
+		desc.State = sqlbase.TableDescriptor_ADD
+	}
+
 	if err = params.p.createDescriptorWithID(
 		params.ctx, key, id, &desc, params.EvalContext().Settings); err != nil {
 		return err
@@ -197,7 +211,8 @@ func (n *createViewNode) Close(ctx context.Context)  {}
 // the ADDING state because back-references are added to the view's
 // dependencies in the same transaction that the view is created and it
 // doesn't matter if reads/writes use a cached descriptor that doesn't
-// include the back-references.
+// include the back-references. Materialized views are the exception, see
+// startExec.
 func (n *createViewNode) makeViewTableDesc(
 	params runParams,
 	viewName string,
@@ -210,8 +225,14 @@ func (n *createViewNode) makeViewTableDesc(
 	desc := InitTableDescriptor(id, parentID, viewName,
 		params.p.txn.CommitTimestamp(), privileges)
 	desc.ViewQuery = tree.AsStringWithFlags(n.n.AsSource, tree.FmtParsable)
+	desc.IsMaterializedView = n.n.Materialized
 	for i, colRes := range resultColumns {
 		columnTableDef := tree.ColumnTableDef{Name: tree.Name(colRes.Name), Type: colRes.Typ}
+		if n.n.Materialized {
+			// The results of the view query are stored, so its columns must
+			// accept NULLs like those of CREATE TABLE AS.
+			columnTableDef.Nullable.Nullability = tree.SilentNull
+		}
 		if len(columnNames) > i {
 			columnTableDef.Name = columnNames[i]
 		}
@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
 )
 
@@ -56,6 +57,11 @@ func (p *planner) Delete(
 		return nil, pgerror.DangerousStatementf("DELETE without WHERE clause")
 	}
 
+	if len(n.Using) > 0 {
+		return nil, unimplemented.NewWithIssue(7841,
+			"DELETE ... USING is not supported by the heuristic planner")
+	}
+
 	// CTE analysis.
 	resetter, err := p.initWith(ctx, n.With)
 	if err != nil {
@@ -206,6 +212,15 @@ type deleteRun struct {
 	// rows contains the accumulated result rows if rowsNeeded is set.
 	rows *rowcontainer.RowContainer
 
+	// numPassthrough is the number of columns at the end of the source rows
+	// that are returned as they are after the columns of the deleted rows,
+	// for the columns of the USING tables referenced by RETURNING.
+	numPassthrough int
+
+	// resultRowBuffer is used to build the result rows when there are
+	// passthrough columns.
+	resultRowBuffer tree.Datums
+
 	// traceKV caches the current KV tracing flag.
 	traceKV bool
 }
@@ -332,8 +347,14 @@ func (d *deleteNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		// visible. We do not want them to be available for RETURNING.
 		//
 		// d.columns is guaranteed to only contain the requested
-		// public columns.
-		resultValues := sourceVals[:len(d.columns)]
+		// public columns, followed by any passthrough columns.
+		resultValues := sourceVals[:len(d.columns)-d.run.numPassthrough]
+		if d.run.numPassthrough > 0 {
+			d.run.resultRowBuffer = append(d.run.resultRowBuffer[:0], resultValues...)
+			d.run.resultRowBuffer = append(d.run.resultRowBuffer,
+				sourceVals[len(sourceVals)-d.run.numPassthrough:]...)
+			resultValues = d.run.resultRowBuffer
+		}
 		if _, err := d.run.rows.AddRow(params.ctx, resultValues); err != nil {
 			return err
 		}
@@ -290,6 +290,7 @@ func newQueryNotSupportedErrorf(format string, args ...interface{}) error {
 
 var mutationsNotSupportedError = newQueryNotSupportedError("mutations not supported")
 var setNotSupportedError = newQueryNotSupportedError("SET / SET CLUSTER SETTING should never distribute")
+var lockingScanNotSupportedError = newQueryNotSupportedError("row-level locking should never distribute")
 
 // mustWrapNode returns true if a node has no DistSQL-processor equivalent.
 // This must be kept in sync with createPlanForNode.
@@ -380,6 +381,12 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return rec, nil
 
 	case *scanNode:
+		if n.lockingStrength.IsExclusive() {
+			// Locking scans write intents, which can only be done by the root
+			// transaction on the gateway.
+			return cannotDistribute, lockingScanNotSupportedError
+		}
+
 		rec := canDistribute
 		if n.softLimit != 0 {
 			// We don't yet recommend distributing plans where soft limits propagate
@@ -410,6 +417,11 @@ func (dsp *DistSQLPlanner) checkSupportForNode(node planNode) (distRecommendatio
 		return dsp.checkSupportForNode(n.index)
 
 	case *lookupJoinNode:
+		if n.table.lockingStrength.IsExclusive() {
+			// Locking lookup joins write intents, which can only be done by the
+			// root transaction on the gateway.
+			return cannotDistribute, lockingScanNotSupportedError
+		}
 		if err := dsp.checkExpr(n.onCond); err != nil {
 			return cannotDistribute, err
 		}
@@ -872,6 +884,8 @@ func initTableReaderSpec(
 		IsCheck:    n.isCheck,
 		Visibility: n.colCfg.visibility.toDistSQLScanVisibility(),
 
+		LockingStrength: n.lockingStrength,
+
 		// Retain the capacity of the spans slice.
 		Spans: s.Spans[:0],
 	}
@@ -1844,9 +1858,10 @@ func (dsp *DistSQLPlanner) createPlanForIndexJoin(
 	}
 
 	joinReaderSpec := distsqlpb.JoinReaderSpec{
-		Table:      *n.index.desc.TableDesc(),
-		IndexIdx:   0,
-		Visibility: n.table.colCfg.visibility.toDistSQLScanVisibility(),
+		Table:           *n.index.desc.TableDesc(),
+		IndexIdx:        0,
+		Visibility:      n.table.colCfg.visibility.toDistSQLScanVisibility(),
+		LockingStrength: n.table.lockingStrength,
 	}
 
 	filter, err := distsqlplan.MakeExpression(
@@ -1912,8 +1927,9 @@ func (dsp *DistSQLPlanner) createPlanForLookupJoin(
 	}
 
 	joinReaderSpec := distsqlpb.JoinReaderSpec{
-		Table: *n.table.desc.TableDesc(),
-		Type:  n.joinType,
+		Table:           *n.table.desc.TableDesc(),
+		Type:            n.joinType,
+		LockingStrength: n.table.lockingStrength,
 	}
 	joinReaderSpec.IndexIdx, err = getIndexIdx(n.table)
 	if err != nil {
@@ -60,6 +60,15 @@ func PlanAndRunExport(
 ) error {
 	planCtx := dsp.NewPlanningCtx(ctx, evalCtx, txn)
 
+	if out.CSVWriter != nil {
+		// Formats with a schema name the columns after the input's.
+		cols := planColumns(in)
+		out.CSVWriter.ColumnNames = make([]string, len(cols))
+		for i := range cols {
+			out.CSVWriter.ColumnNames[i] = cols[i].Name
+		}
+	}
+
 	rec, err := dsp.checkSupportForNode(in)
 	planCtx.isLocal = err != nil || rec == cannotDistribute
 
@@ -160,13 +169,15 @@ func (c *callbackResultWriter) Err() error {
 }
 
 // LoadCSV performs a distributed transformation of the CSV files at from
-// and stores them in enterprise backup format at to.
+// and stores them in enterprise backup format at to. If targetCols is
+// non-empty, the files supply only those columns of the single table.
 func LoadCSV(
 	ctx context.Context,
 	phs PlanHookState,
 	job *jobs.Job,
 	resultRows *RowResultWriter,
 	tables map[string]*sqlbase.TableDescriptor,
+	targetCols []string,
 	from []string,
 	format roachpb.IOFileFormat,
 	walltime int64,
@@ -182,7 +193,7 @@ func LoadCSV(
 		return err
 	}
 
-	inputSpecs := makeImportReaderSpecs(job, tables, from, format, nodes, walltime)
+	inputSpecs := makeImportReaderSpecs(job, tables, targetCols, from, format, nodes, walltime)
 
 	sstSpecs := make([]distsqlpb.SSTWriterSpec, len(nodes))
 	for i := range nodes {
@@ -467,6 +478,7 @@ func (dsp *DistSQLPlanner) setupAllNodesPlanning(
 func makeImportReaderSpecs(
 	job *jobs.Job,
 	tables map[string]*sqlbase.TableDescriptor,
+	targetCols []string,
 	from []string,
 	format roachpb.IOFileFormat,
 	nodes []roachpb.NodeID,
@@ -480,8 +492,9 @@ func makeImportReaderSpecs(
 		// creates the spec. Future files just add themselves to the Uris.
 		if i < len(nodes) {
 			spec := &distsqlpb.ReadImportDataSpec{
-				Tables: tables,
-				Format: format,
+				Tables:     tables,
+				TargetCols: targetCols,
+				Format:     format,
 				Progress: distsqlpb.JobProgress{
 					JobID: *job.ID(),
 					Slot:  int32(i),
@@ -654,12 +667,14 @@ func (dsp *DistSQLPlanner) loadCSVSamplingPlan(
 // DistIngest is used by IMPORT to run a DistSQL flow to ingest data by starting
 // reader processes on many nodes that each read and ingest their assigned files
 // and then send back a summary of what they ingested. The combined summary is
-// returned.
+// returned. If targetCols is non-empty, the files supply only those columns of
+// the single table.
 func DistIngest(
 	ctx context.Context,
 	phs PlanHookState,
 	job *jobs.Job,
 	tables map[string]*sqlbase.TableDescriptor,
+	targetCols []string,
 	from []string,
 	format roachpb.IOFileFormat,
 	walltime int64,
@@ -674,7 +689,7 @@ func DistIngest(
 		return roachpb.BulkOpSummary{}, err
 	}
 
-	inputSpecs := makeImportReaderSpecs(job, tables, from, format, nodes, walltime)
+	inputSpecs := makeImportReaderSpecs(job, tables, targetCols, from, format, nodes, walltime)
 
 	for i := range inputSpecs {
 		inputSpecs[i].IngestDirectly = true
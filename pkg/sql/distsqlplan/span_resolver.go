@@ -121,6 +121,10 @@ type spanResolver struct {
 	distSender    *kv.DistSender
 	nodeDesc      roachpb.NodeDescriptor
 	oracleFactory replicaoracle.OracleFactory
+	// closestOracleFactory is used instead of oracleFactory for
+	// bounded-staleness reads, whose timestamp is negotiated so that the
+	// closest replica of every range can serve them.
+	closestOracleFactory replicaoracle.OracleFactory
 }
 
 var _ SpanResolver = &spanResolver{}
@@ -134,18 +138,20 @@ func NewSpanResolver(
 	rpcCtx *rpc.Context,
 	policy replicaoracle.Policy,
 ) SpanResolver {
+	oracleCfg := replicaoracle.Config{
+		Settings:         st,
+		Gossip:           gossip,
+		NodeDesc:         nodeDesc,
+		RPCContext:       rpcCtx,
+		LeaseHolderCache: distSender.LeaseHolderCache(),
+	}
 	return &spanResolver{
-		st:       st,
-		nodeDesc: nodeDesc,
-		oracleFactory: replicaoracle.NewOracleFactory(policy, replicaoracle.Config{
-			Settings:         st,
-			Gossip:           gossip,
-			NodeDesc:         nodeDesc,
-			RPCContext:       rpcCtx,
-			LeaseHolderCache: distSender.LeaseHolderCache(),
-		}),
-		distSender: distSender,
-		gossip:     gossip,
+		st:                   st,
+		nodeDesc:             nodeDesc,
+		oracleFactory:        replicaoracle.NewOracleFactory(policy, oracleCfg),
+		closestOracleFactory: replicaoracle.NewOracleFactory(replicaoracle.ClosestChoice, oracleCfg),
+		distSender:           distSender,
+		gossip:               gossip,
 	}
 }
 
@@ -173,10 +179,14 @@ var _ SpanResolverIterator = &spanResolverIterator{}
 
 // NewSpanResolverIterator creates a new SpanResolverIterator.
 func (sr *spanResolver) NewSpanResolverIterator(txn *client.Txn) SpanResolverIterator {
+	oracleFactory := sr.oracleFactory
+	if txn != nil && txn.BoundedStaleness() {
+		oracleFactory = sr.closestOracleFactory
+	}
 	return &spanResolverIterator{
 		gossip:     sr.gossip,
 		it:         kv.NewRangeIterator(sr.distSender),
-		oracle:     sr.oracleFactory.Oracle(txn),
+		oracle:     oracleFactory.Oracle(txn),
 		queryState: replicaoracle.MakeQueryState(),
 	}
 }
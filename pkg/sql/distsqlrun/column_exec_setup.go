@@ -27,7 +27,9 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	semtypes "github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/tracing"
@@ -92,10 +94,94 @@ func wrapRowSource(
 	return newColumnarizer(flowCtx, processorID, toWrap)
 }
 
+// vectorizedFlowResources tracks the memory monitors, the accounts and the
+// temporary storage used by the operators of a vectorized flow that spill to
+// disk. They are released when the flow is cleaned up.
+type vectorizedFlowResources struct {
+	monitors []*mon.BytesMonitor
+	accounts []*mon.BoundAccount
+	closers  []exec.Closer
+}
+
+// spillingConfig returns the configuration of an operator that may spill the
+// tuples of its inputs to disk, and whether the operator should spill at all.
+// useTempStorage is the cluster setting that controls whether the operator
+// uses temporary storage, and inputTypes contains the types of each input that
+// may be spilled.
+func (r *vectorizedFlowResources) spillingConfig(
+	ctx context.Context,
+	flowCtx *FlowCtx,
+	name string,
+	useTempStorage bool,
+	inputTypes ...[]types.T,
+) (exec.SpillingConfig, bool) {
+	if !useTempStorage && flowCtx.testingKnobs.MemoryLimitBytes <= 0 {
+		return exec.SpillingConfig{}, false
+	}
+	if flowCtx.TempStorage == nil {
+		return exec.SpillingConfig{}, false
+	}
+	for _, typs := range inputTypes {
+		if !exec.SupportsSpilling(typs) {
+			return exec.SpillingConfig{}, false
+		}
+	}
+	memAcc := r.limitedMemAccount(ctx, flowCtx, name)
+	diskMon := NewMonitor(ctx, flowCtx.diskMonitor, name+"-disk")
+	diskAcc := diskMon.MakeBoundAccount()
+	r.monitors = append(r.monitors, diskMon)
+	r.accounts = append(r.accounts, &diskAcc)
+	return exec.SpillingConfig{
+		MemAcc:         memAcc,
+		DiskAcc:        &diskAcc,
+		DiskMapFactory: flowCtx.TempStorage,
+	}, true
+}
+
+// limitedMemAccount returns a memory account for an operator that buffers
+// its input, limited by the work memory setting. The operator is expected to
+// overflow to disk or to fall back to another implementation if the limit is
+// not enough.
+func (r *vectorizedFlowResources) limitedMemAccount(
+	ctx context.Context, flowCtx *FlowCtx, name string,
+) *mon.BoundAccount {
+	limit := flowCtx.testingKnobs.MemoryLimitBytes
+	if limit <= 0 {
+		limit = settingWorkMemBytes.Get(&flowCtx.Settings.SV)
+	}
+	limitedMon := mon.MakeMonitorInheritWithLimit(name+"-limited", limit, flowCtx.EvalCtx.Mon)
+	limitedMon.Start(ctx, flowCtx.EvalCtx.Mon, mon.BoundAccount{})
+	memAcc := limitedMon.MakeBoundAccount()
+	r.monitors = append(r.monitors, &limitedMon)
+	r.accounts = append(r.accounts, &memAcc)
+	return &memAcc
+}
+
+// close releases the temporary storage used by the operators, and then their
+// accounts and monitors.
+func (r *vectorizedFlowResources) close(ctx context.Context) {
+	for _, c := range r.closers {
+		c.Close(ctx)
+	}
+	for _, acc := range r.accounts {
+		acc.Close(ctx)
+	}
+	for _, m := range r.monitors {
+		m.Stop(ctx)
+	}
+	*r = vectorizedFlowResources{}
+}
+
 // newColOperator creates a new columnar operator according to the given spec.
-// The operator and its output types are returned if there was no error.
+// The operator and its output types are returned if there was no error. The
+// resources used by the operator that must be released once the flow is done
+// are added to resources.
 func newColOperator(
-	ctx context.Context, flowCtx *FlowCtx, spec *distsqlpb.ProcessorSpec, inputs []exec.Operator,
+	ctx context.Context,
+	flowCtx *FlowCtx,
+	spec *distsqlpb.ProcessorSpec,
+	inputs []exec.Operator,
+	resources *vectorizedFlowResources,
 ) (exec.Operator, []types.T, error) {
 	core := &spec.Core
 	post := &spec.Post
@@ -120,6 +206,9 @@ func newColOperator(
 		if err := checkNumIn(inputs, 0); err != nil {
 			return nil, nil, err
 		}
+		if core.TableReader.LockingStrength != sqlbase.ScanLockingStrength_FOR_NONE {
+			return nil, nil, errors.Newf("row-level locking is not supported by the vectorized engine")
+		}
 		op, err = newColBatchScan(flowCtx, core.TableReader, post)
 		// We want to check for cancellation once per input batch, and wrapping
 		// only colBatchScan with an exec.CancelChecker allows us to do just that.
@@ -197,9 +286,18 @@ func newColOperator(
 			columnTypes[i] = *retType
 		}
 		if needHash {
-			op, err = exec.NewHashAggregator(
-				inputs[0], conv.FromColumnTypes(spec.Input[0].ColumnTypes), aggFns, aggSpec.GroupCols, aggCols,
-			)
+			inputTypes := conv.FromColumnTypes(spec.Input[0].ColumnTypes)
+			if cfg, ok := resources.spillingConfig(
+				ctx, flowCtx, "hashaggregator", settingUseTempStorageAggregations.Get(&flowCtx.Settings.SV), inputTypes,
+			); ok {
+				op, err = exec.NewExternalHashAggregator(
+					inputs[0], inputTypes, aggFns, aggSpec.GroupCols, aggCols, cfg,
+				)
+			} else {
+				op, err = exec.NewHashAggregator(
+					inputs[0], inputTypes, aggFns, aggSpec.GroupCols, aggCols,
+				)
+			}
 		} else {
 			op, err = exec.NewOrderedAggregator(
 				inputs[0], conv.FromColumnTypes(spec.Input[0].ColumnTypes), aggFns, aggSpec.GroupCols, aggCols,
@@ -277,19 +375,38 @@ func newColOperator(
 			}
 		}
 
-		op, err = exec.NewEqHashJoinerOp(
-			inputs[0],
-			inputs[1],
-			core.HashJoiner.LeftEqColumns,
-			core.HashJoiner.RightEqColumns,
-			leftOutCols,
-			rightOutCols,
-			leftTypes,
-			rightTypes,
-			core.HashJoiner.RightEqColumnsAreKey,
-			core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
-			core.HashJoiner.Type,
-		)
+		if cfg, ok := resources.spillingConfig(
+			ctx, flowCtx, "hashjoiner", settingUseTempStorageJoins.Get(&flowCtx.Settings.SV), leftTypes, rightTypes,
+		); ok {
+			op, err = exec.NewExternalEqHashJoinerOp(
+				inputs[0],
+				inputs[1],
+				core.HashJoiner.LeftEqColumns,
+				core.HashJoiner.RightEqColumns,
+				leftOutCols,
+				rightOutCols,
+				leftTypes,
+				rightTypes,
+				core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.Type,
+				cfg,
+			)
+		} else {
+			op, err = exec.NewEqHashJoinerOp(
+				inputs[0],
+				inputs[1],
+				core.HashJoiner.LeftEqColumns,
+				core.HashJoiner.RightEqColumns,
+				leftOutCols,
+				rightOutCols,
+				leftTypes,
+				rightTypes,
+				core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.LeftEqColumnsAreKey || core.HashJoiner.RightEqColumnsAreKey,
+				core.HashJoiner.Type,
+			)
+		}
 
 	case core.MergeJoiner != nil:
 		if err := checkNumIn(inputs, 2); err != nil {
@@ -395,6 +512,12 @@ func newColOperator(
 			// which uses a heap to avoid storing more rows than necessary.
 			k := uint16(post.Limit + post.Offset)
 			op = exec.NewTopKSorter(input, inputTypes, orderingCols, k)
+		} else if cfg, ok := resources.spillingConfig(
+			ctx, flowCtx, "sorter", settingUseTempStorageSorts.Get(&flowCtx.Settings.SV), inputTypes,
+		); ok {
+			// No optimizations possible, and the sort may not fit in memory. Use
+			// an external sorter, which falls back to a merge sort on disk.
+			op, err = exec.NewExternalSorter(input, inputTypes, orderingCols, cfg)
 		} else {
 			// No optimizations possible. Default to the standard sort operator.
 			op, err = exec.NewSorter(input, inputTypes, orderingCols)
@@ -409,11 +532,17 @@ func newColOperator(
 			return nil, nil, errors.Newf("only a single window function is currently supported")
 		}
 		wf := core.Windower.WindowFns[0]
-		if wf.Frame != nil {
-			return nil, nil, errors.Newf("window functions with window frames are not supported")
+		// FilterColIdx is -1 when there is no FILTER clause.
+		if wf.FilterColIdx != -1 {
+			return nil, nil, errors.Newf("window functions with FILTER clause are not supported")
 		}
-		if wf.Func.AggregateFunc != nil {
-			return nil, nil, errors.Newf("aggregate functions used as window functions are not supported")
+		argTypes := make([]semtypes.T, len(wf.ArgsIdxs))
+		for i, idx := range wf.ArgsIdxs {
+			argTypes[i] = spec.Input[0].ColumnTypes[idx]
+		}
+		var outputType *semtypes.T
+		if _, outputType, err = GetWindowFunctionInfo(wf.Func, argTypes...); err != nil {
+			return nil, nil, err
 		}
 
 		input := inputs[0]
@@ -439,15 +568,41 @@ func newColOperator(
 			return nil, nil, err
 		}
 
-		switch *wf.Func.WindowFunc {
-		case distsqlpb.WindowerSpec_ROW_NUMBER:
-			op = vecbuiltins.NewRowNumberOperator(input, int(wf.OutputColIdx)+tempPartitionColOffset, partitionColIdx)
-		case distsqlpb.WindowerSpec_RANK:
-			op, err = vecbuiltins.NewRankOperator(input, typs, false /* dense */, orderingCols, int(wf.OutputColIdx)+tempPartitionColOffset, partitionColIdx)
-		case distsqlpb.WindowerSpec_DENSE_RANK:
-			op, err = vecbuiltins.NewRankOperator(input, typs, true /* dense */, orderingCols, int(wf.OutputColIdx)+tempPartitionColOffset, partitionColIdx)
-		default:
-			return nil, nil, errors.Newf("window function %s is not supported", wf.String())
+		outputColIdx := int(wf.OutputColIdx) + tempPartitionColOffset
+		// The window functions that buffer whole partitions fall back to the row
+		// windower, which can spill to disk, once a partition doesn't fit in
+		// memory.
+		bufferingCfg := vecbuiltins.BufferingConfig{
+			MemAcc: resources.limitedMemAccount(ctx, flowCtx, "windower"),
+			Fallback: func(input exec.Operator) (exec.Operator, error) {
+				return wrapRowSource(flowCtx, input, spec.Input[0].ColumnTypes, func(input RowSource) (RowSource, error) {
+					return newWindower(flowCtx, spec.ProcessorID, core.Windower, input, &distsqlpb.PostProcessSpec{}, nil /* output */)
+				})
+			},
+		}
+		if wf.Func.AggregateFunc != nil {
+			op, err = vecbuiltins.NewWindowAggregateOperator(input, typs, orderingCols, *wf.Func.AggregateFunc, wf.ArgsIdxs, wf.Frame, outputColIdx, partitionColIdx, bufferingCfg)
+		} else {
+			switch *wf.Func.WindowFunc {
+			case distsqlpb.WindowerSpec_ROW_NUMBER:
+				op = vecbuiltins.NewRowNumberOperator(input, outputColIdx, partitionColIdx)
+			case distsqlpb.WindowerSpec_RANK:
+				op, err = vecbuiltins.NewRankOperator(input, typs, false /* dense */, orderingCols, outputColIdx, partitionColIdx)
+			case distsqlpb.WindowerSpec_DENSE_RANK:
+				op, err = vecbuiltins.NewRankOperator(input, typs, true /* dense */, orderingCols, outputColIdx, partitionColIdx)
+			case distsqlpb.WindowerSpec_PERCENT_RANK:
+				op, err = vecbuiltins.NewPercentRankOperator(input, typs, orderingCols, outputColIdx, partitionColIdx, bufferingCfg)
+			case distsqlpb.WindowerSpec_CUME_DIST:
+				op, err = vecbuiltins.NewCumeDistOperator(input, typs, orderingCols, outputColIdx, partitionColIdx, bufferingCfg)
+			case distsqlpb.WindowerSpec_NTILE:
+				op, err = vecbuiltins.NewNTileOperator(input, typs, orderingCols, int(wf.ArgsIdxs[0]), outputColIdx, partitionColIdx, bufferingCfg)
+			case distsqlpb.WindowerSpec_LAG:
+				op, err = vecbuiltins.NewLeadLagOperator(input, typs, orderingCols, false /* forward */, wf.ArgsIdxs, outputColIdx, partitionColIdx, bufferingCfg)
+			case distsqlpb.WindowerSpec_LEAD:
+				op, err = vecbuiltins.NewLeadLagOperator(input, typs, orderingCols, true /* forward */, wf.ArgsIdxs, outputColIdx, partitionColIdx, bufferingCfg)
+			default:
+				return nil, nil, errors.Newf("window function %s is not supported", wf.String())
+			}
 		}
 
 		if partitionColIdx != -1 {
@@ -461,7 +616,7 @@ func newColOperator(
 			op = exec.NewSimpleProjectOp(op, projection)
 		}
 
-		columnTypes = append(spec.Input[0].ColumnTypes, *semtypes.Int)
+		columnTypes = append(spec.Input[0].ColumnTypes, *outputType)
 
 	default:
 		return nil, nil, errors.Newf("unsupported processor core %s", core)
@@ -471,6 +626,9 @@ func newColOperator(
 	if err != nil {
 		return nil, nil, err
 	}
+	if c, ok := op.(exec.Closer); ok {
+		resources.closers = append(resources.closers, c)
+	}
 
 	if columnTypes == nil {
 		return nil, nil, errors.AssertionFailedf("output columnTypes unset after planning %T", op)
@@ -594,9 +752,9 @@ func planProjectionOperators(
 	case *tree.IndexedVar:
 		return input, t.Idx, columnTypes, nil
 	case *tree.ComparisonExpr:
-		return planProjectionExpr(ctx, t.Operator, t.TypedLeft(), t.TypedRight(), columnTypes, input)
+		return planProjectionExpr(ctx, t.Operator, t.ResolvedType(), t.TypedLeft(), t.TypedRight(), columnTypes, input)
 	case *tree.BinaryExpr:
-		return planProjectionExpr(ctx, t.Operator, t.TypedLeft(), t.TypedRight(), columnTypes, input)
+		return planProjectionExpr(ctx, t.Operator, t.ResolvedType(), t.TypedLeft(), t.TypedRight(), columnTypes, input)
 	case tree.Datum:
 		datumType := t.ResolvedType()
 		ct := columnTypes
@@ -623,6 +781,7 @@ func planProjectionOperators(
 func planProjectionExpr(
 	ctx *tree.EvalContext,
 	binOp tree.Operator,
+	outputType *semtypes.T,
 	left, right tree.TypedExpr,
 	columnTypes []semtypes.T,
 	input exec.Operator,
@@ -644,10 +803,14 @@ func planProjectionExpr(
 		}
 		resultIdx = len(ct)
 		typ := &ct[rightIdx]
+		constType := projectionConstType(lConstArg, typ)
+		if err := checkProjectionTypes(ctx, constType, typ); err != nil {
+			return nil, resultIdx, ct, err
+		}
 		// The projection result will be outputted to a new column which is appended
 		// to the input batch.
-		op, err = exec.GetProjectionLConstOperator(typ, binOp, rightOp, rightIdx, lConstArg, resultIdx)
-		ct = append(ct, *typ)
+		op, err = exec.GetProjectionLConstOperator(constType, typ, binOp, rightOp, rightIdx, lConstArg, resultIdx)
+		ct = append(ct, *projectionOutputType(outputType, typ))
 		return op, resultIdx, ct, err
 	}
 	leftOp, leftIdx, ct, err := planProjectionOperators(ctx, left, columnTypes, input)
@@ -660,8 +823,12 @@ func planProjectionExpr(
 		// The projection result will be outputted to a new column which is appended
 		// to the input batch.
 		resultIdx = len(ct)
-		op, err = exec.GetProjectionRConstOperator(typ, binOp, leftOp, leftIdx, rConstArg, resultIdx)
-		ct = append(ct, *typ)
+		constType := projectionConstType(rConstArg, typ)
+		if err := checkProjectionTypes(ctx, typ, constType); err != nil {
+			return nil, resultIdx, ct, err
+		}
+		op, err = exec.GetProjectionRConstOperator(typ, constType, binOp, leftOp, leftIdx, rConstArg, resultIdx)
+		ct = append(ct, *projectionOutputType(outputType, typ))
 		return op, resultIdx, ct, err
 	}
 	// Case 3: neither are constant.
@@ -669,18 +836,67 @@ func planProjectionExpr(
 	if err != nil {
 		return nil, resultIdx, nil, err
 	}
-	if !ct[leftIdx].Identical(&ct[rightIdx]) {
-		err = errors.Errorf(
-			"projection on %s and %s is unhandled", ct[leftIdx].Family(),
-			ct[rightIdx].Family())
+	// ct may have been reallocated while planning the right side.
+	typ = &ct[leftIdx]
+	if err := checkProjectionTypes(ctx, typ, &ct[rightIdx]); err != nil {
 		return nil, resultIdx, ct, err
 	}
 	resultIdx = len(ct)
-	op, err = exec.GetProjectionOperator(typ, binOp, rightOp, leftIdx, rightIdx, resultIdx)
-	ct = append(ct, *typ)
+	op, err = exec.GetProjectionOperator(typ, &ct[rightIdx], binOp, rightOp, leftIdx, rightIdx, resultIdx)
+	ct = append(ct, *projectionOutputType(outputType, typ))
 	return op, resultIdx, ct, err
 }
 
+// projectionConstType returns the type with which the constant argument of a
+// projection is converted to its physical representation. A constant of the
+// same type family as the column it is combined with is converted with the
+// column's type, so that, for example, an INT8 constant can be added to an INT4
+// column.
+func projectionConstType(constArg tree.Datum, colType *semtypes.T) *semtypes.T {
+	if constType := constArg.ResolvedType(); constType.Family() != colType.Family() {
+		return constType
+	}
+	return colType
+}
+
+// checkProjectionTypes returns an error if the vectorized engine can't evaluate
+// a projection on arguments of the given types.
+func checkProjectionTypes(ctx *tree.EvalContext, left, right *semtypes.T) error {
+	lFamily, rFamily := left.Family(), right.Family()
+	switch {
+	case lFamily == rFamily:
+		if !left.Identical(right) {
+			return errors.Errorf("projection on %s and %s is unhandled", left, right)
+		}
+	case conv.FromColumnType(left) == conv.FromColumnType(right):
+		// The arguments share a physical representation, but can't be combined
+		// without a conversion; for example, TIMESTAMP and TIMESTAMPTZ.
+		return errors.Errorf("projection on %s and %s is unhandled", lFamily, rFamily)
+	case lFamily == semtypes.IntervalFamily || rFamily == semtypes.IntervalFamily:
+		// The projection operators on timestamps and intervals always use the
+		// default duration addition mode.
+		if ctx.GetAdditionMode() != duration.AdditionModeCompatible {
+			return errors.Errorf(
+				"projection on %s and %s is unhandled with duration addition mode %s",
+				lFamily, rFamily, ctx.GetAdditionMode())
+		}
+	}
+	return nil
+}
+
+// projectionOutputType returns the type of the column produced by a projection
+// whose column argument is of type colType, given the type of the projected
+// expression. The column argument's type is preserved when it has the same
+// physical representation as the result, since the operators produce a column
+// of that representation (an INT4 column plus an INT8 constant produces an INT4
+// column).
+func projectionOutputType(outputType, colType *semtypes.T) *semtypes.T {
+	if conv.FromColumnType(outputType) == conv.FromColumnType(colType) {
+		return colType
+	}
+	return outputType
+}
+
 // wrapWithVectorizedStatsCollector creates a new exec.VectorizedStatsCollector
 // that wraps op and connects the newly created wrapper with those
 // corresponding to operators in inputs (the latter must have already been
@@ -1009,7 +1225,7 @@ func (f *Flow) setupVectorized(ctx context.Context) error {
 			inputs = append(inputs, synchronizer)
 		}
 
-		op, outputTypes, err := newColOperator(ctx, &f.FlowCtx, pspec, inputs)
+		op, outputTypes, err := newColOperator(ctx, &f.FlowCtx, pspec, inputs, &f.vectorizedResources)
 		if err != nil {
 			return err
 		}
@@ -180,6 +180,136 @@ func TestMergeJoinerAgainstProcessor(t *testing.T) {
 	}
 }
 
+func TestWindowFunctionsAgainstProcessor(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	rng, _ := randutil.NewPseudoRand()
+
+	nRows := 100
+	maxNum := 10
+	// TODO(yuzefovich): change nullProbability to non 0 value once ordered
+	// distinct handles nulls.
+	nullProbability := 0.0
+	// The first column is the partitioning column, the second is the argument
+	// of the window functions and the last one contains positive integers for
+	// the number of buckets of ntile and the offsets of lag and lead.
+	inputTypes := []types.T{*types.Int, *types.Int, *types.Int}
+	windowFnMaker := func(windowFn distsqlpb.WindowerSpec_WindowFunc) distsqlpb.WindowerSpec_Func {
+		return distsqlpb.WindowerSpec_Func{WindowFunc: &windowFn}
+	}
+	aggFnMaker := func(aggFn distsqlpb.AggregatorSpec_Func) distsqlpb.WindowerSpec_Func {
+		return distsqlpb.WindowerSpec_Func{AggregateFunc: &aggFn}
+	}
+	type fnSpec struct {
+		fn       distsqlpb.WindowerSpec_Func
+		argsIdxs []uint32
+	}
+	fns := []fnSpec{
+		{fn: windowFnMaker(distsqlpb.WindowerSpec_PERCENT_RANK)},
+		{fn: windowFnMaker(distsqlpb.WindowerSpec_CUME_DIST)},
+		{fn: windowFnMaker(distsqlpb.WindowerSpec_NTILE), argsIdxs: []uint32{2}},
+		{fn: windowFnMaker(distsqlpb.WindowerSpec_LAG), argsIdxs: []uint32{1}},
+		{fn: windowFnMaker(distsqlpb.WindowerSpec_LEAD), argsIdxs: []uint32{1, 2}},
+		{fn: windowFnMaker(distsqlpb.WindowerSpec_LAG), argsIdxs: []uint32{1, 2, 0}},
+	}
+	for _, aggFn := range []distsqlpb.AggregatorSpec_Func{
+		distsqlpb.AggregatorSpec_COUNT,
+		distsqlpb.AggregatorSpec_SUM,
+		distsqlpb.AggregatorSpec_AVG,
+		distsqlpb.AggregatorSpec_MIN,
+		distsqlpb.AggregatorSpec_MAX,
+	} {
+		fns = append(fns, fnSpec{fn: aggFnMaker(aggFn), argsIdxs: []uint32{1}})
+	}
+	fns = append(fns, fnSpec{fn: aggFnMaker(distsqlpb.AggregatorSpec_COUNT_ROWS)})
+	offsetBound := func(
+		boundType distsqlpb.WindowerSpec_Frame_BoundType, offset uint64,
+	) distsqlpb.WindowerSpec_Frame_Bound {
+		return distsqlpb.WindowerSpec_Frame_Bound{BoundType: boundType, IntOffset: offset}
+	}
+	frames := []*distsqlpb.WindowerSpec_Frame{
+		nil,
+		{
+			Mode: distsqlpb.WindowerSpec_Frame_ROWS,
+			Bounds: distsqlpb.WindowerSpec_Frame_Bounds{
+				Start: offsetBound(distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING, 2),
+				End:   &distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_CURRENT_ROW},
+			},
+		},
+		{
+			Mode: distsqlpb.WindowerSpec_Frame_ROWS,
+			Bounds: distsqlpb.WindowerSpec_Frame_Bounds{
+				Start: offsetBound(distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING, 1),
+				End:   &distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING},
+			},
+		},
+		{
+			Mode: distsqlpb.WindowerSpec_Frame_RANGE,
+			Bounds: distsqlpb.WindowerSpec_Frame_Bounds{
+				Start: distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_CURRENT_ROW},
+				End:   &distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING},
+			},
+		},
+		{
+			Mode: distsqlpb.WindowerSpec_Frame_GROUPS,
+			Bounds: distsqlpb.WindowerSpec_Frame_Bounds{
+				Start: offsetBound(distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING, 1),
+				End:   &distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING},
+			},
+		},
+	}
+
+	for _, partitionBy := range [][]uint32{{}, {0}} {
+		for _, fn := range fns {
+			fnFrames := frames[:1]
+			if fn.fn.AggregateFunc != nil {
+				fnFrames = frames
+			}
+			for _, frame := range fnFrames {
+				rows := sqlbase.MakeRandIntRowsInRange(rng, nRows, len(inputTypes), maxNum, nullProbability)
+				for _, row := range rows {
+					row[2] = sqlbase.DatumToEncDatum(types.Int, tree.NewDInt(tree.DInt(1+rng.Intn(maxNum))))
+				}
+				// Note: the window functions are ordered on all columns since the
+				// results of some of them depend on the order of the peers, so they
+				// are deterministic only when all peers are equal.
+				windowerSpec := &distsqlpb.WindowerSpec{
+					PartitionBy: partitionBy,
+					WindowFns: []distsqlpb.WindowerSpec_WindowFn{{
+						Func:         fn.fn,
+						ArgsIdxs:     fn.argsIdxs,
+						Ordering:     distsqlpb.Ordering{Columns: generateColumnOrdering(rng, len(inputTypes), len(inputTypes))},
+						Frame:        frame,
+						FilterColIdx: -1,
+						OutputColIdx: uint32(len(inputTypes)),
+					}},
+				}
+				argTypes := make([]types.T, len(fn.argsIdxs))
+				for i, idx := range fn.argsIdxs {
+					argTypes[i] = inputTypes[idx]
+				}
+				_, outputType, err := GetWindowFunctionInfo(fn.fn, argTypes...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				pspec := &distsqlpb.ProcessorSpec{
+					Input: []distsqlpb.InputSyncSpec{{ColumnTypes: inputTypes}},
+					Core:  distsqlpb.ProcessorCoreUnion{Windower: windowerSpec},
+				}
+				outputTypes := append(inputTypes[:len(inputTypes):len(inputTypes)], *outputType)
+				// The memory limits make the columnar operator fall back to the
+				// processor either right away or after a few partitions.
+				for _, memoryLimit := range []int64{0, 1, 200} {
+					if err := verifyColOperatorWithMemoryLimit(
+						true /* anyOrder */, [][]types.T{inputTypes}, []sqlbase.EncDatumRows{rows}, outputTypes, pspec, memoryLimit,
+					); err != nil {
+						t.Fatalf("%s with frame %v and memory limit %d: %v", fn.fn.String(), frame, memoryLimit, err)
+					}
+				}
+			}
+		}
+	}
+}
+
 // generateColumnOrdering produces a random ordering of nOrderingCols columns
 // on a table with nCols columns, so nOrderingCols must be not greater than
 // nCols
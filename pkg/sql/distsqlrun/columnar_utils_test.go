@@ -35,6 +35,20 @@ func verifyColOperator(
 	inputs []sqlbase.EncDatumRows,
 	outputTypes []types.T,
 	pspec *distsqlpb.ProcessorSpec,
+) error {
+	return verifyColOperatorWithMemoryLimit(anyOrder, inputTypes, inputs, outputTypes, pspec, 0 /* memoryLimit */)
+}
+
+// verifyColOperatorWithMemoryLimit is like verifyColOperator, but limits the
+// memory available to the columnar operator to memoryLimit bytes if it is
+// positive.
+func verifyColOperatorWithMemoryLimit(
+	anyOrder bool,
+	inputTypes [][]types.T,
+	inputs []sqlbase.EncDatumRows,
+	outputTypes []types.T,
+	pspec *distsqlpb.ProcessorSpec,
+	memoryLimit int64,
 ) error {
 	ctx := context.Background()
 	st := cluster.MakeTestingClusterSettings()
@@ -54,6 +68,7 @@ func verifyColOperator(
 		TempStorage: tempEngine,
 		diskMonitor: diskMonitor,
 	}
+	flowCtx.testingKnobs.MemoryLimitBytes = memoryLimit
 
 	inputsProc := make([]RowSource, len(inputs))
 	inputsColOp := make([]RowSource, len(inputs))
@@ -80,7 +95,9 @@ func verifyColOperator(
 		columnarizers[i] = c
 	}
 
-	colOp, _, err := newColOperator(ctx, flowCtx, pspec, columnarizers)
+	var resources vectorizedFlowResources
+	defer resources.close(ctx)
+	colOp, _, err := newColOperator(ctx, flowCtx, pspec, columnarizers, &resources)
 	if err != nil {
 		return err
 	}
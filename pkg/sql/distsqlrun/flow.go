@@ -198,6 +198,10 @@ type Flow struct {
 
 	// spec is the request that produced this flow. Only used for debugging.
 	spec *distsqlpb.FlowSpec
+
+	// vectorizedResources contains the resources used by the operators of a
+	// vectorized flow that spill to disk.
+	vectorizedResources vectorizedFlowResources
 }
 
 func newFlow(
@@ -516,6 +520,7 @@ func (f *Flow) setup(ctx context.Context, spec *distsqlpb.FlowSpec) error {
 			return &VectorizedSetupError{cause: err}
 		}
 		// Reset state to be used by the row execution branch.
+		f.vectorizedResources.close(ctx)
 		f.processors = nil
 		f.inboundStreams = nil
 		f.startables = nil
@@ -691,6 +696,9 @@ func (f *Flow) Cleanup(ctx context.Context) {
 	if f.status == FlowFinished {
 		panic("flow cleanup called twice")
 	}
+	// The monitors of the vectorized operators are children of the monitor
+	// opened in ServerImpl.setupFlow, so they need to be stopped first.
+	f.vectorizedResources.close(ctx)
 	// This closes the monitor opened in ServerImpl.setupFlow.
 	f.EvalCtx.Stop(ctx)
 	for _, p := range f.processors {
@@ -49,6 +49,10 @@ type indexJoiner struct {
 	spans roachpb.Spans
 
 	alloc sqlbase.DatumAlloc
+
+	// lockedRows is set if the rows emitted by the indexJoiner need to be
+	// locked, see JoinReaderSpec.LockingStrength.
+	lockedRows *lockingRowBuffer
 }
 
 var _ Processor = &indexJoiner{}
@@ -93,14 +97,26 @@ func newIndexJoiner(
 	); err != nil {
 		return nil, err
 	}
+	columnIdxMap := ij.desc.ColumnIdxMapWithMutations(needMutations)
+	neededColumns := ij.out.neededColumns()
+	var err error
+	if ij.lockedRows, err = newLockingRowBuffer(
+		spec.LockingStrength, &ij.desc, columnIdxMap, &ij.alloc, flowCtx.traceKV,
+	); err != nil {
+		return nil, err
+	}
+	if ij.lockedRows != nil {
+		addPrimaryKeyColumns(&neededColumns, &ij.desc, columnIdxMap)
+	}
+
 	var fetcher row.Fetcher
-	if _, _, err := initRowFetcher(
+	if _, _, err = initRowFetcher(
 		&fetcher,
 		&ij.desc,
 		0, /* primary index */
-		ij.desc.ColumnIdxMapWithMutations(needMutations),
+		columnIdxMap,
 		false, /* reverse */
-		ij.out.neededColumns(),
+		neededColumns,
 		false, /* isCheck */
 		&ij.alloc,
 		spec.Visibility,
@@ -130,6 +146,15 @@ func (ij *indexJoiner) Start(ctx context.Context) context.Context {
 // Next is part of the RowSource interface.
 func (ij *indexJoiner) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata) {
 	for ij.State == StateRunning {
+		if ij.lockedRows != nil {
+			if outRow := ij.lockedRows.next(); outRow != nil {
+				return outRow, nil
+			}
+			if ij.lockedRows.done() {
+				ij.MoveToDraining(nil /* err */)
+				break
+			}
+		}
 		if !ij.fetcherReady {
 			// Retrieve a batch of rows from the input.
 			for len(ij.spans) < ij.batchSize {
@@ -152,6 +177,14 @@ func (ij *indexJoiner) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 			}
 			if len(ij.spans) == 0 {
 				// All done.
+				if ij.lockedRows != nil {
+					// The pending rows must be locked before moving to draining,
+					// which closes the indexJoiner.
+					if err := ij.lockedRows.finishInput(&ij.ProcessorBase); err != nil {
+						ij.MoveToDraining(err)
+					}
+					continue
+				}
 				ij.MoveToDraining(nil /* err */)
 				return nil, ij.DrainHelper()
 			}
@@ -174,6 +207,10 @@ func (ij *indexJoiner) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 		if row == nil {
 			// Done with this batch.
 			ij.fetcherReady = false
+		} else if ij.lockedRows != nil {
+			if err := ij.lockedRows.processRow(&ij.ProcessorBase, row, row); err != nil {
+				ij.MoveToDraining(err)
+			}
 		} else if outRow := ij.ProcessRowHelper(row); outRow != nil {
 			return outRow, nil
 		}
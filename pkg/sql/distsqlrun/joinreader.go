@@ -99,6 +99,10 @@ type joinReader struct {
 	// A few scratch buffers, to avoid re-allocating.
 	lookupRows  []lookupRow
 	indexKeyRow sqlbase.EncDatumRow
+
+	// lockedRows is set if the looked up rows need to be locked, see
+	// JoinReaderSpec.LockingStrength.
+	lockedRows *lockingRowBuffer
 }
 
 // lookupRow represents an index key and the corresponding index row.
@@ -178,6 +182,14 @@ func newJoinReader(
 	if err := jr.indexFilter.init(spec.IndexFilterExpr, columnTypes, jr.evalCtx); err != nil {
 		return nil, err
 	}
+	if jr.lockedRows, err = newLockingRowBuffer(
+		spec.LockingStrength, &jr.desc, jr.colIdxMap, &jr.alloc, flowCtx.traceKV,
+	); err != nil {
+		return nil, err
+	}
+	if jr.lockedRows != nil && jr.joinType != sqlbase.InnerJoin && jr.joinType != sqlbase.LeftOuterJoin {
+		return nil, errors.AssertionFailedf("cannot lock rows in a %s lookup join", jr.joinType)
+	}
 
 	collectingStats := false
 	if sp := opentracing.SpanFromContext(flowCtx.EvalCtx.Ctx()); sp != nil && tracing.IsRecording(sp) {
@@ -253,6 +265,11 @@ func (jr *joinReader) neededRightCols() util.FastIntSet {
 		neededRightCols.Add(v.Idx)
 	}
 
+	// Add the primary key columns needed to lock the looked up rows.
+	if jr.lockedRows != nil {
+		addPrimaryKeyColumns(&neededRightCols, &jr.desc, jr.colIdxMap)
+	}
+
 	return neededRightCols
 }
 
@@ -288,6 +305,15 @@ func (jr *joinReader) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 	// - Join the index rows with the corresponding input rows and buffer the
 	//   results in jr.toEmit.
 	for jr.State == StateRunning {
+		if jr.lockedRows != nil {
+			if outRow := jr.lockedRows.next(); outRow != nil {
+				return outRow, nil
+			}
+			if jr.lockedRows.done() {
+				jr.MoveToDraining(nil /* err */)
+				break
+			}
+		}
 		var row sqlbase.EncDatumRow
 		var meta *distsqlpb.ProducerMetadata
 		switch jr.runningState {
@@ -308,6 +334,15 @@ func (jr *joinReader) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 		if meta != nil {
 			return nil, meta
 		}
+		if jr.lockedRows != nil {
+			// The looked up columns follow the input columns in the joined row.
+			if err := jr.lockedRows.processRow(
+				&jr.ProcessorBase, row[len(jr.inputTypes):], row,
+			); err != nil {
+				jr.MoveToDraining(err)
+			}
+			continue
+		}
 		if outRow := jr.ProcessRowHelper(row); outRow != nil {
 			return outRow, nil
 		}
@@ -335,6 +370,15 @@ func (jr *joinReader) readInput() (joinReaderState, *distsqlpb.ProducerMetadata)
 
 	if len(jr.inputRows) == 0 {
 		// We're done.
+		if jr.lockedRows != nil {
+			// The pending rows must be locked before moving to draining, which
+			// closes the joinReader.
+			if err := jr.lockedRows.finishInput(&jr.ProcessorBase); err != nil {
+				jr.MoveToDraining(err)
+				return jrStateUnknown, jr.DrainHelper()
+			}
+			return jrReadingInput, nil
+		}
 		jr.MoveToDraining(nil)
 		return jrStateUnknown, jr.DrainHelper()
 	}
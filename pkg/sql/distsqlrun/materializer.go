@@ -22,6 +22,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/lib/pq/oid"
 )
 
@@ -177,6 +178,18 @@ func (m *materializer) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 				m.row[outIdx].Datum = m.da.NewDBytes(tree.DBytes(col.Bytes()[rowIdx]))
 			case types.OidFamily:
 				m.row[outIdx].Datum = m.da.NewDOid(tree.MakeDOid(tree.DInt(col.Int64()[rowIdx])))
+			case types.TimestampFamily:
+				m.row[outIdx].Datum = m.da.NewDTimestamp(tree.DTimestamp{Time: col.Timestamp()[rowIdx]})
+			case types.TimestampTZFamily:
+				m.row[outIdx].Datum = m.da.NewDTimestampTZ(tree.DTimestampTZ{Time: col.Timestamp()[rowIdx]})
+			case types.IntervalFamily:
+				m.row[outIdx].Datum = m.da.NewDInterval(tree.DInterval{Duration: col.Interval()[rowIdx]})
+			case types.UuidFamily:
+				id, err := uuid.FromBytes(col.Bytes()[rowIdx])
+				if err != nil {
+					panic(err)
+				}
+				m.row[outIdx].Datum = m.da.NewDUuid(tree.DUuid{UUID: id})
 			default:
 				panic(fmt.Sprintf("Unsupported column type %s", ct.String()))
 			}
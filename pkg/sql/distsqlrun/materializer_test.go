@@ -13,6 +13,7 @@ package distsqlrun
 import (
 	"context"
 	"testing"
+	"time"
 	"unsafe"
 
 	"github.com/cockroachdb/apd"
@@ -21,8 +22,12 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 	"github.com/cockroachdb/cockroach/pkg/util/timeutil/pgdate"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 )
 
 func TestColumnarizeMaterialize(t *testing.T) {
@@ -101,6 +106,10 @@ func TestMaterializeTypes(t *testing.T) {
 		*types.Bytes,
 		*types.Name,
 		*types.Oid,
+		*types.Timestamp,
+		*types.TimestampTZ,
+		*types.Interval,
+		*types.Uuid,
 	}
 	inputRow := sqlbase.EncDatumRow{
 		sqlbase.EncDatum{Datum: tree.DBoolTrue},
@@ -112,6 +121,10 @@ func TestMaterializeTypes(t *testing.T) {
 		sqlbase.EncDatum{Datum: tree.NewDBytes("ciao")},
 		sqlbase.EncDatum{Datum: tree.NewDName("aloha")},
 		sqlbase.EncDatum{Datum: tree.NewDOid(59)},
+		sqlbase.EncDatum{Datum: tree.MakeDTimestamp(timeutil.Unix(61, 0), time.Microsecond)},
+		sqlbase.EncDatum{Datum: tree.MakeDTimestampTZ(timeutil.Unix(67, 0), time.Microsecond)},
+		sqlbase.EncDatum{Datum: &tree.DInterval{Duration: duration.MakeDuration(71, 73, 79)}},
+		sqlbase.EncDatum{Datum: tree.NewDUuid(tree.DUuid{UUID: uuid.FromUint128(uint128.FromInts(83, 89))})},
 	}
 	input := NewRepeatableRowSource(types, sqlbase.EncDatumRows{inputRow})
 
@@ -0,0 +1,157 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package distsqlrun
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/row"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/errors"
+)
+
+// rowLockingBatchSize is the maximum number of rows whose locks are acquired
+// together by a lockingRowBuffer. Not a constant so we can lower it for
+// testing.
+var rowLockingBatchSize = 100
+
+// lockingRowBuffer acquires row-level locks on the rows emitted by a processor
+// that reads from a table with a FOR UPDATE locking strength.
+//
+// Rows are locked only once they have passed the processor's ON condition and
+// post-processing stage, so that rows that are filtered out are not locked.
+// To amortize the cost of acquiring the locks, the output rows are buffered
+// until rowLockingBatchSize of them have been collected or the processor does
+// not need any more input rows. The buffered rows are then locked with a single
+// batch and emitted. No row is emitted before its lock has been acquired.
+type lockingRowBuffer struct {
+	locker row.Locker
+
+	// pending are the output rows whose locks are queued up in locker but have
+	// not yet been acquired.
+	pending []sqlbase.EncDatumRow
+	// locked are the output rows whose locks have been acquired, which can be
+	// emitted starting at lockedIdx.
+	locked    []sqlbase.EncDatumRow
+	lockedIdx int
+	// inputDone is set once the processor does not need any more input rows.
+	inputDone bool
+
+	rowAlloc sqlbase.EncDatumRowAlloc
+}
+
+// newLockingRowBuffer returns a lockingRowBuffer if the given locking strength
+// requires rows to be locked, or nil otherwise. See row.Locker.Init for a
+// description of the other parameters.
+func newLockingRowBuffer(
+	lockStr sqlbase.ScanLockingStrength,
+	desc *sqlbase.TableDescriptor,
+	colIdxMap map[sqlbase.ColumnID]int,
+	alloc *sqlbase.DatumAlloc,
+	traceKV bool,
+) (*lockingRowBuffer, error) {
+	switch {
+	case lockStr == sqlbase.ScanLockingStrength_FOR_NONE:
+		return nil, nil
+	case !lockStr.IsExclusive():
+		return nil, errors.AssertionFailedf("unsupported locking strength %s", lockStr)
+	}
+	b := &lockingRowBuffer{}
+	if err := b.locker.Init(desc, colIdxMap, alloc, traceKV); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// addPrimaryKeyColumns adds the ordinals of the table's primary key columns,
+// which are needed to lock the rows, to the given set of needed columns.
+func addPrimaryKeyColumns(
+	neededColumns *util.FastIntSet,
+	desc *sqlbase.TableDescriptor,
+	colIdxMap map[sqlbase.ColumnID]int,
+) {
+	for _, colID := range desc.PrimaryIndex.ColumnIDs {
+		neededColumns.Add(colIdxMap[colID])
+	}
+}
+
+// processRow passes a row through the processor's post-processing stage and,
+// if it produces an output row, queues up the lock on the table row from which
+// it was derived. It is used in place of ProcessRowHelper by processors that
+// lock rows. The output row is copied and buffered until its lock has been
+// acquired, which happens once enough rows are pending or once the processor
+// does not need any more input rows.
+func (b *lockingRowBuffer) processRow(
+	pb *ProcessorBase, tableRow, row sqlbase.EncDatumRow,
+) error {
+	outRow, ok, err := pb.out.ProcessRow(pb.Ctx, row)
+	if err != nil {
+		return err
+	}
+	if outRow != nil {
+		if err := b.locker.AddRow(pb.Ctx, tableRow); err != nil {
+			return err
+		}
+		b.pending = append(b.pending, b.rowAlloc.CopyRow(outRow))
+	}
+	if !ok {
+		return b.finishInput(pb)
+	}
+	if len(b.pending) >= rowLockingBatchSize {
+		return b.flush(pb)
+	}
+	return nil
+}
+
+// finishInput is called once the processor does not need any more input rows,
+// either because its input has been exhausted or because its output does not
+// need any more rows. It acquires the locks on the pending rows. The processor
+// must keep emitting rows returned by next until done returns true, and only
+// then move to draining.
+func (b *lockingRowBuffer) finishInput(pb *ProcessorBase) error {
+	b.inputDone = true
+	return b.flush(pb)
+}
+
+// flush acquires the locks on all of the pending rows, which then become
+// available through next. It must only be called once all of the previously
+// locked rows have been returned by next.
+func (b *lockingRowBuffer) flush(pb *ProcessorBase) error {
+	if len(b.pending) == 0 {
+		return nil
+	}
+	if b.lockedIdx < len(b.locked) {
+		return errors.AssertionFailedf("flushing with %d locked rows left", len(b.locked)-b.lockedIdx)
+	}
+	if err := b.locker.Flush(pb.Ctx, pb.flowCtx.txn); err != nil {
+		return err
+	}
+	b.locked, b.pending = b.pending, b.locked[:0]
+	b.lockedIdx = 0
+	return nil
+}
+
+// next returns the next row whose lock has been acquired, or nil if there is
+// no such row.
+func (b *lockingRowBuffer) next() sqlbase.EncDatumRow {
+	if b.lockedIdx >= len(b.locked) {
+		return nil
+	}
+	outRow := b.locked[b.lockedIdx]
+	b.locked[b.lockedIdx] = nil
+	b.lockedIdx++
+	return outRow
+}
+
+// done returns whether the processor does not need any more input rows and all
+// of the locked rows have been returned by next.
+func (b *lockingRowBuffer) done() bool {
+	return b.inputDone && len(b.pending) == 0 && b.lockedIdx >= len(b.locked)
+}
@@ -58,6 +58,10 @@ type tableReader struct {
 	fetcher rowFetcher
 	alloc   sqlbase.DatumAlloc
 
+	// lockedRows is set if the rows emitted by the tableReader need to be
+	// locked, see TableReaderSpec.LockingStrength.
+	lockedRows *lockingRowBuffer
+
 	// rowsRead is the number of rows read and is tracked unconditionally.
 	rowsRead int64
 }
@@ -116,10 +120,20 @@ func newTableReader(
 	}
 
 	neededColumns := tr.out.neededColumns()
+	columnIdxMap := spec.Table.ColumnIdxMapWithMutations(returnMutations)
+
+	var err error
+	if tr.lockedRows, err = newLockingRowBuffer(
+		spec.LockingStrength, &spec.Table, columnIdxMap, &tr.alloc, flowCtx.traceKV,
+	); err != nil {
+		return nil, err
+	}
+	if tr.lockedRows != nil {
+		addPrimaryKeyColumns(&neededColumns, &spec.Table, columnIdxMap)
+	}
 
 	var fetcher row.Fetcher
-	columnIdxMap := spec.Table.ColumnIdxMapWithMutations(returnMutations)
-	if _, _, err := initRowFetcher(
+	if _, _, err = initRowFetcher(
 		&fetcher, &spec.Table, int(spec.IndexIdx), columnIdxMap, spec.Reverse,
 		neededColumns, spec.IsCheck, &tr.alloc, spec.Visibility,
 	); err != nil {
@@ -257,6 +271,16 @@ func (tr *tableReader) Release() {
 // Next is part of the RowSource interface.
 func (tr *tableReader) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata) {
 	for tr.State == StateRunning {
+		if tr.lockedRows != nil {
+			if outRow := tr.lockedRows.next(); outRow != nil {
+				return outRow, nil
+			}
+			if tr.lockedRows.done() {
+				tr.MoveToDraining(nil /* err */)
+				break
+			}
+		}
+
 		row, meta := tr.fetcher.Next()
 
 		if meta != nil {
@@ -266,6 +290,14 @@ func (tr *tableReader) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 			return nil, meta
 		}
 		if row == nil {
+			if tr.lockedRows != nil {
+				// The pending rows must be locked before moving to draining, which
+				// closes the tableReader.
+				if err := tr.lockedRows.finishInput(&tr.ProcessorBase); err != nil {
+					tr.MoveToDraining(err)
+				}
+				continue
+			}
 			tr.MoveToDraining(nil /* err */)
 			break
 		}
@@ -275,6 +307,12 @@ func (tr *tableReader) Next() (sqlbase.EncDatumRow, *distsqlpb.ProducerMetadata)
 		// case can avoid tracking of the stall time which gives a noticeable
 		// performance hit.
 		tr.rowsRead++
+		if tr.lockedRows != nil {
+			if err := tr.lockedRows.processRow(&tr.ProcessorBase, row, row); err != nil {
+				tr.MoveToDraining(err)
+			}
+			continue
+		}
 		if outRow := tr.ProcessRowHelper(row); outRow != nil {
 			return outRow, nil
 		}
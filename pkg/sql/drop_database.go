@@ -12,6 +12,7 @@ package sql
 
 import (
 	"context"
+	"sort"
 
 	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
@@ -29,6 +30,9 @@ type dropDatabaseNode struct {
 	n      *tree.DropDatabase
 	dbDesc *sqlbase.DatabaseDescriptor
 	td     []toDelete
+	// tempSchemaNames are the names of the temporary schemas of the database,
+	// whose tables are part of td.
+	tempSchemaNames []string
 }
 
 // DropDatabase drops a database.
@@ -67,6 +71,25 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		return nil, err
 	}
 
+	// The temporary tables of all sessions are dropped along with the
+	// database.
+	tempSchemas, err := getTemporarySchemaNames(ctx, p.txn, dbDesc.ID)
+	if err != nil {
+		return nil, err
+	}
+	tempSchemaNames := make([]string, 0, len(tempSchemas))
+	for _, scName := range tempSchemas {
+		tempSchemaNames = append(tempSchemaNames, scName)
+	}
+	sort.Strings(tempSchemaNames)
+	for _, scName := range tempSchemaNames {
+		tempTbNames, err := GetObjectNames(ctx, p.txn, p, dbDesc, scName, true /*explicitPrefix*/)
+		if err != nil {
+			return nil, err
+		}
+		tbNames = append(tbNames, tempTbNames...)
+	}
+
 	if len(tbNames) > 0 {
 		switch n.DropBehavior {
 		case tree.DropRestrict:
@@ -107,7 +130,7 @@ func (p *planner) DropDatabase(ctx context.Context, n *tree.DropDatabase) (planN
 		return nil, err
 	}
 
-	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td}, nil
+	return &dropDatabaseNode{n: n, dbDesc: dbDesc, td: td, tempSchemaNames: tempSchemaNames}, nil
 }
 
 func (n *dropDatabaseNode) startExec(params runParams) error {
@@ -118,7 +141,7 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 	tableDescs := make([]*sqlbase.MutableTableDescriptor, 0, len(n.td))
 
 	for _, toDel := range n.td {
-		if toDel.desc.IsView() {
+		if toDel.desc.IsView() && !toDel.desc.MaterializedView() {
 			continue
 		}
 		droppedTableDetails = append(droppedTableDetails, jobspb.DroppedTableDetails{
@@ -168,6 +191,24 @@ func (n *dropDatabaseNode) startExec(params runParams) error {
 	}
 	b.Del(descKey)
 	b.Del(nameKey)
+	for _, scName := range n.tempSchemaNames {
+		b.Del(sqlbase.NewSchemaKey(n.dbDesc.ID, scName).Key())
+	}
+	// User-defined types are not in the namespace table, so only their
+	// descriptors need to be removed.
+	allDescs, err := GetAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+	for _, desc := range allDescs {
+		if typDesc, ok := desc.(*sqlbase.TypeDescriptor); ok && typDesc.ParentID == n.dbDesc.ID {
+			typKey := sqlbase.MakeDescMetadataKey(typDesc.ID)
+			if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+				log.VEventf(ctx, 2, "Del %s", typKey)
+			}
+			b.Del(typKey)
+		}
+	}
 
 	// No job was created because no tables were dropped, so zone config can be
 	// immediately removed.
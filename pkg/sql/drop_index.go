@@ -67,7 +67,7 @@ func (n *dropIndexNode) startExec(params runParams) error {
 		// the mutation list and new version number created by the first
 		// drop need to be visible to the second drop.
 		tableDesc, err := params.p.ResolveMutableTableDescriptor(
-			ctx, index.tn, true /*required*/, ResolveRequireTableDesc)
+			ctx, index.tn, true /*required*/, ResolveRequireTableOrMaterializedViewDesc)
 		if err != nil {
 			// Somehow the descriptor we had during newPlan() is not there
 			// any more.
@@ -132,6 +132,13 @@ func (p *planner) dropIndexByName(
 		return nil
 	}
 
+	if err := checkIndexNotUsedByRowLevelTTL(tableDesc, idx); err != nil {
+		return err
+	}
+	if err := checkNoPendingMaterializedViewRefresh(tableDesc); err != nil {
+		return err
+	}
+
 	// Check if requires CCL binary for eventual zone config removal.
 	_, zone, _, err := GetZoneConfigInTxn(ctx, p.txn, uint32(tableDesc.ID), nil, "", false)
 	if err != nil {
@@ -14,6 +14,8 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -42,6 +44,16 @@ func (p *planner) DropView(ctx context.Context, n *tree.DropView) (planNode, err
 			// IfExists specified and the view did not exist.
 			continue
 		}
+		if droppedDesc.MaterializedView() != n.IsMaterialized {
+			if n.IsMaterialized {
+				return nil, pgerror.Newf(pgcode.WrongObjectType,
+					"%q is not a materialized view", tree.ErrString(tn))
+			}
+			return nil, errors.WithHint(
+				pgerror.Newf(pgcode.WrongObjectType, "%q is a materialized view", tree.ErrString(tn)),
+				"use DROP MATERIALIZED VIEW instead.",
+			)
+		}
 
 		td = append(td, toDelete{tn, droppedDesc})
 	}
@@ -11,8 +11,10 @@
 package colserde
 
 import (
+	"encoding/binary"
 	"fmt"
 	"reflect"
+	"time"
 	"unsafe"
 
 	"github.com/apache/arrow/go/arrow"
@@ -20,6 +22,7 @@ import (
 	"github.com/apache/arrow/go/arrow/memory"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/pkg/errors"
 )
 
@@ -35,7 +38,8 @@ type ArrowBatchConverter struct {
 		// boolBuilder builds arrow bool columns as a bitmap from a bool slice.
 		boolBuilder *array.BooleanBuilder
 		// binaryBuilder builds arrow []byte columns as one []byte slice with
-		// accompanying offsets from a [][]byte slice.
+		// accompanying offsets from a [][]byte slice. It is also used for
+		// timestamps and intervals, which are stored in their binary encoding.
 		binaryBuilder *array.BinaryBuilder
 	}
 
@@ -47,6 +51,9 @@ type ArrowBatchConverter struct {
 		// buffers is scratch space for exactly two buffers per element in
 		// arrowData.
 		buffers [][]*memory.Buffer
+		// encoded is scratch space for the binary encodings of timestamps and
+		// intervals.
+		encoded [][]byte
 	}
 }
 
@@ -75,8 +82,21 @@ const (
 	sizeOfInt64   = int(unsafe.Sizeof(int64(0)))
 	sizeOfFloat32 = int(unsafe.Sizeof(float32(0)))
 	sizeOfFloat64 = int(unsafe.Sizeof(float64(0)))
+	// sizeOfInterval is the size of the binary encoding of an interval, which
+	// consists of its months, days and nanos.
+	sizeOfInterval = 3 * sizeOfInt64
 )
 
+// isBinaryType returns whether values of type t are converted to an arrow
+// binary array.
+func isBinaryType(t types.T) bool {
+	switch t {
+	case types.Bytes, types.Timestamp, types.Interval:
+		return true
+	}
+	return false
+}
+
 // BatchToArrow converts the first batch.Length elements of the batch into an
 // arrow []*array.Data. It is assumed that the batch is not larger than
 // coldata.BatchSize. The returned []*array.Data may only be used until the
@@ -97,9 +117,10 @@ func (c *ArrowBatchConverter) BatchToArrow(batch coldata.Batch) ([]*array.Data,
 			arrowBitmap = n.NullBitmap()
 		}
 
-		if typ == types.Bool || typ == types.Bytes {
-			// Bools and Bytes are handled differently from other types. Refer to the
-			// comment on ArrowBatchConverter.builders for more information.
+		if typ == types.Bool || isBinaryType(typ) {
+			// Bools, Bytes, Timestamps and Intervals are handled differently from
+			// other types. Refer to the comment on ArrowBatchConverter.builders for
+			// more information.
 			var data *array.Data
 			switch typ {
 			case types.Bool:
@@ -108,6 +129,16 @@ func (c *ArrowBatchConverter) BatchToArrow(batch coldata.Batch) ([]*array.Data,
 			case types.Bytes:
 				c.builders.binaryBuilder.AppendValues(vec.Bytes()[:n], nil /* valid */)
 				data = c.builders.binaryBuilder.NewBinaryArray().Data()
+			case types.Timestamp:
+				encoded, err := c.encodeTimestamps(vec.Timestamp()[:n])
+				if err != nil {
+					return nil, err
+				}
+				c.builders.binaryBuilder.AppendValues(encoded, nil /* valid */)
+				data = c.builders.binaryBuilder.NewBinaryArray().Data()
+			case types.Interval:
+				c.builders.binaryBuilder.AppendValues(c.encodeIntervals(vec.Interval()[:n]), nil /* valid */)
+				data = c.builders.binaryBuilder.NewBinaryArray().Data()
 			default:
 				panic(fmt.Sprintf("unexpected type %s", typ))
 			}
@@ -181,6 +212,35 @@ func (c *ArrowBatchConverter) BatchToArrow(batch coldata.Batch) ([]*array.Data,
 	return c.scratch.arrowData, nil
 }
 
+// encodeTimestamps returns the binary encodings of ts. The returned slice may
+// only be used until the next call to encodeTimestamps or encodeIntervals.
+func (c *ArrowBatchConverter) encodeTimestamps(ts []time.Time) ([][]byte, error) {
+	c.scratch.encoded = c.scratch.encoded[:0]
+	for i := range ts {
+		b, err := ts[i].MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		c.scratch.encoded = append(c.scratch.encoded, b)
+	}
+	return c.scratch.encoded, nil
+}
+
+// encodeIntervals returns the binary encodings of ds. The returned slice may
+// only be used until the next call to encodeTimestamps or encodeIntervals.
+func (c *ArrowBatchConverter) encodeIntervals(ds []duration.Duration) [][]byte {
+	c.scratch.encoded = c.scratch.encoded[:0]
+	buf := make([]byte, len(ds)*sizeOfInterval)
+	for i := range ds {
+		b := buf[i*sizeOfInterval : (i+1)*sizeOfInterval]
+		binary.LittleEndian.PutUint64(b, uint64(ds[i].Months))
+		binary.LittleEndian.PutUint64(b[sizeOfInt64:], uint64(ds[i].Days))
+		binary.LittleEndian.PutUint64(b[2*sizeOfInt64:], uint64(ds[i].Nanos()))
+		c.scratch.encoded = append(c.scratch.encoded, b)
+	}
+	return c.scratch.encoded
+}
+
 // ArrowToBatch converts []*array.Data to a coldata.Batch. There must not be
 // more than coldata.BatchSize elements in data. The returned batch may only be
 // used until the next call to ArrowToBatch.
@@ -195,7 +255,7 @@ func (c *ArrowBatchConverter) ArrowToBatch(data []*array.Data) (coldata.Batch, e
 		d := data[i]
 
 		var arr array.Interface
-		if typ == types.Bool || typ == types.Bytes {
+		if typ == types.Bool || isBinaryType(typ) {
 			switch typ {
 			case types.Bool:
 				boolArr := array.NewBooleanData(d)
@@ -219,6 +279,30 @@ func (c *ArrowBatchConverter) ArrowToBatch(data []*array.Data) (coldata.Batch, e
 					vecArr[i] = bytes[offsets[i]:offsets[i+1]]
 				}
 				arr = bytesArr
+			case types.Timestamp:
+				bytesArr := array.NewBinaryData(d)
+				vecArr := vec.Timestamp()
+				for i := 0; i < bytesArr.Len(); i++ {
+					if err := vecArr[i].UnmarshalBinary(bytesArr.Value(i)); err != nil {
+						return nil, err
+					}
+				}
+				arr = bytesArr
+			case types.Interval:
+				bytesArr := array.NewBinaryData(d)
+				vecArr := vec.Interval()
+				for i := 0; i < bytesArr.Len(); i++ {
+					b := bytesArr.Value(i)
+					if len(b) != sizeOfInterval {
+						return nil, errors.Errorf("unexpected interval encoding length %d", len(b))
+					}
+					vecArr[i] = duration.DecodeDuration(
+						int64(binary.LittleEndian.Uint64(b)),
+						int64(binary.LittleEndian.Uint64(b[sizeOfInt64:])),
+						int64(binary.LittleEndian.Uint64(b[2*sizeOfInt64:])),
+					)
+				}
+				arr = bytesArr
 			default:
 				panic(fmt.Sprintf("unexpected type %s", typ))
 			}
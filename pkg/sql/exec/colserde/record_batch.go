@@ -36,8 +36,8 @@ func numBuffersForType(t types.T) int {
 	// null bitmap and one for the values.
 	numBuffers := 2
 	switch t {
-	case types.Bytes:
-		// This type has an extra offsets buffer.
+	case types.Bytes, types.Timestamp, types.Interval:
+		// These types have an extra offsets buffer.
 		numBuffers = 3
 	}
 	return numBuffers
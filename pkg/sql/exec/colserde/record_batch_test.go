@@ -146,6 +146,20 @@ func randomDataFromType(rng *rand.Rand, t types.T, n int, nullProbability float6
 			}
 			builder.(*array.FixedSizeBinaryBuilder).AppendValues(data, valid)
 		}
+	case types.Timestamp, types.Interval:
+		// Timestamps and intervals are represented by their binary encodings,
+		// the contents of which are opaque to the serializer.
+		const encodingLen = 24
+		builder = array.NewBinaryBuilder(memory.DefaultAllocator, arrow.BinaryTypes.Binary)
+		data := make([][]byte, n)
+		for i := range data {
+			slice := make([]byte, encodingLen)
+			if valid[i] {
+				_, _ = rng.Read(slice)
+			}
+			data[i] = slice
+		}
+		builder.(*array.BinaryBuilder).AppendValues(data, valid)
 	default:
 		panic(fmt.Sprintf("unsupported type %s", t))
 	}
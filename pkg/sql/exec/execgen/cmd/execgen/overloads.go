@@ -88,6 +88,12 @@ type compareFunc func(l, r string) string
 var binaryOpOverloads []*overload
 var comparisonOpOverloads []*overload
 
+// mixedTypeBinaryOpOverloads is a list of the binary operator overloads whose
+// arguments are of different types or whose result is of a different type than
+// its arguments, such as timestamp + interval or timestamp - timestamp. Unlike
+// binaryOpOverloads, they are only used by the projection operators.
+var mixedTypeBinaryOpOverloads []*overload
+
 // binaryOpToOverloads maps a binary operator to all of the overloads that
 // implement it.
 var binaryOpToOverloads map[tree.BinaryOperator][]*overload
@@ -153,8 +159,13 @@ func init() {
 		for _, op := range binOps {
 			// Skip types that don't have associated binary ops.
 			switch t {
-			case types.Bytes, types.Bool:
+			case types.Bytes, types.Bool, types.Timestamp:
 				continue
+			case types.Interval:
+				// Intervals can only be added to and subtracted from each other.
+				if op != tree.Plus && op != tree.Minus {
+					continue
+				}
 			}
 			ov := &overload{
 				Name:    binaryOpName[op],
@@ -216,6 +227,32 @@ func init() {
 		}
 		hashOverloads = append(hashOverloads, ov)
 	}
+
+	// Build overload definitions for the binary operators on timestamps and
+	// intervals.
+	for _, o := range []struct {
+		op                 tree.BinaryOperator
+		lTyp, rTyp, retTyp types.T
+	}{
+		{op: tree.Plus, lTyp: types.Timestamp, rTyp: types.Interval, retTyp: types.Timestamp},
+		{op: tree.Plus, lTyp: types.Interval, rTyp: types.Timestamp, retTyp: types.Timestamp},
+		{op: tree.Minus, lTyp: types.Timestamp, rTyp: types.Interval, retTyp: types.Timestamp},
+		{op: tree.Minus, lTyp: types.Timestamp, rTyp: types.Timestamp, retTyp: types.Interval},
+	} {
+		ov := &overload{
+			Name:       binaryOpName[o.op],
+			BinOp:      o.op,
+			IsBinOp:    true,
+			OpStr:      binaryOpInfix[o.op],
+			LTyp:       o.lTyp,
+			RTyp:       o.rTyp,
+			LGoType:    o.lTyp.GoTypeName(),
+			RGoType:    o.rTyp.GoTypeName(),
+			RetTyp:     o.retTyp,
+			AssignFunc: timestampIntervalCustomizer{}.getBinOpAssignFunc(),
+		}
+		mixedTypeBinaryOpOverloads = append(mixedTypeBinaryOpOverloads, ov)
+	}
 }
 
 // typeCustomizer is a marker interface for something that implements one or
@@ -270,6 +307,18 @@ type floatCustomizer struct{ width int }
 // intCustomizers are used for hash functions.
 type intCustomizer struct{ width int }
 
+// timestampCustomizer is necessary since time.Time doesn't have infix operators.
+type timestampCustomizer struct{}
+
+// intervalCustomizer is necessary since duration.Duration doesn't have infix
+// operators.
+type intervalCustomizer struct{}
+
+// timestampIntervalCustomizer is necessary since time.Time and
+// duration.Duration can't be added to or subtracted from each other with infix
+// operators. It is only used by the mixedTypeBinaryOpOverloads.
+type timestampIntervalCustomizer struct{}
+
 func (boolCustomizer) getCmpOpCompareFunc() compareFunc {
 	return func(l, r string) string {
 		return fmt.Sprintf("tree.CompareBools(%s, %s)", l, r)
@@ -342,6 +391,81 @@ func (c intCustomizer) getHashAssignFunc() assignFunc {
 	}
 }
 
+func (timestampCustomizer) getCmpOpCompareFunc() compareFunc {
+	return func(l, r string) string {
+		return fmt.Sprintf("tree.CompareTimes(%s, %s)", l, r)
+	}
+}
+
+func (timestampCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		return fmt.Sprintf(`
+			s := %[2]s.UnixNano()
+			%[1]s = memhash64(noescape(unsafe.Pointer(&s)), %[1]s)
+		`, target, v)
+	}
+}
+
+func (intervalCustomizer) getCmpOpCompareFunc() compareFunc {
+	return func(l, r string) string {
+		return fmt.Sprintf("%s.Compare(%s)", l, r)
+	}
+}
+
+func (intervalCustomizer) getBinOpAssignFunc() assignFunc {
+	return func(op overload, target, l, r string) string {
+		switch op.BinOp {
+		case tree.Plus:
+			return fmt.Sprintf("%s = %s.Add(%s)", target, l, r)
+		case tree.Minus:
+			return fmt.Sprintf("%s = %s.Sub(%s)", target, l, r)
+		default:
+			panic(fmt.Sprintf("unhandled binary operator %s", op.BinOp.String()))
+		}
+	}
+}
+
+func (timestampIntervalCustomizer) getBinOpAssignFunc() assignFunc {
+	return func(op overload, target, l, r string) string {
+		// The results match those of the row engine when the session uses the
+		// default duration addition mode; the planner doesn't use these operators
+		// otherwise. Timestamps are rounded to microseconds, like
+		// tree.MakeDTimestamp does.
+		switch {
+		case op.BinOp == tree.Plus && op.LTyp == types.Timestamp:
+			return fmt.Sprintf(
+				"%s = duration.Add(duration.AdditionModeCompatible, %s, %s).Round(time.Microsecond)",
+				target, l, r)
+		case op.BinOp == tree.Plus && op.RTyp == types.Timestamp:
+			return fmt.Sprintf(
+				"%s = duration.Add(duration.AdditionModeCompatible, %s, %s).Round(time.Microsecond)",
+				target, r, l)
+		case op.BinOp == tree.Minus && op.RTyp == types.Interval:
+			return fmt.Sprintf(
+				"%s = duration.Add(duration.AdditionModeCompatible, %s, %s.Mul(-1)).Round(time.Microsecond)",
+				target, l, r)
+		case op.BinOp == tree.Minus && op.RTyp == types.Timestamp:
+			return fmt.Sprintf(
+				"%s = duration.MakeDuration(%s.Sub(%s).Nanoseconds(), 0, 0)", target, l, r)
+		default:
+			panic(fmt.Sprintf("unhandled binary operator %s on %s and %s", op.BinOp.String(), op.LTyp, op.RTyp))
+		}
+	}
+}
+
+func (intervalCustomizer) getHashAssignFunc() assignFunc {
+	return func(op overload, target, v, _ string) string {
+		// Intervals that compare equal may have different months, days and
+		// nanoseconds (for example, 1 month and 30 days), so the hash is computed
+		// over the total number of nanoseconds, which doesn't depend on the
+		// representation.
+		return fmt.Sprintf(`
+			n := %[2]s.Nanos() + %[2]s.Days*int64(24*time.Hour) + %[2]s.Months*int64(30*24*time.Hour)
+			%[1]s = memhash64(noescape(unsafe.Pointer(&n)), %[1]s)
+		`, target, v)
+	}
+}
+
 func registerTypeCustomizers() {
 	typeCustomizers = make(map[types.T]typeCustomizer)
 	registerTypeCustomizer(types.Bool, boolCustomizer{})
@@ -353,6 +477,8 @@ func registerTypeCustomizers() {
 	registerTypeCustomizer(types.Int16, intCustomizer{width: 16})
 	registerTypeCustomizer(types.Int32, intCustomizer{width: 32})
 	registerTypeCustomizer(types.Int64, intCustomizer{width: 64})
+	registerTypeCustomizer(types.Timestamp, timestampCustomizer{})
+	registerTypeCustomizer(types.Interval, intervalCustomizer{})
 }
 
 // Avoid unused warning for functions which are only used in templates.
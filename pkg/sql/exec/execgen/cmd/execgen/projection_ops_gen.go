@@ -23,6 +23,7 @@ package exec
 import (
 	"bytes"
   "context"
+	"time"
 
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
@@ -30,6 +31,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types/conv"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	semtypes "github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/pkg/errors"
 )
 
@@ -37,10 +39,7 @@ import (
 {{define "opLConstName"}}proj{{.Name}}{{.LTyp}}Const{{.RTyp}}Op{{end}}
 {{define "opName"}}proj{{.Name}}{{.LTyp}}{{.RTyp}}Op{{end}}
 
-{{/* The outer range is a types.T, and the inner is the overloads associated
-     with that type. */}}
-{{range .TypToOverloads}}
-{{range .}}
+{{range .Overloads}}
 
 type {{template "opRConstName" .}} struct {
 	input Operator
@@ -176,130 +175,149 @@ func (p {{template "opName" .}}) Init() {
 	p.input.Init()
 }
 
-{{end}}
 {{end}}
 
 {{/* Range over true and false. $left will be true when outputting a left-const
      operator, and false when outputting a right-const operator. */}}
 {{range $left := .ConstSides}}
-// GetProjectionConstOperator returns the appropriate constant projection
-// operator for the given column type and comparison.
+// GetProjection{{if $left}}L{{else}}R{{end}}ConstOperator returns the
+// appropriate constant projection operator for the given left and right column
+// types and operation.
 func GetProjection{{if $left}}L{{else}}R{{end}}ConstOperator(
-	ct *semtypes.T,
+	leftColType *semtypes.T,
+	rightColType *semtypes.T,
 	op tree.Operator,
 	input Operator,
 	colIdx int,
 	constArg tree.Datum,
   outputIdx int,
 ) (Operator, error) {
-	c, err := conv.GetDatumToPhysicalFn(ct)(constArg)
+	c, err := conv.GetDatumToPhysicalFn({{if $left}}leftColType{{else}}rightColType{{end}})(constArg)
 	if err != nil {
 		return nil, err
 	}
-	switch t := conv.FromColumnType(ct); t {
-	{{range $typ, $overloads := $.TypToOverloads}}
-	case types.{{$typ}}:
-		switch op.(type) {
-		case tree.BinaryOperator:
-			switch op {
-			{{range $overloads}}
-			{{if .IsBinOp}}
-			case tree.{{.Name}}:
-				return &{{if $left}}{{template "opLConstName" .}}{{else}}{{template "opRConstName" .}}{{end}}{
-					input:    input,
-					colIdx:   colIdx,
-					constArg: c.({{if $left}}{{.LGoType}}{{else}}{{.RGoType}}{{end}}),
-					outputIdx: outputIdx,
-				}, nil
-			{{end}}
-			{{end}}
-			default:
-				return nil, errors.Errorf("unhandled binary operator: %s", op)
-			}
-		case tree.ComparisonOperator:
-			switch op {
-			{{range $overloads}}
-			{{if .IsCmpOp}}
-			case tree.{{.Name}}:
-				return &{{if $left}}{{template "opLConstName" .}}{{else}}{{template "opRConstName" .}}{{end}}{
-					input:    input,
-					colIdx:   colIdx,
-					constArg: c.({{if $left}}{{.LGoType}}{{else}}{{.RGoType}}{{end}}),
-					outputIdx: outputIdx,
-				}, nil
-			{{end}}
-			{{end}}
+	switch leftType := conv.FromColumnType(leftColType); leftType {
+	{{range $lTyp, $rTypToOverloads := $.LTypToRTypToOverloads}}
+	case types.{{$lTyp}}:
+		switch rightType := conv.FromColumnType(rightColType); rightType {
+		{{range $rTyp, $overloads := $rTypToOverloads}}
+		case types.{{$rTyp}}:
+			switch op.(type) {
+			case tree.BinaryOperator:
+				switch op {
+				{{range $overloads}}
+				{{if .IsBinOp}}
+				case tree.{{.Name}}:
+					return &{{if $left}}{{template "opLConstName" .}}{{else}}{{template "opRConstName" .}}{{end}}{
+						input:    input,
+						colIdx:   colIdx,
+						constArg: c.({{if $left}}{{.LGoType}}{{else}}{{.RGoType}}{{end}}),
+						outputIdx: outputIdx,
+					}, nil
+				{{end}}
+				{{end}}
+				default:
+					return nil, errors.Errorf("unhandled binary operator: %s", op)
+				}
+			case tree.ComparisonOperator:
+				switch op {
+				{{range $overloads}}
+				{{if .IsCmpOp}}
+				case tree.{{.Name}}:
+					return &{{if $left}}{{template "opLConstName" .}}{{else}}{{template "opRConstName" .}}{{end}}{
+						input:    input,
+						colIdx:   colIdx,
+						constArg: c.({{if $left}}{{.LGoType}}{{else}}{{.RGoType}}{{end}}),
+						outputIdx: outputIdx,
+					}, nil
+				{{end}}
+				{{end}}
+				default:
+					return nil, errors.Errorf("unhandled comparison operator: %s", op)
+				}
 			default:
-				return nil, errors.Errorf("unhandled comparison operator: %s", op)
+				return nil, errors.New("unhandled operator type")
 			}
+		{{end}}
 		default:
-			return nil, errors.New("unhandled operator type")
+			return nil, errors.Errorf("unhandled right type: %s", rightType)
 		}
 	{{end}}
 	default:
-		return nil, errors.Errorf("unhandled type: %s", t)
+		return nil, errors.Errorf("unhandled left type: %s", leftType)
 	}
 }
 {{end}}
 
 // GetProjectionOperator returns the appropriate projection operator for the
-// given column type and comparison.
+// given left and right column types and operation.
 func GetProjectionOperator(
-	ct *semtypes.T,
+	leftColType *semtypes.T,
+	rightColType *semtypes.T,
 	op tree.Operator,
 	input Operator,
 	col1Idx int,
 	col2Idx int,
   outputIdx int,
 ) (Operator, error) {
-	switch t := conv.FromColumnType(ct); t {
-	{{range $typ, $overloads := .TypToOverloads}}
-	case types.{{$typ}}:
-		switch op.(type) {
-		case tree.BinaryOperator:
-			switch op {
-			{{range $overloads}}
-			{{if .IsBinOp}}
-			case tree.{{.Name}}:
-				return &{{template "opName" .}}{
-					input:    input,
-					col1Idx:   col1Idx,
-					col2Idx:   col2Idx,
-					outputIdx: outputIdx,
-				}, nil
-			{{end}}
-			{{end}}
+	switch leftType := conv.FromColumnType(leftColType); leftType {
+	{{range $lTyp, $rTypToOverloads := .LTypToRTypToOverloads}}
+	case types.{{$lTyp}}:
+		switch rightType := conv.FromColumnType(rightColType); rightType {
+		{{range $rTyp, $overloads := $rTypToOverloads}}
+		case types.{{$rTyp}}:
+			switch op.(type) {
+			case tree.BinaryOperator:
+				switch op {
+				{{range $overloads}}
+				{{if .IsBinOp}}
+				case tree.{{.Name}}:
+					return &{{template "opName" .}}{
+						input:    input,
+						col1Idx:   col1Idx,
+						col2Idx:   col2Idx,
+						outputIdx: outputIdx,
+					}, nil
+				{{end}}
+				{{end}}
+				default:
+					return nil, errors.Errorf("unhandled binary operator: %s", op)
+				}
+			case tree.ComparisonOperator:
+				switch op {
+				{{range $overloads}}
+				{{if .IsCmpOp}}
+				case tree.{{.Name}}:
+					return &{{template "opName" .}}{
+						input:    input,
+						col1Idx:   col1Idx,
+						col2Idx:   col2Idx,
+						outputIdx: outputIdx,
+					}, nil
+				{{end}}
+				{{end}}
+				default:
+					return nil, errors.Errorf("unhandled comparison operator: %s", op)
+				}
 			default:
-				return nil, errors.Errorf("unhandled binary operator: %s", op)
-			}
-		case tree.ComparisonOperator:
-			switch op {
-			{{range $overloads}}
-			{{if .IsCmpOp}}
-			case tree.{{.Name}}:
-				return &{{template "opName" .}}{
-					input:    input,
-					col1Idx:   col1Idx,
-					col2Idx:   col2Idx,
-					outputIdx: outputIdx,
-				}, nil
-			{{end}}
-			{{end}}
-			default:
-				return nil, errors.Errorf("unhandled comparison operator: %s", op)
+				return nil, errors.New("unhandled operator type")
 			}
+		{{end}}
 		default:
-			return nil, errors.New("unhandled operator type")
+			return nil, errors.Errorf("unhandled right type: %s", rightType)
 		}
 	{{end}}
 	default:
-		return nil, errors.Errorf("unhandled type: %s", t)
+		return nil, errors.Errorf("unhandled left type: %s", leftType)
 	}
 }
 `
 
 type genInput struct {
-	TypToOverloads map[types.T][]*overload
+	Overloads []*overload
+	// LTypToRTypToOverloads maps the left and right argument types of the
+	// overloads to the overloads themselves.
+	LTypToRTypToOverloads map[types.T]map[types.T][]*overload
 	// ConstSides is a boolean array that contains two elements, true and false.
 	// It's used by the template to generate both variants of the const projection
 	// op - once where the left is const, and one where the right is const.
@@ -314,14 +332,18 @@ func genProjectionOps(wr io.Writer) error {
 
 	var allOverloads []*overload
 	allOverloads = append(allOverloads, binaryOpOverloads...)
+	allOverloads = append(allOverloads, mixedTypeBinaryOpOverloads...)
 	allOverloads = append(allOverloads, comparisonOpOverloads...)
 
-	typToOverloads := make(map[types.T][]*overload)
-	for _, overload := range allOverloads {
-		typ := overload.LTyp
-		typToOverloads[typ] = append(typToOverloads[typ], overload)
+	lTypToRTypToOverloads := make(map[types.T]map[types.T][]*overload)
+	for _, ov := range allOverloads {
+		lTyp, rTyp := ov.LTyp, ov.RTyp
+		if lTypToRTypToOverloads[lTyp] == nil {
+			lTypToRTypToOverloads[lTyp] = make(map[types.T][]*overload)
+		}
+		lTypToRTypToOverloads[lTyp][rTyp] = append(lTypToRTypToOverloads[lTyp][rTyp], ov)
 	}
-	return tmpl.Execute(wr, genInput{typToOverloads, []bool{false, true}})
+	return tmpl.Execute(wr, genInput{allOverloads, lTypToRTypToOverloads, []bool{false, true}})
 }
 
 func init() {
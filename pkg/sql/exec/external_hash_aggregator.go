@@ -0,0 +1,246 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// externalHashAggregatorState represents the state of the external hash
+// aggregator.
+type externalHashAggregatorState int
+
+const (
+	// ehaBuffering is the initial state of the external hash aggregator, where
+	// it buffers its input in memory.
+	ehaBuffering externalHashAggregatorState = iota
+	// ehaInMemory is the state of the external hash aggregator when its input
+	// fits in memory. The aggregation is performed by an in-memory hash
+	// aggregator.
+	ehaInMemory
+	// ehaAggregatingPartitions is the state of the external hash aggregator
+	// once it has spilled its input to disk. The partitions are aggregated one
+	// at a time.
+	ehaAggregatingPartitions
+	// ehaDone is the state of the external hash aggregator once all the
+	// partitions have been aggregated.
+	ehaDone
+)
+
+// externalHashAggregator is a hash aggregator that partitions its input on
+// disk using the hash of the grouping columns when the input doesn't fit
+// within its memory budget. All the tuples of a group end up in the same
+// partition, so every partition is then aggregated independently by another
+// externalHashAggregator. The tuples of a partition are partitioned again if
+// they still don't fit in memory. As for the in-memory hash aggregator, the
+// order of the output is arbitrary.
+type externalHashAggregator struct {
+	cfg SpillingConfig
+	// level is the number of times the tuples of the input have been
+	// partitioned.
+	level int
+
+	input      Operator
+	inputTypes []types.T
+	groupCols  []uint32
+	// newInMemoryAggregator creates the in-memory hash aggregator used once the
+	// tuples of the input fit in memory.
+	newInMemoryAggregator func(input Operator) (Operator, error)
+
+	state    externalHashAggregatorState
+	buffered bufferedBatches
+
+	inMemoryAggregator Operator
+
+	partitions *spilledPartitions
+	// partitionIdx is the index of the next partition to aggregate.
+	partitionIdx        int
+	partitionAggregator *externalHashAggregator
+	partitionReader     Closer
+
+	zeroBatch coldata.Batch
+}
+
+var _ Operator = &externalHashAggregator{}
+var _ Closer = &externalHashAggregator{}
+
+// NewExternalHashAggregator creates a hash aggregator that spills to disk when
+// its input doesn't fit within the memory budget described by cfg. The other
+// arguments are the same as those of NewHashAggregator.
+func NewExternalHashAggregator(
+	input Operator,
+	colTypes []types.T,
+	aggFns []distsqlpb.AggregatorSpec_Func,
+	groupCols []uint32,
+	aggCols [][]uint32,
+	cfg SpillingConfig,
+) (Operator, error) {
+	newInMemoryAggregator := func(input Operator) (Operator, error) {
+		return NewHashAggregator(input, colTypes, aggFns, groupCols, aggCols)
+	}
+	// Validate the arguments, and determine the output types.
+	op, err := newInMemoryAggregator(input)
+	if err != nil {
+		return nil, err
+	}
+	return &externalHashAggregator{
+		cfg:                   cfg,
+		input:                 input,
+		inputTypes:            colTypes,
+		groupCols:             groupCols,
+		newInMemoryAggregator: newInMemoryAggregator,
+		zeroBatch:             coldata.NewMemBatchWithSize(op.(*hashAggregator).orderedAgg.outputTypes, 0),
+	}, nil
+}
+
+func (a *externalHashAggregator) Init() {
+	a.input.Init()
+	a.buffered = bufferedBatches{typs: a.inputTypes, memAcc: a.cfg.MemAcc}
+	a.zeroBatch.SetLength(0)
+}
+
+func (a *externalHashAggregator) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch a.state {
+		case ehaBuffering:
+			a.bufferInput(ctx)
+		case ehaInMemory:
+			return a.inMemoryAggregator.Next(ctx)
+		case ehaAggregatingPartitions:
+			if a.partitionAggregator == nil && !a.nextPartition() {
+				// Release the temporary storage right away.
+				a.Close(ctx)
+				a.state = ehaDone
+				continue
+			}
+			batch := a.partitionAggregator.Next(ctx)
+			if batch.Length() > 0 {
+				return batch
+			}
+			a.closePartitionAggregator(ctx)
+		case ehaDone:
+			return a.zeroBatch
+		default:
+			panic("external hash aggregator in unhandled state")
+		}
+	}
+}
+
+// bufferInput buffers the input in memory. If it fits, the aggregation is
+// performed by an in-memory hash aggregator. Otherwise, the input is spilled
+// to disk.
+func (a *externalHashAggregator) bufferInput(ctx context.Context) {
+	for {
+		batch := a.input.Next(ctx)
+		if batch.Length() == 0 {
+			break
+		}
+		if err := a.buffered.add(ctx, batch); err != nil {
+			if !sqlbase.IsOutOfMemoryError(err) || a.level >= externalHashMaxLevel {
+				panic(err)
+			}
+			a.spill(ctx, batch)
+			a.state = ehaAggregatingPartitions
+			return
+		}
+	}
+
+	var err error
+	a.inMemoryAggregator, err = a.newInMemoryAggregator(a.buffered.newReplayOp())
+	if err != nil {
+		panic(err)
+	}
+	a.inMemoryAggregator.Init()
+	a.state = ehaInMemory
+}
+
+// spill partitions the tuples of the input to disk. pending is the batch of
+// the input that didn't fit in memory.
+func (a *externalHashAggregator) spill(ctx context.Context, pending coldata.Batch) {
+	var err error
+	a.partitions, err = newSpilledPartitions(a.inputTypes, a.cfg)
+	if err != nil {
+		panic(err)
+	}
+	partitioner := makeHashPartitioner(a.inputTypes, a.groupCols, externalHashNumPartitions, a.level)
+	for _, batch := range a.buffered.batches {
+		if err := a.partitions.addPartitioned(ctx, &partitioner, batch); err != nil {
+			panic(err)
+		}
+	}
+	a.buffered.release(ctx)
+	for batch := pending; batch.Length() > 0; batch = a.input.Next(ctx) {
+		if err := a.partitions.addPartitioned(ctx, &partitioner, batch); err != nil {
+			panic(err)
+		}
+	}
+	if err := a.partitions.finish(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// nextPartition sets up the aggregator of the next non-empty partition. It
+// returns false if there are no more partitions.
+func (a *externalHashAggregator) nextPartition() bool {
+	for a.partitionIdx < a.partitions.numPartitions() {
+		idx := a.partitionIdx
+		a.partitionIdx++
+		if a.partitions.numTuples[idx] == 0 {
+			continue
+		}
+		reader, err := a.partitions.newReader(idx)
+		if err != nil {
+			panic(err)
+		}
+		a.partitionReader = reader.(Closer)
+		a.partitionAggregator = &externalHashAggregator{
+			cfg:                   a.cfg,
+			level:                 a.level + 1,
+			input:                 reader,
+			inputTypes:            a.inputTypes,
+			groupCols:             a.groupCols,
+			newInMemoryAggregator: a.newInMemoryAggregator,
+			zeroBatch:             a.zeroBatch,
+		}
+		a.partitionAggregator.Init()
+		return true
+	}
+	return false
+}
+
+func (a *externalHashAggregator) closePartitionAggregator(ctx context.Context) {
+	if a.partitionAggregator != nil {
+		a.partitionAggregator.Close(ctx)
+		a.partitionAggregator = nil
+	}
+	if a.partitionReader != nil {
+		a.partitionReader.Close(ctx)
+		a.partitionReader = nil
+	}
+}
+
+// Close is part of the Closer interface.
+func (a *externalHashAggregator) Close(ctx context.Context) {
+	a.closePartitionAggregator(ctx)
+	if a.partitions != nil {
+		a.partitions.close(ctx)
+		a.partitions = nil
+	}
+	a.inMemoryAggregator = nil
+	if a.buffered.memAcc != nil {
+		a.buffered.release(ctx)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestExternalHashAggregator(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	rng, _ := randutil.NewPseudoRand()
+	typs := []types.T{types.Int64, types.Int64}
+	aggFns := []distsqlpb.AggregatorSpec_Func{
+		distsqlpb.AggregatorSpec_ANY_NOT_NULL,
+		distsqlpb.AggregatorSpec_SUM_INT,
+		distsqlpb.AggregatorSpec_COUNT_ROWS,
+	}
+	aggCols := [][]uint32{{0}, {1}, {}}
+
+	// The input doesn't fit within the smaller memory limit, while each of its
+	// partitions does.
+	nGroups := 2048
+	tups := make(tuples, 8192)
+	type group struct{ sum, count int64 }
+	groups := make(map[int64]*group)
+	var nullGroup *group
+	for i := range tups {
+		tups[i] = tuple{rng.Int63() % int64(nGroups), rng.Int63() % 100}
+		if rng.Intn(20) == 0 {
+			tups[i][0] = nil
+		}
+		var g *group
+		if tups[i][0] == nil {
+			if nullGroup == nil {
+				nullGroup = &group{}
+			}
+			g = nullGroup
+		} else {
+			if groups[tups[i][0].(int64)] == nil {
+				groups[tups[i][0].(int64)] = &group{}
+			}
+			g = groups[tups[i][0].(int64)]
+		}
+		g.sum += tups[i][1].(int64)
+		g.count++
+	}
+	var expected tuples
+	if nullGroup != nil {
+		expected = append(expected, tuple{nil, nullGroup.sum, nullGroup.count})
+	}
+	var keys []int64
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	for _, k := range keys {
+		expected = append(expected, tuple{k, groups[k].sum, groups[k].count})
+	}
+
+	for _, memLimit := range []int64{math.MaxInt64, 20 << 10} {
+		t.Run(fmt.Sprintf("memLimit=%d", memLimit), func(t *testing.T) {
+			runTests(t, []tuples{tups}, func(t *testing.T, input []Operator) {
+				cfg, cleanup := newTestSpillingConfig(ctx, t, memLimit)
+				defer cleanup()
+
+				agg, err := NewExternalHashAggregator(
+					input[0], typs, aggFns, []uint32{0}, aggCols, cfg,
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer agg.(Closer).Close(ctx)
+				// The output of the aggregator is sorted on the grouping column
+				// before being verified.
+				sorter, err := NewSorter(
+					agg, []types.T{types.Int64, types.Int64, types.Int64}, []distsqlpb.Ordering_Column{{ColIdx: 0}},
+				)
+				if err != nil {
+					t.Fatal(err)
+				}
+				out := newOpTestOutput(sorter, []int{0, 1, 2}, expected)
+				if err := out.Verify(); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
@@ -0,0 +1,347 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// externalHashNumPartitions is the number of partitions into which the
+// external hash joiner and the external hash aggregator split their inputs
+// once they spill to disk.
+const externalHashNumPartitions = 16
+
+// externalHashMaxLevel is the number of times the tuples of the external hash
+// joiner and of the external hash aggregator can be partitioned. If a
+// partition still doesn't fit in memory at that point (for example because
+// all of its tuples are equal on the partitioning columns), the memory error
+// is returned.
+const externalHashMaxLevel = 3
+
+// externalHashJoinerState represents the state of the external hash joiner.
+type externalHashJoinerState int
+
+const (
+	// ehjBuffering is the initial state of the external hash joiner, where it
+	// buffers its build side in memory.
+	ehjBuffering externalHashJoinerState = iota
+	// ehjInMemory is the state of the external hash joiner when its build side
+	// fits in memory. The join is performed by an in-memory hash joiner.
+	ehjInMemory
+	// ehjJoiningPartitions is the state of the external hash joiner once it has
+	// spilled both of its inputs to disk. The partitions are joined one at a
+	// time.
+	ehjJoiningPartitions
+	// ehjDone is the state of the external hash joiner once all the partitions
+	// have been joined.
+	ehjDone
+)
+
+// externalHashJoiner is an equality hash joiner that falls back to a grace
+// hash join when its build side doesn't fit within its memory budget. In that
+// case, both inputs are partitioned on disk using the hash of their equality
+// columns, so that the matching tuples end up in the same partition, and every
+// pair of partitions is then joined by another externalHashJoiner. The tuples
+// of a partition are partitioned again if they still don't fit in memory.
+type externalHashJoiner struct {
+	cfg SpillingConfig
+	// level is the number of times the tuples of the inputs have been
+	// partitioned.
+	level int
+
+	left, right             Operator
+	leftTypes, rightTypes   []types.T
+	leftEqCols, rightEqCols []uint32
+	buildRightSide          bool
+	// newInMemoryJoiner creates the in-memory hash joiner used once the tuples
+	// of the build side fit in memory.
+	newInMemoryJoiner func(left, right Operator) (Operator, error)
+
+	state    externalHashJoinerState
+	buffered bufferedBatches
+
+	inMemoryJoiner Operator
+
+	buildPartitions, probePartitions *spilledPartitions
+	// partitionIdx is the index of the next partition to join.
+	partitionIdx     int
+	partitionJoiner  *externalHashJoiner
+	partitionReaders []Closer
+
+	zeroBatch coldata.Batch
+}
+
+var _ Operator = &externalHashJoiner{}
+var _ Closer = &externalHashJoiner{}
+
+// NewExternalEqHashJoinerOp creates a new equality hash join operator that
+// spills to disk when the tuples of its build side don't fit within the
+// memory budget described by cfg. The other arguments are the same as those of
+// NewEqHashJoinerOp.
+func NewExternalEqHashJoinerOp(
+	leftSource Operator,
+	rightSource Operator,
+	leftEqCols []uint32,
+	rightEqCols []uint32,
+	leftOutCols []uint32,
+	rightOutCols []uint32,
+	leftTypes []types.T,
+	rightTypes []types.T,
+	buildRightSide bool,
+	buildDistinct bool,
+	joinType sqlbase.JoinType,
+	cfg SpillingConfig,
+) (Operator, error) {
+	newInMemoryJoiner := func(left, right Operator) (Operator, error) {
+		return NewEqHashJoinerOp(
+			left, right, leftEqCols, rightEqCols, leftOutCols, rightOutCols,
+			leftTypes, rightTypes, buildRightSide, buildDistinct, joinType,
+		)
+	}
+	// Validate the arguments, and determine which side is the build side (the
+	// hash joiner may decide to build the right side depending on the type of
+	// the join).
+	op, err := newInMemoryJoiner(leftSource, rightSource)
+	if err != nil {
+		return nil, err
+	}
+	outputTypes := make([]types.T, 0, len(leftTypes)+len(rightTypes))
+	outputTypes = append(outputTypes, leftTypes...)
+	outputTypes = append(outputTypes, rightTypes...)
+	return &externalHashJoiner{
+		cfg:               cfg,
+		left:              leftSource,
+		right:             rightSource,
+		leftTypes:         leftTypes,
+		rightTypes:        rightTypes,
+		leftEqCols:        leftEqCols,
+		rightEqCols:       rightEqCols,
+		buildRightSide:    op.(*hashJoinEqOp).spec.buildRightSide,
+		newInMemoryJoiner: newInMemoryJoiner,
+		zeroBatch:         coldata.NewMemBatchWithSize(outputTypes, 0),
+	}, nil
+}
+
+// newPartitionJoiner returns an externalHashJoiner for the given inputs that
+// otherwise has the same specification as h.
+func (h *externalHashJoiner) newPartitionJoiner(left, right Operator) *externalHashJoiner {
+	return &externalHashJoiner{
+		cfg:               h.cfg,
+		level:             h.level + 1,
+		left:              left,
+		right:             right,
+		leftTypes:         h.leftTypes,
+		rightTypes:        h.rightTypes,
+		leftEqCols:        h.leftEqCols,
+		rightEqCols:       h.rightEqCols,
+		buildRightSide:    h.buildRightSide,
+		newInMemoryJoiner: h.newInMemoryJoiner,
+		zeroBatch:         h.zeroBatch,
+	}
+}
+
+func (h *externalHashJoiner) buildSide() (Operator, []types.T, []uint32) {
+	if h.buildRightSide {
+		return h.right, h.rightTypes, h.rightEqCols
+	}
+	return h.left, h.leftTypes, h.leftEqCols
+}
+
+func (h *externalHashJoiner) probeSide() (Operator, []types.T, []uint32) {
+	if h.buildRightSide {
+		return h.left, h.leftTypes, h.leftEqCols
+	}
+	return h.right, h.rightTypes, h.rightEqCols
+}
+
+func (h *externalHashJoiner) Init() {
+	// Only the build side is initialized here. The probe side is initialized
+	// either by the in-memory hash joiner or right before it is spilled.
+	build, buildTypes, _ := h.buildSide()
+	build.Init()
+	h.buffered = bufferedBatches{typs: buildTypes, memAcc: h.cfg.MemAcc}
+	h.zeroBatch.SetLength(0)
+}
+
+func (h *externalHashJoiner) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch h.state {
+		case ehjBuffering:
+			h.bufferBuildSide(ctx)
+		case ehjInMemory:
+			return h.inMemoryJoiner.Next(ctx)
+		case ehjJoiningPartitions:
+			if h.partitionJoiner == nil && !h.nextPartition() {
+				// Release the temporary storage right away.
+				h.Close(ctx)
+				h.state = ehjDone
+				continue
+			}
+			batch := h.partitionJoiner.Next(ctx)
+			if batch.Length() > 0 {
+				return batch
+			}
+			h.closePartitionJoiner(ctx)
+		case ehjDone:
+			return h.zeroBatch
+		default:
+			panic("external hash joiner in unhandled state")
+		}
+	}
+}
+
+// bufferBuildSide buffers the build side in memory. If it fits, the join is
+// performed by an in-memory hash joiner. Otherwise, both inputs are spilled to
+// disk.
+func (h *externalHashJoiner) bufferBuildSide(ctx context.Context) {
+	build, _, _ := h.buildSide()
+	for {
+		batch := build.Next(ctx)
+		if batch.Length() == 0 {
+			break
+		}
+		if err := h.buffered.add(ctx, batch); err != nil {
+			if !sqlbase.IsOutOfMemoryError(err) || h.level >= externalHashMaxLevel {
+				panic(err)
+			}
+			h.spill(ctx, batch)
+			h.state = ehjJoiningPartitions
+			return
+		}
+	}
+
+	left, right := h.left, h.right
+	if h.buildRightSide {
+		right = h.buffered.newReplayOp()
+	} else {
+		left = h.buffered.newReplayOp()
+	}
+	var err error
+	h.inMemoryJoiner, err = h.newInMemoryJoiner(left, right)
+	if err != nil {
+		panic(err)
+	}
+	h.inMemoryJoiner.Init()
+	h.state = ehjInMemory
+}
+
+// spill partitions the tuples of both inputs to disk. pending is the batch of
+// the build side that didn't fit in memory.
+func (h *externalHashJoiner) spill(ctx context.Context, pending coldata.Batch) {
+	build, buildTypes, buildEqCols := h.buildSide()
+	probe, probeTypes, probeEqCols := h.probeSide()
+
+	var err error
+	h.buildPartitions, err = newSpilledPartitions(buildTypes, h.cfg)
+	if err != nil {
+		panic(err)
+	}
+	partitioner := makeHashPartitioner(buildTypes, buildEqCols, externalHashNumPartitions, h.level)
+	for _, batch := range h.buffered.batches {
+		if err := h.buildPartitions.addPartitioned(ctx, &partitioner, batch); err != nil {
+			panic(err)
+		}
+	}
+	h.buffered.release(ctx)
+	for batch := pending; batch.Length() > 0; batch = build.Next(ctx) {
+		if err := h.buildPartitions.addPartitioned(ctx, &partitioner, batch); err != nil {
+			panic(err)
+		}
+	}
+	if err := h.buildPartitions.finish(ctx); err != nil {
+		panic(err)
+	}
+
+	// The probe side is partitioned with the same seed, so that the tuples
+	// that match end up in partitions with the same index.
+	probe.Init()
+	h.probePartitions, err = newSpilledPartitions(probeTypes, h.cfg)
+	if err != nil {
+		panic(err)
+	}
+	partitioner = makeHashPartitioner(probeTypes, probeEqCols, externalHashNumPartitions, h.level)
+	for batch := probe.Next(ctx); batch.Length() > 0; batch = probe.Next(ctx) {
+		if err := h.probePartitions.addPartitioned(ctx, &partitioner, batch); err != nil {
+			panic(err)
+		}
+	}
+	if err := h.probePartitions.finish(ctx); err != nil {
+		panic(err)
+	}
+}
+
+// nextPartition sets up the joiner of the next pair of partitions that may
+// produce output. It returns false if there are no more partitions.
+func (h *externalHashJoiner) nextPartition() bool {
+	for h.partitionIdx < externalHashNumPartitions {
+		idx := h.partitionIdx
+		h.partitionIdx++
+		if h.numTuples(h.buildPartitions, idx) == 0 && h.numTuples(h.probePartitions, idx) == 0 {
+			continue
+		}
+		build, err := h.buildPartitions.newReader(idx)
+		if err != nil {
+			panic(err)
+		}
+		probe, err := h.probePartitions.newReader(idx)
+		if err != nil {
+			panic(err)
+		}
+		h.partitionReaders = append(h.partitionReaders[:0], build.(Closer), probe.(Closer))
+		if h.buildRightSide {
+			h.partitionJoiner = h.newPartitionJoiner(probe, build)
+		} else {
+			h.partitionJoiner = h.newPartitionJoiner(build, probe)
+		}
+		h.partitionJoiner.Init()
+		return true
+	}
+	return false
+}
+
+func (h *externalHashJoiner) numTuples(p *spilledPartitions, idx int) uint64 {
+	if idx >= p.numPartitions() {
+		return 0
+	}
+	return p.numTuples[idx]
+}
+
+func (h *externalHashJoiner) closePartitionJoiner(ctx context.Context) {
+	if h.partitionJoiner != nil {
+		h.partitionJoiner.Close(ctx)
+		h.partitionJoiner = nil
+	}
+	for _, r := range h.partitionReaders {
+		r.Close(ctx)
+	}
+	h.partitionReaders = h.partitionReaders[:0]
+}
+
+// Close is part of the Closer interface.
+func (h *externalHashJoiner) Close(ctx context.Context) {
+	h.closePartitionJoiner(ctx)
+	if h.buildPartitions != nil {
+		h.buildPartitions.close(ctx)
+		h.buildPartitions = nil
+	}
+	if h.probePartitions != nil {
+		h.probePartitions.close(ctx)
+		h.probePartitions = nil
+	}
+	h.inMemoryJoiner = nil
+	if h.buffered.memAcc != nil {
+		h.buffered.release(ctx)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestExternalHashJoiner(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	rng, _ := randutil.NewPseudoRand()
+	typs := []types.T{types.Int64, types.Int64}
+
+	// The right side, which is the build side, doesn't fit within the smaller
+	// memory limit, while each of its partitions does.
+	nRightTups := 4096
+	rightTups := make(tuples, nRightTups)
+	for i := range rightTups {
+		rightTups[i] = tuple{int64(i), int64(2 * i)}
+	}
+	leftTups := make(tuples, 2048)
+	for i := range leftTups {
+		leftTups[i] = tuple{rng.Int63() % int64(2*nRightTups), int64(i)}
+		if rng.Intn(10) == 0 {
+			leftTups[i][0] = nil
+		}
+	}
+
+	for _, joinType := range []sqlbase.JoinType{sqlbase.JoinType_INNER, sqlbase.JoinType_LEFT_OUTER} {
+		var expected tuples
+		for _, l := range leftTups {
+			if l[0] != nil && l[0].(int64) < int64(nRightTups) {
+				expected = append(expected, tuple{l[0], l[1], l[0], 2 * l[0].(int64)})
+			} else if joinType == sqlbase.JoinType_LEFT_OUTER {
+				expected = append(expected, tuple{l[0], l[1], nil, nil})
+			}
+		}
+		// The output of the joiner is sorted on the left columns before being
+		// verified.
+		sort.Slice(expected, func(i, j int) bool {
+			if expected[i][0] == nil || expected[j][0] == nil {
+				if expected[i][0] == nil && expected[j][0] == nil {
+					return expected[i][1].(int64) < expected[j][1].(int64)
+				}
+				return expected[i][0] == nil
+			}
+			return less(expected, []distsqlpb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1}})(i, j)
+		})
+
+		for _, memLimit := range []int64{math.MaxInt64, 20 << 10} {
+			t.Run(fmt.Sprintf("joinType=%s/memLimit=%d", joinType, memLimit), func(t *testing.T) {
+				runTests(t, []tuples{leftTups, rightTups}, func(t *testing.T, input []Operator) {
+					cfg, cleanup := newTestSpillingConfig(ctx, t, memLimit)
+					defer cleanup()
+
+					hj, err := NewExternalEqHashJoinerOp(
+						input[0], input[1],
+						[]uint32{0}, []uint32{0},
+						[]uint32{0, 1}, []uint32{0, 1},
+						typs, typs,
+						true,  /* buildRightSide */
+						false, /* buildDistinct */
+						joinType,
+						cfg,
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+					defer hj.(Closer).Close(ctx)
+					sorter, err := NewSorter(
+						hj, append(typs, typs...), []distsqlpb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1}},
+					)
+					if err != nil {
+						t.Fatal(err)
+					}
+					out := newOpTestOutput(sorter, []int{0, 1, 2, 3}, expected)
+					if err := out.Verify(); err != nil {
+						t.Fatal(err)
+					}
+				})
+			})
+		}
+	}
+}
@@ -0,0 +1,235 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+// externalSorterMaxFanIn is the maximum number of sorted runs that the
+// external sorter merges at once. Runs are merged into larger runs until there
+// are few enough of them to be merged into the output.
+const externalSorterMaxFanIn = 16
+
+// externalSorterState represents the state of the external sorter.
+type externalSorterState int
+
+const (
+	// esBuffering is the initial state of the external sorter, where it buffers
+	// its input in memory and writes a sorted run to disk every time the memory
+	// budget is exceeded.
+	esBuffering externalSorterState = iota
+	// esInMemory is the state of the external sorter when its input fits in
+	// memory. The sort is performed by an in-memory sorter.
+	esInMemory
+	// esMerging is the state of the external sorter once its whole input has
+	// been written to disk as sorted runs, which are merged into the output.
+	esMerging
+)
+
+// externalSorter is a sorter that falls back to an external merge sort when
+// its input doesn't fit within its memory budget. The input is buffered until
+// the budget is exceeded, at which point the buffered tuples are sorted in
+// memory and written to disk as a sorted run. Once the input is exhausted, the
+// runs are merged with an OrderedSynchronizer.
+type externalSorter struct {
+	cfg          SpillingConfig
+	input        Operator
+	inputTypes   []types.T
+	orderingCols []distsqlpb.Ordering_Column
+
+	state    externalSorterState
+	buffered bufferedBatches
+
+	inMemorySorter Operator
+
+	// runs contains the sorted runs written to disk, one per partition.
+	runs *spilledPartitions
+	// firstRun is the index of the first run that hasn't been merged yet.
+	firstRun int
+	merger   Operator
+	// mergeReaders are the readers of the runs being merged into the output.
+	mergeReaders []Closer
+}
+
+var _ Operator = &externalSorter{}
+var _ Closer = &externalSorter{}
+
+// NewExternalSorter creates a sorter that spills to disk when its input
+// doesn't fit within the memory budget described by cfg. The other arguments
+// are the same as those of NewSorter.
+func NewExternalSorter(
+	input Operator,
+	inputTypes []types.T,
+	orderingCols []distsqlpb.Ordering_Column,
+	cfg SpillingConfig,
+) (Operator, error) {
+	// Validate the arguments.
+	if _, err := NewSorter(input, inputTypes, orderingCols); err != nil {
+		return nil, err
+	}
+	return &externalSorter{
+		cfg:          cfg,
+		input:        input,
+		inputTypes:   inputTypes,
+		orderingCols: orderingCols,
+	}, nil
+}
+
+func (s *externalSorter) Init() {
+	s.input.Init()
+	s.buffered = bufferedBatches{typs: s.inputTypes, memAcc: s.cfg.MemAcc}
+}
+
+func (s *externalSorter) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch s.state {
+		case esBuffering:
+			s.bufferInput(ctx)
+		case esInMemory:
+			return s.inMemorySorter.Next(ctx)
+		case esMerging:
+			batch := s.merger.Next(ctx)
+			if batch.Length() == 0 {
+				// Release the temporary storage right away.
+				s.Close(ctx)
+			}
+			return batch
+		default:
+			panic("external sorter in unhandled state")
+		}
+	}
+}
+
+// bufferInput consumes the input. If it fits in memory, the sort is performed
+// by an in-memory sorter. Otherwise, the input is written to disk as sorted
+// runs, which are then merged.
+func (s *externalSorter) bufferInput(ctx context.Context) {
+	for {
+		batch := s.input.Next(ctx)
+		if batch.Length() == 0 {
+			break
+		}
+		if err := s.buffered.add(ctx, batch); err != nil {
+			if !sqlbase.IsOutOfMemoryError(err) || len(s.buffered.batches) == 0 {
+				panic(err)
+			}
+			s.spillRun(ctx)
+			if err := s.buffered.add(ctx, batch); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	if s.runs == nil {
+		s.inMemorySorter = s.newInMemorySorter()
+		s.inMemorySorter.Init()
+		s.state = esInMemory
+		return
+	}
+	if len(s.buffered.batches) > 0 {
+		s.spillRun(ctx)
+	}
+	for s.runs.numPartitions()-s.firstRun > externalSorterMaxFanIn {
+		s.mergeRuns(ctx, externalSorterMaxFanIn)
+	}
+	s.merger = s.newMerger(s.runs.numPartitions() - s.firstRun)
+	s.merger.Init()
+	s.state = esMerging
+}
+
+func (s *externalSorter) newInMemorySorter() Operator {
+	sorter, err := NewSorter(s.buffered.newReplayOp(), s.inputTypes, s.orderingCols)
+	if err != nil {
+		panic(err)
+	}
+	return sorter
+}
+
+// spillRun sorts the buffered tuples and writes them to disk as a new run.
+func (s *externalSorter) spillRun(ctx context.Context) {
+	if s.runs == nil {
+		var err error
+		s.runs, err = newSpilledPartitions(s.inputTypes, s.cfg)
+		if err != nil {
+			panic(err)
+		}
+	}
+	sorter := s.newInMemorySorter()
+	sorter.Init()
+	s.writeRun(ctx, sorter)
+	s.buffered.release(ctx)
+}
+
+// mergeRuns merges the n oldest runs that haven't been merged yet into a new
+// run.
+func (s *externalSorter) mergeRuns(ctx context.Context, n int) {
+	merger := s.newMerger(n)
+	merger.Init()
+	s.writeRun(ctx, merger)
+	s.closeMergeReaders(ctx)
+}
+
+// writeRun writes all the tuples returned by input to disk as a new run.
+func (s *externalSorter) writeRun(ctx context.Context, input Operator) {
+	runIdx := s.runs.numPartitions()
+	s.runs.ensurePartition(runIdx)
+	for batch := input.Next(ctx); batch.Length() > 0; batch = input.Next(ctx) {
+		if err := s.runs.addBatch(ctx, runIdx, batch); err != nil {
+			panic(err)
+		}
+	}
+	if err := s.runs.finishPartition(ctx, runIdx); err != nil {
+		panic(err)
+	}
+}
+
+// newMerger returns an Operator that merges the n oldest runs that haven't
+// been merged yet.
+func (s *externalSorter) newMerger(n int) Operator {
+	inputs := make([]Operator, n)
+	for i := range inputs {
+		reader, err := s.runs.newReader(s.firstRun + i)
+		if err != nil {
+			panic(err)
+		}
+		inputs[i] = reader
+		s.mergeReaders = append(s.mergeReaders, reader.(Closer))
+	}
+	s.firstRun += n
+	ordering := distsqlpb.ConvertToColumnOrdering(distsqlpb.Ordering{Columns: s.orderingCols})
+	return NewOrderedSynchronizer(inputs, s.inputTypes, ordering)
+}
+
+func (s *externalSorter) closeMergeReaders(ctx context.Context) {
+	for _, r := range s.mergeReaders {
+		r.Close(ctx)
+	}
+	s.mergeReaders = s.mergeReaders[:0]
+}
+
+// Close is part of the Closer interface.
+func (s *externalSorter) Close(ctx context.Context) {
+	s.closeMergeReaders(ctx)
+	if s.runs != nil {
+		s.runs.close(ctx)
+		s.runs = nil
+	}
+	s.inMemorySorter = nil
+	if s.buffered.memAcc != nil {
+		s.buffered.release(ctx)
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestExternalSort(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	rng, _ := randutil.NewPseudoRand()
+	// With the smaller memory limit, the input is split into more runs than
+	// can be merged at once.
+	nTups := 24 * int(coldata.BatchSize)
+	typs := []types.T{types.Int64, types.Int64}
+	ordCols := []distsqlpb.Ordering_Column{{ColIdx: 0}, {ColIdx: 1}}
+
+	tups := make(tuples, nTups)
+	for i := range tups {
+		tups[i] = tuple{rng.Int63() % 64, int64(i)}
+		if rng.Intn(10) == 0 {
+			tups[i][0] = nil
+		}
+	}
+	expected := make(tuples, nTups)
+	copy(expected, tups)
+	sort.SliceStable(expected, func(i, j int) bool {
+		if expected[i][0] == nil || expected[j][0] == nil {
+			return expected[i][0] == nil && expected[j][0] != nil
+		}
+		return less(expected, ordCols)(i, j)
+	})
+
+	for _, memLimit := range []int64{math.MaxInt64, 20 << 10} {
+		t.Run(fmt.Sprintf("memLimit=%d", memLimit), func(t *testing.T) {
+			runTests(t, []tuples{tups}, func(t *testing.T, input []Operator) {
+				cfg, cleanup := newTestSpillingConfig(ctx, t, memLimit)
+				defer cleanup()
+
+				sorter, err := NewExternalSorter(input[0], typs, ordCols, cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer sorter.(Closer).Close(ctx)
+				out := newOpTestOutput(sorter, []int{0, 1}, expected)
+				if err := out.Verify(); err != nil {
+					t.Fatal(err)
+				}
+			})
+		})
+	}
+}
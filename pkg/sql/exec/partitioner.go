@@ -37,7 +37,7 @@ func NewWindowSortingPartitioner(
 	copy(partitionAndOrderingCols[len(partitionIdxs):], ordCols)
 	orderingColsIdxs = make([]uint32, len(ordCols))
 	for i := range ordCols {
-		orderingColsIdxs[i] = uint32(len(partitionIdxs) + i)
+		orderingColsIdxs[i] = ordCols[i].ColIdx
 	}
 	input, err = NewSorter(input, inputTyps, partitionAndOrderingCols)
 	if err != nil {
@@ -16,11 +16,14 @@ import (
 	"math/rand"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	semtypes "github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 )
 
 func TestProjPlusInt64Int64ConstOp(t *testing.T) {
@@ -55,6 +58,88 @@ func TestProjPlusInt64Int64Op(t *testing.T) {
 	})
 }
 
+func TestProjTimestampIntervalOps(t *testing.T) {
+	t1 := time.Date(2019, 1, 31, 12, 0, 0, 0, time.UTC)
+	t2 := time.Date(2019, 2, 28, 12, 0, 0, 0, time.UTC)
+	day := duration.MakeDuration(0, 1, 0)
+	month := duration.MakeDuration(0, 0, 1)
+
+	t.Run("PlusTimestampIntervalConst", func(t *testing.T) {
+		runTests(t, []tuples{{{t1}, {nil}}}, func(t *testing.T, input []Operator) {
+			op := projPlusTimestampIntervalConstOp{
+				input:     input[0],
+				colIdx:    0,
+				constArg:  month,
+				outputIdx: 1,
+			}
+			op.Init()
+			// Adding a month to the last day of January yields the last day of
+			// February, as in the row engine.
+			out := newOpTestOutput(&op, []int{0, 1}, tuples{{t1, t2}, {nil, nil}})
+			if err := out.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	})
+
+	t.Run("PlusIntervalConstTimestamp", func(t *testing.T) {
+		runTests(t, []tuples{{{t1}, {nil}}}, func(t *testing.T, input []Operator) {
+			op := projPlusIntervalConstTimestampOp{
+				input:     input[0],
+				colIdx:    0,
+				constArg:  day,
+				outputIdx: 1,
+			}
+			op.Init()
+			out := newOpTestOutput(&op, []int{0, 1}, tuples{{t1, t1.AddDate(0, 0, 1)}, {nil, nil}})
+			if err := out.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	})
+
+	t.Run("MinusTimestampInterval", func(t *testing.T) {
+		runTests(t, []tuples{{{t2, month}, {t2, nil}}}, func(t *testing.T, input []Operator) {
+			op := projMinusTimestampIntervalOp{
+				input:     input[0],
+				col1Idx:   0,
+				col2Idx:   1,
+				outputIdx: 2,
+			}
+			op.Init()
+			out := newOpTestOutput(&op, []int{0, 1, 2}, tuples{
+				{t2, month, t2.AddDate(0, -1, 0)},
+				{t2, nil, nil},
+			})
+			if err := out.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	})
+
+	t.Run("MinusTimestampTimestamp", func(t *testing.T) {
+		runTests(t, []tuples{{{t2, t1}, {t1, t2}, {nil, t1}}}, func(t *testing.T, input []Operator) {
+			op := projMinusTimestampTimestampOp{
+				input:     input[0],
+				col1Idx:   0,
+				col2Idx:   1,
+				outputIdx: 2,
+			}
+			op.Init()
+			// The difference between two timestamps is expressed in hours only.
+			diff := t2.Sub(t1)
+			out := newOpTestOutput(&op, []int{0, 1, 2}, tuples{
+				{t2, t1, duration.MakeDuration(diff.Nanoseconds(), 0, 0)},
+				{t1, t2, duration.MakeDuration(-diff.Nanoseconds(), 0, 0)},
+				{nil, t1, nil},
+			})
+			if err := out.Verify(); err != nil {
+				t.Error(err)
+			}
+		})
+	})
+}
+
 func benchmarkProjPlusInt64Int64ConstOp(b *testing.B, useSelectionVector bool, hasNulls bool) {
 	ctx := context.Background()
 
@@ -112,7 +197,7 @@ func TestGetProjectionConstOperator(t *testing.T) {
 	constVal := float64(31.37)
 	constArg := tree.NewDFloat(tree.DFloat(constVal))
 	outputIdx := 5
-	op, err := GetProjectionRConstOperator(semtypes.Float, binOp, input, colIdx, constArg, outputIdx)
+	op, err := GetProjectionRConstOperator(semtypes.Float, semtypes.Float, binOp, input, colIdx, constArg, outputIdx)
 	if err != nil {
 		t.Error(err)
 	}
@@ -134,7 +219,7 @@ func TestGetProjectionOperator(t *testing.T) {
 	col1Idx := 5
 	col2Idx := 7
 	outputIdx := 9
-	op, err := GetProjectionOperator(ct, binOp, input, col1Idx, col2Idx, outputIdx)
+	op, err := GetProjectionOperator(ct, ct, binOp, input, col1Idx, col2Idx, outputIdx)
 	if err != nil {
 		t.Error(err)
 	}
@@ -149,6 +234,49 @@ func TestGetProjectionOperator(t *testing.T) {
 	}
 }
 
+func TestGetProjectionOperatorMixedTypes(t *testing.T) {
+	var input Operator
+	constArg := &tree.DInterval{Duration: duration.MakeDuration(0, 1, 0)}
+	op, err := GetProjectionRConstOperator(
+		semtypes.Timestamp, semtypes.Interval, tree.Minus, input, 1 /* colIdx */, constArg, 2, /* outputIdx */
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedConstOp := &projMinusTimestampIntervalConstOp{
+		input:     input,
+		colIdx:    1,
+		constArg:  constArg.Duration,
+		outputIdx: 2,
+	}
+	if !reflect.DeepEqual(op, expectedConstOp) {
+		t.Errorf("got %+v, expected %+v", op, expectedConstOp)
+	}
+
+	op, err = GetProjectionOperator(
+		semtypes.TimestampTZ, semtypes.TimestampTZ, tree.Minus, input, 1 /* col1Idx */, 2 /* col2Idx */, 3, /* outputIdx */
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedOp := &projMinusTimestampTimestampOp{
+		input:     input,
+		col1Idx:   1,
+		col2Idx:   2,
+		outputIdx: 3,
+	}
+	if !reflect.DeepEqual(op, expectedOp) {
+		t.Errorf("got %+v, expected %+v", op, expectedOp)
+	}
+
+	// Intervals can't be multiplied by timestamps.
+	if _, err := GetProjectionOperator(
+		semtypes.Interval, semtypes.Timestamp, tree.Mult, input, 1 /* col1Idx */, 2 /* col2Idx */, 3, /* outputIdx */
+	); !testutils.IsError(err, "unhandled binary operator") {
+		t.Errorf("expected unhandled binary operator error, got %v", err)
+	}
+}
+
 func benchmarkProjPlusInt64Int64Op(b *testing.B, useSelectionVector bool, hasNulls bool) {
 	ctx := context.Background()
 
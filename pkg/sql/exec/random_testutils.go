@@ -14,9 +14,12 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 // maxVarLen specifies a length limit for variable length types (e.g. byte slices).
@@ -92,6 +95,16 @@ func randomVec(rng *rand.Rand, typ types.T, vec coldata.Vec, n int, nullProbabil
 		for i := 0; i < n; i++ {
 			floats[i] = rng.Float64()
 		}
+	case types.Timestamp:
+		timestamps := vec.Timestamp()
+		for i := 0; i < n; i++ {
+			timestamps[i] = timeutil.Unix(rng.Int63n(2000000000), rng.Int63n(1000000)*1000)
+		}
+	case types.Interval:
+		intervals := vec.Interval()
+		for i := 0; i < n; i++ {
+			intervals[i] = duration.MakeDuration(rng.Int63n(int64(24*time.Hour)), rng.Int63n(1000), rng.Int63n(1000))
+		}
 	default:
 		panic(fmt.Sprintf("unhandled type %s", typ))
 	}
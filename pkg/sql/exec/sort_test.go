@@ -20,8 +20,10 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
 	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
 )
 
 func TestSort(t *testing.T) {
@@ -81,6 +83,18 @@ func TestSort(t *testing.T) {
 			typ:      []types.T{types.Float64},
 			ordCols:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
 		},
+		{
+			tuples:   tuples{{timeutil.Unix(3, 0)}, {timeutil.Unix(1, 0)}, {timeutil.Unix(2, 0)}},
+			expected: tuples{{timeutil.Unix(1, 0)}, {timeutil.Unix(2, 0)}, {timeutil.Unix(3, 0)}},
+			typ:      []types.T{types.Timestamp},
+			ordCols:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+		},
+		{
+			tuples:   tuples{{duration.MakeDuration(0, 0, 1)}, {duration.MakeDuration(0, 1, 0)}, {duration.MakeDuration(1000, 0, 0)}},
+			expected: tuples{{duration.MakeDuration(1000, 0, 0)}, {duration.MakeDuration(0, 1, 0)}, {duration.MakeDuration(0, 0, 1)}},
+			typ:      []types.T{types.Interval},
+			ordCols:  []distsqlpb.Ordering_Column{{ColIdx: 0}},
+		},
 
 		{
 			tuples:   tuples{{0, 1, 0}, {1, 2, 0}, {2, 3, 2}, {3, 7, 1}, {4, 2, 2}},
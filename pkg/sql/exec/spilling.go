@@ -0,0 +1,549 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"time"
+	"unsafe"
+
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/colserde"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/diskmap"
+	"github.com/cockroachdb/cockroach/pkg/util/duration"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// SpillingConfig describes the resources available to an operator that falls
+// back to an external algorithm once it cannot buffer its input in memory
+// anymore.
+type SpillingConfig struct {
+	// MemAcc accounts for the tuples buffered in memory by the operator. Once
+	// the account cannot be grown anymore, the operator spills to disk.
+	MemAcc *mon.BoundAccount
+	// DiskAcc accounts for the temporary storage used by the operator.
+	DiskAcc *mon.BoundAccount
+	// DiskMapFactory creates the temporary storage used by the operator once it
+	// has spilled to disk.
+	DiskMapFactory diskmap.Factory
+}
+
+// Closer is implemented by the operators that hold resources, such as
+// temporary storage, that need to be released once the flow they are a part of
+// is done. Close must be idempotent.
+type Closer interface {
+	Close(ctx context.Context)
+}
+
+// SupportsSpilling returns whether tuples of the given types can be written to
+// temporary storage.
+func SupportsSpilling(typs []types.T) bool {
+	if len(typs) == 0 {
+		// The serialization format doesn't support zero-length schemas.
+		return false
+	}
+	for _, t := range typs {
+		switch t {
+		case types.Unhandled, types.Decimal:
+			// Decimals aren't supported by the serialization format yet.
+			return false
+		}
+	}
+	return true
+}
+
+const (
+	sizeOfBool      = int64(unsafe.Sizeof(true))
+	sizeOfInt8      = int64(unsafe.Sizeof(int8(0)))
+	sizeOfInt16     = int64(unsafe.Sizeof(int16(0)))
+	sizeOfInt32     = int64(unsafe.Sizeof(int32(0)))
+	sizeOfInt64     = int64(unsafe.Sizeof(int64(0)))
+	sizeOfFloat32   = int64(unsafe.Sizeof(float32(0)))
+	sizeOfFloat64   = int64(unsafe.Sizeof(float64(0)))
+	sizeOfDecimal   = int64(unsafe.Sizeof(apd.Decimal{}))
+	sizeOfBytes     = int64(unsafe.Sizeof([]byte(nil)))
+	sizeOfTimestamp = int64(unsafe.Sizeof(time.Time{}))
+	sizeOfInterval  = int64(unsafe.Sizeof(duration.Duration{}))
+)
+
+// batchMemSize returns an estimate of the memory used by the tuples of batch
+// selected by sel, or by the first n tuples of batch if sel is nil. The
+// payload of byte slices is included, while the nulls bitmaps are not.
+func batchMemSize(typs []types.T, batch coldata.Batch, sel []uint16, n uint16) int64 {
+	return TuplesMemSize(typs, batch, sel, 0, n)
+}
+
+// TuplesMemSize returns an estimate of the memory used by the tuples of batch
+// selected by sel[start:end], or by the tuples [start, end) of batch if sel is
+// nil. The payload of byte slices is included, while the nulls bitmaps are
+// not.
+func TuplesMemSize(typs []types.T, batch coldata.Batch, sel []uint16, start, end uint16) int64 {
+	var size int64
+	n := int64(end - start)
+	for i, t := range typs {
+		switch t {
+		case types.Bool:
+			size += sizeOfBool * n
+		case types.Int8:
+			size += sizeOfInt8 * n
+		case types.Int16:
+			size += sizeOfInt16 * n
+		case types.Int32:
+			size += sizeOfInt32 * n
+		case types.Int64:
+			size += sizeOfInt64 * n
+		case types.Float32:
+			size += sizeOfFloat32 * n
+		case types.Float64:
+			size += sizeOfFloat64 * n
+		case types.Decimal:
+			size += sizeOfDecimal * n
+		case types.Timestamp:
+			size += sizeOfTimestamp * n
+		case types.Interval:
+			size += sizeOfInterval * n
+		case types.Bytes:
+			size += sizeOfBytes * n
+			col := batch.ColVec(i).Bytes()
+			if sel != nil {
+				for _, idx := range sel[start:end] {
+					size += int64(len(col[idx]))
+				}
+			} else {
+				for _, b := range col[start:end] {
+					size += int64(len(b))
+				}
+			}
+		}
+	}
+	return size
+}
+
+// appendTuples appends the tuples of src selected by sel[start:end] (or the
+// tuples src[start:end] if sel is nil) to the end of dst. dst must not have a
+// selection vector.
+func appendTuples(typs []types.T, dst coldata.Batch, src coldata.Batch, sel []uint16, start, end uint16) {
+	for i, t := range typs {
+		dst.ColVec(i).Append(
+			coldata.AppendArgs{
+				ColType:     t,
+				Src:         src.ColVec(i),
+				Sel:         sel,
+				DestIdx:     uint64(dst.Length()),
+				SrcStartIdx: start,
+				SrcEndIdx:   end,
+			},
+		)
+	}
+	dst.SetLength(dst.Length() + end - start)
+}
+
+// resetBatch empties batch, which must have been filled using appendTuples, so
+// that it can be reused.
+func resetBatch(batch coldata.Batch) {
+	for _, vec := range batch.ColVecs() {
+		vec.Nulls().UnsetNulls()
+	}
+	batch.SetLength(0)
+}
+
+// bufferedBatches accumulates the tuples of the batches added to it into dense
+// batches, accounting for their memory usage.
+type bufferedBatches struct {
+	typs   []types.T
+	memAcc *mon.BoundAccount
+
+	batches []coldata.Batch
+}
+
+// add buffers the tuples of batch. An out of memory error is returned, and
+// the tuples are not buffered, if the memory account cannot be grown to fit
+// them.
+func (b *bufferedBatches) add(ctx context.Context, batch coldata.Batch) error {
+	n := batch.Length()
+	sel := batch.Selection()
+	if err := b.memAcc.Grow(ctx, batchMemSize(b.typs, batch, sel, n)); err != nil {
+		return err
+	}
+	for start := uint16(0); start < n; {
+		if len(b.batches) == 0 || b.batches[len(b.batches)-1].Length() == coldata.BatchSize {
+			dst := coldata.NewMemBatch(b.typs)
+			dst.SetLength(0)
+			b.batches = append(b.batches, dst)
+		}
+		dst := b.batches[len(b.batches)-1]
+		end := start + (coldata.BatchSize - dst.Length())
+		if end > n {
+			end = n
+		}
+		appendTuples(b.typs, dst, batch, sel, start, end)
+		start = end
+	}
+	return nil
+}
+
+// release drops the buffered tuples and returns their memory to the account.
+func (b *bufferedBatches) release(ctx context.Context) {
+	b.batches = nil
+	b.memAcc.Clear(ctx)
+}
+
+// newReplayOp returns an Operator that returns the buffered batches.
+func (b *bufferedBatches) newReplayOp() Operator {
+	return &replayOp{batches: b.batches, zeroBatch: coldata.NewMemBatchWithSize(b.typs, 0)}
+}
+
+// replayOp is an Operator that returns a list of batches.
+type replayOp struct {
+	batches   []coldata.Batch
+	zeroBatch coldata.Batch
+}
+
+var _ Operator = &replayOp{}
+
+func (r *replayOp) Init() {
+	r.zeroBatch.SetLength(0)
+}
+
+func (r *replayOp) Next(context.Context) coldata.Batch {
+	if len(r.batches) == 0 {
+		return r.zeroBatch
+	}
+	batch := r.batches[0]
+	r.batches = r.batches[1:]
+	return batch
+}
+
+// spilledPartitions stores tuples in temporary storage, grouped into
+// partitions that are read back independently of each other. Tuples are
+// buffered in memory until a full batch of a partition can be written.
+//
+// Every batch is stored under a key made of the index of its partition
+// followed by its index within the partition, so that iterating over a
+// partition returns its batches in the order in which they were written.
+type spilledPartitions struct {
+	typs    []types.T
+	diskAcc *mon.BoundAccount
+
+	diskMap diskmap.SortedDiskMap
+	writer  diskmap.SortedDiskMapBatchWriter
+
+	converter  *colserde.ArrowBatchConverter
+	serializer *colserde.RecordBatchSerializer
+
+	// buffers contains, for each partition, the tuples that haven't been written
+	// yet. Note that the memory used by these buffers is not accounted for,
+	// since there is a small, fixed number of them.
+	buffers []coldata.Batch
+	// numBatches contains, for each partition, the number of batches written.
+	numBatches []uint64
+	// numTuples contains, for each partition, the number of tuples added.
+	numTuples []uint64
+
+	scratch struct {
+		key   []byte
+		value bytes.Buffer
+	}
+}
+
+func newSpilledPartitions(typs []types.T, cfg SpillingConfig) (*spilledPartitions, error) {
+	serializer, err := colserde.NewRecordBatchSerializer(typs)
+	if err != nil {
+		return nil, err
+	}
+	p := &spilledPartitions{
+		typs:       typs,
+		diskAcc:    cfg.DiskAcc,
+		diskMap:    cfg.DiskMapFactory.NewSortedDiskMap(),
+		converter:  colserde.NewArrowBatchConverter(typs),
+		serializer: serializer,
+	}
+	p.writer = p.diskMap.NewBatchWriter()
+	return p, nil
+}
+
+// numPartitions returns the number of partitions that tuples were added to.
+func (p *spilledPartitions) numPartitions() int {
+	return len(p.numTuples)
+}
+
+func (p *spilledPartitions) ensurePartition(partitionIdx int) {
+	for len(p.numTuples) <= partitionIdx {
+		p.buffers = append(p.buffers, nil)
+		p.numBatches = append(p.numBatches, 0)
+		p.numTuples = append(p.numTuples, 0)
+	}
+	if p.buffers[partitionIdx] == nil {
+		p.buffers[partitionIdx] = coldata.NewMemBatch(p.typs)
+		p.buffers[partitionIdx].SetLength(0)
+	}
+}
+
+// add adds the tuples of batch selected by sel[:n], or the first n tuples of
+// batch if sel is nil, to the given partition.
+func (p *spilledPartitions) add(
+	ctx context.Context, partitionIdx int, batch coldata.Batch, sel []uint16, n uint16,
+) error {
+	p.ensurePartition(partitionIdx)
+	buf := p.buffers[partitionIdx]
+	for start := uint16(0); start < n; {
+		end := start + (coldata.BatchSize - buf.Length())
+		if end > n {
+			end = n
+		}
+		appendTuples(p.typs, buf, batch, sel, start, end)
+		if buf.Length() == coldata.BatchSize {
+			if err := p.flushPartition(ctx, partitionIdx); err != nil {
+				return err
+			}
+		}
+		start = end
+	}
+	p.numTuples[partitionIdx] += uint64(n)
+	return nil
+}
+
+// addBatch adds all the tuples of batch to the given partition.
+func (p *spilledPartitions) addBatch(ctx context.Context, partitionIdx int, batch coldata.Batch) error {
+	return p.add(ctx, partitionIdx, batch, batch.Selection(), batch.Length())
+}
+
+// flushPartition writes the buffered tuples of the given partition.
+func (p *spilledPartitions) flushPartition(ctx context.Context, partitionIdx int) error {
+	buf := p.buffers[partitionIdx]
+	if buf == nil || buf.Length() == 0 {
+		return nil
+	}
+	data, err := p.converter.BatchToArrow(buf)
+	if err != nil {
+		return err
+	}
+	p.scratch.value.Reset()
+	if err := p.serializer.Serialize(&p.scratch.value, data); err != nil {
+		return err
+	}
+	p.scratch.key = encodePartitionKey(p.scratch.key[:0], partitionIdx)
+	p.scratch.key = encoding.EncodeUvarintAscending(p.scratch.key, p.numBatches[partitionIdx])
+	if err := p.diskAcc.Grow(ctx, int64(len(p.scratch.key)+p.scratch.value.Len())); err != nil {
+		return err
+	}
+	if err := p.writer.Put(p.scratch.key, p.scratch.value.Bytes()); err != nil {
+		return err
+	}
+	p.numBatches[partitionIdx]++
+	resetBatch(buf)
+	return nil
+}
+
+// finishPartition writes the buffered tuples of the given partition and
+// releases its buffer. No tuples can be added to the partition afterwards.
+func (p *spilledPartitions) finishPartition(ctx context.Context, partitionIdx int) error {
+	p.ensurePartition(partitionIdx)
+	if err := p.flushPartition(ctx, partitionIdx); err != nil {
+		return err
+	}
+	p.buffers[partitionIdx] = nil
+	return p.writer.Flush()
+}
+
+// finish writes all the buffered tuples. It must be called before any
+// partition is read.
+func (p *spilledPartitions) finish(ctx context.Context) error {
+	for i := range p.buffers {
+		if err := p.flushPartition(ctx, i); err != nil {
+			return err
+		}
+		p.buffers[i] = nil
+	}
+	return p.writer.Flush()
+}
+
+// newReader returns an Operator that returns the tuples of the given
+// partition. The partition must have been finished.
+func (p *spilledPartitions) newReader(partitionIdx int) (Operator, error) {
+	serializer, err := colserde.NewRecordBatchSerializer(p.typs)
+	if err != nil {
+		return nil, err
+	}
+	return &spilledPartitionReader{
+		prefix:     encodePartitionKey(nil, partitionIdx),
+		diskMap:    p.diskMap,
+		converter:  colserde.NewArrowBatchConverter(p.typs),
+		serializer: serializer,
+		zeroBatch:  coldata.NewMemBatchWithSize(p.typs, 0),
+	}, nil
+}
+
+// close releases the temporary storage used by the partitions.
+func (p *spilledPartitions) close(ctx context.Context) {
+	if p.writer != nil {
+		// The error is ignored since the written data is discarded anyway.
+		_ = p.writer.Close(ctx)
+		p.writer = nil
+	}
+	if p.diskMap != nil {
+		p.diskMap.Close(ctx)
+		p.diskMap = nil
+	}
+}
+
+func encodePartitionKey(b []byte, partitionIdx int) []byte {
+	return encoding.EncodeUvarintAscending(b, uint64(partitionIdx))
+}
+
+// spilledPartitionReader is an Operator that returns the tuples of a
+// partition of spilledPartitions.
+type spilledPartitionReader struct {
+	prefix  []byte
+	diskMap diskmap.SortedDiskMap
+	iter    diskmap.SortedDiskMapIterator
+
+	converter  *colserde.ArrowBatchConverter
+	serializer *colserde.RecordBatchSerializer
+	arrowData  []*array.Data
+	zeroBatch  coldata.Batch
+	done       bool
+}
+
+var _ Operator = &spilledPartitionReader{}
+var _ Closer = &spilledPartitionReader{}
+
+func (r *spilledPartitionReader) Init() {
+	r.zeroBatch.SetLength(0)
+}
+
+func (r *spilledPartitionReader) Next(ctx context.Context) coldata.Batch {
+	if r.done {
+		return r.zeroBatch
+	}
+	if r.iter == nil {
+		r.iter = r.diskMap.NewIterator()
+		r.iter.Seek(r.prefix)
+	} else {
+		r.iter.Next()
+	}
+	if ok, err := r.iter.Valid(); err != nil {
+		panic(err)
+	} else if !ok || !bytes.HasPrefix(r.iter.UnsafeKey(), r.prefix) {
+		r.Close(ctx)
+		return r.zeroBatch
+	}
+	// Value, unlike UnsafeValue, returns memory that stays valid after the
+	// iterator is moved, which is needed since the returned batch may reference
+	// it.
+	r.arrowData = r.arrowData[:0]
+	if err := r.serializer.Deserialize(&r.arrowData, r.iter.Value()); err != nil {
+		panic(err)
+	}
+	batch, err := r.converter.ArrowToBatch(r.arrowData)
+	if err != nil {
+		panic(err)
+	}
+	return batch
+}
+
+// Close is part of the Closer interface.
+func (r *spilledPartitionReader) Close(context.Context) {
+	r.done = true
+	if r.iter != nil {
+		r.iter.Close()
+		r.iter = nil
+	}
+}
+
+// hashPartitioner distributes tuples into partitions based on the hash of
+// their values in a set of columns, so that tuples that are equal in those
+// columns end up in the same partition.
+type hashPartitioner struct {
+	typs          []types.T
+	cols          []uint32
+	numPartitions int
+	// seed is the initial value of the hashes. It is different for every level
+	// of partitioning, and different from the one used by the hashTable, so
+	// that the tuples of a partition are distributed uniformly when it is
+	// partitioned again or when it is loaded into a hashTable.
+	seed uint64
+
+	// hasher is only used for its rehash method.
+	hasher  hashTable
+	buckets []uint64
+	// sels contains, for each partition, the selection vector of the tuples of
+	// the last partitioned batch that belong to the partition.
+	sels [][]uint16
+}
+
+func makeHashPartitioner(typs []types.T, cols []uint32, numPartitions int, level int) hashPartitioner {
+	sels := make([][]uint16, numPartitions)
+	for i := range sels {
+		sels[i] = make([]uint16, 0, coldata.BatchSize)
+	}
+	return hashPartitioner{
+		typs:          typs,
+		cols:          cols,
+		numPartitions: numPartitions,
+		seed:          uint64(level) + 2,
+		buckets:       make([]uint64, coldata.BatchSize),
+		sels:          sels,
+	}
+}
+
+// partition computes the partition of every tuple of batch and populates
+// sels accordingly. Tuples that have a NULL in any of the columns are all put
+// in the first partition.
+func (h *hashPartitioner) partition(ctx context.Context, batch coldata.Batch) {
+	n := batch.Length()
+	sel := batch.Selection()
+	for i := range h.sels {
+		h.sels[i] = h.sels[i][:0]
+	}
+	for i := uint16(0); i < n; i++ {
+		h.buckets[i] = h.seed
+	}
+	for i, col := range h.cols {
+		h.hasher.rehash(ctx, h.buckets, i, h.typs[col], batch.ColVec(int(col)), uint64(n), sel)
+	}
+	for i := uint16(0); i < n; i++ {
+		idx := i
+		if sel != nil {
+			idx = sel[i]
+		}
+		partitionIdx := int(h.buckets[i] % uint64(h.numPartitions))
+		for _, col := range h.cols {
+			if vec := batch.ColVec(int(col)); vec.HasNulls() && vec.Nulls().NullAt(idx) {
+				partitionIdx = 0
+				break
+			}
+		}
+		h.sels[partitionIdx] = append(h.sels[partitionIdx], idx)
+	}
+}
+
+// addPartitioned partitions the tuples of batch using h and adds them to the
+// corresponding partitions of p.
+func (p *spilledPartitions) addPartitioned(
+	ctx context.Context, h *hashPartitioner, batch coldata.Batch,
+) error {
+	h.partition(ctx, batch)
+	for i, sel := range h.sels {
+		if len(sel) == 0 {
+			continue
+		}
+		if err := p.add(ctx, i, batch, sel, uint16(len(sel))); err != nil {
+			return err
+		}
+	}
+	return nil
+}
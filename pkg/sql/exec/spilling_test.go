@@ -0,0 +1,132 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package exec
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// newTestSpillingConfig returns a SpillingConfig with a memory budget of
+// memLimit bytes that is backed by a temporary engine, along with a function
+// that releases it.
+func newTestSpillingConfig(
+	ctx context.Context, t *testing.T, memLimit int64,
+) (SpillingConfig, func()) {
+	st := cluster.MakeTestingClusterSettings()
+	tempEngine, err := engine.NewTempEngine(base.DefaultTestTempStorageConfig(st), base.DefaultTestStoreSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	memMonitor := mon.MakeMonitorWithLimit(
+		"test-mem",
+		mon.MemoryResource,
+		memLimit,
+		nil, /* curCount */
+		nil, /* maxHist */
+		1,   /* increment */
+		math.MaxInt64,
+		st,
+	)
+	memMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	diskMonitor := mon.MakeMonitor(
+		"test-disk",
+		mon.DiskResource,
+		nil, /* curCount */
+		nil, /* maxHist */
+		-1,  /* increment: use default block size */
+		math.MaxInt64,
+		st,
+	)
+	diskMonitor.Start(ctx, nil /* pool */, mon.MakeStandaloneBudget(math.MaxInt64))
+	memAcc := memMonitor.MakeBoundAccount()
+	diskAcc := diskMonitor.MakeBoundAccount()
+	cfg := SpillingConfig{
+		MemAcc:         &memAcc,
+		DiskAcc:        &diskAcc,
+		DiskMapFactory: tempEngine,
+	}
+	return cfg, func() {
+		memAcc.Close(ctx)
+		diskAcc.Close(ctx)
+		memMonitor.Stop(ctx)
+		diskMonitor.Stop(ctx)
+		tempEngine.Close()
+	}
+}
+
+func TestSpilledPartitions(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	cfg, cleanup := newTestSpillingConfig(ctx, t, math.MaxInt64)
+	defer cleanup()
+
+	const numPartitions = 3
+	tups := make(tuples, 3000)
+	for i := range tups {
+		tups[i] = tuple{int64(i), int64(i % numPartitions)}
+	}
+	expected := make([]tuples, numPartitions)
+	for _, tup := range tups {
+		expected[tup[1].(int64)] = append(expected[tup[1].(int64)], tup)
+	}
+
+	runTests(t, []tuples{tups}, func(t *testing.T, input []Operator) {
+		typs := []types.T{types.Int64, types.Int64}
+		p, err := newSpilledPartitions(typs, cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer p.close(ctx)
+
+		input[0].Init()
+		for batch := input[0].Next(ctx); batch.Length() > 0; batch = input[0].Next(ctx) {
+			// Add every tuple to the partition given by its second column.
+			col := batch.ColVec(1).Int64()
+			sel := batch.Selection()
+			for i := uint16(0); i < batch.Length(); i++ {
+				idx := i
+				if sel != nil {
+					idx = sel[i]
+				}
+				if err := p.add(ctx, int(col[idx]), batch, []uint16{idx}, 1); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		if err := p.finish(ctx); err != nil {
+			t.Fatal(err)
+		}
+
+		if p.numPartitions() != numPartitions {
+			t.Fatalf("expected %d partitions, found %d", numPartitions, p.numPartitions())
+		}
+		for i := 0; i < numPartitions; i++ {
+			reader, err := p.newReader(i)
+			if err != nil {
+				t.Fatal(err)
+			}
+			out := newOpTestOutput(reader, []int{0, 1}, expected[i])
+			if err := out.Verify(); err != nil {
+				t.Fatal(err)
+			}
+		}
+	})
+}
@@ -26,7 +26,7 @@ func FromColumnType(ct *semtypes.T) types.T {
 	switch ct.Family() {
 	case semtypes.BoolFamily:
 		return types.Bool
-	case semtypes.BytesFamily, semtypes.StringFamily:
+	case semtypes.BytesFamily, semtypes.StringFamily, semtypes.UuidFamily:
 		return types.Bytes
 	case semtypes.DateFamily, semtypes.OidFamily:
 		return types.Int64
@@ -46,6 +46,10 @@ func FromColumnType(ct *semtypes.T) types.T {
 		panic(fmt.Sprintf("integer with unknown width %d", ct.Width()))
 	case semtypes.FloatFamily:
 		return types.Float64
+	case semtypes.TimestampFamily, semtypes.TimestampTZFamily:
+		return types.Timestamp
+	case semtypes.IntervalFamily:
+		return types.Interval
 	}
 	return types.Unhandled
 }
@@ -162,6 +166,38 @@ func GetDatumToPhysicalFn(ct *semtypes.T) func(tree.Datum) (interface{}, error)
 			}
 			return d.Decimal, nil
 		}
+	case semtypes.TimestampFamily:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DTimestamp)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DTimestamp, found %s", reflect.TypeOf(datum))
+			}
+			return d.Time, nil
+		}
+	case semtypes.TimestampTZFamily:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DTimestampTZ)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DTimestampTZ, found %s", reflect.TypeOf(datum))
+			}
+			return d.Time, nil
+		}
+	case semtypes.IntervalFamily:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DInterval)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DInterval, found %s", reflect.TypeOf(datum))
+			}
+			return d.Duration, nil
+		}
+	case semtypes.UuidFamily:
+		return func(datum tree.Datum) (interface{}, error) {
+			d, ok := datum.(*tree.DUuid)
+			if !ok {
+				return nil, errors.Errorf("expected *tree.DUuid, found %s", reflect.TypeOf(datum))
+			}
+			return d.GetBytes(), nil
+		}
 	}
 	panic(fmt.Sprintf("unhandled type %s", ct.DebugString()))
 }
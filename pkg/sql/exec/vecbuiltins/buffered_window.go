@@ -0,0 +1,459 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+	"github.com/pkg/errors"
+)
+
+// windowBuffer contains all the tuples of the input of a bufferedWindowOp
+// along with the information about the partitions and the peer groups they
+// belong to.
+type windowBuffer struct {
+	// typs contains the types of all the columns of the input, including the
+	// partition column if there is one.
+	typs []types.T
+	// cols contains the buffered columns of the input.
+	cols []coldata.Vec
+	// partitionCol, if set, has a true for every tuple that is the first within
+	// its partition.
+	partitionCol []bool
+	// peersCol has a true for every tuple that is the first within its peer
+	// group, i.e. that is distinct on the ordering columns from the previous
+	// tuple or that starts a new partition.
+	peersCol []bool
+	// numTuples is the number of tuples buffered.
+	numTuples uint64
+}
+
+// peerGroups returns the indices of the first tuples of all the peer groups of
+// the partition [start, end) of the buffer followed by end.
+func (b *windowBuffer) peerGroups(start, end uint64) []uint64 {
+	groups := []uint64{start}
+	for i := start + 1; i < end; i++ {
+		if b.peersCol[i] {
+			groups = append(groups, i)
+		}
+	}
+	return append(groups, end)
+}
+
+// bufferedWindowFn is a window function that is computed over partitions that
+// have been fully buffered.
+type bufferedWindowFn interface {
+	// computePartition computes the results of the window function for the
+	// tuples [start, end) of buf, which form a single partition, and writes
+	// them into the same positions of output.
+	computePartition(buf *windowBuffer, start, end uint64, output coldata.Vec)
+}
+
+// BufferingConfig limits the memory used by the window operators that buffer
+// whole partitions of their input.
+type BufferingConfig struct {
+	// MemAcc accounts for the memory used by the buffered partition. If it is
+	// nil, the memory usage is not limited.
+	MemAcc *mon.BoundAccount
+	// Fallback, if set, is called once a partition doesn't fit in MemAcc. It is
+	// passed an operator that returns the rest of the input, starting with the
+	// partition being buffered and without the partition column, and must
+	// return an operator that outputs the same tuples followed by the result of
+	// the window function. If Fallback is nil, an out of memory error is raised
+	// instead.
+	Fallback func(input exec.Operator) (exec.Operator, error)
+}
+
+type bufferedWindowState int
+
+const (
+	// windowBuffering is the state in which the tuples of the current partition
+	// are being buffered.
+	windowBuffering bufferedWindowState = iota
+	// windowEmitting is the state in which the tuples of the fully buffered
+	// partition are being emitted along with the results.
+	windowEmitting
+	// windowFallingBack is the state in which the rest of the input is handled
+	// by the fallback operator.
+	windowFallingBack
+	// windowDone is the state in which all the input has been emitted.
+	windowDone
+)
+
+// bufferedWindowOp is an operator that computes a window function that needs
+// to see the whole partition before it can output its results for any tuple
+// of the partition. It buffers the tuples of one partition at a time,
+// computes the window function over it and then emits the tuples along with
+// the results before moving on to the next partition. If a partition doesn't
+// fit in the memory budget, the rest of the input is handed to a fallback
+// operator.
+type bufferedWindowOp struct {
+	input exec.Operator
+	// distinctCol is the output column of the chain of ordered distinct
+	// operators on the ordering columns. It is nil if there are no ordering
+	// columns, in which case all the tuples of a partition are peers.
+	distinctCol     []bool
+	partitionColIdx int
+	outputType      types.T
+	fn              bufferedWindowFn
+	cfg             BufferingConfig
+
+	state bufferedWindowState
+	// buffer contains the tuples of the current partition.
+	buffer windowBuffer
+	// pending is the last batch read from the input, whose tuples starting at
+	// pendingIdx have not been buffered yet.
+	pending    coldata.Batch
+	pendingIdx uint16
+	// inputDone is set once the input has returned a zero-length batch.
+	inputDone bool
+	// output contains the results of the window function for the buffered
+	// partition.
+	output coldata.Vec
+	// emitted is the number of tuples of the buffered partition that have
+	// already been emitted.
+	emitted uint64
+	// fallback is the operator that handles the rest of the input once a
+	// partition didn't fit in memory.
+	fallback exec.Operator
+	batch    coldata.Batch
+}
+
+var _ exec.Operator = &bufferedWindowOp{}
+
+// newBufferedWindowOp creates a new bufferedWindowOp that computes fn over
+// input, which must already be ordered on the partition columns first and the
+// orderingCols second. inputTypes are the types of input without the partition
+// column which, if partitionColIdx is not -1, is expected right after them.
+// The results of fn, of type outputType, are put in outputColIdx'th column,
+// which must be the next to be appended.
+func newBufferedWindowOp(
+	input exec.Operator,
+	inputTypes []types.T,
+	orderingCols []uint32,
+	outputColIdx int,
+	partitionColIdx int,
+	outputType types.T,
+	fn bufferedWindowFn,
+	cfg BufferingConfig,
+) (exec.Operator, error) {
+	typs := inputTypes
+	if partitionColIdx != -1 {
+		if partitionColIdx != len(inputTypes) {
+			return nil, errors.Errorf("unexpected partition column index %d", partitionColIdx)
+		}
+		typs = append(typs[:len(typs):len(typs)], types.Bool)
+	}
+	if outputColIdx != len(typs) {
+		return nil, errors.Errorf("unexpected output column index %d", outputColIdx)
+	}
+	var distinctCol []bool
+	if len(orderingCols) > 0 {
+		var err error
+		input, distinctCol, err = exec.OrderedDistinctColsToOperators(input, orderingCols, inputTypes)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return &bufferedWindowOp{
+		input:           input,
+		distinctCol:     distinctCol,
+		partitionColIdx: partitionColIdx,
+		outputType:      outputType,
+		fn:              fn,
+		cfg:             cfg,
+		buffer:          windowBuffer{typs: typs},
+	}, nil
+}
+
+func (w *bufferedWindowOp) Init() {
+	w.input.Init()
+	w.resetBuffer()
+	w.batch = coldata.NewMemBatch(append(w.buffer.typs[:len(w.buffer.typs):len(w.buffer.typs)], w.outputType))
+}
+
+func (w *bufferedWindowOp) Next(ctx context.Context) coldata.Batch {
+	for {
+		switch w.state {
+		case windowBuffering:
+			if err := w.bufferPartition(ctx); err != nil {
+				if w.cfg.Fallback == nil {
+					panic(err)
+				}
+				w.startFallback()
+				continue
+			}
+			if w.buffer.numTuples == 0 {
+				w.state = windowDone
+				continue
+			}
+			w.computeOutput()
+			w.state = windowEmitting
+		case windowEmitting:
+			if w.emitted == w.buffer.numTuples {
+				w.resetBuffer()
+				if w.cfg.MemAcc != nil {
+					w.cfg.MemAcc.Clear(ctx)
+				}
+				w.state = windowBuffering
+				continue
+			}
+			return w.emit()
+		case windowFallingBack:
+			return w.emitFallback(ctx)
+		case windowDone:
+			w.batch.SetLength(0)
+			return w.batch
+		default:
+			panic(fmt.Sprintf("unexpected bufferedWindowState %d", w.state))
+		}
+	}
+}
+
+// bufferPartition buffers the tuples of the next partition of the input. It
+// leaves the buffer empty if the input has been exhausted. An out of memory
+// error is returned if the partition doesn't fit in the memory account, in
+// which case the tuples that couldn't be buffered are left pending.
+func (w *bufferedWindowOp) bufferPartition(ctx context.Context) error {
+	b := &w.buffer
+	for !w.inputDone {
+		if w.pending == nil || w.pendingIdx == w.pending.Length() {
+			w.pending = w.input.Next(ctx)
+			w.pendingIdx = 0
+			if w.pending.Length() == 0 {
+				w.inputDone = true
+				break
+			}
+		}
+		sel := w.pending.Selection()
+		n := w.pending.Length()
+		var partitionCol []bool
+		if w.partitionColIdx != -1 {
+			partitionCol = w.pending.ColVec(w.partitionColIdx).Bool()
+		}
+		// Find the end of the current partition within the pending tuples.
+		end := w.pendingIdx
+		for ; end < n; end++ {
+			idx := end
+			if sel != nil {
+				idx = sel[end]
+			}
+			if partitionCol != nil && partitionCol[idx] && (b.numTuples > 0 || end > w.pendingIdx) {
+				break
+			}
+		}
+		if w.cfg.MemAcc != nil {
+			if err := w.cfg.MemAcc.Grow(
+				ctx, exec.TuplesMemSize(b.typs, w.pending, sel, w.pendingIdx, end),
+			); err != nil {
+				return err
+			}
+		}
+		w.appendPending(end)
+		if end < n {
+			// The next partition starts within the pending batch.
+			break
+		}
+	}
+	return nil
+}
+
+// appendPending appends the pending tuples up to end to the buffer.
+func (w *bufferedWindowOp) appendPending(end uint16) {
+	b := &w.buffer
+	sel := w.pending.Selection()
+	for i, t := range b.typs {
+		b.cols[i].Append(coldata.AppendArgs{
+			ColType:     t,
+			Src:         w.pending.ColVec(i),
+			Sel:         sel,
+			DestIdx:     b.numTuples,
+			SrcStartIdx: w.pendingIdx,
+			SrcEndIdx:   end,
+		})
+	}
+	for i := w.pendingIdx; i < end; i++ {
+		idx := i
+		if sel != nil {
+			idx = sel[i]
+		}
+		// The first tuple of every partition starts a new peer group.
+		b.peersCol = append(b.peersCol, len(b.peersCol) == 0 || (w.distinctCol != nil && w.distinctCol[idx]))
+	}
+	b.numTuples += uint64(end - w.pendingIdx)
+	w.pendingIdx = end
+}
+
+// resetBuffer drops the buffered partition.
+func (w *bufferedWindowOp) resetBuffer() {
+	b := &w.buffer
+	b.cols = make([]coldata.Vec, len(b.typs))
+	for i, t := range b.typs {
+		b.cols[i] = coldata.NewMemColumn(t, 0)
+	}
+	b.partitionCol = nil
+	b.peersCol = nil
+	b.numTuples = 0
+	w.output = nil
+	w.emitted = 0
+}
+
+// computeOutput computes the window function over the buffered partition.
+func (w *bufferedWindowOp) computeOutput() {
+	b := &w.buffer
+	if w.partitionColIdx != -1 {
+		b.partitionCol = b.cols[w.partitionColIdx].Bool()
+	}
+	w.output = coldata.NewMemColumn(w.outputType, int(b.numTuples))
+	w.fn.computePartition(b, 0, b.numTuples, w.output)
+}
+
+// emit returns the next batch of the buffered partition along with the
+// results.
+func (w *bufferedWindowOp) emit() coldata.Batch {
+	n := w.buffer.numTuples - w.emitted
+	if n > uint64(coldata.BatchSize) {
+		n = uint64(coldata.BatchSize)
+	}
+	for i, t := range w.buffer.typs {
+		w.batch.ColVec(i).Copy(coldata.CopyArgs{
+			ColType:     t,
+			Src:         w.buffer.cols[i],
+			SrcStartIdx: w.emitted,
+			SrcEndIdx:   w.emitted + n,
+		})
+	}
+	w.batch.ColVec(len(w.buffer.typs)).Copy(coldata.CopyArgs{
+		ColType:     w.outputType,
+		Src:         w.output,
+		SrcStartIdx: w.emitted,
+		SrcEndIdx:   w.emitted + n,
+	})
+	w.batch.SetLength(uint16(n))
+	w.emitted += n
+	return w.batch
+}
+
+// startFallback hands the partition being buffered, the pending tuples and
+// the rest of the input to the fallback operator.
+func (w *bufferedWindowOp) startFallback() {
+	// The pending tuples are appended to the buffer without accounting for
+	// them, since the buffer is only kept until it has been replayed.
+	w.appendPending(w.pending.Length())
+	var input exec.Operator = &windowReplayOp{
+		buffer:  &w.buffer,
+		input:   w.input,
+		memAcc:  w.cfg.MemAcc,
+		inputOk: !w.inputDone,
+		batch:   coldata.NewMemBatch(w.buffer.typs),
+	}
+	if w.partitionColIdx != -1 {
+		projection := make([]uint32, w.partitionColIdx)
+		for i := range projection {
+			projection[i] = uint32(i)
+		}
+		input = exec.NewSimpleProjectOp(input, projection)
+	}
+	fallback, err := w.cfg.Fallback(input)
+	if err != nil {
+		panic(err)
+	}
+	w.fallback = fallback
+	w.state = windowFallingBack
+}
+
+// emitFallback returns the next batch of the fallback operator, whose output
+// doesn't have the partition column, laid out like the output of w.
+func (w *bufferedWindowOp) emitFallback(ctx context.Context) coldata.Batch {
+	batch := w.fallback.Next(ctx)
+	n := batch.Length()
+	if n == 0 {
+		w.state = windowDone
+		w.batch.SetLength(0)
+		return w.batch
+	}
+	sel := batch.Selection()
+	srcIdx := 0
+	for i := range w.batch.ColVecs() {
+		if i == w.partitionColIdx {
+			// The partition column is projected out above this operator.
+			continue
+		}
+		typ := w.outputType
+		if i < len(w.buffer.typs) {
+			typ = w.buffer.typs[i]
+		}
+		w.batch.ColVec(i).Copy(coldata.CopyArgs{
+			ColType:   typ,
+			Src:       batch.ColVec(srcIdx),
+			Sel:       sel,
+			SrcEndIdx: uint64(n),
+		})
+		srcIdx++
+	}
+	w.batch.SetLength(n)
+	return w.batch
+}
+
+// windowReplayOp is the input of the fallback of a bufferedWindowOp. It
+// returns the buffered tuples followed by the rest of the input, which has
+// already been initialized.
+type windowReplayOp struct {
+	buffer *windowBuffer
+	input  exec.Operator
+	memAcc *mon.BoundAccount
+	// inputOk is false if the input has already been exhausted.
+	inputOk  bool
+	replayed uint64
+	batch    coldata.Batch
+}
+
+var _ exec.Operator = &windowReplayOp{}
+
+func (r *windowReplayOp) Init() {}
+
+func (r *windowReplayOp) Next(ctx context.Context) coldata.Batch {
+	if r.buffer != nil {
+		if r.replayed < r.buffer.numTuples {
+			n := r.buffer.numTuples - r.replayed
+			if n > uint64(coldata.BatchSize) {
+				n = uint64(coldata.BatchSize)
+			}
+			for i, t := range r.buffer.typs {
+				r.batch.ColVec(i).Copy(coldata.CopyArgs{
+					ColType:     t,
+					Src:         r.buffer.cols[i],
+					SrcStartIdx: r.replayed,
+					SrcEndIdx:   r.replayed + n,
+				})
+			}
+			r.batch.SetLength(uint16(n))
+			r.replayed += n
+			return r.batch
+		}
+		// The buffered tuples have all been replayed, so their memory can be
+		// released.
+		r.buffer = nil
+		if r.memAcc != nil {
+			r.memAcc.Clear(ctx)
+		}
+	}
+	if !r.inputOk {
+		r.batch.SetLength(0)
+		return r.batch
+	}
+	return r.input.Next(ctx)
+}
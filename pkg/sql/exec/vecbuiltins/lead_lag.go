@@ -0,0 +1,101 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/pkg/errors"
+)
+
+// NewLeadLagOperator creates a new exec.Operator that computes the lead (if
+// forward is true) or the lag window function. argColIdxs contain the indices
+// of the value column, optionally followed by the offset column and the
+// default value column. The value of the tuple that is the offset number of
+// tuples after (or before for lag) the current one within the partition is put
+// in outputColIdx'th column. The input must be ordered on the partition
+// columns first and orderingCols second.
+func NewLeadLagOperator(
+	input exec.Operator,
+	inputTypes []types.T,
+	orderingCols []uint32,
+	forward bool,
+	argColIdxs []uint32,
+	outputColIdx int,
+	partitionColIdx int,
+	cfg BufferingConfig,
+) (exec.Operator, error) {
+	if len(argColIdxs) == 0 || len(argColIdxs) > 3 {
+		return nil, errors.Errorf("unexpected number of arguments %d", len(argColIdxs))
+	}
+	f := leadLagFn{
+		forward:       forward,
+		valueColIdx:   int(argColIdxs[0]),
+		offsetColIdx:  -1,
+		defaultColIdx: -1,
+		typ:           inputTypes[argColIdxs[0]],
+	}
+	if len(argColIdxs) > 1 {
+		f.offsetColIdx = int(argColIdxs[1])
+		if inputTypes[f.offsetColIdx] != types.Int64 {
+			return nil, errors.Errorf("unsupported offset type %s", inputTypes[f.offsetColIdx])
+		}
+	}
+	if len(argColIdxs) > 2 {
+		f.defaultColIdx = int(argColIdxs[2])
+		if inputTypes[f.defaultColIdx] != f.typ {
+			return nil, errors.Errorf(
+				"default value of type %s doesn't match %s", inputTypes[f.defaultColIdx], f.typ,
+			)
+		}
+	}
+	return newBufferedWindowOp(
+		input, inputTypes, orderingCols, outputColIdx, partitionColIdx, f.typ, f, cfg,
+	)
+}
+
+type leadLagFn struct {
+	forward bool
+	// offsetColIdx and defaultColIdx are -1 when the corresponding arguments
+	// are omitted, in which case the offset is 1 and the default is NULL.
+	valueColIdx, offsetColIdx, defaultColIdx int
+	typ                                      types.T
+}
+
+func (f leadLagFn) computePartition(buf *windowBuffer, start, end uint64, output coldata.Vec) {
+	value := buf.cols[f.valueColIdx]
+	for i := start; i < end; i++ {
+		offset := int64(1)
+		if f.offsetColIdx != -1 {
+			offsetCol := buf.cols[f.offsetColIdx]
+			if offsetCol.Nulls().NullAt64(i) {
+				output.Nulls().SetNull64(i)
+				continue
+			}
+			offset = offsetCol.Int64()[i]
+		}
+		if !f.forward {
+			offset = -offset
+		}
+		if target := int64(i-start) + offset; target < 0 || target >= int64(end-start) {
+			// The target tuple is out of the partition, so we supply the default
+			// value if provided and NULL otherwise.
+			if f.defaultColIdx != -1 {
+				copyValue(f.typ, output, i, buf.cols[f.defaultColIdx], i)
+			} else {
+				output.Nulls().SetNull64(i)
+			}
+			continue
+		}
+		copyValue(f.typ, output, i, value, uint64(int64(i)+offset))
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/pkg/errors"
+)
+
+var errInvalidArgumentForNtile = pgerror.Newf(
+	pgcode.InvalidParameterValue, "argument of ntile() must be greater than zero")
+
+// NewNTileOperator creates a new exec.Operator that computes the ntile window
+// function, which divides the partition into argColIdx'th column number of
+// buckets as equally as possible, and puts the number of the bucket of every
+// tuple (of type types.Int64) in outputColIdx'th column. The input must be
+// ordered on the partition columns first and orderingCols second.
+func NewNTileOperator(
+	input exec.Operator,
+	inputTypes []types.T,
+	orderingCols []uint32,
+	argColIdx int,
+	outputColIdx int,
+	partitionColIdx int,
+	cfg BufferingConfig,
+) (exec.Operator, error) {
+	if inputTypes[argColIdx] != types.Int64 {
+		return nil, errors.Errorf("unsupported ntile argument type %s", inputTypes[argColIdx])
+	}
+	return newBufferedWindowOp(
+		input, inputTypes, orderingCols, outputColIdx, partitionColIdx, types.Int64, ntileFn{argColIdx: argColIdx}, cfg,
+	)
+}
+
+type ntileFn struct {
+	argColIdx int
+}
+
+func (f ntileFn) computePartition(buf *windowBuffer, start, end uint64, output coldata.Vec) {
+	arg := buf.cols[f.argColIdx]
+	col := output.Int64()
+	total := int64(end - start)
+	var ntile, boundary, remainder, curBucketCount int64
+	i := start
+	// The number of buckets is determined by the first tuple of the partition
+	// with a non-null argument. All tuples before it get NULL.
+	for ; i < end; i++ {
+		if arg.Nulls().NullAt64(i) {
+			output.Nulls().SetNull64(i)
+			continue
+		}
+		nbuckets := arg.Int64()[i]
+		if nbuckets <= 0 {
+			panic(errInvalidArgumentForNtile)
+		}
+		ntile = 1
+		boundary = total / nbuckets
+		if boundary <= 0 {
+			boundary = 1
+		} else {
+			// If the total number is not divisible, add 1 tuple to leading buckets.
+			remainder = total % nbuckets
+			if remainder != 0 {
+				boundary++
+			}
+		}
+		break
+	}
+	for ; i < end; i++ {
+		curBucketCount++
+		if boundary < curBucketCount {
+			// Move to the next bucket.
+			if remainder != 0 && ntile == remainder {
+				remainder = 0
+				boundary--
+			}
+			ntile++
+			curBucketCount = 1
+		}
+		col[i] = ntile
+	}
+}
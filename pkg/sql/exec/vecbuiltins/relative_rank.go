@@ -0,0 +1,87 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+)
+
+// NewPercentRankOperator creates a new exec.Operator that computes the
+// percent_rank window function, i.e. (rank - 1) / (number of tuples in the
+// partition - 1), and puts its output (of type types.Float64) in
+// outputColIdx'th column. The input must be ordered on the partition columns
+// first and orderingCols second.
+func NewPercentRankOperator(
+	input exec.Operator,
+	inputTypes []types.T,
+	orderingCols []uint32,
+	outputColIdx int,
+	partitionColIdx int,
+	cfg BufferingConfig,
+) (exec.Operator, error) {
+	return newBufferedWindowOp(
+		input, inputTypes, orderingCols, outputColIdx, partitionColIdx, types.Float64, percentRankFn{}, cfg,
+	)
+}
+
+// NewCumeDistOperator creates a new exec.Operator that computes the cume_dist
+// window function, i.e. (number of tuples preceding or peer with the current
+// tuple) / (number of tuples in the partition), and puts its output (of type
+// types.Float64) in outputColIdx'th column. The input must be ordered on the
+// partition columns first and orderingCols second.
+func NewCumeDistOperator(
+	input exec.Operator,
+	inputTypes []types.T,
+	orderingCols []uint32,
+	outputColIdx int,
+	partitionColIdx int,
+	cfg BufferingConfig,
+) (exec.Operator, error) {
+	return newBufferedWindowOp(
+		input, inputTypes, orderingCols, outputColIdx, partitionColIdx, types.Float64, cumeDistFn{}, cfg,
+	)
+}
+
+type percentRankFn struct{}
+
+func (percentRankFn) computePartition(buf *windowBuffer, start, end uint64, output coldata.Vec) {
+	col := output.Float64()
+	if end-start <= 1 {
+		// The only tuple of the partition gets zero.
+		for i := start; i < end; i++ {
+			col[i] = 0
+		}
+		return
+	}
+	groups := buf.peerGroups(start, end)
+	for g := 0; g < len(groups)-1; g++ {
+		// All peers share the rank of the first tuple of their peer group.
+		percentRank := float64(groups[g]-start) / float64(end-start-1)
+		for i := groups[g]; i < groups[g+1]; i++ {
+			col[i] = percentRank
+		}
+	}
+}
+
+type cumeDistFn struct{}
+
+func (cumeDistFn) computePartition(buf *windowBuffer, start, end uint64, output coldata.Vec) {
+	col := output.Float64()
+	groups := buf.peerGroups(start, end)
+	for g := 0; g < len(groups)-1; g++ {
+		cumeDist := float64(groups[g+1]-start) / float64(end-start)
+		for i := groups[g]; i < groups[g+1]; i++ {
+			col[i] = cumeDist
+		}
+	}
+}
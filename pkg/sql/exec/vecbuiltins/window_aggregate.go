@@ -0,0 +1,325 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"github.com/cockroachdb/apd"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/pkg/errors"
+)
+
+// NewWindowAggregateOperator creates a new exec.Operator that computes the
+// aggregate function aggFn over the window frame (described by frame which can
+// be nil for the default frame) of every tuple and puts the result in
+// outputColIdx'th column. argColIdxs contain the indices of the arguments of
+// the aggregate function. The input must be ordered on the partition columns
+// first and orderingCols second.
+//
+// The supported aggregate functions are COUNT_ROWS, COUNT, SUM and AVG over
+// integers, decimals and floats, as well as MIN and MAX over all types.
+func NewWindowAggregateOperator(
+	input exec.Operator,
+	inputTypes []types.T,
+	orderingCols []uint32,
+	aggFn distsqlpb.AggregatorSpec_Func,
+	argColIdxs []uint32,
+	frame *distsqlpb.WindowerSpec_Frame,
+	outputColIdx int,
+	partitionColIdx int,
+	cfg BufferingConfig,
+) (exec.Operator, error) {
+	wf, err := newWindowFrame(frame)
+	if err != nil {
+		return nil, err
+	}
+	if aggFn == distsqlpb.AggregatorSpec_COUNT_ROWS {
+		if len(argColIdxs) != 0 {
+			return nil, errors.Errorf("unexpected number of arguments %d", len(argColIdxs))
+		}
+		return newBufferedWindowOp(
+			input, inputTypes, orderingCols, outputColIdx, partitionColIdx, types.Int64,
+			&windowAggregateFn{frame: wf, agg: &countWindowAgg{argColIdx: -1}, perPeerGroup: true},
+			cfg,
+		)
+	}
+	if len(argColIdxs) != 1 {
+		return nil, errors.Errorf("unexpected number of arguments %d", len(argColIdxs))
+	}
+	argColIdx := int(argColIdxs[0])
+	argType := inputTypes[argColIdx]
+	f := &windowAggregateFn{frame: wf}
+	var outputType types.T
+	switch aggFn {
+	case distsqlpb.AggregatorSpec_COUNT:
+		// COUNT follows the behavior of the row execution engine, which computes
+		// it only for the first tuple of each peer group.
+		f.agg, f.perPeerGroup = &countWindowAgg{argColIdx: argColIdx}, true
+		outputType = types.Int64
+	case distsqlpb.AggregatorSpec_SUM, distsqlpb.AggregatorSpec_AVG:
+		agg := &sumWindowAgg{argColIdx: argColIdx, argType: argType, avg: aggFn == distsqlpb.AggregatorSpec_AVG}
+		switch argType {
+		case types.Int8, types.Int16, types.Int32, types.Int64, types.Decimal:
+			outputType = types.Decimal
+		case types.Float64:
+			outputType = types.Float64
+		default:
+			return nil, errors.Errorf("unsupported %s argument type %s", aggFn, argType)
+		}
+		f.agg = agg
+	case distsqlpb.AggregatorSpec_MIN, distsqlpb.AggregatorSpec_MAX:
+		f.agg = &minMaxWindowAgg{argColIdx: argColIdx, argType: argType, isMax: aggFn == distsqlpb.AggregatorSpec_MAX}
+		outputType = argType
+	default:
+		return nil, errors.Errorf("aggregate function %s is not supported as a window function", aggFn)
+	}
+	return newBufferedWindowOp(
+		input, inputTypes, orderingCols, outputColIdx, partitionColIdx, outputType, f, cfg,
+	)
+}
+
+// windowAggregator is an aggregate function computed over a window frame that
+// slides forward, i.e. whose start and end never go back.
+type windowAggregator interface {
+	// init prepares the aggregator for the partition of buf.
+	init(buf *windowBuffer)
+	// add adds the i'th tuple of the buffer, which has just entered the frame,
+	// to the aggregation.
+	add(i uint64)
+	// remove removes the i'th tuple of the buffer, which has just left the
+	// frame, from the aggregation.
+	remove(i uint64)
+	// setResult sets the i'th value of output to the result of the aggregation.
+	setResult(output coldata.Vec, i uint64)
+}
+
+type windowAggregateFn struct {
+	frame *windowFrame
+	agg   windowAggregator
+	// perPeerGroup indicates that the result is computed only over the frame
+	// of the first tuple of each peer group and is shared by all the peers.
+	perPeerGroup bool
+}
+
+func (f *windowAggregateFn) computePartition(
+	buf *windowBuffer, start, end uint64, output coldata.Vec,
+) {
+	f.agg.init(buf)
+	groups := buf.peerGroups(start, end)
+	// prevStart and prevEnd are the bounds of the frame of the previous tuple,
+	// all tuples of which have been added to the aggregation.
+	prevStart, prevEnd := start, start
+	for g := 0; g < len(groups)-1; g++ {
+		for i := groups[g]; i < groups[g+1]; i++ {
+			if f.perPeerGroup && i != groups[g] {
+				copyValue(output.Type(), output, i, output, groups[g])
+				continue
+			}
+			frameStart, frameEnd := f.frame.bounds(groups, g, i)
+			// We need to discard all values that are no longer in the frame.
+			for j := prevStart; j < frameStart && j < prevEnd; j++ {
+				f.agg.remove(j)
+			}
+			// We need to add all values that just entered the frame and have not
+			// been added yet.
+			j := prevEnd
+			if j < frameStart {
+				j = frameStart
+			}
+			for ; j < frameEnd; j++ {
+				f.agg.add(j)
+			}
+			prevStart, prevEnd = frameStart, frameEnd
+			f.agg.setResult(output, i)
+		}
+	}
+}
+
+// countWindowAgg counts the non-null values of argColIdx'th column or, if
+// argColIdx is -1, all tuples.
+type countWindowAgg struct {
+	argColIdx int
+	arg       coldata.Vec
+	count     int64
+}
+
+func (a *countWindowAgg) init(buf *windowBuffer) {
+	if a.argColIdx != -1 {
+		a.arg = buf.cols[a.argColIdx]
+	}
+	a.count = 0
+}
+
+func (a *countWindowAgg) add(i uint64) {
+	if a.arg == nil || !a.arg.Nulls().NullAt64(i) {
+		a.count++
+	}
+}
+
+func (a *countWindowAgg) remove(i uint64) {
+	if a.arg == nil || !a.arg.Nulls().NullAt64(i) {
+		a.count--
+	}
+}
+
+func (a *countWindowAgg) setResult(output coldata.Vec, i uint64) {
+	output.Int64()[i] = a.count
+}
+
+// sumWindowAgg computes the sum or, if avg is set, the average of the non-null
+// values of argColIdx'th column. Integers are summed up as decimals.
+type sumWindowAgg struct {
+	argColIdx int
+	argType   types.T
+	avg       bool
+
+	arg coldata.Vec
+	// count is the number of non-null values within the frame.
+	count      int64
+	decimalSum apd.Decimal
+	floatSum   float64
+	scratch    apd.Decimal
+}
+
+func (a *sumWindowAgg) init(buf *windowBuffer) {
+	a.arg = buf.cols[a.argColIdx]
+	a.count = 0
+	a.decimalSum.SetFinite(0, 0)
+	a.floatSum = 0
+}
+
+func (a *sumWindowAgg) add(i uint64) {
+	a.addWithSign(i, false /* negate */)
+}
+
+func (a *sumWindowAgg) remove(i uint64) {
+	// The values leaving the frame are subtracted by adding their negation,
+	// the same way as the row execution engine does.
+	a.addWithSign(i, true /* negate */)
+}
+
+func (a *sumWindowAgg) addWithSign(i uint64, negate bool) {
+	if a.arg.Nulls().NullAt64(i) {
+		return
+	}
+	if negate {
+		a.count--
+	} else {
+		a.count++
+	}
+	if a.argType == types.Float64 {
+		v := a.arg.Float64()[i]
+		if negate {
+			v = -v
+		}
+		a.floatSum += v
+		return
+	}
+	switch a.argType {
+	case types.Int8:
+		a.scratch.SetFinite(int64(a.arg.Int8()[i]), 0)
+	case types.Int16:
+		a.scratch.SetFinite(int64(a.arg.Int16()[i]), 0)
+	case types.Int32:
+		a.scratch.SetFinite(int64(a.arg.Int32()[i]), 0)
+	case types.Int64:
+		a.scratch.SetFinite(a.arg.Int64()[i], 0)
+	default:
+		a.scratch.Set(&a.arg.Decimal()[i])
+	}
+	if negate {
+		a.scratch.Neg(&a.scratch)
+	}
+	if _, err := tree.ExactCtx.Add(&a.decimalSum, &a.decimalSum, &a.scratch); err != nil {
+		panic(err)
+	}
+}
+
+func (a *sumWindowAgg) setResult(output coldata.Vec, i uint64) {
+	if a.count == 0 {
+		// Either the frame is empty or it contains only nulls.
+		output.Nulls().SetNull64(i)
+		return
+	}
+	if a.argType == types.Float64 {
+		res := a.floatSum
+		if a.avg {
+			res /= float64(a.count)
+		}
+		output.Float64()[i] = res
+		return
+	}
+	res := &output.Decimal()[i]
+	if !a.avg {
+		res.Set(&a.decimalSum)
+		return
+	}
+	if _, err := tree.DecimalCtx.Quo(res, &a.decimalSum, apd.New(a.count, 0)); err != nil {
+		panic(err)
+	}
+}
+
+// minMaxWindowAgg computes the minimum or, if isMax is set, the maximum of the
+// non-null values of argColIdx'th column. It maintains a deque of the indices
+// of the values within the frame that can still become the result, sorted in
+// the order of their "priority".
+type minMaxWindowAgg struct {
+	argColIdx int
+	argType   types.T
+	isMax     bool
+
+	arg   coldata.Vec
+	cmp   func(i, j uint64) int
+	deque []uint64
+}
+
+func (a *minMaxWindowAgg) init(buf *windowBuffer) {
+	a.arg = buf.cols[a.argColIdx]
+	cmp := makeComparator(a.argType, a.arg)
+	if a.isMax {
+		a.cmp = cmp
+	} else {
+		a.cmp = func(i, j uint64) int { return -cmp(i, j) }
+	}
+	a.deque = a.deque[:0]
+}
+
+func (a *minMaxWindowAgg) add(i uint64) {
+	if a.arg.Nulls().NullAt64(i) {
+		// Null values can neither be the minimum nor the maximum of a frame with
+		// non-null values.
+		return
+	}
+	// All values with lower or equal priority can never become the result
+	// anymore, so they are removed from the back of the deque.
+	for len(a.deque) > 0 && a.cmp(a.deque[len(a.deque)-1], i) <= 0 {
+		a.deque = a.deque[:len(a.deque)-1]
+	}
+	a.deque = append(a.deque, i)
+}
+
+func (a *minMaxWindowAgg) remove(i uint64) {
+	if len(a.deque) > 0 && a.deque[0] == i {
+		a.deque = a.deque[1:]
+	}
+}
+
+func (a *minMaxWindowAgg) setResult(output coldata.Vec, i uint64) {
+	if len(a.deque) == 0 {
+		// The frame is either empty or contains only nulls.
+		output.Nulls().SetNull64(i)
+		return
+	}
+	copyValue(a.argType, output, i, a.arg, a.deque[0])
+}
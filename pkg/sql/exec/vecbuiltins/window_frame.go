@@ -0,0 +1,129 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlpb"
+	"github.com/pkg/errors"
+)
+
+// defaultWindowFrame is the frame used when none is specified, i.e. RANGE
+// BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW.
+var defaultWindowFrame = distsqlpb.WindowerSpec_Frame{
+	Mode: distsqlpb.WindowerSpec_Frame_RANGE,
+	Bounds: distsqlpb.WindowerSpec_Frame_Bounds{
+		Start: distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING},
+		End:   &distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_CURRENT_ROW},
+	},
+}
+
+// windowFrame computes the bounds of the window frame of every tuple of a
+// partition.
+type windowFrame struct {
+	mode       distsqlpb.WindowerSpec_Frame_Mode
+	start, end distsqlpb.WindowerSpec_Frame_Bound
+}
+
+// newWindowFrame returns a windowFrame for the given frame spec which can be
+// nil, in which case the default frame is used.
+func newWindowFrame(frame *distsqlpb.WindowerSpec_Frame) (*windowFrame, error) {
+	if frame == nil {
+		frame = &defaultWindowFrame
+	}
+	f := &windowFrame{
+		mode:  frame.Mode,
+		start: frame.Bounds.Start,
+		end:   distsqlpb.WindowerSpec_Frame_Bound{BoundType: distsqlpb.WindowerSpec_Frame_CURRENT_ROW},
+	}
+	if frame.Bounds.End != nil {
+		f.end = *frame.Bounds.End
+	}
+	if f.mode == distsqlpb.WindowerSpec_Frame_RANGE && (isOffsetBound(f.start) || isOffsetBound(f.end)) {
+		return nil, errors.New("RANGE mode with offsets is not supported")
+	}
+	return f, nil
+}
+
+func isOffsetBound(b distsqlpb.WindowerSpec_Frame_Bound) bool {
+	return b.BoundType == distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING ||
+		b.BoundType == distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING
+}
+
+// bounds returns the window frame [start, end) of the i'th tuple of the
+// partition described by peerGroups (see windowBuffer.peerGroups). g is the
+// index of the peer group the tuple belongs to.
+func (f *windowFrame) bounds(peerGroups []uint64, g int, i uint64) (uint64, uint64) {
+	start := f.boundIdx(f.start, false /* isEnd */, peerGroups, g, i)
+	end := f.boundIdx(f.end, true /* isEnd */, peerGroups, g, i)
+	if end < start {
+		// The frame is empty.
+		end = start
+	}
+	return start, end
+}
+
+// boundIdx returns the index of the tuple at which the frame of the i'th tuple
+// starts or, if isEnd is set, the index right after the last tuple of the
+// frame.
+func (f *windowFrame) boundIdx(
+	b distsqlpb.WindowerSpec_Frame_Bound, isEnd bool, peerGroups []uint64, g int, i uint64,
+) uint64 {
+	partitionStart, partitionEnd := peerGroups[0], peerGroups[len(peerGroups)-1]
+	switch b.BoundType {
+	case distsqlpb.WindowerSpec_Frame_UNBOUNDED_PRECEDING:
+		return partitionStart
+	case distsqlpb.WindowerSpec_Frame_UNBOUNDED_FOLLOWING:
+		return partitionEnd
+	}
+	if f.mode == distsqlpb.WindowerSpec_Frame_ROWS {
+		if isEnd {
+			// The end index is exclusive.
+			i++
+		}
+		switch b.BoundType {
+		case distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING:
+			if i-partitionStart < b.IntOffset {
+				return partitionStart
+			}
+			return i - b.IntOffset
+		case distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING:
+			if partitionEnd-i < b.IntOffset {
+				return partitionEnd
+			}
+			return i + b.IntOffset
+		default:
+			return i
+		}
+	}
+	// In RANGE and GROUPS modes the frame is made of whole peer groups.
+	numGroups := uint64(len(peerGroups) - 1)
+	group := uint64(g)
+	switch b.BoundType {
+	case distsqlpb.WindowerSpec_Frame_OFFSET_PRECEDING:
+		if group < b.IntOffset {
+			if isEnd {
+				return partitionStart
+			}
+			group = 0
+		} else {
+			group -= b.IntOffset
+		}
+	case distsqlpb.WindowerSpec_Frame_OFFSET_FOLLOWING:
+		if numGroups-group <= b.IntOffset {
+			return partitionEnd
+		}
+		group += b.IntOffset
+	}
+	if isEnd {
+		group++
+	}
+	return peerGroups[group]
+}
@@ -0,0 +1,128 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package vecbuiltins
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/coldata"
+	"github.com/cockroachdb/cockroach/pkg/sql/exec/types"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// copyValue sets the dstIdx'th value of dst to the srcIdx'th value of src,
+// including its nullity. Both vecs must be of type t.
+func copyValue(t types.T, dst coldata.Vec, dstIdx uint64, src coldata.Vec, srcIdx uint64) {
+	if src.Nulls().NullAt64(srcIdx) {
+		dst.Nulls().SetNull64(dstIdx)
+		return
+	}
+	switch t {
+	case types.Bool:
+		dst.Bool()[dstIdx] = src.Bool()[srcIdx]
+	case types.Bytes:
+		dst.Bytes()[dstIdx] = src.Bytes()[srcIdx]
+	case types.Decimal:
+		dst.Decimal()[dstIdx].Set(&src.Decimal()[srcIdx])
+	case types.Int8:
+		dst.Int8()[dstIdx] = src.Int8()[srcIdx]
+	case types.Int16:
+		dst.Int16()[dstIdx] = src.Int16()[srcIdx]
+	case types.Int32:
+		dst.Int32()[dstIdx] = src.Int32()[srcIdx]
+	case types.Int64:
+		dst.Int64()[dstIdx] = src.Int64()[srcIdx]
+	case types.Float32:
+		dst.Float32()[dstIdx] = src.Float32()[srcIdx]
+	case types.Float64:
+		dst.Float64()[dstIdx] = src.Float64()[srcIdx]
+	case types.Timestamp:
+		dst.Timestamp()[dstIdx] = src.Timestamp()[srcIdx]
+	case types.Interval:
+		dst.Interval()[dstIdx] = src.Interval()[srcIdx]
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+}
+
+// compareFloats compares two floats according to the SQL comparison rules,
+// making sure that NaNs sort first.
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	if a == b {
+		return 0
+	}
+	if aNaN, bNaN := math.IsNaN(a), math.IsNaN(b); aNaN && !bNaN {
+		return -1
+	} else if !aNaN && bNaN {
+		return 1
+	}
+	return 0
+}
+
+// makeComparator returns a function that compares the i'th and the j'th
+// non-null values of vec, which is of type t.
+func makeComparator(t types.T, vec coldata.Vec) func(i, j uint64) int {
+	switch t {
+	case types.Bool:
+		col := vec.Bool()
+		return func(i, j uint64) int { return tree.CompareBools(col[i], col[j]) }
+	case types.Bytes:
+		col := vec.Bytes()
+		return func(i, j uint64) int { return bytes.Compare(col[i], col[j]) }
+	case types.Decimal:
+		col := vec.Decimal()
+		return func(i, j uint64) int { return tree.CompareDecimals(&col[i], &col[j]) }
+	case types.Int8:
+		col := vec.Int8()
+		return func(i, j uint64) int { return compareInts(int64(col[i]), int64(col[j])) }
+	case types.Int16:
+		col := vec.Int16()
+		return func(i, j uint64) int { return compareInts(int64(col[i]), int64(col[j])) }
+	case types.Int32:
+		col := vec.Int32()
+		return func(i, j uint64) int { return compareInts(int64(col[i]), int64(col[j])) }
+	case types.Int64:
+		col := vec.Int64()
+		return func(i, j uint64) int { return compareInts(col[i], col[j]) }
+	case types.Float32:
+		col := vec.Float32()
+		return func(i, j uint64) int { return compareFloats(float64(col[i]), float64(col[j])) }
+	case types.Float64:
+		col := vec.Float64()
+		return func(i, j uint64) int { return compareFloats(col[i], col[j]) }
+	case types.Timestamp:
+		col := vec.Timestamp()
+		return func(i, j uint64) int { return tree.CompareTimes(col[i], col[j]) }
+	case types.Interval:
+		col := vec.Interval()
+		return func(i, j uint64) int { return col[i].Compare(col[j]) }
+	default:
+		panic(fmt.Sprintf("unhandled type %s", t))
+	}
+}
+
+func compareInts(a, b int64) int {
+	if a < b {
+		return -1
+	}
+	if a > b {
+		return 1
+	}
+	return 0
+}
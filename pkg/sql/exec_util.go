@@ -784,6 +784,7 @@ func checkResultType(typ *types.T) error {
 	case types.UuidFamily:
 	case types.INetFamily:
 	case types.OidFamily:
+	case types.EnumFamily:
 	case types.TupleFamily:
 	case types.ArrayFamily:
 		if typ.ArrayContents().Family() == types.ArrayFamily {
@@ -827,13 +828,9 @@ func ParseHLC(s string) (hlc.Timestamp, error) {
 	return tree.DecimalToHLC(dec)
 }
 
-// isAsOf analyzes a statement to bypass the logic in newPlan(), since
-// that requires the transaction to be started already. If the returned
-// timestamp is not nil, it is the timestamp to which a transaction
-// should be set. The statements that will be checked are Select,
-// ShowTrace (of a Select statement), Scrub, Export, and CreateStats.
-func (p *planner) isAsOf(stmt tree.Statement) (*hlc.Timestamp, error) {
-	var asOf tree.AsOfClause
+// asOfClause returns the AS OF SYSTEM TIME clause of the statements checked
+// by isAsOf, if they have one.
+func asOfClause(stmt tree.Statement) (tree.AsOfClause, bool) {
 	switch s := stmt.(type) {
 	case *tree.Select:
 		selStmt := s.Select
@@ -845,32 +842,52 @@ func (p *planner) isAsOf(stmt tree.Statement) (*hlc.Timestamp, error) {
 
 		sc, ok := selStmt.(*tree.SelectClause)
 		if !ok {
-			return nil, nil
+			return tree.AsOfClause{}, false
 		}
 		if sc.From == nil || sc.From.AsOf.Expr == nil {
-			return nil, nil
+			return tree.AsOfClause{}, false
 		}
 
-		asOf = sc.From.AsOf
+		return sc.From.AsOf, true
 	case *tree.Scrub:
-		if s.AsOf.Expr == nil {
-			return nil, nil
-		}
-		asOf = s.AsOf
+		return s.AsOf, s.AsOf.Expr != nil
 	case *tree.Export:
-		return p.isAsOf(s.Query)
+		return asOfClause(s.Query)
 	case *tree.CreateStats:
-		if s.Options.AsOf.Expr == nil {
-			return nil, nil
-		}
-		asOf = s.Options.AsOf
+		return s.Options.AsOf, s.Options.AsOf.Expr != nil
 	default:
+		return tree.AsOfClause{}, false
+	}
+}
+
+// isAsOf analyzes a statement to bypass the logic in newPlan(), since
+// that requires the transaction to be started already. If the returned
+// timestamp is not nil, it is the timestamp to which a transaction
+// should be set. The statements that will be checked are Select,
+// ShowTrace (of a Select statement), Scrub, Export, and CreateStats.
+func (p *planner) isAsOf(stmt tree.Statement) (*hlc.Timestamp, error) {
+	asOf, ok := asOfClause(stmt)
+	if !ok {
 		return nil, nil
 	}
 	ts, err := p.EvalAsOfTimestamp(asOf)
 	return &ts, err
 }
 
+// isBoundedStalenessAsOf returns whether the statement has an AS OF SYSTEM
+// TIME clause performing a bounded-staleness read.
+func (p *planner) isBoundedStalenessAsOf(stmt tree.Statement) bool {
+	asOf, ok := asOfClause(stmt)
+	return ok && tree.IsBoundedStalenessAsOf(asOf, p.semaCtx.SearchPath)
+}
+
+// errBoundedStalenessInExplicitTxn is returned when a bounded-staleness read is
+// attempted outside of a single-statement implicit transaction. The timestamp
+// of such a read is only chosen when the statement starts.
+var errBoundedStalenessInExplicitTxn = pgerror.Newf(pgcode.FeatureNotSupported,
+	"AS OF SYSTEM TIME: %s can only be used in single-statement implicit transactions",
+	tree.WithMaxStalenessFunctionName)
+
 // isSavepoint returns true if stmt is a SAVEPOINT statement.
 func isSavepoint(stmt Statement) bool {
 	_, isSavepoint := stmt.AST.(*tree.Savepoint)
@@ -1823,6 +1840,10 @@ func (m *sessionDataMutator) SetSearchPath(val sessiondata.SearchPath) {
 	m.data.SearchPath = val
 }
 
+func (m *sessionDataMutator) SetTemporarySchemaName(scName string) {
+	m.data.SearchPath = m.data.SearchPath.WithTemporarySchemaName(scName)
+}
+
 func (m *sessionDataMutator) SetLocation(loc *time.Location) {
 	m.data.DataConversion.Location = loc
 }
@@ -1244,9 +1244,10 @@ func populateTablePrivileges(
 }
 
 var (
-	tableTypeSystemView = tree.NewDString("SYSTEM VIEW")
-	tableTypeBaseTable  = tree.NewDString("BASE TABLE")
-	tableTypeView       = tree.NewDString("VIEW")
+	tableTypeSystemView     = tree.NewDString("SYSTEM VIEW")
+	tableTypeBaseTable      = tree.NewDString("BASE TABLE")
+	tableTypeView           = tree.NewDString("VIEW")
+	tableTypeTemporaryTable = tree.NewDString("LOCAL TEMPORARY")
 )
 
 var informationSchemaTablesTable = virtualSchemaTable{
@@ -1268,6 +1269,8 @@ https://www.postgresql.org/docs/9.5/infoschema-tables.html`,
 				} else if table.IsView() {
 					tableType = tableTypeView
 					insertable = noString
+				} else if table.IsTemporary() {
+					tableType = tableTypeTemporaryTable
 				}
 				dbNameStr := tree.NewDString(db.Name)
 				scNameStr := tree.NewDString(scName)
@@ -1333,11 +1336,19 @@ CREATE TABLE information_schema.views (
 	},
 }
 
-// forEachSchemaName iterates over the physical and virtual schemas.
+// forEachSchemaName iterates over the physical, temporary and virtual schemas.
 func forEachSchemaName(
 	ctx context.Context, p *planner, db *sqlbase.DatabaseDescriptor, fn func(string) error,
 ) error {
 	scNames := []string{string(tree.PublicSchemaName)}
+	// Handle temporary schemas.
+	tempSchemas, err := getTemporarySchemaNames(ctx, p.txn, db.ID)
+	if err != nil {
+		return err
+	}
+	for _, scName := range tempSchemas {
+		scNames = append(scNames, scName)
+	}
 	// Handle virtual schemas.
 	for _, schema := range p.getVirtualTabler().getEntries() {
 		scNames = append(scNames, schema.desc.Name)
@@ -1383,6 +1394,41 @@ func forEachDatabaseDesc(
 	return nil
 }
 
+// forEachTypeDesc retrieves all user-defined type descriptors and iterates
+// through them. For each type that the user has privileges on, the function
+// will call fn with its respective database and type descriptor.
+//
+// The dbContext argument specifies in which database context we are
+// requesting the descriptors. In context nil all descriptors are
+// visible, in non-empty contexts only the descriptors of that
+// database are visible.
+func forEachTypeDesc(
+	ctx context.Context,
+	p *planner,
+	dbContext *DatabaseDescriptor,
+	fn func(*sqlbase.DatabaseDescriptor, *sqlbase.TypeDescriptor) error,
+) error {
+	descs, err := p.Tables().getAllDescriptors(ctx, p.txn)
+	if err != nil {
+		return err
+	}
+	lCtx := newInternalLookupCtx(descs, dbContext)
+	for _, desc := range descs {
+		typDesc, ok := desc.(*sqlbase.TypeDescriptor)
+		if !ok || (dbContext != nil && dbContext.ID != typDesc.ParentID) {
+			continue
+		}
+		dbDesc, ok := lCtx.dbDescs[typDesc.ParentID]
+		if !ok || p.CheckAnyPrivilege(ctx, typDesc) != nil {
+			continue
+		}
+		if err := fn(dbDesc, typDesc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // forEachTableDesc retrieves all table descriptors from the current
 // database and all system databases and iterates through them. For
 // each table, the function will call fn with its respective database
@@ -1515,14 +1561,31 @@ func forEachTableDescWithTableLookupInternal(
 		}
 	}
 
-	// Physical descriptors next.
+	// Physical descriptors next. The names of temporary schemas are only
+	// looked up for databases that contain temporary tables.
+	tempSchemasByDB := make(map[sqlbase.ID]map[sqlbase.ID]string)
 	for _, tbID := range lCtx.tbIDs {
 		table := lCtx.tbDescs[tbID]
 		dbDesc, parentExists := lCtx.dbDescs[table.GetParentID()]
 		if table.Dropped() || !userCanSeeTable(ctx, p, table, allowAdding) || !parentExists {
 			continue
 		}
-		if err := fn(dbDesc, tree.PublicSchema, table, lCtx); err != nil {
+		scName := tree.PublicSchema
+		if table.IsTemporary() {
+			tempSchemas, ok := tempSchemasByDB[dbDesc.ID]
+			if !ok {
+				tempSchemas, err = getTemporarySchemaNames(ctx, p.txn, dbDesc.ID)
+				if err != nil {
+					return err
+				}
+				tempSchemasByDB[dbDesc.ID] = tempSchemas
+			}
+			if scName, ok = tempSchemas[table.TemporarySchemaID]; !ok {
+				// The temporary schema is being cleaned up.
+				continue
+			}
+		}
+		if err := fn(dbDesc, scName, table, lCtx); err != nil {
 			return err
 		}
 	}
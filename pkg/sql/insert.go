@@ -271,7 +271,7 @@ func (p *planner) Insert(
 
 	// Create the table insert, which does the bulk of the work.
 	ri, err := row.MakeInserter(p.txn, desc, fkTables, insertCols,
-		row.CheckFKs, &p.alloc)
+		row.CheckFKs, p.EvalContext(), &p.alloc)
 	if err != nil {
 		return nil, err
 	}
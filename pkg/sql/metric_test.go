@@ -311,13 +311,13 @@ func TestSavepointMetrics(t *testing.T) {
 		t.Error(err)
 	}
 
-	// Unsupported savepoints go in a different counter.
+	// Regular savepoints go in a different counter.
 	txn, err = sqlDB.Begin()
 	if err != nil {
 		t.Fatal(err)
 	}
-	if _, err := txn.Exec("SAVEPOINT blah"); err == nil {
-		t.Fatal("expected an error but didn't get one")
+	if _, err := txn.Exec("SAVEPOINT blah"); err != nil {
+		t.Fatal(err)
 	}
 	if err := txn.Rollback(); err != nil {
 		t.Fatal(err)
@@ -39,6 +39,7 @@ func (f *stubFactory) ConstructScan(
 	reverse bool,
 	maxResults uint64,
 	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -137,7 +138,11 @@ func (f *stubFactory) ConstructOrdinality(input exec.Node, colName string) (exec
 }
 
 func (f *stubFactory) ConstructIndexJoin(
-	input exec.Node, table cat.Table, cols exec.ColumnOrdinalSet, reqOrdering exec.OutputOrdering,
+	input exec.Node,
+	table cat.Table,
+	cols exec.ColumnOrdinalSet,
+	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -151,6 +156,7 @@ func (f *stubFactory) ConstructLookupJoin(
 	lookupCols exec.ColumnOrdinalSet,
 	onCond tree.TypedExpr,
 	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -191,6 +197,16 @@ func (f *stubFactory) ConstructWindow(n exec.Node, wi exec.WindowInfo) (exec.Nod
 	return struct{}{}, nil
 }
 
+func (f *stubFactory) ConstructRecursiveCTE(
+	initial exec.Node, fn exec.RecursiveCTEIterationFn, label string, deduplicate bool,
+) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
+func (f *stubFactory) ConstructScanBuffer(ref exec.Node, label string) (exec.Node, error) {
+	return struct{}{}, nil
+}
+
 func (f *stubFactory) RenameColumns(input exec.Node, colNames []string) (exec.Node, error) {
 	return struct{}{}, nil
 }
@@ -233,6 +249,7 @@ func (f *stubFactory) ConstructUpdate(
 	table cat.Table,
 	fetchCols exec.ColumnOrdinalSet,
 	updateCols exec.ColumnOrdinalSet,
+	passthrough sqlbase.ResultColumns,
 	checks exec.CheckOrdinalSet,
 	rowsNeeded bool,
 ) (exec.Node, error) {
@@ -253,7 +270,11 @@ func (f *stubFactory) ConstructUpsert(
 }
 
 func (f *stubFactory) ConstructDelete(
-	input exec.Node, table cat.Table, fetchCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	fetchCols exec.ColumnOrdinalSet,
+	passthrough sqlbase.ResultColumns,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	return struct{}{}, nil
 }
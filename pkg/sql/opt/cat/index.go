@@ -44,6 +44,12 @@ type Index interface {
 	// IsInverted returns true if this is a JSON inverted index.
 	IsInverted() bool
 
+	// Predicate returns the SQL text of the predicate of a partial index, and
+	// true. A partial index only contains entries for the rows that satisfy its
+	// predicate, so it can only be used by queries whose filters imply it. If
+	// the index is not partial, Predicate returns false.
+	Predicate() (string, bool)
+
 	// ColumnCount returns the number of columns in the index. This includes
 	// columns that were part of the index definition (including the STORING
 	// clause), as well as implicitly added primary key columns.
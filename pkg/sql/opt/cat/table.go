@@ -45,6 +45,11 @@ type Table interface {
 	// with index(es) from other table(s).
 	IsInterleaved() bool
 
+	// IsMaterializedView returns true if this table stores the results of a
+	// materialized view. Such tables can only be modified by refreshing the
+	// view.
+	IsMaterializedView() bool
+
 	// ColumnCount returns the number of public columns in the table. Public
 	// columns are not currently being added or dropped from the table. This
 	// method should be used when mutation columns can be ignored (the common
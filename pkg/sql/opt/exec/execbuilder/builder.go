@@ -47,6 +47,17 @@ type Builder struct {
 	// each relational subexpression when evalCtx.SessionData.SaveTablesPrefix is
 	// non-empty.
 	nameGen *memo.ExprNameGenerator
+
+	// withExprs contains the WITH bindings (currently, the working buffers of
+	// recursive CTEs) that can be referenced by WithScan operators.
+	withExprs []builtWithExpr
+}
+
+// builtWithExpr is a WITH binding for which an execution node reference is
+// available.
+type builtWithExpr struct {
+	id        opt.WithID
+	bufferRef exec.Node
 }
 
 // New constructs an instance of the execution node builder using the
@@ -118,6 +129,23 @@ func (b *Builder) BuildScalar(ivh *tree.IndexedVarHelper) (tree.TypedExpr, error
 	return b.buildScalar(&ctx, scalar)
 }
 
+// addBuiltWithExpr makes the given buffer reference available to WithScan
+// operators with the given ID.
+func (b *Builder) addBuiltWithExpr(id opt.WithID, bufferRef exec.Node) {
+	b.withExprs = append(b.withExprs, builtWithExpr{id: id, bufferRef: bufferRef})
+}
+
+// findBuiltWithExpr returns the WITH binding with the given ID, or nil if
+// there is none.
+func (b *Builder) findBuiltWithExpr(id opt.WithID) *builtWithExpr {
+	for i := range b.withExprs {
+		if b.withExprs[i].id == id {
+			return &b.withExprs[i]
+		}
+	}
+	return nil
+}
+
 func (b *Builder) decorrelationError() error {
 	return errors.Errorf("could not decorrelate subquery")
 }
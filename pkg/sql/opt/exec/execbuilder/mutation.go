@@ -21,6 +21,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util"
 	"github.com/cockroachdb/errors"
 )
@@ -92,10 +93,12 @@ func (b *Builder) buildUpdate(upd *memo.UpdateExpr) (execPlan, error) {
 	//
 	// TODO(andyk): Using ensureColumns here can result in an extra Render.
 	// Upgrade execution engine to not require this.
-	colList := make(opt.ColList, 0, len(upd.FetchCols)+len(upd.UpdateCols)+len(upd.CheckCols))
+	colList := make(opt.ColList, 0,
+		len(upd.FetchCols)+len(upd.UpdateCols)+len(upd.CheckCols)+len(upd.PassthroughCols))
 	colList = appendColsWhenPresent(colList, upd.FetchCols)
 	colList = appendColsWhenPresent(colList, upd.UpdateCols)
 	colList = appendColsWhenPresent(colList, upd.CheckCols)
+	colList = append(colList, upd.PassthroughCols...)
 	input, err = b.ensureColumns(input, colList, nil, upd.Input.ProvidedPhysical().Ordering)
 	if err != nil {
 		return execPlan{}, err
@@ -112,6 +115,7 @@ func (b *Builder) buildUpdate(upd *memo.UpdateExpr) (execPlan, error) {
 		tab,
 		fetchColOrds,
 		updateColOrds,
+		b.passthroughColumns(upd.PassthroughCols),
 		checkOrds,
 		upd.NeedResults(),
 	)
@@ -219,8 +223,9 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	//
 	// TODO(andyk): Using ensureColumns here can result in an extra Render.
 	// Upgrade execution engine to not require this.
-	colList := make(opt.ColList, 0, len(del.FetchCols))
+	colList := make(opt.ColList, 0, len(del.FetchCols)+len(del.PassthroughCols))
 	colList = appendColsWhenPresent(colList, del.FetchCols)
+	colList = append(colList, del.PassthroughCols...)
 	input, err = b.ensureColumns(input, colList, nil, del.Input.ProvidedPhysical().Ordering)
 	if err != nil {
 		return execPlan{}, err
@@ -230,7 +235,13 @@ func (b *Builder) buildDelete(del *memo.DeleteExpr) (execPlan, error) {
 	md := b.mem.Metadata()
 	tab := md.Table(del.Table)
 	fetchColOrds := ordinalSetFromColList(del.FetchCols)
-	node, err := b.factory.ConstructDelete(input.root, tab, fetchColOrds, del.NeedResults())
+	node, err := b.factory.ConstructDelete(
+		input.root,
+		tab,
+		fetchColOrds,
+		b.passthroughColumns(del.PassthroughCols),
+		del.NeedResults(),
+	)
 	if err != nil {
 		return execPlan{}, err
 	}
@@ -335,9 +346,26 @@ func mutationOutputColMap(mutation memo.RelExpr) opt.ColMap {
 			ord++
 		}
 	}
+	for _, colID := range private.PassthroughCols {
+		colMap.Set(int(colID), ord)
+		ord++
+	}
 	return colMap
 }
 
+// passthroughColumns returns the result columns of the given passthrough
+// columns of a mutation operator.
+func (b *Builder) passthroughColumns(cols opt.ColList) sqlbase.ResultColumns {
+	if len(cols) == 0 {
+		return nil
+	}
+	res := make(sqlbase.ResultColumns, len(cols))
+	for i, col := range cols {
+		res[i] = b.resultColumn(col)
+	}
+	return res
+}
+
 func (b *Builder) buildFKChecks(checks memo.FKChecksExpr) error {
 	md := b.mem.Metadata()
 	for i := range checks {
@@ -241,6 +241,12 @@ func (b *Builder) buildRelational(e memo.RelExpr) (execPlan, error) {
 	case *memo.ShowTraceForSessionExpr:
 		ep, err = b.buildShowTrace(t)
 
+	case *memo.RecursiveCTEExpr:
+		ep, err = b.buildRecursiveCTE(t)
+
+	case *memo.WithScanExpr:
+		ep, err = b.buildWithScan(t)
+
 	default:
 		if opt.IsSetOp(e) {
 			ep, err = b.buildSetOp(e)
@@ -408,6 +414,7 @@ func (b *Builder) buildScan(scan *memo.ScanExpr) (execPlan, error) {
 		ordering.ScanIsReverse(scan, &scan.RequiredPhysical().Ordering),
 		b.indexConstraintMaxResults(scan),
 		res.reqOrdering(scan),
+		scan.Locking,
 	)
 	if err != nil {
 		return execPlan{}, err
@@ -1051,6 +1058,86 @@ func (b *Builder) buildSetOp(set memo.RelExpr) (execPlan, error) {
 	return ep, nil
 }
 
+// buildRecursiveCTE builds a plan for a RecursiveCTEOp. The initial query is
+// built right away; the recursive query is built (from the same memo
+// expression) once per iteration, at execution time, with its WithScan bound
+// to the working buffer of that iteration.
+func (b *Builder) buildRecursiveCTE(rec *memo.RecursiveCTEExpr) (execPlan, error) {
+	initial, err := b.buildRelational(rec.Initial)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// Make sure the initial query produces the columns in the order expected by
+	// the working table.
+	initial, err = b.ensureColumns(
+		initial, rec.InitialCols, nil /* colNames */, rec.Initial.ProvidedPhysical().Ordering,
+	)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	fn := func(bufferRef exec.Node) (exec.Plan, error) {
+		// Use a separate builder for each iteration.
+		innerBld := New(b.factory, b.mem, rec.Recursive, b.evalCtx)
+		innerBld.disableTelemetry = true
+		innerBld.nameGen = nil
+		innerBld.withExprs = append(innerBld.withExprs, b.withExprs...)
+		innerBld.addBuiltWithExpr(rec.WithID, bufferRef)
+
+		plan, err := innerBld.buildRelational(rec.Recursive)
+		if err != nil {
+			return nil, err
+		}
+		// Ensure that the columns are produced in the same order as the initial
+		// query.
+		plan, err = innerBld.ensureColumns(
+			plan, rec.RecursiveCols, nil /* colNames */, rec.Recursive.ProvidedPhysical().Ordering,
+		)
+		if err != nil {
+			return nil, err
+		}
+		return innerBld.factory.ConstructPlan(plan.root, innerBld.subqueries, innerBld.postqueries)
+	}
+
+	label := fmt.Sprintf("working buffer (%s)", rec.Name)
+	node, err := b.factory.ConstructRecursiveCTE(initial.root, fn, label, rec.Deduplicate)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	ep := execPlan{root: node}
+	for i, col := range rec.OutCols {
+		ep.outputCols.Set(int(col), i)
+	}
+	return ep, nil
+}
+
+// buildWithScan builds a plan for a WithScanOp, which reads the working
+// buffer of an enclosing recursive CTE.
+func (b *Builder) buildWithScan(withScan *memo.WithScanExpr) (execPlan, error) {
+	e := b.findBuiltWithExpr(withScan.ID)
+	if e == nil {
+		return execPlan{}, errors.AssertionFailedf(
+			"couldn't find WITH expression %q with ID %d", withScan.Name, log.Safe(withScan.ID),
+		)
+	}
+
+	label := fmt.Sprintf("working buffer (%s)", withScan.Name)
+	node, err := b.factory.ConstructScanBuffer(e.bufferRef, label)
+	if err != nil {
+		return execPlan{}, err
+	}
+
+	// The working buffer always contains the columns of the recursive CTE in
+	// order, so the output columns map 1-1 to the buffer columns.
+	ep := execPlan{root: node}
+	for i, col := range withScan.OutCols {
+		ep.outputCols.Set(int(col), i)
+	}
+	return ep, nil
+}
+
 // buildLimitOffset builds a plan for a LimitOp or OffsetOp
 func (b *Builder) buildLimitOffset(e memo.RelExpr) (execPlan, error) {
 	input, err := b.buildRelational(e.Child(0).(memo.RelExpr))
@@ -1137,7 +1224,7 @@ func (b *Builder) buildIndexJoin(join *memo.IndexJoinExpr) (execPlan, error) {
 	}
 
 	res.root, err = b.factory.ConstructIndexJoin(
-		input.root, md.Table(join.Table), needed, reqOrdering,
+		input.root, md.Table(join.Table), needed, reqOrdering, join.Locking,
 	)
 	if err != nil {
 		return execPlan{}, err
@@ -1192,6 +1279,7 @@ func (b *Builder) buildLookupJoin(join *memo.LookupJoinExpr) (execPlan, error) {
 		lookupOrdinals,
 		onExpr,
 		res.reqOrdering(join),
+		join.Locking,
 	)
 	if err != nil {
 		return execPlan{}, err
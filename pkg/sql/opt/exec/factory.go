@@ -54,6 +54,8 @@ type Factory interface {
 	//     the scan.
 	//   - If maxResults > 0, the scan is guaranteed to return at most maxResults
 	//     rows.
+	//   - If locking is not ForNone, then the scan acquires row-level locks of
+	//     the given strength on the rows it returns.
 	ConstructScan(
 		table cat.Table,
 		index cat.Index,
@@ -63,6 +65,7 @@ type Factory interface {
 		reverse bool,
 		maxResults uint64,
 		reqOrdering OutputOrdering,
+		locking tree.LockingStrength,
 	) (Node, error)
 
 	// ConstructVirtualScan returns a node that represents the scan of a virtual
@@ -194,9 +197,15 @@ type Factory interface {
 
 	// ConstructIndexJoin returns a node that performs an index join.
 	// The input must be created by ConstructScan for the same table; cols is the
-	// set of columns produced by the index join.
+	// set of columns produced by the index join. If locking is not ForNone, then
+	// the index join acquires row-level locks of the given strength on the rows
+	// it returns.
 	ConstructIndexJoin(
-		input Node, table cat.Table, cols ColumnOrdinalSet, reqOrdering OutputOrdering,
+		input Node,
+		table cat.Table,
+		cols ColumnOrdinalSet,
+		reqOrdering OutputOrdering,
+		locking tree.LockingStrength,
 	) (Node, error)
 
 	// ConstructLookupJoin returns a node that preforms a lookup join.
@@ -205,7 +214,9 @@ type Factory interface {
 	// we are retrieving.
 	//
 	// The node produces the columns in the input and lookupCols (ordered by
-	// ordinal). The ON condition can refer to these using IndexedVars.
+	// ordinal). The ON condition can refer to these using IndexedVars. If
+	// locking is not ForNone, then the looked up rows that pass the ON condition
+	// are locked with the given strength.
 	ConstructLookupJoin(
 		joinType sqlbase.JoinType,
 		input Node,
@@ -215,6 +226,7 @@ type Factory interface {
 		lookupCols ColumnOrdinalSet,
 		onCond tree.TypedExpr,
 		reqOrdering OutputOrdering,
+		locking tree.LockingStrength,
 	) (Node, error)
 
 	// ConstructZigzagJoin returns a node that performs a zigzag join.
@@ -258,6 +270,27 @@ type Factory interface {
 	// given node.
 	ConstructWindow(input Node, window WindowInfo) (Node, error)
 
+	// ConstructRecursiveCTE returns a node that executes a recursive CTE:
+	//   - the initial plan is run first; the results are emitted and also saved
+	//     in a buffer.
+	//   - so long as the last buffer is not empty:
+	//     - the RecursiveCTEIterationFn is used to create a plan for the
+	//       recursive side; a reference to the last buffer is passed to this
+	//       function. The returned plan uses this reference with a
+	//       ConstructScanBuffer call.
+	//     - the plan is executed; the results are emitted and also saved in a new
+	//       buffer for the next iteration.
+	//
+	// If deduplicate is set, rows that have already been emitted are discarded.
+	ConstructRecursiveCTE(
+		initial Node, fn RecursiveCTEIterationFn, label string, deduplicate bool,
+	) (Node, error)
+
+	// ConstructScanBuffer constructs a node which refers to the working buffer
+	// of a recursive CTE. The ref is the buffer reference passed to the
+	// RecursiveCTEIterationFn.
+	ConstructScanBuffer(ref Node, label string) (Node, error)
+
 	// RenameColumns modifies the column names of a node.
 	RenameColumns(input Node, colNames []string) (Node, error)
 
@@ -319,11 +352,17 @@ type Factory interface {
 	// columns in the same order as they appear in the table schema, with the
 	// fetch columns first and the update columns second. The rowsNeeded parameter
 	// is true if a RETURNING clause needs the updated row(s) as output.
+	//
+	// The passthrough columns are the last columns of the input, after the
+	// check columns. They are returned as they are after the table columns of
+	// each updated row, which lets the RETURNING clause reference the columns
+	// of the FROM tables.
 	ConstructUpdate(
 		input Node,
 		table cat.Table,
 		fetchCols ColumnOrdinalSet,
 		updateCols ColumnOrdinalSet,
+		passthrough sqlbase.ResultColumns,
 		checks CheckOrdinalSet,
 		rowsNeeded bool,
 	) (Node, error)
@@ -370,8 +409,16 @@ type Factory interface {
 	// the target table. The input must contain those columns in the same order
 	// as they appear in the table schema. The rowsNeeded parameter is true if a
 	// RETURNING clause needs the deleted row(s) as output.
+	//
+	// The passthrough columns follow the fetch columns in the input. They are
+	// returned as they are after the table columns of each deleted row, which
+	// lets the RETURNING clause reference the columns of the USING tables.
 	ConstructDelete(
-		input Node, table cat.Table, fetchCols ColumnOrdinalSet, rowsNeeded bool,
+		input Node,
+		table cat.Table,
+		fetchCols ColumnOrdinalSet,
+		passthrough sqlbase.ResultColumns,
+		rowsNeeded bool,
 	) (Node, error)
 
 	// ConstructDeleteRange creates a node that efficiently deletes contiguous
@@ -503,6 +550,11 @@ type WindowInfo struct {
 	Ordering sqlbase.ColumnOrdering
 }
 
+// RecursiveCTEIterationFn creates a plan for an iteration of the recursive
+// side of a recursive CTE (see ConstructRecursiveCTE), which reads the rows of
+// the given working buffer.
+type RecursiveCTEIterationFn func(bufferRef Node) (Plan, error)
+
 // ExplainEnvData represents the data that's going to be displayed in EXPLAIN (env).
 type ExplainEnvData struct {
 	ShowEnv   bool
@@ -331,6 +331,12 @@ func (sf *ScanFlags) Empty() bool {
 	return !sf.NoIndexJoin && !sf.ForceIndex
 }
 
+// IsLocking returns true if the scan acquires row-level locks on the rows it
+// returns (see tree.LockingStrength).
+func (s *ScanPrivate) IsLocking() bool {
+	return s.Locking != tree.ForNone
+}
+
 // JoinFlags stores restrictions on the join execution method, derived from
 // hints for a join specified in the query (see tree.JoinTableExpr).
 type JoinFlags struct {
@@ -383,13 +389,19 @@ func (m *MutationPrivate) NeedResults() bool {
 
 // MapToInputID maps from the ID of a returned column to the ID of the
 // corresponding input column that provides the value for it. If there is no
-// matching input column ID, MapToInputID returns 0.
+// matching input column ID, MapToInputID returns 0. Passthrough columns are
+// returned as they are, so they map to themselves.
 //
 // NOTE: This can only be called if the mutation operator returns rows.
 func (m *MutationPrivate) MapToInputID(tabColID opt.ColumnID) opt.ColumnID {
 	if m.ReturnCols == nil {
 		panic(errors.AssertionFailedf("MapToInputID cannot be called if ReturnCols is not defined"))
 	}
+	for _, col := range m.PassthroughCols {
+		if col == tabColID {
+			return col
+		}
+	}
 	ord := m.Table.ColumnOrdinal(tabColID)
 	return m.ReturnCols[ord]
 }
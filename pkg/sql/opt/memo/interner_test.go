@@ -333,6 +333,11 @@ func TestInterner(t *testing.T) {
 			{val1: opt.SchemaID(0), val2: opt.SchemaID(1), equal: false},
 		}},
 
+		{hashFn: in.hasher.HashWithID, eqFn: in.hasher.IsWithIDEqual, variations: []testVariation{
+			{val1: opt.WithID(1), val2: opt.WithID(1), equal: true},
+			{val1: opt.WithID(1), val2: opt.WithID(2), equal: false},
+		}},
+
 		{hashFn: in.hasher.HashScanLimit, eqFn: in.hasher.IsScanLimitEqual, variations: []testVariation{
 			{val1: ScanLimit(100), val2: ScanLimit(100), equal: true},
 			{val1: ScanLimit(0), val2: ScanLimit(1), equal: false},
@@ -362,6 +367,12 @@ func TestInterner(t *testing.T) {
 			{val1: tree.ShowTraceKV, val2: tree.ShowTraceRaw, equal: false},
 		}},
 
+		{hashFn: in.hasher.HashLockingStrength, eqFn: in.hasher.IsLockingStrengthEqual, variations: []testVariation{
+			{val1: tree.ForNone, val2: tree.ForNone, equal: true},
+			{val1: tree.ForUpdate, val2: tree.ForUpdate, equal: true},
+			{val1: tree.ForUpdate, val2: tree.ForShare, equal: false},
+		}},
+
 		{hashFn: in.hasher.HashWindowFrame, eqFn: in.hasher.IsWindowFrameEqual, variations: []testVariation{
 			{
 				val1:  WindowFrame{tree.RANGE, tree.UnboundedPreceding, tree.CurrentRow},
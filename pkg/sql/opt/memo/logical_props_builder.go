@@ -632,6 +632,98 @@ func (b *logicalPropsBuilder) buildSetProps(setNode RelExpr, rel *props.Relation
 	}
 }
 
+func (b *logicalPropsBuilder) buildRecursiveCTEProps(
+	rec *RecursiveCTEExpr, rel *props.Relational,
+) {
+	BuildSharedProps(b.mem, rec, &rel.Shared)
+
+	if len(rec.OutCols) != len(rec.InitialCols) || len(rec.OutCols) != len(rec.RecursiveCols) {
+		panic(errors.AssertionFailedf(
+			"lists in RecursiveCTEPrivate are not all the same length. out:%d, initial:%d, recursive:%d",
+			log.Safe(len(rec.OutCols)), log.Safe(len(rec.InitialCols)), log.Safe(len(rec.RecursiveCols)),
+		))
+	}
+
+	initialProps := rec.Initial.Relational()
+	recursiveProps := rec.Recursive.Relational()
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition.
+	rel.OutputCols = rec.OutCols.ToSet()
+
+	// Not Null Columns
+	// ----------------
+	// Columns have to be not-null in both the initial and the recursive query to
+	// be not-null in the result.
+	for i := range rec.OutCols {
+		if initialProps.NotNullCols.Contains(rec.InitialCols[i]) &&
+			recursiveProps.NotNullCols.Contains(rec.RecursiveCols[i]) {
+			rel.NotNullCols.Add(rec.OutCols[i])
+		}
+	}
+
+	// Outer Columns
+	// -------------
+	// Outer columns were already derived by buildSharedProps.
+
+	// Functional Dependencies
+	// -----------------------
+	// If duplicates are eliminated, the output columns form a strict key.
+	if rec.Deduplicate {
+		rel.FuncDeps.AddStrictKey(rel.OutputCols, rel.OutputCols)
+	}
+
+	// Cardinality
+	// -----------
+	// The recursive query can be evaluated any number of times, so we can only
+	// say that the output has at least as many rows as the initial query (or at
+	// least one row, if there is deduplication).
+	rel.Cardinality = props.AnyCardinality.AtLeast(initialProps.Cardinality)
+	if rec.Deduplicate {
+		rel.Cardinality = rel.Cardinality.AsLowAs(1)
+	}
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildRecursiveCTE(rec, rel)
+	}
+}
+
+func (b *logicalPropsBuilder) buildWithScanProps(withScan *WithScanExpr, rel *props.Relational) {
+	BuildSharedProps(b.mem, withScan, &rel.Shared)
+
+	// Output Columns
+	// --------------
+	// Output columns are stored in the definition.
+	rel.OutputCols = withScan.OutCols.ToSet()
+
+	// Not Null Columns
+	// ----------------
+	// We can't say much about the contents of the working table, as it changes
+	// on each iteration of the recursive query.
+
+	// Outer Columns
+	// -------------
+	// The operator never has outer columns.
+
+	// Functional Dependencies
+	// -----------------------
+	// Nothing is known about the contents of the working table.
+
+	// Cardinality
+	// -----------
+	// The recursive query is never evaluated with an empty working table.
+	rel.Cardinality = props.AnyCardinality.AtLeast(props.OneCardinality)
+
+	// Statistics
+	// ----------
+	if !b.disableStats {
+		b.sb.buildWithScan(withScan, rel)
+	}
+}
+
 func (b *logicalPropsBuilder) buildValuesProps(values *ValuesExpr, rel *props.Relational) {
 	BuildSharedProps(b.mem, values, &rel.Shared)
 
@@ -1074,11 +1166,15 @@ func (b *logicalPropsBuilder) buildMutationProps(mutation RelExpr, rel *props.Re
 
 	// Output Columns
 	// --------------
-	// Only non-mutation columns are output columns.
+	// Only non-mutation columns are output columns, followed by any passthrough
+	// columns.
 	for i, n := 0, tab.ColumnCount(); i < n; i++ {
 		colID := private.Table.ColumnID(i)
 		rel.OutputCols.Add(colID)
 	}
+	for _, colID := range private.PassthroughCols {
+		rel.OutputCols.Add(colID)
+	}
 
 	// Not Null Columns
 	// ----------------
@@ -1103,6 +1199,11 @@ func (b *logicalPropsBuilder) buildMutationProps(mutation RelExpr, rel *props.Re
 			rel.NotNullCols.Add(private.Table.ColumnID(i))
 		}
 	}
+	for _, colID := range private.PassthroughCols {
+		if inputProps.NotNullCols.Contains(colID) {
+			rel.NotNullCols.Add(colID)
+		}
+	}
 
 	// Outer Columns
 	// -------------
@@ -1315,6 +1416,11 @@ func makeTableFuncDep(md *opt.Metadata, tabID opt.TableID) *props.FuncDepSet {
 			// Skip inverted indexes for now.
 			continue
 		}
+		if _, isPartial := index.Predicate(); isPartial {
+			// A partial index only constrains the rows that satisfy its predicate,
+			// so its columns are not a key of the table.
+			continue
+		}
 
 		// If index has a separate lax key, add a lax key FD. Otherwise, add a
 		// strict key. See the comment for cat.Index.LaxKeyColumnCount.
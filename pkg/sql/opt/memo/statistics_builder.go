@@ -348,6 +348,12 @@ func (sb *statisticsBuilder) colStat(colSet opt.ColSet, e RelExpr) *props.Column
 	case opt.ShowTraceForSessionOp:
 		return sb.colStatShowTrace(colSet, e.(*ShowTraceForSessionExpr))
 
+	case opt.RecursiveCTEOp:
+		return sb.colStatRecursiveCTE(colSet, e.(*RecursiveCTEExpr))
+
+	case opt.WithScanOp:
+		return sb.colStatWithScan(colSet, e.(*WithScanExpr))
+
 	case opt.FakeRelOp:
 		panic(errors.AssertionFailedf("FakeRelOp does not contain col stat for %v", colSet))
 	}
@@ -2091,6 +2097,58 @@ func (sb *statisticsBuilder) colStatShowTrace(
 	return colStat
 }
 
+// +---------------+
+// | Recursive CTE |
+// +---------------+
+
+func (sb *statisticsBuilder) buildRecursiveCTE(rec *RecursiveCTEExpr, relProps *props.Relational) {
+	s := &relProps.Stats
+	if zeroCardinality := s.Init(relProps); zeroCardinality {
+		// Short cut if cardinality is 0.
+		return
+	}
+
+	initialStats := sb.statsFromChild(rec, 0 /* childIdx */)
+	recursiveStats := sb.statsFromChild(rec, 1 /* childIdx */)
+
+	// We have no way of knowing how many times the recursive query will be
+	// evaluated, so assume a fixed number of iterations.
+	s.RowCount = initialStats.RowCount + recursiveStats.RowCount*unknownRecursiveCTEIterations
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatRecursiveCTE(
+	colSet opt.ColSet, rec *RecursiveCTEExpr,
+) *props.ColumnStatistic {
+	relProps := rec.Relational()
+	return sb.colStatLeaf(colSet, &relProps.Stats, &relProps.FuncDeps, relProps.NotNullCols)
+}
+
+// +-----------+
+// | With Scan |
+// +-----------+
+
+func (sb *statisticsBuilder) buildWithScan(withScan *WithScanExpr, relProps *props.Relational) {
+	s := &relProps.Stats
+
+	// The contents of the working table change on every iteration; the best we
+	// can do is use the row count of the expression it was initially bound to.
+	binding := sb.md.WithBinding(withScan.ID).(RelExpr)
+	s.RowCount = binding.Relational().Stats.RowCount
+	// The recursive query is never evaluated with an empty working table.
+	if s.RowCount < 1 {
+		s.RowCount = 1
+	}
+	sb.finalizeFromCardinality(relProps)
+}
+
+func (sb *statisticsBuilder) colStatWithScan(
+	colSet opt.ColSet, withScan *WithScanExpr,
+) *props.ColumnStatistic {
+	relProps := withScan.Relational()
+	return sb.colStatLeaf(colSet, &relProps.Stats, &relProps.FuncDeps, relProps.NotNullCols)
+}
+
 /////////////////////////////////////////////////
 // General helper functions for building stats //
 /////////////////////////////////////////////////
@@ -2260,6 +2318,10 @@ const (
 	// Since the generator row count is so small, we need a larger distinct count
 	// ratio for generator functions.
 	unknownGeneratorDistinctCountRatio = 0.7
+
+	// This is an arbitrary number of iterations of the recursive query of a
+	// recursive CTE, used to estimate the output row count.
+	unknownRecursiveCTEIterations = 10
 )
 
 // countJSONPaths returns the number of JSON paths in the specified
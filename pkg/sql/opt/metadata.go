@@ -80,6 +80,14 @@ type Metadata struct {
 	// values is the highest id for a Values clause that has been assigned.
 	values ValuesID
 
+	// withIDs is the highest id for a WITH binding that has been assigned.
+	withIDs WithID
+
+	// withBindings stores the expression bound to each WithID. The binding
+	// expressions are used to derive properties of the operators that reference
+	// them (e.g. the working table of a recursive CTE).
+	withBindings map[WithID]Expr
+
 	// deps stores information about all catalog objects depended on by the query,
 	// as well as the privileges required to access those objects. The objects are
 	// deduplicated: any name/object pair shows up at most once.
@@ -134,6 +142,10 @@ func (md *Metadata) Init() {
 	md.tables = md.tables[:0]
 	md.views = md.views[:0]
 	md.deps = md.deps[:0]
+	md.withIDs = 0
+	for id := range md.withBindings {
+		delete(md.withBindings, id)
+	}
 }
 
 // CopyFrom initializes the metadata with a copy of the provided metadata.
@@ -159,6 +171,14 @@ func (md *Metadata) CopyFrom(from *Metadata) {
 
 	md.sequences = append(md.sequences, from.sequences...)
 	md.deps = append(md.deps, from.deps...)
+
+	md.withIDs = from.withIDs
+	if len(from.withBindings) != 0 {
+		md.withBindings = make(map[WithID]Expr, len(from.withBindings))
+		for id, expr := range from.withBindings {
+			md.withBindings[id] = expr
+		}
+	}
 }
 
 // AddDataSourceDependency tracks one of the catalog data sources on which the
@@ -476,6 +496,39 @@ func (md *Metadata) NextValuesID() ValuesID {
 	return md.values
 }
 
+// WithID uniquely identifies a WITH binding (currently, the working table of a
+// recursive common table expression) within the scope of a query.
+//
+// See the comment for Metadata for more details on identifiers.
+type WithID uint64
+
+// NextWithID returns a fresh WithID which is guaranteed to never have been
+// allocated prior in this memo.
+func (md *Metadata) NextWithID() WithID {
+	md.withIDs++
+	return md.withIDs
+}
+
+// AddWithBinding associates the given expression with a WithID. The
+// expression is used to derive the properties of the operators that scan the
+// binding.
+func (md *Metadata) AddWithBinding(id WithID, expr Expr) {
+	if md.withBindings == nil {
+		md.withBindings = make(map[WithID]Expr)
+	}
+	md.withBindings[id] = expr
+}
+
+// WithBinding returns the expression associated with the given WithID. It
+// panics if no expression has been bound to the WithID.
+func (md *Metadata) WithBinding(id WithID) Expr {
+	expr, ok := md.withBindings[id]
+	if !ok {
+		panic(errors.AssertionFailedf("no binding for WITH id %d", id))
+	}
+	return expr
+}
+
 // AddView adds a new reference to a view used by the query.
 func (md *Metadata) AddView(v cat.View) {
 	md.views = append(md.views, v)
@@ -59,6 +59,7 @@ func (c *CustomFuncs) NeededMutationCols(private *memo.MutationPrivate) opt.ColS
 	addCols(private.UpdateCols)
 	addCols(private.CheckCols)
 	addCols(private.ReturnCols)
+	addCols(private.PassthroughCols)
 	if private.CanaryCol != 0 {
 		cols.Add(private.CanaryCol)
 	}
@@ -98,6 +99,16 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 		}
 	}
 
+	// predicateCols returns the columns referenced by the predicate of the given
+	// partial index. These are needed to determine whether the existing row has
+	// an entry in the index.
+	predicateCols := func(indexOrd int) opt.ColSet {
+		if pred, ok := tabMeta.PartialIndexPredicate(indexOrd); ok {
+			return c.OuterCols(pred)
+		}
+		return opt.ColSet{}
+	}
+
 	// Retain any FetchCols that are needed for ReturnCols. If a RETURN column
 	// is needed, then:
 	//   1. For Delete, the corresponding FETCH column is always needed, since
@@ -130,11 +141,16 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 		// Make sure to consider indexes that are being added or dropped.
 		for i, n := 0, tabMeta.Table.DeletableIndexCount(); i < n; i++ {
 			indexCols := tabMeta.IndexColumns(i)
-			if !indexCols.Intersects(updateCols) {
+			predCols := predicateCols(i)
+			if !indexCols.Intersects(updateCols) && !predCols.Intersects(updateCols) {
 				// This index is not being updated.
 				continue
 			}
 
+			// Partial indexes need the columns of their predicate, since updating
+			// a row can add it to or remove it from the index.
+			cols.UnionWith(predCols)
+
 			// Always add index strict key columns, since these are needed to fetch
 			// existing rows from the store.
 			keyCols := tabMeta.IndexKeyColumns(i)
@@ -161,6 +177,7 @@ func (c *CustomFuncs) NeededMutationFetchCols(
 		// or dropped.
 		for i, n := 0, tabMeta.Table.DeletableIndexCount(); i < n; i++ {
 			cols.UnionWith(tabMeta.IndexKeyColumns(i))
+			cols.UnionWith(predicateCols(i))
 		}
 	}
 
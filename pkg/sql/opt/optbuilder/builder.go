@@ -107,6 +107,11 @@ type Builder struct {
 	// subquery contains a pointer to the subquery which is currently being built
 	// (if any).
 	subquery *subquery
+
+	// locking contains the row-level locking items (FOR UPDATE, FOR SHARE, etc.)
+	// that apply to the data sources of the FROM clause which is currently being
+	// built (if any).
+	locking lockingSpec
 }
 
 // New creates a new Builder structure initialized with the given
@@ -23,6 +23,10 @@ import (
 // are projected, including mutation columns (the optimizer may later prune the
 // columns if they are not needed).
 //
+// A USING clause joins the deletion table with other tables, whose columns can
+// then be referenced by the WHERE and RETURNING clauses. Each matching row is
+// deleted once, even if it joins to several rows of the USING tables.
+//
 // Note that the ORDER BY clause can only be used if the LIMIT clause is also
 // present. In that case, the ordering determines which rows are included by the
 // limit. The ORDER BY makes no additional guarantees about the order in which
@@ -40,7 +44,7 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	}
 
 	if del.With != nil {
-		inScope = b.buildCTE(del.With.CTEList, inScope)
+		inScope = b.buildCTE(del.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -50,7 +54,7 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(del.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab, resName := b.resolveTable(tn, privilege.DELETE)
+	tab, resName := b.resolveTableForMutation(tn, privilege.DELETE)
 	if alias == nil {
 		alias = &resName
 	}
@@ -64,11 +68,12 @@ func (b *Builder) buildDelete(del *tree.Delete, inScope *scope) (outScope *scope
 	// Build the input expression that selects the rows that will be deleted:
 	//
 	//   WITH <with>
-	//   SELECT <cols> FROM <table> WHERE <where>
+	//   SELECT <cols> FROM <table>, <using> WHERE <where>
 	//   ORDER BY <order-by> LIMIT <limit>
 	//
-	// All columns from the delete table will be projected.
-	mb.buildInputForUpdateOrDelete(inScope, del.Where, del.Limit, del.OrderBy)
+	// All columns from the delete table will be projected, followed by the
+	// columns of any USING tables.
+	mb.buildInputForUpdateOrDelete(inScope, del.Using, del.Where, del.Limit, del.OrderBy)
 
 	// Build the final delete statement, including any returned expressions.
 	if resultsNeeded(del.Returning) {
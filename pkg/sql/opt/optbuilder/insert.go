@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
@@ -155,7 +156,7 @@ func init() {
 // and thereby scrambles the input ordering.
 func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope) {
 	if ins.With != nil {
-		inScope = b.buildCTE(ins.With.CTEList, inScope)
+		inScope = b.buildCTE(ins.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -165,7 +166,7 @@ func (b *Builder) buildInsert(ins *tree.Insert, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(ins.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab, resName := b.resolveTable(tn, privilege.INSERT)
+	tab, resName := b.resolveTableForMutation(tn, privilege.INSERT)
 	if alias == nil {
 		alias = &resName
 	}
@@ -658,8 +659,9 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 		// Build the right side of the left outer join. Use a new metadata instance
 		// of the mutation table so that a different set of column IDs are used for
 		// the two tables in the self-join.
+		scanTabMeta := mb.b.addTable(mb.tab, &mb.alias)
 		scanScope := mb.b.buildScan(
-			mb.b.addTable(mb.tab, &mb.alias),
+			scanTabMeta,
 			nil, /* ordinals */
 			nil, /* indexFlags */
 			excludeMutations,
@@ -689,6 +691,17 @@ func (mb *mutationBuilder) buildInputForDoNothing(inScope *scope, onConflict *tr
 			on = append(on, memo.FiltersItem{Condition: condition})
 		}
 
+		// A unique partial index only guarantees uniqueness among the rows that
+		// satisfy its predicate, so there is only a conflict if both the insert
+		// row and the existing row satisfy it.
+		if pred, ok := index.Predicate(); ok {
+			scanPred, _ := scanTabMeta.PartialIndexPredicate(idx)
+			on = append(on,
+				memo.FiltersItem{Condition: mb.buildPartialIndexPredicateForInsert(pred)},
+				memo.FiltersItem{Condition: scanPred},
+			)
+		}
+
 		// Construct the left join + filter.
 		// TODO(andyk): Convert this to use anti-join once we have support for
 		// lookup anti-joins.
@@ -955,6 +968,30 @@ func (mb *mutationBuilder) projectUpsertColumns() {
 	mb.outScope = projectionsScope
 }
 
+// buildPartialIndexPredicateForInsert builds the given partial index predicate
+// as a scalar expression over the insert columns, so that it can be evaluated
+// for each row being inserted.
+func (mb *mutationBuilder) buildPartialIndexPredicateForInsert(pred string) opt.ScalarExpr {
+	predScope := mb.b.allocScope()
+	predScope.cols = make([]scopeColumn, 0, mb.tab.ColumnCount())
+	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+		tabCol := mb.tab.Column(i)
+		predScope.cols = append(predScope.cols, scopeColumn{
+			name:  tabCol.ColName(),
+			table: mb.alias,
+			typ:   tabCol.DatumType(),
+			id:    mb.insertColID(i),
+		})
+	}
+
+	expr, err := parser.ParseExpr(pred)
+	if err != nil {
+		panic(builderError{err})
+	}
+	texpr := predScope.resolveAndRequireType(expr, types.Bool)
+	return mb.b.buildScalar(texpr, predScope, nil, nil, nil)
+}
+
 // ensureUniqueConflictCols tries to prove that the given list of column names
 // correspond to the columns of at least one UNIQUE index on the target table.
 // If true, then ensureUniqueConflictCols returns the matching index. Otherwise,
@@ -971,6 +1008,11 @@ func (mb *mutationBuilder) ensureUniqueConflictCols(cols tree.NameList) cat.Inde
 			continue
 		}
 
+		// Skip partial indexes, which do not ensure uniqueness across all rows.
+		if _, isPartial := index.Predicate(); isPartial {
+			continue
+		}
+
 		found := true
 		for col, colCount := 0, index.LaxKeyColumnCount(); col < colCount; col++ {
 			if cols[col] != index.Column(col).ColName() {
@@ -0,0 +1,175 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package optbuilder
+
+import (
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// lockingSpec maintains a collection of FOR [KEY] UPDATE/SHARE items that
+// apply to a given scope. Locking clauses can be applied to the lockingSpec
+// as they come into scope in the AST. The lockingSpec can then be consulted
+// to determine the locking strength that applies to a given table.
+//
+// Locking items that list target relations (FOR UPDATE OF t) only apply to
+// the data sources with a matching name. Once a target has been matched, the
+// item is filtered down to an untargeted item, which then applies to every
+// table in the data source, including those inside of views and subqueries.
+type lockingSpec []*tree.LockingItem
+
+// isSet returns whether the spec contains any locking items.
+func (lm lockingSpec) isSet() bool {
+	return len(lm) != 0
+}
+
+// get returns the maximum locking strength of the items in the spec.
+func (lm lockingSpec) get() tree.LockingStrength {
+	return tree.LockingClause(lm).MaxStrength()
+}
+
+// filter returns the subset of the locking items that apply to a data source
+// with the given alias. Items with no target relations always apply. Targeted
+// items that match the alias are stripped of their targets in the result, so
+// that they apply to all tables within the data source.
+func (lm lockingSpec) filter(alias tree.Name) lockingSpec {
+	var ret lockingSpec
+	for _, li := range lm {
+		if len(li.Targets) == 0 {
+			ret = append(ret, li)
+			continue
+		}
+		for i := range li.Targets {
+			if li.Targets[i].TableName == alias {
+				ret = append(ret, &tree.LockingItem{
+					Strength:   li.Strength,
+					WaitPolicy: li.WaitPolicy,
+				})
+				break
+			}
+		}
+	}
+	return ret
+}
+
+// withoutTargets returns the subset of the locking items that have no target
+// relations. It is used for data sources that cannot be named by a locking
+// item, such as unaliased subqueries.
+func (lm lockingSpec) withoutTargets() lockingSpec {
+	return lm.filter("")
+}
+
+// validateLockingInSelect checks that a locking clause is allowed in the given
+// SELECT statement, panicking with an error if it is not.
+func (b *Builder) validateLockingInSelect(locking tree.LockingClause, stmt tree.SelectStatement) {
+	if !b.evalCtx.Settings.Version.IsActive(cluster.VersionLockOnlyIntents) {
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s requires all nodes to be upgraded to %s",
+			locking.MaxStrength(), cluster.VersionByKey(cluster.VersionLockOnlyIntents)))
+	}
+	for _, li := range locking {
+		if !li.Strength.IsExclusive() {
+			// Shared locks would need to be compatible with each other, which
+			// the intents used to lock rows are not.
+			panic(pgerror.Newf(pgcode.FeatureNotSupported,
+				"%s is not supported", li.Strength))
+		}
+		if li.WaitPolicy != tree.LockWaitBlock {
+			// This is reported as a final error rather than as an unimplemented
+			// error, because the heuristic planner does not support locking at
+			// all and so there is nothing to fall back on.
+			panic(pgerror.Newf(pgcode.FeatureNotSupported,
+				"%s is not supported", li.WaitPolicy))
+		}
+	}
+
+	strength := locking.MaxStrength()
+	switch stmt.(type) {
+	case *tree.SelectClause:
+	case *tree.UnionClause:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not allowed with UNION/INTERSECT/EXCEPT", strength))
+	case *tree.ValuesClause:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s cannot be applied to VALUES", strength))
+	}
+}
+
+// validateLockingInSelectClause checks that the given locking spec can be
+// applied to the given SELECT clause, panicking with an error if it cannot.
+// Locking is not allowed when the rows of the result cannot be traced back to
+// individual table rows.
+func (b *Builder) validateLockingInSelectClause(
+	locking lockingSpec, sel *tree.SelectClause, fromScope *scope,
+) {
+	strength := locking.get()
+	switch {
+	case sel.Distinct:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not allowed with DISTINCT clause", strength))
+	case len(sel.GroupBy) > 0:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not allowed with GROUP BY clause", strength))
+	case sel.Having != nil:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not allowed with HAVING clause", strength))
+	case fromScope.hasAggregates():
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not allowed with aggregate functions", strength))
+	case len(fromScope.windows) > 0:
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"%s is not allowed with window functions", strength))
+	}
+
+	// Every target relation must be named in the FROM clause.
+	var names []tree.Name
+	for _, t := range sel.From.Tables {
+		names = collectLockingTargetNames(t, names)
+	}
+	for _, li := range locking {
+		for i := range li.Targets {
+			target := li.Targets[i].TableName
+			found := false
+			for _, name := range names {
+				if name == target {
+					found = true
+					break
+				}
+			}
+			if !found {
+				panic(pgerror.Newf(pgcode.UndefinedTable,
+					"relation %q in %s clause not found in FROM clause", string(target), li.Strength))
+			}
+		}
+	}
+}
+
+// collectLockingTargetNames appends the names by which the data sources in
+// the given table expression can be referenced in a locking clause.
+func collectLockingTargetNames(texpr tree.TableExpr, names []tree.Name) []tree.Name {
+	switch t := texpr.(type) {
+	case *tree.AliasedTableExpr:
+		if t.As.Alias != "" {
+			return append(names, t.As.Alias)
+		}
+		return collectLockingTargetNames(t.Expr, names)
+	case *tree.JoinTableExpr:
+		names = collectLockingTargetNames(t.Left, names)
+		return collectLockingTargetNames(t.Right, names)
+	case *tree.ParenTableExpr:
+		return collectLockingTargetNames(t.Expr, names)
+	case *tree.TableName:
+		return append(names, t.TableName)
+	}
+	return names
+}
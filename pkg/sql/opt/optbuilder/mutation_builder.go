@@ -124,6 +124,11 @@ type mutationBuilder struct {
 
 	// checks contains foreign key check queries; see buildFKChecks.
 	checks memo.FKChecksExpr
+
+	// extraAccessibleCols are the columns of the FROM (UPDATE) or USING
+	// (DELETE) tables. They can be referenced by the RETURNING clause, and are
+	// then passed through the mutation operator.
+	extraAccessibleCols []scopeColumn
 }
 
 func (mb *mutationBuilder) init(b *Builder, op opt.Operator, tab cat.Table, alias tree.TableName) {
@@ -148,6 +153,37 @@ func (mb *mutationBuilder) init(b *Builder, op opt.Operator, tab cat.Table, alia
 
 	// Add the table and its columns (including mutation columns) to metadata.
 	mb.tabID = mb.md.AddTableWithAlias(tab, &mb.alias)
+	mb.addPartialIndexPredicates()
+}
+
+// addPartialIndexPredicates adds the predicates of the target table's partial
+// indexes to its metadata. The predicates reference the target table's column
+// IDs, and are used to determine the columns that must be fetched in order to
+// maintain the partial indexes.
+func (mb *mutationBuilder) addPartialIndexPredicates() {
+	hasPartialIndex := false
+	for i, n := 0, mb.tab.DeletableIndexCount(); i < n; i++ {
+		if _, ok := mb.tab.Index(i).Predicate(); ok {
+			hasPartialIndex = true
+			break
+		}
+	}
+	if !hasPartialIndex {
+		return
+	}
+
+	predScope := mb.b.allocScope()
+	predScope.cols = make([]scopeColumn, 0, mb.tab.ColumnCount())
+	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
+		tabCol := mb.tab.Column(i)
+		predScope.cols = append(predScope.cols, scopeColumn{
+			name:  tabCol.ColName(),
+			table: mb.alias,
+			typ:   tabCol.DatumType(),
+			id:    mb.tabID.ColumnID(i),
+		})
+	}
+	mb.b.addPartialIndexPredicates(predScope, mb.md.TableMeta(mb.tabID))
 }
 
 // scopeOrdToColID returns the ID of the given scope column. If no scope column
@@ -170,15 +206,32 @@ func (mb *mutationBuilder) insertColID(tabOrd int) opt.ColumnID {
 // the Update or Delete operator, similar to this:
 //
 //   SELECT <cols>
-//   FROM <table>
+//   FROM <table>, <from-tables>
 //   WHERE <where>
 //   ORDER BY <order-by>
 //   LIMIT <limit>
 //
-// All columns from the table to update are added to fetchColList.
+// All columns from the table to update are added to fetchColList. If there are
+// additional FROM (UPDATE) or USING (DELETE) tables, their columns follow the
+// table columns in outScope, so that they can be referenced by the SET
+// expressions. In that case, a target row may join to several rows from the
+// other tables. As in Postgres, each target row is only mutated once, using an
+// arbitrary one of the rows it joins to. The rows are made distinct before ORDER
+// BY and LIMIT are applied, so that LIMIT counts target rows rather than joined
+// rows:
+//
+//   SELECT <cols>
+//   FROM (
+//     SELECT DISTINCT ON (<primary-key>) <cols>
+//     FROM <table>, <from-tables>
+//     WHERE <where>
+//   )
+//   ORDER BY <order-by>
+//   LIMIT <limit>
+//
 // TODO(andyk): Do needed column analysis to project fewer columns if possible.
 func (mb *mutationBuilder) buildInputForUpdateOrDelete(
-	inScope *scope, where *tree.Where, limit *tree.Limit, orderBy tree.OrderBy,
+	inScope *scope, from tree.TableExprs, where *tree.Where, limit *tree.Limit, orderBy tree.OrderBy,
 ) {
 	// Fetch columns from different instance of the table metadata, so that it's
 	// possible to remap columns, as in this example:
@@ -195,9 +248,38 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 		inScope,
 	)
 
+	// Join the target table with any additional tables. The target table must
+	// be the left input, since columns are identified by their position in
+	// outScope.
+	if len(from) > 0 {
+		fromScope := mb.b.buildFromTables(from, inScope)
+
+		// Check that the same table name is not used multiple times.
+		mb.b.validateJoinTableNames(mb.outScope, fromScope)
+
+		mb.extraAccessibleCols = append([]scopeColumn(nil), fromScope.cols...)
+		mb.outScope.appendColumnsFromScope(fromScope)
+
+		left := mb.outScope.expr.(memo.RelExpr)
+		right := fromScope.expr.(memo.RelExpr)
+		mb.outScope.expr = mb.b.factory.ConstructInnerJoin(
+			left, right, memo.TrueFilter, memo.EmptyJoinPrivate,
+		)
+	}
+
 	// WHERE
 	mb.b.buildWhere(where, mb.outScope)
 
+	// Set list of columns that will be fetched by the input expression.
+	for i := range mb.fetchOrds {
+		mb.fetchOrds[i] = scopeOrdinal(i)
+	}
+
+	// DISTINCT ON (<primary-key>)
+	if len(from) > 0 {
+		mb.buildDistinctOnPrimaryKey()
+	}
+
 	// SELECT + ORDER BY (which may add projected expressions)
 	projectionsScope := mb.outScope.replace()
 	projectionsScope.appendColumnsFromScope(mb.outScope)
@@ -211,11 +293,36 @@ func (mb *mutationBuilder) buildInputForUpdateOrDelete(
 	}
 
 	mb.outScope = projectionsScope
+}
 
-	// Set list of columns that will be fetched by the input expression.
+// buildDistinctOnPrimaryKey wraps the input expression with a DistinctOn
+// operator that groups on the primary key columns of the target table. This
+// ensures that each target row appears at most once in the mutation input,
+// even if it joins to multiple rows from other tables. All other columns are
+// taken from the first row in each group, so the values for any given target
+// row are consistent with one another.
+func (mb *mutationBuilder) buildDistinctOnPrimaryKey() {
+	var pkCols opt.ColSet
+	primary := mb.tab.Index(cat.PrimaryIndex)
+	for i, n := 0, primary.KeyColumnCount(); i < n; i++ {
+		pkCols.Add(mb.scopeOrdToColID(mb.fetchOrds[primary.Column(i).Ordinal]))
+	}
+
+	aggs := make(memo.AggregationsExpr, 0, len(mb.outScope.cols))
+	excluded := pkCols.Copy()
 	for i := range mb.outScope.cols {
-		mb.fetchOrds[i] = scopeOrdinal(i)
+		if id := mb.outScope.cols[i].id; !excluded.Contains(id) {
+			excluded.Add(id)
+			aggs = append(aggs, memo.AggregationsItem{
+				Agg:        mb.b.factory.ConstructFirstAgg(mb.b.factory.ConstructVariable(id)),
+				ColPrivate: memo.ColPrivate{Col: id},
+			})
+		}
 	}
+
+	private := memo.GroupingPrivate{GroupingCols: pkCols}
+	input := mb.outScope.expr.(memo.RelExpr)
+	mb.outScope.expr = mb.b.factory.ConstructDistinctOn(input, aggs, &private)
 }
 
 // addTargetColsByName adds one target column for each of the names in the given
@@ -602,6 +709,13 @@ func (mb *mutationBuilder) makeMutationPrivate(needResults bool) *memo.MutationP
 			}
 			private.ReturnCols[i] = mb.outScope.cols[scopeOrd].id
 		}
+
+		if len(mb.extraAccessibleCols) > 0 {
+			private.PassthroughCols = make(opt.ColList, len(mb.extraAccessibleCols))
+			for i := range mb.extraAccessibleCols {
+				private.PassthroughCols[i] = mb.extraAccessibleCols[i].id
+			}
+		}
 	}
 
 	return private
@@ -662,9 +776,12 @@ func (mb *mutationBuilder) buildReturning(returning tree.ReturningExprs) {
 	//   3. Mark hidden columns.
 	//   4. Project columns in same order as defined in table schema.
 	//
+	// As in Postgres, the columns of any FROM or USING tables, which are passed
+	// through the mutation operator, can be referenced as well, and follow the
+	// table columns.
 	inScope := mb.outScope.replace()
 	inScope.expr = mb.outScope.expr
-	inScope.cols = make([]scopeColumn, 0, mb.tab.ColumnCount())
+	inScope.cols = make([]scopeColumn, 0, mb.tab.ColumnCount()+len(mb.extraAccessibleCols))
 	for i, n := 0, mb.tab.ColumnCount(); i < n; i++ {
 		tabCol := mb.tab.Column(i)
 		inScope.cols = append(inScope.cols, scopeColumn{
@@ -675,6 +792,7 @@ func (mb *mutationBuilder) buildReturning(returning tree.ReturningExprs) {
 			hidden: tabCol.IsHidden(),
 		})
 	}
+	inScope.cols = append(inScope.cols, mb.extraAccessibleCols...)
 
 	// Construct the Project operator that projects the RETURNING expressions.
 	outScope := inScope.replace()
@@ -102,6 +102,10 @@ type cteSource struct {
 	// to only having a single reference to a given CTE, so if this is set then
 	// this CTE has already been referenced and may not be referenced again.
 	used bool
+
+	// onRef is called (if set) whenever the CTE is referenced, before the used
+	// flag is checked. It is used to validate references to recursive CTEs.
+	onRef func()
 }
 
 // groupByStrSet is a set of stringified GROUP BY expressions that map to the
@@ -53,6 +53,12 @@ func (b *Builder) buildDataSource(
 			indexFlags = source.IndexFlags
 		}
 
+		if source.As.Alias != "" && b.locking.isSet() {
+			// Locking clauses refer to aliased data sources by their alias.
+			defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+			b.locking = b.locking.filter(source.As.Alias)
+		}
+
 		outScope = b.buildDataSource(source.Expr, indexFlags, inScope)
 
 		if source.Ordinality {
@@ -72,6 +78,9 @@ func (b *Builder) buildDataSource(
 
 		// CTEs take precedence over other data sources.
 		if cte := inScope.resolveCTE(tn); cte != nil {
+			if cte.onRef != nil {
+				cte.onRef()
+			}
 			if cte.used {
 				panic(unimplementedWithIssueDetailf(21084, "", "unsupported multiple use of CTE clause %q", tn))
 			}
@@ -86,9 +95,18 @@ func (b *Builder) buildDataSource(
 			return outScope
 		}
 
+		if b.locking.isSet() {
+			defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+			b.locking = b.locking.filter(tn.TableName)
+		}
+
 		ds, resName := b.resolveDataSource(tn, privilege.SELECT)
 		switch t := ds.(type) {
 		case cat.Table:
+			if b.locking.isSet() {
+				// Locking rows of a table requires UPDATE privilege on it.
+				b.checkPrivilege(tn, ds, privilege.UPDATE)
+			}
 			tabMeta := b.addTable(t, &resName)
 			return b.buildScan(tabMeta, nil /* ordinals */, indexFlags, excludeMutations, inScope)
 		case cat.View:
@@ -106,6 +124,12 @@ func (b *Builder) buildDataSource(
 		return b.buildZip(source.Items, inScope)
 
 	case *tree.Subquery:
+		if b.locking.isSet() {
+			// Targeted locking items cannot refer to an unaliased subquery.
+			defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+			b.locking = b.locking.withoutTargets()
+		}
+
 		outScope = b.buildStmt(source.Select, nil /* desiredTypes */, inScope)
 
 		// Treat the subquery result as an anonymous data source (i.e. column names
@@ -393,8 +417,20 @@ func (b *Builder) buildScan(
 				private.Flags.Direction = indexFlags.Direction
 			}
 		}
+		if b.locking.isSet() {
+			// Targeted locking items have already been matched against the name
+			// of the data source (see buildDataSource), so only the untargeted
+			// items apply to this table.
+			private.Locking = b.locking.withoutTargets().get()
+		}
 		outScope.expr = b.factory.ConstructScan(&private)
 		b.addCheckConstraintsToScan(outScope, tabMeta)
+
+		// The predicates of partial indexes can only be resolved if the scan
+		// projects all of the table's columns.
+		if ordinals == nil {
+			b.addPartialIndexPredicates(outScope, tabMeta)
+		}
 	}
 	return outScope
 }
@@ -424,6 +460,29 @@ func (b *Builder) addCheckConstraintsToScan(scope *scope, tabMeta *opt.TableMeta
 	}
 }
 
+// addPartialIndexPredicates finds all the partial indexes on the table,
+// including mutation indexes, and adds their predicates to the table metadata.
+// The optimizer can then use a partial index when the filters of a query imply
+// its predicate, and determine the columns that mutations need to maintain it.
+// To do this, the scalar expressions of the predicates are built here, using
+// the columns of the given scope.
+func (b *Builder) addPartialIndexPredicates(scope *scope, tabMeta *opt.TableMeta) {
+	tab := tabMeta.Table
+	for i, n := 0, tab.DeletableIndexCount(); i < n; i++ {
+		pred, ok := tab.Index(i).Predicate()
+		if !ok {
+			continue
+		}
+		expr, err := parser.ParseExpr(pred)
+		if err != nil {
+			panic(builderError{err})
+		}
+
+		texpr := scope.resolveAndRequireType(expr, types.Bool)
+		tabMeta.AddPartialIndexPredicate(i, b.buildScalar(texpr, scope, nil, nil, nil))
+	}
+}
+
 func (b *Builder) buildSequenceSelect(seq cat.Sequence, inScope *scope) (outScope *scope) {
 	tn := seq.SequenceName()
 	md := b.factory.Metadata()
@@ -475,58 +534,245 @@ func (b *Builder) buildWithOrdinality(colName string, inScope *scope) (outScope
 	return inScope
 }
 
-func (b *Builder) buildCTE(ctes []*tree.CTE, inScope *scope) (outScope *scope) {
+func (b *Builder) buildCTE(with *tree.With, inScope *scope) (outScope *scope) {
 	outScope = inScope.push()
 
-	outScope.ctes = make(map[string]*cteSource)
-	for i := range ctes {
-		cteScope := b.buildStmt(ctes[i].Stmt, nil /* desiredTypes */, outScope)
-		cols := cteScope.cols
-		name := ctes[i].Name.Alias
+	// Locking clauses do not apply to common table expressions.
+	defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+	b.locking = nil
 
+	outScope.ctes = make(map[string]*cteSource)
+	for _, cte := range with.CTEList {
+		name := cte.Name.Alias
 		if _, ok := outScope.ctes[name.String()]; ok {
 			panic(pgerror.Newf(
 				pgcode.DuplicateAlias,
-				"WITH query name %s specified more than once", ctes[i].Name.Alias),
+				"WITH query name %s specified more than once", name),
 			)
 		}
 
-		// Names for the output columns can optionally be specified.
-		if ctes[i].Name.Cols != nil {
-			if len(cteScope.cols) != len(ctes[i].Name.Cols) {
-				panic(pgerror.Newf(
-					pgcode.InvalidColumnReference,
-					"source %q has %d columns available but %d columns specified",
-					name, len(cteScope.cols), len(ctes[i].Name.Cols),
-				))
-			}
-
-			cols = make([]scopeColumn, len(cteScope.cols))
-			tableName := tree.MakeUnqualifiedTableName(ctes[i].Name.Alias)
-			copy(cols, cteScope.cols)
-			for j := range cols {
-				cols[j].name = ctes[i].Name.Cols[j]
-				cols[j].table = tableName
-			}
-		}
-
-		if len(cols) == 0 {
-			panic(pgerror.Newf(pgcode.FeatureNotSupported,
-				"WITH clause %q does not have a RETURNING clause", tree.ErrString(&name)))
+		var expr memo.RelExpr
+		var cols []scopeColumn
+		if with.Recursive {
+			expr, cols = b.buildRecursiveCTE(cte, outScope)
+		} else {
+			cteScope := b.buildStmt(cte.Stmt, nil /* desiredTypes */, outScope)
+			expr = cteScope.expr.(memo.RelExpr)
+			cols = b.getCTECols(cteScope, cte.Name)
 		}
 
-		outScope.ctes[ctes[i].Name.Alias.String()] = &cteSource{
-			name: ctes[i].Name,
+		outScope.ctes[name.String()] = &cteSource{
+			name: cte.Name,
 			cols: cols,
-			expr: cteScope.expr,
+			expr: expr,
 		}
 	}
 
 	telemetry.Inc(sqltelemetry.CteUseCounter)
+	if with.Recursive {
+		telemetry.Inc(sqltelemetry.RecursiveCteUseCounter)
+	}
 
 	return outScope
 }
 
+// getCTECols returns the output columns of a CTE, renamed according to the
+// column names in the CTE alias (if any).
+func (b *Builder) getCTECols(cteScope *scope, name tree.AliasClause) []scopeColumn {
+	cols := cteScope.cols
+
+	// Names for the output columns can optionally be specified.
+	if name.Cols != nil {
+		if len(cteScope.cols) != len(name.Cols) {
+			panic(pgerror.Newf(
+				pgcode.InvalidColumnReference,
+				"source %q has %d columns available but %d columns specified",
+				name.Alias, len(cteScope.cols), len(name.Cols),
+			))
+		}
+
+		cols = make([]scopeColumn, len(cteScope.cols))
+		tableName := tree.MakeUnqualifiedTableName(name.Alias)
+		copy(cols, cteScope.cols)
+		for i := range cols {
+			cols[i].name = name.Cols[i]
+			cols[i].table = tableName
+		}
+	}
+
+	if len(cols) == 0 {
+		panic(pgerror.Newf(pgcode.FeatureNotSupported,
+			"WITH clause %q does not have a RETURNING clause", tree.ErrString(&name.Alias)))
+	}
+
+	return cols
+}
+
+// buildRecursiveCTE builds a CTE in a WITH RECURSIVE clause. A recursive CTE
+// must have the form:
+//
+//   <initial query> UNION [ALL] <recursive query>
+//
+// where only the recursive query references the CTE, exactly once. The
+// recursive query is built against a WithScan which stands for the rows
+// produced by the previous iteration (the "working table").
+//
+// A CTE that does not reference itself is built like a regular CTE.
+func (b *Builder) buildRecursiveCTE(
+	cte *tree.CTE, inScope *scope,
+) (expr memo.RelExpr, cols []scopeColumn) {
+	name := cte.Name.Alias
+	nameStr := name.String()
+
+	// Register a placeholder for the CTE, which is used to detect (invalid)
+	// references to the CTE outside of the recursive query.
+	var onRef func()
+	cteScope := inScope.push()
+	cteScope.ctes = map[string]*cteSource{nameStr: {
+		name:  cte.Name,
+		onRef: func() { onRef() },
+	}}
+
+	initialClause, recursiveClause, isUnionAll, ok := splitRecursiveCTE(cte.Stmt)
+	if !ok {
+		onRef = func() {
+			panic(pgerror.Newf(pgcode.InvalidRecursion,
+				"recursive query %q does not have the form non-recursive-term UNION [ALL] recursive-term",
+				name,
+			))
+		}
+		stmtScope := b.buildStmt(cte.Stmt, nil /* desiredTypes */, cteScope)
+		return stmtScope.expr.(memo.RelExpr), b.getCTECols(stmtScope, cte.Name)
+	}
+
+	onRef = func() {
+		panic(pgerror.Newf(pgcode.InvalidRecursion,
+			"recursive reference to query %q must not appear within its non-recursive term",
+			name,
+		))
+	}
+	initialScope := b.buildSelect(initialClause, nil /* desiredTypes */, cteScope)
+	initialScope.removeHiddenCols()
+	initialCols := b.getCTECols(initialScope, cte.Name)
+
+	// Synthesize the columns of the working table, which is scanned by the
+	// recursive query.
+	md := b.factory.Metadata()
+	withID := md.NextWithID()
+	md.AddWithBinding(withID, initialScope.expr.(memo.RelExpr))
+
+	workingScope := cteScope.push()
+	for i := range initialCols {
+		c := b.synthesizeColumn(
+			workingScope, string(initialCols[i].name), initialCols[i].typ, nil, nil, /* scalar */
+		)
+		c.table = initialCols[i].table
+	}
+	workingExpr := b.factory.ConstructWithScan(&memo.WithScanPrivate{
+		ID:      withID,
+		Name:    nameStr,
+		OutCols: colsToColList(workingScope.cols),
+	})
+
+	numRefs := 0
+	onRef = func() {
+		numRefs++
+		if numRefs > 1 {
+			panic(pgerror.Newf(pgcode.InvalidRecursion,
+				"recursive reference to query %q must not appear more than once",
+				name,
+			))
+		}
+	}
+	cteScope.ctes[nameStr].cols = workingScope.cols
+	cteScope.ctes[nameStr].expr = workingExpr
+
+	recursiveScope := b.buildSelect(recursiveClause, nil /* desiredTypes */, cteScope)
+	recursiveScope.removeHiddenCols()
+
+	if numRefs == 0 {
+		// The query doesn't reference itself, so it is a regular set operation.
+		stmtScope := b.buildSetOp(tree.UnionOp, isUnionAll, cteScope, initialScope, recursiveScope)
+		return stmtScope.expr.(memo.RelExpr), b.getCTECols(stmtScope, cte.Name)
+	}
+
+	// The columns of the recursive query must match the columns of the initial
+	// query.
+	if len(recursiveScope.cols) != len(initialCols) {
+		panic(pgerror.Newf(pgcode.Syntax,
+			"each UNION query must have the same number of columns: %d vs %d",
+			len(initialCols), len(recursiveScope.cols),
+		))
+	}
+	propagateTypes := false
+	for i := range initialCols {
+		initialTyp, recursiveTyp := initialCols[i].typ, recursiveScope.cols[i].typ
+		if recursiveTyp.Family() == types.UnknownFamily {
+			propagateTypes = true
+			continue
+		}
+		if !initialTyp.Equivalent(recursiveTyp) {
+			panic(pgerror.Newf(pgcode.DatatypeMismatch,
+				"recursive query %q column %d has type %s in non-recursive term but type %s overall",
+				name, i+1, initialTyp, recursiveTyp,
+			))
+		}
+	}
+	if propagateTypes {
+		recursiveScope = b.propagateTypes(recursiveScope, initialScope)
+	}
+
+	// Synthesize the output columns of the recursive CTE.
+	outScope := cteScope.push()
+	for i := range initialCols {
+		c := b.synthesizeColumn(
+			outScope, string(initialCols[i].name), initialCols[i].typ, nil, nil, /* scalar */
+		)
+		c.table = initialCols[i].table
+	}
+
+	expr = b.factory.ConstructRecursiveCTE(
+		initialScope.expr.(memo.RelExpr),
+		recursiveScope.expr.(memo.RelExpr),
+		&memo.RecursiveCTEPrivate{
+			Name:          nameStr,
+			WithID:        withID,
+			InitialCols:   colsToColList(initialScope.cols),
+			RecursiveCols: colsToColList(recursiveScope.cols),
+			OutCols:       colsToColList(outScope.cols),
+			Deduplicate:   !isUnionAll,
+		},
+	)
+	return expr, outScope.cols
+}
+
+// splitRecursiveCTE splits the statement of a recursive CTE into its initial
+// and recursive queries. Returns ok=false if the statement does not have the
+// form <initial> UNION [ALL] <recursive>.
+func splitRecursiveCTE(
+	stmt tree.Statement,
+) (initial, recursive *tree.Select, isUnionAll bool, ok bool) {
+	sel, ok := stmt.(*tree.Select)
+	if !ok || sel.With != nil || sel.OrderBy != nil || sel.Limit != nil {
+		return nil, nil, false, false
+	}
+	for {
+		paren, ok := sel.Select.(*tree.ParenSelect)
+		if !ok {
+			break
+		}
+		sel = paren.Select
+		if sel.With != nil || sel.OrderBy != nil || sel.Limit != nil {
+			return nil, nil, false, false
+		}
+	}
+	union, ok := sel.Select.(*tree.UnionClause)
+	if !ok || union.Type != tree.UnionOp {
+		return nil, nil, false, false
+	}
+	return union.Left, union.Right, union.All, true
+}
+
 // checkCTEUsage ensures that a CTE that contains a mutation (like INSERT) is
 // used at least once by the query. Otherwise, it might not be executed.
 func (b *Builder) checkCTEUsage(inScope *scope) {
@@ -577,6 +823,7 @@ func (b *Builder) buildSelect(
 	orderBy := stmt.OrderBy
 	limit := stmt.Limit
 	with := stmt.With
+	locking := stmt.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		stmt = s.Select
@@ -606,13 +853,25 @@ func (b *Builder) buildSelect(
 			}
 			limit = stmt.Limit
 		}
+		locking = append(locking[:len(locking):len(locking)], stmt.Locking...)
 	}
 
 	if with != nil {
-		inScope = b.buildCTE(with.CTEList, inScope)
+		inScope = b.buildCTE(with, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
+	if len(locking) > 0 {
+		b.validateLockingInSelect(locking, stmt.Select)
+		telemetry.Inc(sqltelemetry.SelectForUpdateCounter)
+
+		// The locking clause applies to the data sources in the FROM clause of
+		// this statement, in addition to any locking inherited from an enclosing
+		// statement.
+		defer func(locking lockingSpec) { b.locking = locking }(b.locking)
+		b.locking = append(b.locking[:len(b.locking):len(b.locking)], locking...)
+	}
+
 	// NB: The case statements are sorted lexicographically.
 	switch t := stmt.Select.(type) {
 	case *tree.SelectClause:
@@ -663,6 +922,13 @@ func (b *Builder) buildSelectClause(
 	sel *tree.SelectClause, orderBy tree.OrderBy, desiredTypes []*types.T, inScope *scope,
 ) (outScope *scope) {
 	fromScope := b.buildFrom(sel.From, inScope)
+
+	// Locking clauses only apply to the data sources in the FROM clause, and
+	// not to any subqueries in the rest of the statement.
+	locking := b.locking
+	defer func() { b.locking = locking }()
+	b.locking = nil
+
 	b.processWindowDefs(sel, fromScope)
 	b.buildWhere(sel.Where, fromScope)
 
@@ -680,6 +946,10 @@ func (b *Builder) buildSelectClause(
 	orderByScope := b.analyzeOrderBy(orderBy, fromScope, projectionsScope)
 	distinctOnScope := b.analyzeDistinctOnArgs(sel.DistinctOn, fromScope, projectionsScope)
 
+	if locking.isSet() {
+		b.validateLockingInSelectClause(locking, sel, fromScope)
+	}
+
 	var groupingCols []scopeColumn
 	var having opt.ScalarExpr
 	needsAgg := b.needsAggregation(sel, fromScope)
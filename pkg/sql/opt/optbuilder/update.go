@@ -55,6 +55,20 @@ import (
 //   LEFT JOIN LATERAL (SELECT y FROM xyz WHERE x=a)
 //   ON True
 //
+// A FROM clause joins the target table with other tables, whose columns can
+// then be referenced by the WHERE clause and SET expressions:
+//
+//   UPDATE abc SET b=y FROM xyz WHERE a=x
+//   =>
+//   SELECT DISTINCT ON (a) a AS oa, b AS ob, c AS oc, y AS nb
+//   FROM abc, xyz
+//   WHERE a=x
+//
+// The DISTINCT ON ensures that each row is updated at most once, even if it
+// matches several rows of the FROM tables. In that case, the values of an
+// arbitrary one of the matching rows are used, as in Postgres. The RETURNING
+// clause can reference the columns of the FROM tables as well.
+//
 // Computed columns result in an additional wrapper projection that can depend
 // on input columns.
 //
@@ -75,7 +89,7 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	}
 
 	if upd.With != nil {
-		inScope = b.buildCTE(upd.With.CTEList, inScope)
+		inScope = b.buildCTE(upd.With, inScope)
 		defer b.checkCTEUsage(inScope)
 	}
 
@@ -85,7 +99,7 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	tn, alias := getAliasedTableName(upd.Table)
 
 	// Find which table we're working on, check the permissions.
-	tab, resName := b.resolveTable(tn, privilege.UPDATE)
+	tab, resName := b.resolveTableForMutation(tn, privilege.UPDATE)
 	if alias == nil {
 		alias = &resName
 	}
@@ -99,11 +113,12 @@ func (b *Builder) buildUpdate(upd *tree.Update, inScope *scope) (outScope *scope
 	// Build the input expression that selects the rows that will be updated:
 	//
 	//   WITH <with>
-	//   SELECT <cols> FROM <table> WHERE <where>
+	//   SELECT <cols> FROM <table>, <from> WHERE <where>
 	//   ORDER BY <order-by> LIMIT <limit>
 	//
-	// All columns from the update table will be projected.
-	mb.buildInputForUpdateOrDelete(inScope, upd.Where, upd.Limit, upd.OrderBy)
+	// All columns from the update table will be projected, followed by the
+	// columns of any FROM tables.
+	mb.buildInputForUpdateOrDelete(inScope, upd.From, upd.Where, upd.Limit, upd.OrderBy)
 
 	// Derive the columns that will be updated from the SET expressions.
 	mb.addTargetColsForUpdate(upd.Exprs)
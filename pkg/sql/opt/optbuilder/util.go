@@ -457,6 +457,20 @@ func (b *Builder) resolveTable(
 	return tab, resName
 }
 
+// resolveTableForMutation is similar to resolveTable, but also raises an error
+// if the table is a materialized view, since its contents can only be changed
+// by REFRESH MATERIALIZED VIEW.
+func (b *Builder) resolveTableForMutation(
+	tn *tree.TableName, priv privilege.Kind,
+) (cat.Table, tree.TableName) {
+	tab, resName := b.resolveTable(tn, priv)
+	if tab.IsMaterializedView() {
+		panic(builderError{pgerror.Newf(pgcode.WrongObjectType,
+			"cannot mutate materialized view %q", tree.ErrString(tn))})
+	}
+	return tab, resName
+}
+
 // resolveDataSource returns the data source in the catalog with the given name.
 // If the name does not resolve to a table, or if the current user does not have
 // the given privilege, then resolveDataSource raises an error.
@@ -140,6 +140,13 @@ type TableMeta struct {
 	// in certain queries. See comment above GenerateConstrainedScans for more
 	// detail.
 	constraints []ScalarExpr
+
+	// partialIndexPredicates maps the ordinals of the table's partial indexes
+	// to their predicates, stored in the ScalarExpr form so that they can be
+	// compared with the filters of a query. A partial index can only be used
+	// when the filters imply its predicate; see the comment above
+	// scanIndexIter for more detail.
+	partialIndexPredicates map[int]ScalarExpr
 }
 
 // clearAnnotations resets all the table annotations; used when copying a
@@ -194,6 +201,23 @@ func (tm *TableMeta) AddConstraint(constraint ScalarExpr) {
 	tm.constraints = append(tm.constraints, constraint)
 }
 
+// PartialIndexPredicate returns the predicate of the partial index with the
+// given ordinal, and true. If the index is not partial, or if its predicate
+// was not added to the table's metadata, PartialIndexPredicate returns false.
+func (tm *TableMeta) PartialIndexPredicate(indexOrd int) (ScalarExpr, bool) {
+	pred, ok := tm.partialIndexPredicates[indexOrd]
+	return pred, ok
+}
+
+// AddPartialIndexPredicate adds the predicate of the partial index with the
+// given ordinal to the table's metadata.
+func (tm *TableMeta) AddPartialIndexPredicate(indexOrd int, pred ScalarExpr) {
+	if tm.partialIndexPredicates == nil {
+		tm.partialIndexPredicates = make(map[int]ScalarExpr)
+	}
+	tm.partialIndexPredicates[indexOrd] = pred
+}
+
 // TableAnnotation returns the given annotation that is associated with the
 // given table. If the table has no such annotation, TableAnnotation returns
 // nil.
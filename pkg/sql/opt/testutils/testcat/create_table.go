@@ -269,8 +269,11 @@ func (tc *Catalog) resolveFK(tab *Table, d *tree.ForeignKeyConstraintTableDef) {
 
 	// matches returns true if the key columns in the given index match the given
 	// columns. If strict is false, it is acceptable if the given columns are a
-	// prefix of the index key columns.
+	// prefix of the index key columns. Partial indexes never match.
 	matches := func(idx *Index, cols []int, strict bool) bool {
+		if idx.predicate != "" {
+			return false
+		}
 		if idx.LaxKeyColumnCount() < len(cols) {
 			return false
 		}
@@ -374,6 +377,9 @@ func (tt *Table) addIndex(def *tree.IndexTableDef, typ indexType) *Index {
 		IdxZone:  &config.ZoneConfig{},
 		table:    tt,
 	}
+	if def.Predicate != nil {
+		idx.predicate = tree.Serialize(def.Predicate)
+	}
 
 	// Look for name suffixes indicating this is a mutation index.
 	if name, ok := extractWriteOnlyIndex(def); ok {
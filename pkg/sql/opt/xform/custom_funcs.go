@@ -262,9 +262,10 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 	// Consider the checkFilters as well to constrain each of the indexes.
 	filters := append(explicitFilters, checkFilters...)
 
-	// Iterate over all indexes.
+	// Iterate over all indexes, including the partial indexes whose predicates
+	// are implied by the filters.
 	var iter scanIndexIter
-	iter.init(c.e.mem, scanPrivate)
+	iter.initWithFilters(c.e.evalCtx, c.e.mem, scanPrivate, filters)
 	for iter.next() {
 		// Check whether the filter can constrain the index.
 		constraintFilters, remainingFilters, ok := c.tryConstrainIndex(
@@ -327,6 +328,71 @@ func (c *CustomFuncs) GenerateConstrainedScans(
 	}
 }
 
+// HasPartialIndexes returns true if at least one partial index is defined on
+// the Scan operator's table.
+func (c *CustomFuncs) HasPartialIndexes(scanPrivate *memo.ScanPrivate) bool {
+	tab := c.e.mem.Metadata().Table(scanPrivate.Table)
+	for i, n := 0, tab.IndexCount(); i < n; i++ {
+		if _, isPartial := tab.Index(i).Predicate(); isPartial {
+			return true
+		}
+	}
+	return false
+}
+
+// GeneratePartialIndexScans enumerates the partial indexes on the Scan
+// operator's table whose predicates are implied by the given filters, and
+// generates an unconstrained Scan operator over each of them, wrapped by a
+// Select operator that applies the filters. If the partial index does not
+// include all the needed columns, an IndexJoin is introduced to supply them.
+// For example, given:
+//
+//   CREATE TABLE t (k INT PRIMARY KEY, u INT, active BOOL, INDEX (u) WHERE active)
+//   SELECT k, u FROM t WHERE active
+//
+// the partial index is scanned in its entirety, since it only contains the
+// rows for which active is true.
+func (c *CustomFuncs) GeneratePartialIndexScans(
+	grp memo.RelExpr, scanPrivate *memo.ScanPrivate, filters memo.FiltersExpr,
+) {
+	var sb indexScanBuilder
+	sb.init(c, scanPrivate.Table)
+
+	var iter scanIndexIter
+	iter.initWithFilters(c.e.evalCtx, c.e.mem, scanPrivate, filters)
+	for iter.next() {
+		if _, isPartial := iter.index.Predicate(); !isPartial {
+			continue
+		}
+
+		newScanPrivate := *scanPrivate
+		newScanPrivate.Index = iter.indexOrdinal
+
+		// If the partial index includes the set of needed columns, then construct
+		// a new Scan operator using that index.
+		if iter.isCovering() {
+			sb.setScan(&newScanPrivate)
+			sb.addSelect(filters)
+			sb.build(grp)
+			continue
+		}
+
+		// Otherwise, construct an IndexJoin operator that provides the columns
+		// missing from the index.
+		if scanPrivate.Flags.NoIndexJoin {
+			continue
+		}
+		newScanPrivate.Cols = iter.indexCols().Intersection(scanPrivate.Cols)
+		newScanPrivate.Cols.UnionWith(sb.primaryKeyCols())
+		sb.setScan(&newScanPrivate)
+
+		remainingFilters := sb.addSelectAfterSplit(filters, newScanPrivate.Cols)
+		sb.addIndexJoin(scanPrivate.Cols)
+		sb.addSelect(remainingFilters)
+		sb.build(grp)
+	}
+}
+
 // HasInvertedIndexes returns true if at least one inverted index is defined on
 // the Scan operator's table.
 func (c *CustomFuncs) HasInvertedIndexes(scanPrivate *memo.ScanPrivate) bool {
@@ -350,9 +416,10 @@ func (c *CustomFuncs) GenerateInvertedIndexScans(
 	var sb indexScanBuilder
 	sb.init(c, scanPrivate.Table)
 
-	// Iterate over all inverted indexes.
+	// Iterate over all inverted indexes, including the partial indexes whose
+	// predicates are implied by the filters.
 	var iter scanIndexIter
-	iter.init(c.e.mem, scanPrivate)
+	iter.initWithFilters(c.e.evalCtx, c.e.mem, scanPrivate, filters)
 	for iter.nextInverted() {
 		// Check whether the filter can constrain the index.
 		constraint, remaining, ok := c.tryConstrainIndex(
@@ -790,6 +857,11 @@ func (c *CustomFuncs) GenerateLookupJoins(
 	if joinPrivate.Flags.DisallowLookupJoin {
 		return
 	}
+	if scanPrivate.IsLocking() && joinType != opt.InnerJoinOp && joinType != opt.LeftJoinOp {
+		// The lookup joiner only locks the rows that it looks up for inner and
+		// left joins.
+		return
+	}
 	inputProps := input.Relational()
 
 	leftEq, rightEq := memo.ExtractJoinEqualityColumns(inputProps.OutputCols, scanPrivate.Cols, on)
@@ -882,6 +954,7 @@ func (c *CustomFuncs) GenerateLookupJoins(
 		if iter.isCovering() {
 			// Case 1 (see function comment).
 			lookupJoin.Cols = scanPrivate.Cols.Union(inputProps.OutputCols)
+			lookupJoin.Locking = scanPrivate.Locking
 			c.e.mem.AddLookupJoinToGroup(&lookupJoin, grp)
 			continue
 		}
@@ -953,6 +1026,9 @@ func (c *CustomFuncs) GenerateLookupJoins(
 		indexJoin.Index = cat.PrimaryIndex
 		indexJoin.KeyCols = pkCols
 		indexJoin.Cols = scanPrivate.Cols.Union(inputProps.OutputCols)
+		// The rows are locked by the index join, after the lower lookup join
+		// has filtered them.
+		indexJoin.Locking = scanPrivate.Locking
 
 		// Create the LookupJoin for the index join in the same group.
 		c.e.mem.AddLookupJoinToGroup(&indexJoin, grp)
@@ -1085,6 +1161,11 @@ func (c *CustomFuncs) GenerateZigzagJoins(
 		return
 	}
 
+	// Zigzag joins do not acquire row locks.
+	if scanPrivate.IsLocking() {
+		return
+	}
+
 	fixedCols := memo.ExtractConstColumns(filters, c.e.mem, c.e.evalCtx)
 
 	if fixedCols.Len() == 0 {
@@ -1309,6 +1390,11 @@ func (c *CustomFuncs) GenerateInvertedIndexZigzagJoins(
 		return
 	}
 
+	// Zigzag joins do not acquire row locks.
+	if scanPrivate.IsLocking() {
+		return
+	}
+
 	var sb indexScanBuilder
 	sb.init(c, scanPrivate.Table)
 
@@ -1610,6 +1696,9 @@ func (c *CustomFuncs) MakeOrderingChoiceFromColumn(
 //     doSomething(iter.indexOrdinal)
 //   }
 //
+// Partial indexes only contain the rows that satisfy their predicate, so they
+// are skipped unless the iterator was initialized with initWithFilters, and the
+// given filters imply the predicate.
 type scanIndexIter struct {
 	mem          *memo.Memo
 	scanPrivate  *memo.ScanPrivate
@@ -1617,6 +1706,10 @@ type scanIndexIter struct {
 	indexOrdinal int
 	index        cat.Index
 	cols         opt.ColSet
+
+	// evalCtx and filters are only set by initWithFilters.
+	evalCtx *tree.EvalContext
+	filters memo.FiltersExpr
 }
 
 func (it *scanIndexIter) init(mem *memo.Memo, scanPrivate *memo.ScanPrivate) {
@@ -1625,6 +1718,21 @@ func (it *scanIndexIter) init(mem *memo.Memo, scanPrivate *memo.ScanPrivate) {
 	it.tab = mem.Metadata().Table(scanPrivate.Table)
 	it.indexOrdinal = -1
 	it.index = nil
+	it.evalCtx = nil
+	it.filters = nil
+}
+
+// initWithFilters is like init, but also enumerates the partial indexes whose
+// predicates are implied by the given filters.
+func (it *scanIndexIter) initWithFilters(
+	evalCtx *tree.EvalContext,
+	mem *memo.Memo,
+	scanPrivate *memo.ScanPrivate,
+	filters memo.FiltersExpr,
+) {
+	it.init(mem, scanPrivate)
+	it.evalCtx = evalCtx
+	it.filters = filters
 }
 
 // next advances iteration to the next index of the Scan operator's table. This
@@ -1644,6 +1752,9 @@ func (it *scanIndexIter) next() bool {
 		if it.index.IsInverted() {
 			continue
 		}
+		if !it.isPartialIndexUsable() {
+			continue
+		}
 		if it.scanPrivate.Flags.ForceIndex && it.scanPrivate.Flags.Index != it.indexOrdinal {
 			// If we are forcing a specific index, ignore the others.
 			continue
@@ -1669,6 +1780,9 @@ func (it *scanIndexIter) nextInverted() bool {
 		if !it.index.IsInverted() {
 			continue
 		}
+		if !it.isPartialIndexUsable() {
+			continue
+		}
 		if it.scanPrivate.Flags.ForceIndex && it.scanPrivate.Flags.Index != it.indexOrdinal {
 			// If we are forcing a specific index, ignore the others.
 			continue
@@ -1678,6 +1792,86 @@ func (it *scanIndexIter) nextInverted() bool {
 	}
 }
 
+// isPartialIndexUsable returns true if the current index is not a partial
+// index, or if it is a partial index whose predicate is implied by the filters
+// the iterator was initialized with.
+func (it *scanIndexIter) isPartialIndexUsable() bool {
+	if _, isPartial := it.index.Predicate(); !isPartial {
+		return true
+	}
+	if it.filters == nil {
+		return false
+	}
+	tabMeta := it.mem.Metadata().TableMeta(it.scanPrivate.Table)
+	pred, ok := tabMeta.PartialIndexPredicate(it.indexOrdinal)
+	if !ok {
+		// The predicate could not be built for this scan.
+		return false
+	}
+	return filtersImplyPredicate(it.evalCtx, it.mem, it.filters, pred)
+}
+
+// filtersImplyPredicate returns true if every row that satisfies the given
+// filters is guaranteed to satisfy the given partial index predicate. This is
+// a conservative check: each conjunct of the predicate must either be
+// identical to one of the filter conditions, or be exactly equivalent to a
+// constraint that contains the constraint derived from one of the filters. For
+// example, the filter a = 5 implies the predicate a > 0, and the filter
+// "b AND a < 10" implies the predicate b.
+func filtersImplyPredicate(
+	evalCtx *tree.EvalContext, mem *memo.Memo, filters memo.FiltersExpr, pred opt.ScalarExpr,
+) bool {
+	switch t := pred.(type) {
+	case *memo.AndExpr:
+		return filtersImplyPredicate(evalCtx, mem, filters, t.Left) &&
+			filtersImplyPredicate(evalCtx, mem, filters, t.Right)
+
+	case *memo.TrueExpr:
+		return true
+	}
+
+	// Scalar expressions are interned, so identical conditions are identical
+	// pointers.
+	for i := range filters {
+		if filters[i].Condition == pred {
+			return true
+		}
+	}
+
+	predItem := memo.FiltersItem{Condition: pred}
+	predProps := predItem.ScalarProps(mem)
+	if predProps.Constraints == nil || !predProps.TightConstraints ||
+		predProps.Constraints.Length() != 1 {
+		return false
+	}
+	predConstraint := predProps.Constraints.Constraint(0)
+	for i := range filters {
+		filterConstraints := filters[i].ScalarProps(mem).Constraints
+		if filterConstraints == nil {
+			continue
+		}
+		// Each of the filter's constraints is implied by the filter, whether or
+		// not they are tight.
+		for j, n := 0, filterConstraints.Length(); j < n; j++ {
+			c := filterConstraints.Constraint(j)
+			if !c.Columns.Equals(&predConstraint.Columns) {
+				continue
+			}
+			contained := true
+			for k, spanCount := 0, c.Spans.Count(); k < spanCount; k++ {
+				if !predConstraint.ContainsSpan(evalCtx, c.Spans.Get(k)) {
+					contained = false
+					break
+				}
+			}
+			if contained {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // indexCols returns the set of columns contained in the current index.
 func (it *scanIndexIter) indexCols() opt.ColSet {
 	if it.cols.Empty() {
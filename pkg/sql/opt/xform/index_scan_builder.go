@@ -15,6 +15,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/cat"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/memo"
 	"github.com/cockroachdb/cockroach/pkg/sql/opt/norm"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/errors"
 )
 
@@ -127,7 +128,9 @@ func (b *indexScanBuilder) addSelectAfterSplit(
 }
 
 // addIndexJoin wraps the input expression with an IndexJoin expression that
-// produces the given set of columns by lookup in the primary index.
+// produces the given set of columns by lookup in the primary index. If the scan
+// is locking, the locks are acquired by the IndexJoin rather than the scan, so
+// that only the rows that pass the inner filters are locked.
 func (b *indexScanBuilder) addIndexJoin(cols opt.ColSet) {
 	if b.indexJoinPrivate.Table != 0 {
 		panic(errors.AssertionFailedf("cannot call addIndexJoin twice"))
@@ -136,9 +139,11 @@ func (b *indexScanBuilder) addIndexJoin(cols opt.ColSet) {
 		panic(errors.AssertionFailedf("cannot add index join after an outer filter has been added"))
 	}
 	b.indexJoinPrivate = memo.IndexJoinPrivate{
-		Table: b.tabID,
-		Cols:  cols,
+		Table:   b.tabID,
+		Cols:    cols,
+		Locking: b.scanPrivate.Locking,
 	}
+	b.scanPrivate.Locking = tree.ForNone
 }
 
 // build constructs the final memo expression by composing together the various
@@ -72,6 +72,7 @@ func (ef *execFactory) ConstructScan(
 	reverse bool,
 	maxResults uint64,
 	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
 ) (exec.Node, error) {
 	tabDesc := table.(*optTable).desc
 	indexDesc := index.(*optIndex).desc
@@ -98,6 +99,7 @@ func (ef *execFactory) ConstructScan(
 	scan.hardLimit = hardLimit
 	scan.reverse = reverse
 	scan.maxResults = maxResults
+	scan.lockingStrength = sqlbase.ToScanLockingStrength(locking)
 	scan.parallelScansEnabled = sqlbase.ParallelScans.Get(&ef.planner.extendedEvalCtx.Settings.SV)
 	var err error
 	scan.spans, err = spansFromConstraint(
@@ -540,7 +542,11 @@ func (ef *execFactory) ConstructOrdinality(input exec.Node, colName string) (exe
 
 // ConstructIndexJoin is part of the exec.Factory interface.
 func (ef *execFactory) ConstructIndexJoin(
-	input exec.Node, table cat.Table, cols exec.ColumnOrdinalSet, reqOrdering exec.OutputOrdering,
+	input exec.Node,
+	table cat.Table,
+	cols exec.ColumnOrdinalSet,
+	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
 ) (exec.Node, error) {
 	tabDesc := table.(*optTable).desc
 	colCfg := makeScanColumnsConfig(table, cols)
@@ -575,6 +581,7 @@ func (ef *execFactory) ConstructIndexJoin(
 	tableScan.index = &primaryIndex
 	tableScan.isSecondaryIndex = false
 	tableScan.disableBatchLimit()
+	tableScan.lockingStrength = sqlbase.ToScanLockingStrength(locking)
 
 	primaryKeyColumns, colIDtoRowIndex := processIndexJoinColumns(tableScan, scan)
 	primaryKeyPrefix := roachpb.Key(sqlbase.MakeIndexKeyPrefix(tabDesc.TableDesc(), tableScan.index.ID))
@@ -605,6 +612,7 @@ func (ef *execFactory) ConstructLookupJoin(
 	lookupCols exec.ColumnOrdinalSet,
 	onCond tree.TypedExpr,
 	reqOrdering exec.OutputOrdering,
+	locking tree.LockingStrength,
 ) (exec.Node, error) {
 	tabDesc := table.(*optTable).desc
 	indexDesc := index.(*optIndex).desc
@@ -617,6 +625,7 @@ func (ef *execFactory) ConstructLookupJoin(
 
 	tableScan.index = indexDesc
 	tableScan.isSecondaryIndex = (indexDesc != &tabDesc.PrimaryIndex)
+	tableScan.lockingStrength = sqlbase.ToScanLockingStrength(locking)
 
 	n := &lookupJoinNode{
 		input:    input.(planNode),
@@ -846,6 +855,26 @@ func (ef *execFactory) ConstructWindow(root exec.Node, wi exec.WindowInfo) (exec
 	return p, nil
 }
 
+// ConstructRecursiveCTE is part of the exec.Factory interface.
+func (ef *execFactory) ConstructRecursiveCTE(
+	initial exec.Node, fn exec.RecursiveCTEIterationFn, label string, deduplicate bool,
+) (exec.Node, error) {
+	return &recursiveCTENode{
+		initial:        initial.(planNode),
+		genIterationFn: fn,
+		label:          label,
+		deduplicate:    deduplicate,
+	}, nil
+}
+
+// ConstructScanBuffer is part of the exec.Factory interface.
+func (ef *execFactory) ConstructScanBuffer(ref exec.Node, label string) (exec.Node, error) {
+	return &scanBufferNode{
+		buffer: ref.(*bufferNode),
+		label:  label,
+	}, nil
+}
+
 // ConstructPlan is part of the exec.Factory interface.
 func (ef *execFactory) ConstructPlan(
 	root exec.Node, subqueries []exec.Subquery, postqueries []exec.Node,
@@ -1174,7 +1203,7 @@ func (ef *execFactory) ConstructInsert(
 		checkFKs = row.SkipFKs
 	}
 	ri, err := row.MakeInserter(ef.planner.txn, tabDesc, fkTables, colDescs,
-		checkFKs, &ef.planner.alloc)
+		checkFKs, ef.planner.EvalContext(), &ef.planner.alloc)
 	if err != nil {
 		return nil, err
 	}
@@ -1222,6 +1251,7 @@ func (ef *execFactory) ConstructUpdate(
 	table cat.Table,
 	fetchCols exec.ColumnOrdinalSet,
 	updateCols exec.ColumnOrdinalSet,
+	passthrough sqlbase.ResultColumns,
 	checks exec.CheckOrdinalSet,
 	rowsNeeded bool,
 ) (exec.Node, error) {
@@ -1282,8 +1312,9 @@ func (ef *execFactory) ConstructUpdate(
 	var returnCols sqlbase.ResultColumns
 	if rowsNeeded {
 		// Update always returns all non-mutation columns, in the same order they
-		// are defined in the table.
+		// are defined in the table, followed by any passthrough columns.
 		returnCols = sqlbase.ResultColumnsFromColDescs(tabDesc.Columns)
+		returnCols = append(returnCols, passthrough...)
 	}
 
 	// updateColsIdx inverts the mapping of UpdateCols to FetchCols. See
@@ -1299,9 +1330,10 @@ func (ef *execFactory) ConstructUpdate(
 		source:  input.(planNode),
 		columns: returnCols,
 		run: updateRun{
-			tu:          tableUpdater{ru: ru},
-			checkHelper: checkHelper,
-			rowsNeeded:  rowsNeeded,
+			tu:             tableUpdater{ru: ru},
+			checkHelper:    checkHelper,
+			rowsNeeded:     rowsNeeded,
+			numPassthrough: len(passthrough),
 			iVarContainerForComputedCols: sqlbase.RowIndexedVarContainer{
 				CurSourceRow: make(tree.Datums, len(ru.FetchCols)),
 				Cols:         ru.FetchCols,
@@ -1369,7 +1401,7 @@ func (ef *execFactory) ConstructUpsert(
 
 	// Create the table inserter, which does the bulk of the insert-related work.
 	ri, err := row.MakeInserter(ef.planner.txn, tabDesc, fkTables, insertColDescs,
-		row.CheckFKs, &ef.planner.alloc)
+		row.CheckFKs, ef.planner.EvalContext(), &ef.planner.alloc)
 	if err != nil {
 		return nil, err
 	}
@@ -1454,7 +1486,11 @@ func (ef *execFactory) ConstructUpsert(
 }
 
 func (ef *execFactory) ConstructDelete(
-	input exec.Node, table cat.Table, fetchCols exec.ColumnOrdinalSet, rowsNeeded bool,
+	input exec.Node,
+	table cat.Table,
+	fetchCols exec.ColumnOrdinalSet,
+	passthrough sqlbase.ResultColumns,
+	rowsNeeded bool,
 ) (exec.Node, error) {
 	// Derive table and column descriptors.
 	tabDesc := table.(*optTable).desc
@@ -1498,8 +1534,9 @@ func (ef *execFactory) ConstructDelete(
 	var returnCols sqlbase.ResultColumns
 	if rowsNeeded {
 		// Delete always returns all non-mutation columns, in the same order they
-		// are defined in the table.
+		// are defined in the table, followed by any passthrough columns.
 		returnCols = sqlbase.ResultColumnsFromColDescs(tabDesc.Columns)
+		returnCols = append(returnCols, passthrough...)
 	}
 
 	// Now make a delete node. We use a pool.
@@ -1508,8 +1545,9 @@ func (ef *execFactory) ConstructDelete(
 		source:  input.(planNode),
 		columns: returnCols,
 		run: deleteRun{
-			td:         tableDeleter{rd: rd, alloc: &ef.planner.alloc},
-			rowsNeeded: rowsNeeded,
+			td:             tableDeleter{rd: rd, alloc: &ef.planner.alloc},
+			rowsNeeded:     rowsNeeded,
+			numPassthrough: len(passthrough),
 		},
 	}
 
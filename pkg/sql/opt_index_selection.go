@@ -118,6 +118,11 @@ func (p *planner) selectIndex(
 			index: &s.desc.PrimaryIndex,
 		})
 		for i := range s.desc.Indexes {
+			// Partial indexes do not contain all rows, and this planner cannot
+			// prove that the filter implies their predicate.
+			if s.desc.Indexes[i].IsPartial() {
+				continue
+			}
 			candidates = append(candidates, &indexInfo{
 				desc:  s.desc,
 				index: &s.desc.Indexes[i],
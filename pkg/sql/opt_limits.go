@@ -206,12 +206,14 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *alterIndexNode:
 	case *alterTableNode:
 	case *alterSequenceNode:
+	case *alterTypeNode:
 	case *alterUserSetPasswordNode:
 	case *deleteRangeNode:
 	case *renameColumnNode:
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *scrubNode:
 	case *truncateNode:
 	case *commentOnColumnNode:
@@ -222,6 +224,7 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *CreateUserNode:
 	case *createViewNode:
 	case *createSequenceNode:
+	case *createTypeNode:
 	case *createStatsNode:
 	case *dropDatabaseNode:
 	case *dropIndexNode:
@@ -241,7 +244,7 @@ func (p *planner) applyLimit(plan planNode, numRows int64, soft bool) {
 	case *scatterNode:
 	case *scanBufferNode:
 
-	case *applyJoinNode, *lookupJoinNode, *zigzagJoinNode, *saveTableNode:
+	case *applyJoinNode, *lookupJoinNode, *zigzagJoinNode, *saveTableNode, *recursiveCTENode:
 		// These nodes are only planned by the optimizer.
 
 	default:
@@ -266,6 +266,21 @@ func ParseTableName(sql string) (*tree.UnresolvedObjectName, error) {
 	return rename.Name, nil
 }
 
+// ParseNameList parses a comma-separated list of names.
+func ParseNameList(sql string) (tree.NameList, error) {
+	// We wrap the names we want to parse into a dummy statement since our parser
+	// can only parse full statements.
+	stmt, err := ParseOne(fmt.Sprintf("CREATE STATISTICS x ON %s FROM t", sql))
+	if err != nil {
+		return nil, err
+	}
+	createStats, ok := stmt.AST.(*tree.CreateStats)
+	if !ok {
+		return nil, errors.AssertionFailedf("expected a CREATE STATISTICS statement, but found %T", stmt)
+	}
+	return createStats.ColumnNames, nil
+}
+
 // parseExprs parses one or more sql expressions.
 func parseExprs(exprs []string) (tree.Exprs, error) {
 	stmt, err := ParseOne(fmt.Sprintf("SET ROW (%s)", strings.Join(exprs, ",")))
@@ -94,11 +94,19 @@ func TestParse(t *testing.T) {
 		{`CREATE INVERTED INDEX a ON b.c (d)`},
 		{`CREATE INVERTED INDEX a ON b (c) STORING (d)`},
 		{`CREATE INVERTED INDEX a ON b (c) INTERLEAVE IN PARENT d (e)`},
+		{`CREATE INDEX a ON b (c) WHERE d > 0`},
+		{`CREATE INDEX IF NOT EXISTS a ON b (c) STORING (d) WHERE d IS NULL`},
+		{`CREATE UNIQUE INDEX a ON b (c) WHERE (d = 'active') AND (e IS NOT NULL)`},
+		{`CREATE INDEX ON a (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)) WHERE e`},
+		{`CREATE INVERTED INDEX a ON b (c) WHERE d > 0`},
 
 		{`CREATE TABLE a ()`},
 		{`EXPLAIN CREATE TABLE a ()`},
 		{`CREATE TABLE a (b INT8)`},
 		{`CREATE TABLE a (b INT8, c INT8)`},
+		{`CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE TEMPORARY TABLE IF NOT EXISTS a (b INT8)`},
+		{`CREATE TEMPORARY TABLE a AS SELECT * FROM b`},
 		{`CREATE TABLE a (b CHAR)`},
 		{`CREATE TABLE a (b CHAR(3))`},
 		{`CREATE TABLE a (b VARCHAR)`},
@@ -199,6 +207,8 @@ func TestParse(t *testing.T) {
 		{`CREATE TABLE a (b INT8, INDEX (b) STORING (c))`},
 		{`CREATE TABLE a (b INT8, c STRING, INDEX (b ASC, c DESC) STORING (c))`},
 		{`CREATE TABLE a (b INT8, INDEX (b) INTERLEAVE IN PARENT c (d, e))`},
+		{`CREATE TABLE a (b INT8, c BOOL, INDEX (b) WHERE c)`},
+		{`CREATE TABLE a (b INT8, c BOOL, UNIQUE INDEX foo (b) STORING (c) WHERE c)`},
 		{`CREATE TABLE a (b INT8, FAMILY (b))`},
 		{`CREATE TABLE a (b INT8, c STRING, FAMILY foo (b), FAMILY (c))`},
 		{`CREATE TABLE a (b INT8) INTERLEAVE IN PARENT foo (c, d)`},
@@ -266,6 +276,15 @@ func TestParse(t *testing.T) {
 		{`CREATE VIEW a AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a (x, y) AS VALUES (1, 'one'), (2, 'two')`},
 		{`CREATE VIEW a AS TABLE b`},
+		{`CREATE MATERIALIZED VIEW a AS SELECT * FROM b`},
+		{`CREATE MATERIALIZED VIEW a (x, y) AS SELECT c, d FROM b`},
+		{`REFRESH MATERIALIZED VIEW a`},
+		{`REFRESH MATERIALIZED VIEW a.b`},
+
+		{`CREATE TYPE a AS ENUM ()`},
+		{`CREATE TYPE a AS ENUM ('x', 'y')`},
+		{`CREATE TYPE a.b AS ENUM ('x')`},
+		{`EXPLAIN CREATE TYPE a AS ENUM ('x')`},
 
 		{`CREATE SEQUENCE a`},
 		{`EXPLAIN CREATE SEQUENCE a`},
@@ -309,6 +328,10 @@ func TestParse(t *testing.T) {
 		{`DELETE FROM a WHERE a = b RETURNING a + b`},
 		{`DELETE FROM a WHERE a = b RETURNING NOTHING`},
 		{`DELETE FROM a WHERE a = b ORDER BY c LIMIT d RETURNING e`},
+		{`DELETE FROM a USING b WHERE a.c = b.c`},
+		{`DELETE FROM a USING b, c WHERE (a.d = b.d) AND (b.e = c.e)`},
+		{`DELETE FROM a AS x USING a AS y WHERE x.b = y.b RETURNING x.c`},
+		{`DELETE FROM a USING b JOIN c ON b.d = c.d WHERE a.e = b.e`},
 
 		{`DISCARD ALL`},
 
@@ -346,6 +369,8 @@ func TestParse(t *testing.T) {
 		{`DROP VIEW IF EXISTS a, b RESTRICT`},
 		{`DROP VIEW a.b CASCADE`},
 		{`DROP VIEW a, b CASCADE`},
+		{`DROP MATERIALIZED VIEW a`},
+		{`DROP MATERIALIZED VIEW IF EXISTS a, b CASCADE`},
 		{`DROP SEQUENCE a`},
 		{`EXPLAIN DROP SEQUENCE a`},
 		{`DROP SEQUENCE a.b`},
@@ -738,6 +763,9 @@ func TestParse(t *testing.T) {
 		{`SELECT "FROM" FROM t`},
 		{`SELECT CAST(1 AS STRING)`},
 		{`SELECT ANNOTATE_TYPE(1, STRING)`},
+		{`SELECT CAST(1 AS greeting)`},
+		{`SELECT ANNOTATE_TYPE('hi', greeting)`},
+		{`SELECT greeting 'hi'`},
 		{`SELECT a FROM t AS bar`},
 		{`SELECT a FROM t AS bar (bar1)`},
 		{`SELECT a FROM t AS bar (bar1, bar2, bar3)`},
@@ -952,6 +980,11 @@ func TestParse(t *testing.T) {
 		{`SELECT a FROM t1 FULL MERGE JOIN t2 USING (a)`},
 		{`SELECT * FROM (t1 WITH ORDINALITY AS o1 CROSS JOIN t2 WITH ORDINALITY AS o2) WITH ORDINALITY AS o3`},
 
+		{`WITH a AS (SELECT 1) SELECT * FROM a`},
+		{`WITH RECURSIVE a AS (SELECT 1) SELECT * FROM a`},
+		{`WITH RECURSIVE a (n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM a WHERE n < 10) SELECT n FROM a`},
+		{`WITH RECURSIVE a (n) AS (SELECT 1 UNION SELECT n + 1 FROM a WHERE n < 10) SELECT n FROM a`},
+
 		{`SELECT a FROM t1 AS OF SYSTEM TIME '2016-01-01'`},
 		{`SELECT a FROM t1, t2 AS OF SYSTEM TIME '2016-01-01'`},
 		{`SELECT a FROM t1 AS OF SYSTEM TIME -('a' || 'b')::INTERVAL`},
@@ -963,6 +996,19 @@ func TestParse(t *testing.T) {
 		{`SELECT DISTINCT a, b FROM t`},
 		{`SELECT DISTINCT ON (a, b) c FROM t`},
 
+		{`SELECT * FROM t FOR UPDATE`},
+		{`SELECT * FROM t FOR NO KEY UPDATE`},
+		{`SELECT * FROM t FOR SHARE`},
+		{`SELECT * FROM t FOR KEY SHARE`},
+		{`SELECT * FROM t FOR UPDATE OF t`},
+		{`SELECT * FROM t, u FOR UPDATE OF t, u NOWAIT`},
+		{`SELECT * FROM t FOR SHARE SKIP LOCKED`},
+		{`SELECT * FROM t, u FOR UPDATE OF t FOR SHARE OF u`},
+		{`SELECT * FROM t ORDER BY a FOR UPDATE`},
+		{`SELECT * FROM t LIMIT 1 FOR UPDATE`},
+		{`(SELECT * FROM t) FOR UPDATE`},
+		{`WITH a AS (SELECT 1) SELECT * FROM a FOR UPDATE`},
+
 		{`SET a = 3`},
 		{`EXPLAIN SET a = 3`},
 		{`SET a = 3, 4`},
@@ -1034,6 +1080,10 @@ func TestParse(t *testing.T) {
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING a, a + b`},
 		{`UPDATE a SET b = 3 WHERE a = b RETURNING NOTHING`},
 		{`UPDATE a SET b = 3 WHERE a = b ORDER BY c LIMIT d RETURNING e`},
+		{`UPDATE a SET b = c.d FROM c WHERE a.e = c.e`},
+		{`UPDATE a SET b = c.d FROM c, d WHERE (a.e = c.e) AND (c.f = d.f)`},
+		{`UPDATE a AS x SET b = y.b FROM a AS y WHERE x.c = y.c RETURNING x.b`},
+		{`UPDATE a SET b = (SELECT 1) FROM (SELECT c FROM d) AS e WHERE a.c = e.c`},
 
 		{`UPDATE t AS "0" SET k = ''`},                 // "0" lost its quotes
 		{`SELECT * FROM "0" JOIN "0" USING (id, "0")`}, // last "0" lost its quotes.
@@ -1212,6 +1262,12 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN ALTER TABLE t EXPERIMENTAL_AUDIT SET READ WRITE`},
 		{`ALTER TABLE t EXPERIMENTAL_AUDIT SET OFF`},
 
+		{`ALTER TABLE t SET TTL '30 days' ON ts`},
+		{`ALTER TABLE IF EXISTS t SET TTL '1 hour' ON ts`},
+		{`ALTER TABLE t RESET TTL`},
+		{`ALTER INDEX t@i SET TTL '30 days'`},
+		{`ALTER INDEX t@i RESET TTL`},
+
 		{`COMMENT ON COLUMN a.b IS 'a'`},
 		{`COMMENT ON COLUMN a.b IS NULL`},
 		{`COMMENT ON COLUMN a.b.c IS 'a'`},
@@ -1225,6 +1281,11 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN ALTER SEQUENCE a RENAME TO b`},
 		{`ALTER SEQUENCE IF EXISTS a RENAME TO b`},
 
+		{`ALTER TYPE a ADD VALUE 'x'`},
+		{`ALTER TYPE a.b ADD VALUE IF NOT EXISTS 'x'`},
+		{`ALTER TYPE a ADD VALUE 'x' BEFORE 'y'`},
+		{`ALTER TYPE a ADD VALUE IF NOT EXISTS 'x' AFTER 'y'`},
+
 		{`ALTER SEQUENCE a INCREMENT BY 5 START WITH 1000`},
 		{`EXPLAIN ALTER SEQUENCE a INCREMENT BY 5 START WITH 1000`},
 		{`ALTER SEQUENCE IF EXISTS a INCREMENT BY 5 START WITH 1000`},
@@ -1252,6 +1313,7 @@ func TestParse(t *testing.T) {
 		{`EXPLAIN SHOW BACKUP 'bar'`},
 		{`SHOW BACKUP RANGES 'bar'`},
 		{`SHOW BACKUP FILES 'bar'`},
+		{`SHOW BACKUP 'bar' WITH encryption_passphrase = 'secret'`},
 
 		{`BACKUP TABLE foo TO 'bar' AS OF SYSTEM TIME '1' INCREMENTAL FROM 'baz'`},
 		{`BACKUP TABLE foo TO $1 INCREMENTAL FROM 'bar', $2, 'baz'`},
@@ -1276,6 +1338,13 @@ func TestParse(t *testing.T) {
 		{`BACKUP TABLE foo TO 'bar' WITH key1, key2 = 'value'`},
 		{`RESTORE TABLE foo FROM 'bar' WITH key1, key2 = 'value'`},
 
+		{`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE foo INTO 'bar' RECURRING '@daily'`},
+		{`EXPLAIN CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE foo INTO 'bar' RECURRING '@daily'`},
+		{`CREATE SCHEDULE $1 FOR BACKUP TABLE foo, baz INTO $2 WITH key1, key2 = 'value' RECURRING $3 FULL BACKUP '@weekly'`},
+		{`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE foo INTO 'bar' RECURRING '@hourly' FULL BACKUP '@daily' WITH SCHEDULE OPTIONS retention = '72h'`},
+		{`SHOW SCHEDULES`},
+		{`EXPLAIN SHOW SCHEDULES`},
+
 		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`EXPLAIN IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' CSV DATA ('path/to/some/file', $1) WITH temp = 'path/to/temp'`},
 		{`IMPORT TABLE foo CREATE USING 'nodelocal:///some/file' MYSQLOUTFILE DATA ('path/to/some/file', $1)`},
@@ -1339,6 +1408,9 @@ func TestParse2(t *testing.T) {
 			`CREATE DATABASE a TEMPLATE = 'invalid'`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b))`,
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b))`},
+		{`CREATE TEMP TABLE a (b INT)`, `CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE LOCAL TEMPORARY TABLE a (b INT)`, `CREATE TEMPORARY TABLE a (b INT8)`},
+		{`CREATE GLOBAL TEMP TABLE a AS SELECT 1`, `CREATE TEMPORARY TABLE a AS SELECT 1`},
 		{`CREATE TABLE a (b INT, UNIQUE INDEX foo (b) INTERLEAVE IN PARENT c (d))`,
 			`CREATE TABLE a (b INT8, CONSTRAINT foo UNIQUE (b) INTERLEAVE IN PARENT c (d))`},
 		{`CREATE TABLE a (UNIQUE INDEX (b) PARTITION BY LIST (c) (PARTITION d VALUES IN (1)))`,
@@ -1383,6 +1455,7 @@ func TestParse2(t *testing.T) {
 		{`SELECT CAST(1 AS "timestamp")`, `SELECT CAST(1 AS TIMESTAMP)`},
 		{`SELECT CAST(1 AS _int8)`, `SELECT CAST(1 AS INT8[])`},
 		{`SELECT CAST(1 AS "_int8")`, `SELECT CAST(1 AS INT8[])`},
+		{`SELECT 'f'::"Greeting"`, `SELECT 'f'::"Greeting"`},
 		{`SELECT SERIAL8 'foo', 'foo'::SERIAL8`, `SELECT INT8 'foo', 'foo'::INT8`},
 
 		{`SELECT 'a' FROM t@{FORCE_INDEX=bar}`, `SELECT 'a' FROM t@bar`},
@@ -1517,6 +1590,12 @@ func TestParse2(t *testing.T) {
 			`SELECT a FROM t LIMIT 2 * a OFFSET b`},
 		{`SELECT a FROM t FETCH FIRST (2 * a) ROWS ONLY OFFSET b`,
 			`SELECT a FROM t LIMIT 2 * a OFFSET b`},
+		// We allow the locking clause before LIMIT, but always output it last.
+		{`SELECT a FROM t FOR UPDATE LIMIT 1`,
+			`SELECT a FROM t LIMIT 1 FOR UPDATE`},
+		// FOR READ ONLY is a no-op.
+		{`SELECT a FROM t FOR READ ONLY`,
+			`SELECT a FROM t`},
 		// Double negation. See #1800.
 		{`SELECT *,-/* comment */-5`,
 			`SELECT *, 5`},
@@ -1737,6 +1816,8 @@ func TestParse2(t *testing.T) {
 
 		{`CREATE CHANGEFEED FOR TABLE foo INTO sink`,
 			`CREATE CHANGEFEED FOR TABLE foo INTO 'sink'`},
+		{`CREATE SCHEDULE nightly FOR BACKUP DATABASE foo INTO bar RECURRING '@daily' WITH SCHEDULE OPTIONS (retention = '24h')`,
+			`CREATE SCHEDULE 'nightly' FOR BACKUP DATABASE foo INTO 'bar' RECURRING '@daily' WITH SCHEDULE OPTIONS retention = '24h'`},
 
 		{`SHOW CLUSTER SETTING ALL`, `SHOW ALL CLUSTER SETTINGS`},
 
@@ -2283,11 +2364,6 @@ DETAIL: source SQL:
 SELECT 1e-
        ^
 HINT: try \h SELECT`},
-		{"SELECT foo''",
-			`at or near "": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT foo''
-          ^`},
 		{
 			`SELECT 0x FROM t`,
 			`lexical error: invalid hexadecimal numeric literal
@@ -2394,20 +2470,6 @@ ALTER TABLE t RENAME COLUMN x TO family
                                  ^
 HINT: try \h ALTER TABLE`,
 		},
-		{
-			`SELECT CAST(1.2+2.3 AS notatype)`,
-			`at or near "notatype": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT CAST(1.2+2.3 AS notatype)
-                       ^`,
-		},
-		{
-			`SELECT ANNOTATE_TYPE(1.2+2.3, notatype)`,
-			`at or near "notatype": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT ANNOTATE_TYPE(1.2+2.3, notatype)
-                              ^`,
-		},
 		{
 			`CREATE USER foo WITH PASSWORD`,
 			`at or near "EOF": syntax error
@@ -2474,13 +2536,6 @@ DETAIL: source SQL:
 SELECT 1 + ANY ARRAY[1, 2, 3]
                              ^`,
 		},
-		{
-			`SELECT 'f'::"blah"`,
-			`at or near "blah": syntax error: type does not exist
-DETAIL: source SQL:
-SELECT 'f'::"blah"
-            ^`,
-		},
 		// Ensure that the support for ON ROLE <namelist> doesn't leak
 		// where it should not be recognized.
 		{
@@ -2891,7 +2946,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE FUNCTION a`, 17511, `create`},
 		{`CREATE OR REPLACE FUNCTION a`, 17511, `create`},
 		{`CREATE LANGUAGE a`, 17511, `create language a`},
-		{`CREATE MATERIALIZED VIEW a`, 24747, ``},
 		{`CREATE OPERATOR a`, 0, `create operator`},
 		{`CREATE PUBLICATION a`, 0, `create publication`},
 		{`CREATE RULE a`, 0, `create rule`},
@@ -2930,7 +2984,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`SET LOCAL foo = bar`, 32562, ``},
 		{`SET foo FROM CURRENT`, 0, `set from current`},
 
-		{`CREATE TEMP TABLE a(b INT8)`, 5807, ``},
 		{`CREATE UNLOGGED TABLE a(b INT8)`, 0, `create unlogged`},
 		{`CREATE TEMP VIEW a AS SELECT b`, 5807, ``},
 		{`CREATE TEMP SEQUENCE a`, 5807, ``},
@@ -2965,13 +3018,11 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`CREATE RECURSIVE VIEW a AS SELECT b`, 0, `create recursive view`},
 
 		{`CREATE TYPE a AS (b)`, 27792, ``},
-		{`CREATE TYPE a AS ENUM (b)`, 24873, ``},
 		{`CREATE TYPE a AS RANGE b`, 27791, ``},
 		{`CREATE TYPE a (b)`, 27793, `base`},
 		{`CREATE TYPE a`, 27793, `shell`},
 		{`CREATE DOMAIN a`, 27796, `create`},
 
-		{`CREATE INDEX a ON b(c) WHERE d > 0`, 9683, ``},
 		{`CREATE INDEX a ON b USING HASH (c)`, 0, `index using hash`},
 		{`CREATE INDEX a ON b USING GIST (c)`, 0, `index using gist`},
 		{`CREATE INDEX a ON b USING SPGIST (c)`, 0, `index using spgist`},
@@ -2984,7 +3035,6 @@ func TestUnimplementedSyntax(t *testing.T) {
 		{`INSERT INTO foo(a, a.b) VALUES (1,2)`, 27792, ``},
 		{`INSERT INTO foo VALUES (1,2) ON CONFLICT ON CONSTRAINT a DO NOTHING`, 28161, ``},
 
-		{`SELECT * FROM a FOR UPDATE`, 6583, ``},
 		{`SELECT * FROM ROWS FROM (a(b) AS (d))`, 0, `ROWS FROM with col_def_list`},
 
 		{`SELECT 123 AT TIME ZONE 'b'`, 32005, ``},
@@ -3040,11 +3090,8 @@ func TestUnimplementedSyntax(t *testing.T) {
 
 		{`INSERT INTO a VALUES (1) ON CONFLICT (x) WHERE x > 3 DO NOTHING`, 32557, ``},
 
-		{`WITH RECURSIVE a AS (TABLE b) SELECT c`, 21085, ``},
-
 		{`UPDATE foo SET (a, a.b) = (1, 2)`, 27792, ``},
 		{`UPDATE foo SET a.b = 1`, 27792, ``},
-		{`UPDATE foo SET x = y FROM a, b`, 7841, ``},
 		{`UPDATE Foo SET x.y = z`, 27792, ``},
 
 		{`UPSERT INTO foo(a, a.b) VALUES (1,2)`, 27792, ``},
@@ -468,12 +468,14 @@ CREATE TABLE pg_catalog.pg_auth_members (
 }
 
 var (
-	relKindTable    = tree.NewDString("r")
-	relKindIndex    = tree.NewDString("i")
-	relKindView     = tree.NewDString("v")
-	relKindSequence = tree.NewDString("S")
+	relKindTable            = tree.NewDString("r")
+	relKindIndex            = tree.NewDString("i")
+	relKindView             = tree.NewDString("v")
+	relKindMaterializedView = tree.NewDString("m")
+	relKindSequence         = tree.NewDString("S")
 
 	relPersistencePermanent = tree.NewDString("p")
+	relPersistenceTemporary = tree.NewDString("t")
 )
 
 var pgCatalogClassTable = virtualSchemaTable{
@@ -515,11 +517,18 @@ CREATE TABLE pg_catalog.pg_class (
 			func(db *sqlbase.DatabaseDescriptor, scName string, table *sqlbase.TableDescriptor) error {
 				// The only difference between tables, views and sequences is the relkind column.
 				relKind := relKindTable
-				if table.IsView() {
+				if table.MaterializedView() {
+					relKind = relKindMaterializedView
+				} else if table.IsView() {
 					relKind = relKindView
 				} else if table.IsSequence() {
 					relKind = relKindSequence
 				}
+				relPersistence := relPersistencePermanent
+				if table.IsTemporary() {
+					relPersistence = relPersistenceTemporary
+				}
+				isTemp := tree.MakeDBool(tree.DBool(table.IsTemporary()))
 				namespaceOid := h.NamespaceOid(db, scName)
 				if err := addRow(
 					defaultOid(table.ID),      // oid
@@ -535,10 +544,10 @@ CREATE TABLE pg_catalog.pg_class (
 					zeroVal,                   // relallvisible
 					oidZero,                   // reltoastrelid
 					tree.MakeDBool(tree.DBool(table.IsPhysicalTable())), // relhasindex
-					tree.DBoolFalse,         // relisshared
-					relPersistencePermanent, // relPersistence
-					tree.DBoolFalse,         // relistemp
-					relKind,                 // relkind
+					tree.DBoolFalse, // relisshared
+					relPersistence,  // relPersistence
+					isTemp,          // relistemp
+					relKind,         // relkind
 					tree.NewDInt(tree.DInt(len(table.Columns))), // relnatts
 					tree.NewDInt(tree.DInt(len(table.Checks))),  // relchecks
 					tree.DBoolFalse, // relhasoids
@@ -577,8 +586,8 @@ CREATE TABLE pg_catalog.pg_class (
 						oidZero,                              // reltoastrelid
 						tree.DBoolFalse,                      // relhasindex
 						tree.DBoolFalse,                      // relisshared
-						relPersistencePermanent,              // relPersistence
-						tree.DBoolFalse,                      // relistemp
+						relPersistence,                       // relPersistence
+						isTemp,                               // relistemp
 						relKindIndex,                         // relkind
 						tree.NewDInt(tree.DInt(len(index.ColumnNames))), // relnatts
 						zeroVal,         // relchecks
@@ -1112,7 +1121,7 @@ CREATE TABLE pg_catalog.pg_shdescription (
 }
 
 var pgCatalogEnumTable = virtualSchemaTable{
-	comment: `enum types and labels (empty - feature does not exist)
+	comment: `enum types and labels
 https://www.postgresql.org/docs/9.5/catalog-pg-enum.html`,
 	schema: `
 CREATE TABLE pg_catalog.pg_enum (
@@ -1121,9 +1130,23 @@ CREATE TABLE pg_catalog.pg_enum (
   enumsortorder FLOAT,
   enumlabel STRING
 )`,
-	populate: func(_ context.Context, p *planner, _ *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
-		// Enum types are not currently supported.
-		return nil
+	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
+		h := makeOidHasher()
+		return forEachTypeDesc(ctx, p, dbContext, func(_ *DatabaseDescriptor, typDesc *sqlbase.TypeDescriptor) error {
+			typOid := tree.NewDOid(tree.DInt(typDesc.MakeTypesT().Oid()))
+			for i := range typDesc.EnumMembers {
+				label := typDesc.EnumMembers[i].LogicalRepresentation
+				if err := addRow(
+					h.EnumEntryOid(typDesc.ID, label), // oid
+					typOid,                            // enumtypid
+					tree.NewDFloat(tree.DFloat(float64(i+1))), // enumsortorder
+					tree.NewDString(label),                    // enumlabel
+				); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
 	},
 }
 
@@ -2052,7 +2075,6 @@ var (
 	// Avoid unused warning for constants.
 	_ = typTypeComposite
 	_ = typTypeDomain
-	_ = typTypeEnum
 	_ = typTypePseudo
 	_ = typTypeRange
 
@@ -2075,7 +2097,6 @@ var (
 
 	// Avoid unused warning for constants.
 	_ = typCategoryComposite
-	_ = typCategoryEnum
 	_ = typCategoryGeometric
 	_ = typCategoryRange
 	_ = typCategoryBitString
@@ -2122,7 +2143,7 @@ CREATE TABLE pg_catalog.pg_type (
 )`,
 	populate: func(ctx context.Context, p *planner, dbContext *DatabaseDescriptor, addRow func(...tree.Datum) error) error {
 		h := makeOidHasher()
-		return forEachDatabaseDesc(ctx, p, dbContext, func(db *DatabaseDescriptor) error {
+		if err := forEachDatabaseDesc(ctx, p, dbContext, func(db *DatabaseDescriptor) error {
 			nspOid := h.NamespaceOid(db, pgCatalogName)
 
 			for o, typ := range types.OidToType {
@@ -2197,6 +2218,49 @@ CREATE TABLE pg_catalog.pg_type (
 				}
 			}
 			return nil
+		}); err != nil {
+			return err
+		}
+
+		// User-defined types are only visible in the database that defines them.
+		return forEachTypeDesc(ctx, p, dbContext, func(db *DatabaseDescriptor, typDesc *sqlbase.TypeDescriptor) error {
+			typ := typDesc.MakeTypesT()
+			return addRow(
+				tree.NewDOid(tree.DInt(typ.Oid())),    // oid
+				tree.NewDName(typ.PGName()),           // typname
+				h.NamespaceOid(db, tree.PublicSchema), // typnamespace
+				tree.DNull,                            // typowner
+				typLen(typ),                           // typlen
+				typByVal(typ),                         // typbyval
+				typTypeEnum,                           // typtype
+				typCategoryEnum,                       // typcategory
+				tree.DBoolFalse,                       // typispreferred
+				tree.DBoolTrue,                        // typisdefined
+				typDelim,                              // typdelim
+				oidZero,                               // typrelid
+				oidZero,                               // typelem
+				oidZero,                               // typarray
+
+				// regproc references
+				h.RegProc("enum_in"),   // typinput
+				h.RegProc("enum_out"),  // typoutput
+				h.RegProc("enum_recv"), // typreceive
+				h.RegProc("enum_send"), // typsend
+				oidZero,                // typmodin
+				oidZero,                // typmodout
+				oidZero,                // typanalyze
+
+				tree.DNull,      // typalign
+				tree.DNull,      // typstorage
+				tree.DBoolFalse, // typnotnull
+				oidZero,         // typbasetype
+				negOneVal,       // typtypmod
+				zeroVal,         // typndims
+				oidZero,         // typcollation
+				tree.DNull,      // typdefaultbin
+				tree.DNull,      // typdefault
+				tree.DNull,      // typacl
+			)
 		})
 	},
 }
@@ -2365,6 +2429,7 @@ var datumToTypeCategory = map[types.Family]*tree.DString{
 	types.BoolFamily:        typCategoryBoolean,
 	types.BytesFamily:       typCategoryUserDefined,
 	types.DateFamily:        typCategoryDateTime,
+	types.EnumFamily:        typCategoryEnum,
 	types.TimeFamily:        typCategoryDateTime,
 	types.FloatFamily:       typCategoryNumeric,
 	types.IntFamily:         typCategoryNumeric,
@@ -2498,6 +2563,7 @@ const (
 	userTypeTag
 	collationTypeTag
 	operatorTypeTag
+	enumEntryTypeTag
 )
 
 func (h oidHasher) writeTypeTag(tag oidTypeTag) {
@@ -2656,6 +2722,13 @@ func (h oidHasher) OperatorOid(name string, leftType, rightType, returnType *tre
 	return h.getOid()
 }
 
+func (h oidHasher) EnumEntryOid(typeID sqlbase.ID, label string) *tree.DOid {
+	h.writeTypeTag(enumEntryTypeTag)
+	h.writeUInt32(uint32(typeID))
+	h.writeStr(label)
+	return h.getOid()
+}
+
 func defaultOid(id sqlbase.ID) *tree.DOid {
 	return tree.NewDOid(tree.DInt(id))
 }
@@ -137,6 +137,9 @@ func (b *writeBuffer) writeTextDatum(
 	case *tree.DString:
 		b.writeLengthPrefixedString(string(*v))
 
+	case *tree.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *tree.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
@@ -396,6 +399,9 @@ func (b *writeBuffer) writeBinaryDatum(
 	case *tree.DString:
 		b.writeLengthPrefixedString(string(*v))
 
+	case *tree.DEnum:
+		b.writeLengthPrefixedString(v.LogicalRep)
+
 	case *tree.DCollatedString:
 		b.writeLengthPrefixedString(v.Contents)
 
@@ -16,6 +16,7 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
@@ -87,6 +88,9 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 	scName string,
 	flags DatabaseListFlags,
 ) (TableNames, error) {
+	if sessiondata.IsTemporarySchemaName(scName) {
+		return a.getTemporaryObjectNames(ctx, txn, dbDesc, scName, flags)
+	}
 	if ok := a.IsValidSchema(dbDesc, scName); !ok {
 		if flags.required {
 			tn := tree.MakeTableNameWithSchema(tree.Name(dbDesc.Name), tree.Name(scName), "")
@@ -109,6 +113,11 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 		if err != nil {
 			return nil, err
 		}
+		if sessiondata.IsTemporarySchemaName(tableName) {
+			// Temporary schemas are recorded alongside the tables of the
+			// database; they are not objects of the public schema.
+			continue
+		}
 		tn := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(tableName))
 		tn.ExplicitCatalog = flags.explicitPrefix
 		tn.ExplicitSchema = flags.explicitPrefix
@@ -117,12 +126,50 @@ func (a UncachedPhysicalAccessor) GetObjectNames(
 	return tableNames, nil
 }
 
+// getTemporaryObjectNames retrieves the names of all objects in the given
+// temporary schema.
+func (a UncachedPhysicalAccessor) getTemporaryObjectNames(
+	ctx context.Context,
+	txn *client.Txn,
+	dbDesc *DatabaseDescriptor,
+	scName string,
+	flags DatabaseListFlags,
+) (TableNames, error) {
+	scID, err := getTemporarySchemaID(ctx, txn, dbDesc.ID, scName)
+	if err != nil || scID == sqlbase.InvalidID {
+		return nil, err
+	}
+
+	log.Eventf(ctx, "fetching list of objects for %q.%q", dbDesc.Name, scName)
+	prefix := sqlbase.MakeNameMetadataKey(scID, "")
+	sr, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+
+	var tableNames tree.TableNames
+	for _, row := range sr {
+		_, tableName, err := encoding.DecodeUnsafeStringAscending(
+			bytes.TrimPrefix(row.Key, prefix), nil)
+		if err != nil {
+			return nil, err
+		}
+		tn := tree.MakeTableNameWithSchema(tree.Name(dbDesc.Name), tree.Name(scName), tree.Name(tableName))
+		tn.ExplicitCatalog = flags.explicitPrefix
+		tn.ExplicitSchema = flags.explicitPrefix
+		tableNames = append(tableNames, tn)
+	}
+	return tableNames, nil
+}
+
 // GetObjectDesc implements the SchemaAccessor interface.
 func (a UncachedPhysicalAccessor) GetObjectDesc(
 	ctx context.Context, txn *client.Txn, name *ObjectName, flags ObjectLookupFlags,
 ) (ObjectDescriptor, error) {
-	// At this point, only the public schema is recognized.
-	if name.Schema() != tree.PublicSchema {
+	// At this point, only the public schema and temporary schemas are
+	// recognized.
+	isTemporary := sessiondata.IsTemporarySchemaName(name.Schema())
+	if name.Schema() != tree.PublicSchema && !isTemporary {
 		if flags.required {
 			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(name))
 		}
@@ -136,13 +183,23 @@ func (a UncachedPhysicalAccessor) GetObjectDesc(
 		return nil, err
 	}
 
+	// Objects of temporary schemas are recorded under the ID of the schema
+	// rather than that of the database.
+	parentID := dbID
+	if isTemporary {
+		parentID, err = getTemporarySchemaID(ctx, txn, dbID, name.Schema())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Try to use the system name resolution bypass. This avoids a hotspot.
 	// Note: we can only bypass name to ID resolution. The desc
 	// lookup below must still go through KV because system descriptors
 	// can be modified on a running cluster.
-	descID := sqlbase.LookupSystemTableDescriptorID(dbID, name.Table())
-	if descID == sqlbase.InvalidID {
-		descID, err = getDescriptorID(ctx, txn, sqlbase.NewTableKey(dbID, name.Table()))
+	descID := sqlbase.LookupSystemTableDescriptorID(parentID, name.Table())
+	if descID == sqlbase.InvalidID && parentID != sqlbase.InvalidID {
+		descID, err = getDescriptorID(ctx, txn, sqlbase.NewTableKey(parentID, name.Table()))
 		if err != nil {
 			return nil, err
 		}
@@ -118,6 +118,8 @@ func getPlanColumns(plan planNode, mut bool) sqlbase.ResultColumns {
 	// valueNode helper.
 	case *bufferNode:
 		return getPlanColumns(n.plan, mut)
+	case *recursiveCTENode:
+		return getPlanColumns(n.initial, mut)
 	case *distinctNode:
 		return getPlanColumns(n.plan, mut)
 	case *filterNode:
@@ -91,12 +91,14 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *applyJoinNode:
 	case *bufferNode:
 	case *scanBufferNode:
+	case *recursiveCTENode:
 
 	// Every other node simply has no guarantees on its output rows.
 	case *CreateUserNode:
 	case *DropUserNode:
 	case *alterIndexNode:
 	case *alterSequenceNode:
+	case *alterTypeNode:
 	case *alterTableNode:
 	case *alterUserSetPasswordNode:
 	case *cancelQueriesNode:
@@ -108,6 +110,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *createDatabaseNode:
 	case *createIndexNode:
 	case *createSequenceNode:
+	case *createTypeNode:
 	case *createStatsNode:
 	case *createTableNode:
 	case *createViewNode:
@@ -126,6 +129,7 @@ func planPhysicalProps(plan planNode) physicalProps {
 	case *renameDatabaseNode:
 	case *renameIndexNode:
 	case *renameTableNode:
+	case *refreshMaterializedViewNode:
 	case *rowCountNode:
 	case *rowSourceToPlanNode:
 	case *scatterNode:
@@ -0,0 +1,243 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/sql/opt/exec"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/rowcontainer"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/mon"
+)
+
+// recursiveCTEMaxIterations guards against runaway recursive queries.
+var recursiveCTEMaxIterations = settings.RegisterNonNegativeIntSetting(
+	"sql.recursive_cte.max_iterations",
+	"maximum number of iterations of the recursive query of a recursive common table expression "+
+		"(0 means no limit)",
+	100000,
+)
+
+// recursiveCTENode implements the logic for a recursive CTE:
+//  1. Evaluate the initial query; emit the results and also save them in
+//     a "working" table.
+//  2. So long as the working table is not empty:
+//     * evaluate the recursive query, substituting the current contents of
+//       the working table for the recursive self-reference;
+//     * emit all resulting rows, and save them as the next iteration's
+//       working table.
+// The recursive query tree is regenerated each time using a callback
+// (implemented by the execbuilder).
+//
+// If deduplicate is set (UNION as opposed to UNION ALL), rows that have
+// already been emitted are discarded from both the output and the working
+// table; this guarantees termination for queries over cyclic data.
+type recursiveCTENode struct {
+	initial planNode
+
+	genIterationFn exec.RecursiveCTEIterationFn
+
+	// label is a string used to describe the node in an EXPLAIN output.
+	label string
+
+	deduplicate bool
+
+	run recursiveCTERun
+}
+
+type recursiveCTERun struct {
+	// workingRows contains the rows produced by the current iteration (aka the
+	// "working" table).
+	workingRows *rowcontainer.RowContainer
+	// nextRowIdx is the index inside workingRows of the next row to be returned
+	// by the operator.
+	nextRowIdx int
+	// currentRow is the row returned by the last call to Next.
+	currentRow tree.Datums
+
+	initialDone bool
+
+	// iterations is the number of times the recursive query has been run.
+	iterations int64
+
+	// The following fields are only used if deduplicate is set.
+	//
+	// iterationRows temporarily holds the rows produced by an iteration, before
+	// they are deduplicated into workingRows.
+	iterationRows *rowcontainer.RowContainer
+	// seen contains the encodings of all the rows emitted so far.
+	seen    map[string]struct{}
+	seenAcc mon.BoundAccount
+	scratch []byte
+}
+
+func (n *recursiveCTENode) startExec(params runParams) error {
+	n.run.workingRows = n.newRowContainer(params)
+	n.run.nextRowIdx = 0
+	if n.deduplicate {
+		n.run.iterationRows = n.newRowContainer(params)
+		n.run.seen = make(map[string]struct{})
+		n.run.seenAcc = params.EvalContext().Mon.MakeBoundAccount()
+	}
+	return nil
+}
+
+func (n *recursiveCTENode) newRowContainer(params runParams) *rowcontainer.RowContainer {
+	return rowcontainer.NewRowContainer(
+		params.EvalContext().Mon.MakeBoundAccount(),
+		sqlbase.ColTypeInfoFromResCols(getPlanColumns(n.initial, false /* mut */)),
+		0, /* rowCapacity */
+	)
+}
+
+func (n *recursiveCTENode) Next(params runParams) (bool, error) {
+	if err := params.p.cancelChecker.Check(); err != nil {
+		return false, err
+	}
+
+	// Emit the rows of the initial query, saving them in the working table.
+	for !n.run.initialDone {
+		ok, err := n.initial.Next(params)
+		if err != nil {
+			return false, err
+		}
+		if !ok {
+			n.run.initialDone = true
+			break
+		}
+		row := n.initial.Values()
+		if n.deduplicate {
+			dup, err := n.checkDuplicate(params.ctx, row)
+			if err != nil {
+				return false, err
+			}
+			if dup {
+				continue
+			}
+		}
+		if _, err := n.run.workingRows.AddRow(params.ctx, row); err != nil {
+			return false, err
+		}
+		n.run.nextRowIdx = n.run.workingRows.Len()
+		n.run.currentRow = row
+		return true, nil
+	}
+
+	// Run iterations of the recursive query until we get some rows to emit, or
+	// until an iteration produces no rows.
+	for n.run.nextRowIdx >= n.run.workingRows.Len() {
+		if n.run.workingRows.Len() == 0 {
+			// The last iteration produced no rows; we are done.
+			return false, nil
+		}
+		if err := n.runIteration(params); err != nil {
+			return false, err
+		}
+	}
+
+	n.run.currentRow = n.run.workingRows.At(n.run.nextRowIdx)
+	n.run.nextRowIdx++
+	return true, nil
+}
+
+// runIteration runs the recursive query once against the current working
+// table; the results become the new working table.
+func (n *recursiveCTENode) runIteration(params runParams) error {
+	n.run.iterations++
+	if limit := recursiveCTEMaxIterations.Get(&params.EvalContext().Settings.SV); limit > 0 &&
+		n.run.iterations > limit {
+		return pgerror.Newf(pgcode.ProgramLimitExceeded,
+			"%s: recursive query exceeded the maximum of %d iterations "+
+				"(see the sql.recursive_cte.max_iterations cluster setting)",
+			n.label, limit,
+		)
+	}
+
+	// Set up a bufferNode that can be used as a reference for a scanBufferNode.
+	buf := &bufferNode{
+		// The plan is only used to determine the columns of the buffer; it is
+		// never executed through this node.
+		plan:         n.initial,
+		bufferedRows: n.run.workingRows,
+	}
+	defer buf.bufferedRows.Close(params.ctx)
+
+	newPlan, err := n.genIterationFn(buf)
+	if err != nil {
+		return err
+	}
+
+	n.run.workingRows = n.newRowContainer(params)
+	n.run.nextRowIdx = 0
+
+	if !n.deduplicate {
+		return runPlanInsidePlan(params, newPlan.(*planTop), n.run.workingRows)
+	}
+
+	n.run.iterationRows.Clear(params.ctx)
+	if err := runPlanInsidePlan(params, newPlan.(*planTop), n.run.iterationRows); err != nil {
+		return err
+	}
+	for i, l := 0, n.run.iterationRows.Len(); i < l; i++ {
+		row := n.run.iterationRows.At(i)
+		dup, err := n.checkDuplicate(params.ctx, row)
+		if err != nil {
+			return err
+		}
+		if dup {
+			continue
+		}
+		if _, err := n.run.workingRows.AddRow(params.ctx, row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkDuplicate returns true if the given row has been seen before; otherwise
+// it remembers the row and returns false.
+func (n *recursiveCTENode) checkDuplicate(ctx context.Context, row tree.Datums) (bool, error) {
+	var err error
+	n.run.scratch, err = sqlbase.EncodeDatumsKeyAscending(n.run.scratch[:0], row)
+	if err != nil {
+		return false, err
+	}
+	if _, ok := n.run.seen[string(n.run.scratch)]; ok {
+		return true, nil
+	}
+	if err := n.run.seenAcc.Grow(ctx, int64(len(n.run.scratch))); err != nil {
+		return false, err
+	}
+	n.run.seen[string(n.run.scratch)] = struct{}{}
+	return false, nil
+}
+
+func (n *recursiveCTENode) Values() tree.Datums {
+	return n.run.currentRow
+}
+
+func (n *recursiveCTENode) Close(ctx context.Context) {
+	n.initial.Close(ctx)
+	if n.run.workingRows != nil {
+		n.run.workingRows.Close(ctx)
+	}
+	if n.run.iterationRows != nil {
+		n.run.iterationRows.Close(ctx)
+	}
+	if n.deduplicate {
+		n.run.seenAcc.Close(ctx)
+	}
+}
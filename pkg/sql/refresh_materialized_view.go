@@ -0,0 +1,94 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+)
+
+type refreshMaterializedViewNode struct {
+	n    *tree.RefreshMaterializedView
+	desc *sqlbase.MutableTableDescriptor
+}
+
+// RefreshMaterializedView recomputes the contents of a materialized view.
+// Privileges: CREATE on view.
+//   notes: postgres allows only the owner of the view to refresh it.
+func (p *planner) RefreshMaterializedView(
+	ctx context.Context, n *tree.RefreshMaterializedView,
+) (planNode, error) {
+	desc, err := p.ResolveMutableTableDescriptor(ctx, &n.Name, true /*required*/, ResolveRequireViewDesc)
+	if err != nil {
+		return nil, err
+	}
+	if !desc.MaterializedView() {
+		return nil, pgerror.Newf(pgcode.WrongObjectType,
+			"%q is not a materialized view", tree.ErrString(&n.Name))
+	}
+
+	if err := p.CheckPrivilege(ctx, desc, privilege.CREATE); err != nil {
+		return nil, err
+	}
+
+	return &refreshMaterializedViewNode{n: n, desc: desc}, nil
+}
+
+func (n *refreshMaterializedViewNode) startExec(params runParams) error {
+	// A materialized view created in the same transaction is populated with
+	// the results of its query once the transaction commits, so there is
+	// nothing to refresh yet.
+	if n.desc.Adding() {
+		return nil
+	}
+
+	// The refresh writes copies of the indexes of the view, so they must not be
+	// changed by other schema changes until it completes.
+	if len(n.desc.Mutations) > 0 {
+		return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+			"materialized view %q is undergoing a schema change, try again later", n.desc.Name)
+	}
+
+	// The refresh itself is run by the schema changer, as part of a schema
+	// change job. The view query is run as of the commit timestamp of this
+	// transaction.
+	n.desc.AddMaterializedViewRefreshMutation(params.p.txn.CommitTimestamp())
+	mutationID, err := params.p.createOrUpdateSchemaChangeJob(
+		params.ctx, n.desc, tree.AsStringWithFQNames(n.n, params.Ann()),
+	)
+	if err != nil {
+		return err
+	}
+	return params.p.writeSchemaChange(params.ctx, n.desc, mutationID)
+}
+
+// checkNoPendingMaterializedViewRefresh returns an error if a refresh of the
+// given materialized view is in progress. A refresh replaces the indexes of the
+// view with the copies made when it was queued, so the existing indexes cannot
+// be changed until it completes.
+func checkNoPendingMaterializedViewRefresh(desc *sqlbase.MutableTableDescriptor) error {
+	for _, m := range desc.Mutations {
+		if m.GetMaterializedViewRefresh() != nil {
+			return pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+				"materialized view %q is being refreshed, try again later", desc.Name)
+		}
+	}
+	return nil
+}
+
+func (*refreshMaterializedViewNode) Next(runParams) (bool, error) { return false, nil }
+func (*refreshMaterializedViewNode) Values() tree.Datums          { return tree.Datums{} }
+func (*refreshMaterializedViewNode) Close(context.Context)        {}
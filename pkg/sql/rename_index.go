@@ -53,6 +53,10 @@ func (p *planner) RenameIndex(ctx context.Context, n *tree.RenameIndex) (planNod
 		return nil, err
 	}
 
+	if err := checkNoPendingMaterializedViewRefresh(tableDesc); err != nil {
+		return nil, err
+	}
+
 	return &renameIndexNode{n: n, idx: idx, tableDesc: tableDesc}, nil
 }
 
@@ -16,6 +16,8 @@ import (
 
 	"github.com/cockroachdb/cockroach/pkg/internal/client"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/privilege"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
@@ -95,6 +97,19 @@ func (n *renameTableNode) startExec(params runParams) error {
 		return err
 	}
 
+	// Temporary tables stay in their temporary schema, and permanent tables
+	// cannot be moved into one.
+	if tableDesc.IsTemporary() {
+		if targetDbDesc.ID != prevDbDesc.ID {
+			return pgerror.Newf(pgcode.FeatureNotSupported,
+				"cannot move temporary table %q to another database", oldTn.Table())
+		}
+		newTn.SchemaName = oldTn.SchemaName
+	} else if isTemporarySchema(newTn.Schema()) {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"cannot move permanent table %q into a temporary schema", oldTn.Table())
+	}
+
 	// oldTn and newTn are already normalized, so we can compare directly here.
 	if oldTn.Catalog() == newTn.Catalog() &&
 		oldTn.Schema() == newTn.Schema() &&
@@ -103,11 +118,12 @@ func (n *renameTableNode) startExec(params runParams) error {
 		return nil
 	}
 
+	prevNameParentID := tableDesc.GetNameParentID()
 	tableDesc.SetName(newTn.Table())
 	tableDesc.ParentID = targetDbDesc.ID
 
 	descKey := sqlbase.MakeDescMetadataKey(tableDesc.GetID())
-	newTbKey := sqlbase.NewTableKey(targetDbDesc.ID, newTn.Table()).Key()
+	newTbKey := sqlbase.NewTableKey(tableDesc.GetNameParentID(), newTn.Table()).Key()
 
 	if err := tableDesc.Validate(ctx, p.txn, p.EvalContext().Settings); err != nil {
 		return err
@@ -117,7 +133,7 @@ func (n *renameTableNode) startExec(params runParams) error {
 	descDesc := sqlbase.WrapDescriptor(tableDesc)
 
 	renameDetails := sqlbase.TableDescriptor_NameInfo{
-		ParentID: prevDbDesc.ID,
+		ParentID: prevNameParentID,
 		Name:     oldTn.Table()}
 	tableDesc.DrainingNames = append(tableDesc.DrainingNames, renameDetails)
 	if err := p.writeSchemaChange(ctx, tableDesc, sqlbase.InvalidMutationID); err != nil {
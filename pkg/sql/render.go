@@ -94,9 +94,13 @@ func (p *planner) Select(
 	limit := n.Limit
 	orderBy := n.OrderBy
 	with := n.With
+	locking := n.Locking
 
 	for s, ok := wrapped.(*tree.ParenSelect); ok; s, ok = wrapped.(*tree.ParenSelect) {
 		wrapped = s.Select.Select
+		if s.Select.Locking != nil {
+			locking = s.Select.Locking
+		}
 		if s.Select.With != nil {
 			if with != nil {
 				return nil, unimplemented.NewWithIssue(24303,
@@ -122,6 +126,12 @@ func (p *planner) Select(
 		}
 	}
 
+	if locking != nil {
+		// Row-level locking is only implemented by the cost-based optimizer.
+		return nil, unimplemented.NewWithIssuef(6583,
+			"%s is not supported by the heuristic planner", locking.MaxStrength())
+	}
+
 	switch s := wrapped.(type) {
 	case *tree.SelectClause:
 		// Select can potentially optimize index selection if it's being ordered,
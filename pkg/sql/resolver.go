@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/errors"
 )
 
@@ -156,6 +157,8 @@ func resolveExistingObjectImpl(
 		goodType = obj.TableDesc().IsTable() || obj.TableDesc().IsView()
 	case ResolveRequireSequenceDesc:
 		goodType = obj.TableDesc().IsSequence()
+	case ResolveRequireTableOrMaterializedViewDesc:
+		goodType = obj.TableDesc().IsTable() || obj.TableDesc().MaterializedView()
 	}
 	if !goodType {
 		return nil, sqlbase.NewWrongObjectTypeError(tn, requiredTypeNames[requiredType])
@@ -230,10 +233,16 @@ func ResolveTargetObject(
 		err = errors.WithHint(err, "verify that the current database and search_path are valid and/or the target database exists")
 		return nil, err
 	}
-	if tn.Schema() != tree.PublicSchema {
+	if tn.Schema() != tree.PublicSchema && !isTemporarySchema(tn.Schema()) {
 		return nil, pgerror.Newf(pgcode.InvalidName,
 			"schema cannot be modified: %q", tree.ErrString(&tn.TableNamePrefix))
 	}
+	if sessiondata.IsTemporarySchemaName(tn.Table()) {
+		// Temporary schemas share the namespace of the tables of their
+		// database, so their names cannot be used for relations.
+		return nil, pgerror.Newf(pgcode.ReservedName,
+			"unacceptable relation name %q", tn.Table())
+	}
 	return descI.(*DatabaseDescriptor), nil
 }
 
@@ -257,13 +266,15 @@ const (
 	ResolveRequireViewDesc
 	ResolveRequireTableOrViewDesc
 	ResolveRequireSequenceDesc
+	ResolveRequireTableOrMaterializedViewDesc
 )
 
 var requiredTypeNames = [...]string{
-	ResolveRequireTableDesc:       "table",
-	ResolveRequireViewDesc:        "view",
-	ResolveRequireTableOrViewDesc: "table or view",
-	ResolveRequireSequenceDesc:    "sequence",
+	ResolveRequireTableDesc:                   "table",
+	ResolveRequireViewDesc:                    "view",
+	ResolveRequireTableOrViewDesc:             "table or view",
+	ResolveRequireSequenceDesc:                "sequence",
+	ResolveRequireTableOrMaterializedViewDesc: "table or materialized view",
 }
 
 // LookupSchema implements the tree.TableNameTargetResolver interface.
@@ -275,6 +286,10 @@ func (p *planner) LookupSchema(
 	if err != nil || dbDesc == nil {
 		return false, nil, err
 	}
+	if isTemporarySchema(scName) {
+		found, err = p.temporarySchemaExists(ctx, dbDesc.ID, scName)
+		return found, dbDesc, err
+	}
 	return sc.IsValidSchema(dbDesc, scName), dbDesc, nil
 }
 
@@ -283,6 +298,13 @@ func (p *planner) LookupObject(
 	ctx context.Context, requireMutable bool, dbName, scName, tbName string,
 ) (found bool, objMeta tree.NameResolutionResult, err error) {
 	sc := p.LogicalSchemaAccessor()
+	if scName == sessiondata.PgTempSchemaName {
+		// pg_temp is an alias for the temporary schema of the session.
+		scName = p.SessionData().SearchPath.GetTemporarySchemaName()
+		if scName == "" {
+			return false, nil, nil
+		}
+	}
 	p.tableName = tree.MakeTableNameWithSchema(tree.Name(dbName), tree.Name(scName), tree.Name(tbName))
 	objDesc, err := sc.GetObjectDesc(ctx, p.txn, &p.tableName, p.ObjectLookupFlags(false /*required*/, requireMutable))
 	return objDesc != nil, objDesc, err
@@ -400,7 +422,7 @@ func findTableContainingIndex(
 		if err != nil {
 			return nil, nil, err
 		}
-		if tableDesc == nil || !tableDesc.IsTable() {
+		if tableDesc == nil || !(tableDesc.IsTable() || tableDesc.MaterializedView()) {
 			continue
 		}
 
@@ -453,7 +475,9 @@ func expandIndexName(
 	tn = &index.Table
 	if tn.Table() != "" {
 		// The index and its table prefix must exist already. Resolve the table.
-		desc, err = ResolveMutableExistingObject(ctx, sc, tn, requireTable, ResolveRequireTableDesc)
+		desc, err = ResolveMutableExistingObject(
+			ctx, sc, tn, requireTable, ResolveRequireTableOrMaterializedViewDesc,
+		)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -694,3 +718,52 @@ func (p *planner) ResolveExistingObjectEx(
 func (p *planner) ResolvedName(u *tree.UnresolvedObjectName) *tree.TableName {
 	return u.Resolved(&p.semaCtx.Annotations)
 }
+
+// ResolveType implements the tree.TypeReferenceResolver interface. User-defined
+// types are looked up in the current database.
+func (p *planner) ResolveType(name string) (*types.T, error) {
+	tn := tree.MakeUnqualifiedTableName(tree.Name(name))
+	desc, err := p.resolveTypeDesc(p.EvalContext().Context, &tn)
+	if err != nil {
+		return nil, err
+	}
+	if desc == nil {
+		return nil, pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", name)
+	}
+	return desc.MakeTypesT(), nil
+}
+
+// resolveTypeDesc looks up the descriptor of the user-defined type with the
+// given name. The name is qualified in place like the name of a new table. A
+// nil descriptor is returned if the type does not exist.
+func (p *planner) resolveTypeDesc(
+	ctx context.Context, tn *ObjectName,
+) (*sqlbase.TypeDescriptor, error) {
+	dbDesc, err := p.ResolveUncachedDatabase(ctx, tn)
+	if err != nil {
+		return nil, err
+	}
+	return findTypeDesc(ctx, p.txn, dbDesc.ID, tn.Table())
+}
+
+// findTypeDesc returns the descriptor of the user-defined type with the given
+// name in the given database, or nil if there is no such type.
+//
+// Types are not recorded in system.namespace, so that they are not mistaken
+// for tables when listing the objects of a database; they are found by
+// scanning the descriptor table instead.
+func findTypeDesc(
+	ctx context.Context, txn *client.Txn, parentID sqlbase.ID, name string,
+) (*sqlbase.TypeDescriptor, error) {
+	descs, err := GetAllDescriptors(ctx, txn)
+	if err != nil {
+		return nil, err
+	}
+	for _, desc := range descs {
+		if typeDesc, ok := desc.(*sqlbase.TypeDescriptor); ok &&
+			typeDesc.ParentID == parentID && typeDesc.Name == name {
+			return typeDesc, nil
+		}
+	}
+	return nil, nil
+}
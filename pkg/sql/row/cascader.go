@@ -416,6 +416,7 @@ func (c *cascader) addRowDeleter(
 		c.fkTables,
 		nil, /* requestedCol */
 		CheckFKs,
+		c.evalCtx,
 		c.alloc,
 	)
 	if err != nil {
@@ -476,6 +477,7 @@ func (c *cascader) addRowUpdater(
 		table.Columns,
 		nil, /* requestedCol */
 		UpdaterDefault,
+		c.evalCtx,
 		c.alloc,
 	)
 	if err != nil {
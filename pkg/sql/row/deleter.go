@@ -47,7 +47,7 @@ func MakeDeleter(
 	alloc *sqlbase.DatumAlloc,
 ) (Deleter, error) {
 	rowDeleter, err := makeRowDeleterWithoutCascader(
-		txn, tableDesc, fkTables, requestedCols, checkFKs, alloc,
+		txn, tableDesc, fkTables, requestedCols, checkFKs, evalCtx, alloc,
 	)
 	if err != nil {
 		return Deleter{}, err
@@ -70,6 +70,7 @@ func makeRowDeleterWithoutCascader(
 	fkTables FkTableMetadata,
 	requestedCols []sqlbase.ColumnDescriptor,
 	checkFKs checkFKConstraints,
+	evalCtx *tree.EvalContext,
 	alloc *sqlbase.DatumAlloc,
 ) (Deleter, error) {
 	indexes := tableDesc.DeletableIndexes()
@@ -105,15 +106,29 @@ func makeRowDeleterWithoutCascader(
 				return Deleter{}, err
 			}
 		}
+		// The predicate columns of partial indexes are needed to determine
+		// whether the row has an entry to delete.
+		predCols, err := index.PredicateColumnIDs(tableDesc.TableDesc())
+		if err != nil {
+			return Deleter{}, err
+		}
+		for _, colID := range predCols {
+			if err := maybeAddCol(colID); err != nil {
+				return Deleter{}, err
+			}
+		}
 	}
 
+	rh, err := newRowHelper(tableDesc, indexes, evalCtx)
+	if err != nil {
+		return Deleter{}, err
+	}
 	rd := Deleter{
-		Helper:               newRowHelper(tableDesc, indexes),
+		Helper:               rh,
 		FetchCols:            fetchCols,
 		FetchColIDtoRowIndex: fetchColIDtoRowIndex,
 	}
 	if checkFKs == CheckFKs {
-		var err error
 		if rd.Fks, err = makeFkExistenceCheckHelperForDelete(txn, tableDesc, fkTables,
 			fetchColIDtoRowIndex, alloc); err != nil {
 			return Deleter{}, err
@@ -142,6 +157,10 @@ func (rd *Deleter) DeleteRow(
 	// Delete the row from any secondary indices.
 	for i := range secondaryIndexEntries {
 		secondaryIndexEntry := &secondaryIndexEntries[i]
+		if secondaryIndexEntry.Key == nil {
+			// The row is not included in this partial index.
+			continue
+		}
 		if traceKV {
 			log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(rd.Helper.secIndexValDirs[i], secondaryIndexEntry.Key))
 		}
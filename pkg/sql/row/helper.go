@@ -16,7 +16,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
-	"github.com/pkg/errors"
+	"github.com/cockroachdb/errors"
 )
 
 // rowHelper has the common methods for table row manipulations.
@@ -26,6 +26,9 @@ type rowHelper struct {
 	Indexes      []sqlbase.IndexDescriptor
 	indexEntries []sqlbase.IndexEntry
 
+	// Predicates of the partial indexes among Indexes; nil if there are none.
+	partialIndexPreds *sqlbase.PartialIndexPredicates
+
 	// Computed during initialization for pretty-printing.
 	primIndexValDirs []encoding.Direction
 	secIndexValDirs  [][]encoding.Direction
@@ -37,10 +40,30 @@ type rowHelper struct {
 }
 
 func newRowHelper(
-	desc *sqlbase.ImmutableTableDescriptor, indexes []sqlbase.IndexDescriptor,
-) rowHelper {
+	desc *sqlbase.ImmutableTableDescriptor,
+	indexes []sqlbase.IndexDescriptor,
+	evalCtx *tree.EvalContext,
+) (rowHelper, error) {
 	rh := rowHelper{TableDesc: desc, Indexes: indexes}
 
+	// Partial indexes only contain entries for the rows that satisfy their
+	// predicate, which requires an evaluation context.
+	for i := range indexes {
+		if !indexes[i].IsPartial() {
+			continue
+		}
+		if evalCtx == nil {
+			return rowHelper{}, errors.AssertionFailedf(
+				"cannot write to partial index %q without an evaluation context", indexes[i].Name)
+		}
+		var err error
+		rh.partialIndexPreds, err = sqlbase.NewPartialIndexPredicates(desc.TableDesc(), indexes, evalCtx)
+		if err != nil {
+			return rowHelper{}, err
+		}
+		break
+	}
+
 	// Pre-compute the encoding directions of the index key values for
 	// pretty-printing in traces.
 	rh.primIndexValDirs = sqlbase.IndexKeyValDirs(&rh.TableDesc.PrimaryIndex)
@@ -50,7 +73,7 @@ func newRowHelper(
 		rh.secIndexValDirs[i] = sqlbase.IndexKeyValDirs(&rh.Indexes[i])
 	}
 
-	return rh
+	return rh, nil
 }
 
 // encodeIndexes encodes the primary and secondary index keys. The
@@ -77,7 +100,8 @@ func (rh *rowHelper) encodeIndexes(
 
 // encodeSecondaryIndexes encodes the secondary index keys. The
 // secondaryIndexEntries are only valid until the next call to encodeIndexes or
-// encodeSecondaryIndexes.
+// encodeSecondaryIndexes. The entry of a partial index whose predicate is not
+// satisfied by the row is empty, and must not be written.
 func (rh *rowHelper) encodeSecondaryIndexes(
 	colIDtoRowIndex map[sqlbase.ColumnID]int, values []tree.Datum,
 ) (secondaryIndexEntries []sqlbase.IndexEntry, err error) {
@@ -85,7 +109,8 @@ func (rh *rowHelper) encodeSecondaryIndexes(
 		rh.indexEntries = make([]sqlbase.IndexEntry, len(rh.Indexes))
 	}
 	rh.indexEntries, err = sqlbase.EncodeSecondaryIndexes(
-		rh.TableDesc.TableDesc(), rh.Indexes, colIDtoRowIndex, values, rh.indexEntries)
+		rh.TableDesc.TableDesc(), rh.Indexes, colIDtoRowIndex, values,
+		rh.partialIndexPreds, rh.indexEntries)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,131 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package row
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/errors"
+)
+
+// Locker acquires exclusive row-level locks on the rows of a table on behalf
+// of a transaction. A row is locked by locking the primary index key of each
+// of its column families (see client.Batch.Lock), which prevents concurrent
+// transactions from reading or writing the row until the locking transaction
+// finishes, but does not write a new version of the row.
+//
+// Rows are added to the Locker with AddRow, and the locks on all of the rows
+// added since the last flush are acquired in a single batch by Flush.
+type Locker struct {
+	desc      *sqlbase.TableDescriptor
+	keyPrefix []byte
+
+	// pkColIdxs are the ordinals of the primary key columns in the rows passed
+	// to AddRow, in the order of the primary index.
+	pkColIdxs []int
+	pkTypes   []types.T
+	pkRow     sqlbase.EncDatumRow
+
+	alloc   *sqlbase.DatumAlloc
+	traceKV bool
+
+	b       *client.Batch
+	numRows int
+}
+
+// Init initializes the Locker for the given table. colIdxMap maps the IDs of
+// the table's columns to their ordinals in the rows passed to AddRow, and must
+// contain all of the primary key columns. traceKV is set to log the locks that
+// are acquired.
+func (l *Locker) Init(
+	desc *sqlbase.TableDescriptor,
+	colIdxMap map[sqlbase.ColumnID]int,
+	alloc *sqlbase.DatumAlloc,
+	traceKV bool,
+) error {
+	pkIndex := &desc.PrimaryIndex
+	*l = Locker{
+		desc:      desc,
+		keyPrefix: sqlbase.MakeIndexKeyPrefix(desc, pkIndex.ID),
+		pkColIdxs: make([]int, len(pkIndex.ColumnIDs)),
+		pkTypes:   make([]types.T, len(pkIndex.ColumnIDs)),
+		pkRow:     make(sqlbase.EncDatumRow, len(pkIndex.ColumnIDs)),
+		alloc:     alloc,
+		traceKV:   traceKV,
+		b:         &client.Batch{},
+	}
+	for i, colID := range pkIndex.ColumnIDs {
+		idx, ok := colIdxMap[colID]
+		if !ok {
+			return errors.AssertionFailedf("primary key column %d is not available for locking", colID)
+		}
+		col, err := desc.FindColumnByID(colID)
+		if err != nil {
+			return err
+		}
+		l.pkColIdxs[i] = idx
+		l.pkTypes[i] = col.Type
+	}
+	return nil
+}
+
+// AddRow queues up the locks on the given row, which must contain the values
+// of all of the table's primary key columns. A row with a NULL primary key,
+// such as the NULL-extended row of an outer join, does not correspond to a row
+// of the table and is not locked.
+func (l *Locker) AddRow(ctx context.Context, row sqlbase.EncDatumRow) error {
+	for i, idx := range l.pkColIdxs {
+		if row[idx].IsNull() {
+			return nil
+		}
+		l.pkRow[i] = row[idx]
+	}
+	span, err := sqlbase.MakeSpanFromEncDatums(
+		l.keyPrefix, l.pkRow, l.pkTypes, l.desc.PrimaryIndex.ColumnDirections, l.desc,
+		&l.desc.PrimaryIndex, l.alloc)
+	if err != nil {
+		return err
+	}
+	// Cap the key so that the family keys below do not share a backing array.
+	pkKey := span.Key[:len(span.Key):len(span.Key)]
+	for i := range l.desc.Families {
+		key := roachpb.Key(keys.MakeFamilyKey(pkKey, uint32(l.desc.Families[i].ID)))
+		if l.traceKV {
+			log.VEventf(ctx, 2, "Lock %s", key)
+		}
+		l.b.Lock(key)
+	}
+	l.numRows++
+	return nil
+}
+
+// Len returns the number of rows whose locks have been queued up since the
+// last flush.
+func (l *Locker) Len() int {
+	return l.numRows
+}
+
+// Flush acquires the locks queued up by AddRow in the given transaction.
+func (l *Locker) Flush(ctx context.Context, txn *client.Txn) error {
+	if l.numRows == 0 {
+		return nil
+	}
+	b := l.b
+	l.b = &client.Batch{}
+	l.numRows = 0
+	return txn.Run(ctx, b)
+}
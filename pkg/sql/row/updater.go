@@ -81,7 +81,7 @@ func MakeUpdater(
 	alloc *sqlbase.DatumAlloc,
 ) (Updater, error) {
 	rowUpdater, err := makeUpdaterWithoutCascader(
-		txn, tableDesc, fkTables, updateCols, requestedCols, updateType, alloc,
+		txn, tableDesc, fkTables, updateCols, requestedCols, updateType, evalCtx, alloc,
 	)
 	if err != nil {
 		return Updater{}, err
@@ -110,6 +110,7 @@ func makeUpdaterWithoutCascader(
 	updateCols []sqlbase.ColumnDescriptor,
 	requestedCols []sqlbase.ColumnDescriptor,
 	updateType rowUpdaterType,
+	evalCtx *tree.EvalContext,
 	alloc *sqlbase.DatumAlloc,
 ) (Updater, error) {
 	updateColIDtoRowIndex := ColIDtoRowIndexFromCols(updateCols)
@@ -128,27 +129,43 @@ func makeUpdaterWithoutCascader(
 	}
 
 	// Secondary indexes needing updating.
-	needsUpdate := func(index sqlbase.IndexDescriptor) bool {
+	needsUpdate := func(index sqlbase.IndexDescriptor) (bool, error) {
 		if updateType == UpdaterOnlyColumns {
 			// Only update columns.
-			return false
+			return false, nil
 		}
 		// If the primary key changed, we need to update all of them.
 		if primaryKeyColChange {
-			return true
+			return true, nil
 		}
-		return index.RunOverAllColumns(func(id sqlbase.ColumnID) error {
+		if index.RunOverAllColumns(func(id sqlbase.ColumnID) error {
 			if _, ok := updateColIDtoRowIndex[id]; ok {
 				return returnTruePseudoError
 			}
 			return nil
-		}) != nil
+		}) != nil {
+			return true, nil
+		}
+		// Updating a column referenced by the predicate of a partial index can
+		// add the row to or remove it from the index.
+		predCols, err := index.PredicateColumnIDs(tableDesc.TableDesc())
+		if err != nil {
+			return false, err
+		}
+		for _, id := range predCols {
+			if _, ok := updateColIDtoRowIndex[id]; ok {
+				return true, nil
+			}
+		}
+		return false, nil
 	}
 
 	writableIndexes := tableDesc.WritableIndexes()
 	includeIndexes := make([]sqlbase.IndexDescriptor, 0, len(writableIndexes))
 	for _, index := range writableIndexes {
-		if needsUpdate(index) {
+		if ok, err := needsUpdate(index); err != nil {
+			return Updater{}, err
+		} else if ok {
 			includeIndexes = append(includeIndexes, index)
 		}
 	}
@@ -158,7 +175,9 @@ func makeUpdaterWithoutCascader(
 
 	var deleteOnlyIndexes []sqlbase.IndexDescriptor
 	for _, idx := range tableDesc.DeleteOnlyIndexes() {
-		if needsUpdate(idx) {
+		if ok, err := needsUpdate(idx); err != nil {
+			return Updater{}, err
+		} else if ok {
 			if deleteOnlyIndexes == nil {
 				// Allocate at most once.
 				deleteOnlyIndexes = make([]sqlbase.IndexDescriptor, 0, len(tableDesc.DeleteOnlyIndexes()))
@@ -169,12 +188,19 @@ func makeUpdaterWithoutCascader(
 
 	var deleteOnlyHelper *rowHelper
 	if len(deleteOnlyIndexes) > 0 {
-		rh := newRowHelper(tableDesc, deleteOnlyIndexes)
+		rh, err := newRowHelper(tableDesc, deleteOnlyIndexes, evalCtx)
+		if err != nil {
+			return Updater{}, err
+		}
 		deleteOnlyHelper = &rh
 	}
 
+	rh, err := newRowHelper(tableDesc, includeIndexes, evalCtx)
+	if err != nil {
+		return Updater{}, err
+	}
 	ru := Updater{
-		Helper:                newRowHelper(tableDesc, includeIndexes),
+		Helper:                rh,
 		DeleteHelper:          deleteOnlyHelper,
 		UpdateCols:            updateCols,
 		UpdateColIDtoRowIndex: updateColIDtoRowIndex,
@@ -187,16 +213,15 @@ func makeUpdaterWithoutCascader(
 		// These fields are only used when the primary key is changing.
 		// When changing the primary key, we delete the old values and reinsert
 		// them, so request them all.
-		var err error
 		if ru.rd, err = makeRowDeleterWithoutCascader(
-			txn, tableDesc, fkTables, tableCols, SkipFKs, alloc,
+			txn, tableDesc, fkTables, tableCols, SkipFKs, evalCtx, alloc,
 		); err != nil {
 			return Updater{}, err
 		}
 		ru.FetchCols = ru.rd.FetchCols
 		ru.FetchColIDtoRowIndex = ColIDtoRowIndexFromCols(ru.FetchCols)
 		if ru.ri, err = MakeInserter(txn, tableDesc, fkTables,
-			tableCols, SkipFKs, alloc); err != nil {
+			tableCols, SkipFKs, evalCtx, alloc); err != nil {
 			return Updater{}, err
 		}
 	} else {
@@ -247,20 +272,36 @@ func makeUpdaterWithoutCascader(
 		}
 
 		// Fetch all columns from indices that are being update so that they can
-		// be used to create the new kv pairs for those indices.
-		for _, index := range includeIndexes {
+		// be used to create the new kv pairs for those indices. This includes
+		// the columns referenced by the predicates of partial indexes, which
+		// determine whether the old and new rows have entries in them.
+		addIndexCols := func(index *sqlbase.IndexDescriptor) error {
 			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
+				return err
+			}
+			predCols, err := index.PredicateColumnIDs(tableDesc.TableDesc())
+			if err != nil {
+				return err
+			}
+			for _, colID := range predCols {
+				if err := maybeAddCol(colID); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		for i := range includeIndexes {
+			if err := addIndexCols(&includeIndexes[i]); err != nil {
 				return Updater{}, err
 			}
 		}
-		for _, index := range deleteOnlyIndexes {
-			if err := index.RunOverAllColumns(maybeAddCol); err != nil {
+		for i := range deleteOnlyIndexes {
+			if err := addIndexCols(&deleteOnlyIndexes[i]); err != nil {
 				return Updater{}, err
 			}
 		}
 	}
 
-	var err error
 	if ru.Fks, err = makeFkExistenceCheckHelperForUpdate(txn, tableDesc, fkTables,
 		ru.FetchColIDtoRowIndex, alloc); err != nil {
 		return Updater{}, err
@@ -413,21 +454,35 @@ func (ru *Updater) UpdateRow(
 
 		// We're skipping inverted indexes in this loop, but appending the inverted index entry to the back of
 		// newSecondaryIndexEntries to process later. For inverted indexes we need to remove all old entries before adding
-		// new ones.
+		// new ones. The entries of partial indexes that do not include the old or
+		// new row are empty and are skipped.
 		if index.Type == sqlbase.IndexDescriptor_INVERTED {
-			newSecondaryIndexEntries = append(newSecondaryIndexEntries, *newSecondaryIndexEntry)
-			oldSecondaryIndexEntries = append(oldSecondaryIndexEntries, *oldSecondaryIndexEntry)
+			if newSecondaryIndexEntry.Key != nil {
+				newSecondaryIndexEntries = append(newSecondaryIndexEntries, *newSecondaryIndexEntry)
+			}
+			if oldSecondaryIndexEntry.Key != nil {
+				oldSecondaryIndexEntries = append(oldSecondaryIndexEntries, *oldSecondaryIndexEntry)
+			}
 
 			continue
 		}
+		if newSecondaryIndexEntry.Key == nil && oldSecondaryIndexEntry.Key == nil {
+			// Neither the old nor the new row is included in this partial index.
+			continue
+		}
 
 		var expValue interface{}
 		if !bytes.Equal(newSecondaryIndexEntry.Key, oldSecondaryIndexEntry.Key) {
 			ru.Fks.addCheckForIndex(ru.Helper.Indexes[i].ID, ru.Helper.Indexes[i].Type)
-			if traceKV {
-				log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+			if oldSecondaryIndexEntry.Key != nil {
+				if traceKV {
+					log.VEventf(ctx, 2, "Del %s", keys.PrettyPrint(ru.Helper.secIndexValDirs[i], oldSecondaryIndexEntry.Key))
+				}
+				batch.Del(oldSecondaryIndexEntry.Key)
+			}
+			if newSecondaryIndexEntry.Key == nil {
+				continue
 			}
-			batch.Del(oldSecondaryIndexEntry.Key)
 		} else if !newSecondaryIndexEntry.Value.EqualData(oldSecondaryIndexEntry.Value) {
 			expValue = &oldSecondaryIndexEntry.Value
 		} else {
@@ -450,6 +505,9 @@ func (ru *Updater) UpdateRow(
 	// indexed will be handled separately.
 	if ru.DeleteHelper != nil {
 		for _, deletedSecondaryIndexEntry := range deleteOldSecondaryIndexEntries {
+			if deletedSecondaryIndexEntry.Key == nil {
+				continue
+			}
 			if traceKV {
 				log.VEventf(ctx, 2, "Del %s", deletedSecondaryIndexEntry.Key)
 			}
@@ -12,14 +12,19 @@ package sql
 
 import (
 	"context"
+	"encoding/binary"
+	"hash/fnv"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/builtins"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/transform"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/uuid"
 	"github.com/pkg/errors"
 )
 
@@ -66,21 +71,39 @@ type RowConverter struct {
 	tableDesc *sqlbase.ImmutableTableDescriptor
 
 	// The rest of these are derived from tableDesc, just cached here.
-	hidden                int
-	ri                    row.Inserter
-	EvalCtx               *tree.EvalContext
-	cols                  []sqlbase.ColumnDescriptor
-	VisibleCols           []sqlbase.ColumnDescriptor
-	VisibleColTypes       []*types.T
+	ri      row.Inserter
+	EvalCtx *tree.EvalContext
+	cols    []sqlbase.ColumnDescriptor
+	// VisibleCols are the columns supplied by the input, which are the first
+	// len(VisibleCols) entries of Datums. The values of the rest of the columns
+	// in cols are computed by Row.
+	VisibleCols     []sqlbase.ColumnDescriptor
+	VisibleColTypes []*types.T
+	// uniqueRowIDOrdinals is, for the columns not supplied by the input whose
+	// DEFAULT expression is unique_rowid(), their ordinal among those columns,
+	// and -1 for the rest of the columns, indexed like cols.
+	uniqueRowIDOrdinals []int
+	numUniqueRowIDs     int
+	// randomUUIDs is, for the columns not supplied by the input whose DEFAULT
+	// expression is gen_random_uuid() or uuid_v4(), the type of the values of
+	// the expression, and nil for the rest of the columns, indexed like cols.
+	randomUUIDs           []*types.T
 	defaultExprs          []tree.TypedExpr
+	computedCols          []sqlbase.ColumnDescriptor
+	computeExprs          []tree.TypedExpr
 	computedIVarContainer sqlbase.RowIndexedVarContainer
 }
 
 const kvRowConverterBatchSize = 5000
 
-// NewRowConverter returns an instance of a RowConverter.
+// NewRowConverter returns an instance of a RowConverter. targetColNames are
+// the columns supplied by the input, in order; if empty, the input supplies
+// all the visible, non-computed columns of the table.
 func NewRowConverter(
-	tableDesc *sqlbase.TableDescriptor, evalCtx *tree.EvalContext, kvCh chan<- []roachpb.KeyValue,
+	tableDesc *sqlbase.TableDescriptor,
+	targetColNames tree.NameList,
+	evalCtx *tree.EvalContext,
+	kvCh chan<- []roachpb.KeyValue,
 ) (*RowConverter, error) {
 	immutDesc := sqlbase.NewImmutableTableDescriptor(*tableDesc)
 	c := &RowConverter{
@@ -89,45 +112,108 @@ func NewRowConverter(
 		EvalCtx:   evalCtx,
 	}
 
-	ri, err := row.MakeInserter(nil /* txn */, immutDesc, nil, /* fkTables */
-		immutDesc.Columns, false /* checkFKs */, &sqlbase.DatumAlloc{})
-	if err != nil {
-		return nil, errors.Wrap(err, "make row inserter")
+	var targetCols []sqlbase.ColumnDescriptor
+	if len(targetColNames) == 0 {
+		for _, col := range immutDesc.VisibleColumns() {
+			if !col.IsComputed() {
+				targetCols = append(targetCols, col)
+			}
+		}
+	} else {
+		seen := make(map[sqlbase.ColumnID]struct{}, len(targetColNames))
+		for i := range targetColNames {
+			col, err := immutDesc.FindActiveColumnByName(string(targetColNames[i]))
+			if err != nil {
+				return nil, err
+			}
+			if col.IsComputed() {
+				return nil, sqlbase.CannotWriteToComputedColError(col.Name)
+			}
+			if _, ok := seen[col.ID]; ok {
+				return nil, pgerror.Newf(pgcode.Syntax,
+					"multiple assignments to the same column %q", &targetColNames[i])
+			}
+			seen[col.ID] = struct{}{}
+			targetCols = append(targetCols, *col)
+		}
 	}
-	c.ri = ri
 
+	// The columns that are written are the target columns, followed by the
+	// computed columns and then the remaining columns with a DEFAULT expression.
 	var txCtx transform.ExprTransformContext
-	// Although we don't yet support DEFAULT expressions on visible columns,
-	// we do on hidden columns (which is only the default _rowid one). This
-	// allows those expressions to run.
-	cols, defaultExprs, err := sqlbase.ProcessDefaultColumns(immutDesc.Columns, immutDesc, &txCtx, c.EvalCtx)
+	tn := tree.MakeUnqualifiedTableName(tree.Name(immutDesc.Name))
+	cols, computedCols, computeExprs, err := sqlbase.ProcessComputedColumns(
+		context.TODO(), targetCols, &tn, immutDesc, &txCtx, c.EvalCtx)
+	if err != nil {
+		return nil, errors.Wrap(err, "process computed columns")
+	}
+	cols, defaultExprs, err := sqlbase.ProcessDefaultColumns(cols, immutDesc, &txCtx, c.EvalCtx)
 	if err != nil {
 		return nil, errors.Wrap(err, "process default columns")
 	}
 	c.cols = cols
 	c.defaultExprs = defaultExprs
+	c.computedCols = computedCols
+	c.computeExprs = computeExprs
+
+	ri, err := row.MakeInserter(nil /* txn */, immutDesc, nil, /* fkTables */
+		cols, false /* checkFKs */, evalCtx, &sqlbase.DatumAlloc{})
+	if err != nil {
+		return nil, errors.Wrap(err, "make row inserter")
+	}
+	c.ri = ri
 
-	c.VisibleCols = immutDesc.VisibleColumns()
+	c.VisibleCols = targetCols
 	c.VisibleColTypes = make([]*types.T, len(c.VisibleCols))
 	for i := range c.VisibleCols {
 		c.VisibleColTypes[i] = c.VisibleCols[i].DatumType()
 	}
-	c.Datums = make([]tree.Datum, len(c.VisibleCols), len(cols))
+	c.Datums = make([]tree.Datum, len(cols))
 
-	// Check for a hidden column. This should be the unique_rowid PK if present.
-	c.hidden = -1
+	// The values of the DEFAULT expressions of the columns not supplied by the
+	// input must be the same if the import of a row is retried, or the retry
+	// would write different index entries for the same row. unique_rowid(),
+	// gen_random_uuid() and uuid_v4() are replaced with functions of the
+	// position of the row in the input below, but other volatile expressions
+	// can't be, so they are only allowed for columns that are not part of any
+	// index key, whose values are overwritten by a retry.
+	keyCols := make(map[sqlbase.ColumnID]struct{})
+	for _, idx := range immutDesc.AllNonDropIndexes() {
+		for _, id := range idx.ColumnIDs {
+			keyCols[id] = struct{}{}
+		}
+	}
+	c.uniqueRowIDOrdinals = make([]int, len(cols))
+	c.randomUUIDs = make([]*types.T, len(cols))
 	for i := range cols {
+		c.uniqueRowIDOrdinals[i] = -1
 		col := &cols[i]
-		if col.Hidden {
-			if col.DefaultExpr == nil || *col.DefaultExpr != "unique_rowid()" || c.hidden != -1 {
-				return nil, errors.New("unexpected hidden column")
-			}
-			c.hidden = i
-			c.Datums = append(c.Datums, nil)
+		if i < len(targetCols) || col.DefaultExpr == nil {
+			continue
+		}
+		switch *col.DefaultExpr {
+		case "unique_rowid()":
+			c.uniqueRowIDOrdinals[i] = c.numUniqueRowIDs
+			c.numUniqueRowIDs++
+			continue
+		case "gen_random_uuid()":
+			c.randomUUIDs[i] = types.Uuid
+			continue
+		case "uuid_v4()":
+			c.randomUUIDs[i] = types.Bytes
+			continue
+		}
+		if _, ok := keyCols[col.ID]; !ok {
+			continue
+		}
+		var v volatileFuncVisitor
+		tree.WalkExprConst(&v, defaultExprs[i])
+		if v.fn != nil {
+			return nil, pgerror.Newf(pgcode.FeatureNotSupported,
+				"column %q must be imported into: it is part of an index and its DEFAULT "+
+					"expression calls the volatile function %s",
+				tree.ErrNameString(col.Name), v.fn.Func.String())
 		}
-	}
-	if len(c.Datums) != len(cols) {
-		return nil, errors.New("unexpected hidden column")
 	}
 
 	padding := 2 * (len(immutDesc.Indexes) + len(immutDesc.Families))
@@ -141,32 +227,79 @@ func NewRowConverter(
 	return c, nil
 }
 
+// volatileFuncVisitor finds a call to a volatile function in an expression.
+type volatileFuncVisitor struct {
+	fn *tree.FuncExpr
+}
+
+var _ tree.Visitor = &volatileFuncVisitor{}
+
+func (v *volatileFuncVisitor) VisitPre(expr tree.Expr) (recurse bool, newExpr tree.Expr) {
+	if f, ok := expr.(*tree.FuncExpr); ok && f.IsImpure() && v.fn == nil {
+		v.fn = f
+	}
+	return v.fn == nil, expr
+}
+
+func (*volatileFuncVisitor) VisitPost(expr tree.Expr) tree.Expr { return expr }
+
 // Row inserts kv operations into the current kv batch, and triggers a SendBatch
 // if necessary.
 func (c *RowConverter) Row(ctx context.Context, fileIndex int32, rowIndex int64) error {
-	if c.hidden >= 0 {
-		// We don't want to call unique_rowid() for the hidden PK column because
-		// it is not idempotent. The sampling from the first stage will be useless
-		// during the read phase, producing a single range split with all of the
-		// data. Instead, we will call our own function that mimics that function,
-		// but more-or-less guarantees that it will not interfere with the numbers
-		// that will be produced by it. The lower 15 bits mimic the node id, but as
-		// the CSV file number. The upper 48 bits are the line number and mimic the
-		// timestamp. It would take a file with many more than 2**32 lines to even
-		// begin approaching what unique_rowid would return today, so we assume it
-		// to be safe. Since the timestamp is won't overlap, it is safe to use any
-		// number in the node id portion. The 15 bits in that portion should account
-		// for up to 32k CSV files in a single IMPORT. In the case of > 32k files,
-		// the data is xor'd so the final bits are flipped instead of set.
-		c.Datums[c.hidden] = tree.NewDInt(builtins.GenerateUniqueID(fileIndex, uint64(rowIndex)))
-	}
-
-	// TODO(justin): we currently disallow computed columns in import statements.
-	var computeExprs []tree.TypedExpr
-	var computedCols []sqlbase.ColumnDescriptor
+	// Evaluate the DEFAULT expressions of the columns not supplied by the
+	// input. Computed columns have no DEFAULT and are evaluated below, by
+	// GenerateInsertRow.
+	for i := len(c.VisibleCols); i < len(c.cols); i++ {
+		if ord := c.uniqueRowIDOrdinals[i]; ord >= 0 {
+			// We don't want to call unique_rowid() for the hidden PK column, or
+			// any other column that defaults to it, because it is not
+			// idempotent. The sampling from the first stage will be useless
+			// during the read phase, producing a single range split with all of
+			// the data. Instead, we will call our own function that mimics that
+			// function, but more-or-less guarantees that it will not interfere
+			// with the numbers that will be produced by it. The lower 15 bits
+			// mimic the node id, but as the CSV file number. The upper 48 bits are
+			// the line number and mimic the timestamp. It would take a file with
+			// many more than 2**32 lines to even begin approaching what
+			// unique_rowid would return today, so we assume it to be safe. Since
+			// the timestamp is won't overlap, it is safe to use any number in the
+			// node id portion. The 15 bits in that portion should account for up
+			// to 32k CSV files in a single IMPORT. In the case of > 32k files, the
+			// data is xor'd so the final bits are flipped instead of set. When
+			// several columns default to unique_rowid(), the line number is
+			// spread over them by the ordinal of the column so that they don't
+			// get the same IDs.
+			c.Datums[i] = tree.NewDInt(builtins.GenerateUniqueID(
+				fileIndex, uint64(rowIndex)*uint64(c.numUniqueRowIDs)+uint64(ord)))
+			continue
+		}
+		if typ := c.randomUUIDs[i]; typ != nil {
+			// For the same reason, gen_random_uuid() and uuid_v4() are replaced
+			// by a UUID derived from the file number, the line number and the
+			// column, which looks just as random but is the same if the import of
+			// the row is retried.
+			u := importRandomUUID(fileIndex, rowIndex, i)
+			if typ.Family() == types.UuidFamily {
+				c.Datums[i] = tree.NewDUuid(tree.DUuid{UUID: u})
+			} else {
+				c.Datums[i] = tree.NewDBytes(tree.DBytes(u.GetBytes()))
+			}
+			continue
+		}
+		if c.defaultExprs == nil {
+			c.Datums[i] = tree.DNull
+			continue
+		}
+		d, err := c.defaultExprs[i].Eval(c.EvalCtx)
+		if err != nil {
+			return errors.Wrapf(err, "default expression for column %s",
+				tree.ErrString((*tree.Name)(&c.cols[i].Name)))
+		}
+		c.Datums[i] = d
+	}
 
 	insertRow, err := GenerateInsertRow(
-		c.defaultExprs, computeExprs, c.cols, computedCols, c.EvalCtx, c.tableDesc, c.Datums, &c.computedIVarContainer)
+		c.defaultExprs, c.computeExprs, c.cols, c.computedCols, c.EvalCtx, c.tableDesc, c.Datums, &c.computedIVarContainer)
 	if err != nil {
 		return errors.Wrap(err, "generate insert row")
 	}
@@ -192,6 +325,23 @@ func (c *RowConverter) Row(ctx context.Context, fileIndex int32, rowIndex int64)
 	return nil
 }
 
+// importRandomUUID returns a version 4 UUID whose bits are a hash of the
+// position of a row in the input of an import and of the ordinal of the column
+// in the row.
+func importRandomUUID(fileIndex int32, rowIndex int64, colIdx int) uuid.UUID {
+	var buf [16]byte
+	binary.BigEndian.PutUint32(buf[0:], uint32(fileIndex))
+	binary.BigEndian.PutUint32(buf[4:], uint32(colIdx))
+	binary.BigEndian.PutUint64(buf[8:], uint64(rowIndex))
+	h := fnv.New128a()
+	_, _ = h.Write(buf[:])
+	var u uuid.UUID
+	copy(u[:], h.Sum(nil))
+	u.SetVersion(uuid.V4)
+	u.SetVariant(uuid.VariantRFC4122)
+	return u
+}
+
 // SendBatch streams kv operations from the current KvBatch to the destination
 // channel, and resets the KvBatch to empty.
 func (c *RowConverter) SendBatch(ctx context.Context) error {
@@ -0,0 +1,554 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/metric"
+	"github.com/cockroachdb/cockroach/pkg/util/timeutil"
+	"github.com/cockroachdb/errors"
+	"golang.org/x/time/rate"
+)
+
+// rowLevelTTLJobInterval is the time between two deletion passes of a
+// row-level TTL job.
+var rowLevelTTLJobInterval = settings.RegisterValidatedDurationSetting(
+	"sql.ttl.job_interval",
+	"how often the row-level TTL job of a table deletes expired rows",
+	5*time.Minute,
+	func(v time.Duration) error {
+		if v <= 0 {
+			return errors.Errorf("cannot set sql.ttl.job_interval to a non-positive duration: %s", v)
+		}
+		return nil
+	},
+)
+
+// rowLevelTTLDeleteBatchSize is the maximum number of rows deleted by a
+// single DELETE statement of a row-level TTL job. Each batch is its own
+// transaction, which keeps the intents laid down by the job bounded.
+var rowLevelTTLDeleteBatchSize = settings.RegisterPositiveIntSetting(
+	"sql.ttl.delete_batch_size",
+	"number of expired rows deleted by each statement of a row-level TTL job",
+	500,
+)
+
+// rowLevelTTLDeleteRateLimit bounds the rate at which a row-level TTL job
+// deletes rows, so that expiring a large backlog of rows doesn't overwhelm
+// the foreground traffic on the table.
+var rowLevelTTLDeleteRateLimit = settings.RegisterNonNegativeIntSetting(
+	"sql.ttl.delete_rate_limit",
+	"maximum number of expired rows deleted per second by each row-level TTL job (0 means no limit)",
+	1000,
+)
+
+var (
+	metaRowLevelTTLRowsDeleted = metric.Metadata{
+		Name:        "sql.ttl.rows_deleted",
+		Help:        "Number of expired rows deleted by row-level TTL jobs",
+		Measurement: "Rows",
+		Unit:        metric.Unit_COUNT,
+	}
+	metaRowLevelTTLDeleteLatency = metric.Metadata{
+		Name:        "sql.ttl.delete_latency",
+		Help:        "Latency of the statements deleting a batch of expired rows",
+		Measurement: "Latency",
+		Unit:        metric.Unit_NANOSECONDS,
+	}
+	metaRowLevelTTLPasses = metric.Metadata{
+		Name:        "sql.ttl.passes",
+		Help:        "Number of completed deletion passes of row-level TTL jobs",
+		Measurement: "Passes",
+		Unit:        metric.Unit_COUNT,
+	}
+)
+
+// RowLevelTTLMetrics are the metrics of the row-level TTL jobs running on a
+// node.
+type RowLevelTTLMetrics struct {
+	RowsDeleted   *metric.Counter
+	DeleteLatency *metric.Histogram
+	Passes        *metric.Counter
+}
+
+// MetricStruct implements the metric.Struct interface.
+func (*RowLevelTTLMetrics) MetricStruct() {}
+
+func makeRowLevelTTLMetrics(histogramWindow time.Duration) metric.Struct {
+	return &RowLevelTTLMetrics{
+		RowsDeleted:   metric.NewCounter(metaRowLevelTTLRowsDeleted),
+		DeleteLatency: metric.NewLatency(metaRowLevelTTLDeleteLatency, histogramWindow),
+		Passes:        metric.NewCounter(metaRowLevelTTLPasses),
+	}
+}
+
+// setRowLevelTTL makes the rows of tableDesc expire once the value of col is
+// older than the interval ttlExpr evaluates to. If index is nil, the index
+// scanned for expired rows is picked among the indexes of the table.
+//
+// A new row-level TTL job is created for every call, in the current
+// transaction. A job previously created for the table notices that it no
+// longer owns the TTL of the table at the start of its next pass and exits.
+func (p *planner) setRowLevelTTL(
+	ctx context.Context,
+	tableDesc *sqlbase.MutableTableDescriptor,
+	ttlExpr tree.Expr,
+	col *sqlbase.ColumnDescriptor,
+	index *sqlbase.IndexDescriptor,
+) error {
+	if tableDesc.IsTemporary() {
+		return pgerror.Newf(pgcode.FeatureNotSupported,
+			"row-level TTL is not supported on temporary table %q", tableDesc.Name)
+	}
+	switch col.Type.Family() {
+	case types.TimestampFamily, types.TimestampTZFamily:
+	default:
+		return pgerror.Newf(pgcode.DatatypeMismatch,
+			"row-level TTL column %q must be of type TIMESTAMP or TIMESTAMPTZ, found %s",
+			col.Name, col.Type.SQLString())
+	}
+
+	typedExpr, err := p.analyzeExpr(
+		ctx, ttlExpr, nil, tree.IndexedVarHelper{}, types.Interval, true /* requireType */, "TTL",
+	)
+	if err != nil {
+		return err
+	}
+	d, err := typedExpr.Eval(p.EvalContext())
+	if err != nil {
+		return err
+	}
+	if d == tree.DNull {
+		return pgerror.New(pgcode.InvalidParameterValue, "TTL must not be NULL")
+	}
+	duration, _, _, err := d.(*tree.DInterval).Duration.Encode()
+	if err != nil {
+		return err
+	}
+	if duration <= 0 {
+		return pgerror.Newf(pgcode.InvalidParameterValue,
+			"TTL must be a positive interval, found %s", d)
+	}
+
+	if index == nil {
+		index = rowLevelTTLIndex(tableDesc.TableDesc(), col.ID)
+	}
+	jobID, err := p.createRowLevelTTLJob(ctx, tableDesc.TableDesc())
+	if err != nil {
+		return err
+	}
+	tableDesc.RowLevelTTL = &sqlbase.TableDescriptor_RowLevelTTL{
+		ColumnID: col.ID,
+		IndexID:  index.ID,
+		Duration: duration,
+		JobID:    jobID,
+	}
+	return nil
+}
+
+// rowLevelTTLIndex returns the index scanned for the rows of desc that expired
+// according to the given column. An index whose first column is the TTL column
+// lets the job only read expired rows, so the primary index is preferred if it
+// is such an index, and then any such secondary index. Failing that, the whole
+// primary index is scanned on every pass.
+func rowLevelTTLIndex(desc *sqlbase.TableDescriptor, colID sqlbase.ColumnID) *sqlbase.IndexDescriptor {
+	if desc.PrimaryIndex.ColumnIDs[0] == colID {
+		return &desc.PrimaryIndex
+	}
+	for i := range desc.Indexes {
+		idx := &desc.Indexes[i]
+		if !idx.IsPartial() && idx.ColumnIDs[0] == colID {
+			return idx
+		}
+	}
+	return &desc.PrimaryIndex
+}
+
+// createRowLevelTTLJob creates the job deleting the expired rows of desc. The
+// job only starts running once the current transaction commits.
+func (p *planner) createRowLevelTTLJob(
+	ctx context.Context, desc *sqlbase.TableDescriptor,
+) (int64, error) {
+	tableName, err := p.getQualifiedTableName(ctx, desc)
+	if err != nil {
+		return 0, err
+	}
+	return CreateRowLevelTTLJob(ctx, p.ExecCfg().JobRegistry, p.txn, p.User(), desc, tableName)
+}
+
+// CreateRowLevelTTLJob creates the job deleting the expired rows of desc,
+// whose fully qualified name is tableName, in txn. The job only starts running
+// once txn commits, and the caller must store its ID in the row-level TTL of
+// desc for the job to delete any rows.
+func CreateRowLevelTTLJob(
+	ctx context.Context,
+	registry *jobs.Registry,
+	txn *client.Txn,
+	user string,
+	desc *sqlbase.TableDescriptor,
+	tableName string,
+) (int64, error) {
+	job, err := registry.CreateAdoptableJobWithTxn(ctx, jobs.Record{
+		Description:   fmt.Sprintf("row-level TTL for table %s", tableName),
+		Username:      user,
+		DescriptorIDs: sqlbase.IDs{desc.ID},
+		Details:       jobspb.RowLevelTTLDetails{TableID: desc.ID},
+		Progress:      jobspb.RowLevelTTLProgress{},
+	}, txn)
+	if err != nil {
+		return 0, err
+	}
+	return *job.ID(), nil
+}
+
+// checkIndexNotUsedByRowLevelTTL returns an error if the given index of desc is
+// scanned by its row-level TTL job.
+func checkIndexNotUsedByRowLevelTTL(
+	desc *sqlbase.MutableTableDescriptor, idx *sqlbase.IndexDescriptor,
+) error {
+	if desc.RowLevelTTL != nil && desc.RowLevelTTL.IndexID == idx.ID {
+		return pgerror.Newf(pgcode.DependentObjectsStillExist,
+			"index %q is in use by the row-level TTL of table %q", idx.Name, desc.Name)
+	}
+	return nil
+}
+
+// rowLevelTTLResumer implements the jobs.Resumer interface for row-level TTL
+// jobs. A row-level TTL job never completes by itself: it runs a deletion
+// pass every sql.ttl.job_interval until the TTL of its table is removed or
+// replaced, or the table is dropped.
+//
+// Expired rows are deleted with regular DELETE statements through the
+// internal executor rather than with raw DeleteRange requests, so that the
+// entries of the rows in secondary indexes are deleted along with them.
+// The expired rows are found by scanning the TTL index, which lets the job
+// only read the expired rows when the TTL column leads the index.
+type rowLevelTTLResumer struct {
+	job *jobs.Job
+}
+
+var _ jobs.Resumer = &rowLevelTTLResumer{}
+
+// Resume is part of the jobs.Resumer interface.
+func (r *rowLevelTTLResumer) Resume(
+	ctx context.Context, phs interface{}, resultsCh chan<- tree.Datums,
+) error {
+	execCfg := phs.(*planner).ExecCfg()
+	metrics := execCfg.JobRegistry.MetricsStruct().RowLevelTTL.(*RowLevelTTLMetrics)
+
+	timer := timeutil.NewTimer()
+	defer timer.Stop()
+	for {
+		// Exit if the job was paused or canceled. Any other error of a pass
+		// is retried on the next one.
+		if err := r.job.CheckStatus(ctx); err != nil {
+			return err
+		}
+		if active, err := r.deleteExpiredRows(ctx, execCfg, metrics); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if statusErr := r.job.CheckStatus(ctx); statusErr != nil {
+				return statusErr
+			}
+			log.Warningf(ctx, "row-level TTL job %d failed to delete expired rows: %v", *r.job.ID(), err)
+		} else if !active {
+			return nil
+		} else {
+			metrics.Passes.Inc(1)
+		}
+
+		timer.Reset(rowLevelTTLJobInterval.Get(&execCfg.Settings.SV))
+		select {
+		case <-timer.C:
+			timer.Read = true
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// deleteExpiredRows runs a single deletion pass of the job. It returns false
+// if the job no longer owns the row-level TTL of its table, in which case
+// there is nothing left to do for it.
+//
+// Each batch of expired rows is found by scanning the TTL index from the key
+// of the last row of the previous batch, rather than from the start of the
+// index, which would read the rows deleted by all previous batches of the
+// pass again (or at least their tombstones). The rows of the batch are then
+// deleted by primary key.
+func (r *rowLevelTTLResumer) deleteExpiredRows(
+	ctx context.Context, execCfg *ExecutorConfig, metrics *RowLevelTTLMetrics,
+) (bool, error) {
+	details := r.job.Details().(jobspb.RowLevelTTLDetails)
+	batchSize := rowLevelTTLDeleteBatchSize.Get(&execCfg.Settings.SV)
+
+	var ttl *sqlbase.TableDescriptor_RowLevelTTL
+	var colType *types.T
+	var tableName, indexName, colName string
+	var keyCols []string
+	var keyDirs []sqlbase.IndexDescriptor_Direction
+	var pkOrdinals []int
+	if err := execCfg.DB.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		desc, err := sqlbase.GetTableDescFromID(ctx, txn, details.TableID)
+		if err != nil {
+			return err
+		}
+		if desc.Dropped() || desc.RowLevelTTL == nil || desc.RowLevelTTL.JobID != *r.job.ID() {
+			return nil
+		}
+		ttl = desc.RowLevelTTL
+		col, err := desc.FindActiveColumnByID(ttl.ColumnID)
+		if err != nil {
+			return err
+		}
+		colType = &col.Type
+		colName = tree.NameString(col.Name)
+		idx, err := desc.FindIndexByID(ttl.IndexID)
+		if err != nil {
+			return err
+		}
+		indexName = tree.NameString(idx.Name)
+		dbDesc, err := sqlbase.GetDatabaseDescFromID(ctx, txn, desc.ParentID)
+		if err != nil {
+			return err
+		}
+		tn := tree.MakeTableName(tree.Name(dbDesc.Name), tree.Name(desc.Name))
+		tableName = tn.String()
+
+		// The rows are paginated on the full key of the TTL index, which ends
+		// with the primary key columns missing from the index. These are always
+		// part of the key of a non-unique index, and they break the ties between
+		// the NULL values of a unique one.
+		keyIDs := append(append([]sqlbase.ColumnID(nil), idx.ColumnIDs...), idx.ExtraColumnIDs...)
+		keyDirs = append([]sqlbase.IndexDescriptor_Direction(nil), idx.ColumnDirections...)
+		for range idx.ExtraColumnIDs {
+			keyDirs = append(keyDirs, sqlbase.IndexDescriptor_ASC)
+		}
+		ordinals := make(map[sqlbase.ColumnID]int, len(keyIDs))
+		for i, id := range keyIDs {
+			keyCol, err := desc.FindActiveColumnByID(id)
+			if err != nil {
+				return err
+			}
+			keyCols = append(keyCols, tree.NameString(keyCol.Name))
+			ordinals[id] = i
+		}
+		for _, id := range desc.PrimaryIndex.ColumnIDs {
+			pkOrdinals = append(pkOrdinals, ordinals[id])
+		}
+		return nil
+	}); err != nil {
+		if errors.Is(err, sqlbase.ErrDescriptorNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	if ttl == nil {
+		return false, nil
+	}
+
+	cutoff := timeutil.Now().Add(-time.Duration(ttl.Duration))
+	var cutoffDatum tree.Datum = tree.MakeDTimestampTZ(cutoff, time.Microsecond)
+	if colType.Family() == types.TimestampFamily {
+		cutoffDatum = tree.MakeDTimestamp(cutoff, time.Microsecond)
+	}
+
+	limit := rate.Inf
+	if l := rowLevelTTLDeleteRateLimit.Get(&execCfg.Settings.SV); l > 0 {
+		limit = rate.Limit(l)
+	}
+	limiter := rate.NewLimiter(limit, int(batchSize))
+
+	orderBy := make([]string, len(keyCols))
+	for i := range keyCols {
+		orderBy[i] = keyCols[i]
+		if keyDirs[i] == sqlbase.IndexDescriptor_DESC {
+			orderBy[i] += " DESC"
+		}
+	}
+	pkCols := make([]string, len(pkOrdinals))
+	for i, ord := range pkOrdinals {
+		pkCols[i] = keyCols[ord]
+	}
+
+	var deleted int64
+	var lastKey tree.Datums
+	for {
+		start := timeutil.Now()
+		args := []interface{}{cutoffDatum}
+		var resume string
+		if lastKey != nil {
+			resume, args = rowLevelTTLResumePredicate(keyCols, keyDirs, lastKey, args)
+			resume = " AND " + resume
+		}
+		rows, err := execCfg.InternalExecutor.Query(
+			ctx, "row-level-ttl-select", nil, /* txn */
+			fmt.Sprintf(`SELECT %s FROM %s@%s WHERE %s < $1%s ORDER BY %s LIMIT %d`,
+				strings.Join(keyCols, ", "), tableName, indexName, colName, resume,
+				strings.Join(orderBy, ", "), batchSize),
+			args...,
+		)
+		if err != nil {
+			return false, err
+		}
+		if len(rows) == 0 {
+			break
+		}
+		lastKey = rows[len(rows)-1]
+
+		// The TTL column is checked again, since the rows may have been updated
+		// since they were read.
+		args = []interface{}{cutoffDatum}
+		pks := make([]string, len(rows))
+		for i, row := range rows {
+			placeholders := make([]string, len(pkOrdinals))
+			for j, ord := range pkOrdinals {
+				args = append(args, row[ord])
+				placeholders[j] = fmt.Sprintf("$%d", len(args))
+			}
+			pks[i] = "(" + strings.Join(placeholders, ", ") + ")"
+		}
+		n, err := execCfg.InternalExecutor.Exec(
+			ctx, "row-level-ttl-delete", nil, /* txn */
+			fmt.Sprintf(`DELETE FROM %s WHERE %s < $1 AND (%s) IN (%s)`,
+				tableName, colName, strings.Join(pkCols, ", "), strings.Join(pks, ", ")),
+			args...,
+		)
+		if err != nil {
+			return false, err
+		}
+		metrics.DeleteLatency.RecordValue(timeutil.Since(start).Nanoseconds())
+		metrics.RowsDeleted.Inc(int64(n))
+		deleted += int64(n)
+
+		if err := r.job.RunningStatus(ctx, func(
+			_ context.Context, details jobspb.Details,
+		) (jobs.RunningStatus, error) {
+			prog := details.(*jobspb.Progress_RowLevelTTL).RowLevelTTL
+			prog.RowsDeleted += int64(n)
+			return jobs.RunningStatus(fmt.Sprintf(
+				"deleting rows expired before %s: %d deleted", cutoff.Format(time.RFC3339), deleted,
+			)), nil
+		}); err != nil {
+			return false, err
+		}
+		if int64(len(rows)) < batchSize {
+			break
+		}
+		if err := limiter.WaitN(ctx, len(rows)); err != nil {
+			return false, err
+		}
+	}
+
+	if err := r.job.RunningStatus(ctx, func(
+		_ context.Context, details jobspb.Details,
+	) (jobs.RunningStatus, error) {
+		prog := details.(*jobspb.Progress_RowLevelTTL).RowLevelTTL
+		prog.LastCutoffMicros = cutoff.UnixNano() / int64(time.Microsecond)
+		return jobs.RunningStatus(fmt.Sprintf(
+			"waiting for the next pass: %d rows expired before %s deleted",
+			deleted, cutoff.Format(time.RFC3339),
+		)), nil
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// rowLevelTTLResumePredicate returns the predicate selecting the rows that
+// come after lastKey in the order of an index with the given key columns and
+// directions, along with args extended by the placeholder values it uses.
+//
+// The predicate is the disjunction, for every key column, of the rows equal
+// to lastKey on the previous columns and after it on that column. NULLs sort
+// first in ascending columns and last in descending ones, as in the index.
+func rowLevelTTLResumePredicate(
+	keyCols []string,
+	keyDirs []sqlbase.IndexDescriptor_Direction,
+	lastKey tree.Datums,
+	args []interface{},
+) (string, []interface{}) {
+	placeholders := make([]string, len(lastKey))
+	for i, d := range lastKey {
+		if d != tree.DNull {
+			args = append(args, d)
+			placeholders[i] = fmt.Sprintf("$%d", len(args))
+		}
+	}
+
+	var disjuncts []string
+	var eqs []string
+	for i, col := range keyCols {
+		var after string
+		switch {
+		case lastKey[i] == tree.DNull && keyDirs[i] == sqlbase.IndexDescriptor_DESC:
+			// Nothing comes after a NULL in a descending column.
+		case lastKey[i] == tree.DNull:
+			after = fmt.Sprintf("%s IS NOT NULL", col)
+		case keyDirs[i] == sqlbase.IndexDescriptor_DESC:
+			after = fmt.Sprintf("(%s < %s OR %s IS NULL)", col, placeholders[i], col)
+		default:
+			after = fmt.Sprintf("%s > %s", col, placeholders[i])
+		}
+		if after != "" {
+			disjuncts = append(disjuncts,
+				"("+strings.Join(append(append([]string(nil), eqs...), after), " AND ")+")")
+		}
+		if lastKey[i] == tree.DNull {
+			eqs = append(eqs, fmt.Sprintf("%s IS NULL", col))
+		} else {
+			eqs = append(eqs, fmt.Sprintf("%s = %s", col, placeholders[i]))
+		}
+	}
+	if len(disjuncts) == 0 {
+		return "false", args
+	}
+	return "(" + strings.Join(disjuncts, " OR ") + ")", args
+}
+
+// OnFailOrCancel is part of the jobs.Resumer interface.
+func (r *rowLevelTTLResumer) OnFailOrCancel(context.Context, *client.Txn) error {
+	return nil
+}
+
+// OnSuccess is part of the jobs.Resumer interface.
+func (r *rowLevelTTLResumer) OnSuccess(context.Context, *client.Txn) error {
+	return nil
+}
+
+// OnTerminal is part of the jobs.Resumer interface.
+func (r *rowLevelTTLResumer) OnTerminal(context.Context, jobs.Status, chan<- tree.Datums) {}
+
+func init() {
+	jobs.MakeRowLevelTTLMetricsHook = makeRowLevelTTLMetrics
+	jobs.RegisterConstructor(
+		jobspb.TypeRowLevelTTL,
+		func(job *jobs.Job, _ *cluster.Settings) jobs.Resumer {
+			return &rowLevelTTLResumer{job: job}
+		},
+	)
+}
@@ -0,0 +1,114 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/jobs"
+	"github.com/cockroachdb/cockroach/pkg/sql"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+// TestRowLevelTTL verifies that the row-level TTL job of a table deletes the
+// expired rows of the table, along with their secondary index entries, and
+// exits once the TTL of the table is removed.
+func TestRowLevelTTL(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(oldInterval time.Duration) {
+		jobs.DefaultAdoptInterval = oldInterval
+	}(jobs.DefaultAdoptInterval)
+	jobs.DefaultAdoptInterval = 100 * time.Millisecond
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `SET CLUSTER SETTING sql.ttl.job_interval = '100ms'`)
+	r.Exec(t, `SET CLUSTER SETTING sql.ttl.delete_batch_size = 7`)
+	r.Exec(t, `CREATE DATABASE d`)
+	r.Exec(t, `CREATE TABLE d.t (k INT PRIMARY KEY, ts TIMESTAMP NOT NULL, v INT, INDEX (ts), INDEX (v))`)
+	r.Exec(t, `INSERT INTO d.t SELECT k, now() - '2 days'::INTERVAL, k FROM generate_series(1, 50) AS g(k)`)
+	r.Exec(t, `INSERT INTO d.t SELECT k, now() + '1 day'::INTERVAL, k FROM generate_series(51, 60) AS g(k)`)
+
+	r.Exec(t, `ALTER TABLE d.t SET TTL '1 day' ON ts`)
+	r.CheckQueryResultsRetry(t, `SELECT count(*) FROM d.t`, [][]string{{"10"}})
+	r.CheckQueryResults(t, `SELECT count(*) FROM d.t@t_v_idx WHERE v <= 50`, [][]string{{"0"}})
+	r.CheckQueryResults(t, `SELECT min(k) FROM d.t@t_ts_idx`, [][]string{{"51"}})
+
+	metrics := s.JobRegistry().(*jobs.Registry).MetricsStruct().RowLevelTTL.(*sql.RowLevelTTLMetrics)
+	testutils.SucceedsSoon(t, func() error {
+		if n := metrics.RowsDeleted.Count(); n != 50 {
+			return errors.Errorf("expected 50 deleted rows, found %d", n)
+		}
+		return nil
+	})
+
+	r.Exec(t, `ALTER TABLE d.t RESET TTL`)
+	r.CheckQueryResultsRetry(t,
+		`SELECT status FROM [SHOW JOBS] WHERE job_type = 'ROW LEVEL TTL'`, [][]string{{"succeeded"}},
+	)
+}
+
+// TestRowLevelTTLPagination verifies that the row-level TTL job deletes all
+// the expired rows when its batches are paginated on TTL indexes with
+// descending and NULL key columns, and on the primary index.
+func TestRowLevelTTLPagination(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	defer func(oldInterval time.Duration) {
+		jobs.DefaultAdoptInterval = oldInterval
+	}(jobs.DefaultAdoptInterval)
+	jobs.DefaultAdoptInterval = 100 * time.Millisecond
+
+	s, db, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+
+	r := sqlutils.MakeSQLRunner(db)
+	r.Exec(t, `SET CLUSTER SETTING sql.ttl.job_interval = '100ms'`)
+	r.Exec(t, `SET CLUSTER SETTING sql.ttl.delete_batch_size = 7`)
+	r.Exec(t, `CREATE DATABASE d`)
+
+	// Many expired rows share their TTL column value and their NULL value of
+	// the second column of the index, so that batches end within runs of
+	// equal values.
+	r.Exec(t, `CREATE TABLE d.u (
+  a INT,
+  b STRING,
+  ts TIMESTAMPTZ NOT NULL,
+  v INT,
+  PRIMARY KEY (a, b DESC),
+  INDEX ts_v_idx (ts, v DESC)
+)`)
+	r.Exec(t, `INSERT INTO d.u SELECT k % 3, k::STRING, now() - (k % 2) * '1 day'::INTERVAL - '25 hours'::INTERVAL,
+  CASE WHEN k % 4 = 0 THEN NULL ELSE k % 5 END FROM generate_series(1, 60) AS g(k)`)
+	r.Exec(t, `INSERT INTO d.u SELECT 10, k::STRING, now() + '1 day'::INTERVAL, NULL FROM generate_series(1, 10) AS g(k)`)
+
+	// Without an index on the TTL column, the primary index is scanned.
+	r.Exec(t, `CREATE TABLE d.w (k INT, j INT, ts TIMESTAMP NOT NULL, PRIMARY KEY (k DESC, j))`)
+	r.Exec(t, `INSERT INTO d.w SELECT k % 4, k, now() - '2 days'::INTERVAL FROM generate_series(1, 60) AS g(k)`)
+	r.Exec(t, `INSERT INTO d.w SELECT k % 4, k, now() + '1 day'::INTERVAL FROM generate_series(61, 70) AS g(k)`)
+
+	r.Exec(t, `ALTER INDEX d.u@ts_v_idx SET TTL '1 day'`)
+	r.Exec(t, `ALTER TABLE d.w SET TTL '1 day' ON ts`)
+	r.CheckQueryResultsRetry(t, `SELECT count(*) FROM d.u`, [][]string{{"10"}})
+	r.CheckQueryResultsRetry(t, `SELECT count(*) FROM d.w`, [][]string{{"10"}})
+	r.CheckQueryResults(t, `SELECT count(*) FROM d.u@ts_v_idx WHERE a != 10`, [][]string{{"0"}})
+	r.CheckQueryResults(t, `SELECT min(j) FROM d.w`, [][]string{{"61"}})
+}
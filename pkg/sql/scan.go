@@ -23,6 +23,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/pkg/errors"
 )
 
@@ -117,6 +118,11 @@ type scanNode struct {
 	// scan is guaranteed to return.
 	maxResults uint64
 
+	// lockingStrength is the row-level locking mode used by the scan. If
+	// exclusive, the scan acquires locks on every row it returns and must run
+	// in the root transaction.
+	lockingStrength sqlbase.ScanLockingStrength
+
 	// Indicates if this scan is the source for a delete node.
 	isDeleteSource bool
 }
@@ -305,6 +311,10 @@ func (n *scanNode) lookupSpecifiedIndex(indexFlags *tree.IndexFlags) error {
 			return errors.Errorf("index [%d] not found", indexFlags.IndexID)
 		}
 	}
+	if n.specifiedIndex != nil && n.specifiedIndex.IsPartial() {
+		return unimplemented.NewWithIssuef(9683,
+			"cannot force the use of partial index %q", n.specifiedIndex.Name)
+	}
 	if indexFlags.Direction == tree.Descending {
 		n.specifiedIndexReverse = true
 	}
@@ -11,6 +11,7 @@
 package sql
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"math"
@@ -29,6 +30,8 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/security"
 	"github.com/cockroachdb/cockroach/pkg/settings"
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/backfill"
+	"github.com/cockroachdb/cockroach/pkg/sql/distsqlrun"
 	"github.com/cockroachdb/cockroach/pkg/sql/parser"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
@@ -572,195 +575,453 @@ func (sc *SchemaChanger) maybeDropTable(
 }
 
 // maybe backfill a created table by executing the AS query. Return nil if
-// successfully backfilled.
+// successfully backfilled. Materialized views are populated with the results
+// of their view query by backfillMaterializedView.
 func (sc *SchemaChanger) maybeBackfillCreateTableAs(
 	ctx context.Context,
 	table *sqlbase.TableDescriptor,
 	evalCtx *extendedEvalContext,
 	placeholders *tree.PlaceholderInfo,
 ) error {
-	if table.Adding() && table.IsAs() {
-		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
-			txn.SetFixedTimestamp(ctx, table.CreateAsOfTime)
-
-			// Create an internal planner as the planner used to serve the user query
-			// would have committed by this point.
-			p, cleanup := NewInternalPlanner("ctasBackfill", txn, security.RootUser, &MemoryMetrics{}, sc.execCfg)
-			defer cleanup()
-			localPlanner := p.(*planner)
-			stmt, err := parser.ParseOne(table.CreateQuery)
-			if err != nil {
-				return err
-			}
+	if !table.Adding() || !(table.IsAs() || table.MaterializedView()) {
+		return nil
+	}
+	if table.MaterializedView() {
+		// No job tracks the creation of the view, so there is nowhere to record
+		// checkpoints and the backfill starts over if it is interrupted.
+		return sc.backfillMaterializedView(
+			ctx, nil /* lease */, table, &table.PrimaryIndex, table.Indexes, table.CreateAsOfTime,
+			0 /* resumeRowID */, nil /* checkpoint */, evalCtx,
+		)
+	}
+	return sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, table.CreateAsOfTime)
+		return sc.backfillQueryIntoTable(ctx, table, table.CreateQuery, txn, evalCtx)
+	})
+}
 
-			// Construct an optimized logical plan of the AS source stmt.
-			// TODO(adityamaru): Design a way to fallback on the heuristic planner if
-			// the optimizer fails.
-			localPlanner.stmt = &Statement{Statement: stmt}
-			localPlanner.optPlanningCtx.init(localPlanner)
-
-			var result *planTop
-			localPlanner.runWithOptions(resolveFlags{skipCache: true}, func() {
-				result, _, err = localPlanner.makeOptimizerPlan(ctx)
-				if err == nil {
-					localPlanner.curPlan = *result
-				}
-			})
+// planAndRunBackfillQuery plans the given query with localPlanner, which must
+// be an internal planner using txn, and runs it, passing its results to rw.
+func (sc *SchemaChanger) planAndRunBackfillQuery(
+	ctx context.Context,
+	localPlanner *planner,
+	query string,
+	txn *client.Txn,
+	rw rowResultWriter,
+	evalCtx *extendedEvalContext,
+) error {
+	stmt, err := parser.ParseOne(query)
+	if err != nil {
+		return err
+	}
 
-			if err != nil {
-				return err
-			}
-			defer localPlanner.curPlan.close(ctx)
+	// Construct an optimized logical plan of the AS source stmt.
+	// TODO(adityamaru): Design a way to fallback on the heuristic planner if
+	// the optimizer fails.
+	localPlanner.stmt = &Statement{Statement: stmt}
+	localPlanner.optPlanningCtx.init(localPlanner)
 
-			colTypes := make([]types.T, len(table.VisibleColumns()))
-			for i, t := range table.VisibleColumns() {
-				colTypes[i] = t.Type
-			}
-			ci := sqlbase.ColTypeInfoFromColTypes(colTypes)
-			rows := rowcontainer.NewRowContainer(
-				localPlanner.EvalContext().Mon.MakeBoundAccount(), ci, 0,
-			)
-			defer rows.Close(ctx)
+	var result *planTop
+	localPlanner.runWithOptions(resolveFlags{skipCache: true}, func() {
+		result, _, err = localPlanner.makeOptimizerPlan(ctx)
+		if err == nil {
+			localPlanner.curPlan = *result
+		}
+	})
 
-			rw := NewRowResultWriter(rows)
-			recv := MakeDistSQLReceiver(
-				ctx,
-				rw,
-				stmt.AST.StatementType(),
-				sc.execCfg.RangeDescriptorCache,
-				sc.execCfg.LeaseHolderCache,
-				txn,
-				func(ts hlc.Timestamp) {
-					_ = sc.clock.Update(ts)
-				},
-				evalCtx.Tracing,
-			)
-			defer recv.Release()
-
-			planCtx := sc.distSQLPlanner.NewPlanningCtx(ctx, localPlanner.ExtendedEvalContext(), txn)
-			rec, err := sc.distSQLPlanner.checkSupportForNode(localPlanner.curPlan.plan)
-			planCtx.isLocal = err != nil || rec == cannotDistribute
-			planCtx.planner = localPlanner
-			planCtx.stmtType = recv.stmtType
-
-			var planAndRunErr error
-			localPlanner.runWithOptions(resolveFlags{skipCache: true}, func() {
-				if len(localPlanner.curPlan.subqueryPlans) != 0 {
-					if !sc.distSQLPlanner.PlanAndRunSubqueries(
-						planCtx.ctx, localPlanner, localPlanner.ExtendedEvalContextCopy,
-						localPlanner.curPlan.subqueryPlans, recv, rec == canDistribute,
-					) {
-						if planAndRunErr = rw.Err(); err != nil {
-							return
-						}
-						if recv.commErr != nil {
-							planAndRunErr = recv.commErr
-							return
-						}
-					}
-				}
-				// Copy the evalCtx as it might be modified.
-				evalCtxCopy := localPlanner.ExtendedEvalContextCopy()
+	if err != nil {
+		return err
+	}
+	defer localPlanner.curPlan.close(ctx)
 
-				sc.distSQLPlanner.PlanAndRun(ctx, evalCtxCopy, planCtx, txn, localPlanner.curPlan.plan, recv)
-				if recv.commErr != nil {
-					planAndRunErr = recv.commErr
+	recv := MakeDistSQLReceiver(
+		ctx,
+		rw,
+		stmt.AST.StatementType(),
+		sc.execCfg.RangeDescriptorCache,
+		sc.execCfg.LeaseHolderCache,
+		txn,
+		func(ts hlc.Timestamp) {
+			_ = sc.clock.Update(ts)
+		},
+		evalCtx.Tracing,
+	)
+	defer recv.Release()
+
+	planCtx := sc.distSQLPlanner.NewPlanningCtx(ctx, localPlanner.ExtendedEvalContext(), txn)
+	rec, err := sc.distSQLPlanner.checkSupportForNode(localPlanner.curPlan.plan)
+	planCtx.isLocal = err != nil || rec == cannotDistribute
+	planCtx.planner = localPlanner
+	planCtx.stmtType = recv.stmtType
+
+	var planAndRunErr error
+	localPlanner.runWithOptions(resolveFlags{skipCache: true}, func() {
+		if len(localPlanner.curPlan.subqueryPlans) != 0 {
+			if !sc.distSQLPlanner.PlanAndRunSubqueries(
+				planCtx.ctx, localPlanner, localPlanner.ExtendedEvalContextCopy,
+				localPlanner.curPlan.subqueryPlans, recv, rec == canDistribute,
+			) {
+				if planAndRunErr = rw.Err(); err != nil {
 					return
 				}
-				if rw.Err() != nil {
-					planAndRunErr = rw.Err()
+				if recv.commErr != nil {
+					planAndRunErr = recv.commErr
 					return
 				}
-			})
-
-			if planAndRunErr != nil {
-				return planAndRunErr
 			}
+		}
+		// Copy the evalCtx as it might be modified.
+		evalCtxCopy := localPlanner.ExtendedEvalContextCopy()
 
-			// This is a very simplified version of the INSERT logic: no CHECK
-			// expressions, no FK checks, no arbitrary insertion order, no
-			// RETURNING, etc.
+		sc.distSQLPlanner.PlanAndRun(ctx, evalCtxCopy, planCtx, txn, localPlanner.curPlan.plan, recv)
+		if recv.commErr != nil {
+			planAndRunErr = recv.commErr
+			return
+		}
+		if rw.Err() != nil {
+			planAndRunErr = rw.Err()
+			return
+		}
+	})
+	return planAndRunErr
+}
 
-			// Instantiate a row inserter and table writer.
-			ri, err := row.MakeInserter(
-				txn,
-				sqlbase.NewImmutableTableDescriptor(*table),
-				nil,
-				table.Columns,
-				row.SkipFKs,
-				&localPlanner.alloc)
-			if err != nil {
-				return err
-			}
-			ti := tableInserterPool.Get().(*tableInserter)
-			*ti = tableInserter{ri: ri}
-			tw := tableWriter(ti)
-			defer func() {
-				tw.close(ctx)
-				*ti = tableInserter{}
-				tableInserterPool.Put(ti)
-			}()
-			if err := tw.init(txn, localPlanner.EvalContext()); err != nil {
-				return err
-			}
+// backfillQueryIntoTable runs the given query in txn and inserts its results
+// into the table, whose last column must be the rowid column added by
+// ensurePrimaryKey().
+func (sc *SchemaChanger) backfillQueryIntoTable(
+	ctx context.Context,
+	table *sqlbase.TableDescriptor,
+	query string,
+	txn *client.Txn,
+	evalCtx *extendedEvalContext,
+) error {
+	// Create an internal planner as the planner used to serve the user query
+	// would have committed by this point.
+	p, cleanup := NewInternalPlanner("ctasBackfill", txn, security.RootUser, &MemoryMetrics{}, sc.execCfg)
+	defer cleanup()
+	localPlanner := p.(*planner)
+
+	colTypes := make([]types.T, len(table.VisibleColumns()))
+	for i, t := range table.VisibleColumns() {
+		colTypes[i] = t.Type
+	}
+	ci := sqlbase.ColTypeInfoFromColTypes(colTypes)
+	rows := rowcontainer.NewRowContainer(
+		localPlanner.EvalContext().Mon.MakeBoundAccount(), ci, 0,
+	)
+	defer rows.Close(ctx)
+
+	rw := NewRowResultWriter(rows)
+	if err := sc.planAndRunBackfillQuery(ctx, localPlanner, query, txn, rw, evalCtx); err != nil {
+		return err
+	}
 
-			// Prepare the buffer for row values. At this point, one more
-			// column has been added by ensurePrimaryKey() to the list of
-			// columns stored in table.Columns.
-			rowBuffer := make(tree.Datums, len(table.Columns))
-			pkColIdx := len(table.Columns) - 1
+	// This is a very simplified version of the INSERT logic: no CHECK
+	// expressions, no FK checks, no arbitrary insertion order, no
+	// RETURNING, etc.
 
-			// The optimizer includes the rowID expression as part of the input
-			// expression. But the heuristic planner does not do this, so construct a
-			// rowID expression to be evaluated separately.
-			//
-			// TODO(adityamaru): This could be redundant as it is only required when
-			// the heuristic planner is used, but currently there is no way of knowing
-			// this from the SchemaChanger.
-			var defTypedExpr tree.TypedExpr
-			// Prepare the rowID expression.
-			defExprSQL := *table.Columns[pkColIdx].DefaultExpr
-			defExpr, err := parser.ParseExpr(defExprSQL)
-			if err != nil {
-				return err
-			}
-			defTypedExpr, err = localPlanner.analyzeExpr(
-				ctx,
-				defExpr,
-				nil, /*sources*/
-				tree.IndexedVarHelper{},
-				types.Any,
-				false, /*requireType*/
-				"CREATE TABLE AS")
-			if err != nil {
-				return err
+	// Instantiate a row inserter and table writer.
+	ri, err := row.MakeInserter(
+		txn,
+		sqlbase.NewImmutableTableDescriptor(*table),
+		nil,
+		table.Columns,
+		row.SkipFKs,
+		localPlanner.EvalContext(),
+		&localPlanner.alloc)
+	if err != nil {
+		return err
+	}
+	ti := tableInserterPool.Get().(*tableInserter)
+	*ti = tableInserter{ri: ri}
+	tw := tableWriter(ti)
+	defer func() {
+		tw.close(ctx)
+		*ti = tableInserter{}
+		tableInserterPool.Put(ti)
+	}()
+	if err := tw.init(txn, localPlanner.EvalContext()); err != nil {
+		return err
+	}
+
+	// Prepare the buffer for row values. At this point, one more
+	// column has been added by ensurePrimaryKey() to the list of
+	// columns stored in table.Columns.
+	rowBuffer := make(tree.Datums, len(table.Columns))
+	pkColIdx := len(table.Columns) - 1
+
+	// The optimizer includes the rowID expression as part of the input
+	// expression. But the heuristic planner does not do this, so construct a
+	// rowID expression to be evaluated separately.
+	//
+	// TODO(adityamaru): This could be redundant as it is only required when
+	// the heuristic planner is used, but currently there is no way of knowing
+	// this from the SchemaChanger.
+	var defTypedExpr tree.TypedExpr
+	// Prepare the rowID expression.
+	defExprSQL := *table.Columns[pkColIdx].DefaultExpr
+	defExpr, err := parser.ParseExpr(defExprSQL)
+	if err != nil {
+		return err
+	}
+	defTypedExpr, err = localPlanner.analyzeExpr(
+		ctx,
+		defExpr,
+		nil, /*sources*/
+		tree.IndexedVarHelper{},
+		types.Any,
+		false, /*requireType*/
+		"CREATE TABLE AS")
+	if err != nil {
+		return err
+	}
+
+	for i := 0; i < rows.Len(); i++ {
+		copy(rowBuffer, rows.At(i))
+
+		rowBuffer[pkColIdx], err = defTypedExpr.Eval(localPlanner.EvalContext())
+		if err != nil {
+			return err
+		}
+
+		err := tw.row(ctx, rowBuffer, evalCtx.Tracing.KVTracingEnabled())
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tw.finalize(
+		ctx, evalCtx.Tracing.KVTracingEnabled())
+	if err != nil {
+		return err
+	}
+	return rw.Err()
+}
+
+// materializedViewBackfillQuery returns the query that produces the rows of
+// a materialized view starting with the row at position offset. The rows are
+// ordered on all of the columns of the view where possible, in which case
+// resumable is true: rows that compare equal are then indistinguishable, so
+// the same rows are returned at each position whenever the query is run as of
+// the same timestamp. Types whose values can compare equal without being
+// identical, such as decimals, do not allow this.
+func materializedViewBackfillQuery(
+	table *sqlbase.TableDescriptor, offset int64,
+) (query string, resumable bool) {
+	cols := table.VisibleColumns()
+	resumable = true
+	for i := range cols {
+		family := cols[i].Type.Family()
+		if sqlbase.MustBeValueEncoded(family) || sqlbase.HasCompositeKeyEncoding(family) {
+			resumable = false
+			break
+		}
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "SELECT * FROM (%s)", table.ViewQuery)
+	if resumable {
+		for i := range cols {
+			if i == 0 {
+				buf.WriteString(" ORDER BY ")
+			} else {
+				buf.WriteString(", ")
 			}
+			fmt.Fprintf(&buf, "%d", i+1)
+		}
+		if offset > 0 {
+			fmt.Fprintf(&buf, " OFFSET %d", offset)
+		}
+	}
+	return buf.String(), resumable
+}
 
-			for i := 0; i < rows.Len(); i++ {
-				copy(rowBuffer, rows.At(i))
+// materializedViewResumeSpan returns the span of the given primary index of a
+// materialized view that remains to be backfilled before the row at position
+// rowID is written. The rows are keyed by their position, see
+// backfill.MaterializedViewBackfiller.
+func materializedViewResumeSpan(
+	table *sqlbase.TableDescriptor, primaryIndex *sqlbase.IndexDescriptor, rowID int64,
+) roachpb.Span {
+	span := table.IndexSpan(primaryIndex.ID)
+	if rowID > 0 {
+		span.Key = encoding.EncodeVarintAscending(append(roachpb.Key(nil), span.Key...), rowID)
+	}
+	return span
+}
 
-				rowBuffer[pkColIdx], err = defTypedExpr.Eval(localPlanner.EvalContext())
-				if err != nil {
-					return err
-				}
+// materializedViewResumeRowID returns the position of the first row that
+// remains to be backfilled into the given primary index of a materialized view
+// according to the resume span.
+func materializedViewResumeRowID(
+	table *sqlbase.TableDescriptor, primaryIndex *sqlbase.IndexDescriptor, resume roachpb.Span,
+) (int64, error) {
+	prefix := table.IndexSpan(primaryIndex.ID).Key
+	if !bytes.HasPrefix(resume.Key, prefix) {
+		return 0, errors.AssertionFailedf(
+			"resume key %s is not in index %d", resume.Key, errors.Safe(primaryIndex.ID))
+	}
+	if len(resume.Key) == len(prefix) {
+		return 0, nil
+	}
+	_, rowID, err := encoding.DecodeVarintAscending(resume.Key[len(prefix):])
+	return rowID, err
+}
 
-				err := tw.row(ctx, rowBuffer, evalCtx.Tracing.KVTracingEnabled())
-				if err != nil {
+// backfillMaterializedView writes the results of the view query of a
+// materialized view, run as of asOf, into the given primary and secondary
+// indexes of the view, which must not be read until the backfill completes.
+//
+// The rows are written in chunks, each in its own transaction, starting with
+// the row at position resumeRowID. If checkpoint is set, it is called in the
+// transaction writing each chunk with the span of the primary index that
+// remains to be backfilled, which is empty once all of the rows have been
+// written. If the results of the view query cannot be ordered
+// deterministically, the backfill always starts over from the first row.
+func (sc *SchemaChanger) backfillMaterializedView(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	table *sqlbase.TableDescriptor,
+	primaryIndex *sqlbase.IndexDescriptor,
+	indexes []sqlbase.IndexDescriptor,
+	asOf hlc.Timestamp,
+	resumeRowID int64,
+	checkpoint func(ctx context.Context, txn *client.Txn, resume []roachpb.Span) error,
+	evalCtx *extendedEvalContext,
+) error {
+	var mb backfill.MaterializedViewBackfiller
+	if err := mb.Init(&evalCtx.EvalContext, table, primaryIndex, indexes); err != nil {
+		return err
+	}
+	chunkSize := sc.getChunkSize(materializedViewBackfillChunkSize)
+	traceKV := evalCtx.Tracing.KVTracingEnabled()
+
+	nextRowID := resumeRowID
+	chunk := make([]tree.Datums, 0, chunkSize)
+	writeChunk := func(ctx context.Context, last bool) error {
+		if lease != nil {
+			if err := sc.ExtendLease(ctx, lease); err != nil {
+				return err
+			}
+		}
+		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			if fn := sc.execCfg.DistSQLRunTestingKnobs.RunBeforeBackfillChunk; fn != nil {
+				if err := fn(materializedViewResumeSpan(table, primaryIndex, nextRowID)); err != nil {
 					return err
 				}
 			}
-
-			_, err = tw.finalize(
-				ctx, evalCtx.Tracing.KVTracingEnabled())
-			if err != nil {
+			if fn := sc.execCfg.DistSQLRunTestingKnobs.RunAfterBackfillChunk; fn != nil {
+				defer fn()
+			}
+			if err := mb.RunMaterializedViewBackfillChunk(ctx, txn, chunk, nextRowID, traceKV); err != nil {
 				return err
 			}
-			return rw.Err()
+			if checkpoint == nil {
+				return nil
+			}
+			var resume []roachpb.Span
+			if !last {
+				resume = append(resume,
+					materializedViewResumeSpan(table, primaryIndex, nextRowID+int64(len(chunk))))
+			}
+			return checkpoint(ctx, txn, resume)
 		}); err != nil {
 			return err
 		}
+		nextRowID += int64(len(chunk))
+		chunk = chunk[:0]
+		return nil
 	}
-	return nil
+
+	return sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		txn.SetFixedTimestamp(ctx, asOf)
+
+		// This transaction may be a retry, in which case the rows that have
+		// already been written are skipped if possible.
+		chunk = chunk[:0]
+		if _, resumable := materializedViewBackfillQuery(table, 0); !resumable || nextRowID == 0 {
+			nextRowID = 0
+			// Discard the rows written by an earlier attempt. ClearRange cannot be
+			// run in a transaction, so send it in a non-transactional batch.
+			b := &client.Batch{}
+			for _, idx := range append([]sqlbase.IndexDescriptor{*primaryIndex}, indexes...) {
+				span := table.IndexSpan(idx.ID)
+				b.AddRawRequest(&roachpb.ClearRangeRequest{
+					RequestHeader: roachpb.RequestHeader{
+						Key:    span.Key,
+						EndKey: span.EndKey,
+					},
+				})
+			}
+			if err := sc.db.Run(ctx, b); err != nil {
+				return err
+			}
+		}
+
+		query, _ := materializedViewBackfillQuery(table, nextRowID)
+		p, cleanup := NewInternalPlanner(
+			"materializedViewBackfill", txn, security.RootUser, &MemoryMetrics{}, sc.execCfg,
+		)
+		defer cleanup()
+		rw := newCallbackResultWriter(func(ctx context.Context, row tree.Datums) error {
+			chunk = append(chunk, append(tree.Datums(nil), row...))
+			if int64(len(chunk)) < chunkSize {
+				return nil
+			}
+			return writeChunk(ctx, false /* last */)
+		})
+		if err := sc.planAndRunBackfillQuery(ctx, p.(*planner), query, txn, rw, evalCtx); err != nil {
+			return err
+		}
+		return writeChunk(ctx, true /* last */)
+	})
+}
+
+// refreshMaterializedView replaces the contents of a materialized view with
+// the results of its view query as of the time of the refresh. The results
+// are backfilled into the new indexes of the refresh mutation, which replace
+// the existing indexes of the view when the mutation completes. The progress
+// of the backfill is checkpointed in the schema change job.
+func (sc *SchemaChanger) refreshMaterializedView(
+	ctx context.Context,
+	lease *sqlbase.TableDescriptor_SchemaChangeLease,
+	table *sqlbase.TableDescriptor,
+	refresh *sqlbase.MaterializedViewRefresh,
+	evalCtx *extendedEvalContext,
+) error {
+	var resumeSpans []roachpb.Span
+	if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		var err error
+		resumeSpans, _, _, err = distsqlrun.GetResumeSpans(
+			ctx, sc.jobRegistry, txn, sc.tableID, sc.mutationID,
+			backfill.MaterializedViewRefreshMutationFilter,
+		)
+		return err
+	}); err != nil {
+		return err
+	}
+	if len(resumeSpans) == 0 {
+		// The backfill has already completed.
+		return nil
+	}
+	resumeRowID, err := materializedViewResumeRowID(table, &refresh.NewPrimaryIndex, resumeSpans[0])
+	if err != nil {
+		return err
+	}
+	checkpoint := func(ctx context.Context, txn *client.Txn, resume []roachpb.Span) error {
+		_, job, mutationIdx, err := distsqlrun.GetResumeSpans(
+			ctx, sc.jobRegistry, txn, sc.tableID, sc.mutationID,
+			backfill.MaterializedViewRefreshMutationFilter,
+		)
+		if err != nil {
+			return err
+		}
+		return distsqlrun.SetResumeSpansInJob(ctx, resume, mutationIdx, txn, job)
+	}
+	return sc.backfillMaterializedView(
+		ctx, lease, table, &refresh.NewPrimaryIndex, refresh.NewIndexes, refresh.AsOf,
+		resumeRowID, checkpoint, evalCtx,
+	)
 }
 
 // maybe make a table PUBLIC if it's in the ADD state.
@@ -797,6 +1058,113 @@ func (sc *SchemaChanger) maybeMakeAddTablePublic(
 	return nil
 }
 
+// maybeMakeEnumMembersWritable makes the enum labels that are being added to
+// the types of the table's columns writable. A label is added to a type in a
+// read-only state, together with a new version of each table that uses the
+// type; the label can only be written once all the nodes have stopped using
+// the previous versions of those tables, because these cannot decode it.
+func (sc *SchemaChanger) maybeMakeEnumMembersWritable(
+	ctx context.Context, table *sqlbase.TableDescriptor,
+) error {
+	typeIDs := make(map[sqlbase.ID]struct{})
+	for i := range table.Columns {
+		typ := &table.Columns[i].Type
+		if typ.Family() != types.EnumFamily {
+			continue
+		}
+		for j := range typ.EnumLogicalRepresentations() {
+			if typ.EnumMemberIsReadOnly(j) {
+				typeIDs[sqlbase.ID(typ.StableTypeID())] = struct{}{}
+				break
+			}
+		}
+	}
+
+	for typeID := range typeIDs {
+		// Find the live tables that use the type. They have all been written
+		// with the type's current labels in the transaction that added them.
+		var tableIDs []sqlbase.ID
+		if err := sc.db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+			tableIDs = tableIDs[:0]
+			typeDesc := &sqlbase.TypeDescriptor{}
+			if err := getDescriptorByID(ctx, txn, typeID, typeDesc); err != nil {
+				return err
+			}
+			for _, id := range typeDesc.ReferencingDescriptorIDs {
+				tableDesc, err := sqlbase.GetTableDescFromID(ctx, txn, id)
+				if err != nil {
+					if err == sqlbase.ErrDescriptorNotFound {
+						continue
+					}
+					return err
+				}
+				if !tableDesc.Dropped() {
+					tableIDs = append(tableIDs, id)
+				}
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		// PublishMultiple waits for a single version of each of the tables
+		// before writing the next one, which guarantees that every node knows
+		// of the labels that are made writable.
+		var writable map[string]struct{}
+		if _, err := sc.leaseMgr.PublishMultiple(
+			ctx,
+			tableIDs,
+			func(descs map[sqlbase.ID]*sqlbase.MutableTableDescriptor) error {
+				writable = make(map[string]struct{})
+				update := func(col *sqlbase.ColumnDescriptor) {
+					if col.Type.Family() != types.EnumFamily ||
+						sqlbase.ID(col.Type.StableTypeID()) != typeID {
+						return
+					}
+					for i, label := range col.Type.EnumLogicalRepresentations() {
+						if col.Type.EnumMemberIsReadOnly(i) {
+							writable[label] = struct{}{}
+						}
+					}
+					col.Type = *col.Type.WithWritableEnumMembers()
+				}
+				for _, desc := range descs {
+					for i := range desc.Columns {
+						update(&desc.Columns[i])
+					}
+					for i := range desc.Mutations {
+						if col := desc.Mutations[i].GetColumn(); col != nil {
+							update(col)
+						}
+					}
+				}
+				if len(writable) == 0 {
+					return errDidntUpdateDescriptor
+				}
+				return nil
+			},
+			func(txn *client.Txn) error {
+				typeDesc := &sqlbase.TypeDescriptor{}
+				if err := getDescriptorByID(ctx, txn, typeID, typeDesc); err != nil {
+					return err
+				}
+				// Labels added since the tables were read remain read-only; they
+				// are made writable once the tables that were rewritten to add them
+				// have converged.
+				for i := range typeDesc.EnumMembers {
+					if _, ok := writable[typeDesc.EnumMembers[i].LogicalRepresentation]; ok {
+						typeDesc.EnumMembers[i].ReadOnly = false
+					}
+				}
+				return txn.Put(ctx, sqlbase.MakeDescMetadataKey(typeID), sqlbase.WrapDescriptor(typeDesc))
+			},
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (sc *SchemaChanger) maybeGCMutations(
 	ctx context.Context, inSession bool, table *sqlbase.TableDescriptor,
 ) error {
@@ -1030,6 +1398,10 @@ func (sc *SchemaChanger) exec(
 		return err
 	}
 
+	if err := sc.maybeMakeEnumMembersWritable(ctx, tableDesc); err != nil {
+		return err
+	}
+
 	// Wait for the schema change to propagate to all nodes after this function
 	// returns, so that the new schema is live everywhere. This is not needed for
 	// correctness but is done to make the UI experience/tests predictable.
@@ -1049,7 +1421,7 @@ func (sc *SchemaChanger) exec(
 
 	if sc.mutationID == sqlbase.InvalidMutationID {
 		// Nothing more to do.
-		isCreateTableAs := tableDesc.Adding() && tableDesc.IsAs()
+		isCreateTableAs := tableDesc.Adding() && (tableDesc.IsAs() || tableDesc.MaterializedView())
 		waitToUpdateLeases(isCreateTableAs /* refreshStats */)
 		return nil
 	}
@@ -1315,6 +1687,24 @@ func (sc *SchemaChanger) done(ctx context.Context) (*sqlbase.ImmutableTableDescr
 				break
 			}
 			isRollback = mutation.Rollback
+			if refresh := mutation.GetMaterializedViewRefresh(); refresh != nil {
+				// The indexes that are no longer used are the old indexes of the view
+				// if the refresh succeeded, or the new ones if it was rolled back.
+				unused := append([]sqlbase.IndexDescriptor{desc.PrimaryIndex}, desc.Indexes...)
+				if mutation.Direction == sqlbase.DescriptorMutation_DROP {
+					unused = append([]sqlbase.IndexDescriptor{refresh.NewPrimaryIndex}, refresh.NewIndexes...)
+				}
+				jobSucceeded = false
+				for i := range unused {
+					desc.GCMutations = append(
+						desc.GCMutations,
+						sqlbase.TableDescriptor_GCDescriptorMutation{
+							IndexID:  unused[i].ID,
+							DropTime: now,
+							JobID:    *sc.job.ID(),
+						})
+				}
+			}
 			if indexDesc := mutation.GetIndex(); mutation.Direction == sqlbase.DescriptorMutation_DROP &&
 				indexDesc != nil {
 				if sc.canClearRangeForDrop(indexDesc) {
@@ -1381,6 +1381,78 @@ CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
 	dropIndexSchemaChange(t, sqlDB, kvDB, maxValue, 2)
 }
 
+// Test that a materialized view refresh that fails part way through is resumed
+// from its last checkpoint and produces the complete contents of the view.
+func TestMaterializedViewRefreshRetry(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	params, _ := tests.CreateTestServerParams()
+
+	var enabled int32
+	currChunk := 0
+	seenSpan := roachpb.Span{}
+	checkSpan := func(sp roachpb.Span) error {
+		if atomic.LoadInt32(&enabled) == 0 {
+			return nil
+		}
+		currChunk++
+		// Fail somewhere in the middle.
+		if currChunk == 3 {
+			return context.DeadlineExceeded
+		}
+		if seenSpan.Key != nil {
+			// Check that no chunk is written twice.
+			if seenSpan.Key.Compare(sp.Key) >= 0 {
+				t.Errorf("reprocessing span %s, already seen span %s", sp, seenSpan)
+			}
+			if !seenSpan.EndKey.Equal(sp.EndKey) {
+				t.Errorf("different EndKey: span %s, already seen span %s", sp, seenSpan)
+			}
+		}
+		seenSpan = sp
+		return nil
+	}
+
+	params.Knobs = base.TestingKnobs{
+		SQLSchemaChanger: &sql.SchemaChangerTestingKnobs{
+			// Disable asynchronous schema change execution to allow
+			// synchronous path to run schema changes.
+			AsyncExecNotification: asyncSchemaChangerDisabled,
+			BackfillChunkSize:     100,
+		},
+		DistSQL: &distsqlrun.TestingKnobs{RunBeforeBackfillChunk: checkSpan},
+	}
+	s, sqlDB, _ := serverutils.StartServer(t, params)
+	defer s.Stopper().Stop(context.TODO())
+
+	if _, err := sqlDB.Exec(`
+CREATE DATABASE t;
+CREATE TABLE t.test (k INT PRIMARY KEY, v INT);
+INSERT INTO t.test SELECT x, -x FROM generate_series(1, 1000) AS g(x);
+CREATE MATERIALIZED VIEW t.mv AS SELECT k, v FROM t.test;
+INSERT INTO t.test SELECT x, -x FROM generate_series(1001, 2000) AS g(x);
+`); err != nil {
+		t.Fatal(err)
+	}
+
+	atomic.StoreInt32(&enabled, 1)
+	if _, err := sqlDB.Exec(`REFRESH MATERIALIZED VIEW t.mv`); err != nil {
+		t.Fatal(err)
+	}
+	if currChunk <= 3 {
+		t.Fatalf("expected the refresh to be resumed, but only %d chunks were written", currChunk)
+	}
+
+	var count, distinct int
+	if err := sqlDB.QueryRow(
+		`SELECT count(*), count(DISTINCT (k, v)) FROM t.mv`,
+	).Scan(&count, &distinct); err != nil {
+		t.Fatal(err)
+	}
+	if count != 2000 || distinct != 2000 {
+		t.Fatalf("expected 2000 distinct rows, got %d rows of which %d distinct", count, distinct)
+	}
+}
+
 // Test schema changes are retried and complete properly when the table
 // version changes. This also checks that a mutation checkpoint reduces
 // the number of chunks operated on during a retry.
@@ -1739,6 +1739,33 @@ return without an error.`,
 		},
 	),
 
+	tree.WithMaxStalenessFunctionName: makeBuiltin(
+		tree.FunctionProperties{Impure: true},
+		tree.Overload{
+			Types:      tree.ArgTypes{{"max_staleness", types.Interval}},
+			ReturnType: tree.FixedReturnType(types.TimestampTZ),
+			Fn: func(ctx *tree.EvalContext, args tree.Datums) (tree.Datum, error) {
+				ts, err := boundedStalenessTimestamp(ctx, args[0].(*tree.DInterval).Duration)
+				if err != nil {
+					return nil, err
+				}
+				return tree.MakeDTimestampTZ(ts, time.Microsecond), nil
+			},
+			Info: `Returns the oldest timestamp that a bounded-staleness read, which
+must not observe data older than max_staleness, may be performed at.
+
+This function is intended to be used with an AS OF SYSTEM TIME clause. The read
+is then performed at the most recent timestamp, no older than the one returned,
+that the closest replica of each range it touches can serve without
+redirecting it to the leaseholder. The timestamp is negotiated with those
+replicas before the read starts.
+
+Unlike ` + tree.FollowerReadTimestampFunctionName + `, this function does not
+require an enterprise license. It can only be used in single-statement implicit
+transactions.`,
+		},
+	),
+
 	"cluster_logical_timestamp": makeBuiltin(
 		tree.FunctionProperties{
 			Category: categorySystemInfo,
@@ -4633,6 +4660,20 @@ func checkPrivilegedUser(ctx *tree.EvalContext) error {
 // if an enterprise license is not installed.
 var EvalFollowerReadOffset func(clusterID uuid.UUID, _ *cluster.Settings) (time.Duration, error)
 
+// boundedStalenessTimestamp returns the oldest timestamp that a
+// bounded-staleness read, which must not observe data older than maxStaleness,
+// may be performed at. The read itself is performed at a more recent timestamp
+// negotiated with the replicas it touches once it has been planned.
+func boundedStalenessTimestamp(
+	ctx *tree.EvalContext, maxStaleness duration.Duration,
+) (time.Time, error) {
+	if maxStaleness.Compare(duration.Duration{}) <= 0 {
+		return time.Time{}, pgerror.New(pgcode.InvalidParameterValue,
+			"max_staleness must be a positive interval")
+	}
+	return duration.Add(ctx, ctx.StmtTimestamp, maxStaleness.Mul(-1)), nil
+}
+
 func recentTimestamp(ctx *tree.EvalContext) (time.Time, error) {
 	if EvalFollowerReadOffset == nil {
 		return time.Time{}, pgerror.New(pgcode.FeatureNotSupported,
@@ -12,13 +12,16 @@ package builtins
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
 )
 
 func TestCategory(t *testing.T) {
@@ -234,3 +237,43 @@ func TestLPadRPad(t *testing.T) {
 		}
 	}
 }
+
+// TestBoundedStalenessTimestamp verifies that bounded-staleness reads may be
+// performed as far back as their staleness bound allows, and that the bound
+// must be positive.
+func TestBoundedStalenessTimestamp(t *testing.T) {
+	st := cluster.MakeTestingClusterSettings()
+	ctx := tree.NewTestingEvalContext(st)
+	defer ctx.Stop(context.Background())
+	ctx.StmtTimestamp = time.Date(2019, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		maxStaleness string
+		expected     time.Duration
+		expectedErr  string
+	}{
+		{maxStaleness: "1h", expected: time.Hour},
+		{maxStaleness: "48s", expected: 48 * time.Second},
+		{maxStaleness: "1us", expected: time.Microsecond},
+		{maxStaleness: "0s", expectedErr: "max_staleness must be a positive interval"},
+		{maxStaleness: "-1s", expectedErr: "max_staleness must be a positive interval"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.maxStaleness, func(t *testing.T) {
+			d, err := tree.ParseDInterval(tc.maxStaleness)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ts, err := boundedStalenessTimestamp(ctx, d.Duration)
+			if !testutils.IsError(err, tc.expectedErr) {
+				t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if lag := ctx.StmtTimestamp.Sub(ts); lag != tc.expected {
+				t.Errorf("expected a lag of %s, got %s", tc.expected, lag)
+			}
+		})
+	}
+}
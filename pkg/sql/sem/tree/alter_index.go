@@ -51,8 +51,12 @@ type AlterIndexCmd interface {
 }
 
 func (*AlterIndexPartitionBy) alterIndexCmd() {}
+func (*AlterIndexSetTTL) alterIndexCmd()      {}
+func (*AlterIndexResetTTL) alterIndexCmd()    {}
 
 var _ AlterIndexCmd = &AlterIndexPartitionBy{}
+var _ AlterIndexCmd = &AlterIndexSetTTL{}
+var _ AlterIndexCmd = &AlterIndexResetTTL{}
 
 // AlterIndexPartitionBy represents an ALTER INDEX PARTITION BY
 // command.
@@ -64,3 +68,22 @@ type AlterIndexPartitionBy struct {
 func (node *AlterIndexPartitionBy) Format(ctx *FmtCtx) {
 	ctx.FormatNode(node.PartitionBy)
 }
+
+// AlterIndexSetTTL represents an ALTER INDEX SET TTL command.
+type AlterIndexSetTTL struct {
+	TTL Expr
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterIndexSetTTL) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET TTL ")
+	ctx.FormatNode(node.TTL)
+}
+
+// AlterIndexResetTTL represents an ALTER INDEX RESET TTL command.
+type AlterIndexResetTTL struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterIndexResetTTL) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RESET TTL")
+}
@@ -66,6 +66,8 @@ func (*AlterTableSetDefault) alterTableCmd()         {}
 func (*AlterTableValidateConstraint) alterTableCmd() {}
 func (*AlterTablePartitionBy) alterTableCmd()        {}
 func (*AlterTableInjectStats) alterTableCmd()        {}
+func (*AlterTableSetTTL) alterTableCmd()             {}
+func (*AlterTableResetTTL) alterTableCmd()           {}
 
 var _ AlterTableCmd = &AlterTableAddColumn{}
 var _ AlterTableCmd = &AlterTableAddConstraint{}
@@ -83,6 +85,8 @@ var _ AlterTableCmd = &AlterTableSetDefault{}
 var _ AlterTableCmd = &AlterTableValidateConstraint{}
 var _ AlterTableCmd = &AlterTablePartitionBy{}
 var _ AlterTableCmd = &AlterTableInjectStats{}
+var _ AlterTableCmd = &AlterTableSetTTL{}
+var _ AlterTableCmd = &AlterTableResetTTL{}
 
 // ColumnMutationCmd is the subset of AlterTableCmds that modify an
 // existing column.
@@ -422,3 +426,25 @@ func (node *AlterTableInjectStats) Format(ctx *FmtCtx) {
 	ctx.WriteString(" INJECT STATISTICS ")
 	ctx.FormatNode(node.Stats)
 }
+
+// AlterTableSetTTL represents an ALTER TABLE SET TTL command.
+type AlterTableSetTTL struct {
+	TTL    Expr
+	Column Name
+}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableSetTTL) Format(ctx *FmtCtx) {
+	ctx.WriteString(" SET TTL ")
+	ctx.FormatNode(node.TTL)
+	ctx.WriteString(" ON ")
+	ctx.FormatNode(&node.Column)
+}
+
+// AlterTableResetTTL represents an ALTER TABLE RESET TTL command.
+type AlterTableResetTTL struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *AlterTableResetTTL) Format(ctx *FmtCtx) {
+	ctx.WriteString(" RESET TTL")
+}
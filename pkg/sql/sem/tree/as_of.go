@@ -18,6 +18,7 @@ import (
 	"github.com/cockroachdb/apd"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
 	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/duration"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
@@ -29,8 +30,25 @@ import (
 // reads.
 const FollowerReadTimestampFunctionName = "experimental_follower_read_timestamp"
 
-var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions or " +
-	FollowerReadTimestampFunctionName + " are allowed")
+// WithMaxStalenessFunctionName is the name of the function which can be used
+// with AOST clauses to perform a bounded-staleness read: the read is performed
+// at the most recent timestamp within the given staleness bound that the
+// nearest replica of every range it touches can serve.
+const WithMaxStalenessFunctionName = "with_max_staleness"
+
+var errInvalidExprForAsOf = errors.Errorf("AS OF SYSTEM TIME: only constant expressions, " +
+	FollowerReadTimestampFunctionName + " or " + WithMaxStalenessFunctionName + " are allowed")
+
+// IsBoundedStalenessAsOf returns whether the AS OF SYSTEM TIME clause performs
+// a bounded-staleness read with the WithMaxStalenessFunctionName function.
+func IsBoundedStalenessAsOf(asOf AsOfClause, searchPath sessiondata.SearchPath) bool {
+	fe, ok := asOf.Expr.(*FuncExpr)
+	if !ok {
+		return false
+	}
+	def, err := fe.Func.Resolve(searchPath)
+	return err == nil && def.Name == WithMaxStalenessFunctionName
+}
 
 // EvalAsOfTimestamp evaluates the timestamp argument to an AS OF SYSTEM TIME query.
 func EvalAsOfTimestamp(
@@ -44,22 +62,29 @@ func EvalAsOfTimestamp(
 	scalarProps.Require("AS OF SYSTEM TIME", RejectSpecial|RejectSubqueries)
 
 	// In order to support the follower reads feature we permit this expression
-	// to be a simple invocation of the `FollowerReadTimestampFunction`.
-	// Over time we could expand the set of allowed functions or expressions.
-	// All non-function expressions must be const and must TypeCheck into a
-	// string.
+	// to be a simple invocation of the `FollowerReadTimestampFunction` or, for
+	// bounded-staleness reads, of the `WithMaxStalenessFunction` with constant
+	// arguments. Over time we could expand the set of allowed functions or
+	// expressions. All non-function expressions must be const and must
+	// TypeCheck into a string.
 	var te TypedExpr
 	if fe, ok := asOf.Expr.(*FuncExpr); ok {
 		def, err := fe.Func.Resolve(semaCtx.SearchPath)
 		if err != nil {
 			return hlc.Timestamp{}, errInvalidExprForAsOf
 		}
-		if def.Name != FollowerReadTimestampFunctionName {
+		if def.Name != FollowerReadTimestampFunctionName &&
+			def.Name != WithMaxStalenessFunctionName {
 			return hlc.Timestamp{}, errInvalidExprForAsOf
 		}
 		if te, err = fe.TypeCheck(semaCtx, types.TimestampTZ); err != nil {
 			return hlc.Timestamp{}, err
 		}
+		for _, arg := range te.(*FuncExpr).Exprs {
+			if !IsConst(evalCtx, arg) {
+				return hlc.Timestamp{}, errInvalidExprForAsOf
+			}
+		}
 	} else {
 		var err error
 		te, err = asOf.Expr.TypeCheck(semaCtx, types.String)
@@ -41,6 +41,45 @@ func (node *Backup) Format(ctx *FmtCtx) {
 	}
 }
 
+// ScheduledBackup represents a CREATE SCHEDULE FOR BACKUP statement.
+type ScheduledBackup struct {
+	ScheduleName  Expr
+	Targets       TargetList
+	To            Expr
+	BackupOptions KVOptions
+	Recurrence    Expr
+	// FullBackup is the recurrence of full backups, or nil if every backup is
+	// a full backup.
+	FullBackup      Expr
+	ScheduleOptions KVOptions
+}
+
+var _ Statement = &ScheduledBackup{}
+
+// Format implements the NodeFormatter interface.
+func (node *ScheduledBackup) Format(ctx *FmtCtx) {
+	ctx.WriteString("CREATE SCHEDULE ")
+	ctx.FormatNode(node.ScheduleName)
+	ctx.WriteString(" FOR BACKUP ")
+	ctx.FormatNode(&node.Targets)
+	ctx.WriteString(" INTO ")
+	ctx.FormatNode(node.To)
+	if node.BackupOptions != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.BackupOptions)
+	}
+	ctx.WriteString(" RECURRING ")
+	ctx.FormatNode(node.Recurrence)
+	if node.FullBackup != nil {
+		ctx.WriteString(" FULL BACKUP ")
+		ctx.FormatNode(node.FullBackup)
+	}
+	if node.ScheduleOptions != nil {
+		ctx.WriteString(" WITH SCHEDULE OPTIONS ")
+		ctx.FormatNode(&node.ScheduleOptions)
+	}
+}
+
 // Restore represents a RESTORE statement.
 type Restore struct {
 	Targets TargetList
@@ -1460,6 +1460,161 @@ func (d *DUuid) Size() uintptr {
 	return unsafe.Sizeof(*d)
 }
 
+// DEnum is the Datum for a value of a user-defined enum type. Enum values are
+// compared using their physical representations, which sort in the order in
+// which the enum's labels were declared.
+type DEnum struct {
+	// EnumTyp is the enum type that this value belongs to.
+	EnumTyp *types.T
+	// PhysicalRep is the encoded form of the value, which is used to store it
+	// and to compare it with other values of the same type.
+	PhysicalRep []byte
+	// LogicalRep is the label of the value.
+	LogicalRep string
+}
+
+// MakeDEnumFromPhysicalRepresentation creates a DEnum of the given type from
+// the encoded form of one of its labels.
+func MakeDEnumFromPhysicalRepresentation(typ *types.T, rep []byte) (*DEnum, error) {
+	for i, b := range typ.EnumPhysicalRepresentations() {
+		if bytes.Equal(b, rep) {
+			return &DEnum{
+				EnumTyp:     typ,
+				PhysicalRep: b,
+				LogicalRep:  typ.EnumLogicalRepresentations()[i],
+			}, nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.InvalidParameterValue,
+		"could not find %x in enum %s representation", rep, typ.SQLString())
+}
+
+// MakeDEnumFromLogicalRepresentation creates a DEnum of the given type from
+// one of its labels. Labels that are still being added to the type cannot be
+// used yet.
+func MakeDEnumFromLogicalRepresentation(typ *types.T, rep string) (*DEnum, error) {
+	for i, l := range typ.EnumLogicalRepresentations() {
+		if l == rep {
+			if typ.EnumMemberIsReadOnly(i) {
+				return nil, pgerror.Newf(pgcode.ObjectNotInPrerequisiteState,
+					"enum label %q is being added, try again later", rep)
+			}
+			return &DEnum{
+				EnumTyp:     typ,
+				PhysicalRep: typ.EnumPhysicalRepresentations()[i],
+				LogicalRep:  l,
+			}, nil
+		}
+	}
+	return nil, pgerror.Newf(pgcode.InvalidTextRepresentation,
+		"invalid input value for enum %s: %q", typ.SQLString(), rep)
+}
+
+// ParseDEnum parses a label of the given enum type into a DEnum.
+func ParseDEnum(typ *types.T, s string) (*DEnum, error) {
+	return MakeDEnumFromLogicalRepresentation(typ, s)
+}
+
+// ResolvedType implements the TypedExpr interface.
+func (d *DEnum) ResolvedType() *types.T {
+	return d.EnumTyp
+}
+
+// Compare implements the Datum interface.
+func (d *DEnum) Compare(ctx *EvalContext, other Datum) int {
+	if other == DNull {
+		// NULL is less than any non-NULL value.
+		return 1
+	}
+	v, ok := UnwrapDatum(ctx, other).(*DEnum)
+	if !ok || v.EnumTyp.Oid() != d.EnumTyp.Oid() {
+		panic(makeUnsupportedComparisonMessage(d, other))
+	}
+	return bytes.Compare(d.PhysicalRep, v.PhysicalRep)
+}
+
+// position returns the index of the value's label in the enum type.
+func (d *DEnum) position() int {
+	for i, b := range d.EnumTyp.EnumPhysicalRepresentations() {
+		if bytes.Equal(b, d.PhysicalRep) {
+			return i
+		}
+	}
+	panic(errors.AssertionFailedf("enum value %q not found in type %s", d.LogicalRep, d.EnumTyp))
+}
+
+// enumValueAt returns the value of the given enum type at the given position.
+func enumValueAt(typ *types.T, idx int) *DEnum {
+	return &DEnum{
+		EnumTyp:     typ,
+		PhysicalRep: typ.EnumPhysicalRepresentations()[idx],
+		LogicalRep:  typ.EnumLogicalRepresentations()[idx],
+	}
+}
+
+// Prev implements the Datum interface.
+func (d *DEnum) Prev(_ *EvalContext) (Datum, bool) {
+	idx := d.position()
+	if idx == 0 {
+		return nil, false
+	}
+	return enumValueAt(d.EnumTyp, idx-1), true
+}
+
+// Next implements the Datum interface.
+func (d *DEnum) Next(_ *EvalContext) (Datum, bool) {
+	idx := d.position()
+	if idx == len(d.EnumTyp.EnumPhysicalRepresentations())-1 {
+		return nil, false
+	}
+	return enumValueAt(d.EnumTyp, idx+1), true
+}
+
+// IsMax implements the Datum interface.
+func (d *DEnum) IsMax(_ *EvalContext) bool {
+	return d.position() == len(d.EnumTyp.EnumPhysicalRepresentations())-1
+}
+
+// IsMin implements the Datum interface.
+func (d *DEnum) IsMin(_ *EvalContext) bool {
+	return d.position() == 0
+}
+
+// Min implements the Datum interface.
+func (d *DEnum) Min(_ *EvalContext) (Datum, bool) {
+	if len(d.EnumTyp.EnumPhysicalRepresentations()) == 0 {
+		return nil, false
+	}
+	return enumValueAt(d.EnumTyp, 0), true
+}
+
+// Max implements the Datum interface.
+func (d *DEnum) Max(_ *EvalContext) (Datum, bool) {
+	n := len(d.EnumTyp.EnumPhysicalRepresentations())
+	if n == 0 {
+		return nil, false
+	}
+	return enumValueAt(d.EnumTyp, n-1), true
+}
+
+// AmbiguousFormat implements the Datum interface.
+func (*DEnum) AmbiguousFormat() bool { return true }
+
+// Format implements the NodeFormatter interface.
+func (d *DEnum) Format(ctx *FmtCtx) {
+	buf, f := &ctx.Buffer, ctx.flags
+	if f.HasFlags(fmtRawStrings) {
+		buf.WriteString(d.LogicalRep)
+	} else {
+		lex.EncodeSQLStringWithFlags(buf, d.LogicalRep, f.EncodeFlags())
+	}
+}
+
+// Size implements the Datum interface.
+func (d *DEnum) Size() uintptr {
+	return unsafe.Sizeof(*d) + uintptr(len(d.PhysicalRep)) + uintptr(len(d.LogicalRep))
+}
+
 // DIPAddr is the IPAddr Datum.
 type DIPAddr struct {
 	ipaddr.IPAddr
@@ -1988,10 +2143,15 @@ func compareTimestamps(ctx *EvalContext, l Datum, r Datum) int {
 	if !lOk || !rOk {
 		panic(makeUnsupportedComparisonMessage(l, r))
 	}
-	if lTime.Before(rTime) {
+	return CompareTimes(lTime, rTime)
+}
+
+// CompareTimes compares the input times according to the SQL comparison rules.
+func CompareTimes(l, r time.Time) int {
+	if l.Before(r) {
 		return -1
 	}
-	if rTime.Before(lTime) {
+	if r.Before(l) {
 		return 1
 	}
 	return 0
@@ -2489,6 +2649,8 @@ func AsJSON(d Datum) (json.JSON, error) {
 		return json.FromString(t.UTC().Format("2006-01-02T15:04:05.999999999")), nil
 	case *DDate, *DUuid, *DOid, *DInterval, *DBytes, *DIPAddr, *DTime, *DBitArray:
 		return json.FromString(AsStringWithFlags(t, FmtBareStrings)), nil
+	case *DEnum:
+		return json.FromString(t.LogicalRep), nil
 	default:
 		if d == DNull {
 			return json.NullJSONValue, nil
@@ -3595,6 +3757,7 @@ var baseDatumTypeSizes = map[types.Family]struct {
 	types.IntervalFamily:       {unsafe.Sizeof(DInterval{}), fixedSize},
 	types.JsonFamily:           {unsafe.Sizeof(DJSON{}), variableSize},
 	types.UuidFamily:           {unsafe.Sizeof(DUuid{}), fixedSize},
+	types.EnumFamily:           {unsafe.Sizeof(DEnum{}), variableSize},
 	types.INetFamily:           {unsafe.Sizeof(DIPAddr{}), fixedSize},
 	types.OidFamily:            {unsafe.Sizeof(DInt(0)), fixedSize},
 
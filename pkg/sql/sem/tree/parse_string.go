@@ -90,6 +90,8 @@ func parseStringAs(t *types.T, s string, ctx ParseTimeContext) (Datum, error) {
 		return ParseDTimestampTZ(ctx, s, time.Microsecond)
 	case types.UuidFamily:
 		return ParseDUuidFromString(s)
+	case types.EnumFamily:
+		return ParseDEnum(t, s)
 	default:
 		return nil, nil
 	}
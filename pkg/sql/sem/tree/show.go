@@ -77,6 +77,7 @@ const (
 type ShowBackup struct {
 	Path    Expr
 	Details BackupDetails
+	Options KVOptions
 }
 
 // Format implements the NodeFormatter interface.
@@ -88,6 +89,10 @@ func (node *ShowBackup) Format(ctx *FmtCtx) {
 		ctx.WriteString("FILES ")
 	}
 	ctx.FormatNode(node.Path)
+	if node.Options != nil {
+		ctx.WriteString(" WITH ")
+		ctx.FormatNode(&node.Options)
+	}
 }
 
 // ShowColumns represents a SHOW COLUMNS statement.
@@ -204,6 +209,14 @@ func (node *ShowJobs) Format(ctx *FmtCtx) {
 	ctx.WriteString("JOBS")
 }
 
+// ShowSchedules represents a SHOW SCHEDULES statement.
+type ShowSchedules struct{}
+
+// Format implements the NodeFormatter interface.
+func (node *ShowSchedules) Format(ctx *FmtCtx) {
+	ctx.WriteString("SHOW SCHEDULES")
+}
+
 // ShowSessions represents a SHOW SESSIONS statement
 type ShowSessions struct {
 	All     bool
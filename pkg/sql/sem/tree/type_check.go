@@ -59,9 +59,35 @@ type SemaContext struct {
 	// globally for the entire txn and this field would not be needed.
 	AsOfTimestamp *hlc.Timestamp
 
+	// TypeResolver is used to resolve references to user-defined types. If
+	// it is nil, expressions that reference user-defined types fail to type
+	// check.
+	TypeResolver TypeReferenceResolver
+
 	Properties SemaProperties
 }
 
+// TypeReferenceResolver resolves the names of user-defined types to the types
+// that they refer to.
+type TypeReferenceResolver interface {
+	// ResolveType returns the user-defined type with the given name.
+	ResolveType(name string) (*types.T, error)
+}
+
+// ResolveTypeReference returns the given type if it is fully resolved.
+// Otherwise, it is a reference to a user-defined type (see
+// types.MakeUserDefinedTypeReference), which is resolved using the context's
+// TypeResolver. The receiver may be nil.
+func (sc *SemaContext) ResolveTypeReference(typ *types.T) (*types.T, error) {
+	if !typ.IsUserDefinedTypeReference() {
+		return typ, nil
+	}
+	if sc == nil || sc.TypeResolver == nil {
+		return nil, pgerror.Newf(pgcode.UndefinedObject, "type %q does not exist", typ.Name())
+	}
+	return sc.TypeResolver.ResolveType(typ.Name())
+}
+
 // SemaProperties is a holder for required and derived properties
 // during semantic analysis. It provides scoping semantics via its
 // Restore() method, see below.
@@ -443,6 +469,12 @@ func isEmptyArray(expr Expr) bool {
 
 // TypeCheck implements the Expr interface.
 func (expr *CastExpr) TypeCheck(ctx *SemaContext, _ *types.T) (TypedExpr, error) {
+	typ, err := ctx.ResolveTypeReference(expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	expr.Type = typ
+
 	// The desired type provided to a CastExpr is ignored. Instead,
 	// types.Any is passed to the child of the cast. There are two
 	// exceptions, described below.
@@ -530,6 +562,12 @@ func (expr *IndirectionExpr) TypeCheck(ctx *SemaContext, desired *types.T) (Type
 
 // TypeCheck implements the Expr interface.
 func (expr *AnnotateTypeExpr) TypeCheck(ctx *SemaContext, desired *types.T) (TypedExpr, error) {
+	typ, err := ctx.ResolveTypeReference(expr.Type)
+	if err != nil {
+		return nil, err
+	}
+	expr.Type = typ
+
 	subExpr, err := typeCheckAndRequire(ctx, expr.Expr, expr.Type,
 		fmt.Sprintf("type annotation for %v as %s, found", expr.Expr, expr.Type))
 	if err != nil {
@@ -1358,6 +1396,10 @@ func (d *DBytes) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { retu
 // identity function for Datum.
 func (d *DUuid) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { return d, nil }
 
+// TypeCheck implements the Expr interface. It is implemented as an idempotent
+// identity function for Datum.
+func (d *DEnum) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { return d, nil }
+
 // TypeCheck implements the Expr interface. It is implemented as an idempotent
 // identity function for Datum.
 func (d *DIPAddr) TypeCheck(_ *SemaContext, _ *types.T) (TypedExpr, error) { return d, nil }
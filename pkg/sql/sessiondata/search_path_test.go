@@ -57,6 +57,46 @@ func TestImpliedSearchPath(t *testing.T) {
 	}
 }
 
+func TestSearchPathWithTemporarySchema(t *testing.T) {
+	testCases := []struct {
+		explicitSearchPath                         []string
+		tempSchemaName                             string
+		expectedSearchPath                         []string
+		expectedSearchPathWithoutImplicitPgCatalog []string
+	}{
+		{[]string{`foobar`}, ``, []string{`pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`, `pg_temp`}, ``, []string{`pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`}, `pg_temp_1_2`,
+			[]string{`pg_temp_1_2`, `pg_catalog`, `foobar`}, []string{`foobar`}},
+		{[]string{`foobar`, `pg_temp`}, `pg_temp_1_2`,
+			[]string{`pg_catalog`, `foobar`, `pg_temp_1_2`}, []string{`foobar`, `pg_temp_1_2`}},
+		{[]string{`pg_catalog`, `pg_temp`, `foobar`}, `pg_temp_1_2`,
+			[]string{`pg_catalog`, `pg_temp_1_2`, `foobar`}, []string{`pg_catalog`, `pg_temp_1_2`, `foobar`}},
+	}
+
+	for _, tc := range testCases {
+		name := strings.Join(tc.explicitSearchPath, ",") + "/" + tc.tempSchemaName
+		searchPath := MakeSearchPath(tc.explicitSearchPath).WithTemporarySchemaName(tc.tempSchemaName)
+		t.Run(name, func(t *testing.T) {
+			actualSearchPath := make([]string, 0)
+			iter := searchPath.Iter()
+			for p, ok := iter.Next(); ok; p, ok = iter.Next() {
+				actualSearchPath = append(actualSearchPath, p)
+			}
+			assert.Equal(t, tc.expectedSearchPath, actualSearchPath)
+		})
+
+		t.Run(name+"/no-pg-catalog", func(t *testing.T) {
+			actualSearchPath := make([]string, 0)
+			iter := searchPath.IterWithoutImplicitPGCatalog()
+			for p, ok := iter.Next(); ok; p, ok = iter.Next() {
+				actualSearchPath = append(actualSearchPath, p)
+			}
+			assert.Equal(t, tc.expectedSearchPathWithoutImplicitPgCatalog, actualSearchPath)
+		})
+	}
+}
+
 func TestSearchPathEquals(t *testing.T) {
 	a1 := MakeSearchPath([]string{"x", "y", "z"})
 	a2 := MakeSearchPath([]string{"x", "y", "z"})
@@ -76,4 +116,7 @@ func TestSearchPathEquals(t *testing.T) {
 
 	d := MakeSearchPath([]string{"x"})
 	assert.False(t, a1.Equals(&d))
+
+	e := a1.WithTemporarySchemaName("pg_temp_1_2")
+	assert.False(t, a1.Equals(&e))
 }
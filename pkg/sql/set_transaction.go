@@ -20,6 +20,9 @@ import (
 func (p *planner) SetTransaction(n *tree.SetTransaction) (planNode, error) {
 	var asOfTs hlc.Timestamp
 	if n.Modes.AsOf.Expr != nil {
+		if tree.IsBoundedStalenessAsOf(n.Modes.AsOf, p.semaCtx.SearchPath) {
+			return nil, errBoundedStalenessInExplicitTxn
+		}
 		var err error
 		asOfTs, err = p.EvalAsOfTimestamp(n.Modes.AsOf)
 		if err != nil {
@@ -805,17 +805,30 @@ func EncodeSecondaryIndex(
 // maps ColumnIDs to indices in `values`. secondaryIndexEntries is the return
 // value (passed as a parameter so the caller can reuse between rows) and is
 // expected to be the same length as indexes.
+//
+// preds determines which rows are included in partial indexes; it can be nil
+// if none of the indexes are partial. The entry of a partial index that does
+// not include the row is left empty (with a nil key).
 func EncodeSecondaryIndexes(
 	tableDesc *TableDescriptor,
 	indexes []IndexDescriptor,
 	colMap map[ColumnID]int,
 	values []tree.Datum,
+	preds *PartialIndexPredicates,
 	secondaryIndexEntries []IndexEntry,
 ) ([]IndexEntry, error) {
 	if len(secondaryIndexEntries) != len(indexes) {
 		panic("Length of secondaryIndexEntries is not equal to the number of indexes.")
 	}
 	for i := range indexes {
+		included, err := preds.Includes(&indexes[i], colMap, values)
+		if err != nil {
+			return secondaryIndexEntries, err
+		}
+		if !included {
+			secondaryIndexEntries[i] = IndexEntry{}
+			continue
+		}
 		entries, err := EncodeSecondaryIndex(tableDesc, &indexes[i], colMap, values)
 		if err != nil {
 			return secondaryIndexEntries, err
@@ -0,0 +1,61 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sqlbase
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+)
+
+// ToScanLockingStrength converts a tree.LockingStrength to its corresponding
+// ScanLockingStrength.
+func ToScanLockingStrength(s tree.LockingStrength) ScanLockingStrength {
+	switch s {
+	case tree.ForNone:
+		return ScanLockingStrength_FOR_NONE
+	case tree.ForKeyShare:
+		return ScanLockingStrength_FOR_KEY_SHARE
+	case tree.ForShare:
+		return ScanLockingStrength_FOR_SHARE
+	case tree.ForNoKeyUpdate:
+		return ScanLockingStrength_FOR_NO_KEY_UPDATE
+	case tree.ForUpdate:
+		return ScanLockingStrength_FOR_UPDATE
+	default:
+		panic(fmt.Sprintf("unknown locking strength %s", s))
+	}
+}
+
+// IsExclusive returns true if scans with this locking strength prevent
+// concurrent transactions from modifying the rows that they return.
+func (s ScanLockingStrength) IsExclusive() bool {
+	return s >= ScanLockingStrength_FOR_NO_KEY_UPDATE
+}
+
+// PrettyString returns the SQL spelling of the locking strength, for use in
+// EXPLAIN output.
+func (s ScanLockingStrength) PrettyString() string {
+	switch s {
+	case ScanLockingStrength_FOR_NONE:
+		return "for none"
+	case ScanLockingStrength_FOR_KEY_SHARE:
+		return "for key share"
+	case ScanLockingStrength_FOR_SHARE:
+		return "for share"
+	case ScanLockingStrength_FOR_NO_KEY_UPDATE:
+		return "for no key update"
+	case ScanLockingStrength_FOR_UPDATE:
+		return "for update"
+	default:
+		panic(fmt.Sprintf("unexpected strength %s", s))
+	}
+}
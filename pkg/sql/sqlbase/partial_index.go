@@ -0,0 +1,152 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sqlbase
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/sql/parser"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/types"
+)
+
+// PredicateColumnIDs returns the IDs of the columns referenced by the
+// predicate of a partial index, in sorted order. It returns nil if the index
+// is not partial.
+func (desc *IndexDescriptor) PredicateColumnIDs(tableDesc *TableDescriptor) ([]ColumnID, error) {
+	if !desc.IsPartial() {
+		return nil, nil
+	}
+	parsed, err := parser.ParseExpr(desc.Predicate)
+	if err != nil {
+		return nil, pgerror.Wrapf(err, pgcode.Syntax,
+			"could not parse predicate of index %q", desc.Name)
+	}
+
+	colIDsUsed := make(map[ColumnID]struct{})
+	visitFn := func(expr tree.Expr) (recurse bool, newExpr tree.Expr, err error) {
+		if vBase, ok := expr.(tree.VarName); ok {
+			v, err := vBase.NormalizeVarName()
+			if err != nil {
+				return false, nil, err
+			}
+			if c, ok := v.(*tree.ColumnItem); ok {
+				col, _, err := tableDesc.FindColumnByName(c.ColumnName)
+				if err != nil {
+					return false, nil, pgerror.Newf(pgcode.UndefinedColumn,
+						"column %q not found for predicate of index %q",
+						c.ColumnName, desc.Name)
+				}
+				colIDsUsed[col.ID] = struct{}{}
+			}
+			return false, v, nil
+		}
+		return true, expr, nil
+	}
+	if _, err := tree.SimpleVisit(parsed, visitFn); err != nil {
+		return nil, err
+	}
+
+	colIDs := make([]ColumnID, 0, len(colIDsUsed))
+	for colID := range colIDsUsed {
+		colIDs = append(colIDs, colID)
+	}
+	sort.Sort(ColumnIDs(colIDs))
+	return colIDs, nil
+}
+
+// PartialIndexPredicates evaluates the predicates of a set of partial indexes
+// over table rows, in order to determine which of these indexes must contain
+// an entry for a given row.
+type PartialIndexPredicates struct {
+	evalCtx *tree.EvalContext
+	// exprs maps the ID of each partial index to its type-checked predicate.
+	exprs map[IndexID]tree.TypedExpr
+	iv    RowIndexedVarContainer
+}
+
+// NewPartialIndexPredicates parses and type-checks the predicates of the
+// partial indexes in the given slice. It returns nil if none of the indexes
+// are partial; a nil *PartialIndexPredicates includes every row in every
+// index.
+func NewPartialIndexPredicates(
+	tableDesc *TableDescriptor, indexes []IndexDescriptor, evalCtx *tree.EvalContext,
+) (*PartialIndexPredicates, error) {
+	var p *PartialIndexPredicates
+	for i := range indexes {
+		index := &indexes[i]
+		if !index.IsPartial() {
+			continue
+		}
+		if p == nil {
+			p = &PartialIndexPredicates{
+				evalCtx: evalCtx,
+				exprs:   make(map[IndexID]tree.TypedExpr),
+				iv:      RowIndexedVarContainer{Cols: tableDesc.Columns},
+			}
+		}
+		expr, err := parser.ParseExpr(index.Predicate)
+		if err != nil {
+			return nil, err
+		}
+
+		// Predicates can only reference the columns of the table, so we only
+		// need to resolve the types of those columns for type checking.
+		iv := &descContainer{tableDesc.Columns}
+		ivarHelper := tree.MakeIndexedVarHelper(iv, len(tableDesc.Columns))
+		tn := tree.MakeUnqualifiedTableName(tree.Name(tableDesc.Name))
+		source := NewSourceInfoForSingleTable(tn, ResultColumnsFromColDescs(tableDesc.Columns))
+		searchPath := DefaultSearchPath
+		if evalCtx.SessionData != nil {
+			searchPath = evalCtx.SessionData.SearchPath
+		}
+		expr, _, _, err = ResolveNames(expr, MakeMultiSourceInfo(source), ivarHelper, searchPath)
+		if err != nil {
+			return nil, err
+		}
+
+		semaCtx := tree.MakeSemaContext()
+		semaCtx.IVarContainer = iv
+		typedExpr, err := tree.TypeCheck(expr, &semaCtx, types.Bool)
+		if err != nil {
+			return nil, err
+		}
+		p.exprs[index.ID] = typedExpr
+	}
+	return p, nil
+}
+
+// Includes returns whether the given row, whose values are laid out according
+// to colMap, must have an entry in the given index. Rows are included in a
+// partial index only if its predicate evaluates to true; a NULL result
+// excludes the row, as for WHERE clauses.
+func (p *PartialIndexPredicates) Includes(
+	index *IndexDescriptor, colMap map[ColumnID]int, values []tree.Datum,
+) (bool, error) {
+	if p == nil {
+		return true, nil
+	}
+	expr, ok := p.exprs[index.ID]
+	if !ok {
+		return true, nil
+	}
+	p.iv.CurSourceRow = values
+	p.iv.Mapping = colMap
+	p.evalCtx.PushIVarContainer(&p.iv)
+	d, err := expr.Eval(p.evalCtx)
+	p.evalCtx.PopIVarContainer()
+	if err != nil {
+		return false, err
+	}
+	return d == tree.DBoolTrue, nil
+}
@@ -11,6 +11,7 @@
 package sqlbase
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"sort"
@@ -29,6 +30,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/types"
 	"github.com/cockroachdb/cockroach/pkg/util/encoding"
 	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/interval"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
@@ -500,6 +502,12 @@ func (desc *IndexDescriptor) IsInterleaved() bool {
 	return len(desc.Interleave.Ancestors) > 0 || len(desc.InterleavedBy) > 0
 }
 
+// IsPartial returns whether the index is a partial index, i.e. whether it only
+// contains entries for the rows that satisfy its predicate.
+func (desc *IndexDescriptor) IsPartial() bool {
+	return desc.Predicate != ""
+}
+
 // SetID implements the DescriptorProto interface.
 func (desc *TableDescriptor) SetID(id ID) {
 	desc.ID = id
@@ -533,6 +541,12 @@ func (desc *TableDescriptor) IsView() bool {
 	return desc.ViewQuery != ""
 }
 
+// MaterializedView returns true if the TableDescriptor describes a
+// materialized view, whose results are stored like the contents of a table.
+func (desc *TableDescriptor) MaterializedView() bool {
+	return desc.IsMaterializedView
+}
+
 // IsAs returns true if the TableDescriptor actually describes
 // a Table resource with an As source.
 func (desc *TableDescriptor) IsAs() bool {
@@ -545,6 +559,22 @@ func (desc *TableDescriptor) IsSequence() bool {
 	return desc.SequenceOpts != nil
 }
 
+// IsTemporary returns true if the TableDescriptor describes a temporary
+// table, which belongs to the temporary schema of the session that created it.
+func (desc *TableDescriptor) IsTemporary() bool {
+	return desc.TemporarySchemaID != InvalidID
+}
+
+// GetNameParentID returns the ID under which the name of the table is
+// recorded in system.namespace. This is the ID of its temporary schema for
+// temporary tables, and the ID of its database otherwise.
+func (desc *TableDescriptor) GetNameParentID() ID {
+	if desc.IsTemporary() {
+		return desc.TemporarySchemaID
+	}
+	return desc.ParentID
+}
+
 // IsVirtualTable returns true if the TableDescriptor describes a
 // virtual Table (like the information_schema tables) and thus doesn't
 // need to be physically stored.
@@ -563,10 +593,11 @@ func IsVirtualTable(id ID) bool {
 // physical Table that needs to be stored in the kv layer, as opposed to a
 // different resource like a view or a virtual table. Physical tables have
 // primary keys, column families, and indexes (unlike virtual tables).
-// Sequences count as physical tables because their values are stored in
-// the KV layer.
+// Sequences and materialized views count as physical tables because their
+// values are stored in the KV layer.
 func (desc *TableDescriptor) IsPhysicalTable() bool {
-	return desc.IsSequence() || (desc.IsTable() && !desc.IsVirtualTable())
+	return desc.IsSequence() || (desc.IsTable() && !desc.IsVirtualTable()) ||
+		desc.MaterializedView()
 }
 
 // KeysPerRow returns the maximum number of keys used to encode a row for the
@@ -1416,6 +1447,12 @@ func (desc *TableDescriptor) ValidateTable(st *cluster.Settings) error {
 					"mutation in state %s, direction %s, constraint %v",
 					errors.Safe(m.State), errors.Safe(m.Direction), desc.Constraint.Name)
 			}
+		case *DescriptorMutation_MaterializedViewRefresh:
+			if unSetEnums {
+				return errors.AssertionFailedf(
+					"mutation in state %s, direction %s, materialized view refresh",
+					errors.Safe(m.State), errors.Safe(m.Direction))
+			}
 		default:
 			return errors.AssertionFailedf(
 				"mutation in state %s, direction %s, and no column/index descriptor",
@@ -2423,6 +2460,12 @@ func (desc *MutableTableDescriptor) MakeMutationComplete(m DescriptorMutation) e
 			default:
 				return errors.Errorf("unsupported constraint type: %d", t.Constraint.ConstraintType)
 			}
+
+		case *DescriptorMutation_MaterializedViewRefresh:
+			// Swap in the indexes holding the new contents of the view. The data
+			// of the old indexes is garbage collected by the schema changer.
+			desc.PrimaryIndex = t.MaterializedViewRefresh.NewPrimaryIndex
+			desc.Indexes = t.MaterializedViewRefresh.NewIndexes
 		}
 
 	case DescriptorMutation_DROP:
@@ -2467,6 +2510,32 @@ func (desc *MutableTableDescriptor) AddForeignKeyValidationMutation(
 	desc.addMutation(m)
 }
 
+// AddMaterializedViewRefreshMutation adds a mutation to desc.Mutations that
+// recomputes the contents of the materialized view as of the given timestamp.
+// The results are written to copies of the view's indexes with new IDs, which
+// replace the existing indexes once the mutation completes.
+func (desc *MutableTableDescriptor) AddMaterializedViewRefreshMutation(asOf hlc.Timestamp) {
+	refresh := &MaterializedViewRefresh{
+		NewPrimaryIndex: desc.PrimaryIndex,
+		NewIndexes:      make([]IndexDescriptor, len(desc.Indexes)),
+		AsOf:            asOf,
+	}
+	refresh.NewPrimaryIndex.ID = desc.NextIndexID
+	desc.NextIndexID++
+	for i := range desc.Indexes {
+		refresh.NewIndexes[i] = desc.Indexes[i]
+		refresh.NewIndexes[i].ID = desc.NextIndexID
+		desc.NextIndexID++
+	}
+	m := DescriptorMutation{
+		Descriptor_: &DescriptorMutation_MaterializedViewRefresh{
+			MaterializedViewRefresh: refresh,
+		},
+		Direction: DescriptorMutation_ADD,
+	}
+	desc.addMutation(m)
+}
+
 // makeNotNullCheckConstraint creates a dummy check constraint equivalent to a
 // NOT NULL constraint on a column, so that NOT NULL constraints can be added
 // and dropped correctly in the schema changer. This function mutates inuseNames
@@ -2750,6 +2819,94 @@ func (desc *DatabaseDescriptor) Validate() error {
 	return desc.Privileges.Validate(desc.GetID())
 }
 
+// SetID implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetID(id ID) {
+	desc.ID = id
+}
+
+// TypeName returns the plain type of this descriptor.
+func (desc *TypeDescriptor) TypeName() string {
+	return "type"
+}
+
+// SetName implements the DescriptorProto interface.
+func (desc *TypeDescriptor) SetName(name string) {
+	desc.Name = name
+}
+
+// GetAuditMode is part of the DescriptorProto interface.
+// This is a stub; auditing is not supported for types.
+func (desc *TypeDescriptor) GetAuditMode() TableDescriptor_AuditMode {
+	return TableDescriptor_DISABLED
+}
+
+// Validate validates that the type descriptor is well formed.
+func (desc *TypeDescriptor) Validate() error {
+	if err := validateName(desc.Name, "type"); err != nil {
+		return err
+	}
+	if desc.ID == 0 {
+		return fmt.Errorf("invalid type ID %d", desc.ID)
+	}
+	if desc.ParentID == 0 {
+		return fmt.Errorf("invalid parent ID %d", desc.ParentID)
+	}
+	labels := make(map[string]struct{}, len(desc.EnumMembers))
+	for i := range desc.EnumMembers {
+		member := &desc.EnumMembers[i]
+		if _, ok := labels[member.LogicalRepresentation]; ok {
+			return fmt.Errorf("duplicate enum label %q", member.LogicalRepresentation)
+		}
+		labels[member.LogicalRepresentation] = struct{}{}
+		if i > 0 && bytes.Compare(
+			desc.EnumMembers[i-1].PhysicalRepresentation, member.PhysicalRepresentation) >= 0 {
+			return fmt.Errorf("enum label %q is not encoded in declaration order",
+				member.LogicalRepresentation)
+		}
+	}
+	return desc.Privileges.Validate(desc.GetID())
+}
+
+// MakeTypesT returns the types.T for the type described by the descriptor.
+// The returned type carries a copy of the enum's labels, so that values of the
+// type can be encoded and decoded without access to the descriptor.
+func (desc *TypeDescriptor) MakeTypesT() *types.T {
+	logical := make([]string, len(desc.EnumMembers))
+	physical := make([][]byte, len(desc.EnumMembers))
+	var readOnly []bool
+	for i := range desc.EnumMembers {
+		logical[i] = desc.EnumMembers[i].LogicalRepresentation
+		physical[i] = desc.EnumMembers[i].PhysicalRepresentation
+		if desc.EnumMembers[i].ReadOnly {
+			if readOnly == nil {
+				readOnly = make([]bool, len(desc.EnumMembers))
+			}
+			readOnly[i] = true
+		}
+	}
+	return types.MakeEnum(uint32(desc.ID), desc.Name, logical, physical, readOnly)
+}
+
+// MakeEnumMembersWritable marks all the members of the enum as writable. It
+// must only be called once every node that may decode values of the type
+// knows of all its members; see TypeDescriptor_EnumMember.ReadOnly.
+func (desc *TypeDescriptor) MakeEnumMembersWritable() {
+	for i := range desc.EnumMembers {
+		desc.EnumMembers[i].ReadOnly = false
+	}
+}
+
+// AddReferencingDescriptorID records that the descriptor with the given ID
+// has a column of this type.
+func (desc *TypeDescriptor) AddReferencingDescriptorID(id ID) {
+	for _, refID := range desc.ReferencingDescriptorIDs {
+		if refID == id {
+			return
+		}
+	}
+	desc.ReferencingDescriptorIDs = append(desc.ReferencingDescriptorIDs, id)
+}
+
 // GetID returns the ID of the descriptor.
 func (desc *Descriptor) GetID() ID {
 	switch t := desc.Union.(type) {
@@ -2757,6 +2914,8 @@ func (desc *Descriptor) GetID() ID {
 		return t.Table.ID
 	case *Descriptor_Database:
 		return t.Database.ID
+	case *Descriptor_Type:
+		return t.Type.ID
 	default:
 		return 0
 	}
@@ -2769,6 +2928,8 @@ func (desc *Descriptor) GetName() string {
 		return t.Table.Name
 	case *Descriptor_Database:
 		return t.Database.Name
+	case *Descriptor_Type:
+		return t.Type.Name
 	default:
 		return ""
 	}
@@ -3243,3 +3404,26 @@ func (tk TableKey) Key() roachpb.Key {
 func (tk TableKey) Name() string {
 	return tk.name
 }
+
+// SchemaKey implements DescriptorKey interface. It is only used for the
+// temporary schemas of sessions, which are recorded in system.namespace under
+// the ID of their database.
+type SchemaKey struct {
+	parentID ID
+	name     string
+}
+
+// NewSchemaKey returns a new SchemaKey.
+func NewSchemaKey(parentID ID, name string) SchemaKey {
+	return SchemaKey{parentID, name}
+}
+
+// Key implements DescriptorKey interface.
+func (sk SchemaKey) Key() roachpb.Key {
+	return MakeNameMetadataKey(sk.parentID, sk.name)
+}
+
+// Name implements DescriptorKey interface.
+func (sk SchemaKey) Name() string {
+	return sk.name
+}
@@ -224,6 +224,29 @@ CREATE TABLE system.comments (
    comment   STRING NOT NULL, -- the comment
    PRIMARY KEY (type, object_id, sub_id)
 );`
+
+	// scheduled_jobs stores the schedules of jobs which are run periodically,
+	// such as scheduled backups. The execution_args are specific to the
+	// executor_type, which runs the schedule when it's due.
+	ScheduledJobsTableSchema = `
+CREATE TABLE system.scheduled_jobs (
+	schedule_id    INT8      DEFAULT unique_rowid() PRIMARY KEY,
+	schedule_name  STRING    NOT NULL,
+	created        TIMESTAMP NOT NULL DEFAULT now(),
+	owner          STRING    NOT NULL,
+	schedule_expr  STRING    NOT NULL,
+	next_run       TIMESTAMP,
+	executor_type  STRING    NOT NULL,
+	execution_args BYTES     NOT NULL,
+	last_run       TIMESTAMP,
+	last_error     STRING,
+	running_node   INT8,
+	INDEX (next_run),
+	FAMILY "primary" (
+		schedule_id, schedule_name, created, owner, schedule_expr, next_run,
+		executor_type, execution_args, last_run, last_error, running_node
+	)
+);`
 )
 
 func pk(name string) IndexDescriptor {
@@ -264,6 +287,7 @@ var SystemAllowedPrivileges = map[ID]privilege.List{
 	keys.LocationsTableID:       privilege.ReadWriteData,
 	keys.RoleMembersTableID:     privilege.ReadWriteData,
 	keys.CommentsTableID:        privilege.ReadWriteData,
+	keys.ScheduledJobsTableID:   privilege.ReadWriteData,
 }
 
 // Helpers used to make some of the TableDescriptor literals below more concise.
@@ -860,6 +884,56 @@ var (
 		FormatVersion:  InterleavedFormatVersion,
 		NextMutationID: 1,
 	}
+
+	// ScheduledJobsTable is the descriptor for the scheduled jobs table.
+	ScheduledJobsTable = TableDescriptor{
+		Name:     "scheduled_jobs",
+		ID:       keys.ScheduledJobsTableID,
+		ParentID: keys.SystemDatabaseID,
+		Version:  1,
+		Columns: []ColumnDescriptor{
+			{Name: "schedule_id", ID: 1, Type: *types.Int, DefaultExpr: &uniqueRowIDString},
+			{Name: "schedule_name", ID: 2, Type: *types.String},
+			{Name: "created", ID: 3, Type: *types.Timestamp, DefaultExpr: &nowString},
+			{Name: "owner", ID: 4, Type: *types.String},
+			{Name: "schedule_expr", ID: 5, Type: *types.String},
+			{Name: "next_run", ID: 6, Type: *types.Timestamp, Nullable: true},
+			{Name: "executor_type", ID: 7, Type: *types.String},
+			{Name: "execution_args", ID: 8, Type: *types.Bytes},
+			{Name: "last_run", ID: 9, Type: *types.Timestamp, Nullable: true},
+			{Name: "last_error", ID: 10, Type: *types.String, Nullable: true},
+			{Name: "running_node", ID: 11, Type: *types.Int, Nullable: true},
+		},
+		NextColumnID: 12,
+		Families: []ColumnFamilyDescriptor{
+			{
+				Name: "primary",
+				ID:   0,
+				ColumnNames: []string{
+					"schedule_id", "schedule_name", "created", "owner", "schedule_expr", "next_run",
+					"executor_type", "execution_args", "last_run", "last_error", "running_node",
+				},
+				ColumnIDs: []ColumnID{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11},
+			},
+		},
+		NextFamilyID: 1,
+		PrimaryIndex: pk("schedule_id"),
+		Indexes: []IndexDescriptor{
+			{
+				Name:             "scheduled_jobs_next_run_idx",
+				ID:               2,
+				Unique:           false,
+				ColumnNames:      []string{"next_run"},
+				ColumnDirections: []IndexDescriptor_Direction{IndexDescriptor_ASC},
+				ColumnIDs:        []ColumnID{6},
+				ExtraColumnIDs:   []ColumnID{1},
+			},
+		},
+		NextIndexID:    3,
+		Privileges:     NewCustomSuperuserPrivilegeDescriptor(SystemAllowedPrivileges[keys.ScheduledJobsTableID]),
+		FormatVersion:  InterleavedFormatVersion,
+		NextMutationID: 1,
+	}
 )
 
 // Create a kv pair for the zone config for the given key and config value.
@@ -905,6 +979,11 @@ func addSystemDescriptorsToSchema(target *MetadataSchema) {
 	// The CommentsTable has been introduced in 2.2. It was added here since it
 	// was introduced, but it's also created as a migration for older clusters.
 	target.AddDescriptor(keys.SystemDatabaseID, &CommentsTable)
+
+	// The ScheduledJobsTable has been introduced in 19.2. It was added here
+	// since it was introduced, but it's also created as a migration for older
+	// clusters.
+	target.AddDescriptor(keys.SystemDatabaseID, &ScheduledJobsTable)
 }
 
 // addSystemDatabaseToSchema populates the supplied MetadataSchema with the
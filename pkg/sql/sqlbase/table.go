@@ -102,6 +102,11 @@ func ValidateColumnDefType(t *types.T) error {
 		types.TimestampFamily, types.TimestampTZFamily, types.UuidFamily:
 		// These types are OK.
 
+	case types.EnumFamily:
+		if t.IsUserDefinedTypeReference() {
+			return errors.AssertionFailedf("unresolved reference to type %s", t.Name())
+		}
+
 	default:
 		return pgerror.Newf(pgcode.InvalidTableDefinition,
 			"value type %s cannot be used for table columns", t.String())
@@ -151,11 +156,16 @@ func MakeColumnDefDescs(
 		Nullable: d.Nullable.Nullability != tree.NotNull && !d.PrimaryKey,
 	}
 
-	// Validate and assign column type.
-	err := ValidateColumnDefType(d.Type)
+	// Resolve references to user-defined types, then validate and assign the
+	// column type.
+	typ, err := semaCtx.ResolveTypeReference(d.Type)
 	if err != nil {
 		return nil, nil, nil, err
 	}
+	d.Type = typ
+	if err := ValidateColumnDefType(d.Type); err != nil {
+		return nil, nil, nil, err
+	}
 	col.Type = *d.Type
 
 	var typedExpr tree.TypedExpr
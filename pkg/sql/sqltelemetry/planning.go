@@ -20,6 +20,14 @@ import (
 // is planned without error in a query.
 var CteUseCounter = telemetry.GetCounterOnce("sql.plan.cte")
 
+// RecursiveCteUseCounter is to be incremented every time a recursive CTE (WITH
+// RECURSIVE...) is planned without error in a query.
+var RecursiveCteUseCounter = telemetry.GetCounterOnce("sql.plan.cte.recursive")
+
+// SelectForUpdateCounter is to be incremented every time a locking clause
+// (FOR UPDATE, FOR SHARE, etc.) is planned in a SELECT statement.
+var SelectForUpdateCounter = telemetry.GetCounterOnce("sql.plan.select-for-update")
+
 // SubqueryUseCounter is to be incremented every time a subquery is
 // planned.
 var SubqueryUseCounter = telemetry.GetCounterOnce("sql.plan.subquery")
@@ -20,6 +20,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/jobs/jobspb"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
@@ -141,7 +142,8 @@ func (tc *TableCollection) getMutableTableDescriptor(
 		log.Infof(ctx, "reading mutable descriptor on table '%s'", tn)
 	}
 
-	if tn.SchemaName != tree.PublicSchemaName {
+	isTemporary := sessiondata.IsTemporarySchemaName(tn.Schema())
+	if tn.SchemaName != tree.PublicSchemaName && !isTemporary {
 		if flags.required {
 			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(tn))
 		}
@@ -164,7 +166,17 @@ func (tc *TableCollection) getMutableTableDescriptor(
 		}
 	}
 
-	if refuseFurtherLookup, table, err := tc.getUncommittedTable(dbID, tn, flags.required); refuseFurtherLookup || err != nil {
+	// Objects of temporary schemas are recorded under the ID of the schema
+	// rather than that of the database.
+	parentID := dbID
+	if isTemporary {
+		parentID, err = getTemporarySchemaID(ctx, txn, dbID, tn.Schema())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if refuseFurtherLookup, table, err := tc.getUncommittedTable(parentID, tn, flags.required); refuseFurtherLookup || err != nil {
 		return nil, err
 	} else if mut := table.MutableTableDescriptor; mut != nil {
 		log.VEventf(ctx, 2, "found uncommitted table %d", mut.ID)
@@ -197,7 +209,8 @@ func (tc *TableCollection) getTableVersion(
 		log.Infof(ctx, "planner acquiring lease on table '%s'", tn)
 	}
 
-	if tn.SchemaName != tree.PublicSchemaName {
+	isTemporary := sessiondata.IsTemporarySchemaName(tn.Schema())
+	if tn.SchemaName != tree.PublicSchemaName && !isTemporary {
 		if flags.required {
 			return nil, sqlbase.NewUnsupportedSchemaUsageError(tree.ErrString(tn))
 		}
@@ -220,6 +233,16 @@ func (tc *TableCollection) getTableVersion(
 		}
 	}
 
+	// Objects of temporary schemas are recorded under the ID of the schema
+	// rather than that of the database.
+	parentID := dbID
+	if isTemporary {
+		parentID, err = getTemporarySchemaID(ctx, txn, dbID, tn.Schema())
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// TODO(vivek): Ideally we'd avoid caching for only the
 	// system.descriptor and system.lease tables, because they are
 	// used for acquiring leases, creating a chicken&egg problem.
@@ -227,10 +250,13 @@ func (tc *TableCollection) getTableVersion(
 	// disabling caching of system.eventlog, system.rangelog, and
 	// system.users. For now we're sticking to disabling caching of
 	// all system descriptors except the role-members-table.
-	avoidCache := flags.avoidCached || testDisableTableLeases ||
+	//
+	// Temporary tables are only ever used by the session that created them,
+	// so there is no point in leasing them either.
+	avoidCache := flags.avoidCached || testDisableTableLeases || isTemporary ||
 		(tn.Catalog() == sqlbase.SystemDB.Name && tn.TableName.String() != sqlbase.RoleMembersTable.Name)
 
-	if refuseFurtherLookup, table, err := tc.getUncommittedTable(dbID, tn, flags.required); refuseFurtherLookup || err != nil {
+	if refuseFurtherLookup, table, err := tc.getUncommittedTable(parentID, tn, flags.required); refuseFurtherLookup || err != nil {
 		return nil, err
 	} else if immut := table.ImmutableTableDescriptor; immut != nil {
 		// If not forcing to resolve using KV, tables being added aren't visible.
@@ -265,14 +291,14 @@ func (tc *TableCollection) getTableVersion(
 	// transaction.
 	for _, table := range tc.leasedTables {
 		if table.Name == string(tn.TableName) &&
-			table.ParentID == dbID {
+			table.GetNameParentID() == parentID {
 			log.VEventf(ctx, 2, "found table in table collection for table '%s'", tn)
 			return table, nil
 		}
 	}
 
 	origTimestamp := txn.OrigTimestamp()
-	table, expiration, err := tc.leaseMgr.AcquireByName(ctx, origTimestamp, dbID, tn.Table())
+	table, expiration, err := tc.leaseMgr.AcquireByName(ctx, origTimestamp, parentID, tn.Table())
 	if err != nil {
 		// Read the descriptor from the store in the face of some specific errors
 		// because of a known limitation of AcquireByName. See the known
@@ -546,7 +572,7 @@ func (tc *TableCollection) getUncommittedTable(
 
 		// Do we know about a table with this name?
 		if mutTbl.Name == string(tn.TableName) &&
-			mutTbl.ParentID == dbID {
+			mutTbl.GetNameParentID() == dbID {
 			// Right state?
 			if err = filterTableState(mutTbl.TableDesc()); err != nil && err != errTableAdding {
 				if !required {
@@ -645,8 +671,13 @@ func (p *planner) createOrUpdateSchemaChangeJob(
 		spanList = job.Details().(jobspb.SchemaChangeDetails).ResumeSpanList
 	}
 
-	span := tableDesc.PrimaryIndexSpan()
 	for i := len(tableDesc.ClusterVersion.Mutations) + len(spanList); i < len(tableDesc.Mutations); i++ {
+		span := tableDesc.PrimaryIndexSpan()
+		if refresh := tableDesc.Mutations[i].GetMaterializedViewRefresh(); refresh != nil {
+			// A materialized view refresh backfills the new primary index of the
+			// view rather than reading the existing one.
+			span = tableDesc.IndexSpan(refresh.NewPrimaryIndex.ID)
+		}
 		spanList = append(spanList,
 			jobspb.ResumeSpanList{
 				ResumeSpans: []roachpb.Span{span},
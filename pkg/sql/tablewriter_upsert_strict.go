@@ -19,6 +19,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/sql/row"
 	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
 	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/errorutil/unimplemented"
 	"github.com/cockroachdb/cockroach/pkg/util/log"
 	"github.com/cockroachdb/errors"
 )
@@ -103,6 +104,10 @@ func (tu *strictTableUpserter) getUniqueIndexes() (err error) {
 	indexes := tableDesc.Indexes
 	for _, index := range indexes {
 		if index.Unique {
+			if index.IsPartial() {
+				return unimplemented.NewWithIssuef(9683,
+					"ON CONFLICT DO NOTHING on a table with unique partial index %q", index.Name)
+			}
 			tu.conflictIndexes = append(tu.conflictIndexes, index)
 		}
 	}
@@ -0,0 +1,355 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/internal/client"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/server/serverpb"
+	"github.com/cockroachdb/cockroach/pkg/settings"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgcode"
+	"github.com/cockroachdb/cockroach/pkg/sql/pgwire/pgerror"
+	"github.com/cockroachdb/cockroach/pkg/sql/sem/tree"
+	"github.com/cockroachdb/cockroach/pkg/sql/sessiondata"
+	"github.com/cockroachdb/cockroach/pkg/sql/sqlbase"
+	"github.com/cockroachdb/cockroach/pkg/util/encoding"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/stop"
+	"github.com/cockroachdb/cockroach/pkg/util/uint128"
+	"github.com/cockroachdb/errors"
+)
+
+// Temporary tables live in a per-session temporary schema. There are no
+// schema descriptors, so a temporary schema is only recorded as an entry
+// (dbID, "pg_temp_<session ID>") -> schemaID in system.namespace, and the
+// tables it contains are recorded under (schemaID, tableName). The table
+// descriptors themselves keep the database as their parent and additionally
+// record the ID of their temporary schema.
+//
+// A temporary schema and its tables are dropped when the owning session
+// ends. Sessions on nodes that crash never get that chance, so every node
+// also runs a TemporaryObjectCleaner that periodically drops temporary
+// schemas whose session no longer exists.
+
+// TempObjectCleanupInterval is the interval at which the temporary object
+// cleaner looks for temporary schemas left behind by dead sessions.
+var TempObjectCleanupInterval = settings.RegisterNonNegativeDurationSetting(
+	"sql.temp_object_cleaner.cleanup_interval",
+	"how often to clean up temporary objects left behind by sessions that no longer exist",
+	30*time.Minute,
+)
+
+// temporarySchemaName returns the name of the temporary schema owned by the
+// session with the given ID.
+func temporarySchemaName(sessionID ClusterWideID) string {
+	return fmt.Sprintf("%s_%d_%d", sessiondata.PgTempSchemaName, sessionID.Hi, sessionID.Lo)
+}
+
+// temporarySchemaSessionID returns the ID of the session owning the
+// temporary schema with the given name.
+func temporarySchemaSessionID(scName string) (ClusterWideID, error) {
+	parts := strings.Split(scName, "_")
+	if len(parts) != 4 || !sessiondata.IsTemporarySchemaName(scName) {
+		return ClusterWideID{}, errors.AssertionFailedf("malformed temporary schema name %q", scName)
+	}
+	hi, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil {
+		return ClusterWideID{}, errors.Wrapf(err, "malformed temporary schema name %q", scName)
+	}
+	lo, err := strconv.ParseUint(parts[3], 10, 64)
+	if err != nil {
+		return ClusterWideID{}, errors.Wrapf(err, "malformed temporary schema name %q", scName)
+	}
+	return ClusterWideID{Uint128: uint128.Uint128{Hi: hi, Lo: lo}}, nil
+}
+
+// isTemporarySchema returns whether the given schema name designates a
+// temporary schema, either through the pg_temp alias or by its actual name.
+func isTemporarySchema(scName string) bool {
+	return scName == sessiondata.PgTempSchemaName || sessiondata.IsTemporarySchemaName(scName)
+}
+
+// getTemporarySchemaID looks up the ID of the temporary schema with the
+// given name in the given database. InvalidID is returned if the schema does
+// not exist.
+func getTemporarySchemaID(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID, scName string,
+) (sqlbase.ID, error) {
+	return getDescriptorID(ctx, txn, sqlbase.NewSchemaKey(dbID, scName))
+}
+
+// temporarySchemaExists returns whether the given temporary schema exists in
+// the given database. The temporary schema of the current session is always
+// considered to exist, since it is created on demand.
+func (p *planner) temporarySchemaExists(
+	ctx context.Context, dbID sqlbase.ID, scName string,
+) (bool, error) {
+	if sessionID := p.ExtendedEvalContext().SessionID; sessionID != (ClusterWideID{}) &&
+		(scName == sessiondata.PgTempSchemaName || scName == temporarySchemaName(sessionID)) {
+		return true, nil
+	}
+	if !sessiondata.IsTemporarySchemaName(scName) {
+		return false, nil
+	}
+	scID, err := getTemporarySchemaID(ctx, p.txn, dbID, scName)
+	return scID != sqlbase.InvalidID, err
+}
+
+// getTemporarySchemaNames returns the names of the temporary schemas of the
+// given database, indexed by schema ID.
+func getTemporarySchemaNames(
+	ctx context.Context, txn *client.Txn, dbID sqlbase.ID,
+) (map[sqlbase.ID]string, error) {
+	prefix := sqlbase.MakeNameMetadataKey(dbID, "")
+	sr, err := txn.Scan(ctx, prefix, prefix.PrefixEnd(), 0)
+	if err != nil {
+		return nil, err
+	}
+	res := make(map[sqlbase.ID]string)
+	for _, row := range sr {
+		_, name, err := encoding.DecodeUnsafeStringAscending(bytes.TrimPrefix(row.Key, prefix), nil)
+		if err != nil {
+			return nil, err
+		}
+		if sessiondata.IsTemporarySchemaName(name) {
+			res[sqlbase.ID(row.ValueInt())] = name
+		}
+	}
+	return res, nil
+}
+
+// getOrCreateTemporarySchema returns the ID of the temporary schema of the
+// current session in the given database, creating the schema if this is the
+// first temporary object of the session in that database.
+func (p *planner) getOrCreateTemporarySchema(
+	ctx context.Context, dbID sqlbase.ID,
+) (sqlbase.ID, error) {
+	sessionID := p.ExtendedEvalContext().SessionID
+	if sessionID == (ClusterWideID{}) {
+		return sqlbase.InvalidID, pgerror.New(pgcode.FeatureNotSupported,
+			"temporary tables can only be created by client sessions")
+	}
+	scName := temporarySchemaName(sessionID)
+	scID, err := getTemporarySchemaID(ctx, p.txn, dbID, scName)
+	if err != nil {
+		return sqlbase.InvalidID, err
+	}
+	if scID == sqlbase.InvalidID {
+		scID, err = GenerateUniqueDescID(ctx, p.ExecCfg().DB)
+		if err != nil {
+			return sqlbase.InvalidID, err
+		}
+		key := sqlbase.NewSchemaKey(dbID, scName).Key()
+		if p.ExtendedEvalContext().Tracing.KVTracingEnabled() {
+			log.VEventf(ctx, 2, "CPut %s -> %d", key, scID)
+		}
+		if err := p.txn.CPut(ctx, key, scID, nil); err != nil {
+			return sqlbase.InvalidID, err
+		}
+	}
+	p.sessionDataMutator.SetTemporarySchemaName(scName)
+	return scID, nil
+}
+
+// resolveTemporaryTableTarget determines whether a table about to be created
+// is temporary, given its resolved name, whether the statement used the
+// TEMPORARY modifier and whether the statement explicitly named a permanent
+// schema. Tables created in the pg_temp schema are temporary even without the
+// modifier. The schema of tn is replaced by the temporary schema of the
+// session for temporary tables.
+func (p *planner) resolveTemporaryTableTarget(
+	tn *tree.TableName, temporary, explicitPermanentSchema bool,
+) (bool, error) {
+	sessionSchema := temporarySchemaName(p.ExtendedEvalContext().SessionID)
+	switch scName := tn.Schema(); {
+	case scName == sessiondata.PgTempSchemaName || scName == sessionSchema:
+		temporary = true
+	case sessiondata.IsTemporarySchemaName(scName):
+		return false, pgerror.New(pgcode.InvalidTableDefinition,
+			"cannot create relations in temporary schemas of other sessions")
+	case temporary && explicitPermanentSchema:
+		return false, pgerror.New(pgcode.InvalidTableDefinition,
+			"cannot create temporary relation in non-temporary schema")
+	}
+	if temporary {
+		tn.SchemaName = tree.Name(sessionSchema)
+	}
+	return temporary, nil
+}
+
+// cleanupSessionTempObjects drops all the temporary schemas owned by the
+// session with the given ID, along with the tables they contain.
+func cleanupSessionTempObjects(
+	ctx context.Context, db *client.DB, ie *InternalExecutor, sessionID ClusterWideID,
+) error {
+	scName := temporarySchemaName(sessionID)
+	return db.Txn(ctx, func(ctx context.Context, txn *client.Txn) error {
+		rows, err := ie.Query(
+			ctx, "find-temp-schemas", txn,
+			`SELECT d.id, d.name, s.id FROM system.namespace AS s
+			   JOIN system.namespace AS d ON s."parentID" = d.id
+			  WHERE d."parentID" = 0 AND s.name = $1`,
+			scName,
+		)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			dbID := sqlbase.ID(tree.MustBeDInt(row[0]))
+			dbName := string(tree.MustBeDString(row[1]))
+			scID := sqlbase.ID(tree.MustBeDInt(row[2]))
+			if err := cleanupTempSchema(ctx, txn, ie, dbID, dbName, scID, scName); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// cleanupTempSchema drops the tables of a temporary schema and then removes
+// the schema itself from system.namespace.
+func cleanupTempSchema(
+	ctx context.Context,
+	txn *client.Txn,
+	ie *InternalExecutor,
+	dbID sqlbase.ID,
+	dbName string,
+	scID sqlbase.ID,
+	scName string,
+) error {
+	rows, err := ie.Query(
+		ctx, "find-temp-tables", txn,
+		`SELECT name FROM system.namespace WHERE "parentID" = $1`, scID,
+	)
+	if err != nil {
+		return err
+	}
+	if len(rows) > 0 {
+		// Temporary tables can only reference each other, so they are all
+		// dropped at once.
+		var tables tree.TableNames
+		for _, row := range rows {
+			tables = append(tables, tree.MakeTableNameWithSchema(
+				tree.Name(dbName), tree.Name(scName), tree.Name(tree.MustBeDString(row[0]))))
+		}
+		stmt := &tree.DropTable{Names: tables, IfExists: true, DropBehavior: tree.DropCascade}
+		if _, err := ie.Exec(ctx, "drop-temp-tables", txn, tree.AsString(stmt)); err != nil {
+			return err
+		}
+	}
+	return txn.Del(ctx, sqlbase.NewSchemaKey(dbID, scName).Key())
+}
+
+// TemporaryObjectCleaner periodically drops the temporary schemas of
+// sessions that no longer exist, typically because the node they were
+// running on went down before the session could clean up after itself.
+type TemporaryObjectCleaner struct {
+	settings     *cluster.Settings
+	db           *client.DB
+	ie           *InternalExecutor
+	statusServer serverpb.StatusServer
+	isLive       func(roachpb.NodeID) (bool, error)
+}
+
+// NewTemporaryObjectCleaner creates a TemporaryObjectCleaner.
+func NewTemporaryObjectCleaner(
+	settings *cluster.Settings,
+	db *client.DB,
+	ie *InternalExecutor,
+	statusServer serverpb.StatusServer,
+	isLive func(roachpb.NodeID) (bool, error),
+) *TemporaryObjectCleaner {
+	return &TemporaryObjectCleaner{
+		settings:     settings,
+		db:           db,
+		ie:           ie,
+		statusServer: statusServer,
+		isLive:       isLive,
+	}
+}
+
+// Start starts the cleaner loop.
+func (c *TemporaryObjectCleaner) Start(ctx context.Context, stopper *stop.Stopper) {
+	stopper.RunWorker(ctx, func(ctx context.Context) {
+		for {
+			select {
+			case <-time.After(TempObjectCleanupInterval.Get(&c.settings.SV)):
+				if err := c.doCleanup(ctx); err != nil {
+					log.Warningf(ctx, "failed to clean up temporary objects: %v", err)
+				}
+			case <-stopper.ShouldQuiesce():
+				return
+			}
+		}
+	})
+}
+
+// doCleanup drops the temporary schemas of all the sessions that are no
+// longer active.
+func (c *TemporaryObjectCleaner) doCleanup(ctx context.Context) error {
+	rows, err := c.ie.Query(
+		ctx, "find-all-temp-schemas", nil, /* txn */
+		`SELECT DISTINCT s.name FROM system.namespace AS s
+		   JOIN system.namespace AS d ON s."parentID" = d.id
+		  WHERE d."parentID" = 0 AND s.name LIKE $1`,
+		sessiondata.PgTempSchemaName+"\\_%",
+	)
+	if err != nil || len(rows) == 0 {
+		return err
+	}
+
+	// Only consider sessions absent from the list of active sessions. A node
+	// that failed to report its sessions may still be running the session
+	// unless the node is known to be dead.
+	resp, err := c.statusServer.ListSessions(ctx, &serverpb.ListSessionsRequest{})
+	if err != nil {
+		return err
+	}
+	activeSessions := make(map[uint128.Uint128]struct{}, len(resp.Sessions))
+	for _, s := range resp.Sessions {
+		activeSessions[uint128.FromBytes(s.ID)] = struct{}{}
+	}
+	unreachableNodes := make(map[roachpb.NodeID]struct{}, len(resp.Errors))
+	for _, e := range resp.Errors {
+		unreachableNodes[e.NodeID] = struct{}{}
+	}
+
+	for _, row := range rows {
+		scName := string(tree.MustBeDString(row[0]))
+		sessionID, err := temporarySchemaSessionID(scName)
+		if err != nil {
+			log.Warningf(ctx, "%v", err)
+			continue
+		}
+		if _, ok := activeSessions[sessionID.Uint128]; ok {
+			continue
+		}
+		nodeID := roachpb.NodeID(sessionID.GetNodeID())
+		if _, ok := unreachableNodes[nodeID]; ok {
+			if live, err := c.isLive(nodeID); err != nil || live {
+				continue
+			}
+		}
+		log.Infof(ctx, "cleaning up temporary schema %s", scName)
+		if err := cleanupSessionTempObjects(ctx, c.db, c.ie, sessionID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
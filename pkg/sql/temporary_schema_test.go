@@ -0,0 +1,101 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package sql
+
+import (
+	"context"
+	gosql "database/sql"
+	"net/url"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/base"
+	"github.com/cockroachdb/cockroach/pkg/security"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/serverutils"
+	"github.com/cockroachdb/cockroach/pkg/testutils/sqlutils"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/pkg/errors"
+)
+
+func TestTemporarySchemaName(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	sessionID := GenerateClusterWideID(hlc.Timestamp{WallTime: 1234567890, Logical: 12}, 3)
+	scName := temporarySchemaName(sessionID)
+	if scName != "pg_temp_1234567890_51539607555" {
+		t.Fatalf("unexpected temporary schema name %q", scName)
+	}
+	parsed, err := temporarySchemaSessionID(scName)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed != sessionID {
+		t.Fatalf("expected session ID %s, got %s", sessionID, parsed)
+	}
+	if parsed.GetNodeID() != 3 {
+		t.Fatalf("expected node ID 3, got %d", parsed.GetNodeID())
+	}
+
+	for _, name := range []string{"pg_temp", "pg_temp_1", "pg_temp_a_b", "public"} {
+		if _, err := temporarySchemaSessionID(name); err == nil {
+			t.Errorf("expected error for %q", name)
+		}
+	}
+}
+
+// TestTemporaryTablesDroppedOnSessionClose checks that the temporary
+// tables of a session, along with its temporary schemas, are dropped when
+// the session ends.
+func TestTemporaryTablesDroppedOnSessionClose(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	s, mainDB, _ := serverutils.StartServer(t, base.TestServerArgs{})
+	defer s.Stopper().Stop(context.TODO())
+	sqlDB := sqlutils.MakeSQLRunner(mainDB)
+
+	sqlDB.Exec(t, `CREATE DATABASE d1; CREATE DATABASE d2`)
+
+	pgURL, cleanup := sqlutils.PGUrl(
+		t, s.ServingAddr(), "TestTemporaryTablesDroppedOnSessionClose", url.User(security.RootUser))
+	defer cleanup()
+	db, err := gosql.Open("postgres", pgURL.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Use a single connection, so that all the statements run in the same
+	// session.
+	db.SetMaxOpenConns(1)
+	tempDB := sqlutils.MakeSQLRunner(db)
+	tempDB.Exec(t, `CREATE TEMP TABLE d1.t (a INT)`)
+	tempDB.Exec(t, `SET database = d2`)
+	tempDB.Exec(t, `CREATE TEMP TABLE t (a INT PRIMARY KEY)`)
+	tempDB.Exec(t, `CREATE TEMP TABLE u (a INT REFERENCES t)`)
+
+	const countTempSchemas = `SELECT count(*) FROM system.namespace WHERE name LIKE 'pg\_temp\_%'`
+	sqlDB.CheckQueryResults(t, countTempSchemas, [][]string{{"2"}})
+
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	testutils.SucceedsSoon(t, func() error {
+		var count int
+		sqlDB.QueryRow(t, countTempSchemas).Scan(&count)
+		if count != 0 {
+			return errors.Errorf("expected no temporary schemas, found %d", count)
+		}
+		return nil
+	})
+	sqlDB.CheckQueryResults(t,
+		`SELECT count(*) FROM [SHOW TABLES FROM d1] UNION ALL SELECT count(*) FROM [SHOW TABLES FROM d2]`,
+		[][]string{{"0"}, {"0"}},
+	)
+}
@@ -42,7 +42,9 @@ func CreateTestTableDescriptor(
 		nil, /* vt */
 		st,
 		stmt.AST.(*tree.CreateTable),
-		parentID, id,
+		parentID,
+		sqlbase.InvalidID, /* temporarySchemaID */
+		id,
 		hlc.Timestamp{}, /* creationTime */
 		privileges,
 		nil, /* affected */
@@ -208,7 +208,7 @@ func (p *planner) truncateTable(
 	//
 	// TODO(vivek): Fix properly along with #12123.
 	zoneKey := config.MakeZoneKey(uint32(tableDesc.ID))
-	nameKey := sqlbase.MakeNameMetadataKey(tableDesc.ParentID, tableDesc.GetName())
+	nameKey := sqlbase.MakeNameMetadataKey(tableDesc.GetNameParentID(), tableDesc.GetName())
 	b := &client.Batch{}
 	// Use CPut because we want to remove a specific name -> id map.
 	if traceKV {
@@ -264,8 +264,21 @@ func (p *planner) truncateTable(
 	}
 	newTableDesc.Mutations = nil
 	newTableDesc.GCMutations = nil
+
+	// The row-level TTL job of the old table exits once the table is dropped,
+	// so the new table needs its own job.
+	if newTableDesc.RowLevelTTL != nil {
+		newTableDesc.SetID(newID)
+		jobID, err := p.createRowLevelTTLJob(ctx, newTableDesc.TableDesc())
+		if err != nil {
+			return err
+		}
+		ttl := *newTableDesc.RowLevelTTL
+		ttl.JobID = jobID
+		newTableDesc.RowLevelTTL = &ttl
+	}
 	newTableDesc.ModificationTime = p.txn.CommitTimestamp()
-	key := sqlbase.NewTableKey(newTableDesc.ParentID, newTableDesc.Name).Key()
+	key := sqlbase.NewTableKey(newTableDesc.GetNameParentID(), newTableDesc.Name).Key()
 	if err := p.createDescriptorWithID(
 		ctx, key, newID, newTableDesc, p.ExtendedEvalContext().Settings); err != nil {
 		return err
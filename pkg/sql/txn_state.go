@@ -108,6 +108,15 @@ type txnState struct {
 	// activeSavepointName stores the name of the active savepoint,
 	// or is empty if no savepoint is active.
 	activeSavepointName tree.Name
+
+	// savepoints is the stack of the regular savepoints established in the
+	// current transaction, innermost last. The restart savepoint tracked by
+	// activeSavepointName is not part of it.
+	//
+	// While in the Aborted state, a non-empty stack means that the KV txn was
+	// kept open so that it can be resumed by a ROLLBACK TO SAVEPOINT; it is
+	// rolled back when the SQL txn finishes instead.
+	savepoints savepointStack
 }
 
 // txnType represents the type of a SQL transaction.
@@ -221,6 +230,7 @@ func (ts *txnState) resetForNewSQLTxn(
 
 	// Discard the old schemaChangers, if any.
 	ts.schemaChangers = schemaChangerCollection{}
+	ts.savepoints = nil
 }
 
 // finishSQLTxn finalizes a transaction's results and closes the root span for
@@ -257,6 +267,7 @@ func (ts *txnState) finishSQLTxn() {
 	ts.mu.txn = nil
 	ts.mu.Unlock()
 	ts.recordingThreshold = 0
+	ts.savepoints = nil
 }
 
 // finishExternalTxn is a stripped-down version of finishSQLTxn used by
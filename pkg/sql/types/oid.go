@@ -151,6 +151,7 @@ var familyToOid = map[Family]oid.Oid{
 	JsonFamily:           oid.T_jsonb,
 	TupleFamily:          oid.T_record,
 	BitFamily:            oid.T_bit,
+	EnumFamily:           oid.T_anyenum,
 	AnyFamily:            oid.T_anyelement,
 }
 
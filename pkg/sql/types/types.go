@@ -151,6 +151,17 @@ import (
 // When these types are themselves made into arrays, the Oids become T__int2vector and
 // T__oidvector, respectively.
 //
+// User-defined types
+// ------------------
+//
+// | Field           | Description                                             |
+// |-----------------|---------------------------------------------------------|
+// | Family          | EnumFamily                                              |
+// | Oid             | ID of the type descriptor + UserDefinedTypeOIDOffset    |
+// | UDTMetadata     | Name of the type, plus the labels of an enum type and   |
+// |                 | their encoded (physical) representations, and whether  |
+// |                 | each label can be written yet                           |
+//
 type T struct {
 	// InternalType should never be directly referenced outside this package. The
 	// only reason it is exported is because gogoproto panics when printing the
@@ -333,6 +344,12 @@ var (
 	AnyTuple = &T{InternalType: InternalType{
 		Family: TupleFamily, TupleContents: []T{*Any}, Oid: oid.T_record, Locale: &emptyLocale}}
 
+	// AnyEnum is a special type used only during static analysis as a wildcard
+	// type that matches any user-defined enum type. Execution-time values should
+	// never have this type.
+	AnyEnum = &T{InternalType: InternalType{
+		Family: EnumFamily, Oid: oid.T_anyenum, Locale: &emptyLocale}}
+
 	// AnyCollatedString is a special type used only during static analysis as a
 	// wildcard type that matches a collated string with any locale. Execution-
 	// time values should never have this type.
@@ -443,6 +460,11 @@ var (
 	emptyLocale = ""
 )
 
+// UserDefinedTypeOIDOffset is added to the ID of a type descriptor to form the
+// OID of the type that it defines. This keeps the OIDs of user-defined types
+// from colliding with the OIDs of the types that are predefined by Postgres.
+const UserDefinedTypeOIDOffset = 100000
+
 // MakeScalar constructs a new instance of a scalar type (i.e. not array or
 // tuple types) using the provided fields.
 func MakeScalar(family Family, o oid.Oid, precision, width int32, locale string) *T {
@@ -698,6 +720,40 @@ func MakeLabeledTuple(contents []T, labels []string) *T {
 	}}
 }
 
+// MakeEnum constructs a new instance of the EnumFamily type defined by the type
+// descriptor with the given ID. The logical and physical representations of the
+// enum's labels must be listed in sort order. readOnly marks the labels that are
+// still being added to the type; see EnumMemberIsReadOnly.
+func MakeEnum(
+	typeID uint32, name string, logical []string, physical [][]byte, readOnly []bool,
+) *T {
+	return &T{InternalType: InternalType{
+		Family: EnumFamily,
+		Oid:    oid.Oid(typeID + UserDefinedTypeOIDOffset),
+		Locale: &emptyLocale,
+		UDTMetadata: &UserDefinedTypeMetadata{
+			StableTypeID:            typeID,
+			Name:                    name,
+			LogicalRepresentations:  logical,
+			PhysicalRepresentations: physical,
+			IsMemberReadOnly:        readOnly,
+		},
+	}}
+}
+
+// MakeUserDefinedTypeReference constructs a placeholder for a user-defined type
+// that was referenced by name, but has not yet been resolved to the descriptor
+// that defines it. The parser produces such placeholders for type names that it
+// does not recognize; they must be resolved against the SQL catalog before use.
+// See IsUserDefinedTypeReference.
+func MakeUserDefinedTypeReference(name string) *T {
+	return &T{InternalType: InternalType{
+		Family:      EnumFamily,
+		Locale:      &emptyLocale,
+		UDTMetadata: &UserDefinedTypeMetadata{Name: name},
+	}}
+}
+
 // Family specifies a group of types that are compatible with one another. Types
 // in the same family can be compared, assigned, etc., but may differ from one
 // another in width, precision, locale, and other attributes. For example, it is
@@ -799,6 +855,71 @@ func (t *T) TupleLabels() []string {
 	return t.InternalType.TupleLabels
 }
 
+// StableTypeID returns the ID of the descriptor that defines a user-defined
+// type. It is 0 for types that are not user-defined.
+func (t *T) StableTypeID() uint32 {
+	if t.InternalType.UDTMetadata == nil {
+		return 0
+	}
+	return t.InternalType.UDTMetadata.StableTypeID
+}
+
+// EnumLogicalRepresentations returns the labels of an EnumFamily type, in sort
+// order. It is nil for other types.
+func (t *T) EnumLogicalRepresentations() []string {
+	if t.InternalType.UDTMetadata == nil {
+		return nil
+	}
+	return t.InternalType.UDTMetadata.LogicalRepresentations
+}
+
+// EnumPhysicalRepresentations returns the encoded form of the labels of an
+// EnumFamily type, in sort order. It is nil for other types.
+func (t *T) EnumPhysicalRepresentations() [][]byte {
+	if t.InternalType.UDTMetadata == nil {
+		return nil
+	}
+	return t.InternalType.UDTMetadata.PhysicalRepresentations
+}
+
+// EnumMemberIsReadOnly returns whether the label of an EnumFamily type at the
+// given position is still being added to the type. Values holding such a label
+// can be decoded, but must not be written, as nodes that have not yet learned
+// of the label would be unable to decode them.
+func (t *T) EnumMemberIsReadOnly(idx int) bool {
+	if t.InternalType.UDTMetadata == nil {
+		return false
+	}
+	readOnly := t.InternalType.UDTMetadata.IsMemberReadOnly
+	return idx < len(readOnly) && readOnly[idx]
+}
+
+// WithWritableEnumMembers returns a copy of an EnumFamily type in which all
+// labels can be written. See EnumMemberIsReadOnly.
+func (t *T) WithWritableEnumMembers() *T {
+	if t.InternalType.UDTMetadata == nil || t.InternalType.UDTMetadata.IsMemberReadOnly == nil {
+		return t
+	}
+	meta := *t.InternalType.UDTMetadata
+	meta.IsMemberReadOnly = nil
+	res := *t
+	res.InternalType.UDTMetadata = &meta
+	return &res
+}
+
+// UserDefined returns true if this is a user-defined type, or a reference to
+// one that has not yet been resolved.
+func (t *T) UserDefined() bool {
+	return t.InternalType.UDTMetadata != nil
+}
+
+// IsUserDefinedTypeReference returns true if this type is a placeholder for a
+// user-defined type that has not yet been resolved. See
+// MakeUserDefinedTypeReference.
+func (t *T) IsUserDefinedTypeReference() bool {
+	return t.UserDefined() && t.StableTypeID() == 0
+}
+
 // Name returns a single word description of the type that describes it
 // succinctly, but without all the details, such as width, locale, etc. The name
 // is sometimes the same as the name returned by SQLStandardName, but is more
@@ -830,6 +951,11 @@ func (t *T) Name() string {
 		return "date"
 	case DecimalFamily:
 		return "decimal"
+	case EnumFamily:
+		if t.UserDefined() {
+			return t.InternalType.UDTMetadata.Name
+		}
+		return "anyenum"
 	case FloatFamily:
 		switch t.Width() {
 		case 64:
@@ -903,6 +1029,9 @@ func (t *T) Name() string {
 //   int4[]       _int4
 //
 func (t *T) PGName() string {
+	if t.UserDefined() {
+		return t.Name()
+	}
 	name, ok := oid.TypeName[t.Oid()]
 	if ok {
 		return strings.ToLower(name)
@@ -983,6 +1112,8 @@ func (t *T) SQLStandardNameWithTypmod(haveTypmod bool, typmod int) string {
 			typmod&0xffff,
 		)
 
+	case EnumFamily:
+		return t.Name()
 	case FloatFamily:
 		switch t.Width() {
 		case 32:
@@ -1154,6 +1285,14 @@ func (t *T) SQLString() string {
 	case JsonFamily:
 		// Only binary JSON is currently supported.
 		return "JSONB"
+	case EnumFamily:
+		if t.UserDefined() {
+			// User-defined type names are identifiers, so they must not be
+			// upper-cased like the names of built-in types.
+			var buf bytes.Buffer
+			lex.EncodeRestrictedSQLIdent(&buf, t.Name(), lex.EncNoFlags)
+			return buf.String()
+		}
 	case TimestampFamily, TimestampTZFamily:
 		if t.Precision() != -1 {
 			return fmt.Sprintf("%s(%d)", strings.ToUpper(t.Name()), t.Precision())
@@ -1228,6 +1367,17 @@ func (t *T) Equivalent(other *T) bool {
 		if !t.ArrayContents().Equivalent(other.ArrayContents()) {
 			return false
 		}
+
+	case EnumFamily:
+		// The wildcard AnyEnum type is equivalent to any enum type. Otherwise,
+		// enum types are only equivalent if they are defined by the same type
+		// descriptor; the labels may differ if one of them is stale.
+		if t.Oid() == oid.T_anyenum || other.Oid() == oid.T_anyenum {
+			return true
+		}
+		if t.Oid() != other.Oid() {
+			return false
+		}
 	}
 
 	return true
@@ -1699,6 +1849,8 @@ func IsValidArrayElementType(t *T) (valid bool, issueNum int) {
 	switch t.Family() {
 	case JsonFamily:
 		return false, 23468
+	case EnumFamily:
+		return false, 0
 	default:
 		return true, 0
 	}
@@ -61,6 +61,11 @@ func (p *planner) Update(
 		return nil, pgerror.DangerousStatementf("UPDATE without WHERE clause")
 	}
 
+	if len(n.From) > 0 {
+		return nil, unimplemented.NewWithIssue(7841,
+			"UPDATE ... FROM is not supported by the heuristic planner")
+	}
+
 	// CTE analysis.
 	resetter, err := p.initWith(ctx, n.With)
 	if err != nil {
@@ -428,6 +433,15 @@ type updateRun struct {
 	// rows contains the accumulated result rows if rowsNeeded is set.
 	rows *rowcontainer.RowContainer
 
+	// numPassthrough is the number of columns at the end of the source rows
+	// that are returned as they are after the columns of the updated rows,
+	// for the columns of the FROM tables referenced by RETURNING.
+	numPassthrough int
+
+	// resultRowBuffer is used to build the result rows when there are
+	// passthrough columns.
+	resultRowBuffer tree.Datums
+
 	// traceKV caches the current KV tracing flag.
 	traceKV bool
 
@@ -679,7 +693,7 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 				return err
 			}
 		} else {
-			checkVals := sourceVals[len(u.run.tu.ru.FetchCols)+len(u.run.tu.ru.UpdateCols):]
+			checkVals := sourceVals[len(u.run.tu.ru.FetchCols)+len(u.run.tu.ru.UpdateCols) : len(sourceVals)-u.run.numPassthrough]
 			if err := u.run.checkHelper.CheckInput(checkVals); err != nil {
 				return err
 			}
@@ -700,8 +714,15 @@ func (u *updateNode) processSourceRow(params runParams, sourceVals tree.Datums)
 		// visible. We do not want them to be available for RETURNING.
 		//
 		// MakeUpdater guarantees that the first columns of the new values
-		// are those specified u.columns.
-		resultValues := newValues[:len(u.columns)]
+		// are those specified u.columns, save for the passthrough columns
+		// that follow them.
+		resultValues := newValues[:len(u.columns)-u.run.numPassthrough]
+		if u.run.numPassthrough > 0 {
+			u.run.resultRowBuffer = append(u.run.resultRowBuffer[:0], resultValues...)
+			u.run.resultRowBuffer = append(u.run.resultRowBuffer,
+				sourceVals[len(sourceVals)-u.run.numPassthrough:]...)
+			resultValues = u.run.resultRowBuffer
+		}
 		if _, err := u.run.rows.AddRow(params.ctx, resultValues); err != nil {
 			return err
 		}
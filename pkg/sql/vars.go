@@ -578,7 +578,10 @@ var varGen = map[string]sessionVar{
 		},
 		Set: func(_ context.Context, m *sessionDataMutator, s string) error {
 			paths := strings.Split(s, ",")
-			m.SetSearchPath(sessiondata.MakeSearchPath(paths))
+			// The temporary schema of the session is not part of the value of the
+			// variable, and it survives changes to it.
+			tempSchemaName := m.data.SearchPath.GetTemporarySchemaName()
+			m.SetSearchPath(sessiondata.MakeSearchPath(paths).WithTemporarySchemaName(tempSchemaName))
 			return nil
 		},
 		Get: func(evalCtx *extendedEvalContext) string {
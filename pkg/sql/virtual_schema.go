@@ -118,6 +118,7 @@ func (t virtualSchemaTable) initVirtualTableDesc(
 		st,
 		create,
 		0, /* parentID */
+		0, /* temporarySchemaID */
 		id,
 		hlc.Timestamp{}, /* creationTime */
 		publicSelectPrivileges,
@@ -43,6 +43,10 @@ func declareKeysClearRange(
 	// We look up the range descriptor key to check whether the span
 	// is equal to the entire range for fast stats updating.
 	spans.Add(spanset.SpanReadOnly, roachpb.Span{Key: keys.RangeDescriptorKey(desc.StartKey)})
+	// Clearing the entire range bumps its GC threshold. Whether the span is
+	// the entire range is only known for sure at evaluation time, since the
+	// descriptor may change in the meantime, so always declare the key.
+	spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: keys.RangeLastGCKey(header.RangeID)})
 }
 
 // ClearRange wipes all MVCC versions of keys covered by the specified
@@ -52,6 +56,17 @@ func declareKeysClearRange(
 // spans consisting of user data that we know is not being written to
 // or queried any more, such as after a DROP or TRUNCATE table, or
 // DROP index.
+//
+// Unlike the range keys of a GCRequest, which may only cover keys whose
+// every version is garbage at the GC threshold, ClearRange removes live
+// values too, along with the history below them. To keep reads from
+// observing that history as if it had never been written, clearing the
+// entire range forwards its GC threshold to the timestamp of the request,
+// so that reads at or below it fail instead of returning nothing. The GC
+// threshold applies to the whole range, so it is left alone when only
+// part of the range is cleared; callers must then only clear spans that
+// no read can observe at any timestamp, which the schema changer ensures
+// by waiting until the GC TTL of the dropped table or index has elapsed.
 func ClearRange(
 	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
 ) (result.Result, error) {
@@ -73,6 +88,19 @@ func ClearRange(
 	}
 	cArgs.Stats.Subtract(statsDelta)
 
+	// If the entire range is cleared, forward its GC threshold.
+	desc := cArgs.EvalCtx.Desc()
+	if desc.StartKey.Equal(from.Key) && desc.EndKey.Equal(to.Key) {
+		newThreshold := cArgs.EvalCtx.GetGCThreshold()
+		if newThreshold.Forward(cArgs.Header.Timestamp) {
+			stateLoader := MakeStateLoader(cArgs.EvalCtx)
+			if err := stateLoader.SetGCThreshold(ctx, batch, cArgs.Stats, &newThreshold); err != nil {
+				return result.Result{}, err
+			}
+			pd.Replicated.State = &storagepb.ReplicaState{GCThreshold: &newThreshold}
+		}
+	}
+
 	// If the total size of data to be cleared is less than
 	// clearRangeBytesThreshold, clear the individual values manually,
 	// instead of using a range tombstone (inefficient for small ranges).
@@ -150,3 +150,94 @@ func TestCmdClearRangeBytesThreshold(t *testing.T) {
 		})
 	}
 }
+
+// TestCmdClearRangeGCThreshold verifies that clearing an entire range
+// forwards its GC threshold to the timestamp of the request, and that
+// clearing only part of it leaves the threshold alone.
+func TestCmdClearRangeGCThreshold(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	startKey := roachpb.Key("0000")
+	endKey := roachpb.Key("9999")
+	desc := roachpb.RangeDescriptor{
+		RangeID:  99,
+		StartKey: roachpb.RKey(startKey),
+		EndKey:   roachpb.RKey(endKey),
+	}
+	oldThreshold := hlc.Timestamp{WallTime: 1}
+	ts := hlc.Timestamp{WallTime: 10}
+
+	tests := []struct {
+		name         string
+		endKey       roachpb.Key
+		expThreshold *hlc.Timestamp
+	}{
+		{name: "entire range", endKey: endKey, expThreshold: &ts},
+		{name: "part of range", endKey: roachpb.Key("5000"), expThreshold: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+			defer eng.Close()
+
+			var stats enginepb.MVCCStats
+			var value roachpb.Value
+			value.SetString("value")
+			if err := engine.MVCCPut(
+				ctx, eng, &stats, roachpb.Key("1000"), hlc.Timestamp{WallTime: 5}, value, nil,
+			); err != nil {
+				t.Fatal(err)
+			}
+
+			batch := eng.NewBatch()
+			defer batch.Close()
+
+			var h roachpb.Header
+			h.RangeID = desc.RangeID
+			h.Timestamp = ts
+
+			cArgs := CommandArgs{Header: h}
+			cArgs.EvalCtx = &mockEvalCtx{
+				desc:        &desc,
+				clock:       hlc.NewClock(hlc.UnixNano, time.Nanosecond),
+				stats:       stats,
+				gcThreshold: oldThreshold,
+			}
+			cArgs.Args = &roachpb.ClearRangeRequest{
+				RequestHeader: roachpb.RequestHeader{
+					Key:    startKey,
+					EndKey: test.endKey,
+				},
+			}
+			cArgs.Stats = &enginepb.MVCCStats{}
+
+			res, err := ClearRange(ctx, batch, cArgs, &roachpb.ClearRangeResponse{})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var threshold *hlc.Timestamp
+			if res.Replicated.State != nil {
+				threshold = res.Replicated.State.GCThreshold
+			}
+			if test.expThreshold == nil {
+				if threshold != nil {
+					t.Fatalf("expected GC threshold to be left alone, found %s", threshold)
+				}
+				return
+			}
+			if threshold == nil || *threshold != *test.expThreshold {
+				t.Fatalf("expected GC threshold %s, found %v", test.expThreshold, threshold)
+			}
+			persisted, err := MakeStateLoader(cArgs.EvalCtx).LoadGCThreshold(ctx, batch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if *persisted != *test.expThreshold {
+				t.Fatalf("expected persisted GC threshold %s, found %s", test.expThreshold, persisted)
+			}
+		})
+	}
+}
@@ -35,6 +35,9 @@ func declareKeysGC(
 	for _, key := range gcr.Keys {
 		spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: key.Key})
 	}
+	for _, rk := range gcr.RangeKeys {
+		spans.Add(spanset.SpanReadWrite, roachpb.Span{Key: rk.StartKey, EndKey: rk.EndKey})
+	}
 	// Be smart here about blocking on the threshold keys. The GC queue can send an empty
 	// request first to bump the thresholds, and then another one that actually does work
 	// but can avoid declaring these keys below.
@@ -57,8 +60,10 @@ func declareKeysGC(
 
 // GC iterates through the list of keys to garbage collect
 // specified in the arguments. MVCCGarbageCollect is invoked on each
-// listed key along with the expiration timestamp. The GC metadata
-// specified in the args is persisted after GC.
+// listed key along with the expiration timestamp. The spans listed as
+// range keys are removed using MVCCGarbageCollectRange, which writes a
+// range deletion. The GC metadata specified in the args is persisted
+// after GC.
 func GC(
 	ctx context.Context, batch engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
 ) (result.Result, error) {
@@ -94,6 +99,41 @@ func GC(
 		newThreshold.Forward(args.Threshold)
 	}
 
+	var pd result.Result
+
+	// Garbage collect the specified spans with range deletions, and suggest a
+	// compaction for each of them to reclaim the space quickly. As for keys,
+	// spans which are not contained in this range are dropped. The versions
+	// in the spans are only garbage below the GC threshold, which is checked
+	// against the threshold in effect after this request.
+	gcThreshold := cArgs.EvalCtx.GetGCThreshold()
+	gcThreshold.Forward(newThreshold)
+	desc := cArgs.EvalCtx.Desc()
+	for _, rk := range args.RangeKeys {
+		if !desc.ContainsKeyRange(roachpb.RKey(rk.StartKey), roachpb.RKey(rk.EndKey)) {
+			continue
+		}
+		ms, err := engine.MVCCGarbageCollectRange(
+			ctx, batch, rk.StartKey, rk.EndKey, gcThreshold, h.Timestamp,
+		)
+		if err != nil {
+			return result.Result{}, err
+		}
+		if ms.Total() == 0 {
+			continue
+		}
+		cArgs.Stats.Subtract(ms)
+		pd.Replicated.SuggestedCompactions = append(pd.Replicated.SuggestedCompactions,
+			storagepb.SuggestedCompaction{
+				StartKey: rk.StartKey,
+				EndKey:   rk.EndKey,
+				Compaction: storagepb.Compaction{
+					Bytes:            ms.Total(),
+					SuggestedAtNanos: h.Timestamp.WallTime,
+				},
+			})
+	}
+
 	var newTxnSpanGCThreshold hlc.Timestamp
 	if args.TxnSpanGCThreshold != (hlc.Timestamp{}) {
 		oldTxnSpanGCThreshold := cArgs.EvalCtx.GetTxnSpanGCThreshold()
@@ -101,7 +141,6 @@ func GC(
 		newTxnSpanGCThreshold.Forward(args.TxnSpanGCThreshold)
 	}
 
-	var pd result.Result
 	stateLoader := MakeStateLoader(cArgs.EvalCtx)
 
 	// Don't write these keys unless we have to. We also don't declare these
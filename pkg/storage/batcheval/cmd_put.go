@@ -17,6 +17,7 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/pkg/errors"
 )
 
 func init() {
@@ -44,6 +45,12 @@ func Put(
 			defer batch.Close()
 		}
 	}
+	if args.LockOnly {
+		if args.Value.IsPresent() {
+			return result.Result{}, errors.Errorf("lock-only put cannot have a value")
+		}
+		return result.Result{}, engine.MVCCLock(ctx, batch, ms, args.Key, ts, h.Txn)
+	}
 	if args.Blind {
 		return result.Result{}, engine.MVCCBlindPut(ctx, batch, ms, args.Key, ts, args.Value, h.Txn)
 	}
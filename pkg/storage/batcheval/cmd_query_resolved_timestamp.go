@@ -0,0 +1,42 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/batcheval/result"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+)
+
+func init() {
+	RegisterCommand(roachpb.QueryResolvedTimestamp, declareKeysQueryResolvedTimestamp, QueryResolvedTimestamp)
+}
+
+func declareKeysQueryResolvedTimestamp(
+	*roachpb.RangeDescriptor, roachpb.Header, roachpb.Request, *spanset.SpanSet,
+) {
+	// Intentionally declare no keys, as QueryResolvedTimestamp does not read
+	// any keys and does not need to be serialized with any other commands.
+}
+
+// QueryResolvedTimestamp returns the closed timestamp of the range known to
+// the evaluating replica, which may be a follower. Reads at or below this
+// timestamp can be served by the replica.
+func QueryResolvedTimestamp(
+	ctx context.Context, _ engine.ReadWriter, cArgs CommandArgs, resp roachpb.Response,
+) (result.Result, error) {
+	reply := resp.(*roachpb.QueryResolvedTimestampResponse)
+	reply.ResolvedTS = cArgs.EvalCtx.GetClosedTimestamp(ctx)
+	return result.Result{}, nil
+}
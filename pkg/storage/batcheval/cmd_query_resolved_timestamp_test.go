@@ -0,0 +1,54 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package batcheval
+
+import (
+	"context"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/spanset"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestQueryResolvedTimestamp verifies that QueryResolvedTimestamp returns the
+// closed timestamp of the evaluating replica without declaring any keys.
+func TestQueryResolvedTimestamp(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	req := &roachpb.QueryResolvedTimestampRequest{
+		RequestHeader: roachpb.RequestHeader{Key: roachpb.Key("a"), EndKey: roachpb.Key("z")},
+	}
+	desc := roachpb.RangeDescriptor{
+		StartKey: roachpb.RKeyMin,
+		EndKey:   roachpb.RKeyMax,
+	}
+
+	var spans spanset.SpanSet
+	declareKeysQueryResolvedTimestamp(&desc, roachpb.Header{}, req, &spans)
+	if n := spans.Len(); n != 0 {
+		t.Fatalf("expected no declared spans, found %d", n)
+	}
+
+	closedTS := hlc.Timestamp{WallTime: 123, Logical: 4}
+	cArgs := CommandArgs{
+		EvalCtx: &mockEvalCtx{desc: &desc, closedTS: closedTS},
+		Args:    req,
+	}
+	var resp roachpb.QueryResolvedTimestampResponse
+	if _, err := QueryResolvedTimestamp(context.Background(), nil, cArgs, &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.ResolvedTS != closedTS {
+		t.Fatalf("expected resolved timestamp %s, found %s", closedTS, resp.ResolvedTS)
+	}
+}
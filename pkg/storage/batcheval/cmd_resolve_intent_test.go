@@ -39,6 +39,7 @@ type mockEvalCtx struct {
 	qps              float64
 	abortSpan        *abortspan.AbortSpan
 	gcThreshold      hlc.Timestamp
+	closedTS         hlc.Timestamp
 	term, firstIndex uint64
 	canCreateTxnFn   func() (bool, hlc.Timestamp, roachpb.TransactionAbortedReason)
 }
@@ -120,6 +121,9 @@ func (m *mockEvalCtx) GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp,
 func (m *mockEvalCtx) GetLease() (roachpb.Lease, roachpb.Lease) {
 	panic("unimplemented")
 }
+func (m *mockEvalCtx) GetClosedTimestamp(context.Context) hlc.Timestamp {
+	return m.closedTS
+}
 
 func TestDeclareKeysResolveIntent(t *testing.T) {
 	defer leaktest.AfterTest(t)()
@@ -94,4 +94,8 @@ type EvalContext interface {
 	GetTxnSpanGCThreshold() hlc.Timestamp
 	GetLastReplicaGCTimestamp(context.Context) (hlc.Timestamp, error)
 	GetLease() (roachpb.Lease, roachpb.Lease)
+
+	// GetClosedTimestamp returns the closed timestamp of the range known to
+	// the replica, below which it can serve reads without holding the lease.
+	GetClosedTimestamp(context.Context) hlc.Timestamp
 }
@@ -12,6 +12,7 @@ package closedts
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/settings"
@@ -36,3 +37,41 @@ var CloseFraction = settings.RegisterValidatedFloatSetting(
 		}
 		return nil
 	})
+
+// FollowerReadMultiple is the multiple of CloseFraction intervals which, on
+// top of TargetDuration, determines how old a timestamp needs to be for reads
+// at it to very likely be servable by any replica. It is a hidden setting.
+var FollowerReadMultiple = func() *settings.FloatSetting {
+	s := settings.RegisterValidatedFloatSetting(
+		"kv.follower_read.target_multiple",
+		"if above 1, encourages the distsender to perform a read against the "+
+			"closest replica if a request is older than kv.closed_timestamp.target_duration"+
+			" * (1 + kv.closed_timestamp.close_fraction * this) less a clock uncertainty "+
+			"interval. This value also is used to create follower_timestamp().",
+		3,
+		func(v float64) error {
+			if v < 1 {
+				return fmt.Errorf("%v is not >= 1", v)
+			}
+			return nil
+		},
+	)
+	s.SetSensitive()
+	return s
+}()
+
+// FollowerReadLag returns how far in the past a timestamp needs to be for reads
+// at it to very likely be servable by any replica. Closed timestamps trail the
+// present by TargetDuration and are advanced every CloseFraction of it;
+// FollowerReadMultiple such intervals are added on top of the target to give
+// closed timestamp updates time to reach followers.
+//
+// The lag is derived from the settings alone, not from the closed timestamps
+// that the replicas actually reached, so a replica can lag further behind
+// and fail to serve a read at a timestamp this old.
+func FollowerReadLag(sv *settings.Values) time.Duration {
+	targetDuration := TargetDuration.Get(sv)
+	closeFraction := CloseFraction.Get(sv)
+	closeMultiple := FollowerReadMultiple.Get(sv)
+	return time.Duration(float64(targetDuration) * (1 + closeFraction*closeMultiple))
+}
@@ -277,6 +277,14 @@ func (b *RocksDBBatchBuilder) SingleClear(key MVCCKey) {
 	b.repr[pos] = byte(BatchTypeSingleDeletion)
 }
 
+// clearRange removes all of the keys in the range [start, end).
+//
+// It is safe to modify the contents of the arguments after clearRange
+// returns.
+func (b *RocksDBBatchBuilder) clearRange(start, end MVCCKey) {
+	b.encodeKeyValue(start, EncodeKey(end), BatchTypeRangeDeletion)
+}
+
 // LogData adds a blob of log data to the batch. It will be written to the WAL,
 // but otherwise uninterpreted by RocksDB.
 //
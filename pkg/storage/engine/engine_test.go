@@ -27,7 +27,6 @@ import (
 	"github.com/cockroachdb/cockroach/pkg/util/hlc"
 	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
 	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
-	"github.com/cockroachdb/cockroach/pkg/util/stop"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
@@ -53,14 +52,21 @@ var (
 	inMemAttrs = roachpb.Attributes{Attrs: []string{"mem"}}
 )
 
+// engineTypes are the types of engine that the engine and MVCC tests run
+// against.
+var engineTypes = []enginepb.EngineType{enginepb.EngineTypeRocksDB, enginepb.EngineTypeGoLSM}
+
 // runWithAllEngines creates a new engine of each supported type and
-// invokes the supplied test func with each instance.
+// invokes the supplied test func with each instance, in a subtest named
+// after the type of the engine.
 func runWithAllEngines(test func(e Engine, t *testing.T), t *testing.T) {
-	stopper := stop.NewStopper()
-	defer stopper.Stop(context.TODO())
-	inMem := NewInMem(inMemAttrs, testCacheSize)
-	stopper.AddCloser(inMem)
-	test(inMem, t)
+	for _, typ := range engineTypes {
+		t.Run(typ.String(), func(t *testing.T) {
+			e := NewInMemEngine(typ, inMemAttrs, testCacheSize)
+			defer e.Close()
+			test(e, t)
+		})
+	}
 }
 
 // TestEngineBatchCommit writes a batch containing 10K rows (all the
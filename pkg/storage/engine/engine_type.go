@@ -0,0 +1,92 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+)
+
+const (
+	engineMarkerFilename     = "STORAGE_ENGINE"
+	engineMarkerFilenameTemp = "STORAGE_ENGINE_TEMP"
+)
+
+// NewInMemEngine allocates and returns a new, opened in-memory engine of the
+// given type. The caller must call the engine's Close method when the engine
+// is no longer needed.
+func NewInMemEngine(typ enginepb.EngineType, attrs roachpb.Attributes, cacheSize int64) Engine {
+	switch typ {
+	case enginepb.EngineTypeGoLSM:
+		return NewInMemGoLSM(attrs, cacheSize)
+	default:
+		return NewInMem(attrs, cacheSize)
+	}
+}
+
+// getEngineMarker returns the storage engine that created the store in the
+// passed in directory, as recorded by its engine marker file. Stores created
+// before the marker was introduced only have a version file, which is only
+// written by RocksDB. The second return value is false if the directory
+// holds neither file, that is if the store is new.
+func getEngineMarker(dir string) (enginepb.EngineType, bool, error) {
+	filename := filepath.Join(dir, engineMarkerFilename)
+	b, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return 0, false, err
+		}
+		if _, err := os.Stat(getVersionFilename(dir)); err != nil {
+			if os.IsNotExist(err) {
+				return 0, false, nil
+			}
+			return 0, false, err
+		}
+		return enginepb.EngineTypeRocksDB, true, nil
+	}
+	var typ enginepb.EngineType
+	if err := typ.Set(strings.TrimSpace(string(b))); err != nil {
+		return 0, false, fmt.Errorf("engine marker file %s is not formatted correctly; %s", filename, err)
+	}
+	return typ, true, nil
+}
+
+// checkEngineMarker returns an error if the store in the passed in directory
+// was created by a storage engine other than typ.
+func checkEngineMarker(dir string, typ enginepb.EngineType) error {
+	existing, ok, err := getEngineMarker(dir)
+	if err != nil || !ok || existing == typ {
+		return err
+	}
+	return fmt.Errorf("store at %q was created with the %s storage engine and can't be "+
+		"opened with %s; specify engine=%s in its --store flag", dir, &existing, &typ, &existing)
+}
+
+// writeEngineMarker records in the passed in directory that the store in it
+// uses the storage engine typ, unless a marker file already exists.
+func writeEngineMarker(dir string, typ enginepb.EngineType) error {
+	filename := filepath.Join(dir, engineMarkerFilename)
+	if _, err := os.Stat(filename); err == nil || !os.IsNotExist(err) {
+		return err
+	}
+	// First write to a temp file, then atomically rename it.
+	tempFilename := filepath.Join(dir, engineMarkerFilenameTemp)
+	if err := ioutil.WriteFile(tempFilename, []byte(typ.String()+"\n"), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tempFilename, filename)
+}
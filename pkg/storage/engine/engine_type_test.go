@@ -0,0 +1,96 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+)
+
+// TestEngineMarker verifies that stores record the engine that created them
+// and can't be opened with another one.
+func TestEngineMarker(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, typ := range engineTypes {
+		t.Run(typ.String(), func(t *testing.T) {
+			dir, cleanup := testutils.TempDir(t)
+			defer cleanup()
+
+			st := cluster.MakeTestingClusterSettings()
+			open := func(typ enginepb.EngineType) error {
+				var eng Engine
+				var err error
+				switch typ {
+				case enginepb.EngineTypeGoLSM:
+					eng, err = NewGoLSM(GoLSMConfig{Dir: dir, Settings: st})
+				default:
+					eng, err = NewRocksDB(RocksDBConfig{Dir: dir, Settings: st}, RocksDBCache{})
+				}
+				if err == nil {
+					eng.Close()
+				}
+				return err
+			}
+
+			if err := open(typ); err != nil {
+				t.Fatal(err)
+			}
+			existing, ok, err := getEngineMarker(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !ok || existing != typ {
+				t.Fatalf("expected a %s marker, got %s (found: %t)", &typ, &existing, ok)
+			}
+
+			for _, other := range engineTypes {
+				err := open(other)
+				if other == typ {
+					if err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				if !testutils.IsError(err, "was created with the "+typ.String()+" storage engine") {
+					t.Fatalf("expected a wrong engine error, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+// TestEngineMarkerVersionFile verifies that stores created before the engine
+// marker file existed are recognized as RocksDB stores by their version file.
+func TestEngineMarkerVersionFile(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, cleanup := testutils.TempDir(t)
+	defer cleanup()
+
+	if _, ok, err := getEngineMarker(dir); err != nil || ok {
+		t.Fatalf("expected no marker in an empty directory, got %t, %v", ok, err)
+	}
+	if err := writeVersionFile(dir, versionBeta20160331); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEngineMarker(dir, enginepb.EngineTypeRocksDB); err != nil {
+		t.Fatal(err)
+	}
+	if err := checkEngineMarker(dir, enginepb.EngineTypeGoLSM); !testutils.IsError(err,
+		"was created with the rocksdb storage engine") {
+		t.Fatalf("expected a wrong engine error, got %v", err)
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package enginepb
+
+import "fmt"
+
+// EngineType selects the implementation of the Engine interface used by a
+// store.
+type EngineType int
+
+const (
+	// EngineTypeRocksDB is the cgo RocksDB engine. It is the default.
+	EngineTypeRocksDB EngineType = iota
+	// EngineTypeGoLSM is the pure-Go golsm engine.
+	EngineTypeGoLSM
+)
+
+// Type implements the pflag.Value interface.
+func (e *EngineType) Type() string { return "string" }
+
+// String implements the pflag.Value interface.
+func (e *EngineType) String() string {
+	switch *e {
+	case EngineTypeRocksDB:
+		return "rocksdb"
+	case EngineTypeGoLSM:
+		return "golsm"
+	}
+	return ""
+}
+
+// Set implements the pflag.Value interface.
+func (e *EngineType) Set(value string) error {
+	switch value {
+	case "rocksdb":
+		*e = EngineTypeRocksDB
+	case "golsm":
+		*e = EngineTypeGoLSM
+	default:
+		return fmt.Errorf("invalid storage engine: %s "+
+			"(possible values: rocksdb, golsm)", value)
+	}
+	return nil
+}
@@ -159,6 +159,12 @@ func (meta MVCCMetadata) IsInline() bool {
 	return meta.RawBytes != nil
 }
 
+// IsLockOnly returns true if the metadata describes a lock-only intent, which
+// is removed instead of being committed when it is resolved.
+func (meta MVCCMetadata) IsLockOnly() bool {
+	return meta.LockOnly != nil && *meta.LockOnly
+}
+
 // AddToIntentHistory adds the sequence and value to the intent history.
 func (meta *MVCCMetadata) AddToIntentHistory(seq TxnSeq, val []byte) {
 	meta.IntentHistory = append(meta.IntentHistory,
@@ -166,15 +172,26 @@ func (meta *MVCCMetadata) AddToIntentHistory(seq TxnSeq, val []byte) {
 }
 
 // GetPrevIntentSeq goes through the intent history and finds the previous
-// intent's sequence number given the current sequence.
-func (meta *MVCCMetadata) GetPrevIntentSeq(seq TxnSeq) (TxnSeq, bool) {
-	index := sort.Search(len(meta.IntentHistory), func(i int) bool {
-		return meta.IntentHistory[i].Sequence >= seq
-	})
-	if index > 0 && index < len(meta.IntentHistory) {
-		return meta.IntentHistory[index-1].Sequence, true
+// intent's sequence number given the current sequence. Sequence numbers
+// contained in the ignored ranges are skipped over.
+func (meta *MVCCMetadata) GetPrevIntentSeq(
+	seq TxnSeq, ignored []IgnoredSeqNumRange,
+) (TxnSeq, bool) {
+	end := len(meta.IntentHistory)
+	for {
+		index := sort.Search(end, func(i int) bool {
+			return meta.IntentHistory[i].Sequence >= seq
+		})
+		if index == 0 {
+			return 0, false
+		}
+		prevSeq := meta.IntentHistory[index-1].Sequence
+		if !TxnSeqIsIgnored(prevSeq, ignored) {
+			return prevSeq, true
+		}
+		// The entry was rolled back. Search again below it.
+		end = index - 1
 	}
-	return 0, false
 }
 
 // GetIntentValue goes through the intent history and finds the value
@@ -40,3 +40,17 @@ func (op *MVCCLogicalOp) MustSetValue(value interface{}) {
 		panic(fmt.Sprintf("%T excludes %T", op, value))
 	}
 }
+
+// TxnSeqIsIgnored returns true iff the sequence number is contained in one of
+// the ignored seqnum ranges. The ranges must be sorted and non-overlapping.
+func TxnSeqIsIgnored(seq TxnSeq, ignored []IgnoredSeqNumRange) bool {
+	// Ranges are sorted, so look for the last one that starts at or before
+	// seq.
+	for i := len(ignored) - 1; i >= 0; i-- {
+		if seq < ignored[i].Start {
+			continue
+		}
+		return seq <= ignored[i].End
+	}
+	return false
+}
@@ -0,0 +1,726 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/settings/cluster"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/golsm"
+	"github.com/cockroachdb/cockroach/pkg/util/log"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/golang/leveldb/table"
+	"github.com/pkg/errors"
+)
+
+// goLSMMemDir is the directory of the DB of in-memory GoLSM instances.
+const goLSMMemDir = "/golsm"
+
+// GoLSMConfig holds all configuration parameters and knobs used in setting up
+// a new GoLSM instance.
+type GoLSMConfig struct {
+	Attrs roachpb.Attributes
+	// Dir is the data directory for this store. An empty Dir creates an
+	// in-memory instance.
+	Dir string
+	// MaxSizeBytes is used for calculating free space and making rebalancing
+	// decisions. Zero indicates that there is no maximum size.
+	MaxSizeBytes int64
+	// CacheSize is the size of the block cache in bytes.
+	CacheSize int64
+	// Settings instance for cluster-wide knobs.
+	Settings *cluster.Settings
+}
+
+// GoLSM is an Engine backed by golsm, a log-structured merge-tree written in
+// Go. Its keys and batch representations are those of the RocksDB engine.
+type GoLSM struct {
+	cfg  GoLSMConfig
+	db   *golsm.DB
+	opts *golsm.Options
+	fs   golsm.FS
+	// auxDir is used for storing auxiliary files. Ideally it is a subdirectory
+	// of Dir.
+	auxDir string
+	closed bool
+}
+
+var _ Engine = &GoLSM{}
+var _ WithSSTables = &GoLSM{}
+
+// goLSMLogger adapts the log package to golsm.Logger.
+type goLSMLogger struct{}
+
+func (goLSMLogger) Infof(format string, args ...interface{}) {
+	log.Infof(context.TODO(), format, args...)
+}
+
+// NewGoLSM allocates and returns a new GoLSM object.
+func NewGoLSM(cfg GoLSMConfig) (*GoLSM, error) {
+	g := &GoLSM{cfg: cfg}
+	g.opts = &golsm.Options{
+		Compare:   goLSMCompare,
+		Merger:    goLSMMerger{},
+		Logger:    goLSMLogger{},
+		CacheSize: cfg.CacheSize,
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		// The DB lives in memory, but the auxiliary directory is created on
+		// disk like for in-memory RocksDB instances, since some of its users
+		// create subdirectories with the os package.
+		g.opts.FS = golsm.NewMemFS()
+		dir = goLSMMemDir
+		auxDir, err := ioutil.TempDir(os.TempDir(), "cockroach-auxiliary")
+		if err != nil {
+			return nil, err
+		}
+		g.auxDir = auxDir
+	} else {
+		if err := checkEngineMarker(dir, enginepb.EngineTypeGoLSM); err != nil {
+			return nil, err
+		}
+		g.opts.FS = golsm.DefaultFS
+		g.auxDir = filepath.Join(dir, "auxiliary")
+	}
+	g.fs = g.opts.FS
+	if err := g.fs.MkdirAll(g.auxDir, 0755); err != nil {
+		return nil, err
+	}
+
+	db, err := golsm.Open(dir, g.opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not open golsm instance at %q", dir)
+	}
+	g.db = db
+	if cfg.Dir != "" {
+		if err := writeEngineMarker(dir, enginepb.EngineTypeGoLSM); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+	return g, nil
+}
+
+// NewInMemGoLSM allocates and returns a new, opened in-memory GoLSM engine.
+// The caller must call the engine's Close method when the engine is no longer
+// needed.
+func NewInMemGoLSM(attrs roachpb.Attributes, cacheSize int64) *GoLSM {
+	g, err := NewGoLSM(GoLSMConfig{
+		Attrs: attrs,
+		// TODO(bdarnell): The hard-coded 512 MiB is wrong; see
+		// https://github.com/cockroachdb/cockroach/issues/16750
+		MaxSizeBytes: 512 << 20,
+		CacheSize:    cacheSize,
+	})
+	if err != nil {
+		panic(err)
+	}
+	return g
+}
+
+// goLSMCompare orders encoded MVCC keys. It matches the comparator of the
+// RocksDB engine (engine/db.cc:DBComparator): keys are ordered by their key
+// part, then the metadata key sorts first, followed by the versions of the
+// key from newest to oldest.
+func goLSMCompare(a, b []byte) int {
+	keyA, tsA, okA := enginepb.SplitMVCCKey(a)
+	keyB, tsB, okB := enginepb.SplitMVCCKey(b)
+	if !okA || !okB {
+		// This should never happen unless there is some sort of corruption of
+		// the keys.
+		return bytes.Compare(a, b)
+	}
+	if c := bytes.Compare(keyA, keyB); c != 0 {
+		return c
+	}
+	if len(tsA) == 0 {
+		if len(tsB) == 0 {
+			return 0
+		}
+		return -1
+	} else if len(tsB) == 0 {
+		return 1
+	}
+	return bytes.Compare(tsB, tsA)
+}
+
+// String formatter.
+func (g *GoLSM) String() string {
+	dir := g.cfg.Dir
+	if dir == "" {
+		dir = "<in-mem>"
+	}
+	attrs := g.Attrs().String()
+	if attrs == "" {
+		attrs = "<no-attributes>"
+	}
+	return fmt.Sprintf("%s=%s", attrs, dir)
+}
+
+// Close implements the Engine interface.
+func (g *GoLSM) Close() {
+	if g.closed {
+		log.Errorf(context.TODO(), "closing unopened golsm instance")
+		return
+	}
+	g.closed = true
+	if g.cfg.Dir == "" {
+		// Remove the temporary directory when the engine is in-memory.
+		if err := os.RemoveAll(g.auxDir); err != nil {
+			log.Warning(context.TODO(), err)
+		}
+	} else {
+		log.Infof(context.TODO(), "closing golsm instance at %q", g.cfg.Dir)
+	}
+	if err := g.db.Close(); err != nil {
+		panic(err)
+	}
+}
+
+// Closed implements the Engine interface.
+func (g *GoLSM) Closed() bool {
+	return g.closed
+}
+
+// Get implements the Engine interface.
+func (g *GoLSM) Get(key MVCCKey) ([]byte, error) {
+	return goLSMGet(g.db, key)
+}
+
+// GetProto implements the Engine interface.
+func (g *GoLSM) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return goLSMGetProto(g.db, key, msg)
+}
+
+// Iterate implements the Engine interface.
+func (g *GoLSM) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return goLSMIterate(g.db, start, end, f)
+}
+
+// NewIterator implements the Engine interface.
+func (g *GoLSM) NewIterator(opts IterOptions) Iterator {
+	return newGoLSMIterator(g.db, opts, nil)
+}
+
+// ApplyBatchRepr implements the Engine interface.
+func (g *GoLSM) ApplyBatchRepr(repr []byte, sync bool) error {
+	b := g.db.NewBatch()
+	if err := goLSMApplyBatchRepr(b, repr); err != nil {
+		return err
+	}
+	return g.db.Apply(b, sync)
+}
+
+// Clear implements the Engine interface.
+func (g *GoLSM) Clear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return g.db.Delete(EncodeKey(key), false /* sync */)
+}
+
+// SingleClear implements the Engine interface.
+func (g *GoLSM) SingleClear(key MVCCKey) error {
+	return g.Clear(key)
+}
+
+// ClearRange implements the Engine interface.
+func (g *GoLSM) ClearRange(start, end MVCCKey) error {
+	return g.db.DeleteRange(EncodeKey(start), EncodeKey(end), false /* sync */)
+}
+
+// ClearIterRange implements the Engine interface.
+func (g *GoLSM) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	b := g.db.NewBatch()
+	if err := goLSMClearIterRange(iter, start, end, func(key MVCCKey) error {
+		b.Delete(EncodeKey(key))
+		return nil
+	}); err != nil {
+		return err
+	}
+	return g.db.Apply(b, false /* sync */)
+}
+
+// Merge implements the Engine interface.
+func (g *GoLSM) Merge(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return g.db.Merge(EncodeKey(key), value, false /* sync */)
+}
+
+// Put implements the Engine interface.
+func (g *GoLSM) Put(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	return g.db.Set(EncodeKey(key), value, false /* sync */)
+}
+
+// LogData implements the Engine interface.
+func (g *GoLSM) LogData(data []byte) error {
+	panic("unimplemented")
+}
+
+// LogLogicalOp implements the Engine interface.
+func (g *GoLSM) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op. Logical logging disabled.
+}
+
+// Attrs implements the Engine interface.
+func (g *GoLSM) Attrs() roachpb.Attributes {
+	return g.cfg.Attrs
+}
+
+// Capacity implements the Engine interface.
+func (g *GoLSM) Capacity() (roachpb.StoreCapacity, error) {
+	return computeCapacity(g.cfg.Dir, g.cfg.MaxSizeBytes)
+}
+
+// Flush implements the Engine interface.
+func (g *GoLSM) Flush() error {
+	return g.db.Flush()
+}
+
+// GetStats implements the Engine interface.
+func (g *GoLSM) GetStats() (*Stats, error) {
+	m := g.db.Metrics()
+	return &Stats{
+		BlockCacheHits:                 m.BlockCacheHits,
+		BlockCacheMisses:               m.BlockCacheMisses,
+		BlockCacheUsage:                m.BlockCacheSize,
+		MemtableTotalSize:              m.MemTableSize,
+		Flushes:                        m.Flushes,
+		Compactions:                    m.Compactions,
+		PendingCompactionBytesEstimate: int64(m.PendingCompactionBytes),
+		L0FileCount:                    int64(m.LevelFiles[0]),
+	}, nil
+}
+
+// GetEnvStats implements the Engine interface. GoLSM does not support
+// encryption, so only the file counts are reported.
+func (g *GoLSM) GetEnvStats() (*EnvStats, error) {
+	stats := &EnvStats{}
+	for _, t := range g.db.Tables() {
+		stats.TotalFiles++
+		stats.TotalBytes += t.Size
+	}
+	return stats, nil
+}
+
+// GetSSTables implements the WithSSTables interface.
+func (g *GoLSM) GetSSTables() SSTableInfos {
+	tables := g.db.Tables()
+	res := make(SSTableInfos, len(tables))
+	for i, t := range tables {
+		// The bounds of the tables are keys written through the engine, so
+		// they always decode.
+		start, _ := DecodeMVCCKey(t.Smallest)
+		end, _ := DecodeMVCCKey(t.Largest)
+		res[i] = SSTableInfo{Level: t.Level, Size: int64(t.Size), Start: start, End: end}
+	}
+	sort.Sort(res)
+	return res
+}
+
+// GetAuxiliaryDir implements the Engine interface.
+func (g *GoLSM) GetAuxiliaryDir() string {
+	return g.auxDir
+}
+
+// NewBatch implements the Engine interface.
+func (g *GoLSM) NewBatch() Batch {
+	return newGoLSMBatch(g, false /* writeOnly */)
+}
+
+// NewWriteOnlyBatch implements the Engine interface.
+func (g *GoLSM) NewWriteOnlyBatch() Batch {
+	return newGoLSMBatch(g, true /* writeOnly */)
+}
+
+// NewReadOnly implements the Engine interface.
+func (g *GoLSM) NewReadOnly() ReadWriter {
+	return &goLSMReadOnly{parent: g}
+}
+
+// NewSnapshot implements the Engine interface.
+func (g *GoLSM) NewSnapshot() Reader {
+	if g.closed {
+		panic("GoLSM is not initialized yet")
+	}
+	return &goLSMSnapshot{snap: g.db.NewSnapshot()}
+}
+
+// IngestExternalFiles implements the Engine interface. The files are
+// RocksDB SSTables, which are rewritten as golsm tables before being
+// ingested. Like the RocksDB engine, the files are removed on success.
+func (g *GoLSM) IngestExternalFiles(
+	ctx context.Context, paths []string, skipWritingSeqNo, allowFileModifications bool,
+) error {
+	converted := make([]string, 0, len(paths))
+	defer func() {
+		for _, path := range converted {
+			if err := g.fs.Remove(path); err != nil {
+				log.Warningf(ctx, "failed to remove %s: %v", path, err)
+			}
+		}
+	}()
+	for _, path := range paths {
+		data, err := g.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		tablePath := path + ".golsm"
+		if err := g.convertSSTable(data, tablePath); err != nil {
+			return errors.Wrapf(err, "converting %s", path)
+		}
+		converted = append(converted, tablePath)
+	}
+	if err := g.db.Ingest(converted); err != nil {
+		return err
+	}
+	for _, path := range paths {
+		if err := g.fs.Remove(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// convertSSTable writes the entries of a RocksDB SSTable to a golsm table at
+// path.
+func (g *GoLSM) convertSSTable(data []byte, path string) (retErr error) {
+	w, err := golsm.NewExternalTableWriter(g.fs, path, g.opts)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := w.Close(); err != nil && retErr == nil {
+			retErr = err
+		}
+	}()
+
+	sst := table.NewReader(newMemFile(data), readerOpts)
+	defer func() { _ = sst.Close() }()
+	iter := sst.Find(nil, nil)
+	for iter.Next() {
+		// RocksDB uses the last 8 bytes to pack the sequence number and value
+		// type into a little-endian encoded uint64. See sstIterator.Next.
+		internalKey := iter.Key()
+		if len(internalKey) < 8 {
+			_ = iter.Close()
+			return errors.Errorf("invalid rocksdb InternalKey: %x", internalKey)
+		}
+		key := internalKey[:len(internalKey)-8]
+		seqAndValueType := binary.LittleEndian.Uint64(internalKey[len(internalKey)-8:])
+		switch valueType := BatchType(seqAndValueType & 0xff); valueType {
+		case BatchTypeValue:
+			err = w.Set(key, iter.Value())
+		case BatchTypeMerge:
+			err = w.Merge(key, iter.Value())
+		case BatchTypeDeletion, BatchTypeSingleDeletion:
+			err = w.Delete(key)
+		default:
+			err = errors.Errorf("value type not supported: %d", valueType)
+		}
+		if err != nil {
+			_ = iter.Close()
+			return err
+		}
+	}
+	return errors.Wrap(iter.Close(), "closing sstable iterator")
+}
+
+// PreIngestDelay implements the Engine interface. See
+// RocksDB.PreIngestDelay.
+func (g *GoLSM) PreIngestDelay(ctx context.Context) {
+	if g.cfg.Settings == nil {
+		return
+	}
+	stats, err := g.GetStats()
+	if err != nil {
+		log.Warningf(ctx, "failed to read stats: %+v", err)
+		return
+	}
+	targetDelay := calculatePreIngestDelay(RocksDBConfig{Settings: g.cfg.Settings}, stats)
+
+	if targetDelay == 0 {
+		return
+	}
+	log.VEventf(ctx, 2, "delaying SST ingestion %s. %d L0 files, %db pending compaction", targetDelay, stats.L0FileCount, stats.PendingCompactionBytesEstimate)
+
+	select {
+	case <-time.After(targetDelay):
+	case <-ctx.Done():
+	}
+}
+
+// ApproximateDiskBytes implements the Engine interface.
+func (g *GoLSM) ApproximateDiskBytes(from, to roachpb.Key) (uint64, error) {
+	start := EncodeKey(MakeMVCCMetadataKey(from))
+	end := EncodeKey(MakeMVCCMetadataKey(to))
+	return g.db.EstimateDiskUsage(start, end), nil
+}
+
+// CompactRange implements the Engine interface. The tables are always
+// compacted to the bottommost level.
+func (g *GoLSM) CompactRange(start, end roachpb.Key, forceBottommost bool) error {
+	var encStart, encEnd []byte
+	if start != nil {
+		encStart = EncodeKey(MakeMVCCMetadataKey(start))
+	}
+	if end != nil {
+		encEnd = EncodeKey(MakeMVCCMetadataKey(end))
+	}
+	return g.db.Compact(encStart, encEnd)
+}
+
+// OpenFile implements the Engine interface.
+func (g *GoLSM) OpenFile(filename string) (DBFile, error) {
+	f, err := g.fs.Create(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	return goLSMFile{f}, nil
+}
+
+// ReadFile implements the Engine interface.
+func (g *GoLSM) ReadFile(filename string) ([]byte, error) {
+	f, err := g.fs.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, os.ErrNotExist
+		}
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+// DeleteFile implements the Engine interface.
+func (g *GoLSM) DeleteFile(filename string) error {
+	if err := g.fs.Remove(filename); err != nil {
+		if os.IsNotExist(err) {
+			return os.ErrNotExist
+		}
+		return err
+	}
+	return nil
+}
+
+// DeleteDirAndFiles implements the Engine interface.
+func (g *GoLSM) DeleteDirAndFiles(dir string) error {
+	names, err := g.fs.List(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		if info, err := g.fs.Stat(path); err == nil && info.IsDir() {
+			continue
+		}
+		if err := g.fs.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	if err := g.fs.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// LinkFile implements the Engine interface.
+func (g *GoLSM) LinkFile(oldname, newname string) error {
+	if err := g.fs.Link(oldname, newname); err != nil {
+		if linkErr, ok := err.(*os.LinkError); ok {
+			return linkErr
+		}
+		return &os.LinkError{
+			Op:  "link",
+			Old: oldname,
+			New: newname,
+			Err: err,
+		}
+	}
+	return nil
+}
+
+// CreateCheckpoint implements the Engine interface.
+func (g *GoLSM) CreateCheckpoint(dir string) error {
+	return errors.Wrap(g.db.Checkpoint(dir), "unable to take golsm checkpoint")
+}
+
+// goLSMFile implements the DBFile interface on top of a golsm.File.
+type goLSMFile struct {
+	f golsm.File
+}
+
+// Append implements the DBFile interface.
+func (f goLSMFile) Append(data []byte) error {
+	_, err := f.f.Write(data)
+	return err
+}
+
+// Close implements the DBFile interface.
+func (f goLSMFile) Close() error {
+	return f.f.Close()
+}
+
+// Sync implements the DBFile interface.
+func (f goLSMFile) Sync() error {
+	return f.f.Sync()
+}
+
+// goLSMSnapshot implements Reader on top of a golsm.Snapshot.
+type goLSMSnapshot struct {
+	snap *golsm.Snapshot
+}
+
+// Close implements the Reader interface.
+func (s *goLSMSnapshot) Close() {
+	if err := s.snap.Close(); err != nil {
+		panic(err)
+	}
+	s.snap = nil
+}
+
+// Closed implements the Reader interface.
+func (s *goLSMSnapshot) Closed() bool {
+	return s.snap == nil
+}
+
+// Get implements the Reader interface.
+func (s *goLSMSnapshot) Get(key MVCCKey) ([]byte, error) {
+	return goLSMGet(s.snap, key)
+}
+
+// GetProto implements the Reader interface.
+func (s *goLSMSnapshot) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return goLSMGetProto(s.snap, key, msg)
+}
+
+// Iterate implements the Reader interface.
+func (s *goLSMSnapshot) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	return goLSMIterate(s.snap, start, end, f)
+}
+
+// NewIterator implements the Reader interface.
+func (s *goLSMSnapshot) NewIterator(opts IterOptions) Iterator {
+	return newGoLSMIterator(s.snap, opts, nil)
+}
+
+// goLSMReadOnly is the ReadWriter returned by GoLSM.NewReadOnly. Reads are
+// served by the engine and writes are not permitted.
+type goLSMReadOnly struct {
+	parent   *GoLSM
+	isClosed bool
+}
+
+func (r *goLSMReadOnly) Close() {
+	if r.isClosed {
+		panic("closing an already-closed goLSMReadOnly")
+	}
+	r.isClosed = true
+}
+
+// Read-only batches are not committed
+func (r *goLSMReadOnly) Closed() bool {
+	return r.isClosed
+}
+
+func (r *goLSMReadOnly) Get(key MVCCKey) ([]byte, error) {
+	if r.isClosed {
+		panic("using a closed goLSMReadOnly")
+	}
+	return r.parent.Get(key)
+}
+
+func (r *goLSMReadOnly) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	if r.isClosed {
+		panic("using a closed goLSMReadOnly")
+	}
+	return r.parent.GetProto(key, msg)
+}
+
+func (r *goLSMReadOnly) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	if r.isClosed {
+		panic("using a closed goLSMReadOnly")
+	}
+	return r.parent.Iterate(start, end, f)
+}
+
+func (r *goLSMReadOnly) NewIterator(opts IterOptions) Iterator {
+	if r.isClosed {
+		panic("using a closed goLSMReadOnly")
+	}
+	return r.parent.NewIterator(opts)
+}
+
+// Writer methods are not implemented for goLSMReadOnly, see rocksDBReadOnly.
+
+func (r *goLSMReadOnly) ApplyBatchRepr(repr []byte, sync bool) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) Clear(key MVCCKey) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) SingleClear(key MVCCKey) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) ClearRange(start, end MVCCKey) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) Merge(key MVCCKey, value []byte) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) Put(key MVCCKey, value []byte) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) LogData(data []byte) error {
+	panic("not implemented")
+}
+
+func (r *goLSMReadOnly) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	panic("not implemented")
+}
@@ -0,0 +1,279 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// batchHeaderLen is the length of the batch header: an 8-byte sequence
+// number followed by a 4-byte count.
+const batchHeaderLen = 12
+
+// Batch is a sequence of writes that are committed atomically. The
+// representation is a header followed by records of the form
+//
+//   kind:byte key:uvarint-prefixed-bytes [value:uvarint-prefixed-bytes]
+//
+// where Delete records have no value and the value of a DeleteRange record
+// is its exclusive end key.
+//
+// An indexed batch additionally supports reads of its own writes layered on
+// top of the DB.
+type Batch struct {
+	db    *DB
+	data  []byte
+	count uint32
+	// numRangeDels is the number of DeleteRange records in the batch.
+	numRangeDels uint32
+
+	// The fields below are only set for indexed batches.
+	index     *skiplist
+	rangeDels []batchRangeDel
+}
+
+// batchRangeDel is a range deletion recorded in an indexed batch. It shadows
+// the DB and the batch entries that precede it.
+type batchRangeDel struct {
+	start, end []byte
+	// idx is the position of the range deletion in the batch.
+	idx uint32
+}
+
+// NewBatch returns a new batch for the DB.
+func (d *DB) NewBatch() *Batch {
+	return &Batch{db: d}
+}
+
+// NewIndexedBatch returns a new batch that supports reading its own writes.
+func (d *DB) NewIndexedBatch() *Batch {
+	return &Batch{db: d, index: newSkiplist(d.icmp)}
+}
+
+func (b *Batch) init() {
+	if len(b.data) == 0 {
+		b.data = make([]byte, batchHeaderLen, 1024)
+	}
+}
+
+func (b *Batch) appendRecord(kind keyKind, key, value []byte, hasValue bool) {
+	b.init()
+	b.data = append(b.data, byte(kind))
+	b.data = appendUvarintBytes(b.data, key)
+	var k, v []byte
+	k = b.data[len(b.data)-len(key):]
+	if hasValue {
+		b.data = appendUvarintBytes(b.data, value)
+		v = b.data[len(b.data)-len(value):]
+	}
+	idx := b.count
+	b.count++
+	if kind == kindDeleteRange {
+		b.numRangeDels++
+	}
+	binary.LittleEndian.PutUint32(b.data[8:12], b.count)
+	if b.index == nil {
+		return
+	}
+	if kind == kindDeleteRange {
+		b.rangeDels = append(b.rangeDels, batchRangeDel{start: k, end: v, idx: idx})
+		return
+	}
+	ikey := makeInternalKey(make([]byte, 0, len(k)+trailerLen), k, seqNumBatchFlag|uint64(idx), kind)
+	b.index.add(ikey, v)
+}
+
+func appendUvarintBytes(dst, b []byte) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(b)))
+	dst = append(dst, buf[:n]...)
+	return append(dst, b...)
+}
+
+// Set adds a write of value to key.
+func (b *Batch) Set(key, value []byte) {
+	b.appendRecord(kindSet, key, value, true)
+}
+
+// Merge adds a merge of value into key.
+func (b *Batch) Merge(key, value []byte) {
+	b.appendRecord(kindMerge, key, value, true)
+}
+
+// Delete adds a deletion of key.
+func (b *Batch) Delete(key []byte) {
+	b.appendRecord(kindDelete, key, nil, false)
+}
+
+// DeleteRange adds a deletion of the keys in [start, end).
+func (b *Batch) DeleteRange(start, end []byte) {
+	b.appendRecord(kindDeleteRange, start, end, true)
+}
+
+// Count returns the number of records in the batch.
+func (b *Batch) Count() uint32 {
+	return b.count
+}
+
+// Empty returns true if the batch holds no records.
+func (b *Batch) Empty() bool {
+	return b.count == 0
+}
+
+// Len returns the size of the batch representation in bytes.
+func (b *Batch) Len() int {
+	if len(b.data) == 0 {
+		return batchHeaderLen
+	}
+	return len(b.data)
+}
+
+// Repr returns the representation of the batch. The returned slice is owned
+// by the batch and is invalidated by further writes.
+func (b *Batch) Repr() []byte {
+	b.init()
+	return b.data
+}
+
+// SetRepr replaces the contents of the batch with a representation
+// previously returned by Repr.
+func (b *Batch) SetRepr(data []byte) error {
+	b.Reset()
+	r, err := newBatchReader(data)
+	if err != nil {
+		return err
+	}
+	return b.applyReader(r)
+}
+
+// Apply appends the records of other to the batch.
+func (b *Batch) Apply(other *Batch) error {
+	if other.Empty() {
+		return nil
+	}
+	r, err := newBatchReader(other.data)
+	if err != nil {
+		return err
+	}
+	return b.applyReader(r)
+}
+
+func (b *Batch) applyReader(r *batchReader) error {
+	for {
+		kind, key, value, ok := r.next()
+		if !ok {
+			break
+		}
+		b.appendRecord(kind, key, value, kind != kindDelete)
+	}
+	return r.err
+}
+
+// Reset clears the batch so that it can be reused.
+func (b *Batch) Reset() {
+	b.data = nil
+	b.count = 0
+	b.numRangeDels = 0
+	if b.index != nil {
+		b.index = newSkiplist(b.index.cmp)
+		b.rangeDels = nil
+	}
+}
+
+// Indexed returns true if the batch supports reads.
+func (b *Batch) Indexed() bool {
+	return b.index != nil
+}
+
+// Commit applies the batch to its DB. If sync is true, the write is durable
+// when Commit returns.
+func (b *Batch) Commit(sync bool) error {
+	return b.db.Apply(b, sync)
+}
+
+// NewIter returns an iterator over the DB merged with the writes in the
+// batch. The iterator observes the batch as of its creation. The batch must
+// be indexed.
+func (b *Batch) NewIter(o *IterOptions) *Iterator {
+	return b.NewIterAt(o, b.count)
+}
+
+// NewIterAt is like NewIter, but the iterator only observes the first
+// count records of the batch.
+func (b *Batch) NewIterAt(o *IterOptions, count uint32) *Iterator {
+	if b.index == nil {
+		panic("golsm: reads require an indexed batch")
+	}
+	return b.db.newIter(o, nil, b, count)
+}
+
+// Get returns the value of key, reading through the batch. It returns
+// ErrNotFound if the key does not exist.
+func (b *Batch) Get(key []byte) ([]byte, error) {
+	return getFromIter(b.NewIter(nil), b.db.opts.Compare, key)
+}
+
+// batchReader decodes the records of a batch representation.
+type batchReader struct {
+	data  []byte
+	count uint32
+	seq   uint64
+	err   error
+}
+
+func newBatchReader(data []byte) (*batchReader, error) {
+	if len(data) == 0 {
+		return &batchReader{}, nil
+	}
+	if len(data) < batchHeaderLen {
+		return nil, errors.Errorf("batch too small: %d bytes", len(data))
+	}
+	return &batchReader{
+		data:  data[batchHeaderLen:],
+		seq:   binary.LittleEndian.Uint64(data[:8]),
+		count: binary.LittleEndian.Uint32(data[8:12]),
+	}, nil
+}
+
+func (r *batchReader) next() (kind keyKind, key, value []byte, ok bool) {
+	if len(r.data) == 0 || r.err != nil {
+		return 0, nil, nil, false
+	}
+	kind = keyKind(r.data[0])
+	r.data = r.data[1:]
+	if key, ok = r.readBytes(); !ok {
+		return 0, nil, nil, false
+	}
+	switch kind {
+	case kindDelete:
+	case kindSet, kindMerge, kindDeleteRange:
+		if value, ok = r.readBytes(); !ok {
+			return 0, nil, nil, false
+		}
+	default:
+		r.err = errors.Errorf("unknown batch record kind %d", kind)
+		return 0, nil, nil, false
+	}
+	return kind, key, value, true
+}
+
+func (r *batchReader) readBytes() ([]byte, bool) {
+	n, w := binary.Uvarint(r.data)
+	if w <= 0 || uint64(len(r.data)-w) < n {
+		r.err = errors.New("corrupt batch record")
+		return nil, false
+	}
+	b := r.data[w : w+int(n)]
+	r.data = r.data[w+int(n):]
+	return b, true
+}
@@ -0,0 +1,111 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"container/list"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+)
+
+type blockCacheKey struct {
+	id, offset uint64
+}
+
+type blockCacheEntry struct {
+	key   blockCacheKey
+	block *block
+}
+
+// blockCache is an LRU cache of decoded table blocks shared by all the
+// tables of a DB.
+type blockCache struct {
+	mu       syncutil.Mutex
+	capacity int64
+	size     int64
+	nextID   uint64
+	lru      *list.List
+	entries  map[blockCacheKey]*list.Element
+	hits     int64
+	misses   int64
+}
+
+func newBlockCache(capacity int64) *blockCache {
+	return &blockCache{
+		capacity: capacity,
+		lru:      list.New(),
+		entries:  make(map[blockCacheKey]*list.Element),
+	}
+}
+
+// newID returns a new ID identifying a table in the cache.
+func (c *blockCache) newID() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	return c.nextID
+}
+
+func (c *blockCache) get(id, offset uint64) *block {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[blockCacheKey{id, offset}]
+	if !ok {
+		c.misses++
+		return nil
+	}
+	c.hits++
+	c.lru.MoveToFront(e)
+	return e.Value.(*blockCacheEntry).block
+}
+
+func (c *blockCache) add(id, offset uint64, b *block) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := blockCacheKey{id, offset}
+	if _, ok := c.entries[key]; ok {
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&blockCacheEntry{key: key, block: b})
+	c.size += b.size()
+	for c.size > c.capacity && c.lru.Len() > 0 {
+		c.removeLocked(c.lru.Back())
+	}
+}
+
+func (c *blockCache) removeLocked(e *list.Element) {
+	entry := c.lru.Remove(e).(*blockCacheEntry)
+	delete(c.entries, entry.key)
+	c.size -= entry.block.size()
+}
+
+// evictID removes all the blocks of the table with the given ID.
+func (c *blockCache) evictID(id uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for e := c.lru.Front(); e != nil; {
+		next := e.Next()
+		if e.Value.(*blockCacheEntry).key.id == id {
+			c.removeLocked(e)
+		}
+		e = next
+	}
+}
+
+type blockCacheStats struct {
+	size, hits, misses int64
+}
+
+func (c *blockCache) stats() blockCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return blockCacheStats{size: c.size, hits: c.hits, misses: c.misses}
+}
@@ -0,0 +1,392 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// compaction merges a set of tables from one level into the next.
+type compaction struct {
+	level       int
+	outputLevel int
+	// inputs holds the input tables of level and outputLevel.
+	inputs [2][]*fileMetadata
+	// v is the version the inputs were picked from. It holds a reference.
+	v *version
+}
+
+// levelMaxBytes returns the target size of each level. L0 is sized by its
+// number of tables instead.
+func (d *DB) levelMaxBytes() [numLevels]float64 {
+	var res [numLevels]float64
+	max := float64(d.opts.LBaseMaxBytes)
+	for level := 1; level < numLevels; level++ {
+		res[level] = max
+		max *= float64(d.opts.LevelMultiplier)
+	}
+	return res
+}
+
+// pickCompactionLocked returns the most urgent compaction, or nil if every
+// level is within its target size.
+func (d *DB) pickCompactionLocked() *compaction {
+	v := d.mu.current
+	bestLevel, bestScore := -1, 1.0
+	if score := float64(len(v.files[0])) / float64(d.opts.L0CompactionThreshold); score >= bestScore {
+		bestLevel, bestScore = 0, score
+	}
+	maxBytes := d.levelMaxBytes()
+	// The last level has no target size.
+	for level := 1; level < numLevels-1; level++ {
+		if score := float64(levelSize(v.files[level])) / maxBytes[level]; score > bestScore {
+			bestLevel, bestScore = level, score
+		}
+	}
+	if bestLevel < 0 {
+		return nil
+	}
+	c := &compaction{level: bestLevel, outputLevel: bestLevel + 1, v: v}
+	if bestLevel == 0 {
+		c.inputs[0] = v.files[0]
+	} else {
+		// Pick tables round-robin so that the whole key space is compacted
+		// over time.
+		files := v.files[bestLevel]
+		f := files[0]
+		if ptr := d.mu.compactPointers[bestLevel]; ptr != nil {
+			for _, g := range files {
+				if d.icmp(g.smallest, ptr) > 0 {
+					f = g
+					break
+				}
+			}
+		}
+		c.inputs[0] = []*fileMetadata{f}
+	}
+	smallest, largest := keyRange(d.ucmp, c.inputs[0])
+	c.inputs[1] = v.overlaps(c.outputLevel, d.ucmp, smallest, largest)
+	v.refs++
+	return c
+}
+
+// compactLoop runs automatic compactions in the background.
+func (d *DB) compactLoop() {
+	defer d.bgWG.Done()
+	for {
+		d.mu.Lock()
+		for !d.mu.closed && !d.needsCompactionLocked() {
+			d.mu.cond.Wait()
+		}
+		closed := d.mu.closed
+		d.mu.Unlock()
+		if closed {
+			return
+		}
+
+		d.compactMu.Lock()
+		d.mu.Lock()
+		if !d.mu.closed && d.needsCompactionLocked() {
+			if c := d.pickCompactionLocked(); c != nil {
+				if err := d.runCompactionLocked(c); err != nil {
+					d.mu.bgErr = err
+					d.logf("compaction failed: %v", err)
+				}
+				d.mu.cond.Broadcast()
+			}
+		}
+		d.mu.Unlock()
+		d.compactMu.Unlock()
+	}
+}
+
+func (d *DB) needsCompactionLocked() bool {
+	if d.mu.bgErr != nil || d.opts.DisableAutomaticCompactions {
+		return false
+	}
+	c := d.pickCompactionLocked()
+	if c == nil {
+		return false
+	}
+	d.unrefVersionLocked(c.v)
+	return true
+}
+
+// runCompactionLocked runs the compaction and installs its result. compactMu
+// and mu must be held; mu is released while the tables are written.
+func (d *DB) runCompactionLocked(c *compaction) error {
+	defer d.unrefVersionLocked(c.v)
+	snapshots := d.snapshotSeqsLocked()
+
+	d.mu.Unlock()
+	outputs, err := d.compactTables(c, snapshots)
+	d.mu.Lock()
+	defer func() {
+		for _, f := range outputs {
+			delete(d.mu.pending, f.fileNum)
+		}
+	}()
+	if err != nil {
+		for _, f := range outputs {
+			_ = d.fs.Remove(tableFilename(d.dirname, f.fileNum))
+		}
+		return err
+	}
+
+	e := &versionEdit{}
+	for _, inputs := range c.inputs {
+		for _, f := range inputs {
+			e.deleteFile(f.fileNum)
+		}
+	}
+	e.added[c.outputLevel] = outputs
+	if err := d.logAndApplyLocked(e, 0); err != nil {
+		for _, f := range outputs {
+			_ = d.fs.Remove(tableFilename(d.dirname, f.fileNum))
+		}
+		return err
+	}
+	if c.level > 0 {
+		d.mu.compactPointers[c.level] = c.inputs[0][len(c.inputs[0])-1].largest
+	}
+	d.mu.metrics.Compactions++
+	d.logf("compacted %d+%d tables from L%d into %d tables in L%d",
+		len(c.inputs[0]), len(c.inputs[1]), c.level, len(outputs), c.outputLevel)
+	return nil
+}
+
+// newFileNum allocates the number of a table to be written.
+func (d *DB) newFileNum() uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	num := d.mu.nextFileNum
+	d.mu.nextFileNum++
+	d.mu.pending[num] = struct{}{}
+	return num
+}
+
+// compactTables merges the inputs of the compaction into new tables. Outputs
+// are split at user key boundaries once they reach the target file size. The
+// returned tables are marked pending, even on error.
+func (d *DB) compactTables(c *compaction, snapshots []uint64) ([]*fileMetadata, error) {
+	var iters []internalIterator
+	if c.level == 0 {
+		for _, f := range c.inputs[0] {
+			t, err := d.tc.get(f)
+			if err != nil {
+				return nil, err
+			}
+			iters = append(iters, t.newIter())
+		}
+	} else {
+		iters = append(iters, newLevelIter(d.icmp, d.tc, c.inputs[0]))
+	}
+	iters = append(iters, newLevelIter(d.icmp, d.tc, c.inputs[1]))
+	iter := newMergingIter(d.icmp, iters...)
+
+	var outputs []*fileMetadata
+	var w *tableWriter
+	var fileNum uint64
+	finish := func() error {
+		if w == nil {
+			return nil
+		}
+		props, size, err := w.finish()
+		w = nil
+		if err != nil {
+			return err
+		}
+		outputs = append(outputs, newFileMetadata(fileNum, size, props, 0))
+		return nil
+	}
+	fail := func(err error) ([]*fileMetadata, error) {
+		if w != nil {
+			w.abort()
+			outputs = append(outputs, &fileMetadata{fileNum: fileNum})
+		}
+		return outputs, err
+	}
+
+	var ukey []byte
+	var group []compactionEntry
+	for valid := iter.First(); valid; {
+		ukey = append(ukey[:0], userKey(iter.Key())...)
+		group = group[:0]
+		for ; valid && d.ucmp(userKey(iter.Key()), ukey) == 0; valid = iter.Next() {
+			seq, kind := decodeTrailer(trailer(iter.Key()))
+			group = append(group, compactionEntry{seq: seq, kind: kind, value: iter.Value()})
+		}
+		if err := iter.Error(); err != nil {
+			return fail(err)
+		}
+		kept := d.compactEntries(ukey, group, snapshots, d.isBottommost(c, ukey))
+		if len(kept) == 0 {
+			continue
+		}
+		if w != nil && w.estimatedSize() >= uint64(d.opts.TargetFileSize) {
+			if err := finish(); err != nil {
+				return fail(err)
+			}
+		}
+		if w == nil {
+			fileNum = d.newFileNum()
+			f, err := d.fs.Create(tableFilename(d.dirname, fileNum))
+			if err != nil {
+				outputs = append(outputs, &fileMetadata{fileNum: fileNum})
+				return outputs, err
+			}
+			w = newTableWriter(f, d.opts.BlockSize)
+		}
+		for _, e := range kept {
+			if err := w.add(makeInternalKey(nil, ukey, e.seq, e.kind), e.value); err != nil {
+				return fail(err)
+			}
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return fail(err)
+	}
+	if err := finish(); err != nil {
+		return fail(err)
+	}
+	return outputs, nil
+}
+
+// isBottommost returns true if no level below the output level of the
+// compaction can hold entries for ukey.
+func (d *DB) isBottommost(c *compaction, ukey []byte) bool {
+	for level := c.outputLevel + 1; level < numLevels; level++ {
+		files := c.v.files[level]
+		i := sort.Search(len(files), func(i int) bool {
+			return d.ucmp(userKey(files[i].largest), ukey) >= 0
+		})
+		if i < len(files) && d.ucmp(userKey(files[i].smallest), ukey) <= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type compactionEntry struct {
+	seq   uint64
+	kind  keyKind
+	value []byte
+}
+
+// compactEntries returns the entries for a user key that must survive a
+// compaction, given the entries ordered from newest to oldest.
+//
+// The open snapshots divide the sequence numbers into stripes. Within a
+// stripe, only the newest entry can be observed, so older entries are dropped
+// after combining them with the merge operands above them. A deletion in the
+// oldest stripe is dropped too if no lower level can hold entries it shadows.
+func (d *DB) compactEntries(
+	ukey []byte, entries []compactionEntry, snapshots []uint64, bottommost bool,
+) []compactionEntry {
+	stripe := func(seq uint64) int {
+		return sort.Search(len(snapshots), func(i int) bool { return snapshots[i] >= seq })
+	}
+	var kept []compactionEntry
+	for start := 0; start < len(entries); {
+		s := stripe(entries[start].seq)
+		end := start + 1
+		for end < len(entries) && stripe(entries[end].seq) == s {
+			end++
+		}
+		kept = append(kept, d.compactStripe(ukey, entries[start:end])...)
+		start = end
+	}
+	if n := len(kept); n > 0 && bottommost && kept[n-1].kind == kindDelete {
+		kept = kept[:n-1]
+	}
+	return kept
+}
+
+// compactStripe reduces the entries of a stripe, ordered from newest to
+// oldest, to those that must be kept.
+func (d *DB) compactStripe(ukey []byte, entries []compactionEntry) []compactionEntry {
+	if entries[0].kind != kindMerge {
+		return entries[:1]
+	}
+	n := 0
+	for n < len(entries) && entries[n].kind == kindMerge {
+		n++
+	}
+	if d.opts.Merger == nil {
+		return entries
+	}
+	operands := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		operands[n-1-i] = entries[i].value
+	}
+	if n < len(entries) {
+		// The operands sit on top of a value or deletion.
+		var existing []byte
+		if entries[n].kind == kindSet {
+			existing = entries[n].value
+		}
+		v, err := d.opts.Merger.FullMerge(ukey, existing, operands)
+		if err != nil {
+			return entries
+		}
+		return []compactionEntry{{seq: entries[0].seq, kind: kindSet, value: v}}
+	}
+	if n == 1 {
+		return entries
+	}
+	v, err := d.opts.Merger.PartialMerge(ukey, operands)
+	if err != nil {
+		return entries
+	}
+	return []compactionEntry{{seq: entries[0].seq, kind: kindMerge, value: v}}
+}
+
+// Compact compacts the tables overlapping the user key range [start, end]
+// down to the last level. A nil bound is unbounded.
+func (d *DB) Compact(start, end []byte) error {
+	if err := d.Flush(); err != nil {
+		return err
+	}
+	d.compactMu.Lock()
+	defer d.compactMu.Unlock()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for level := 0; level < numLevels-1; level++ {
+		if d.mu.closed {
+			return ErrClosed
+		}
+		if d.mu.bgErr != nil {
+			return d.mu.bgErr
+		}
+		v := d.mu.current
+		inputs := v.overlaps(level, d.ucmp, start, end)
+		if len(inputs) == 0 {
+			continue
+		}
+		if level == 0 {
+			// L0 tables may overlap, so older tables outside the range could
+			// hold entries shadowed by the inputs. Compact all of them.
+			inputs = v.files[0]
+		}
+		c := &compaction{level: level, outputLevel: level + 1, v: v}
+		c.inputs[0] = inputs
+		smallest, largest := keyRange(d.ucmp, inputs)
+		c.inputs[1] = v.overlaps(level+1, d.ucmp, smallest, largest)
+		v.refs++
+		if err := d.runCompactionLocked(c); err != nil {
+			return errors.Wrapf(err, "compacting L%d", level)
+		}
+	}
+	d.mu.cond.Broadcast()
+	return nil
+}
@@ -0,0 +1,856 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package golsm implements a key/value store as a log-structured merge tree
+// in pure Go.
+//
+// Writes are appended to a write-ahead log and inserted into an in-memory
+// skiplist (the memtable). Full memtables are flushed to immutable sorted
+// tables in L0, and background compactions merge tables into the lower
+// levels, dropping shadowed entries that no snapshot can observe. Every entry
+// carries a sequence number, and reads only observe the entries committed
+// before the read started.
+package golsm
+
+import (
+	"container/list"
+	"io"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+)
+
+// ErrClosed is returned when operating on a closed DB.
+var ErrClosed = errors.New("golsm: closed")
+
+// DB is a key/value store implemented as a log-structured merge tree. It is
+// safe for concurrent use.
+type DB struct {
+	dirname  string
+	opts     *Options
+	fs       FS
+	ucmp     func(a, b []byte) int
+	icmp     internalCompare
+	cache    *blockCache
+	tc       *tableCache
+	fileLock io.Closer
+
+	// visibleSeq is the highest sequence number visible to readers. It is
+	// accessed atomically.
+	visibleSeq uint64
+
+	// The mutexes below must be acquired in declaration order, and before
+	// mu.
+	//
+	// commitMu serializes commits and ingestions, which allocate sequence
+	// numbers.
+	commitMu syncutil.Mutex
+	// lastSeq is the last allocated sequence number. Protected by commitMu.
+	lastSeq uint64
+	// compactMu serializes compactions and ingestions, which both place
+	// tables below L0.
+	compactMu syncutil.Mutex
+	// manifestMu serializes the installation of new versions.
+	manifestMu syncutil.Mutex
+
+	mu struct {
+		syncutil.Mutex
+		// cond is signaled whenever the background work or its results
+		// change.
+		cond   sync.Cond
+		closed bool
+		// bgErr is the sticky error encountered by a failed WAL write or
+		// background job. Once set, all writes fail.
+		bgErr error
+		mem   *memTable
+		// imm holds the memtables waiting to be flushed, oldest first.
+		imm []*memTable
+		log *logWriter
+		// current is the current version. It holds a reference.
+		current     *version
+		nextFileNum uint64
+		manifestNum uint64
+		// logNum is the oldest WAL holding writes that have not been flushed.
+		logNum uint64
+		// pending holds the numbers of the tables being written.
+		pending map[uint64]struct{}
+		// snapshots holds the open snapshots, oldest first.
+		snapshots       *list.List
+		compactPointers [numLevels][]byte
+		metrics         Metrics
+	}
+	bgWG sync.WaitGroup
+}
+
+// readState pins the memtables and version observed by a reader.
+type readState struct {
+	mem *memTable
+	imm []*memTable
+	v   *version
+}
+
+// Open opens the DB in dirname, creating it if necessary.
+func Open(dirname string, opts *Options) (*DB, error) {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	o.EnsureDefaults()
+	d := &DB{
+		dirname: dirname,
+		opts:    &o,
+		fs:      o.FS,
+		ucmp:    o.Compare,
+		icmp:    makeInternalCompare(o.Compare),
+		cache:   newBlockCache(o.CacheSize),
+	}
+	d.tc = newTableCache(d.fs, dirname, d.ucmp, d.cache)
+	d.mu.cond.L = &d.mu.Mutex
+	d.mu.pending = make(map[uint64]struct{})
+	d.mu.snapshots = list.New()
+
+	if err := d.fs.MkdirAll(dirname, 0755); err != nil {
+		return nil, err
+	}
+	lock, err := d.fs.Lock(filepath.Join(dirname, lockFilename))
+	if err != nil {
+		return nil, errors.Wrapf(err, "locking %s", dirname)
+	}
+	d.fileLock = lock
+	if err := d.recover(); err != nil {
+		d.tc.close()
+		_ = lock.Close()
+		return nil, err
+	}
+	d.bgWG.Add(2)
+	go d.flushLoop()
+	go d.compactLoop()
+	return d, nil
+}
+
+// recover loads the manifest, replays the WALs that hold unflushed writes
+// into a new L0 table and starts a new WAL.
+func (d *DB) recover() error {
+	m, manifestNum, err := readManifest(d.fs, d.dirname)
+	if err != nil {
+		return err
+	}
+	if m == nil {
+		m = &manifest{nextFileNum: 1}
+	}
+	d.mu.nextFileNum = m.nextFileNum
+	d.mu.manifestNum = manifestNum
+	d.lastSeq = m.lastSeq
+	v := &version{files: m.files}
+	d.installVersionLocked(v)
+
+	names, err := d.fs.List(d.dirname)
+	if err != nil {
+		return err
+	}
+	var logs []uint64
+	for _, name := range names {
+		typ, num := parseFilename(name)
+		if num >= d.mu.nextFileNum {
+			d.mu.nextFileNum = num + 1
+		}
+		if typ == fileTypeLog && num >= m.logNum {
+			logs = append(logs, num)
+		}
+	}
+	sort.Slice(logs, func(i, j int) bool { return logs[i] < logs[j] })
+
+	mem := newMemTable(d.icmp, 0)
+	for i, num := range logs {
+		// Only the last log can have been written to when the DB was closed or
+		// crashed, so it is the only one whose last record can be torn.
+		tornTail := i == len(logs)-1
+		if err := replayLog(d.fs, logFilename(d.dirname, num), tornTail, func(payload []byte) error {
+			r, err := newBatchReader(payload)
+			if err != nil {
+				return err
+			}
+			seq := r.seq
+			for {
+				kind, key, value, ok := r.next()
+				if !ok {
+					break
+				}
+				mem.add(seq, kind, key, value)
+				if seq > d.lastSeq {
+					d.lastSeq = seq
+				}
+				seq++
+			}
+			return r.err
+		}); err != nil {
+			return errors.Wrapf(err, "replaying WAL %d", num)
+		}
+	}
+
+	edit := &versionEdit{}
+	if !mem.empty() {
+		fileNum := d.mu.nextFileNum
+		d.mu.nextFileNum++
+		meta, err := d.writeTable(mem.newIter(), fileNum)
+		if err != nil {
+			return err
+		}
+		if meta != nil {
+			edit.added[0] = append(edit.added[0], meta)
+		}
+	}
+	logNum := d.mu.nextFileNum
+	d.mu.nextFileNum++
+	f, err := d.fs.Create(logFilename(d.dirname, logNum))
+	if err != nil {
+		return err
+	}
+	d.mu.log = newLogWriter(f, logNum)
+	d.mu.mem = newMemTable(d.icmp, logNum)
+	d.visibleSeq = d.lastSeq
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	edit.lastSeq = d.lastSeq
+	if err := d.logAndApplyLocked(edit, logNum); err != nil {
+		return err
+	}
+	d.deleteObsoleteFilesLocked()
+	return nil
+}
+
+// deleteObsoleteFilesLocked removes the files that are not part of the
+// current state of the DB. It is called on startup; afterwards files are
+// removed as soon as they become obsolete.
+func (d *DB) deleteObsoleteFilesLocked() {
+	live := make(map[uint64]struct{})
+	for _, files := range d.mu.current.files {
+		for _, f := range files {
+			live[f.fileNum] = struct{}{}
+		}
+	}
+	names, err := d.fs.List(d.dirname)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		typ, num := parseFilename(name)
+		remove := false
+		switch typ {
+		case fileTypeTable:
+			_, ok := live[num]
+			_, pending := d.mu.pending[num]
+			remove = !ok && !pending
+		case fileTypeLog:
+			remove = num < d.mu.logNum
+		case fileTypeManifest:
+			remove = num != d.mu.manifestNum
+		case fileTypeTemp:
+			remove = true
+		}
+		if remove {
+			_ = d.fs.Remove(filepath.Join(d.dirname, name))
+		}
+	}
+}
+
+// writeTable writes the entries of iter to a new table. It returns nil if
+// the iterator is empty.
+func (d *DB) writeTable(iter internalIterator, fileNum uint64) (*fileMetadata, error) {
+	path := tableFilename(d.dirname, fileNum)
+	f, err := d.fs.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	w := newTableWriter(f, d.opts.BlockSize)
+	n := 0
+	for valid := iter.First(); valid; valid = iter.Next() {
+		if err := w.add(iter.Key(), iter.Value()); err != nil {
+			w.abort()
+			_ = d.fs.Remove(path)
+			return nil, err
+		}
+		n++
+	}
+	if err := iter.Error(); err != nil {
+		w.abort()
+		_ = d.fs.Remove(path)
+		return nil, err
+	}
+	if n == 0 {
+		w.abort()
+		return nil, d.fs.Remove(path)
+	}
+	props, size, err := w.finish()
+	if err != nil {
+		_ = d.fs.Remove(path)
+		return nil, err
+	}
+	return newFileMetadata(fileNum, size, props, 0), nil
+}
+
+func newFileMetadata(fileNum, size uint64, props tableProps, globalSeq uint64) *fileMetadata {
+	m := &fileMetadata{
+		fileNum:     fileNum,
+		size:        size,
+		smallest:    props.smallest,
+		largest:     props.largest,
+		smallestSeq: props.smallestSeq,
+		largestSeq:  props.largestSeq,
+		globalSeq:   globalSeq,
+	}
+	if globalSeq != 0 {
+		_, kind := decodeTrailer(trailer(props.smallest))
+		m.smallest = makeInternalKey(nil, userKey(props.smallest), globalSeq, kind)
+		_, kind = decodeTrailer(trailer(props.largest))
+		m.largest = makeInternalKey(nil, userKey(props.largest), globalSeq, kind)
+		m.smallestSeq, m.largestSeq = globalSeq, globalSeq
+	}
+	return m
+}
+
+// logAndApplyLocked persists a new version with the edit applied and
+// installs it. logNum, if non-zero, replaces the number of the oldest WAL
+// needed for recovery. DB.mu must be held; it is released while the manifest
+// is written.
+func (d *DB) logAndApplyLocked(e *versionEdit, logNum uint64) error {
+	d.mu.Unlock()
+	d.manifestMu.Lock()
+	defer d.manifestMu.Unlock()
+	d.mu.Lock()
+
+	nv := d.mu.current.apply(e, d.icmp)
+	if logNum == 0 {
+		logNum = d.mu.logNum
+	}
+	lastSeq := atomic.LoadUint64(&d.visibleSeq)
+	if e.lastSeq > lastSeq {
+		lastSeq = e.lastSeq
+	}
+	manifestNum := d.mu.nextFileNum
+	d.mu.nextFileNum++
+	m := &manifest{
+		nextFileNum: d.mu.nextFileNum,
+		logNum:      logNum,
+		lastSeq:     lastSeq,
+		files:       nv.files,
+	}
+	d.mu.Unlock()
+	err := writeManifest(d.fs, d.dirname, manifestNum, m)
+	d.mu.Lock()
+	if err != nil {
+		return errors.Wrap(err, "writing manifest")
+	}
+	if d.mu.manifestNum != 0 {
+		_ = d.fs.Remove(manifestFilename(d.dirname, d.mu.manifestNum))
+	}
+	d.mu.manifestNum = manifestNum
+	d.mu.logNum = logNum
+	d.installVersionLocked(nv)
+	if e.onInstall != nil {
+		e.onInstall()
+	}
+	return nil
+}
+
+// installVersionLocked makes v the current version.
+func (d *DB) installVersionLocked(v *version) {
+	for _, files := range v.files {
+		for _, f := range files {
+			f.refs++
+		}
+	}
+	v.refs = 1
+	old := d.mu.current
+	d.mu.current = v
+	if old != nil {
+		d.unrefVersionLocked(old)
+	}
+}
+
+// unrefVersionLocked drops a reference to v, deleting the tables that are
+// no longer referenced by any version.
+func (d *DB) unrefVersionLocked(v *version) {
+	v.refs--
+	if v.refs > 0 {
+		return
+	}
+	for _, files := range v.files {
+		for _, f := range files {
+			f.refs--
+			if f.refs == 0 {
+				d.tc.evict(f.fileNum)
+				_ = d.fs.Remove(tableFilename(d.dirname, f.fileNum))
+			}
+		}
+	}
+}
+
+func (d *DB) loadReadState() *readState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	rs := &readState{
+		mem: d.mu.mem,
+		imm: append([]*memTable(nil), d.mu.imm...),
+		v:   d.mu.current,
+	}
+	rs.v.refs++
+	return rs
+}
+
+func (d *DB) releaseReadState(rs *readState) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.unrefVersionLocked(rs.v)
+}
+
+// newIter returns an iterator reading at the given snapshot, or at the
+// latest sequence number if snap is nil, merged with the first batchCount
+// records of the batch if b is non-nil.
+func (d *DB) newIter(o *IterOptions, snap *Snapshot, b *Batch, batchCount uint32) *Iterator {
+	readSeq := atomic.LoadUint64(&d.visibleSeq)
+	if snap != nil {
+		readSeq = snap.seq
+	}
+	return d.newIterAt(o, readSeq, b, batchCount)
+}
+
+func (d *DB) newIterAt(o *IterOptions, readSeq uint64, b *Batch, batchCount uint32) *Iterator {
+	rs := d.loadReadState()
+	var iters []internalIterator
+	it := &Iterator{
+		ucmp:    d.ucmp,
+		merger:  d.opts.Merger,
+		readSeq: readSeq,
+		release: func() { d.releaseReadState(rs) },
+	}
+	if o != nil {
+		it.lower, it.upper = o.LowerBound, o.UpperBound
+	}
+	if b != nil {
+		iters = append(iters, b.index.newIter())
+		it.batchCount = uint64(batchCount)
+		for _, rd := range b.rangeDels {
+			if rd.idx < batchCount {
+				it.rangeDels = append(it.rangeDels, rd)
+			}
+		}
+	}
+	iters = append(iters, rs.mem.newIter())
+	for i := len(rs.imm) - 1; i >= 0; i-- {
+		iters = append(iters, rs.imm[i].newIter())
+	}
+	for i := len(rs.v.files[0]) - 1; i >= 0; i-- {
+		t, err := d.tc.get(rs.v.files[0][i])
+		if err != nil {
+			it.err = err
+			continue
+		}
+		iters = append(iters, t.newIter())
+	}
+	for level := 1; level < numLevels; level++ {
+		if len(rs.v.files[level]) > 0 {
+			iters = append(iters, newLevelIter(d.icmp, d.tc, rs.v.files[level]))
+		}
+	}
+	it.iter = newMergingIter(d.icmp, iters...)
+	if it.err != nil {
+		it.iter = newMergingIter(d.icmp)
+	}
+	return it
+}
+
+// NewIter returns an iterator over the latest state of the DB.
+func (d *DB) NewIter(o *IterOptions) *Iterator {
+	return d.newIter(o, nil, nil, 0)
+}
+
+// Get returns the value of key. It returns ErrNotFound if the key does not
+// exist.
+func (d *DB) Get(key []byte) ([]byte, error) {
+	return getFromIter(d.NewIter(nil), d.ucmp, key)
+}
+
+// Set writes value to key.
+func (d *DB) Set(key, value []byte, sync bool) error {
+	b := d.NewBatch()
+	b.Set(key, value)
+	return d.Apply(b, sync)
+}
+
+// Merge merges value into key.
+func (d *DB) Merge(key, value []byte, sync bool) error {
+	b := d.NewBatch()
+	b.Merge(key, value)
+	return d.Apply(b, sync)
+}
+
+// Delete deletes key.
+func (d *DB) Delete(key []byte, sync bool) error {
+	b := d.NewBatch()
+	b.Delete(key)
+	return d.Apply(b, sync)
+}
+
+// DeleteRange deletes the keys in [start, end).
+func (d *DB) DeleteRange(start, end []byte, sync bool) error {
+	b := d.NewBatch()
+	b.DeleteRange(start, end)
+	return d.Apply(b, sync)
+}
+
+// Apply commits the records of the batch atomically. If sync is true, the
+// batch is durable when Apply returns. The batch may be reused afterwards.
+//
+// Range deletions are expanded into point deletions of the keys they cover
+// at commit time, so the WAL and the tables only ever hold point entries.
+func (d *DB) Apply(b *Batch, sync bool) error {
+	d.commitMu.Lock()
+	defer d.commitMu.Unlock()
+
+	d.mu.Lock()
+	err := d.makeRoomForWriteLocked(false)
+	mem, log := d.mu.mem, d.mu.log
+	d.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if b.Empty() {
+		if sync {
+			return d.failWrite(log.sync())
+		}
+		return nil
+	}
+
+	r, err := newBatchReader(b.data)
+	if err != nil {
+		return err
+	}
+	first := d.lastSeq + 1
+	seq := first
+	// resolved holds the records of the batch with the range deletions
+	// expanded. It is only built if the batch has range deletions.
+	var resolved *Batch
+	if b.numRangeDels > 0 {
+		resolved = &Batch{}
+	}
+	for {
+		kind, key, value, ok := r.next()
+		if !ok {
+			break
+		}
+		if kind != kindDeleteRange {
+			mem.add(seq, kind, key, value)
+			seq++
+			if resolved != nil {
+				resolved.appendRecord(kind, key, value, kind != kindDelete)
+			}
+			continue
+		}
+		// Expand the range deletion by reading the keys it covers, including
+		// those written earlier in this batch.
+		var keys [][]byte
+		it := d.newIterAt(&IterOptions{LowerBound: key, UpperBound: value}, seq-1, nil, 0)
+		for valid := it.First(); valid; valid = it.Next() {
+			keys = append(keys, append([]byte(nil), it.Key()...))
+		}
+		err := it.Error()
+		_ = it.Close()
+		if err != nil {
+			return d.failWrite(err)
+		}
+		for _, k := range keys {
+			mem.add(seq, kindDelete, k, nil)
+			resolved.Delete(k)
+			seq++
+		}
+	}
+	if r.err != nil {
+		return d.failWrite(r.err)
+	}
+	if seq == first {
+		return nil
+	}
+
+	repr := b.data
+	if resolved != nil {
+		repr = resolved.Repr()
+	}
+	putUint64(repr[:8], first)
+	if err := log.writeRecord(repr, sync); err != nil {
+		return d.failWrite(err)
+	}
+	d.lastSeq = seq - 1
+	atomic.StoreUint64(&d.visibleSeq, d.lastSeq)
+	return nil
+}
+
+// failWrite makes err sticky: a failed commit may have left entries in the
+// memtable that are not in the WAL, so the DB cannot accept more writes.
+func (d *DB) failWrite(err error) error {
+	if err == nil {
+		return nil
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mu.bgErr == nil {
+		d.mu.bgErr = err
+	}
+	d.mu.cond.Broadcast()
+	return err
+}
+
+// makeRoomForWriteLocked rotates the memtable if it is full (or if force is
+// set and it is not empty), stalling while too many memtables are waiting to
+// be flushed or too many tables are in L0. commitMu and mu must be held.
+func (d *DB) makeRoomForWriteLocked(force bool) error {
+	for {
+		if d.mu.closed {
+			return ErrClosed
+		}
+		if d.mu.bgErr != nil {
+			return d.mu.bgErr
+		}
+		if !force && d.mu.mem.approximateSize() < d.opts.MemTableSize {
+			return nil
+		}
+		if force && d.mu.mem.empty() {
+			return nil
+		}
+		if len(d.mu.imm) >= d.opts.MaxImmutableMemTables {
+			d.mu.cond.Wait()
+			continue
+		}
+		if !d.opts.DisableAutomaticCompactions &&
+			len(d.mu.current.files[0]) >= d.opts.L0StopWritesThreshold {
+			d.mu.cond.Wait()
+			continue
+		}
+		// The current log is synced and closed before the next one is created,
+		// so that only the last log can end with a torn record.
+		logNum := d.mu.nextFileNum
+		d.mu.nextFileNum++
+		err := d.mu.log.close()
+		var f File
+		if err == nil {
+			f, err = d.fs.Create(logFilename(d.dirname, logNum))
+		}
+		if err == nil {
+			err = d.fs.SyncDir(d.dirname)
+		}
+		if err != nil {
+			d.mu.bgErr = err
+			return err
+		}
+		d.mu.log = newLogWriter(f, logNum)
+		d.mu.imm = append(d.mu.imm, d.mu.mem)
+		d.mu.mem = newMemTable(d.icmp, logNum)
+		d.mu.cond.Broadcast()
+		return nil
+	}
+}
+
+// Flush flushes the memtable to L0 and waits for the flush to complete.
+func (d *DB) Flush() error {
+	d.commitMu.Lock()
+	defer d.commitMu.Unlock()
+	return d.flushLocked()
+}
+
+// flushLocked is like Flush, but commitMu must be held.
+func (d *DB) flushLocked() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.makeRoomForWriteLocked(true); err != nil {
+		return err
+	}
+	if len(d.mu.imm) == 0 {
+		return nil
+	}
+	target := d.mu.imm[len(d.mu.imm)-1]
+	for {
+		if d.mu.bgErr != nil {
+			return d.mu.bgErr
+		}
+		if d.mu.closed {
+			return ErrClosed
+		}
+		flushed := true
+		for _, m := range d.mu.imm {
+			if m == target {
+				flushed = false
+			}
+		}
+		if flushed {
+			return nil
+		}
+		d.mu.cond.Wait()
+	}
+}
+
+// flushLoop flushes the immutable memtables in the background.
+func (d *DB) flushLoop() {
+	defer d.bgWG.Done()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for {
+		for !d.mu.closed && (len(d.mu.imm) == 0 || d.mu.bgErr != nil) {
+			d.mu.cond.Wait()
+		}
+		if d.mu.closed {
+			return
+		}
+		if err := d.flushOneLocked(); err != nil {
+			d.mu.bgErr = err
+			d.logf("flush failed: %v", err)
+		}
+		d.mu.cond.Broadcast()
+	}
+}
+
+// flushOneLocked writes the oldest immutable memtable to an L0 table.
+func (d *DB) flushOneLocked() error {
+	mem := d.mu.imm[0]
+	fileNum := d.mu.nextFileNum
+	d.mu.nextFileNum++
+	d.mu.pending[fileNum] = struct{}{}
+	defer delete(d.mu.pending, fileNum)
+
+	d.mu.Unlock()
+	meta, err := d.writeTable(mem.newIter(), fileNum)
+	d.mu.Lock()
+	if err != nil {
+		return err
+	}
+	// The oldest WAL still needed is the one of the next memtable.
+	logNum := d.mu.mem.logNum
+	if len(d.mu.imm) > 1 {
+		logNum = d.mu.imm[1].logNum
+	}
+	e := &versionEdit{
+		// The memtable must be removed in the same critical section that
+		// installs its table, or readers would observe its entries twice.
+		onInstall: func() { d.mu.imm = d.mu.imm[1:] },
+	}
+	if meta != nil {
+		e.added[0] = []*fileMetadata{meta}
+	}
+	oldLogNum := d.mu.logNum
+	if err := d.logAndApplyLocked(e, logNum); err != nil {
+		if meta != nil {
+			_ = d.fs.Remove(tableFilename(d.dirname, fileNum))
+		}
+		return err
+	}
+	for n := oldLogNum; n < logNum; n++ {
+		_ = d.fs.Remove(logFilename(d.dirname, n))
+	}
+	d.mu.metrics.Flushes++
+	if meta != nil {
+		d.logf("flushed memtable to table %d (%d bytes)", meta.fileNum, meta.size)
+	}
+	return nil
+}
+
+func (d *DB) logf(format string, args ...interface{}) {
+	if d.opts.Logger != nil {
+		d.opts.Logger.Infof(format, args...)
+	}
+}
+
+// Snapshot is a consistent view of the DB as of its creation. Compactions
+// retain the entries observed by open snapshots.
+type Snapshot struct {
+	db   *DB
+	seq  uint64
+	elem *list.Element
+}
+
+// NewSnapshot returns a snapshot of the latest state of the DB. It must be
+// closed when no longer needed.
+func (d *DB) NewSnapshot() *Snapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	s := &Snapshot{db: d, seq: atomic.LoadUint64(&d.visibleSeq)}
+	s.elem = d.mu.snapshots.PushBack(s)
+	return s
+}
+
+// NewIter returns an iterator over the snapshot.
+func (s *Snapshot) NewIter(o *IterOptions) *Iterator {
+	return s.db.newIter(o, s, nil, 0)
+}
+
+// Get returns the value of key in the snapshot. It returns ErrNotFound if
+// the key does not exist.
+func (s *Snapshot) Get(key []byte) ([]byte, error) {
+	return getFromIter(s.NewIter(nil), s.db.ucmp, key)
+}
+
+// Close releases the snapshot.
+func (s *Snapshot) Close() error {
+	d := s.db
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if s.elem != nil {
+		d.mu.snapshots.Remove(s.elem)
+		s.elem = nil
+	}
+	return nil
+}
+
+// snapshotSeqsLocked returns the sequence numbers of the open snapshots in
+// ascending order.
+func (d *DB) snapshotSeqsLocked() []uint64 {
+	seqs := make([]uint64, 0, d.mu.snapshots.Len())
+	for e := d.mu.snapshots.Front(); e != nil; e = e.Next() {
+		seqs = append(seqs, e.Value.(*Snapshot).seq)
+	}
+	return seqs
+}
+
+// Close closes the DB. Unflushed writes are recovered from the WAL when the
+// DB is reopened.
+func (d *DB) Close() error {
+	d.mu.Lock()
+	if d.mu.closed {
+		d.mu.Unlock()
+		return ErrClosed
+	}
+	d.mu.closed = true
+	d.mu.cond.Broadcast()
+	d.mu.Unlock()
+	d.bgWG.Wait()
+
+	// Wait for in-flight commits and ingestions.
+	d.commitMu.Lock()
+	defer d.commitMu.Unlock()
+	d.compactMu.Lock()
+	defer d.compactMu.Unlock()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	err := d.mu.log.close()
+	d.tc.close()
+	if lockErr := d.fileLock.Close(); err == nil {
+		err = lockErr
+	}
+	return err
+}
+
+func putUint64(b []byte, v uint64) {
+	for i := 0; i < 8; i++ {
+		b[i] = byte(v >> (8 * uint(i)))
+	}
+}
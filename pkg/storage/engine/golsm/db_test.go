@@ -0,0 +1,500 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+// concatMerger merges operands by concatenating them.
+type concatMerger struct{}
+
+func (concatMerger) FullMerge(key, existing []byte, operands [][]byte) ([]byte, error) {
+	res := append([]byte(nil), existing...)
+	for _, op := range operands {
+		res = append(res, op...)
+	}
+	return res, nil
+}
+
+func (concatMerger) PartialMerge(key []byte, operands [][]byte) ([]byte, error) {
+	return concatMerger{}.FullMerge(key, nil, operands)
+}
+
+func testOptions(fs FS) *Options {
+	return &Options{
+		FS:                    fs,
+		Merger:                concatMerger{},
+		MemTableSize:          4 << 10,
+		L0CompactionThreshold: 2,
+		LBaseMaxBytes:         16 << 10,
+		TargetFileSize:        4 << 10,
+		BlockSize:             512,
+		CacheSize:             64 << 10,
+	}
+}
+
+func openTestDB(t *testing.T, fs FS, dir string) *DB {
+	t.Helper()
+	d, err := Open(dir, testOptions(fs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+// model is the expected contents of a DB.
+type model map[string]string
+
+func (m model) clone() model {
+	c := make(model, len(m))
+	for k, v := range m {
+		c[k] = v
+	}
+	return c
+}
+
+func (m model) apply(kind keyKind, key, value string) {
+	switch kind {
+	case kindSet:
+		m[key] = value
+	case kindMerge:
+		m[key] += value
+	case kindDelete:
+		delete(m, key)
+	}
+}
+
+func (m model) deleteRange(start, end string) {
+	for k := range m {
+		if k >= start && k < end {
+			delete(m, k)
+		}
+	}
+}
+
+func (m model) sortedKeys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkIter verifies that it holds exactly the contents of m, iterating in
+// both directions.
+func checkIter(t *testing.T, it *Iterator, m model) {
+	t.Helper()
+	defer func() {
+		if err := it.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	keys := m.sortedKeys()
+	var fwd []string
+	for valid := it.First(); valid; valid = it.Next() {
+		fwd = append(fwd, string(it.Key()))
+		if v := string(it.Value()); v != m[string(it.Key())] {
+			t.Fatalf("key %q: expected %q, found %q", it.Key(), m[string(it.Key())], v)
+		}
+	}
+	if err := it.Error(); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(fwd) != fmt.Sprint(keys) && !(len(fwd) == 0 && len(keys) == 0) {
+		t.Fatalf("forward iteration: expected %v, found %v", keys, fwd)
+	}
+	var rev []string
+	for valid := it.Last(); valid; valid = it.Prev() {
+		rev = append([]string{string(it.Key())}, rev...)
+	}
+	if fmt.Sprint(rev) != fmt.Sprint(fwd) {
+		t.Fatalf("reverse iteration: expected %v, found %v", fwd, rev)
+	}
+}
+
+func randKey(rng *rand.Rand) string {
+	return fmt.Sprintf("key-%04d", rng.Intn(500))
+}
+
+// TestDBRandomized applies random operations to a DB and to a model of its
+// contents, checking that they agree through flushes, compactions,
+// snapshots and reopening.
+func TestDBRandomized(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	for _, useMem := range []bool{false, true} {
+		t.Run(fmt.Sprintf("mem=%t", useMem), func(t *testing.T) {
+			fs := DefaultFS
+			dir, err := ioutil.TempDir("", "golsm")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+			if useMem {
+				fs = NewMemFS()
+			}
+
+			rng, _ := randutil.NewPseudoRand()
+			d := openTestDB(t, fs, dir)
+			m := model{}
+			type snap struct {
+				s *Snapshot
+				m model
+			}
+			var snaps []snap
+
+			for i := 0; i < 3000; i++ {
+				switch op := rng.Intn(100); {
+				case op < 80:
+					b := d.NewBatch()
+					for n := rng.Intn(5) + 1; n > 0; n-- {
+						key := randKey(rng)
+						value := fmt.Sprintf("v%d.", i)
+						switch r := rng.Intn(10); {
+						case r < 5:
+							b.Set([]byte(key), []byte(value))
+							m.apply(kindSet, key, value)
+						case r < 7:
+							b.Merge([]byte(key), []byte(value))
+							m.apply(kindMerge, key, value)
+						case r < 9:
+							b.Delete([]byte(key))
+							m.apply(kindDelete, key, "")
+						default:
+							end := randKey(rng)
+							if end < key {
+								key, end = end, key
+							}
+							b.DeleteRange([]byte(key), []byte(end))
+							m.deleteRange(key, end)
+						}
+					}
+					if err := b.Commit(rng.Intn(10) == 0); err != nil {
+						t.Fatal(err)
+					}
+				case op < 85:
+					snaps = append(snaps, snap{s: d.NewSnapshot(), m: m.clone()})
+				case op < 88 && len(snaps) > 0:
+					j := rng.Intn(len(snaps))
+					checkIter(t, snaps[j].s.NewIter(nil), snaps[j].m)
+					if err := snaps[j].s.Close(); err != nil {
+						t.Fatal(err)
+					}
+					snaps = append(snaps[:j], snaps[j+1:]...)
+				case op < 90:
+					if err := d.Flush(); err != nil {
+						t.Fatal(err)
+					}
+				case op < 91:
+					if err := d.Compact(nil, nil); err != nil {
+						t.Fatal(err)
+					}
+				case op < 92:
+					for _, s := range snaps {
+						_ = s.s.Close()
+					}
+					snaps = nil
+					if err := d.Close(); err != nil {
+						t.Fatal(err)
+					}
+					d = openTestDB(t, fs, dir)
+				default:
+					key := randKey(rng)
+					v, err := d.Get([]byte(key))
+					expected, ok := m[key]
+					if !ok {
+						if err != ErrNotFound {
+							t.Fatalf("get %q: expected not found, found %q (%v)", key, v, err)
+						}
+					} else if err != nil || string(v) != expected {
+						t.Fatalf("get %q: expected %q, found %q (%v)", key, expected, v, err)
+					}
+				}
+			}
+			checkIter(t, d.NewIter(nil), m)
+			for _, s := range snaps {
+				checkIter(t, s.s.NewIter(nil), s.m)
+				_ = s.s.Close()
+			}
+			if err := d.Compact(nil, nil); err != nil {
+				t.Fatal(err)
+			}
+			checkIter(t, d.NewIter(nil), m)
+			if metrics := d.Metrics(); metrics.Flushes == 0 || metrics.Compactions == 0 {
+				t.Fatalf("expected flushes and compactions, found %+v", metrics)
+			}
+			if err := d.Close(); err != nil {
+				t.Fatal(err)
+			}
+		})
+	}
+}
+
+func TestIndexedBatch(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fs := NewMemFS()
+	d := openTestDB(t, fs, "/db")
+	defer d.Close()
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		if err := d.Set([]byte(k), []byte("db-"+k), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b := d.NewIndexedBatch()
+	b.Set([]byte("a"), []byte("batch-a"))
+	b.Merge([]byte("b"), []byte("+"))
+	b.DeleteRange([]byte("b"), []byte("d"))
+	b.Set([]byte("c"), []byte("batch-c"))
+	b.Set([]byte("e"), []byte("batch-e"))
+
+	checkIter(t, b.NewIter(nil), model{"a": "batch-a", "c": "batch-c", "d": "db-d", "e": "batch-e"})
+	// An iterator limited to the first two records does not observe the
+	// range deletion.
+	checkIter(t, b.NewIterAt(nil, 2), model{"a": "batch-a", "b": "db-b+", "c": "db-c", "d": "db-d"})
+	// The DB does not observe the batch until it is committed.
+	checkIter(t, d.NewIter(nil), model{"a": "db-a", "b": "db-b", "c": "db-c", "d": "db-d"})
+	if err := b.Commit(true); err != nil {
+		t.Fatal(err)
+	}
+	checkIter(t, d.NewIter(nil), model{"a": "batch-a", "c": "batch-c", "d": "db-d", "e": "batch-e"})
+
+	it := d.NewIter(&IterOptions{LowerBound: []byte("b"), UpperBound: []byte("e")})
+	checkIter(t, it, model{"c": "batch-c", "d": "db-d"})
+}
+
+func TestIterSeek(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	d := openTestDB(t, NewMemFS(), "/db")
+	defer d.Close()
+	for _, k := range []string{"b", "d", "f"} {
+		if err := d.Set([]byte(k), []byte(k), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Delete([]byte("d"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	it := d.NewIter(nil)
+	defer it.Close()
+	testCases := []struct {
+		op       func([]byte) bool
+		key      string
+		expected string
+	}{
+		{it.SeekGE, "a", "b"},
+		{it.SeekGE, "b", "b"},
+		{it.SeekGE, "c", "f"},
+		{it.SeekGE, "g", ""},
+		{it.SeekLT, "b", ""},
+		{it.SeekLT, "c", "b"},
+		{it.SeekLT, "f", "b"},
+		{it.SeekLT, "g", "f"},
+		{it.SeekLE, "a", ""},
+		{it.SeekLE, "b", "b"},
+		{it.SeekLE, "e", "b"},
+		{it.SeekLE, "f", "f"},
+	}
+	for i, c := range testCases {
+		valid := c.op([]byte(c.key))
+		var found string
+		if valid {
+			found = string(it.Key())
+		}
+		if found != c.expected {
+			t.Errorf("%d: seek %q: expected %q, found %q", i, c.key, c.expected, found)
+		}
+	}
+
+	// Switch directions.
+	if !it.SeekGE([]byte("b")) || !it.Next() || string(it.Key()) != "f" {
+		t.Fatalf("expected f")
+	}
+	if !it.Prev() || string(it.Key()) != "b" || it.Prev() {
+		t.Fatalf("expected b then exhaustion")
+	}
+}
+
+func TestIngest(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	dir, err := ioutil.TempDir("", "golsm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	d := openTestDB(t, DefaultFS, filepath.Join(dir, "db"))
+	for _, k := range []string{"a", "c", "x"} {
+		if err := d.Set([]byte(k), []byte("old-"+k), false); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := d.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	// Leave an overlapping write in the memtable.
+	if err := d.Set([]byte("b"), []byte("mem-b"), false); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, "ext.sst")
+	w, err := NewExternalTableWriter(DefaultFS, path, testOptions(DefaultFS))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, k := range []string{"b", "c", "d"} {
+		if err := w.Set([]byte(k), []byte("new-"+k)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Set([]byte("a"), nil); !testutils.IsError(err, "strictly increasing") {
+		t.Fatalf("expected ordering error, found %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Ingest([]string{path}); err != nil {
+		t.Fatal(err)
+	}
+	expected := model{"a": "old-a", "b": "new-b", "c": "new-c", "d": "new-d", "x": "old-x"}
+	checkIter(t, d.NewIter(nil), expected)
+
+	// Writes after the ingestion shadow it, across reopening and compaction.
+	if err := d.Merge([]byte("c"), []byte("+"), false); err != nil {
+		t.Fatal(err)
+	}
+	expected["c"] += "+"
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d = openTestDB(t, DefaultFS, filepath.Join(dir, "db"))
+	checkIter(t, d.NewIter(nil), expected)
+	if err := d.Compact(nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	checkIter(t, d.NewIter(nil), expected)
+
+	// A checkpoint is an independent copy of the DB.
+	if err := d.Checkpoint(filepath.Join(dir, "checkpoint")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Delete([]byte("a"), false); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	cp := openTestDB(t, DefaultFS, filepath.Join(dir, "checkpoint"))
+	defer cp.Close()
+	checkIter(t, cp.NewIter(nil), expected)
+}
+
+func TestLock(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	fs := NewMemFS()
+	d := openTestDB(t, fs, "/db")
+	if _, err := Open("/db", testOptions(fs)); !testutils.IsError(err, "lock") {
+		t.Fatalf("expected lock error, found %v", err)
+	}
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+	d = openTestDB(t, fs, "/db")
+	if err := d.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReplayLog(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// The log holds the records "a", "bb" and "ccc".
+	var log []byte
+	var ends []int
+	for _, payload := range []string{"a", "bb", "ccc"} {
+		var hdr [walHeaderLen]byte
+		binary.LittleEndian.PutUint32(hdr[0:4], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(hdr[4:8], crc32.Checksum([]byte(payload), crcTable))
+		log = append(append(log, hdr[:]...), payload...)
+		ends = append(ends, len(log))
+	}
+	corrupt := func(offset int) []byte {
+		b := append([]byte(nil), log...)
+		b[offset] ^= 0xff
+		return b
+	}
+
+	testCases := []struct {
+		name     string
+		data     []byte
+		tornTail bool
+		expected []string
+		err      string
+	}{
+		{"intact", log, false, []string{"a", "bb", "ccc"}, ""},
+		{"truncated header", log[:ends[1]+3], true, []string{"a", "bb"}, ""},
+		{"truncated payload", log[:len(log)-1], true, []string{"a", "bb"}, ""},
+		{"corrupt last record", corrupt(len(log) - 1), true, []string{"a", "bb"}, ""},
+		{"truncated header, not torn", log[:ends[1]+3], false, nil, "truncated record header at offset 19"},
+		{"truncated payload, not torn", log[:len(log)-1], false, nil, "truncated record at offset 19"},
+		{"corrupt last record, not torn", corrupt(len(log) - 1), false, nil, "corrupt record at offset 19"},
+		{"corrupt middle record", corrupt(ends[1] - 1), true, nil, "corrupt record at offset 9"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fs := NewMemFS()
+			f, err := fs.Create("/000001.log")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := f.Write(tc.data); err != nil {
+				t.Fatal(err)
+			}
+			if err := f.Close(); err != nil {
+				t.Fatal(err)
+			}
+			var payloads []string
+			err = replayLog(fs, "/000001.log", tc.tornTail, func(payload []byte) error {
+				payloads = append(payloads, string(payload))
+				return nil
+			})
+			if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+			if tc.err == "" && !reflect.DeepEqual(tc.expected, payloads) {
+				t.Fatalf("expected %q, got %q", tc.expected, payloads)
+			}
+		})
+	}
+}
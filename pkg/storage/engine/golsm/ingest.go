@@ -0,0 +1,390 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// ExternalTableWriter writes a table that can be added to a DB with Ingest.
+// Keys must be added in strictly increasing order.
+type ExternalTableWriter struct {
+	w       *tableWriter
+	ucmp    func(a, b []byte) int
+	lastKey []byte
+	empty   bool
+}
+
+// NewExternalTableWriter creates a table at path. The comparison function
+// must match the one of the DB the table is ingested into.
+func NewExternalTableWriter(fs FS, path string, opts *Options) (*ExternalTableWriter, error) {
+	o := Options{}
+	if opts != nil {
+		o = *opts
+	}
+	o.EnsureDefaults()
+	if fs == nil {
+		fs = o.FS
+	}
+	f, err := fs.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalTableWriter{w: newTableWriter(f, o.BlockSize), ucmp: o.Compare, empty: true}, nil
+}
+
+func (w *ExternalTableWriter) add(key, value []byte, kind keyKind) error {
+	if !w.empty && w.ucmp(key, w.lastKey) <= 0 {
+		return errors.Errorf("keys must be added in strictly increasing order: %q after %q", key, w.lastKey)
+	}
+	w.empty = false
+	w.lastKey = append(w.lastKey[:0], key...)
+	return w.w.add(makeInternalKey(nil, key, 0, kind), value)
+}
+
+// Set adds a value for key.
+func (w *ExternalTableWriter) Set(key, value []byte) error {
+	return w.add(key, value, kindSet)
+}
+
+// Delete adds a deletion of key.
+func (w *ExternalTableWriter) Delete(key []byte) error {
+	return w.add(key, nil, kindDelete)
+}
+
+// Merge adds a merge operand for key.
+func (w *ExternalTableWriter) Merge(key, value []byte) error {
+	return w.add(key, value, kindMerge)
+}
+
+// Empty returns true if no keys were added.
+func (w *ExternalTableWriter) Empty() bool {
+	return w.empty
+}
+
+// Close finishes the table.
+func (w *ExternalTableWriter) Close() error {
+	_, _, err := w.w.finish()
+	return err
+}
+
+type ingestedFile struct {
+	path string
+	meta *fileMetadata
+}
+
+// Ingest adds the tables written by ExternalTableWriters to the DB. The
+// tables are linked (or copied) into the DB directory, so the caller may
+// delete the files afterwards. Each table is assigned a sequence number that
+// is newer than every existing entry and is placed in the lowest level it
+// doesn't overlap with any newer data.
+func (d *DB) Ingest(paths []string) error {
+	var files []ingestedFile
+	for _, path := range paths {
+		props, size, err := d.readExternalProps(path)
+		if err != nil {
+			return errors.Wrapf(err, "reading %s", path)
+		}
+		if props.numEntries == 0 {
+			continue
+		}
+		files = append(files, ingestedFile{path: path, meta: &fileMetadata{size: size, smallest: props.smallest, largest: props.largest}})
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	d.commitMu.Lock()
+	defer d.commitMu.Unlock()
+	// Entries in the memtables are older than the ingested tables but would
+	// be flushed above them, so flush them first if they overlap. This
+	// happens before acquiring compactMu because the flush may have to wait
+	// for compactions.
+	if d.memTablesOverlap(files) {
+		if err := d.flushLocked(); err != nil {
+			return err
+		}
+	}
+	d.compactMu.Lock()
+	defer d.compactMu.Unlock()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.mu.bgErr != nil {
+		return d.mu.bgErr
+	}
+	e := &versionEdit{}
+	seq := d.lastSeq
+	var linked []uint64
+	for _, f := range files {
+		seq++
+		fileNum := d.mu.nextFileNum
+		d.mu.nextFileNum++
+		d.mu.pending[fileNum] = struct{}{}
+		linked = append(linked, fileNum)
+		if err := linkOrCopyFile(d.fs, f.path, tableFilename(d.dirname, fileNum)); err != nil {
+			d.removeIngestedLocked(linked)
+			return err
+		}
+		props := tableProps{smallest: f.meta.smallest, largest: f.meta.largest}
+		meta := newFileMetadata(fileNum, f.meta.size, props, seq)
+		level := d.ingestTargetLevelLocked(e, meta)
+		e.added[level] = append(e.added[level], meta)
+	}
+	if err := d.fs.SyncDir(d.dirname); err != nil {
+		d.removeIngestedLocked(linked)
+		return err
+	}
+	e.lastSeq = seq
+	if err := d.logAndApplyLocked(e, 0); err != nil {
+		d.removeIngestedLocked(linked)
+		return err
+	}
+	for _, num := range linked {
+		delete(d.mu.pending, num)
+	}
+	d.lastSeq = seq
+	atomic.StoreUint64(&d.visibleSeq, seq)
+	d.mu.metrics.Ingestions += int64(len(files))
+	d.mu.cond.Broadcast()
+	return nil
+}
+
+func (d *DB) removeIngestedLocked(fileNums []uint64) {
+	for _, num := range fileNums {
+		_ = d.fs.Remove(tableFilename(d.dirname, num))
+		delete(d.mu.pending, num)
+	}
+}
+
+func (d *DB) readExternalProps(path string) (tableProps, uint64, error) {
+	f, err := d.fs.Open(path)
+	if err != nil {
+		return tableProps{}, 0, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return tableProps{}, 0, err
+	}
+	t, err := openTable(f, uint64(info.Size()), d.ucmp, nil, 0)
+	if err != nil {
+		_ = f.Close()
+		return tableProps{}, 0, err
+	}
+	props := t.props
+	return props, uint64(info.Size()), t.close()
+}
+
+// memTablesOverlap returns true if any memtable holds a key in the user key
+// range of one of the ingested tables.
+func (d *DB) memTablesOverlap(files []ingestedFile) bool {
+	rs := d.loadReadState()
+	defer d.releaseReadState(rs)
+	mems := append([]*memTable{rs.mem}, rs.imm...)
+	for _, f := range files {
+		smallest, largest := userKey(f.meta.smallest), userKey(f.meta.largest)
+		for _, m := range mems {
+			it := m.newIter()
+			if it.SeekGE(makeSeekKey(smallest)) && d.ucmp(userKey(it.Key()), largest) <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ingestTargetLevelLocked returns the lowest level a table can be placed in:
+// the table must not overlap any table in that level or the levels above,
+// nor any table already added by the edit.
+func (d *DB) ingestTargetLevelLocked(e *versionEdit, meta *fileMetadata) int {
+	smallest, largest := userKey(meta.smallest), userKey(meta.largest)
+	overlaps := func(level int) bool {
+		if len(d.mu.current.overlaps(level, d.ucmp, smallest, largest)) > 0 {
+			return true
+		}
+		for _, f := range e.added[level] {
+			if d.ucmp(userKey(f.largest), smallest) >= 0 && d.ucmp(userKey(f.smallest), largest) <= 0 {
+				return true
+			}
+		}
+		return false
+	}
+	// Tables added by the edit at a level shadow the levels below it as well.
+	addedAbove := func(level int) bool {
+		for l := 0; l < level; l++ {
+			for _, f := range e.added[l] {
+				if d.ucmp(userKey(f.largest), smallest) >= 0 && d.ucmp(userKey(f.smallest), largest) <= 0 {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	if overlaps(0) {
+		return 0
+	}
+	target := 0
+	for level := 1; level < numLevels; level++ {
+		if overlaps(level) || addedAbove(level) {
+			break
+		}
+		target = level
+	}
+	return target
+}
+
+// Checkpoint creates a consistent copy of the DB in destDir, which must not
+// exist. Tables are hard linked when possible.
+func (d *DB) Checkpoint(destDir string) error {
+	if _, err := d.fs.Stat(destDir); err == nil {
+		return errors.Errorf("checkpoint directory %s already exists", destDir)
+	}
+	d.commitMu.Lock()
+	defer d.commitMu.Unlock()
+	// Flush so that the tables hold every committed write.
+	if err := d.flushLocked(); err != nil {
+		return err
+	}
+	d.manifestMu.Lock()
+	defer d.manifestMu.Unlock()
+	d.mu.Lock()
+	v := d.mu.current
+	v.refs++
+	m := &manifest{
+		nextFileNum: d.mu.nextFileNum + 1,
+		logNum:      d.mu.nextFileNum,
+		lastSeq:     d.lastSeq,
+		files:       v.files,
+	}
+	d.mu.Unlock()
+	defer d.releaseReadState(&readState{v: v})
+
+	if err := d.fs.MkdirAll(destDir, 0755); err != nil {
+		return err
+	}
+	for _, files := range v.files {
+		for _, f := range files {
+			if err := linkOrCopyFile(
+				d.fs, tableFilename(d.dirname, f.fileNum), tableFilename(destDir, f.fileNum),
+			); err != nil {
+				return err
+			}
+		}
+	}
+	return writeManifest(d.fs, destDir, m.nextFileNum-1, m)
+}
+
+// TableInfo describes a table of the DB.
+type TableInfo struct {
+	Level    int
+	FileNum  uint64
+	Size     uint64
+	Smallest []byte
+	Largest  []byte
+}
+
+// Tables returns the tables of the DB, ordered by level.
+func (d *DB) Tables() []TableInfo {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var res []TableInfo
+	for level, files := range d.mu.current.files {
+		for _, f := range files {
+			res = append(res, TableInfo{
+				Level:    level,
+				FileNum:  f.fileNum,
+				Size:     f.size,
+				Smallest: userKey(f.smallest),
+				Largest:  userKey(f.largest),
+			})
+		}
+	}
+	return res
+}
+
+// EstimateDiskUsage returns the total size of the tables overlapping the
+// user key range [start, end].
+func (d *DB) EstimateDiskUsage(start, end []byte) uint64 {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	var size uint64
+	for level := range d.mu.current.files {
+		size += levelSize(d.mu.current.overlaps(level, d.ucmp, start, end))
+	}
+	return size
+}
+
+// Metrics holds statistics about the DB.
+type Metrics struct {
+	Flushes     int64
+	Compactions int64
+	Ingestions  int64
+	// MemTableSize is the approximate size of the mutable and immutable
+	// memtables.
+	MemTableSize int64
+	LevelFiles   [numLevels]int
+	LevelSizes   [numLevels]uint64
+	// PendingCompactionBytes estimates the number of bytes that compactions
+	// must rewrite to bring every level within its target size.
+	PendingCompactionBytes uint64
+	BlockCacheSize         int64
+	BlockCacheHits         int64
+	BlockCacheMisses       int64
+	NumSnapshots           int
+	// NumLevels is the number of levels of the LSM.
+	NumLevels int
+}
+
+// Metrics returns the current statistics of the DB.
+func (d *DB) Metrics() Metrics {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	m := d.mu.metrics
+	m.NumLevels = numLevels
+	m.MemTableSize = d.mu.mem.approximateSize()
+	for _, mem := range d.mu.imm {
+		m.MemTableSize += mem.approximateSize()
+	}
+	maxBytes := d.levelMaxBytes()
+	for level, files := range d.mu.current.files {
+		m.LevelFiles[level] = len(files)
+		m.LevelSizes[level] = levelSize(files)
+		if level > 0 && level < numLevels-1 && float64(m.LevelSizes[level]) > maxBytes[level] {
+			m.PendingCompactionBytes += m.LevelSizes[level] - uint64(maxBytes[level])
+		}
+	}
+	if len(d.mu.current.files[0]) >= d.opts.L0CompactionThreshold {
+		m.PendingCompactionBytes += m.LevelSizes[0]
+	}
+	cs := d.cache.stats()
+	m.BlockCacheSize, m.BlockCacheHits, m.BlockCacheMisses = cs.size, cs.hits, cs.misses
+	m.NumSnapshots = d.mu.snapshots.Len()
+	return m
+}
+
+// Dir returns the directory of the DB.
+func (d *DB) Dir() string {
+	return d.dirname
+}
+
+// FS returns the file system of the DB.
+func (d *DB) FS() FS {
+	return d.fs
+}
+
+// Path returns the path of the named file in the DB directory.
+func (d *DB) Path(name string) string {
+	return filepath.Join(d.dirname, name)
+}
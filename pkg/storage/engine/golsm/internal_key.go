@@ -0,0 +1,110 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import "encoding/binary"
+
+// keyKind is the kind of an entry stored in the LSM.
+type keyKind uint8
+
+const (
+	kindDelete keyKind = 0
+	kindSet    keyKind = 1
+	kindMerge  keyKind = 2
+	// kindDeleteRange only appears in batches. It is expanded into point
+	// deletions when the batch is committed.
+	kindDeleteRange keyKind = 15
+	// kindMax sorts before every other kind for the same user key and
+	// sequence number, which makes it suitable for seek keys.
+	kindMax keyKind = 0xff
+)
+
+const (
+	// trailerLen is the length of the trailer appended to user keys to form
+	// internal keys. The trailer is (seqNum << 8 | kind) encoded as a
+	// little-endian uint64.
+	trailerLen = 8
+	// seqNumMax is the largest sequence number.
+	seqNumMax = uint64(1)<<56 - 1
+	// seqNumBatchFlag marks the sequence numbers of entries in an indexed
+	// batch. Such entries are newer than anything in the DB, and the low bits
+	// hold the position of the entry in the batch.
+	seqNumBatchFlag = uint64(1) << 55
+)
+
+// makeInternalKey appends the internal key for (ukey, seq, kind) to dst.
+func makeInternalKey(dst, ukey []byte, seq uint64, kind keyKind) []byte {
+	dst = append(dst, ukey...)
+	var buf [trailerLen]byte
+	binary.LittleEndian.PutUint64(buf[:], seq<<8|uint64(kind))
+	return append(dst, buf[:]...)
+}
+
+// makeSeekKey returns the internal key that sorts before all the entries for
+// ukey.
+func makeSeekKey(ukey []byte) []byte {
+	return makeInternalKey(make([]byte, 0, len(ukey)+trailerLen), ukey, seqNumMax, kindMax)
+}
+
+// userKey returns the user key portion of an internal key.
+func userKey(ikey []byte) []byte {
+	return ikey[:len(ikey)-trailerLen]
+}
+
+// trailer returns the trailer of an internal key.
+func trailer(ikey []byte) uint64 {
+	return binary.LittleEndian.Uint64(ikey[len(ikey)-trailerLen:])
+}
+
+// decodeTrailer splits a trailer into its sequence number and kind.
+func decodeTrailer(t uint64) (uint64, keyKind) {
+	return t >> 8, keyKind(t & 0xff)
+}
+
+// validInternalKey returns true if ikey is long enough to hold a trailer.
+func validInternalKey(ikey []byte) bool {
+	return len(ikey) >= trailerLen
+}
+
+// internalCompare orders internal keys by user key ascending, then by trailer
+// descending so that newer entries come first.
+type internalCompare func(a, b []byte) int
+
+func makeInternalCompare(ucmp func(a, b []byte) int) internalCompare {
+	return func(a, b []byte) int {
+		if c := ucmp(userKey(a), userKey(b)); c != 0 {
+			return c
+		}
+		ta, tb := trailer(a), trailer(b)
+		if ta > tb {
+			return -1
+		} else if ta < tb {
+			return 1
+		}
+		return 0
+	}
+}
+
+// internalIterator iterates over internal keys in internal key order.
+// Positioning methods return whether the iterator is valid.
+type internalIterator interface {
+	SeekGE(key []byte) bool
+	SeekLT(key []byte) bool
+	First() bool
+	Last() bool
+	Next() bool
+	Prev() bool
+	Valid() bool
+	Key() []byte
+	Value() []byte
+	Error() error
+	Close() error
+}
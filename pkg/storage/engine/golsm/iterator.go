@@ -0,0 +1,351 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import "github.com/pkg/errors"
+
+// ErrNotFound is returned by Get when the key does not exist.
+var ErrNotFound = errors.New("golsm: not found")
+
+// IterOptions configures an Iterator.
+type IterOptions struct {
+	// LowerBound, if set, is the inclusive lower bound of the iterator.
+	LowerBound []byte
+	// UpperBound, if set, is the exclusive upper bound of the iterator.
+	UpperBound []byte
+}
+
+// Iterator iterates over the user keys of the DB, as of the sequence number
+// at which it was created, merged with the writes of an indexed batch if it
+// was created from one. Deleted keys are skipped and merge operands are
+// combined with the Merger.
+type Iterator struct {
+	ucmp   func(a, b []byte) int
+	merger Merger
+	iter   internalIterator
+	// readSeq is the highest DB sequence number visible to the iterator.
+	readSeq uint64
+	// batchCount is the number of batch records visible to the iterator.
+	batchCount uint64
+	rangeDels  []batchRangeDel
+	lower      []byte
+	upper      []byte
+
+	// reverse is true if the last positioning operation moved backward. When
+	// iterating forward, iter is positioned at the first entry after the
+	// current key. When iterating backward, it is positioned at the last
+	// entry before the current key.
+	reverse bool
+	valid   bool
+	key     []byte
+	value   []byte
+	err     error
+
+	entries  []iterEntry
+	operands [][]byte
+	release  func()
+}
+
+type iterEntry struct {
+	seq   uint64
+	kind  keyKind
+	value []byte
+}
+
+// visible returns true if the entry with the given sequence number is
+// visible to the iterator and not shadowed by a range deletion in the batch.
+func (i *Iterator) visible(ukey []byte, seq uint64) (visible bool, rangeDeleted bool) {
+	if seq&seqNumBatchFlag != 0 {
+		if seq&^seqNumBatchFlag >= i.batchCount {
+			return false, false
+		}
+	} else if seq > i.readSeq {
+		return false, false
+	}
+	for _, rd := range i.rangeDels {
+		if seq&seqNumBatchFlag != 0 && seq&^seqNumBatchFlag > uint64(rd.idx) {
+			continue
+		}
+		if i.ucmp(rd.start, ukey) <= 0 && i.ucmp(ukey, rd.end) < 0 {
+			return true, true
+		}
+	}
+	return true, false
+}
+
+// collect appends the visible entry at the current position of the internal
+// iterator to the entries of the current key.
+func (i *Iterator) collect(ukey []byte) {
+	seq, kind := decodeTrailer(trailer(i.iter.Key()))
+	visible, rangeDeleted := i.visible(ukey, seq)
+	if !visible {
+		return
+	}
+	if rangeDeleted {
+		kind = kindDelete
+	}
+	i.entries = append(i.entries, iterEntry{seq: seq, kind: kind, value: i.iter.Value()})
+}
+
+// resolve computes the value of the key from its visible entries, which must
+// be ordered from newest to oldest.
+func (i *Iterator) resolve(ukey []byte) (value []byte, ok bool) {
+	i.operands = i.operands[:0]
+	for _, e := range i.entries {
+		switch e.kind {
+		case kindSet:
+			if len(i.operands) == 0 {
+				return e.value, true
+			}
+			return i.fullMerge(ukey, e.value)
+		case kindDelete:
+			if len(i.operands) == 0 {
+				return nil, false
+			}
+			return i.fullMerge(ukey, nil)
+		case kindMerge:
+			i.operands = append(i.operands, e.value)
+		}
+	}
+	if len(i.operands) == 0 {
+		return nil, false
+	}
+	return i.fullMerge(ukey, nil)
+}
+
+func (i *Iterator) fullMerge(ukey, existing []byte) ([]byte, bool) {
+	if i.merger == nil {
+		i.err = errors.New("golsm: merge operand encountered without a Merger")
+		return nil, false
+	}
+	// The operands were collected newest first.
+	ops := i.operands
+	for l, r := 0, len(ops)-1; l < r; l, r = l+1, r-1 {
+		ops[l], ops[r] = ops[r], ops[l]
+	}
+	v, err := i.merger.FullMerge(ukey, existing, ops)
+	if err != nil {
+		i.err = err
+		return nil, false
+	}
+	return v, true
+}
+
+// findNextEntry moves forward from the current position of the internal
+// iterator to the first key with a value.
+func (i *Iterator) findNextEntry() bool {
+	i.valid = false
+	i.reverse = false
+	for i.err == nil && i.iter.Valid() {
+		ukey := userKey(i.iter.Key())
+		if i.upper != nil && i.ucmp(ukey, i.upper) >= 0 {
+			break
+		}
+		i.key = append(i.key[:0], ukey...)
+		i.entries = i.entries[:0]
+		for i.iter.Valid() && i.ucmp(userKey(i.iter.Key()), i.key) == 0 {
+			i.collect(i.key)
+			i.iter.Next()
+		}
+		if value, ok := i.resolve(i.key); ok {
+			i.value = value
+			i.valid = true
+			return true
+		}
+	}
+	if i.err == nil {
+		i.err = i.iter.Error()
+	}
+	return false
+}
+
+// findPrevEntry moves backward from the current position of the internal
+// iterator to the last key with a value.
+func (i *Iterator) findPrevEntry() bool {
+	i.valid = false
+	i.reverse = true
+	for i.err == nil && i.iter.Valid() {
+		ukey := userKey(i.iter.Key())
+		if i.lower != nil && i.ucmp(ukey, i.lower) < 0 {
+			break
+		}
+		i.key = append(i.key[:0], ukey...)
+		i.entries = i.entries[:0]
+		for i.iter.Valid() && i.ucmp(userKey(i.iter.Key()), i.key) == 0 {
+			i.collect(i.key)
+			i.iter.Prev()
+		}
+		// The entries were collected oldest first.
+		for l, r := 0, len(i.entries)-1; l < r; l, r = l+1, r-1 {
+			i.entries[l], i.entries[r] = i.entries[r], i.entries[l]
+		}
+		if value, ok := i.resolve(i.key); ok {
+			i.value = value
+			i.valid = true
+			return true
+		}
+	}
+	if i.err == nil {
+		i.err = i.iter.Error()
+	}
+	return false
+}
+
+// SeekGE moves the iterator to the first key >= key.
+func (i *Iterator) SeekGE(key []byte) bool {
+	if i.lower != nil && i.ucmp(key, i.lower) < 0 {
+		key = i.lower
+	}
+	i.err = nil
+	i.iter.SeekGE(makeSeekKey(key))
+	return i.findNextEntry()
+}
+
+// SeekLT moves the iterator to the last key < key.
+func (i *Iterator) SeekLT(key []byte) bool {
+	if i.upper != nil && i.ucmp(key, i.upper) > 0 {
+		key = i.upper
+	}
+	i.err = nil
+	i.iter.SeekLT(makeSeekKey(key))
+	return i.findPrevEntry()
+}
+
+// SeekLE moves the iterator to the last key <= key.
+func (i *Iterator) SeekLE(key []byte) bool {
+	if i.upper != nil && i.ucmp(key, i.upper) >= 0 {
+		return i.SeekLT(i.upper)
+	}
+	i.err = nil
+	// The internal key with a zero trailer sorts after every entry for the
+	// user key, since sequence numbers start at 1.
+	i.iter.SeekLT(makeInternalKey(nil, key, 0, 0))
+	return i.findPrevEntry()
+}
+
+// First moves the iterator to the first key.
+func (i *Iterator) First() bool {
+	if i.lower != nil {
+		return i.SeekGE(i.lower)
+	}
+	i.err = nil
+	i.iter.First()
+	return i.findNextEntry()
+}
+
+// Last moves the iterator to the last key.
+func (i *Iterator) Last() bool {
+	if i.upper != nil {
+		return i.SeekLT(i.upper)
+	}
+	i.err = nil
+	i.iter.Last()
+	return i.findPrevEntry()
+}
+
+// Next moves the iterator to the next key.
+func (i *Iterator) Next() bool {
+	if !i.valid {
+		return false
+	}
+	if i.reverse {
+		// Skip past the entries of the current key.
+		if i.iter.Valid() {
+			i.iter.Next()
+		} else {
+			i.iter.First()
+		}
+		for i.iter.Valid() && i.ucmp(userKey(i.iter.Key()), i.key) <= 0 {
+			i.iter.Next()
+		}
+	}
+	return i.findNextEntry()
+}
+
+// Prev moves the iterator to the previous key.
+func (i *Iterator) Prev() bool {
+	if !i.valid {
+		return false
+	}
+	if !i.reverse {
+		if i.iter.Valid() {
+			i.iter.Prev()
+		} else {
+			i.iter.Last()
+		}
+		for i.iter.Valid() && i.ucmp(userKey(i.iter.Key()), i.key) >= 0 {
+			i.iter.Prev()
+		}
+	}
+	return i.findPrevEntry()
+}
+
+// Valid returns true if the iterator is positioned at a key.
+func (i *Iterator) Valid() bool {
+	return i.valid
+}
+
+// Key returns the current key. The slice is only valid until the iterator
+// is repositioned.
+func (i *Iterator) Key() []byte {
+	return i.key
+}
+
+// Value returns the current value. The slice is only valid until the
+// iterator is repositioned.
+func (i *Iterator) Value() []byte {
+	return i.value
+}
+
+// Error returns the error, if any, encountered while positioning the
+// iterator.
+func (i *Iterator) Error() error {
+	return i.err
+}
+
+// SetBounds changes the bounds of the iterator. The iterator must be
+// repositioned afterwards.
+func (i *Iterator) SetBounds(lower, upper []byte) {
+	i.lower, i.upper = lower, upper
+	i.valid = false
+}
+
+// Close releases the resources held by the iterator.
+func (i *Iterator) Close() error {
+	err := i.iter.Close()
+	if i.release != nil {
+		i.release()
+		i.release = nil
+	}
+	return err
+}
+
+// getFromIter returns the value of key using a new iterator, which it closes.
+func getFromIter(it *Iterator, ucmp func(a, b []byte) int, key []byte) ([]byte, error) {
+	defer it.Close()
+	it.iter.SeekGE(makeSeekKey(key))
+	it.entries = it.entries[:0]
+	for it.iter.Valid() && ucmp(userKey(it.iter.Key()), key) == 0 {
+		it.collect(key)
+		it.iter.Next()
+	}
+	if err := it.iter.Error(); err != nil {
+		return nil, err
+	}
+	value, ok := it.resolve(key)
+	if it.err != nil {
+		return nil, it.err
+	}
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), value...), nil
+}
@@ -0,0 +1,24 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// +build windows
+
+package golsm
+
+import (
+	"io"
+	"os"
+)
+
+// lockFile opens the named file. Advisory locks are not implemented on
+// Windows, so the caller must ensure that a DB is used by a single process.
+func lockFile(name string) (io.Closer, error) {
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE, 0644)
+}
@@ -0,0 +1,57 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import "sync/atomic"
+
+// memTable is an in-memory table of recent writes, backed by a skiplist. It
+// is written by the commit pipeline and read concurrently by iterators.
+type memTable struct {
+	list *skiplist
+	// logNum is the number of the WAL holding the memtable's writes.
+	logNum uint64
+	// size is the approximate number of bytes in the memtable. Accessed
+	// atomically.
+	size int64
+}
+
+func newMemTable(cmp internalCompare, logNum uint64) *memTable {
+	return &memTable{list: newSkiplist(cmp), logNum: logNum}
+}
+
+// add inserts an entry. The key and value are copied. Calls to add must be
+// serialized.
+func (m *memTable) add(seq uint64, kind keyKind, key, value []byte) {
+	buf := make([]byte, 0, len(key)+trailerLen+len(value))
+	ikey := makeInternalKey(buf, key, seq, kind)
+	var v []byte
+	if len(value) > 0 {
+		v = append(ikey[len(ikey):len(ikey)], value...)
+	}
+	m.list.add(ikey, v)
+	atomic.AddInt64(&m.size, int64(len(ikey)+len(value)+skiplistNodeOverhead))
+}
+
+// skiplistNodeOverhead approximates the memory used by a skiplist node in
+// addition to its key and value.
+const skiplistNodeOverhead = 64
+
+func (m *memTable) approximateSize() int64 {
+	return atomic.LoadInt64(&m.size)
+}
+
+func (m *memTable) empty() bool {
+	return m.list.empty()
+}
+
+func (m *memTable) newIter() internalIterator {
+	return m.list.newIter()
+}
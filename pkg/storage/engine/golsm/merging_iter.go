@@ -0,0 +1,200 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+// mergingIter merges a set of internalIterators into a single internal key
+// ordered stream. It maintains a heap of the valid children: a min-heap when
+// iterating forward and a max-heap when iterating backward.
+type mergingIter struct {
+	cmp   internalCompare
+	iters []internalIterator
+	heap  []int
+	// reverse is true if the heap is a max-heap.
+	reverse bool
+	err     error
+	keyBuf  []byte
+}
+
+var _ internalIterator = &mergingIter{}
+
+func newMergingIter(cmp internalCompare, iters ...internalIterator) *mergingIter {
+	return &mergingIter{cmp: cmp, iters: iters, heap: make([]int, 0, len(iters))}
+}
+
+func (m *mergingIter) less(i, j int) bool {
+	c := m.cmp(m.iters[m.heap[i]].Key(), m.iters[m.heap[j]].Key())
+	if m.reverse {
+		return c > 0
+	}
+	return c < 0
+}
+
+func (m *mergingIter) up(j int) {
+	for j > 0 {
+		i := (j - 1) / 2
+		if !m.less(j, i) {
+			break
+		}
+		m.heap[i], m.heap[j] = m.heap[j], m.heap[i]
+		j = i
+	}
+}
+
+func (m *mergingIter) down(i int) {
+	n := len(m.heap)
+	for {
+		j := 2*i + 1
+		if j >= n {
+			return
+		}
+		if r := j + 1; r < n && m.less(r, j) {
+			j = r
+		}
+		if !m.less(j, i) {
+			return
+		}
+		m.heap[i], m.heap[j] = m.heap[j], m.heap[i]
+		i = j
+	}
+}
+
+// initHeap rebuilds the heap from the children that are valid.
+func (m *mergingIter) initHeap(reverse bool) bool {
+	m.reverse = reverse
+	m.heap = m.heap[:0]
+	for i, it := range m.iters {
+		if it.Valid() {
+			m.heap = append(m.heap, i)
+		} else if err := it.Error(); err != nil && m.err == nil {
+			m.err = err
+		}
+	}
+	for i := len(m.heap)/2 - 1; i >= 0; i-- {
+		m.down(i)
+	}
+	return m.Valid()
+}
+
+// fixTop restores the heap invariant after the top child moved.
+func (m *mergingIter) fixTop() bool {
+	top := m.iters[m.heap[0]]
+	if top.Valid() {
+		m.down(0)
+	} else {
+		if err := top.Error(); err != nil && m.err == nil {
+			m.err = err
+		}
+		last := len(m.heap) - 1
+		m.heap[0] = m.heap[last]
+		m.heap = m.heap[:last]
+		if len(m.heap) > 0 {
+			m.down(0)
+		}
+	}
+	return m.Valid()
+}
+
+func (m *mergingIter) SeekGE(key []byte) bool {
+	m.err = nil
+	for _, it := range m.iters {
+		it.SeekGE(key)
+	}
+	return m.initHeap(false)
+}
+
+func (m *mergingIter) SeekLT(key []byte) bool {
+	m.err = nil
+	for _, it := range m.iters {
+		it.SeekLT(key)
+	}
+	return m.initHeap(true)
+}
+
+func (m *mergingIter) First() bool {
+	m.err = nil
+	for _, it := range m.iters {
+		it.First()
+	}
+	return m.initHeap(false)
+}
+
+func (m *mergingIter) Last() bool {
+	m.err = nil
+	for _, it := range m.iters {
+		it.Last()
+	}
+	return m.initHeap(true)
+}
+
+func (m *mergingIter) Next() bool {
+	if m.reverse {
+		// Position every child at the first key after the current one. The
+		// keys of the children are distinct, so no child other than the
+		// current one can be positioned at the current key.
+		cur := m.heap[0]
+		m.keyBuf = append(m.keyBuf[:0], m.Key()...)
+		for i, it := range m.iters {
+			if i == cur {
+				it.Next()
+				continue
+			}
+			if it.SeekGE(m.keyBuf) && m.cmp(it.Key(), m.keyBuf) == 0 {
+				it.Next()
+			}
+		}
+		return m.initHeap(false)
+	}
+	m.iters[m.heap[0]].Next()
+	return m.fixTop()
+}
+
+func (m *mergingIter) Prev() bool {
+	if !m.reverse {
+		cur := m.heap[0]
+		m.keyBuf = append(m.keyBuf[:0], m.Key()...)
+		for i, it := range m.iters {
+			if i == cur {
+				it.Prev()
+				continue
+			}
+			it.SeekLT(m.keyBuf)
+		}
+		return m.initHeap(true)
+	}
+	m.iters[m.heap[0]].Prev()
+	return m.fixTop()
+}
+
+func (m *mergingIter) Valid() bool {
+	return len(m.heap) > 0 && m.err == nil
+}
+
+func (m *mergingIter) Key() []byte {
+	return m.iters[m.heap[0]].Key()
+}
+
+func (m *mergingIter) Value() []byte {
+	return m.iters[m.heap[0]].Value()
+}
+
+func (m *mergingIter) Error() error {
+	return m.err
+}
+
+func (m *mergingIter) Close() error {
+	var err error
+	for _, it := range m.iters {
+		if e := it.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
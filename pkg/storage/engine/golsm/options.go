@@ -0,0 +1,109 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import "bytes"
+
+// Merger combines the operands written with Batch.Merge.
+type Merger interface {
+	// FullMerge merges the operands, oldest first, into the existing value.
+	// existing is nil if the key has no value beneath the operands.
+	FullMerge(key, existing []byte, operands [][]byte) ([]byte, error)
+	// PartialMerge combines the operands, oldest first, into a single
+	// operand. It is used during compactions when the value beneath the
+	// operands is not known.
+	PartialMerge(key []byte, operands [][]byte) ([]byte, error)
+}
+
+// Logger is used to report background events.
+type Logger interface {
+	Infof(format string, args ...interface{})
+}
+
+// Options holds the parameters for opening a DB. The zero value of each field
+// selects a reasonable default.
+type Options struct {
+	// FS is the file system the DB lives on. Defaults to DefaultFS.
+	FS FS
+	// Compare orders user keys. Defaults to bytes.Compare.
+	Compare func(a, b []byte) int
+	// Merger combines merge operands. Reading or compacting a merge operand
+	// without a Merger is an error.
+	Merger Merger
+	// Logger reports flushes and compactions. Defaults to no logging.
+	Logger Logger
+
+	// MemTableSize is the size at which the mutable memtable is rotated and
+	// queued for flushing.
+	MemTableSize int64
+	// MaxImmutableMemTables is the number of memtables waiting to be flushed
+	// at which writes are stalled.
+	MaxImmutableMemTables int
+	// L0CompactionThreshold is the number of L0 files that triggers an L0
+	// compaction.
+	L0CompactionThreshold int
+	// L0StopWritesThreshold is the number of L0 files at which writes are
+	// stalled until compactions catch up.
+	L0StopWritesThreshold int
+	// LBaseMaxBytes is the target size of L1. Each subsequent level is
+	// LevelMultiplier times larger.
+	LBaseMaxBytes int64
+	// LevelMultiplier is the size ratio between adjacent levels.
+	LevelMultiplier int
+	// TargetFileSize is the size at which compaction outputs are split.
+	TargetFileSize int64
+	// BlockSize is the target uncompressed size of table data blocks.
+	BlockSize int
+	// CacheSize is the capacity of the block cache in bytes.
+	CacheSize int64
+	// DisableAutomaticCompactions disables background compactions. Flushes
+	// still happen. Used in tests.
+	DisableAutomaticCompactions bool
+}
+
+// EnsureDefaults fills in the default values of unset options and returns
+// the receiver.
+func (o *Options) EnsureDefaults() *Options {
+	if o.FS == nil {
+		o.FS = DefaultFS
+	}
+	if o.Compare == nil {
+		o.Compare = bytes.Compare
+	}
+	if o.MemTableSize <= 0 {
+		o.MemTableSize = 4 << 20
+	}
+	if o.MaxImmutableMemTables <= 0 {
+		o.MaxImmutableMemTables = 2
+	}
+	if o.L0CompactionThreshold <= 0 {
+		o.L0CompactionThreshold = 4
+	}
+	if o.L0StopWritesThreshold <= 0 {
+		o.L0StopWritesThreshold = 24
+	}
+	if o.LBaseMaxBytes <= 0 {
+		o.LBaseMaxBytes = 64 << 20
+	}
+	if o.LevelMultiplier <= 0 {
+		o.LevelMultiplier = 10
+	}
+	if o.TargetFileSize <= 0 {
+		o.TargetFileSize = 4 << 20
+	}
+	if o.BlockSize <= 0 {
+		o.BlockSize = 32 << 10
+	}
+	if o.CacheSize <= 0 {
+		o.CacheSize = 8 << 20
+	}
+	return o
+}
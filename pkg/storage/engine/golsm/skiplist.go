@@ -0,0 +1,185 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"math/rand"
+	"sync/atomic"
+	"unsafe"
+)
+
+const (
+	skiplistMaxHeight = 12
+	// skiplistPValue is the inverse of the probability of a node being
+	// promoted to the next level.
+	skiplistPValue = 4
+)
+
+type skipnode struct {
+	key   []byte
+	value []byte
+	// next holds the *skipnode successors of the node, one per level.
+	next []unsafe.Pointer
+}
+
+func (n *skipnode) loadNext(level int) *skipnode {
+	return (*skipnode)(atomic.LoadPointer(&n.next[level]))
+}
+
+// skiplist is a sorted set of internal keys supporting a single writer and
+// any number of concurrent lock-free readers. Keys are never removed. The
+// caller is responsible for serializing calls to add.
+type skiplist struct {
+	cmp  internalCompare
+	head *skipnode
+	// height is accessed atomically.
+	height int32
+	rnd    *rand.Rand
+}
+
+func newSkiplist(cmp internalCompare) *skiplist {
+	return &skiplist{
+		cmp:    cmp,
+		head:   &skipnode{next: make([]unsafe.Pointer, skiplistMaxHeight)},
+		height: 1,
+		rnd:    rand.New(rand.NewSource(0xdecafbad)),
+	}
+}
+
+func (s *skiplist) randomHeight() int {
+	h := 1
+	for h < skiplistMaxHeight && s.rnd.Intn(skiplistPValue) == 0 {
+		h++
+	}
+	return h
+}
+
+// add inserts key into the skiplist. The key must not already be present.
+// The skiplist retains key and value.
+func (s *skiplist) add(key, value []byte) {
+	var prev [skiplistMaxHeight]*skipnode
+	listHeight := int(atomic.LoadInt32(&s.height))
+	x := s.head
+	for level := listHeight - 1; level >= 0; level-- {
+		for next := x.loadNext(level); next != nil && s.cmp(next.key, key) < 0; next = x.loadNext(level) {
+			x = next
+		}
+		prev[level] = x
+	}
+	height := s.randomHeight()
+	if height > listHeight {
+		for level := listHeight; level < height; level++ {
+			prev[level] = s.head
+		}
+		atomic.StoreInt32(&s.height, int32(height))
+	}
+	n := &skipnode{key: key, value: value, next: make([]unsafe.Pointer, height)}
+	// Link the node into every level before publishing it so that a reader
+	// that finds the node at any level can follow its successors.
+	for level := 0; level < height; level++ {
+		n.next[level] = atomic.LoadPointer(&prev[level].next[level])
+	}
+	for level := 0; level < height; level++ {
+		atomic.StorePointer(&prev[level].next[level], unsafe.Pointer(n))
+	}
+}
+
+// findGE returns the first node whose key is >= key, or nil.
+func (s *skiplist) findGE(key []byte) *skipnode {
+	x := s.head
+	for level := int(atomic.LoadInt32(&s.height)) - 1; level >= 0; level-- {
+		for next := x.loadNext(level); next != nil && s.cmp(next.key, key) < 0; next = x.loadNext(level) {
+			x = next
+		}
+	}
+	return x.loadNext(0)
+}
+
+// findLT returns the last node whose key is < key, or nil.
+func (s *skiplist) findLT(key []byte) *skipnode {
+	x := s.head
+	for level := int(atomic.LoadInt32(&s.height)) - 1; level >= 0; level-- {
+		for next := x.loadNext(level); next != nil && s.cmp(next.key, key) < 0; next = x.loadNext(level) {
+			x = next
+		}
+	}
+	if x == s.head {
+		return nil
+	}
+	return x
+}
+
+// findLast returns the last node, or nil if the skiplist is empty.
+func (s *skiplist) findLast() *skipnode {
+	x := s.head
+	for level := int(atomic.LoadInt32(&s.height)) - 1; level >= 0; level-- {
+		for next := x.loadNext(level); next != nil; next = x.loadNext(level) {
+			x = next
+		}
+	}
+	if x == s.head {
+		return nil
+	}
+	return x
+}
+
+func (s *skiplist) empty() bool {
+	return s.head.loadNext(0) == nil
+}
+
+func (s *skiplist) newIter() *skiplistIter {
+	return &skiplistIter{list: s}
+}
+
+// skiplistIter is an internalIterator over a skiplist. It observes keys
+// added after its creation.
+type skiplistIter struct {
+	list *skiplist
+	node *skipnode
+}
+
+var _ internalIterator = &skiplistIter{}
+
+func (it *skiplistIter) SeekGE(key []byte) bool {
+	it.node = it.list.findGE(key)
+	return it.node != nil
+}
+
+func (it *skiplistIter) SeekLT(key []byte) bool {
+	it.node = it.list.findLT(key)
+	return it.node != nil
+}
+
+func (it *skiplistIter) First() bool {
+	it.node = it.list.head.loadNext(0)
+	return it.node != nil
+}
+
+func (it *skiplistIter) Last() bool {
+	it.node = it.list.findLast()
+	return it.node != nil
+}
+
+func (it *skiplistIter) Next() bool {
+	it.node = it.node.loadNext(0)
+	return it.node != nil
+}
+
+func (it *skiplistIter) Prev() bool {
+	it.node = it.list.findLT(it.node.key)
+	return it.node != nil
+}
+
+func (it *skiplistIter) Valid() bool   { return it.node != nil }
+func (it *skiplistIter) Key() []byte   { return it.node.key }
+func (it *skiplistIter) Value() []byte { return it.node.value }
+func (it *skiplistIter) Error() error  { return nil }
+func (it *skiplistIter) Close() error  { return nil }
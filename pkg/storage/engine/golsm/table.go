@@ -0,0 +1,518 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"bufio"
+	"encoding/binary"
+	"hash/crc32"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// A table is an immutable sorted file of internal keys. Its layout is
+//
+//   data block 0 .. data block n-1 | index block | properties | footer
+//
+// Data and index blocks hold uvarint-prefixed key/value pairs followed by
+// the fixed32 offsets of the entries and the fixed32 entry count. Every
+// block and the properties are followed by their fixed32 CRC-32C checksum.
+// The index block maps the last key of each data block to the block's
+// location. The fixed-size footer locates the index and the properties.
+
+const (
+	tableFooterLen = 40
+	tableMagic     = uint64(0x676f6c736d746231) // "golsmtb1"
+	blockTrailer   = 4
+)
+
+type blockHandle struct {
+	offset, length uint64
+}
+
+func (h blockHandle) encode(dst []byte) []byte {
+	var buf [2 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], h.offset)
+	n += binary.PutUvarint(buf[n:], h.length)
+	return append(dst, buf[:n]...)
+}
+
+func decodeBlockHandle(b []byte) (blockHandle, error) {
+	off, n := binary.Uvarint(b)
+	if n <= 0 {
+		return blockHandle{}, errors.New("corrupt block handle")
+	}
+	length, m := binary.Uvarint(b[n:])
+	if m <= 0 {
+		return blockHandle{}, errors.New("corrupt block handle")
+	}
+	return blockHandle{offset: off, length: length}, nil
+}
+
+// tableProps describes the contents of a table.
+type tableProps struct {
+	// smallest and largest are the smallest and largest internal keys in
+	// the table.
+	smallest, largest       []byte
+	numEntries              uint64
+	smallestSeq, largestSeq uint64
+}
+
+func (p *tableProps) encode() []byte {
+	var buf []byte
+	buf = appendUvarintBytes(buf, p.smallest)
+	buf = appendUvarintBytes(buf, p.largest)
+	var tmp [binary.MaxVarintLen64]byte
+	for _, v := range []uint64{p.numEntries, p.smallestSeq, p.largestSeq} {
+		n := binary.PutUvarint(tmp[:], v)
+		buf = append(buf, tmp[:n]...)
+	}
+	return buf
+}
+
+func decodeTableProps(b []byte) (tableProps, error) {
+	var p tableProps
+	r := batchReader{data: b}
+	var ok bool
+	if p.smallest, ok = r.readBytes(); !ok {
+		return p, errors.New("corrupt table properties")
+	}
+	if p.largest, ok = r.readBytes(); !ok {
+		return p, errors.New("corrupt table properties")
+	}
+	for _, v := range []*uint64{&p.numEntries, &p.smallestSeq, &p.largestSeq} {
+		x, n := binary.Uvarint(r.data)
+		if n <= 0 {
+			return p, errors.New("corrupt table properties")
+		}
+		*v = x
+		r.data = r.data[n:]
+	}
+	return p, nil
+}
+
+// blockWriter accumulates the entries of a block.
+type blockWriter struct {
+	buf     []byte
+	offsets []uint32
+}
+
+func (w *blockWriter) add(key, value []byte) {
+	w.offsets = append(w.offsets, uint32(len(w.buf)))
+	w.buf = appendUvarintBytes(w.buf, key)
+	w.buf = appendUvarintBytes(w.buf, value)
+}
+
+func (w *blockWriter) estimatedSize() int {
+	return len(w.buf) + 4*len(w.offsets) + 4
+}
+
+func (w *blockWriter) empty() bool {
+	return len(w.offsets) == 0
+}
+
+// finish returns the encoded block and resets the writer.
+func (w *blockWriter) finish() []byte {
+	var tmp [4]byte
+	for _, off := range w.offsets {
+		binary.LittleEndian.PutUint32(tmp[:], off)
+		w.buf = append(w.buf, tmp[:]...)
+	}
+	binary.LittleEndian.PutUint32(tmp[:], uint32(len(w.offsets)))
+	b := append(w.buf, tmp[:]...)
+	w.buf = nil
+	w.offsets = w.offsets[:0]
+	return b
+}
+
+// block is a decoded data or index block.
+type block struct {
+	data    []byte
+	offsets []byte
+	n       int
+}
+
+func decodeBlock(b []byte) (*block, error) {
+	if len(b) < 4 {
+		return nil, errors.New("corrupt block: too short")
+	}
+	n := int(binary.LittleEndian.Uint32(b[len(b)-4:]))
+	if len(b) < 4+4*n {
+		return nil, errors.New("corrupt block: bad entry count")
+	}
+	end := len(b) - 4 - 4*n
+	return &block{data: b[:end], offsets: b[end : len(b)-4], n: n}, nil
+}
+
+func (b *block) entry(i int) (key, value []byte) {
+	off := binary.LittleEndian.Uint32(b.offsets[4*i:])
+	r := batchReader{data: b.data[off:]}
+	key, _ = r.readBytes()
+	value, _ = r.readBytes()
+	return key, value
+}
+
+func (b *block) key(i int) []byte {
+	k, _ := b.entry(i)
+	return k
+}
+
+func (b *block) size() int64 {
+	return int64(len(b.data) + len(b.offsets))
+}
+
+// tableWriter writes a table. Keys must be added in increasing internal key
+// order.
+type tableWriter struct {
+	f         File
+	w         *bufio.Writer
+	offset    uint64
+	blockSize int
+	block     blockWriter
+	index     blockWriter
+	props     tableProps
+	lastKey   []byte
+	err       error
+}
+
+func newTableWriter(f File, blockSize int) *tableWriter {
+	return &tableWriter{f: f, w: bufio.NewWriterSize(f, 64<<10), blockSize: blockSize}
+}
+
+func (w *tableWriter) add(ikey, value []byte) error {
+	if w.err != nil {
+		return w.err
+	}
+	seq, _ := decodeTrailer(trailer(ikey))
+	if w.props.numEntries == 0 {
+		w.props.smallest = append([]byte(nil), ikey...)
+		w.props.smallestSeq, w.props.largestSeq = seq, seq
+	} else {
+		if seq < w.props.smallestSeq {
+			w.props.smallestSeq = seq
+		}
+		if seq > w.props.largestSeq {
+			w.props.largestSeq = seq
+		}
+	}
+	w.props.numEntries++
+	w.lastKey = append(w.lastKey[:0], ikey...)
+	w.block.add(ikey, value)
+	if w.block.estimatedSize() >= w.blockSize {
+		w.flushBlock()
+	}
+	return w.err
+}
+
+func (w *tableWriter) flushBlock() {
+	if w.block.empty() {
+		return
+	}
+	h := w.writeBlock(w.block.finish())
+	w.index.add(w.lastKey, h.encode(nil))
+}
+
+func (w *tableWriter) writeBlock(b []byte) blockHandle {
+	h := blockHandle{offset: w.offset, length: uint64(len(b))}
+	if w.err != nil {
+		return h
+	}
+	var tmp [blockTrailer]byte
+	binary.LittleEndian.PutUint32(tmp[:], crc32.Checksum(b, crcTable))
+	if _, err := w.w.Write(b); err != nil {
+		w.err = err
+	} else if _, err := w.w.Write(tmp[:]); err != nil {
+		w.err = err
+	}
+	w.offset += uint64(len(b) + blockTrailer)
+	return h
+}
+
+// estimatedSize returns the approximate size of the table if it were
+// finished now.
+func (w *tableWriter) estimatedSize() uint64 {
+	return w.offset + uint64(w.block.estimatedSize()+w.index.estimatedSize())
+}
+
+// finish writes the index, properties and footer, then syncs and closes the
+// file. It returns the properties and the size of the table.
+func (w *tableWriter) finish() (tableProps, uint64, error) {
+	w.flushBlock()
+	w.props.largest = append([]byte(nil), w.lastKey...)
+	indexHandle := w.writeBlock(w.index.finish())
+	propsHandle := w.writeBlock(w.props.encode())
+	var footer [tableFooterLen]byte
+	binary.LittleEndian.PutUint64(footer[0:], indexHandle.offset)
+	binary.LittleEndian.PutUint64(footer[8:], indexHandle.length)
+	binary.LittleEndian.PutUint64(footer[16:], propsHandle.offset)
+	binary.LittleEndian.PutUint64(footer[24:], propsHandle.length)
+	binary.LittleEndian.PutUint64(footer[32:], tableMagic)
+	if w.err == nil {
+		if _, err := w.w.Write(footer[:]); err != nil {
+			w.err = err
+		}
+	}
+	w.offset += tableFooterLen
+	if w.err == nil {
+		w.err = w.w.Flush()
+	}
+	if w.err == nil {
+		w.err = w.f.Sync()
+	}
+	if err := w.f.Close(); err != nil && w.err == nil {
+		w.err = err
+	}
+	return w.props, w.offset, w.err
+}
+
+// abort closes the file without finishing the table.
+func (w *tableWriter) abort() {
+	_ = w.f.Close()
+}
+
+// tableReader reads a table.
+type tableReader struct {
+	f       File
+	ucmp    func(a, b []byte) int
+	icmp    internalCompare
+	cache   *blockCache
+	cacheID uint64
+	index   *block
+	props   tableProps
+	// globalSeq, if non-zero, replaces the sequence numbers of all the
+	// entries in the table. It is assigned to tables when they are ingested.
+	globalSeq uint64
+}
+
+func openTable(
+	f File, size uint64, ucmp func(a, b []byte) int, cache *blockCache, globalSeq uint64,
+) (*tableReader, error) {
+	if size < tableFooterLen {
+		return nil, errors.Errorf("table too small: %d bytes", size)
+	}
+	var footer [tableFooterLen]byte
+	if _, err := f.ReadAt(footer[:], int64(size-tableFooterLen)); err != nil {
+		return nil, errors.Wrap(err, "reading table footer")
+	}
+	if binary.LittleEndian.Uint64(footer[32:]) != tableMagic {
+		return nil, errors.New("not a golsm table: bad magic number")
+	}
+	t := &tableReader{
+		f:         f,
+		ucmp:      ucmp,
+		icmp:      makeInternalCompare(ucmp),
+		cache:     cache,
+		globalSeq: globalSeq,
+	}
+	if cache != nil {
+		t.cacheID = cache.newID()
+	}
+	indexData, err := t.readRaw(blockHandle{
+		offset: binary.LittleEndian.Uint64(footer[0:]),
+		length: binary.LittleEndian.Uint64(footer[8:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t.index, err = decodeBlock(indexData); err != nil {
+		return nil, err
+	}
+	propsData, err := t.readRaw(blockHandle{
+		offset: binary.LittleEndian.Uint64(footer[16:]),
+		length: binary.LittleEndian.Uint64(footer[24:]),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if t.props, err = decodeTableProps(propsData); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (t *tableReader) readRaw(h blockHandle) ([]byte, error) {
+	buf := make([]byte, h.length+blockTrailer)
+	if _, err := t.f.ReadAt(buf, int64(h.offset)); err != nil {
+		return nil, errors.Wrap(err, "reading table block")
+	}
+	b := buf[:h.length]
+	if crc32.Checksum(b, crcTable) != binary.LittleEndian.Uint32(buf[h.length:]) {
+		return nil, errors.Errorf("corrupt table block at offset %d: checksum mismatch", h.offset)
+	}
+	return b, nil
+}
+
+func (t *tableReader) readBlock(h blockHandle) (*block, error) {
+	if t.cache != nil {
+		if b := t.cache.get(t.cacheID, h.offset); b != nil {
+			return b, nil
+		}
+	}
+	data, err := t.readRaw(h)
+	if err != nil {
+		return nil, err
+	}
+	b, err := decodeBlock(data)
+	if err != nil {
+		return nil, err
+	}
+	if t.cache != nil {
+		t.cache.add(t.cacheID, h.offset, b)
+	}
+	return b, nil
+}
+
+// compare compares a key stored in the table with key, taking the global
+// sequence number into account.
+func (t *tableReader) compare(stored, key []byte) int {
+	if t.globalSeq == 0 {
+		return t.icmp(stored, key)
+	}
+	if c := t.ucmp(userKey(stored), userKey(key)); c != 0 {
+		return c
+	}
+	ts, tk := t.globalSeq<<8|trailer(stored)&0xff, trailer(key)
+	if ts > tk {
+		return -1
+	} else if ts < tk {
+		return 1
+	}
+	return 0
+}
+
+func (t *tableReader) close() error {
+	if t.cache != nil {
+		t.cache.evictID(t.cacheID)
+	}
+	return t.f.Close()
+}
+
+func (t *tableReader) newIter() *tableIter {
+	return &tableIter{t: t}
+}
+
+// tableIter is an internalIterator over a table.
+type tableIter struct {
+	t        *tableReader
+	blockIdx int
+	blk      *block
+	pos      int
+	valid    bool
+	keyBuf   []byte
+	err      error
+}
+
+var _ internalIterator = &tableIter{}
+
+func (it *tableIter) loadBlock(i int) bool {
+	it.valid = false
+	if i < 0 || i >= it.t.index.n {
+		return false
+	}
+	_, v := it.t.index.entry(i)
+	h, err := decodeBlockHandle(v)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	b, err := it.t.readBlock(h)
+	if err != nil {
+		it.err = err
+		return false
+	}
+	it.blockIdx, it.blk = i, b
+	return true
+}
+
+func (it *tableIter) setPos(pos int) bool {
+	it.pos = pos
+	it.valid = pos >= 0 && pos < it.blk.n
+	return it.valid
+}
+
+func (it *tableIter) SeekGE(key []byte) bool {
+	idx := it.t.index
+	i := sort.Search(idx.n, func(i int) bool { return it.t.compare(idx.key(i), key) >= 0 })
+	if !it.loadBlock(i) {
+		return false
+	}
+	j := sort.Search(it.blk.n, func(j int) bool { return it.t.compare(it.blk.key(j), key) >= 0 })
+	if j == it.blk.n {
+		return it.loadBlock(i+1) && it.setPos(0)
+	}
+	return it.setPos(j)
+}
+
+func (it *tableIter) SeekLT(key []byte) bool {
+	idx := it.t.index
+	i := sort.Search(idx.n, func(i int) bool { return it.t.compare(idx.key(i), key) >= 0 })
+	if i == idx.n {
+		return it.Last()
+	}
+	if !it.loadBlock(i) {
+		return false
+	}
+	j := sort.Search(it.blk.n, func(j int) bool { return it.t.compare(it.blk.key(j), key) >= 0 })
+	if j > 0 {
+		return it.setPos(j - 1)
+	}
+	return it.loadBlock(i-1) && it.setPos(it.blk.n-1)
+}
+
+func (it *tableIter) First() bool {
+	return it.loadBlock(0) && it.setPos(0)
+}
+
+func (it *tableIter) Last() bool {
+	return it.loadBlock(it.t.index.n-1) && it.setPos(it.blk.n-1)
+}
+
+func (it *tableIter) Next() bool {
+	if it.setPos(it.pos + 1) {
+		return true
+	}
+	return it.loadBlock(it.blockIdx+1) && it.setPos(0)
+}
+
+func (it *tableIter) Prev() bool {
+	if it.setPos(it.pos - 1) {
+		return true
+	}
+	return it.loadBlock(it.blockIdx-1) && it.setPos(it.blk.n-1)
+}
+
+func (it *tableIter) Valid() bool {
+	return it.valid
+}
+
+func (it *tableIter) Key() []byte {
+	k := it.blk.key(it.pos)
+	if it.t.globalSeq == 0 {
+		return k
+	}
+	_, kind := decodeTrailer(trailer(k))
+	it.keyBuf = makeInternalKey(it.keyBuf[:0], userKey(k), it.t.globalSeq, kind)
+	return it.keyBuf
+}
+
+func (it *tableIter) Value() []byte {
+	_, v := it.blk.entry(it.pos)
+	return v
+}
+
+func (it *tableIter) Error() error {
+	return it.err
+}
+
+func (it *tableIter) Close() error {
+	return nil
+}
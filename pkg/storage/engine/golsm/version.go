@@ -0,0 +1,537 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+)
+
+// numLevels is the number of levels of the LSM. Tables in L0 may overlap
+// each other; the tables of every other level are disjoint.
+const numLevels = 7
+
+// fileMetadata describes a table that is part of the LSM.
+type fileMetadata struct {
+	fileNum uint64
+	size    uint64
+	// smallest and largest are the bounds of the table as internal keys. For
+	// ingested tables, their sequence numbers are the global sequence number.
+	smallest, largest       []byte
+	smallestSeq, largestSeq uint64
+	globalSeq               uint64
+	// refs is the number of versions referencing the table. Protected by
+	// DB.mu.
+	refs int
+}
+
+// version is an immutable snapshot of the tables making up the LSM.
+type version struct {
+	files [numLevels][]*fileMetadata
+	// refs is the number of users of the version, including DB.mu.current
+	// when the version is current. Protected by DB.mu.
+	refs int
+}
+
+// versionEdit describes the difference between two versions.
+type versionEdit struct {
+	deleted map[uint64]struct{}
+	added   [numLevels][]*fileMetadata
+	// lastSeq, if larger than the last visible sequence number, is recorded
+	// as the last sequence number in the manifest. It is used by ingestions,
+	// which allocate sequence numbers that are not written to the WAL.
+	lastSeq uint64
+	// onInstall, if set, is called under DB.mu when the new version is
+	// installed.
+	onInstall func()
+}
+
+func (e *versionEdit) deleteFile(fileNum uint64) {
+	if e.deleted == nil {
+		e.deleted = make(map[uint64]struct{})
+	}
+	e.deleted[fileNum] = struct{}{}
+}
+
+// apply returns a new version with the edit applied to v.
+func (v *version) apply(e *versionEdit, icmp internalCompare) *version {
+	nv := &version{}
+	for level := range v.files {
+		files := make([]*fileMetadata, 0, len(v.files[level])+len(e.added[level]))
+		for _, f := range v.files[level] {
+			if _, ok := e.deleted[f.fileNum]; !ok {
+				files = append(files, f)
+			}
+		}
+		files = append(files, e.added[level]...)
+		if level == 0 {
+			// L0 tables are ordered from oldest to newest.
+			sort.Slice(files, func(i, j int) bool {
+				if files[i].largestSeq != files[j].largestSeq {
+					return files[i].largestSeq < files[j].largestSeq
+				}
+				return files[i].fileNum < files[j].fileNum
+			})
+		} else {
+			sort.Slice(files, func(i, j int) bool {
+				return icmp(files[i].smallest, files[j].smallest) < 0
+			})
+		}
+		nv.files[level] = files
+	}
+	return nv
+}
+
+// overlaps returns the tables in the level whose user key range intersects
+// [start, end]. A nil bound is unbounded.
+func (v *version) overlaps(level int, ucmp func(a, b []byte) int, start, end []byte) []*fileMetadata {
+	var res []*fileMetadata
+	for _, f := range v.files[level] {
+		if start != nil && ucmp(userKey(f.largest), start) < 0 {
+			continue
+		}
+		if end != nil && ucmp(userKey(f.smallest), end) > 0 {
+			continue
+		}
+		res = append(res, f)
+	}
+	return res
+}
+
+func levelSize(files []*fileMetadata) uint64 {
+	var size uint64
+	for _, f := range files {
+		size += f.size
+	}
+	return size
+}
+
+// keyRange returns the smallest and largest user keys of the tables.
+func keyRange(ucmp func(a, b []byte) int, files ...[]*fileMetadata) (smallest, largest []byte) {
+	for _, level := range files {
+		for _, f := range level {
+			if smallest == nil || ucmp(userKey(f.smallest), smallest) < 0 {
+				smallest = userKey(f.smallest)
+			}
+			if largest == nil || ucmp(userKey(f.largest), largest) > 0 {
+				largest = userKey(f.largest)
+			}
+		}
+	}
+	return smallest, largest
+}
+
+// tableCache holds the open tables of the DB.
+type tableCache struct {
+	fs      FS
+	dirname string
+	ucmp    func(a, b []byte) int
+	cache   *blockCache
+	mu      struct {
+		syncutil.Mutex
+		tables map[uint64]*tableReader
+	}
+}
+
+func newTableCache(fs FS, dirname string, ucmp func(a, b []byte) int, cache *blockCache) *tableCache {
+	c := &tableCache{fs: fs, dirname: dirname, ucmp: ucmp, cache: cache}
+	c.mu.tables = make(map[uint64]*tableReader)
+	return c
+}
+
+func (c *tableCache) get(f *fileMetadata) (*tableReader, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.mu.tables[f.fileNum]; ok {
+		return t, nil
+	}
+	file, err := c.fs.Open(tableFilename(c.dirname, f.fileNum))
+	if err != nil {
+		return nil, err
+	}
+	t, err := openTable(file, f.size, c.ucmp, c.cache, f.globalSeq)
+	if err != nil {
+		_ = file.Close()
+		return nil, errors.Wrapf(err, "opening table %d", f.fileNum)
+	}
+	c.mu.tables[f.fileNum] = t
+	return t, nil
+}
+
+func (c *tableCache) evict(fileNum uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.mu.tables[fileNum]; ok {
+		_ = t.close()
+		delete(c.mu.tables, fileNum)
+	}
+}
+
+func (c *tableCache) close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for num, t := range c.mu.tables {
+		_ = t.close()
+		delete(c.mu.tables, num)
+	}
+}
+
+// levelIter is an internalIterator over the disjoint tables of a level. It
+// opens the tables lazily.
+type levelIter struct {
+	icmp  internalCompare
+	files []*fileMetadata
+	tc    *tableCache
+	idx   int
+	iter  *tableIter
+	err   error
+}
+
+var _ internalIterator = &levelIter{}
+
+func newLevelIter(icmp internalCompare, tc *tableCache, files []*fileMetadata) *levelIter {
+	return &levelIter{icmp: icmp, tc: tc, files: files, idx: -1}
+}
+
+func (l *levelIter) open(idx int) bool {
+	l.iter = nil
+	if idx < 0 || idx >= len(l.files) {
+		return false
+	}
+	t, err := l.tc.get(l.files[idx])
+	if err != nil {
+		l.err = err
+		return false
+	}
+	l.idx, l.iter = idx, t.newIter()
+	return true
+}
+
+// checkErr invalidates the iterator if the current table hit an error.
+func (l *levelIter) checkErr() bool {
+	if err := l.iter.Error(); err != nil {
+		l.err = err
+		l.iter = nil
+		return true
+	}
+	return false
+}
+
+func (l *levelIter) forward(idx int, pos func(it *tableIter) bool) bool {
+	for ; l.open(idx); idx++ {
+		if pos(l.iter) {
+			return true
+		}
+		if l.checkErr() {
+			return false
+		}
+	}
+	return false
+}
+
+func (l *levelIter) backward(idx int, pos func(it *tableIter) bool) bool {
+	for ; l.open(idx); idx-- {
+		if pos(l.iter) {
+			return true
+		}
+		if l.checkErr() {
+			return false
+		}
+	}
+	return false
+}
+
+func (l *levelIter) SeekGE(key []byte) bool {
+	l.err = nil
+	i := sort.Search(len(l.files), func(i int) bool { return l.icmp(l.files[i].largest, key) >= 0 })
+	return l.forward(i, func(it *tableIter) bool { return it.SeekGE(key) })
+}
+
+func (l *levelIter) SeekLT(key []byte) bool {
+	l.err = nil
+	i := sort.Search(len(l.files), func(i int) bool { return l.icmp(l.files[i].smallest, key) >= 0 })
+	return l.backward(i-1, func(it *tableIter) bool { return it.SeekLT(key) })
+}
+
+func (l *levelIter) First() bool {
+	l.err = nil
+	return l.forward(0, (*tableIter).First)
+}
+
+func (l *levelIter) Last() bool {
+	l.err = nil
+	return l.backward(len(l.files)-1, (*tableIter).Last)
+}
+
+func (l *levelIter) Next() bool {
+	if l.iter.Next() {
+		return true
+	}
+	if l.checkErr() {
+		return false
+	}
+	return l.forward(l.idx+1, (*tableIter).First)
+}
+
+func (l *levelIter) Prev() bool {
+	if l.iter.Prev() {
+		return true
+	}
+	if l.checkErr() {
+		return false
+	}
+	return l.backward(l.idx-1, (*tableIter).Last)
+}
+
+func (l *levelIter) Valid() bool {
+	return l.iter != nil && l.iter.Valid()
+}
+
+func (l *levelIter) Key() []byte {
+	return l.iter.Key()
+}
+
+func (l *levelIter) Value() []byte {
+	return l.iter.Value()
+}
+
+func (l *levelIter) Error() error {
+	return l.err
+}
+
+func (l *levelIter) Close() error {
+	return nil
+}
+
+// File naming.
+
+const (
+	currentFilename = "CURRENT"
+	lockFilename    = "LOCK"
+)
+
+func tableFilename(dirname string, num uint64) string {
+	return filepath.Join(dirname, fmt.Sprintf("%06d.sst", num))
+}
+
+func logFilename(dirname string, num uint64) string {
+	return filepath.Join(dirname, fmt.Sprintf("%06d.log", num))
+}
+
+func manifestFilename(dirname string, num uint64) string {
+	return filepath.Join(dirname, fmt.Sprintf("MANIFEST-%06d", num))
+}
+
+type fileType int
+
+const (
+	fileTypeUnknown fileType = iota
+	fileTypeTable
+	fileTypeLog
+	fileTypeManifest
+	fileTypeTemp
+)
+
+// parseFilename returns the type and number of a DB file.
+func parseFilename(name string) (fileType, uint64) {
+	switch {
+	case strings.HasPrefix(name, "MANIFEST-"):
+		if n, err := strconv.ParseUint(name[len("MANIFEST-"):], 10, 64); err == nil {
+			return fileTypeManifest, n
+		}
+	case strings.HasSuffix(name, ".sst"):
+		if n, err := strconv.ParseUint(strings.TrimSuffix(name, ".sst"), 10, 64); err == nil {
+			return fileTypeTable, n
+		}
+	case strings.HasSuffix(name, ".log"):
+		if n, err := strconv.ParseUint(strings.TrimSuffix(name, ".log"), 10, 64); err == nil {
+			return fileTypeLog, n
+		}
+	case strings.HasSuffix(name, ".tmp"):
+		return fileTypeTemp, 0
+	}
+	return fileTypeUnknown, 0
+}
+
+// manifest is the persistent state of the LSM. The DB writes a complete
+// manifest to a new file whenever the state changes and then points the
+// CURRENT file at it.
+type manifest struct {
+	nextFileNum uint64
+	// logNum is the number of the oldest WAL that holds writes not yet
+	// flushed to tables.
+	logNum  uint64
+	lastSeq uint64
+	files   [numLevels][]*fileMetadata
+}
+
+var manifestMagic = []byte("golsmmf1")
+
+func (m *manifest) encode() []byte {
+	buf := append([]byte(nil), manifestMagic...)
+	var tmp [binary.MaxVarintLen64]byte
+	putUvarint := func(v uint64) {
+		n := binary.PutUvarint(tmp[:], v)
+		buf = append(buf, tmp[:n]...)
+	}
+	putUvarint(m.nextFileNum)
+	putUvarint(m.logNum)
+	putUvarint(m.lastSeq)
+	var count uint64
+	for _, files := range m.files {
+		count += uint64(len(files))
+	}
+	putUvarint(count)
+	for level, files := range m.files {
+		for _, f := range files {
+			putUvarint(uint64(level))
+			putUvarint(f.fileNum)
+			putUvarint(f.size)
+			putUvarint(f.smallestSeq)
+			putUvarint(f.largestSeq)
+			putUvarint(f.globalSeq)
+			buf = appendUvarintBytes(buf, f.smallest)
+			buf = appendUvarintBytes(buf, f.largest)
+		}
+	}
+	var crc [4]byte
+	binary.LittleEndian.PutUint32(crc[:], crc32.Checksum(buf, crcTable))
+	return append(buf, crc[:]...)
+}
+
+func decodeManifest(data []byte) (*manifest, error) {
+	corrupt := errors.New("corrupt manifest")
+	if len(data) < len(manifestMagic)+4 || string(data[:len(manifestMagic)]) != string(manifestMagic) {
+		return nil, corrupt
+	}
+	body := data[:len(data)-4]
+	if crc32.Checksum(body, crcTable) != binary.LittleEndian.Uint32(data[len(data)-4:]) {
+		return nil, errors.New("corrupt manifest: checksum mismatch")
+	}
+	r := batchReader{data: body[len(manifestMagic):]}
+	getUvarint := func() uint64 {
+		v, n := binary.Uvarint(r.data)
+		if n <= 0 {
+			r.err = corrupt
+			return 0
+		}
+		r.data = r.data[n:]
+		return v
+	}
+	m := &manifest{}
+	m.nextFileNum = getUvarint()
+	m.logNum = getUvarint()
+	m.lastSeq = getUvarint()
+	count := getUvarint()
+	for i := uint64(0); i < count && r.err == nil; i++ {
+		level := getUvarint()
+		f := &fileMetadata{
+			fileNum:     getUvarint(),
+			size:        getUvarint(),
+			smallestSeq: getUvarint(),
+			largestSeq:  getUvarint(),
+			globalSeq:   getUvarint(),
+		}
+		var ok bool
+		if f.smallest, ok = r.readBytes(); !ok {
+			return nil, corrupt
+		}
+		if f.largest, ok = r.readBytes(); !ok {
+			return nil, corrupt
+		}
+		if level >= numLevels {
+			return nil, corrupt
+		}
+		m.files[level] = append(m.files[level], f)
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+	return m, nil
+}
+
+// writeManifest writes m to a new manifest file and makes it current.
+func writeManifest(fs FS, dirname string, num uint64, m *manifest) error {
+	name := manifestFilename(dirname, num)
+	if err := writeFileSync(fs, name, m.encode()); err != nil {
+		return err
+	}
+	tmp := filepath.Join(dirname, currentFilename+".tmp")
+	if err := writeFileSync(fs, tmp, []byte(filepath.Base(name)+"\n")); err != nil {
+		return err
+	}
+	if err := fs.Rename(tmp, filepath.Join(dirname, currentFilename)); err != nil {
+		return err
+	}
+	return fs.SyncDir(dirname)
+}
+
+// readManifest reads the current manifest. It returns nil if the directory
+// holds no DB.
+func readManifest(fs FS, dirname string) (*manifest, uint64, error) {
+	current, err := readFile(fs, filepath.Join(dirname, currentFilename))
+	if err != nil {
+		if isNotExist(err) {
+			return nil, 0, nil
+		}
+		return nil, 0, err
+	}
+	name := strings.TrimSpace(string(current))
+	typ, num := parseFilename(name)
+	if typ != fileTypeManifest {
+		return nil, 0, errors.Errorf("CURRENT names an invalid manifest %q", name)
+	}
+	data, err := readFile(fs, filepath.Join(dirname, name))
+	if err != nil {
+		return nil, 0, err
+	}
+	m, err := decodeManifest(data)
+	return m, num, err
+}
+
+func writeFileSync(fs FS, name string, data []byte) error {
+	f, err := fs.Create(name)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func readFile(fs FS, name string) ([]byte, error) {
+	f, err := fs.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ioutil.ReadAll(f)
+}
+
+func isNotExist(err error) bool {
+	return os.IsNotExist(errors.Cause(err))
+}
@@ -0,0 +1,461 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/util/syncutil"
+	"github.com/pkg/errors"
+)
+
+// File is a readable, writable sequence of bytes. Files are written
+// sequentially; random access is only needed for reads.
+type File interface {
+	io.Reader
+	io.ReaderAt
+	io.Writer
+	io.Closer
+	Stat() (os.FileInfo, error)
+	Sync() error
+}
+
+// FS is the file system interface used by the DB. It allows the DB to run on
+// top of the operating system's file system or entirely in memory.
+type FS interface {
+	// Create creates the named file for writing, truncating it if it exists.
+	Create(name string) (File, error)
+	// Open opens the named file for reading.
+	Open(name string) (File, error)
+	// OpenForAppend opens the named file for appending, creating it if it
+	// doesn't exist.
+	OpenForAppend(name string) (File, error)
+	// Remove removes the named file or empty directory.
+	Remove(name string) error
+	// RemoveAll removes the named file or directory and all of its contents.
+	RemoveAll(name string) error
+	// Rename renames a file, replacing the destination if it exists.
+	Rename(oldname, newname string) error
+	// Link creates newname as a hard link to oldname.
+	Link(oldname, newname string) error
+	// MkdirAll creates a directory and all necessary parents.
+	MkdirAll(dir string, perm os.FileMode) error
+	// List returns the names of the entries of the directory.
+	List(dir string) ([]string, error)
+	// Stat returns information about the named file.
+	Stat(name string) (os.FileInfo, error)
+	// SyncDir makes the directory entries of dir durable.
+	SyncDir(dir string) error
+	// Lock acquires an exclusive lock on the named file, creating it if
+	// necessary. The lock is released by closing the returned io.Closer.
+	Lock(name string) (io.Closer, error)
+}
+
+// DefaultFS is the FS backed by the operating system's file system.
+var DefaultFS FS = defaultFS{}
+
+type defaultFS struct{}
+
+func (defaultFS) Create(name string) (File, error) {
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+func (defaultFS) Open(name string) (File, error) {
+	return os.Open(name)
+}
+
+func (defaultFS) OpenForAppend(name string) (File, error) {
+	return os.OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+}
+
+func (defaultFS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+func (defaultFS) RemoveAll(name string) error {
+	return os.RemoveAll(name)
+}
+
+func (defaultFS) Rename(oldname, newname string) error {
+	return os.Rename(oldname, newname)
+}
+
+func (defaultFS) Link(oldname, newname string) error {
+	return os.Link(oldname, newname)
+}
+
+func (defaultFS) MkdirAll(dir string, perm os.FileMode) error {
+	return os.MkdirAll(dir, perm)
+}
+
+func (defaultFS) List(dir string) ([]string, error) {
+	f, err := os.Open(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return f.Readdirnames(-1)
+}
+
+func (defaultFS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+func (defaultFS) SyncDir(dir string) error {
+	f, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	syncErr := f.Sync()
+	closeErr := f.Close()
+	if syncErr != nil {
+		return syncErr
+	}
+	return closeErr
+}
+
+func (defaultFS) Lock(name string) (io.Closer, error) {
+	return lockFile(name)
+}
+
+// NewMemFS returns a new, empty, in-memory FS.
+func NewMemFS() FS {
+	return &memFS{files: make(map[string]*memFileData)}
+}
+
+// memFS is an in-memory FS. Directories are implicit: a directory exists if
+// it was created with MkdirAll or if any file lives in it.
+type memFS struct {
+	mu    syncutil.Mutex
+	files map[string]*memFileData
+	dirs  map[string]struct{}
+}
+
+type memFileData struct {
+	mu      syncutil.RWMutex
+	data    []byte
+	modTime time.Time
+	locked  bool
+}
+
+func (fs *memFS) clean(name string) string {
+	return filepath.Clean(name)
+}
+
+func (fs *memFS) Create(name string) (File, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	d := &memFileData{}
+	fs.files[name] = d
+	return &memFile{name: name, data: d, writable: true}, nil
+}
+
+func (fs *memFS) Open(name string) (File, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	d, ok := fs.files[name]
+	if !ok {
+		if _, isDir := fs.dirs[name]; isDir {
+			return &memFile{name: name, data: &memFileData{}, isDir: true}, nil
+		}
+		return nil, &os.PathError{Op: "open", Path: name, Err: os.ErrNotExist}
+	}
+	return &memFile{name: name, data: d}, nil
+}
+
+func (fs *memFS) OpenForAppend(name string) (File, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	d, ok := fs.files[name]
+	if !ok {
+		d = &memFileData{}
+		fs.files[name] = d
+	}
+	return &memFile{name: name, data: d, writable: true}, nil
+}
+
+func (fs *memFS) Remove(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	if _, ok := fs.files[name]; ok {
+		delete(fs.files, name)
+		return nil
+	}
+	if _, ok := fs.dirs[name]; ok {
+		prefix := name + string(filepath.Separator)
+		for f := range fs.files {
+			if strings.HasPrefix(f, prefix) {
+				return &os.PathError{Op: "remove", Path: name, Err: errors.New("directory not empty")}
+			}
+		}
+		delete(fs.dirs, name)
+		return nil
+	}
+	return &os.PathError{Op: "remove", Path: name, Err: os.ErrNotExist}
+}
+
+func (fs *memFS) RemoveAll(name string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	prefix := name + string(filepath.Separator)
+	for f := range fs.files {
+		if f == name || strings.HasPrefix(f, prefix) {
+			delete(fs.files, f)
+		}
+	}
+	for d := range fs.dirs {
+		if d == name || strings.HasPrefix(d, prefix) {
+			delete(fs.dirs, d)
+		}
+	}
+	return nil
+}
+
+func (fs *memFS) Rename(oldname, newname string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	oldname, newname = fs.clean(oldname), fs.clean(newname)
+	d, ok := fs.files[oldname]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: os.ErrNotExist}
+	}
+	delete(fs.files, oldname)
+	fs.files[newname] = d
+	return nil
+}
+
+func (fs *memFS) Link(oldname, newname string) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	oldname, newname = fs.clean(oldname), fs.clean(newname)
+	d, ok := fs.files[oldname]
+	if !ok {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: os.ErrNotExist}
+	}
+	if _, ok := fs.files[newname]; ok {
+		return &os.LinkError{Op: "link", Old: oldname, New: newname, Err: os.ErrExist}
+	}
+	fs.files[newname] = d
+	return nil
+}
+
+func (fs *memFS) MkdirAll(dir string, perm os.FileMode) error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	if fs.dirs == nil {
+		fs.dirs = make(map[string]struct{})
+	}
+	for dir = fs.clean(dir); ; dir = filepath.Dir(dir) {
+		fs.dirs[dir] = struct{}{}
+		if parent := filepath.Dir(dir); parent == dir {
+			break
+		}
+	}
+	return nil
+}
+
+func (fs *memFS) List(dir string) ([]string, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	dir = fs.clean(dir)
+	prefix := dir + string(filepath.Separator)
+	seen := make(map[string]struct{})
+	add := func(p string) {
+		if !strings.HasPrefix(p, prefix) {
+			return
+		}
+		rest := p[len(prefix):]
+		if i := strings.IndexByte(rest, filepath.Separator); i >= 0 {
+			rest = rest[:i]
+		}
+		if rest != "" {
+			seen[rest] = struct{}{}
+		}
+	}
+	for f := range fs.files {
+		add(f)
+	}
+	for d := range fs.dirs {
+		add(d)
+	}
+	names := make([]string, 0, len(seen))
+	for n := range seen {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (fs *memFS) Stat(name string) (os.FileInfo, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	if d, ok := fs.files[name]; ok {
+		d.mu.RLock()
+		defer d.mu.RUnlock()
+		return memFileInfo{name: filepath.Base(name), size: int64(len(d.data)), modTime: d.modTime}, nil
+	}
+	if _, ok := fs.dirs[name]; ok {
+		return memFileInfo{name: filepath.Base(name), isDir: true}, nil
+	}
+	return nil, &os.PathError{Op: "stat", Path: name, Err: os.ErrNotExist}
+}
+
+func (fs *memFS) SyncDir(string) error {
+	return nil
+}
+
+func (fs *memFS) Lock(name string) (io.Closer, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+	name = fs.clean(name)
+	d, ok := fs.files[name]
+	if !ok {
+		d = &memFileData{}
+		fs.files[name] = d
+	}
+	if d.locked {
+		return nil, errors.Errorf("lock %s is already held", name)
+	}
+	d.locked = true
+	return &memLock{fs: fs, data: d}, nil
+}
+
+type memLock struct {
+	fs   *memFS
+	data *memFileData
+}
+
+func (l *memLock) Close() error {
+	l.fs.mu.Lock()
+	defer l.fs.mu.Unlock()
+	l.data.locked = false
+	return nil
+}
+
+type memFile struct {
+	name     string
+	data     *memFileData
+	pos      int64
+	writable bool
+	isDir    bool
+}
+
+func (f *memFile) Read(p []byte) (int, error) {
+	n, err := f.ReadAt(p, f.pos)
+	f.pos += int64(n)
+	if err == io.EOF && n > 0 {
+		err = nil
+	}
+	return n, err
+}
+
+func (f *memFile) ReadAt(p []byte, off int64) (int, error) {
+	f.data.mu.RLock()
+	defer f.data.mu.RUnlock()
+	if off >= int64(len(f.data.data)) {
+		return 0, io.EOF
+	}
+	n := copy(p, f.data.data[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func (f *memFile) Write(p []byte) (int, error) {
+	if !f.writable {
+		return 0, errors.Errorf("%s: file not opened for writing", f.name)
+	}
+	f.data.mu.Lock()
+	defer f.data.mu.Unlock()
+	f.data.data = append(f.data.data, p...)
+	return len(p), nil
+}
+
+func (f *memFile) Close() error {
+	return nil
+}
+
+func (f *memFile) Stat() (os.FileInfo, error) {
+	f.data.mu.RLock()
+	defer f.data.mu.RUnlock()
+	return memFileInfo{
+		name: filepath.Base(f.name), size: int64(len(f.data.data)),
+		modTime: f.data.modTime, isDir: f.isDir,
+	}, nil
+}
+
+func (f *memFile) Sync() error {
+	return nil
+}
+
+type memFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+	isDir   bool
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) ModTime() time.Time { return i.modTime }
+func (i memFileInfo) IsDir() bool        { return i.isDir }
+func (i memFileInfo) Sys() interface{}   { return nil }
+
+func (i memFileInfo) Mode() os.FileMode {
+	if i.isDir {
+		return os.ModeDir | 0755
+	}
+	return 0644
+}
+
+// copyFile copies the contents of src to dst within fs. It is used as a
+// fallback when hard links are not supported.
+func copyFile(fs FS, src, dst string) error {
+	in, err := fs.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := fs.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// linkOrCopyFile hard links src to dst, falling back to copying the file if
+// the link fails (e.g. because the files live on different devices).
+func linkOrCopyFile(fs FS, src, dst string) error {
+	if err := fs.Link(src, dst); err == nil {
+		return nil
+	}
+	return copyFile(fs, src, dst)
+}
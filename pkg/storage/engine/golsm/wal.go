@@ -0,0 +1,114 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package golsm
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// walHeaderLen is the length of a WAL record header: the payload length and
+// the CRC-32C checksum of the payload.
+const walHeaderLen = 8
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// logWriter appends records to a write-ahead log. Each record holds the
+// representation of a committed batch, whose header carries the sequence
+// number of its first record.
+type logWriter struct {
+	f   File
+	num uint64
+	buf []byte
+}
+
+func newLogWriter(f File, num uint64) *logWriter {
+	return &logWriter{f: f, num: num}
+}
+
+// writeRecord appends a record holding payload, syncing the log if
+// requested.
+func (w *logWriter) writeRecord(payload []byte, sync bool) error {
+	w.buf = append(w.buf[:0], make([]byte, walHeaderLen)...)
+	binary.LittleEndian.PutUint32(w.buf[0:4], uint32(len(payload)))
+	binary.LittleEndian.PutUint32(w.buf[4:8], crc32.Checksum(payload, crcTable))
+	w.buf = append(w.buf, payload...)
+	if _, err := w.f.Write(w.buf); err != nil {
+		return err
+	}
+	if sync {
+		return w.f.Sync()
+	}
+	return nil
+}
+
+func (w *logWriter) sync() error {
+	return w.f.Sync()
+}
+
+func (w *logWriter) close() error {
+	if err := w.f.Sync(); err != nil {
+		_ = w.f.Close()
+		return err
+	}
+	return w.f.Close()
+}
+
+// replayLog calls fn with the payload of every record in the log. If tornTail
+// is set, the last record of the log can be truncated or corrupt, which is the
+// result of a crash in the middle of a write that was never acknowledged, and
+// is ignored. Any other truncated or corrupt record means that the log was
+// damaged after it was written, and is an error.
+func replayLog(fs FS, path string, tornTail bool, fn func(payload []byte) error) error {
+	f, err := fs.Open(path)
+	if err != nil {
+		return err
+	}
+	data, err := ioutil.ReadAll(f)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+	for offset := 0; offset < len(data); {
+		rest := data[offset:]
+		if len(rest) < walHeaderLen {
+			if tornTail {
+				return nil
+			}
+			return errors.Errorf("truncated record header at offset %d", offset)
+		}
+		n := binary.LittleEndian.Uint32(rest[0:4])
+		crc := binary.LittleEndian.Uint32(rest[4:8])
+		if uint64(len(rest)-walHeaderLen) < uint64(n) {
+			if tornTail {
+				return nil
+			}
+			return errors.Errorf("truncated record at offset %d", offset)
+		}
+		end := walHeaderLen + int(n)
+		payload := rest[walHeaderLen:end]
+		if crc32.Checksum(payload, crcTable) != crc {
+			// Only a record that ends the log can have been torn.
+			if tornTail && end == len(rest) {
+				return nil
+			}
+			return errors.Errorf("corrupt record at offset %d", offset)
+		}
+		if err := fn(payload); err != nil {
+			return err
+		}
+		offset += end
+	}
+	return nil
+}
@@ -0,0 +1,481 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/golsm"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/pkg/errors"
+)
+
+// goLSMBatch implements Batch on top of a golsm.Batch. The mutations are
+// recorded twice: in the golsm batch, which serves reads and is applied on
+// commit, and in a RocksDBBatchBuilder, which provides the representation
+// returned by Repr. The two engines thus share a single batch format, which
+// is required as batch representations are replicated through Raft.
+type goLSMBatch struct {
+	parent    *GoLSM
+	batch     *golsm.Batch
+	builder   RocksDBBatchBuilder
+	distinct  goLSMDistinctBatch
+	writeOnly bool
+	// distinctOpen is true while the batch returned by Distinct is in use.
+	distinctOpen bool
+	closed       bool
+	committed    bool
+}
+
+var _ Batch = &goLSMBatch{}
+
+func newGoLSMBatch(parent *GoLSM, writeOnly bool) *goLSMBatch {
+	b := &goLSMBatch{parent: parent, writeOnly: writeOnly}
+	if writeOnly {
+		b.batch = parent.db.NewBatch()
+	} else {
+		b.batch = parent.db.NewIndexedBatch()
+	}
+	b.distinct.goLSMBatch = b
+	return b
+}
+
+// Close implements the Reader interface.
+func (b *goLSMBatch) Close() {
+	if b.closed {
+		panic("this batch was already closed")
+	}
+	b.closed = true
+	b.distinctOpen = false
+	b.batch.Reset()
+	b.builder.reset()
+}
+
+// Closed implements the Reader interface.
+func (b *goLSMBatch) Closed() bool {
+	return b.closed || b.committed
+}
+
+func (b *goLSMBatch) checkReadable() {
+	if b.writeOnly {
+		panic("write-only batch")
+	}
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+}
+
+func (b *goLSMBatch) checkWritable() {
+	if b.distinctOpen {
+		panic("distinct batch open")
+	}
+}
+
+// Get implements the Reader interface.
+func (b *goLSMBatch) Get(key MVCCKey) ([]byte, error) {
+	b.checkReadable()
+	return goLSMGet(b.batch, key)
+}
+
+// GetProto implements the Reader interface.
+func (b *goLSMBatch) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	b.checkReadable()
+	return goLSMGetProto(b.batch, key, msg)
+}
+
+// Iterate implements the Reader interface.
+func (b *goLSMBatch) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)) error {
+	b.checkReadable()
+	return goLSMIterate(b.batch, start, end, f)
+}
+
+// NewIterator returns an iterator over the batch and underlying engine. The
+// iterator observes the writes performed on the batch up to its last seek.
+func (b *goLSMBatch) NewIterator(opts IterOptions) Iterator {
+	b.checkReadable()
+	return newGoLSMIterator(b.batch, opts, b.batch.Count)
+}
+
+// ApplyBatchRepr implements the Writer interface.
+func (b *goLSMBatch) ApplyBatchRepr(repr []byte, sync bool) error {
+	b.checkWritable()
+	return b.applyBatchRepr(repr)
+}
+
+// Clear implements the Writer interface.
+func (b *goLSMBatch) Clear(key MVCCKey) error {
+	b.checkWritable()
+	return b.clear(key)
+}
+
+// SingleClear implements the Writer interface.
+func (b *goLSMBatch) SingleClear(key MVCCKey) error {
+	b.checkWritable()
+	return b.singleClear(key)
+}
+
+// ClearRange implements the Writer interface.
+func (b *goLSMBatch) ClearRange(start, end MVCCKey) error {
+	b.checkWritable()
+	return b.clearRange(start, end)
+}
+
+// ClearIterRange implements the Writer interface.
+func (b *goLSMBatch) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	b.checkWritable()
+	return goLSMClearIterRange(iter, start, end, b.clear)
+}
+
+// Merge implements the Writer interface.
+func (b *goLSMBatch) Merge(key MVCCKey, value []byte) error {
+	b.checkWritable()
+	return b.merge(key, value)
+}
+
+// Put implements the Writer interface.
+func (b *goLSMBatch) Put(key MVCCKey, value []byte) error {
+	b.checkWritable()
+	return b.put(key, value)
+}
+
+// LogData implements the Writer interface.
+func (b *goLSMBatch) LogData(data []byte) error {
+	b.checkWritable()
+	b.builder.LogData(data)
+	return nil
+}
+
+// LogLogicalOp implements the Writer interface.
+func (b *goLSMBatch) LogLogicalOp(op MVCCLogicalOpType, details MVCCLogicalOpDetails) {
+	// No-op. Logical logging disabled.
+}
+
+func (b *goLSMBatch) put(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	b.builder.Put(key, value)
+	b.batch.Set(EncodeKey(key), value)
+	return nil
+}
+
+func (b *goLSMBatch) merge(key MVCCKey, value []byte) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	b.builder.Merge(key, value)
+	b.batch.Merge(EncodeKey(key), value)
+	return nil
+}
+
+func (b *goLSMBatch) clear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	b.builder.Clear(key)
+	b.batch.Delete(EncodeKey(key))
+	return nil
+}
+
+func (b *goLSMBatch) singleClear(key MVCCKey) error {
+	if len(key.Key) == 0 {
+		return emptyKeyError()
+	}
+	b.builder.SingleClear(key)
+	b.batch.Delete(EncodeKey(key))
+	return nil
+}
+
+func (b *goLSMBatch) clearRange(start, end MVCCKey) error {
+	b.builder.clearRange(start, end)
+	b.batch.DeleteRange(EncodeKey(start), EncodeKey(end))
+	return nil
+}
+
+func (b *goLSMBatch) applyBatchRepr(repr []byte) error {
+	if err := goLSMApplyBatchRepr(b.batch, repr); err != nil {
+		return err
+	}
+	return b.builder.ApplyRepr(repr)
+}
+
+// Commit implements the Batch interface.
+func (b *goLSMBatch) Commit(sync bool) error {
+	if b.Closed() {
+		panic("this batch was already committed")
+	}
+	b.distinctOpen = false
+
+	if b.Empty() {
+		// Nothing was written to this batch. Fast path.
+		b.committed = true
+		return nil
+	}
+	if err := b.parent.db.Apply(b.batch, sync); err != nil {
+		return err
+	}
+	b.committed = true
+	return nil
+}
+
+// Distinct implements the Batch interface. Reads performed on the returned
+// ReadWriter observe the batch as of the call to Distinct and not the writes
+// performed through it.
+func (b *goLSMBatch) Distinct() ReadWriter {
+	if b.distinctOpen {
+		panic("distinct batch already open")
+	}
+	b.distinctOpen = true
+	b.distinct.count = b.batch.Count()
+	return &b.distinct
+}
+
+// Empty implements the Batch interface.
+func (b *goLSMBatch) Empty() bool {
+	return b.builder.count == 0 && !b.builder.logData
+}
+
+// Len implements the Batch interface.
+func (b *goLSMBatch) Len() int {
+	return len(b.builder.getRepr())
+}
+
+// Repr implements the Batch interface.
+func (b *goLSMBatch) Repr() []byte {
+	// Make a copy of the builder's byte slice so that the returned []byte is
+	// valid even if the builder is reset.
+	repr := b.builder.getRepr()
+	cpy := make([]byte, len(repr))
+	copy(cpy, repr)
+	return cpy
+}
+
+// goLSMDistinctBatch is the ReadWriter returned by goLSMBatch.Distinct.
+// Writes are passed through to the batch while reads observe the batch as of
+// the first count records, or the engine if the batch is write-only.
+type goLSMDistinctBatch struct {
+	*goLSMBatch
+	count uint32
+}
+
+func (d *goLSMDistinctBatch) reader() goLSMReader {
+	if d.writeOnly {
+		return d.parent.db
+	}
+	return goLSMBatchAt{batch: d.batch, count: d.count}
+}
+
+// Close implements the Reader interface.
+func (d *goLSMDistinctBatch) Close() {
+	if !d.distinctOpen {
+		panic("distinct batch not open")
+	}
+	d.distinctOpen = false
+}
+
+// Get implements the Reader interface.
+func (d *goLSMDistinctBatch) Get(key MVCCKey) ([]byte, error) {
+	return goLSMGet(d.reader(), key)
+}
+
+// GetProto implements the Reader interface.
+func (d *goLSMDistinctBatch) GetProto(
+	key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	return goLSMGetProto(d.reader(), key, msg)
+}
+
+// Iterate implements the Reader interface.
+func (d *goLSMDistinctBatch) Iterate(
+	start, end MVCCKey, f func(MVCCKeyValue) (bool, error),
+) error {
+	return goLSMIterate(d.reader(), start, end, f)
+}
+
+// NewIterator implements the Reader interface.
+func (d *goLSMDistinctBatch) NewIterator(opts IterOptions) Iterator {
+	return newGoLSMIterator(d.reader(), opts, nil)
+}
+
+// ApplyBatchRepr implements the Writer interface.
+func (d *goLSMDistinctBatch) ApplyBatchRepr(repr []byte, sync bool) error {
+	return d.applyBatchRepr(repr)
+}
+
+// Clear implements the Writer interface.
+func (d *goLSMDistinctBatch) Clear(key MVCCKey) error {
+	return d.clear(key)
+}
+
+// SingleClear implements the Writer interface.
+func (d *goLSMDistinctBatch) SingleClear(key MVCCKey) error {
+	return d.singleClear(key)
+}
+
+// ClearRange implements the Writer interface.
+func (d *goLSMDistinctBatch) ClearRange(start, end MVCCKey) error {
+	return d.clearRange(start, end)
+}
+
+// ClearIterRange implements the Writer interface.
+func (d *goLSMDistinctBatch) ClearIterRange(iter Iterator, start, end MVCCKey) error {
+	return goLSMClearIterRange(iter, start, end, d.clear)
+}
+
+// Merge implements the Writer interface.
+func (d *goLSMDistinctBatch) Merge(key MVCCKey, value []byte) error {
+	return d.merge(key, value)
+}
+
+// Put implements the Writer interface.
+func (d *goLSMDistinctBatch) Put(key MVCCKey, value []byte) error {
+	return d.put(key, value)
+}
+
+// LogData implements the Writer interface.
+func (d *goLSMDistinctBatch) LogData(data []byte) error {
+	d.builder.LogData(data)
+	return nil
+}
+
+// goLSMBatchAt is a goLSMReader over the first count records of an indexed
+// batch merged with its DB.
+type goLSMBatchAt struct {
+	batch *golsm.Batch
+	count uint32
+}
+
+func (r goLSMBatchAt) NewIter(o *golsm.IterOptions) *golsm.Iterator {
+	return r.batch.NewIterAt(o, r.count)
+}
+
+// goLSMApplyBatchRepr adds the mutations of a RocksDB batch representation to
+// a golsm batch. LogData entries are dropped.
+func goLSMApplyBatchRepr(b *golsm.Batch, repr []byte) error {
+	_, data, err := rocksDBBatchDecodeHeader(repr)
+	if err != nil {
+		return err
+	}
+	for len(data) > 0 {
+		typ := BatchType(data[0])
+		var key, value []byte
+		if key, data, err = rocksDBBatchVarString(data[1:]); err != nil {
+			return err
+		}
+		switch typ {
+		case BatchTypeDeletion, BatchTypeSingleDeletion:
+			b.Delete(key)
+			continue
+		case BatchTypeLogData:
+			continue
+		case BatchTypeValue, BatchTypeMerge, BatchTypeRangeDeletion:
+		default:
+			return errors.Errorf("unexpected type %d", typ)
+		}
+		if value, data, err = rocksDBBatchVarString(data); err != nil {
+			return err
+		}
+		switch typ {
+		case BatchTypeValue:
+			b.Set(key, value)
+		case BatchTypeMerge:
+			b.Merge(key, value)
+		case BatchTypeRangeDeletion:
+			b.DeleteRange(key, value)
+		}
+	}
+	return nil
+}
+
+// goLSMGet returns the value for the given key, or nil if the key does not
+// exist.
+func goLSMGet(r goLSMReader, key MVCCKey) ([]byte, error) {
+	if len(key.Key) == 0 {
+		return nil, emptyKeyError()
+	}
+	encKey := EncodeKey(key)
+	iter := r.NewIter(&golsm.IterOptions{LowerBound: encKey})
+	defer func() { _ = iter.Close() }()
+	if !iter.SeekGE(encKey) || !bytes.Equal(iter.Key(), encKey) {
+		return nil, iter.Error()
+	}
+	value := iter.Value()
+	result := make([]byte, len(value))
+	copy(result, value)
+	return result, nil
+}
+
+func goLSMGetProto(
+	r goLSMReader, key MVCCKey, msg protoutil.Message,
+) (ok bool, keyBytes, valBytes int64, err error) {
+	var value []byte
+	if value, err = goLSMGet(r, key); err != nil {
+		return
+	}
+	if value == nil {
+		msg.Reset()
+		return
+	}
+	ok = true
+	if msg != nil {
+		err = protoutil.Unmarshal(value, msg)
+	}
+	keyBytes = int64(key.EncodedSize())
+	valBytes = int64(len(value))
+	return
+}
+
+func goLSMIterate(
+	r goLSMReader, start, end MVCCKey, f func(MVCCKeyValue) (bool, error),
+) error {
+	if !start.Less(end) {
+		return nil
+	}
+	it := newGoLSMIterator(r, IterOptions{UpperBound: end.Key}, nil)
+	defer it.Close()
+
+	it.Seek(start)
+	for ; ; it.Next() {
+		ok, err := it.Valid()
+		if err != nil {
+			return err
+		} else if !ok {
+			break
+		}
+		k := it.Key()
+		if !k.Less(end) {
+			break
+		}
+		if done, err := f(MVCCKeyValue{Key: k, Value: it.Value()}); done || err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// goLSMClearIterRange calls clear on each of the keys in [start, end) at
+// which iter is positioned.
+func goLSMClearIterRange(iter Iterator, start, end MVCCKey, clear func(MVCCKey) error) error {
+	iter.Seek(start)
+	for ; ; iter.Next() {
+		ok, err := iter.Valid()
+		if err != nil {
+			return err
+		} else if !ok || !iter.UnsafeKey().Less(end) {
+			return nil
+		}
+		if err := clear(iter.UnsafeKey()); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,429 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"math"
+
+	"github.com/cockroachdb/cockroach/pkg/keys"
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/golsm"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/pkg/errors"
+)
+
+// goLSMReader is implemented by the golsm types that can be iterated over:
+// the DB, snapshots and indexed batches.
+type goLSMReader interface {
+	NewIter(o *golsm.IterOptions) *golsm.Iterator
+}
+
+// goLSMIterator implements Iterator on top of a golsm.Iterator. The keys of
+// the golsm DB are encoded MVCC keys.
+type goLSMIterator struct {
+	reader goLSMReader
+	iter   *golsm.Iterator
+	// If generation is set, the underlying iterator is recreated on the next
+	// seek when the value it returns changes. Batches use this to expose the
+	// writes performed since the iterator was created.
+	generation func() uint32
+	gen        uint32
+	prefix     bool
+	// lower and upper are the encoded bounds of the iterator.
+	lower, upper []byte
+	valid        bool
+	err          error
+	key          MVCCKey
+	keyBuf       []byte
+}
+
+var _ Iterator = &goLSMIterator{}
+
+func newGoLSMIterator(
+	reader goLSMReader, opts IterOptions, generation func() uint32,
+) *goLSMIterator {
+	if !opts.Prefix && len(opts.UpperBound) == 0 && len(opts.LowerBound) == 0 {
+		panic("iterator must set prefix or upper bound or lower bound")
+	}
+	i := &goLSMIterator{reader: reader, generation: generation, prefix: opts.Prefix}
+	if !opts.Prefix {
+		if opts.LowerBound != nil {
+			i.lower = EncodeKey(MakeMVCCMetadataKey(opts.LowerBound))
+		}
+		if opts.UpperBound != nil {
+			i.upper = EncodeKey(MakeMVCCMetadataKey(opts.UpperBound))
+		}
+	}
+	i.init()
+	return i
+}
+
+func (i *goLSMIterator) init() {
+	if i.generation != nil {
+		i.gen = i.generation()
+	}
+	i.iter = i.reader.NewIter(&golsm.IterOptions{LowerBound: i.lower, UpperBound: i.upper})
+	i.valid = false
+}
+
+// maybeRefresh recreates the underlying iterator if it doesn't observe the
+// latest state of its reader.
+func (i *goLSMIterator) maybeRefresh() {
+	if i.generation == nil || i.generation() == i.gen {
+		return
+	}
+	if err := i.iter.Close(); err != nil {
+		i.err = err
+	}
+	i.init()
+}
+
+// setPrefix bounds a prefix iterator to the versions of key.
+func (i *goLSMIterator) setPrefix(key roachpb.Key) {
+	if !i.prefix {
+		return
+	}
+	i.lower = EncodeKey(MakeMVCCMetadataKey(key))
+	i.upper = EncodeKey(MakeMVCCMetadataKey(key.Next()))
+	i.iter.SetBounds(i.lower, i.upper)
+}
+
+func (i *goLSMIterator) updateCurrent() {
+	i.valid = i.iter.Valid()
+	if !i.valid {
+		i.err = i.iter.Error()
+		return
+	}
+	k, ts, err := enginepb.DecodeKey(i.iter.Key())
+	if err != nil {
+		i.valid = false
+		i.err = err
+		return
+	}
+	i.key = MVCCKey{Key: k, Timestamp: ts}
+}
+
+// Close implements the Iterator interface.
+func (i *goLSMIterator) Close() {
+	if i.iter == nil {
+		panic("closing idle iterator")
+	}
+	_ = i.iter.Close()
+	i.iter = nil
+}
+
+// Seek implements the Iterator interface.
+func (i *goLSMIterator) Seek(key MVCCKey) {
+	i.maybeRefresh()
+	i.setPrefix(key.Key)
+	i.err = nil
+	if len(key.Key) == 0 {
+		i.iter.First()
+	} else {
+		i.iter.SeekGE(EncodeKey(key))
+	}
+	i.updateCurrent()
+}
+
+// SeekReverse implements the Iterator interface.
+func (i *goLSMIterator) SeekReverse(key MVCCKey) {
+	i.maybeRefresh()
+	i.setPrefix(key.Key)
+	i.err = nil
+	if len(key.Key) == 0 {
+		i.iter.Last()
+	} else {
+		i.iter.SeekLE(EncodeKey(key))
+	}
+	i.updateCurrent()
+}
+
+// Valid implements the Iterator interface.
+func (i *goLSMIterator) Valid() (bool, error) {
+	return i.valid && i.err == nil, i.err
+}
+
+// Next implements the Iterator interface.
+func (i *goLSMIterator) Next() {
+	i.iter.Next()
+	i.updateCurrent()
+}
+
+// Prev implements the Iterator interface.
+func (i *goLSMIterator) Prev() {
+	i.iter.Prev()
+	i.updateCurrent()
+}
+
+// NextKey implements the Iterator interface.
+func (i *goLSMIterator) NextKey() {
+	if !i.valid {
+		return
+	}
+	i.keyBuf = append(i.keyBuf[:0], i.key.Key...)
+	i.Next()
+	if i.valid && bytes.Equal(i.key.Key, i.keyBuf) {
+		// We're pointed at a different version of the same key. Fall back to
+		// seeking to the next key.
+		i.iter.SeekGE(EncodeKey(MakeMVCCMetadataKey(roachpb.Key(i.keyBuf).Next())))
+		i.updateCurrent()
+	}
+}
+
+// PrevKey implements the Iterator interface.
+func (i *goLSMIterator) PrevKey() {
+	if !i.valid {
+		return
+	}
+	i.keyBuf = append(i.keyBuf[:0], i.key.Key...)
+	i.Prev()
+	if i.valid && bytes.Equal(i.key.Key, i.keyBuf) {
+		// We're pointed at a different version of the same key. Fall back to
+		// seeking to the metadata key and backing up from there.
+		i.iter.SeekLT(EncodeKey(MakeMVCCMetadataKey(i.keyBuf)))
+		i.updateCurrent()
+	}
+}
+
+// Key implements the Iterator interface.
+func (i *goLSMIterator) Key() MVCCKey {
+	return MVCCKey{
+		Key:       append(roachpb.Key(nil), i.key.Key...),
+		Timestamp: i.key.Timestamp,
+	}
+}
+
+// Value implements the Iterator interface.
+func (i *goLSMIterator) Value() []byte {
+	return append([]byte(nil), i.iter.Value()...)
+}
+
+// ValueProto implements the Iterator interface.
+func (i *goLSMIterator) ValueProto(msg protoutil.Message) error {
+	value := i.iter.Value()
+	if len(value) == 0 {
+		return nil
+	}
+	return protoutil.Unmarshal(value, msg)
+}
+
+// UnsafeKey implements the Iterator interface.
+func (i *goLSMIterator) UnsafeKey() MVCCKey {
+	return i.key
+}
+
+// UnsafeValue implements the Iterator interface.
+func (i *goLSMIterator) UnsafeValue() []byte {
+	return i.iter.Value()
+}
+
+// ComputeStats implements the Iterator interface.
+func (i *goLSMIterator) ComputeStats(
+	start, end MVCCKey, nowNanos int64,
+) (enginepb.MVCCStats, error) {
+	return ComputeStatsGo(i, start, end, nowNanos)
+}
+
+// FindSplitKey implements the Iterator interface. It is a port of
+// MVCCFindSplitKey in libroach/mvcc.cc.
+func (i *goLSMIterator) FindSplitKey(
+	start, end, minSplitKey MVCCKey, targetSize int64,
+) (MVCCKey, error) {
+	var sizeSoFar int64
+	var bestSplitKey roachpb.Key
+	bestSplitDiff := int64(math.MaxInt64)
+	var prevKey roachpb.Key
+
+	for i.Seek(start); ; i.Next() {
+		if ok, err := i.Valid(); err != nil {
+			return MVCCKey{}, err
+		} else if !ok || !i.key.Less(end) {
+			break
+		}
+		key := i.key.Key
+		valid := isValidSplitKey(key) && key.Compare(minSplitKey.Key) >= 0
+		diff := targetSize - sizeSoFar
+		if diff < 0 {
+			diff = -diff
+		}
+		if valid && diff < bestSplitDiff {
+			bestSplitKey = append(bestSplitKey[:0], key...)
+			bestSplitDiff = diff
+		}
+		// If diff is increasing, we've passed the ideal split point.
+		if diff > bestSplitDiff && bestSplitKey != nil {
+			break
+		}
+
+		valueLen := int64(len(i.iter.Value()))
+		isValue := i.key.IsValue()
+		if isValue && bytes.Equal(key, prevKey) {
+			sizeSoFar += mvccVersionTimestampSize + valueLen
+		} else {
+			sizeSoFar += int64(len(key)) + 1 + valueLen
+			if isValue {
+				sizeSoFar += mvccVersionTimestampSize
+			}
+		}
+		prevKey = append(prevKey[:0], key...)
+	}
+	if bestSplitKey == nil {
+		return MVCCKey{}, nil
+	}
+	return MVCCKey{Key: bestSplitKey}, nil
+}
+
+// isValidSplitKey is the Go equivalent of IsValidSplitKey.
+func isValidSplitKey(key roachpb.Key) bool {
+	if key.Equal(keys.Meta2KeyMax) {
+		// Range descriptors of ranges ending at Meta2KeyMax would be stored at
+		// Meta1KeyMax, which is already used for a different purpose.
+		return false
+	}
+	for _, span := range keys.NoSplitSpans {
+		if key.Compare(span.Key) > 0 && key.Compare(span.EndKey) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// resetBounds restores the bounds of the underlying iterator after an MVCC
+// operation changed them.
+func (i *goLSMIterator) resetBounds() {
+	i.iter.SetBounds(i.lower, i.upper)
+	i.valid = false
+}
+
+// MVCCGet implements the Iterator interface.
+func (i *goLSMIterator) MVCCGet(
+	key roachpb.Key, timestamp hlc.Timestamp, opts MVCCGetOptions,
+) (*roachpb.Value, *roachpb.Intent, error) {
+	if opts.Inconsistent && opts.Txn != nil {
+		return nil, nil, errors.Errorf("cannot allow inconsistent reads within a transaction")
+	}
+	if len(key) == 0 {
+		return nil, nil, emptyKeyError()
+	}
+
+	i.maybeRefresh()
+	s := newGoLSMScanner(i.iter, key, key.Next(), 1 /* maxKeys */, timestamp, opts.Txn,
+		opts.Inconsistent, opts.Tombstones, opts.IgnoreSequence, false /* reverse */)
+	s.get()
+	defer i.resetBounds()
+
+	if s.err != nil {
+		return nil, nil, s.err
+	}
+	if s.uncertaintyTS != (hlc.Timestamp{}) {
+		return nil, nil, roachpb.NewReadWithinUncertaintyIntervalError(timestamp, s.uncertaintyTS, opts.Txn)
+	}
+
+	intents, err := buildScanIntents(s.intentsRepr())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !opts.Inconsistent && len(intents) > 0 {
+		return nil, nil, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	var intent *roachpb.Intent
+	if len(intents) > 1 {
+		return nil, nil, errors.Errorf("expected 0 or 1 intents, got %d", len(intents))
+	} else if len(intents) == 1 {
+		intent = &intents[0]
+	}
+	if s.count > 1 {
+		return nil, nil, errors.Errorf("expected 0 or 1 result, found %d", s.count)
+	}
+	if s.count == 0 {
+		return nil, intent, nil
+	}
+
+	mvccKey, rawValue, _, err := MVCCScanDecodeKeyValue(s.kvData)
+	if err != nil {
+		return nil, nil, err
+	}
+	value := &roachpb.Value{
+		RawBytes:  rawValue,
+		Timestamp: mvccKey.Timestamp,
+	}
+	return value, intent, nil
+}
+
+// MVCCScan implements the Iterator interface.
+func (i *goLSMIterator) MVCCScan(
+	start, end roachpb.Key, max int64, timestamp hlc.Timestamp, opts MVCCScanOptions,
+) (kvData []byte, numKVs int64, resumeSpan *roachpb.Span, intents []roachpb.Intent, err error) {
+	if opts.Inconsistent && opts.Txn != nil {
+		return nil, 0, nil, nil, errors.Errorf("cannot allow inconsistent reads within a transaction")
+	}
+	if len(end) == 0 {
+		return nil, 0, nil, nil, emptyKeyError()
+	}
+	if max == 0 {
+		resumeSpan = &roachpb.Span{Key: start, EndKey: end}
+		return nil, 0, resumeSpan, nil, nil
+	}
+
+	i.maybeRefresh()
+	s := newGoLSMScanner(i.iter, start, end, max, timestamp, opts.Txn,
+		opts.Inconsistent, opts.Tombstones, opts.IgnoreSequence, opts.Reverse)
+	s.scan()
+	defer i.resetBounds()
+
+	if s.err != nil {
+		return nil, 0, nil, nil, s.err
+	}
+	if s.uncertaintyTS != (hlc.Timestamp{}) {
+		return nil, 0, nil, nil,
+			roachpb.NewReadWithinUncertaintyIntervalError(timestamp, s.uncertaintyTS, opts.Txn)
+	}
+
+	kvData = s.kvData
+	numKVs = s.count
+
+	if resumeKey := s.resumeKey; resumeKey != nil {
+		if opts.Reverse {
+			resumeSpan = &roachpb.Span{Key: start, EndKey: resumeKey.Next()}
+		} else {
+			resumeSpan = &roachpb.Span{Key: resumeKey, EndKey: end}
+		}
+	}
+
+	intents, err = buildScanIntents(s.intentsRepr())
+	if err != nil {
+		return nil, 0, nil, nil, err
+	}
+	if !opts.Inconsistent && len(intents) > 0 {
+		// When encountering intents during a consistent scan we still need to
+		// return the resume key.
+		return nil, 0, resumeSpan, nil, &roachpb.WriteIntentError{Intents: intents}
+	}
+
+	return kvData, numKVs, resumeSpan, intents, nil
+}
+
+// SetUpperBound implements the Iterator interface.
+func (i *goLSMIterator) SetUpperBound(key roachpb.Key) {
+	i.upper = EncodeKey(MakeMVCCMetadataKey(key))
+	i.iter.SetBounds(i.lower, i.upper)
+	i.valid = false
+}
+
+// Stats implements the Iterator interface. The golsm iterator does not
+// collect any statistics.
+func (i *goLSMIterator) Stats() IteratorStats {
+	return IteratorStats{}
+}
@@ -0,0 +1,314 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/pkg/errors"
+)
+
+// The merge operator below is a port of libroach/merge.cc. Values merged with
+// Writer.Merge are MVCCMetadata protos holding an inline value, and the
+// results must match those of the RocksDB merge operator bit for bit.
+
+// valueHeaderSize is the size of the checksum and tag prefix of
+// roachpb.Value.RawBytes.
+const valueHeaderSize = 5
+
+// goLSMMerger implements golsm.Merger.
+type goLSMMerger struct{}
+
+// FullMerge implements golsm.Merger.
+func (goLSMMerger) FullMerge(key, existing []byte, operands [][]byte) ([]byte, error) {
+	var meta enginepb.MVCCMetadata
+	if existing != nil {
+		if err := protoutil.Unmarshal(existing, &meta); err != nil {
+			return nil, errors.Wrap(err, "corrupted existing value")
+		}
+	}
+	return mergeOperands(&meta, operands, true /* full */)
+}
+
+// PartialMerge implements golsm.Merger.
+func (goLSMMerger) PartialMerge(key []byte, operands [][]byte) ([]byte, error) {
+	var meta enginepb.MVCCMetadata
+	return mergeOperands(&meta, operands, false /* full */)
+}
+
+func mergeOperands(meta *enginepb.MVCCMetadata, operands [][]byte, full bool) ([]byte, error) {
+	for _, operand := range operands {
+		var operandMeta enginepb.MVCCMetadata
+		if err := protoutil.Unmarshal(operand, &operandMeta); err != nil {
+			return nil, errors.Wrap(err, "corrupted operand value")
+		}
+		if err := mergeValues(meta, &operandMeta, full); err != nil {
+			return nil, err
+		}
+	}
+	return protoutil.Marshal(meta)
+}
+
+func isTimeSeriesData(rawBytes []byte) bool {
+	return roachpb.Value{RawBytes: rawBytes}.GetTag() == roachpb.ValueType_TIMESERIES
+}
+
+// mergeValues merges right into left. If full is false, time series data is
+// concatenated without being sorted and deduplicated.
+func mergeValues(left, right *enginepb.MVCCMetadata, full bool) error {
+	if left.RawBytes != nil {
+		if right.RawBytes == nil {
+			return errors.New("inconsistent value types for merge (left = bytes, right = ?)")
+		}
+		if isTimeSeriesData(left.RawBytes) || isTimeSeriesData(right.RawBytes) {
+			if !isTimeSeriesData(left.RawBytes) || !isTimeSeriesData(right.RawBytes) {
+				return errors.New("inconsistent value types for merging time series data " +
+					"(type(left) != type(right))")
+			}
+			merged, err := mergeTimeSeriesValues(left.RawBytes, right.RawBytes, full)
+			if err != nil {
+				return err
+			}
+			left.RawBytes = merged
+			return nil
+		}
+		if len(right.RawBytes) >= valueHeaderSize {
+			left.RawBytes = append(left.RawBytes, right.RawBytes[valueHeaderSize:]...)
+		}
+		return nil
+	}
+	left.RawBytes = append([]byte{}, right.RawBytes...)
+	if right.MergeTimestamp != nil {
+		ts := *right.MergeTimestamp
+		left.MergeTimestamp = &ts
+	}
+	if full && isTimeSeriesData(left.RawBytes) {
+		consolidated, err := consolidateTimeSeriesValue(left.RawBytes)
+		if err != nil {
+			return err
+		}
+		left.RawBytes = consolidated
+	}
+	return nil
+}
+
+func parseTimeSeriesValue(rawBytes []byte) (roachpb.InternalTimeSeriesData, error) {
+	var ts roachpb.InternalTimeSeriesData
+	if len(rawBytes) < valueHeaderSize {
+		return ts, errors.New("value too short")
+	}
+	err := protoutil.Unmarshal(rawBytes[valueHeaderSize:], &ts)
+	return ts, err
+}
+
+// serializeTimeSeriesValue encodes ts as the raw bytes of a roachpb.Value
+// with an empty checksum.
+func serializeTimeSeriesValue(ts *roachpb.InternalTimeSeriesData) ([]byte, error) {
+	data, err := protoutil.Marshal(ts)
+	if err != nil {
+		return nil, err
+	}
+	rawBytes := make([]byte, valueHeaderSize, valueHeaderSize+len(data))
+	rawBytes[valueHeaderSize-1] = byte(roachpb.ValueType_TIMESERIES)
+	return append(rawBytes, data...), nil
+}
+
+// mergeTimeSeriesValues merges two values holding InternalTimeSeriesData.
+// The values cannot be merged if they have different start timestamps or
+// sample durations.
+func mergeTimeSeriesValues(left, right []byte, full bool) ([]byte, error) {
+	leftTS, err := parseTimeSeriesValue(left)
+	if err != nil {
+		return nil, errors.Wrap(err, "left InternalTimeSeriesData could not be parsed from bytes")
+	}
+	rightTS, err := parseTimeSeriesValue(right)
+	if err != nil {
+		return nil, errors.Wrap(err, "right InternalTimeSeriesData could not be parsed from bytes")
+	}
+	if leftTS.StartTimestampNanos != rightTS.StartTimestampNanos {
+		return nil, errors.New("TimeSeries merge failed due to mismatched start timestamps")
+	}
+	if leftTS.SampleDurationNanos != rightTS.SampleDurationNanos {
+		return nil, errors.New("TimeSeries merge failed due to mismatched sample durations")
+	}
+
+	useColumnFormat := len(leftTS.Last) > 0 || len(rightTS.Last) > 0
+	if !full {
+		// Values are only sorted and deduplicated by a full merge. Columnar
+		// operands are still converted to keep the order of merges stable.
+		if useColumnFormat {
+			convertToColumnar(&leftTS)
+			convertToColumnar(&rightTS)
+		}
+		appendTimeSeries(&leftTS, &rightTS)
+		return serializeTimeSeriesValue(&leftTS)
+	}
+
+	if useColumnFormat {
+		convertToColumnar(&leftTS)
+		convertToColumnar(&rightTS)
+		// Only the entries of left at or after the smallest offset of right
+		// need to be re-sorted.
+		firstUnsorted := len(leftTS.Offset)
+		if len(rightTS.Offset) > 0 {
+			minOffset := rightTS.Offset[0]
+			for _, o := range rightTS.Offset[1:] {
+				if o < minOffset {
+					minOffset = o
+				}
+			}
+			firstUnsorted = sort.Search(len(leftTS.Offset), func(i int) bool {
+				return leftTS.Offset[i] >= minOffset
+			})
+		}
+		appendTimeSeries(&leftTS, &rightTS)
+		sortAndDeduplicateColumns(&leftTS, firstUnsorted)
+		return serializeTimeSeriesValue(&leftTS)
+	}
+
+	newTS := roachpb.InternalTimeSeriesData{
+		StartTimestampNanos: leftTS.StartTimestampNanos,
+		SampleDurationNanos: leftTS.SampleDurationNanos,
+	}
+	// The samples of left are assumed to be sorted already.
+	sort.SliceStable(rightTS.Samples, func(i, j int) bool {
+		return rightTS.Samples[i].Offset < rightTS.Samples[j].Offset
+	})
+	l, r := leftTS.Samples, rightTS.Samples
+	for len(l) > 0 || len(r) > 0 {
+		var next int32
+		switch {
+		case len(l) == 0:
+			next = r[0].Offset
+		case len(r) == 0:
+			next = l[0].Offset
+		case l[0].Offset <= r[0].Offset:
+			next = l[0].Offset
+		default:
+			next = r[0].Offset
+		}
+		// Only the most recently merged sample at an offset is kept.
+		var src roachpb.InternalTimeSeriesSample
+		for len(l) > 0 && l[0].Offset == next {
+			src, l = l[0], l[1:]
+		}
+		for len(r) > 0 && r[0].Offset == next {
+			src, r = r[0], r[1:]
+		}
+		newTS.Samples = append(newTS.Samples, src)
+	}
+	return serializeTimeSeriesValue(&newTS)
+}
+
+// consolidateTimeSeriesValue sorts the samples of a single time series value,
+// keeping only the last of the samples with duplicate offsets.
+func consolidateTimeSeriesValue(rawBytes []byte) ([]byte, error) {
+	ts, err := parseTimeSeriesValue(rawBytes)
+	if err != nil {
+		return nil, errors.Wrap(err, "InternalTimeSeriesData could not be parsed from bytes")
+	}
+	if len(ts.Offset) > 0 {
+		// Partial merges may have left both row and columnar data behind.
+		convertToColumnar(&ts)
+		sortAndDeduplicateColumns(&ts, 0)
+	} else {
+		sort.SliceStable(ts.Samples, func(i, j int) bool {
+			return ts.Samples[i].Offset < ts.Samples[j].Offset
+		})
+		var deduped []roachpb.InternalTimeSeriesSample
+		for i, s := range ts.Samples {
+			if i+1 < len(ts.Samples) && ts.Samples[i+1].Offset == s.Offset {
+				continue
+			}
+			deduped = append(deduped, s)
+		}
+		ts.Samples = deduped
+	}
+	return serializeTimeSeriesValue(&ts)
+}
+
+// appendTimeSeries appends the repeated fields of src to dst, like the
+// MergeFrom method of the C++ protobuf library.
+func appendTimeSeries(dst, src *roachpb.InternalTimeSeriesData) {
+	dst.Samples = append(dst.Samples, src.Samples...)
+	dst.Offset = append(dst.Offset, src.Offset...)
+	dst.Last = append(dst.Last, src.Last...)
+	dst.Count = append(dst.Count, src.Count...)
+	dst.Sum = append(dst.Sum, src.Sum...)
+	dst.Max = append(dst.Max, src.Max...)
+	dst.Min = append(dst.Min, src.Min...)
+	dst.First = append(dst.First, src.First...)
+	dst.Variance = append(dst.Variance, src.Variance...)
+}
+
+// convertToColumnar converts time series data in the row format to the
+// columnar format. Only the offset and the sum of the samples are retained,
+// as the other fields were never populated in practice.
+func convertToColumnar(ts *roachpb.InternalTimeSeriesData) {
+	for _, s := range ts.Samples {
+		ts.Offset = append(ts.Offset, s.Offset)
+		ts.Last = append(ts.Last, s.Sum)
+	}
+	ts.Samples = nil
+}
+
+// sortAndDeduplicateColumns sorts the columns of the time series data by
+// offset, starting at index firstUnsorted, and removes duplicate offsets,
+// keeping the entry merged last.
+func sortAndDeduplicateColumns(ts *roachpb.InternalTimeSeriesData, firstUnsorted int) {
+	order := make([]int, len(ts.Offset)-firstUnsorted)
+	for i := range order {
+		order[i] = firstUnsorted + i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		return ts.Offset[order[i]] < ts.Offset[order[j]]
+	})
+	deduped := order[:0]
+	for i, idx := range order {
+		if i+1 < len(order) && ts.Offset[order[i+1]] == ts.Offset[idx] {
+			continue
+		}
+		deduped = append(deduped, idx)
+	}
+	order = deduped
+
+	hasRollups := len(ts.Count) > 0
+	offset := append([]int32(nil), ts.Offset[:firstUnsorted]...)
+	last := append([]float64(nil), ts.Last[:firstUnsorted]...)
+	for _, idx := range order {
+		offset = append(offset, ts.Offset[idx])
+		last = append(last, ts.Last[idx])
+	}
+	ts.Offset, ts.Last = offset, last
+	if !hasRollups {
+		return
+	}
+	permuteFloats := func(col []float64) []float64 {
+		res := append([]float64(nil), col[:firstUnsorted]...)
+		for _, idx := range order {
+			res = append(res, col[idx])
+		}
+		return res
+	}
+	count := append([]uint32(nil), ts.Count[:firstUnsorted]...)
+	for _, idx := range order {
+		count = append(count, ts.Count[idx])
+	}
+	ts.Count = count
+	ts.Sum = permuteFloats(ts.Sum)
+	ts.Min = permuteFloats(ts.Min)
+	ts.Max = permuteFloats(ts.Max)
+	ts.First = permuteFloats(ts.First)
+	ts.Variance = permuteFloats(ts.Variance)
+}
@@ -0,0 +1,408 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/enginepb"
+	"github.com/cockroachdb/cockroach/pkg/storage/engine/golsm"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/protoutil"
+	"github.com/pkg/errors"
+)
+
+// maxItersBeforeSeek is the number of calls to Next to perform when looking
+// for the next key or a particular version before seeking.
+const maxItersBeforeSeek = 10
+
+// goLSMScanner implements MVCCGet and MVCCScan on top of a golsm.Iterator.
+// It is a port of the mvccScanner in libroach/mvcc.h and must return the same
+// results; see there for a description of the cases handled by
+// getAndAdvance. The scanner only observes keys in [start, end), which are
+// enforced with the bounds of the iterator.
+type goLSMScanner struct {
+	iter    *golsm.Iterator
+	start   roachpb.Key
+	end     roachpb.Key
+	reverse bool
+	maxKeys int64
+	ts      hlc.Timestamp
+	txn     *roachpb.Transaction
+
+	inconsistent     bool
+	tombstones       bool
+	ignoreSequence   bool
+	checkUncertainty bool
+
+	// Results.
+	kvData        []byte
+	count         int64
+	intents       RocksDBBatchBuilder
+	uncertaintyTS hlc.Timestamp
+	resumeKey     roachpb.Key
+	err           error
+
+	// The current entry of the iterator. curKey and curValue alias the
+	// memory of the iterator.
+	curRawKey []byte
+	curKey    roachpb.Key
+	curTS     hlc.Timestamp
+	curValue  []byte
+	meta      enginepb.MVCCMetadata
+	keyBuf    roachpb.Key
+}
+
+func newGoLSMScanner(
+	iter *golsm.Iterator,
+	start, end roachpb.Key,
+	maxKeys int64,
+	timestamp hlc.Timestamp,
+	txn *roachpb.Transaction,
+	inconsistent, tombstones, ignoreSequence, reverse bool,
+) *goLSMScanner {
+	s := &goLSMScanner{
+		iter:           iter,
+		start:          start,
+		end:            end,
+		reverse:        reverse,
+		maxKeys:        maxKeys,
+		ts:             timestamp,
+		txn:            txn,
+		inconsistent:   inconsistent,
+		tombstones:     tombstones,
+		ignoreSequence: ignoreSequence,
+	}
+	if txn != nil {
+		s.checkUncertainty = timestamp.Less(txn.MaxTimestamp)
+	}
+	iter.SetBounds(EncodeKey(MakeMVCCMetadataKey(start)), EncodeKey(MakeMVCCMetadataKey(end)))
+	return s
+}
+
+func (s *goLSMScanner) get() {
+	if !s.iterSeek(EncodeKey(MakeMVCCMetadataKey(s.start))) {
+		return
+	}
+	s.getAndAdvance()
+}
+
+func (s *goLSMScanner) scan() {
+	if s.reverse {
+		if !s.prevKey(s.end) {
+			return
+		}
+	} else if !s.iterSeek(EncodeKey(MakeMVCCMetadataKey(s.start))) {
+		return
+	}
+	for s.getAndAdvance() {
+	}
+	if s.count == s.maxKeys && s.advanceKey() {
+		s.resumeKey = append(roachpb.Key(nil), s.curKey...)
+	}
+}
+
+func (s *goLSMScanner) txnID() []byte {
+	if s.txn == nil {
+		return nil
+	}
+	return s.txn.ID.GetBytes()
+}
+
+func (s *goLSMScanner) seqNumIsIgnored(seq enginepb.TxnSeq) bool {
+	return s.txn != nil && enginepb.TxnSeqIsIgnored(seq, s.txn.IgnoredSeqNums)
+}
+
+func (s *goLSMScanner) uncertaintyError(ts hlc.Timestamp) bool {
+	s.uncertaintyTS = ts
+	s.kvData = nil
+	s.count = 0
+	s.intents.reset()
+	return false
+}
+
+func (s *goLSMScanner) setError(err error) bool {
+	s.err = err
+	return false
+}
+
+func (s *goLSMScanner) addIntent() {
+	s.intents.Put(MakeMVCCMetadataKey(s.curKey), s.curValue)
+}
+
+// intentsRepr returns the intents encountered by the scan as a RocksDB batch
+// repr, or nil if there are none.
+func (s *goLSMScanner) intentsRepr() []byte {
+	if s.intents.count == 0 {
+		return nil
+	}
+	return s.intents.Finish()
+}
+
+func (s *goLSMScanner) addKV(value []byte) {
+	var lens [8]byte
+	binary.LittleEndian.PutUint32(lens[0:4], uint32(len(value)))
+	binary.LittleEndian.PutUint32(lens[4:8], uint32(len(s.curRawKey)))
+	s.kvData = append(s.kvData, lens[:]...)
+	s.kvData = append(s.kvData, s.curRawKey...)
+	s.kvData = append(s.kvData, value...)
+	s.count++
+}
+
+func (s *goLSMScanner) getAndAdvance() bool {
+	if s.curTS != (hlc.Timestamp{}) {
+		if !s.ts.Less(s.curTS) {
+			// 1. Fast path: there is no intent and our read timestamp is newer
+			// than the most recent version's timestamp.
+			return s.addAndAdvance(s.curValue)
+		}
+		if s.checkUncertainty {
+			// 2. Our txn's read timestamp is less than the max timestamp seen by
+			// the txn. We need to check for clock uncertainty errors.
+			if !s.txn.MaxTimestamp.Less(s.curTS) {
+				return s.uncertaintyError(s.curTS)
+			}
+			return s.seekVersion(s.txn.MaxTimestamp, true)
+		}
+		// 3. Seek to the version visible at our read timestamp.
+		return s.seekVersion(s.ts, false)
+	}
+
+	if len(s.curValue) == 0 {
+		return s.setError(errors.Errorf("zero-length mvcc metadata"))
+	}
+	if err := protoutil.Unmarshal(s.curValue, &s.meta); err != nil {
+		return s.setError(errors.Errorf("unable to decode MVCCMetadata"))
+	}
+	if s.meta.RawBytes != nil {
+		// 4. Emit immediately if the value is inline.
+		return s.addAndAdvance(s.meta.RawBytes)
+	}
+	if s.meta.Txn == nil {
+		return s.setError(errors.Errorf("intent without transaction"))
+	}
+
+	ownIntent := bytes.Equal(s.meta.Txn.ID.GetBytes(), s.txnID())
+	metaTS := hlc.Timestamp(s.meta.Timestamp)
+	// prevTS is the timestamp to read at if the intent is ignored: strictly
+	// below the intent, but not above our read timestamp.
+	prevTS := func() hlc.Timestamp {
+		if s.ts.Less(metaTS) {
+			return s.ts
+		}
+		return metaTS.Prev()
+	}
+	if s.ts.Less(metaTS) && !ownIntent {
+		// 5. The key contains an intent, but we're reading before the intent.
+		return s.seekVersion(s.ts, false)
+	}
+
+	if s.inconsistent {
+		// 6. The key contains an intent and we're doing an inconsistent read
+		// at a timestamp newer than the intent. The intent is returned
+		// separately and we read below it.
+		if s.count == s.maxKeys {
+			// The intents should only correspond to KVs that lie before the
+			// resume key.
+			return false
+		}
+		s.addIntent()
+		return s.seekVersion(prevTS(), false)
+	}
+
+	if !ownIntent {
+		// 7. The key contains an intent which was not written by our
+		// transaction. Keep scanning to return all of the intents in the
+		// range.
+		s.addIntent()
+		return s.advanceKey()
+	}
+
+	if s.txn.Epoch == s.meta.Txn.Epoch {
+		if s.ignoreSequence ||
+			(s.txn.Sequence >= s.meta.Txn.Sequence && !s.seqNumIsIgnored(s.meta.Txn.Sequence)) {
+			// 8. We're reading our own txn's intent at an equal or higher
+			// sequence. Read at the intent timestamp, which may have been
+			// pushed.
+			return s.seekVersion(metaTS, false)
+		}
+		// 9. We're reading our own txn's intent at a lower sequence, or the
+		// write of the intent has been rolled back. Read the value of the
+		// intent history visible at our sequence, if any.
+		if s.getFromIntentHistory() {
+			return s.advanceKey()
+		}
+		// 10. No value of the intent history is visible, so ignore the intent.
+		return s.seekVersion(prevTS(), false)
+	}
+
+	if s.txn.Epoch < s.meta.Txn.Epoch {
+		// 11. The intent was written by a later epoch of our transaction.
+		return s.setError(errors.Errorf("failed to read with epoch %d due to a write intent with epoch %d",
+			s.txn.Epoch, s.meta.Txn.Epoch))
+	}
+
+	// 12. The intent was written by an earlier epoch of our transaction, so
+	// read as if the transaction were starting fresh.
+	return s.seekVersion(prevTS(), false)
+}
+
+func (s *goLSMScanner) getFromIntentHistory() bool {
+	history := s.meta.IntentHistory
+	// Find the last intent with a sequence number at or below ours that was
+	// not rolled back.
+	up := sort.Search(len(history), func(i int) bool {
+		return history[i].Sequence > s.txn.Sequence
+	})
+	for up > 0 && s.seqNumIsIgnored(history[up-1].Sequence) {
+		up--
+	}
+	if up == 0 {
+		return false
+	}
+	if value := history[up-1].Value; len(value) > 0 || s.tombstones {
+		s.addKV(value)
+	}
+	return true
+}
+
+func (s *goLSMScanner) addAndAdvance(value []byte) bool {
+	// Deleted versions are only included if tombstones were requested.
+	if len(value) > 0 || s.tombstones {
+		s.addKV(value)
+		if s.count == s.maxKeys {
+			return false
+		}
+	}
+	return s.advanceKey()
+}
+
+// seekVersion positions the iterator at the version of the current key at or
+// below the desired timestamp and adds it, or moves on to the next key if
+// there is no such version. If checkUncertainty is true, finding a version
+// above the read timestamp results in an uncertainty error.
+func (s *goLSMScanner) seekVersion(desired hlc.Timestamp, checkUncertainty bool) bool {
+	s.keyBuf = append(s.keyBuf[:0], s.curKey...)
+	for i := 0; i < maxItersBeforeSeek; i++ {
+		if !s.iterNext() {
+			return s.advanceKeyAtEnd()
+		}
+		if !bytes.Equal(s.curKey, s.keyBuf) {
+			return s.advanceKeyAtNewKey(s.keyBuf)
+		}
+		if !desired.Less(s.curTS) {
+			if checkUncertainty && s.ts.Less(s.curTS) {
+				return s.uncertaintyError(s.curTS)
+			}
+			return s.addAndAdvance(s.curValue)
+		}
+	}
+
+	if !s.iterSeek(EncodeKey(MVCCKey{Key: s.keyBuf, Timestamp: desired})) {
+		return s.advanceKeyAtEnd()
+	}
+	if !bytes.Equal(s.curKey, s.keyBuf) {
+		return s.advanceKeyAtNewKey(s.keyBuf)
+	}
+	if !desired.Less(s.curTS) {
+		if checkUncertainty && s.ts.Less(s.curTS) {
+			return s.uncertaintyError(s.curTS)
+		}
+		return s.addAndAdvance(s.curValue)
+	}
+	return s.advanceKey()
+}
+
+// advanceKey moves the iterator to the first entry of the next key in the
+// direction of the scan.
+func (s *goLSMScanner) advanceKey() bool {
+	if s.reverse {
+		return s.prevKey(s.curKey)
+	}
+	return s.nextKey()
+}
+
+func (s *goLSMScanner) advanceKeyAtEnd() bool {
+	if s.reverse {
+		// Moving forward exhausted the iterator, so back up from the key we
+		// were looking at.
+		return s.prevKey(s.keyBuf)
+	}
+	return false
+}
+
+func (s *goLSMScanner) advanceKeyAtNewKey(key roachpb.Key) bool {
+	if s.reverse {
+		return s.prevKey(key)
+	}
+	// We're already at the new key.
+	return true
+}
+
+func (s *goLSMScanner) nextKey() bool {
+	s.keyBuf = append(s.keyBuf[:0], s.curKey...)
+	for i := 0; i < maxItersBeforeSeek; i++ {
+		if !s.iterNext() {
+			return false
+		}
+		if !bytes.Equal(s.curKey, s.keyBuf) {
+			return true
+		}
+	}
+	return s.iterSeek(EncodeKey(MakeMVCCMetadataKey(s.keyBuf.Next())))
+}
+
+// prevKey moves the iterator to the first entry, i.e. the metadata or the
+// latest version, of the last key before key.
+func (s *goLSMScanner) prevKey(key roachpb.Key) bool {
+	if !s.iter.SeekLT(EncodeKey(MakeMVCCMetadataKey(key))) {
+		return s.updateCurrent()
+	}
+	if !s.updateCurrent() {
+		return false
+	}
+	if s.curTS == (hlc.Timestamp{}) {
+		// We landed on an intent or inline value, which is the first entry.
+		return true
+	}
+	s.keyBuf = append(s.keyBuf[:0], s.curKey...)
+	return s.iterSeek(EncodeKey(MakeMVCCMetadataKey(s.keyBuf)))
+}
+
+func (s *goLSMScanner) iterSeek(key []byte) bool {
+	s.iter.SeekGE(key)
+	return s.updateCurrent()
+}
+
+func (s *goLSMScanner) iterNext() bool {
+	s.iter.Next()
+	return s.updateCurrent()
+}
+
+func (s *goLSMScanner) updateCurrent() bool {
+	if !s.iter.Valid() {
+		if err := s.iter.Error(); err != nil {
+			return s.setError(err)
+		}
+		return false
+	}
+	s.curRawKey = s.iter.Key()
+	s.curValue = s.iter.Value()
+	key, ts, err := enginepb.DecodeKey(s.curRawKey)
+	if err != nil {
+		return s.setError(errors.Errorf("failed to split mvcc key"))
+	}
+	s.curKey, s.curTS = key, ts
+	return true
+}
@@ -0,0 +1,199 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/roachpb"
+	"github.com/cockroachdb/cockroach/pkg/util/hlc"
+	"github.com/cockroachdb/cockroach/pkg/util/leaktest"
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+// TestGoLSMCompare verifies that the golsm comparator orders encoded keys
+// like MVCCKey.Less.
+func TestGoLSMCompare(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rng, _ := randutil.NewPseudoRand()
+	var keys []MVCCKey
+	for i := 0; i < 1000; i++ {
+		key := MVCCKey{Key: roachpb.Key(randutil.RandBytes(rng, rng.Intn(4)+1))}
+		if rng.Intn(4) != 0 {
+			key.Timestamp.WallTime = rng.Int63n(5)
+			if rng.Intn(2) == 0 {
+				key.Timestamp.Logical = rng.Int31n(3)
+			}
+		}
+		keys = append(keys, key)
+	}
+
+	encoded := make([][]byte, len(keys))
+	for i := range keys {
+		encoded[i] = EncodeKey(keys[i])
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Less(keys[j]) })
+	sort.Slice(encoded, func(i, j int) bool { return goLSMCompare(encoded[i], encoded[j]) < 0 })
+	for i := range keys {
+		if !bytes.Equal(EncodeKey(keys[i]), encoded[i]) {
+			t.Fatalf("%d: expected %s, found %x", i, keys[i], encoded[i])
+		}
+	}
+}
+
+func scanAllKVs(t *testing.T, r Reader) []MVCCKeyValue {
+	var kvs []MVCCKeyValue
+	if err := r.Iterate(MVCCKey{Key: roachpb.KeyMin}, MVCCKey{Key: roachpb.KeyMax},
+		func(kv MVCCKeyValue) (bool, error) {
+			kvs = append(kvs, kv)
+			return false, nil
+		}); err != nil {
+		t.Fatal(err)
+	}
+	return kvs
+}
+
+// TestGoLSMBatchRepr verifies that the representation of golsm batches can be
+// applied to RocksDB and vice versa.
+func TestGoLSMBatchRepr(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	rocksDB := NewInMem(roachpb.Attributes{}, 1<<20)
+	defer rocksDB.Close()
+	goLSM := NewInMemGoLSM(roachpb.Attributes{}, 1<<20)
+	defer goLSM.Close()
+
+	write := func(b Batch) {
+		ts := hlc.Timestamp{WallTime: 1}
+		for i := 0; i < 10; i++ {
+			key := roachpb.Key(fmt.Sprintf("key-%02d", i))
+			if err := b.Put(MakeMVCCMetadataKey(key), []byte("meta")); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Put(MVCCKey{Key: key, Timestamp: ts}, []byte("value")); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := b.Merge(mvccKey("merged"), appender("a")); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Merge(mvccKey("merged"), appender("b")); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.Clear(mvccKey("key-01")); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.ClearRange(mvccKey("key-05"), mvccKey("key-08")); err != nil {
+			t.Fatal(err)
+		}
+		if err := b.LogData([]byte("log")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name     string
+		src, dst Engine
+	}{
+		{"golsm->rocksdb", goLSM, rocksDB},
+		{"rocksdb->golsm", rocksDB, goLSM},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := tc.src.NewBatch()
+			defer b.Close()
+			write(b)
+			if err := tc.dst.ApplyBatchRepr(b.Repr(), false /* sync */); err != nil {
+				t.Fatal(err)
+			}
+			if err := b.Commit(false /* sync */); err != nil {
+				t.Fatal(err)
+			}
+			expected, actual := scanAllKVs(t, tc.src), scanAllKVs(t, tc.dst)
+			if len(expected) == 0 {
+				t.Fatal("expected a non-empty engine")
+			}
+			if !reflect.DeepEqual(expected, actual) {
+				t.Fatalf("expected %v, but found %v", expected, actual)
+			}
+		})
+	}
+}
+
+// TestGoLSMIngestExternalFiles verifies that SSTables written by RocksDB can
+// be ingested into golsm.
+func TestGoLSMIngestExternalFiles(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	ctx := context.Background()
+	e := NewInMemGoLSM(roachpb.Attributes{}, 1<<20)
+	defer e.Close()
+
+	if err := e.Put(mvccKey("b"), []byte("old")); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.Put(mvccKey("c"), []byte("deleted")); err != nil {
+		t.Fatal(err)
+	}
+
+	sst, err := MakeRocksDBSstFileWriter()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sst.Close()
+	for _, kv := range []MVCCKeyValue{
+		{Key: mvccKey("a"), Value: []byte("a")},
+		{Key: mvccKey("b"), Value: []byte("new")},
+	} {
+		if err := sst.Add(kv); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sst.Delete(mvccKey("c")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := sst.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := e.GetAuxiliaryDir() + "/ingest.sst"
+	f, err := e.OpenFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Append(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := e.IngestExternalFiles(ctx, []string{path}, true, true); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []MVCCKeyValue{
+		{Key: mvccKey("a"), Value: []byte("a")},
+		{Key: mvccKey("b"), Value: []byte("new")},
+	}
+	if kvs := scanAllKVs(t, e); !reflect.DeepEqual(expected, kvs) {
+		t.Fatalf("expected %v, but found %v", expected, kvs)
+	}
+	if _, err := e.ReadFile(path); err != os.ErrNotExist {
+		t.Fatalf("expected the ingested file to be removed, but found %v", err)
+	}
+}
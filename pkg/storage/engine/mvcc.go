@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
@@ -1066,6 +1067,55 @@ func MVCCDelete(
 	return mvccPutUsingIter(ctx, engine, iter, ms, key, timestamp, noValue, txn, nil /* valueFn */)
 }
 
+// MVCCLock acquires an exclusive lock on the key for the transaction by
+// laying down a lock-only intent. The intent carries the value that is visible
+// to the transaction, so reads of the key are unaffected, but it conflicts
+// with the reads and writes of other transactions like any other intent. A
+// lock-only intent is never committed: resolving it removes it just like an
+// aborted intent, so locking a key does not create a new MVCC version of it
+// and is not visible to rangefeeds.
+//
+// If the transaction has already written to the key in its current epoch, the
+// key is already locked. The existing intent is then rewritten with the value
+// visible to the transaction and stays a regular intent, so that it carries
+// the sequence number of the lock like any other write. Outside of a
+// transaction there is nothing to hold the lock, so MVCCLock is a no-op when
+// txn is nil.
+func MVCCLock(
+	ctx context.Context,
+	engine ReadWriter,
+	ms *enginepb.MVCCStats,
+	key roachpb.Key,
+	timestamp hlc.Timestamp,
+	txn *roachpb.Transaction,
+) error {
+	if txn == nil {
+		return nil
+	}
+	iter := engine.NewIterator(IterOptions{Prefix: true})
+	defer iter.Close()
+
+	buf := newPutBuffer()
+	defer buf.release()
+
+	ok, _, _, err := mvccGetMetadata(iter, MakeMVCCMetadataKey(key), &buf.meta)
+	if err != nil {
+		return err
+	}
+	lockOnly := true
+	if ok && buf.meta.Txn != nil && buf.meta.Txn.ID == txn.ID &&
+		buf.meta.Txn.Epoch == txn.Epoch && !buf.meta.IsLockOnly() {
+		lockOnly = false
+	}
+	return mvccPutInternal(ctx, engine, iter, ms, key, timestamp, nil, txn, buf,
+		func(existVal *roachpb.Value) ([]byte, error) {
+			if existVal == nil {
+				return nil, nil
+			}
+			return existVal.RawBytes, nil
+		}, lockOnly)
+}
+
 var noValue = roachpb.Value{}
 
 // mvccPutUsingIter sets the value for a specified key using the provided
@@ -1094,7 +1144,7 @@ func mvccPutUsingIter(
 	buf := newPutBuffer()
 
 	err := mvccPutInternal(ctx, engine, iter, ms, key, timestamp, rawBytes,
-		txn, buf, valueFn)
+		txn, buf, valueFn, false /* lockOnly */)
 
 	// Using defer would be more convenient, but it is measurably slower.
 	buf.release()
@@ -1189,11 +1239,12 @@ func replayTransactionalWrite(
 
 	// If the valueFn is specified, we must apply it to the would-be value at the key.
 	if valueFn != nil {
-		prevSeq, prevValueWritten := meta.GetPrevIntentSeq(txn.Sequence)
+		prevSeq, prevValueWritten := meta.GetPrevIntentSeq(txn.Sequence, txn.IgnoredSeqNums)
 		if prevValueWritten {
 			// If the previous value was found in the IntentHistory,
 			// simply apply the value function to the historic value
-			// to get the would-be value.
+			// to get the would-be value. Writes that were rolled back
+			// by the transaction are skipped over.
 			prevVal, _ := meta.GetIntentValue(prevSeq)
 			value, err = valueFn(&roachpb.Value{RawBytes: prevVal})
 			if err != nil {
@@ -1268,6 +1319,7 @@ func mvccPutInternal(
 	txn *roachpb.Transaction,
 	buf *putBuffer,
 	valueFn func(*roachpb.Value) ([]byte, error),
+	lockOnly bool,
 ) error {
 	if len(key) == 0 {
 		return emptyKeyError()
@@ -1398,7 +1450,27 @@ func mvccPutInternal(
 			// version.  For example, a conditional put within same
 			// transaction should read previous write.
 			if valueFn != nil {
-				value, err = valueFn(existingVal)
+				visibleVal := existingVal
+				if existingVal != nil && enginepb.TxnSeqIsIgnored(prevIntentSequence, txn.IgnoredSeqNums) {
+					// The write of the existing intent has been rolled back. The
+					// value visible to the transaction is the latest write in the
+					// intent history that was not rolled back or, if there is no
+					// such write, the last committed value under the intent.
+					if prevSeq, ok := meta.GetPrevIntentSeq(prevIntentSequence, txn.IgnoredSeqNums); ok {
+						prevVal, _ := meta.GetIntentValue(prevSeq)
+						visibleVal = &roachpb.Value{RawBytes: prevVal}
+					} else {
+						committedBuf := newGetBuffer()
+						defer committedBuf.release()
+						committedBuf.meta = buf.meta
+						visibleVal, _, _, err = mvccGetInternal(
+							ctx, iter, metaKey, readTimestamp, false /* consistent */, safeValue, nil /* txn */, committedBuf)
+						if err != nil {
+							return err
+						}
+					}
+				}
+				value, err = valueFn(visibleVal)
 				if err != nil {
 					return err
 				}
@@ -1454,7 +1526,13 @@ func mvccPutInternal(
 						"previous intent of the transaction with the same epoch not found for %s (%+v)",
 						metaKey, txn)
 				}
-				buf.newMeta.AddToIntentHistory(prevIntentSequence, prevIntentValBytes)
+				// The value of a lock-only intent was not written by the
+				// transaction, so it is not recorded in the history. If the
+				// new write is rolled back, the intent is removed and the key
+				// reverts to its committed value.
+				if !meta.IsLockOnly() {
+					buf.newMeta.AddToIntentHistory(prevIntentSequence, prevIntentValBytes)
+				}
 			} else {
 				buf.newMeta.IntentHistory = nil
 			}
@@ -1526,6 +1604,9 @@ func mvccPutInternal(
 		}
 		buf.newMeta.Txn = txnMeta
 		buf.newMeta.Timestamp = hlc.LegacyTimestamp(writeTimestamp)
+		if lockOnly {
+			buf.newMeta.LockOnly = &lockOnly
+		}
 	}
 	newMeta := &buf.newMeta
 
@@ -1882,7 +1963,7 @@ func MVCCDeleteRange(
 
 	for i := range kvs {
 		err = mvccPutInternal(
-			ctx, engine, iter, ms, kvs[i].Key, timestamp, nil, txn, buf, nil)
+			ctx, engine, iter, ms, kvs[i].Key, timestamp, nil, txn, buf, nil, false /* lockOnly */)
 		if err != nil {
 			break
 		}
@@ -2283,6 +2364,12 @@ func mvccResolveWriteIntent(
 	epochsMatch := meta.Txn.Epoch == intent.Txn.Epoch
 	timestampsValid := !intent.Txn.Timestamp.Less(hlc.Timestamp(meta.Timestamp))
 	commit := intent.Status == roachpb.COMMITTED && epochsMatch && timestampsValid
+	// A lock-only intent only held a lock on the key for its transaction and
+	// its value must not become visible as a new version, so it is removed
+	// instead of being committed.
+	if meta.IsLockOnly() {
+		commit = false
+	}
 
 	// Note the small difference to commit epoch handling here: We allow
 	// a push from a previous epoch to move a newer intent. That's not
@@ -2312,11 +2399,29 @@ func mvccResolveWriteIntent(
 	inProgress := !intent.Status.IsFinalized() && meta.Txn.Epoch >= intent.Txn.Epoch
 	pushed := inProgress && hlc.Timestamp(meta.Timestamp).Less(intent.Txn.Timestamp)
 
+	// If the transaction rolled back some of its writes to this key, e.g.
+	// with a ROLLBACK TO SAVEPOINT, restore the latest write that was not
+	// rolled back before proceeding. If all of the writes of the current
+	// epoch were rolled back, the intent is removed below just as if the
+	// transaction had aborted.
+	var rewritten bool
+	if epochsMatch && len(intent.IgnoredSeqNums) > 0 {
+		var removeIntent bool
+		rewritten, removeIntent, err = mvccMaybeRewriteIntentHistory(
+			engine, ms, intent, metaKey, meta, buf, &origMetaKeySize, &origMetaValSize)
+		if err != nil {
+			return false, err
+		}
+		if removeIntent {
+			commit, pushed, inProgress = false, false, false
+		}
+	}
+
 	// There's nothing to do if meta's epoch is greater than or equal txn's
 	// epoch and the state is still in progress but the intent was not pushed
 	// to a larger timestamp.
 	if inProgress && !pushed {
-		return false, nil
+		return rewritten, nil
 	}
 
 	// If we're committing, or if the commit timestamp of the intent has been moved forward, and if
@@ -2465,6 +2570,63 @@ func mvccResolveWriteIntent(
 	return true, nil
 }
 
+// mvccMaybeRewriteIntentHistory rolls back the writes to the intent's key
+// that were made at sequence numbers ignored by the intent's transaction. If
+// the latest write was ignored, the latest write in the intent history that
+// was not ignored becomes the value of the intent again, and meta and the
+// supplied metadata sizes are updated to describe the rewritten intent. If
+// no such write exists, removeIntent is returned as true and the caller is
+// expected to remove the intent altogether.
+func mvccMaybeRewriteIntentHistory(
+	engine ReadWriter,
+	ms *enginepb.MVCCStats,
+	intent roachpb.Intent,
+	metaKey MVCCKey,
+	meta *enginepb.MVCCMetadata,
+	buf *putBuffer,
+	metaKeySize, metaValSize *int64,
+) (rewritten bool, removeIntent bool, err error) {
+	if !enginepb.TxnSeqIsIgnored(meta.Txn.Sequence, intent.IgnoredSeqNums) {
+		// The latest write was not rolled back, so the intent stays as is.
+		return false, false, nil
+	}
+	prevSeq, ok := meta.GetPrevIntentSeq(meta.Txn.Sequence, intent.IgnoredSeqNums)
+	if !ok {
+		return false, true, nil
+	}
+	idx := sort.Search(len(meta.IntentHistory), func(i int) bool {
+		return meta.IntentHistory[i].Sequence >= prevSeq
+	})
+	restoredVal := meta.IntentHistory[idx].Value
+
+	newMeta := *meta
+	newTxn := *meta.Txn
+	newTxn.Sequence = prevSeq
+	newMeta.Txn = &newTxn
+	newMeta.IntentHistory = meta.IntentHistory[:idx]
+	newMeta.Deleted = len(restoredVal) == 0
+	newMeta.ValBytes = int64(len(restoredVal))
+
+	newMetaKeySize, newMetaValSize, err := buf.putMeta(engine, metaKey, &newMeta)
+	if err != nil {
+		return false, false, err
+	}
+	versionKey := MVCCKey{Key: intent.Key, Timestamp: hlc.Timestamp(meta.Timestamp)}
+	if err := engine.Put(versionKey, restoredVal); err != nil {
+		return false, false, err
+	}
+
+	// The intent keeps its timestamp, so the size of the version below it
+	// does not matter for the stats update.
+	if ms != nil {
+		ms.Add(updateStatsOnPut(intent.Key, 0 /* prevValSize */, *metaKeySize, *metaValSize,
+			newMetaKeySize, newMetaValSize, meta, &newMeta))
+	}
+	*meta = newMeta
+	*metaKeySize, *metaValSize = newMetaKeySize, newMetaValSize
+	return true, false, nil
+}
+
 // IterAndBuf used to pass iterators and buffers between MVCC* calls, allowing
 // reuse without the callers needing to know the particulars.
 type IterAndBuf struct {
@@ -2700,6 +2862,68 @@ func MVCCGarbageCollect(
 	return nil
 }
 
+// MVCCGarbageCollectRange removes all versions of all keys in the span
+// [start, end) with a single range deletion. Every key in the span must be
+// garbage at the GC threshold: it must not have an intent or an inline value,
+// and its newest version must be a deletion tombstone at or below the
+// threshold. Otherwise, an error is returned and nothing is removed. The
+// returned stats are those of the removed data, computed as of the timestamp,
+// and must be subtracted from the range's stats by the caller.
+func MVCCGarbageCollectRange(
+	ctx context.Context,
+	engine ReadWriter,
+	start, end roachpb.Key,
+	threshold hlc.Timestamp,
+	timestamp hlc.Timestamp,
+) (enginepb.MVCCStats, error) {
+	startKey, endKey := MakeMVCCMetadataKey(start), MakeMVCCMetadataKey(end)
+	iter := engine.NewIterator(IterOptions{UpperBound: end})
+	defer iter.Close()
+
+	// Verify that the whole span is garbage. Each step lands on the newest
+	// version of a key, or on its MVCCMetadata.
+	var count int64
+	for iter.Seek(startKey); ; iter.NextKey() {
+		if ok, err := iter.Valid(); err != nil {
+			return enginepb.MVCCStats{}, err
+		} else if !ok {
+			break
+		}
+		unsafeKey := iter.UnsafeKey()
+		if !unsafeKey.IsValue() {
+			meta := &enginepb.MVCCMetadata{}
+			if err := protoutil.Unmarshal(iter.UnsafeValue(), meta); err != nil {
+				return enginepb.MVCCStats{}, err
+			}
+			if meta.Txn != nil {
+				return enginepb.MVCCStats{}, errors.Errorf("request to GC intent at %q", unsafeKey.Key)
+			}
+			return enginepb.MVCCStats{}, errors.Errorf("request to GC inline value at %q", unsafeKey.Key)
+		}
+		if len(iter.UnsafeValue()) != 0 {
+			return enginepb.MVCCStats{}, errors.Errorf("request to GC non-deleted, latest value of %q", unsafeKey.Key)
+		}
+		if threshold.Less(unsafeKey.Timestamp) {
+			return enginepb.MVCCStats{}, errors.Errorf("request to GC %q, deleted above the GC threshold %s",
+				unsafeKey.Key, threshold)
+		}
+		count++
+	}
+	if count == 0 {
+		return enginepb.MVCCStats{}, nil
+	}
+
+	ms, err := iter.ComputeStats(startKey, endKey, timestamp.WallTime)
+	if err != nil {
+		return enginepb.MVCCStats{}, err
+	}
+	log.Eventf(ctx, "GC'ing %d keys in [%s,%s) with a range deletion", count, start, end)
+	if err := engine.ClearRange(startKey, endKey); err != nil {
+		return enginepb.MVCCStats{}, err
+	}
+	return ms, nil
+}
+
 // MVCCFindSplitKey finds a key from the given span such that the left side of
 // the split is roughly targetSize bytes. The returned key will never be chosen
 // from the key ranges listed in keys.NoSplitSpans.
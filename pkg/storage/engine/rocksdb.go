@@ -601,6 +601,10 @@ func (r *RocksDB) open() error {
 	if len(r.cfg.Dir) != 0 {
 		log.Infof(context.TODO(), "opening rocksdb instance at %q", r.cfg.Dir)
 
+		if err := checkEngineMarker(r.cfg.Dir, enginepb.EngineTypeRocksDB); err != nil {
+			return err
+		}
+
 		// Check the version number.
 		var err error
 		if existingVersion, err = getVersion(r.cfg.Dir); err != nil {
@@ -661,6 +665,11 @@ func (r *RocksDB) open() error {
 			return err
 		}
 	}
+	if len(r.cfg.Dir) != 0 && !r.cfg.ReadOnly {
+		if err := writeEngineMarker(r.cfg.Dir, enginepb.EngineTypeRocksDB); err != nil {
+			return err
+		}
+	}
 
 	r.commit.cond.L = &r.commit.Mutex
 	r.syncer.cond.L = &r.syncer.Mutex
@@ -873,16 +882,21 @@ func (r *RocksDB) Iterate(start, end MVCCKey, f func(MVCCKeyValue) (bool, error)
 
 // Capacity queries the underlying file system for disk capacity information.
 func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
+	return computeCapacity(r.cfg.Dir, r.cfg.MaxSizeBytes)
+}
+
+// computeCapacity returns the capacity of a store in dir whose size is
+// limited to maxSizeBytes. An empty dir denotes an in-memory store.
+func computeCapacity(dir string, maxSizeBytes int64) (roachpb.StoreCapacity, error) {
 	fileSystemUsage := gosigar.FileSystemUsage{}
-	dir := r.cfg.Dir
 	if dir == "" {
 		// This is an in-memory instance. Pretend we're empty since we
 		// don't know better and only use this for testing. Using any
 		// part of the actual file system here can throw off allocator
 		// rebalancing in a hard-to-trace manner. See #7050.
 		return roachpb.StoreCapacity{
-			Capacity:  r.cfg.MaxSizeBytes,
-			Available: r.cfg.MaxSizeBytes,
+			Capacity:  maxSizeBytes,
+			Available: maxSizeBytes,
 		}, nil
 	}
 	if err := fileSystemUsage.Get(dir); err != nil {
@@ -900,10 +914,10 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	fsuTotal := int64(fileSystemUsage.Total)
 	fsuAvail := int64(fileSystemUsage.Avail)
 
-	// Find the total size of all the files in the r.dir and all its
+	// Find the total size of all the files in the dir and all its
 	// subdirectories.
 	var totalUsedBytes int64
-	if errOuter := filepath.Walk(r.cfg.Dir, func(path string, info os.FileInfo, err error) error {
+	if errOuter := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			// This can happen if rocksdb removes files out from under us - just keep
 			// going to get the best estimate we can.
@@ -929,7 +943,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	// If no size limitation have been placed on the store size or if the
 	// limitation is greater than what's available, just return the actual
 	// totals.
-	if r.cfg.MaxSizeBytes == 0 || r.cfg.MaxSizeBytes >= fsuTotal || r.cfg.Dir == "" {
+	if maxSizeBytes == 0 || maxSizeBytes >= fsuTotal || dir == "" {
 		return roachpb.StoreCapacity{
 			Capacity:  fsuTotal,
 			Available: fsuAvail,
@@ -937,7 +951,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 		}, nil
 	}
 
-	available := r.cfg.MaxSizeBytes - totalUsedBytes
+	available := maxSizeBytes - totalUsedBytes
 	if available > fsuAvail {
 		available = fsuAvail
 	}
@@ -946,7 +960,7 @@ func (r *RocksDB) Capacity() (roachpb.StoreCapacity, error) {
 	}
 
 	return roachpb.StoreCapacity{
-		Capacity:  r.cfg.MaxSizeBytes,
+		Capacity:  maxSizeBytes,
 		Available: available,
 		Used:      totalUsedBytes,
 	}, nil
@@ -2614,6 +2628,10 @@ func goToCTxn(txn *roachpb.Transaction) C.DBTxn {
 		r.epoch = C.uint32_t(txn.Epoch)
 		r.sequence = C.int32_t(txn.Sequence)
 		r.max_timestamp = goToCTimestamp(txn.MaxTimestamp)
+		if len(txn.IgnoredSeqNums) > 0 {
+			r.ignored_seqnums.len = C.int(len(txn.IgnoredSeqNums))
+			r.ignored_seqnums.ranges = (*C.DBIgnoredSeqNumRange)(unsafe.Pointer(&txn.IgnoredSeqNums[0]))
+		}
 	}
 	return r
 }
@@ -56,6 +56,11 @@ const (
 	// gcKeyVersionChunkBytes is the threshold size for splitting
 	// GCRequests into multiple batches.
 	gcKeyVersionChunkBytes = base.ChunkRaftCommandThresholdBytes
+
+	// gcRangeKeyMinBytes is the size of the versions in a span of adjacent,
+	// fully garbage keys above which the span is removed with a range
+	// deletion instead of sending its keys individually.
+	gcRangeKeyMinBytes = gcKeyVersionChunkBytes
 )
 
 // gcQueue manages a queue of replicas slated to be scanned in their
@@ -474,6 +479,9 @@ func (NoopGCer) SetGCThreshold(context.Context, GCThreshold) error { return nil
 // GC implements storage.GCer.
 func (NoopGCer) GC(context.Context, []roachpb.GCRequest_GCKey) error { return nil }
 
+// GCRange implements storage.GCer.
+func (NoopGCer) GCRange(context.Context, []roachpb.GCRequest_GCRangeKey) error { return nil }
+
 type replicaGCer struct {
 	repl  *Replica
 	count int32 // update atomically
@@ -524,6 +532,15 @@ func (r *replicaGCer) GC(ctx context.Context, keys []roachpb.GCRequest_GCKey) er
 	return r.send(ctx, req)
 }
 
+func (r *replicaGCer) GCRange(ctx context.Context, rangeKeys []roachpb.GCRequest_GCRangeKey) error {
+	if len(rangeKeys) == 0 {
+		return nil
+	}
+	req := r.template()
+	req.RangeKeys = rangeKeys
+	return r.send(ctx, req)
+}
+
 // process iterates through all keys in a replica's range, calling the garbage
 // collector for each key and associated set of values. GC'd keys are batched
 // into GC calls. Extant intents are resolved if intents are older than
@@ -604,6 +621,9 @@ type GCInfo struct {
 	// keys with GC'able data, the number of "old" intents and the number of
 	// associated distinct transactions.
 	NumKeysAffected, IntentsConsidered, IntentTxns int
+	// NumRangeKeys is the number of spans of fully garbage keys which were
+	// removed with range deletions.
+	NumRangeKeys int
 	// TransactionSpanTotal is the total number of entries in the transaction span.
 	TransactionSpanTotal int
 	// Summary of transactions which were found GCable (assuming that
@@ -670,6 +690,7 @@ type GCThreshold struct {
 type GCer interface {
 	SetGCThreshold(context.Context, GCThreshold) error
 	GC(context.Context, []roachpb.GCRequest_GCKey) error
+	GCRange(context.Context, []roachpb.GCRequest_GCRangeKey) error
 }
 
 // RunGC runs garbage collection for the specified descriptor on the
@@ -711,6 +732,11 @@ func RunGC(
 		return GCInfo{}, errors.Wrap(err, "failed to set GC thresholds")
 	}
 
+	// Range-local keys are interleaved with unreplicated keys which the
+	// iteration does not see, so only user keys can be removed with range
+	// deletions.
+	userKeyStart := keys.LocalMax
+
 	var batchGCKeys []roachpb.GCRequest_GCKey
 	var batchGCKeysBytes int64
 	var expBaseKey roachpb.Key
@@ -723,11 +749,56 @@ func RunGC(
 	txnMap := map[uuid.UUID]*roachpb.Transaction{}
 	intentSpanMap := map[uuid.UUID][]roachpb.Span{}
 
+	// A run of adjacent user keys of which all versions are garbage. Once it
+	// ends, the run is removed with a range deletion if the keys and values
+	// of its versions add up to gcRangeKeyMinBytes, and its keys are GC'ed
+	// individually otherwise. runKeyBytes only counts the keys, which is
+	// what the size of a batch of GC'ed keys is measured in.
+	var runStart, runEnd roachpb.Key
+	var runKeys []roachpb.GCRequest_GCKey
+	var runBytes, runKeyBytes int64
+
+	// flushRun ends the current run of garbage keys, if any.
+	flushRun := func() {
+		if runStart == nil {
+			return
+		}
+		if runBytes >= gcRangeKeyMinBytes {
+			rangeKey := roachpb.GCRequest_GCRangeKey{StartKey: runStart, EndKey: runEnd.Next()}
+			if err := gcer.GCRange(ctx, []roachpb.GCRequest_GCRangeKey{rangeKey}); err != nil {
+				// As for chunked GC requests, failing to GC a span is safe.
+				log.Warning(ctx, err)
+			} else {
+				infoMu.NumRangeKeys++
+			}
+		} else {
+			batchGCKeys = append(batchGCKeys, runKeys...)
+			batchGCKeysBytes += runKeyBytes
+			if batchGCKeysBytes >= gcKeyVersionChunkBytes {
+				err := gcer.GC(ctx, batchGCKeys)
+				iter.ResetAllocator()
+				batchGCKeys = nil
+				batchGCKeysBytes = 0
+				if err != nil {
+					log.Warning(ctx, err)
+				}
+			}
+		}
+		runStart, runEnd, runKeys, runBytes, runKeyBytes = nil, nil, nil, 0, 0
+	}
+
 	// processKeysAndValues is invoked with each key and its set of
 	// values. Intents older than the intent age threshold are sent for
 	// resolution and values after the MVCC metadata, and possible
 	// intent, are sent for garbage collection.
 	processKeysAndValues := func() {
+		// Any key which isn't found to be entirely garbage below ends the
+		// current run.
+		defer func() {
+			if len(keys) > 0 && !expBaseKey.Equal(runEnd) {
+				flushRun()
+			}
+		}()
 		// If there's more than a single value for the key, possibly send for GC.
 		if len(keys) > 1 {
 			meta := &enginepb.MVCCMetadata{}
@@ -765,6 +836,28 @@ func RunGC(
 				}
 				// See if any values may be GC'd.
 				if idx, gcTS := gc.Filter(keys[startIdx:], vals[startIdx:]); gcTS != (hlc.Timestamp{}) {
+					// If the newest version of a user key is a deletion at or
+					// below the threshold, all of its versions are garbage
+					// and the key joins the current run.
+					if meta.Txn == nil && idx == 0 && len(vals[1]) == 0 &&
+						expBaseKey.Compare(userKeyStart) >= 0 {
+						if runStart == nil {
+							runStart = expBaseKey
+						}
+						runEnd = expBaseKey
+						runKeys = append(runKeys, roachpb.GCRequest_GCKey{Key: expBaseKey, Timestamp: gcTS})
+						for i := 1; i < len(keys); i++ {
+							keyBytes = int64(keys[i].EncodedSize())
+							valBytes = int64(len(vals[i]))
+							infoMu.GCInfo.AffectedVersionsKeyBytes += keyBytes
+							infoMu.GCInfo.AffectedVersionsValBytes += valBytes
+							runBytes += keyBytes + valBytes
+							runKeyBytes += keyBytes
+						}
+						infoMu.NumKeysAffected++
+						return
+					}
+
 					// Batch keys after the total size of version keys exceeds
 					// the threshold limit. This avoids sending potentially large
 					// GC requests through Raft. Iterate through the keys in reverse
@@ -846,6 +939,7 @@ func RunGC(
 	}
 	// Handle last collected set of keys/vals.
 	processKeysAndValues()
+	flushRun()
 	if len(batchGCKeys) > 0 {
 		if err := gcer.GC(ctx, batchGCKeys); err != nil {
 			return GCInfo{}, err
@@ -20,6 +20,7 @@ import (
 	"testing/quick"
 	"time"
 
+	"github.com/cockroachdb/cockroach/pkg/config"
 	"github.com/cockroachdb/cockroach/pkg/keys"
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
 	"github.com/cockroachdb/cockroach/pkg/storage/engine"
@@ -1057,3 +1058,113 @@ func TestGCQueueChunkRequests(t *testing.T) {
 		t.Errorf("expected %d gc requests; got %d", e, a)
 	}
 }
+
+// recordingGCer is a GCer which records the keys and spans it is asked to
+// garbage collect.
+type recordingGCer struct {
+	keys      []roachpb.GCRequest_GCKey
+	rangeKeys []roachpb.GCRequest_GCRangeKey
+}
+
+var _ GCer = &recordingGCer{}
+
+func (r *recordingGCer) SetGCThreshold(context.Context, GCThreshold) error { return nil }
+
+func (r *recordingGCer) GC(_ context.Context, keys []roachpb.GCRequest_GCKey) error {
+	r.keys = append(r.keys, keys...)
+	return nil
+}
+
+func (r *recordingGCer) GCRange(_ context.Context, rangeKeys []roachpb.GCRequest_GCRangeKey) error {
+	r.rangeKeys = append(r.rangeKeys, rangeKeys...)
+	return nil
+}
+
+// TestGCQueueRangeKeys verifies that a large span of adjacent, fully garbage
+// keys is sent as a range key, while smaller spans and keys with live values
+// are sent individually.
+func TestGCQueueRangeKeys(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+	ctx := context.Background()
+
+	eng := engine.NewInMem(roachpb.Attributes{}, 1<<20)
+	defer eng.Close()
+
+	const keyCount = 200
+	// Make each version key take 1000 bytes, so that the first keyCount keys,
+	// which have two versions each, add up to more than gcRangeKeyMinBytes.
+	const keySize = 1000 - 13
+	if 2*keyCount*1000 < gcRangeKeyMinBytes {
+		t.Fatalf("expected gcRangeKeyMinBytes to be smaller than %d", 2*keyCount*1000)
+	}
+	fmtStr := fmt.Sprintf("%%0%dd", keySize)
+	key := func(i int) roachpb.Key {
+		return roachpb.Key(fmt.Sprintf(fmtStr, i))
+	}
+
+	ts1, ts2, ts3 := makeTS(1E9, 0), makeTS(2E9, 0), makeTS(3E9, 0)
+	put := func(i int) {
+		if err := engine.MVCCPut(ctx, eng, nil, key(i), ts1, roachpb.MakeValueFromString("value"), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	del := func(i int) {
+		if err := engine.MVCCDelete(ctx, eng, nil, key(i), ts2, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A large run of garbage keys, followed by a key with a live value, a
+	// key with a garbage version and a small run of garbage keys.
+	for i := 0; i < keyCount; i++ {
+		put(i)
+		del(i)
+	}
+	put(keyCount)
+	put(keyCount + 1)
+	if err := engine.MVCCPut(
+		ctx, eng, nil, key(keyCount+1), ts3, roachpb.MakeValueFromString("value"), nil,
+	); err != nil {
+		t.Fatal(err)
+	}
+	put(keyCount + 2)
+	del(keyCount + 2)
+	put(keyCount + 3)
+	del(keyCount + 3)
+
+	desc := &roachpb.RangeDescriptor{RangeID: 1, StartKey: roachpb.RKeyMin, EndKey: roachpb.RKeyMax}
+	now := makeTS(10E9, 0)
+	var gcer recordingGCer
+	snap := eng.NewSnapshot()
+	defer snap.Close()
+	info, err := RunGC(ctx, desc, snap, now, config.GCPolicy{TTLSeconds: 1}, &gcer,
+		func(ctx context.Context, intents []roachpb.Intent) error {
+			return nil
+		},
+		func(ctx context.Context, txn *roachpb.Transaction, intents []roachpb.Intent) error {
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expRangeKeys := []roachpb.GCRequest_GCRangeKey{
+		{StartKey: key(0), EndKey: key(keyCount - 1).Next()},
+	}
+	if !reflect.DeepEqual(expRangeKeys, gcer.rangeKeys) {
+		t.Errorf("expected range keys %s, found %s", expRangeKeys, gcer.rangeKeys)
+	}
+	expKeys := []roachpb.GCRequest_GCKey{
+		{Key: key(keyCount + 1), Timestamp: ts1},
+		{Key: key(keyCount + 2), Timestamp: ts2},
+		{Key: key(keyCount + 3), Timestamp: ts2},
+	}
+	if !reflect.DeepEqual(expKeys, gcer.keys) {
+		t.Errorf("expected keys %s, found %s", expKeys, gcer.keys)
+	}
+	if info.NumRangeKeys != 1 {
+		t.Errorf("expected 1 range key, found %d", info.NumRangeKeys)
+	}
+	if info.NumKeysAffected != keyCount+3 {
+		t.Errorf("expected %d keys affected, found %d", keyCount+3, info.NumKeysAffected)
+	}
+}
@@ -340,6 +340,9 @@ func (p *Processor) sendStop(pErr *roachpb.Error) {
 // The optionally provided "catch-up" iterator is used to read changes from the
 // engine which occurred after the provided start timestamp.
 //
+// If withDiff is true, the values published to the registration are
+// accompanied by the previous value of their key.
+//
 // If the method returns false, the processor will have been stopped, so calling
 // Stop is not necessary.
 //
@@ -348,6 +351,7 @@ func (p *Processor) Register(
 	span roachpb.RSpan,
 	startTS hlc.Timestamp,
 	catchupIter engine.SimpleIterator,
+	withDiff bool,
 	stream Stream,
 	errC chan<- *roachpb.Error,
 ) bool {
@@ -357,17 +361,34 @@ func (p *Processor) Register(
 	p.syncEventC()
 
 	r := newRegistration(
-		span.AsRawSpanWithNoLocals(), startTS, catchupIter, p.Config.EventChanCap,
+		span.AsRawSpanWithNoLocals(), startTS, catchupIter, withDiff, p.Config.EventChanCap,
 		p.Metrics, stream, errC,
 	)
 	select {
 	case p.regC <- r:
+		if withDiff {
+			// Wait for the processor goroutine to add the registration to the
+			// registry, so that NeedsPrevValues reflects it once we return.
+			p.syncEventC()
+		}
 		return true
 	case <-p.stoppedC:
 		return false
 	}
 }
 
+// NeedsPrevValues returns whether any registration attached to the processor
+// wants the previous values of keys, which callers only need to populate in
+// the logical ops they pass to ConsumeLogicalOps if it returns true. The
+// result accounts for every registration for which Register has returned.
+// Safe to call on nil Processor.
+func (p *Processor) NeedsPrevValues() bool {
+	if p == nil {
+		return false
+	}
+	return p.reg.NeedsPrevValues()
+}
+
 // Len returns the number of registrations attached to the processor.
 func (p *Processor) Len() int {
 	if p == nil {
@@ -500,7 +521,7 @@ func (p *Processor) consumeLogicalOps(ctx context.Context, ops []enginepb.MVCCLo
 		switch t := op.GetValue().(type) {
 		case *enginepb.MVCCWriteValueOp:
 			// Publish the new value directly.
-			p.publishValue(ctx, t.Key, t.Timestamp, t.Value)
+			p.publishValue(ctx, t.Key, t.Timestamp, t.Value, t.PrevValue)
 
 		case *enginepb.MVCCWriteIntentOp:
 			// No updates to publish.
@@ -510,7 +531,7 @@ func (p *Processor) consumeLogicalOps(ctx context.Context, ops []enginepb.MVCCLo
 
 		case *enginepb.MVCCCommitIntentOp:
 			// Publish the newly committed value.
-			p.publishValue(ctx, t.Key, t.Timestamp, t.Value)
+			p.publishValue(ctx, t.Key, t.Timestamp, t.Value, t.PrevValue)
 
 		case *enginepb.MVCCAbortIntentOp:
 			// No updates to publish.
@@ -543,7 +564,7 @@ func (p *Processor) initResolvedTS(ctx context.Context) {
 }
 
 func (p *Processor) publishValue(
-	ctx context.Context, key roachpb.Key, timestamp hlc.Timestamp, value []byte,
+	ctx context.Context, key roachpb.Key, timestamp hlc.Timestamp, value, prevValue []byte,
 ) {
 	if !p.Span.ContainsKey(roachpb.RKey(key)) {
 		log.Fatalf(ctx, "key %v not in Processor's key range %v", key, p.Span)
@@ -557,6 +578,7 @@ func (p *Processor) publishValue(
 			RawBytes:  value,
 			Timestamp: timestamp,
 		},
+		PrevValue: roachpb.Value{RawBytes: prevValue},
 	})
 	p.reg.PublishToOverlapping(span, &event)
 }
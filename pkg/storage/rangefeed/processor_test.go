@@ -179,7 +179,8 @@ func TestProcessorBasic(t *testing.T) {
 	r1OK := p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r1Stream,
 		r1ErrC,
 	)
@@ -294,7 +295,8 @@ func TestProcessorBasic(t *testing.T) {
 	r2OK := p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("c"), EndKey: roachpb.RKey("z")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,  /* catchUpIter */
+		true, /* withDiff */
 		r2Stream,
 		r2ErrC,
 	)
@@ -334,6 +336,23 @@ func TestProcessorBasic(t *testing.T) {
 	require.Equal(t, valEvent, r1Stream.Events())
 	require.Equal(t, valEvent, r2Stream.Events())
 
+	// Test value with a previous value, which is only published to the
+	// registration that wants diffs.
+	p.ConsumeLogicalOps(makeLogicalOp(&enginepb.MVCCWriteValueOp{
+		Key:       roachpb.Key("k"),
+		Timestamp: hlc.Timestamp{WallTime: 22, Logical: 1},
+		Value:     []byte("val2b"),
+		PrevValue: []byte("val2"),
+	}))
+	p.syncEventAndRegistrations()
+	newVal := roachpb.Value{RawBytes: []byte("val2b"), Timestamp: hlc.Timestamp{WallTime: 22, Logical: 1}}
+	require.Equal(t, []*roachpb.RangeFeedEvent{rangeFeedValue(roachpb.Key("k"), newVal)}, r1Stream.Events())
+	require.Equal(t, []*roachpb.RangeFeedEvent{makeRangeFeedEvent(&roachpb.RangeFeedValue{
+		Key:       roachpb.Key("k"),
+		Value:     newVal,
+		PrevValue: roachpb.Value{RawBytes: []byte("val2")},
+	})}, r2Stream.Events())
+
 	// Test value that only overlaps the second registration.
 	p.ConsumeLogicalOps(
 		writeValueOpWithKV(roachpb.Key("v"), hlc.Timestamp{WallTime: 23}, []byte("val3")),
@@ -364,7 +383,8 @@ func TestProcessorBasic(t *testing.T) {
 	r3OK := p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("c"), EndKey: roachpb.RKey("z")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r3Stream,
 		r3ErrC,
 	)
@@ -387,7 +407,7 @@ func TestNilProcessor(t *testing.T) {
 	// The following should panic because they are not safe
 	// to call on a nil Processor.
 	require.Panics(t, func() { p.Start(stop.NewStopper(), nil) })
-	require.Panics(t, func() { p.Register(roachpb.RSpan{}, hlc.Timestamp{}, nil, nil, nil) })
+	require.Panics(t, func() { p.Register(roachpb.RSpan{}, hlc.Timestamp{}, nil, false, nil, nil) })
 }
 
 func TestProcessorSlowConsumer(t *testing.T) {
@@ -404,7 +424,8 @@ func TestProcessorSlowConsumer(t *testing.T) {
 	p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r1Stream,
 		r1ErrC,
 	)
@@ -413,7 +434,8 @@ func TestProcessorSlowConsumer(t *testing.T) {
 	p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("z")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r2Stream,
 		r2ErrC,
 	)
@@ -532,7 +554,8 @@ func TestProcessorInitializeResolvedTimestamp(t *testing.T) {
 	p.Register(
 		roachpb.RSpan{Key: roachpb.RKey("a"), EndKey: roachpb.RKey("m")},
 		hlc.Timestamp{WallTime: 1},
-		nil, /* catchUpIter */
+		nil,   /* catchUpIter */
+		false, /* withDiff */
 		r1Stream,
 		make(chan *roachpb.Error, 1),
 	)
@@ -766,7 +789,7 @@ func TestProcessorConcurrentStop(t *testing.T) {
 			runtime.Gosched()
 			s := newTestStream()
 			errC := make(chan<- *roachpb.Error, 1)
-			p.Register(p.Span, hlc.Timestamp{}, nil, s, errC)
+			p.Register(p.Span, hlc.Timestamp{}, nil, false /* withDiff */, s, errC)
 		}()
 		go func() {
 			defer wg.Done()
@@ -835,7 +858,7 @@ func TestProcessorRegistrationObservesOnlyNewEvents(t *testing.T) {
 			s := newTestStream()
 			regs[s] = firstIdx
 			errC := make(chan *roachpb.Error, 1)
-			p.Register(p.Span, hlc.Timestamp{}, nil, s, errC)
+			p.Register(p.Span, hlc.Timestamp{}, nil, false /* withDiff */, s, errC)
 			regDone <- struct{}{}
 		}
 	}()
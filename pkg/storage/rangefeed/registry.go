@@ -15,6 +15,7 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cockroachdb/cockroach/pkg/roachpb"
@@ -56,7 +57,10 @@ type registration struct {
 	span             roachpb.Span
 	catchupIter      engine.SimpleIterator
 	catchupTimestamp hlc.Timestamp
-	metrics          *Metrics
+	// withDiff is true if the values published to the registration are
+	// accompanied by the previous value of their key.
+	withDiff bool
+	metrics  *Metrics
 
 	// Output.
 	stream Stream
@@ -86,6 +90,7 @@ func newRegistration(
 	span roachpb.Span,
 	startTS hlc.Timestamp,
 	catchupIter engine.SimpleIterator,
+	withDiff bool,
 	bufferSz int,
 	metrics *Metrics,
 	stream Stream,
@@ -94,6 +99,7 @@ func newRegistration(
 	r := registration{
 		span:             span,
 		catchupIter:      catchupIter,
+		withDiff:         withDiff,
 		metrics:          metrics,
 		stream:           stream,
 		errC:             errC,
@@ -236,8 +242,13 @@ func (r *registration) runCatchupScan() error {
 
 	// Iterate though all keys using Next. We want to publish all committed
 	// versions of each key that are after the registration's startTS, so we
-	// can't use NextKey.
+	// can't use NextKey. If the registration wants diffs, the version that
+	// follows each of them in the iteration, which may be at or before startTS,
+	// is its previous value.
 	var meta enginepb.MVCCMetadata
+	// needPrev is set if the last event in reorderBuf is waiting for its
+	// previous value.
+	needPrev := false
 
 	for r.catchupIter.Seek(startKey); ; r.catchupIter.Next() {
 		if ok, err := r.catchupIter.Valid(); err != nil {
@@ -270,7 +281,17 @@ func (r *registration) runCatchupScan() error {
 			// filter on the registration's starting timestamp. Instead, we
 			// return all inline writes.
 			unsafeVal = meta.RawBytes
-		} else if !r.catchupTimestamp.Less(unsafeKey.Timestamp) {
+		}
+
+		sameKey := bytes.Equal(unsafeKey.Key, lastKey)
+		if needPrev && sameKey {
+			// This is the previous value of the last buffered event.
+			var prevVal []byte
+			a, prevVal = a.Copy(unsafeVal, 0)
+			reorderBuf[len(reorderBuf)-1].Val.PrevValue = roachpb.Value{RawBytes: prevVal}
+		}
+		needPrev = false
+		if unsafeKey.IsValue() && !r.catchupTimestamp.Less(unsafeKey.Timestamp) {
 			// At or before the registration's exclusive starting timestamp.
 			// Ignore.
 			continue
@@ -282,7 +303,7 @@ func (r *registration) runCatchupScan() error {
 		ts := unsafeKey.Timestamp
 
 		// Output values in order
-		if !bytes.Equal(key, lastKey) {
+		if !sameKey {
 			if err := outputEvents(); err != nil {
 				return err
 			}
@@ -298,6 +319,7 @@ func (r *registration) runCatchupScan() error {
 			},
 		})
 		reorderBuf = append(reorderBuf, event)
+		needPrev = r.withDiff && unsafeKey.IsValue()
 	}
 
 	// Output events for the last key encountered.
@@ -322,6 +344,12 @@ func (r registration) String() string {
 type registry struct {
 	tree    interval.Tree // *registration items
 	idAlloc int64
+
+	// withDiffCount is the number of registrations in the registry that
+	// want the previous values of keys. It is only written by the goroutine
+	// that owns the registry, but may be read by others. Accessed
+	// atomically.
+	withDiffCount int32
 }
 
 func makeRegistry() registry {
@@ -342,6 +370,22 @@ func (reg *registry) Register(r *registration) {
 	if err := reg.tree.Insert(r, false /* fast */); err != nil {
 		panic(err)
 	}
+	if r.withDiff {
+		atomic.AddInt32(&reg.withDiffCount, 1)
+	}
+}
+
+// NeedsPrevValues returns whether any registration in the registry wants
+// the previous values of keys. Safe to call from any goroutine.
+func (reg *registry) NeedsPrevValues() bool {
+	return atomic.LoadInt32(&reg.withDiffCount) > 0
+}
+
+// removed is called for each registration removed from the registry.
+func (reg *registry) removed(r *registration) {
+	if r.withDiff {
+		atomic.AddInt32(&reg.withDiffCount, -1)
+	}
 }
 
 func (reg *registry) nextID() int64 {
@@ -371,12 +415,24 @@ func (reg *registry) PublishToOverlapping(span roachpb.Span, event *roachpb.Rang
 		panic(fmt.Sprintf("unexpected RangeFeedEvent variant: %v", event))
 	}
 
+	// The previous value is stripped from the event published to the
+	// registrations that don't want diffs.
+	var eventWithoutDiff *roachpb.RangeFeedEvent
+	if t, ok := event.GetValue().(*roachpb.RangeFeedValue); ok && t.PrevValue.IsPresent() {
+		eventWithoutDiff = &roachpb.RangeFeedEvent{}
+		eventWithoutDiff.MustSetValue(&roachpb.RangeFeedValue{Key: t.Key, Value: t.Value})
+	}
+
 	reg.forOverlappingRegs(span, func(r *registration) (bool, *roachpb.Error) {
 		// Don't publish events if they are equal to or less
 		// than the registration's starting timestamp.
 
 		if r.catchupTimestamp.Less(minTS) {
-			r.publish(event)
+			if !r.withDiff && eventWithoutDiff != nil {
+				r.publish(eventWithoutDiff)
+			} else {
+				r.publish(event)
+			}
 		}
 		return false, nil
 	})
@@ -386,9 +442,15 @@ func (reg *registry) PublishToOverlapping(span roachpb.Span, event *roachpb.Rang
 // registration has already been disconnected, this is intended only to clean
 // up the registry.
 func (reg *registry) Unregister(r *registration) {
+	// The registration may already have been removed when it was
+	// disconnected, in which case it isn't removed again.
+	n := reg.tree.Len()
 	if err := reg.tree.Delete(r, false /* fast */); err != nil {
 		panic(err)
 	}
+	if reg.tree.Len() < n {
+		reg.removed(r)
+	}
 }
 
 // Disconnect disconnects all registrations that overlap the specified span with
@@ -421,6 +483,7 @@ func (reg *registry) forOverlappingRegs(
 		dis, pErr := fn(r)
 		if dis {
 			r.disconnect(pErr)
+			reg.removed(r)
 			toDelete = append(toDelete, i)
 		}
 		return false
@@ -96,7 +96,7 @@ type testRegistration struct {
 }
 
 func newTestRegistration(
-	span roachpb.Span, ts hlc.Timestamp, catchup engine.SimpleIterator,
+	span roachpb.Span, ts hlc.Timestamp, catchup engine.SimpleIterator, withDiff bool,
 ) *testRegistration {
 	s := newTestStream()
 	errC := make(chan *roachpb.Error, 1)
@@ -105,6 +105,7 @@ func newTestRegistration(
 			span,
 			ts,
 			catchup,
+			withDiff,
 			5,
 			NewMetrics(),
 			s,
@@ -137,7 +138,7 @@ func TestRegistrationBasic(t *testing.T) {
 	ev2.MustSetValue(&roachpb.RangeFeedValue{Value: val})
 
 	// Registration with no catchup scan specified.
-	noCatchupReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	noCatchupReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	noCatchupReg.publish(ev1)
 	noCatchupReg.publish(ev2)
 	require.Equal(t, len(noCatchupReg.buf), 2)
@@ -153,7 +154,7 @@ func TestRegistrationBasic(t *testing.T) {
 		makeInline("ba", "val2"),
 		makeKV("bc", "val3", 11),
 		makeKV("bd", "val4", 9),
-	}))
+	}), false /* withDiff */)
 	catchupReg.publish(ev1)
 	catchupReg.publish(ev2)
 	require.Equal(t, len(catchupReg.buf), 2)
@@ -167,7 +168,7 @@ func TestRegistrationBasic(t *testing.T) {
 
 	// EXIT CONDITIONS
 	// External Disconnect.
-	disconnectReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	disconnectReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	disconnectReg.publish(ev1)
 	disconnectReg.publish(ev2)
 	go disconnectReg.runOutputLoop(context.Background())
@@ -178,7 +179,7 @@ func TestRegistrationBasic(t *testing.T) {
 	require.Equal(t, discErr, err)
 
 	// Overflow.
-	overflowReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	overflowReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	for i := 0; i < cap(overflowReg.buf)+3; i++ {
 		overflowReg.publish(ev1)
 	}
@@ -188,7 +189,7 @@ func TestRegistrationBasic(t *testing.T) {
 	require.Equal(t, cap(overflowReg.buf), len(overflowReg.Events()))
 
 	// Stream Error.
-	streamErrReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	streamErrReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	streamErr := fmt.Errorf("stream error")
 	streamErrReg.stream.SetSendErr(streamErr)
 	go streamErrReg.runOutputLoop(context.Background())
@@ -197,7 +198,7 @@ func TestRegistrationBasic(t *testing.T) {
 	require.Equal(t, streamErr.Error(), err.GoError().Error())
 
 	// Stream Context Canceled.
-	streamCancelReg := newTestRegistration(spAB, hlc.Timestamp{}, nil)
+	streamCancelReg := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
 	streamCancelReg.stream.Cancel()
 	go streamCancelReg.runOutputLoop(context.Background())
 	require.NoError(t, streamCancelReg.waitForCaughtUp())
@@ -239,7 +240,7 @@ func TestRegistrationCatchUpScan(t *testing.T) {
 	r := newTestRegistration(roachpb.Span{
 		Key:    roachpb.Key("d"),
 		EndKey: roachpb.Key("w"),
-	}, hlc.Timestamp{WallTime: 4}, iter)
+	}, hlc.Timestamp{WallTime: 4}, iter, false /* withDiff */)
 
 	require.Zero(t, r.metrics.RangeFeedCatchupScanNanos.Count())
 	require.NoError(t, r.runCatchupScan())
@@ -264,6 +265,50 @@ func TestRegistrationCatchUpScan(t *testing.T) {
 	require.Equal(t, expEvents, r.Events())
 }
 
+func TestRegistrationCatchUpScanWithDiff(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	// Every value after the starting timestamp is accompanied by the version of
+	// its key that precedes it, even if that version is before the starting
+	// timestamp.
+	txn1 := uuid.MakeV4()
+	iter := newTestIterator([]engine.MVCCKeyValue{
+		makeKV("a", "val1", 10),
+		makeIntent("d", txn1, "txnKey1", 21),
+		makeProvisionalKV("d", "txnKey1", 21),
+		makeKV("d", "val2", 20),
+		makeKV("d", "val3", 19),
+		makeKV("d", "val4", 3),
+		makeKV("e", "val5", 8),
+		makeKV("e", "val5b", 6),
+		makeKV("e", "val6", 2),
+		makeKV("f", "val7", 5),
+		makeInline("g", "val8"),
+	})
+	r := newTestRegistration(roachpb.Span{
+		Key:    roachpb.Key("d"),
+		EndKey: roachpb.Key("w"),
+	}, hlc.Timestamp{WallTime: 4}, iter, true /* withDiff */)
+	require.NoError(t, r.runCatchupScan())
+
+	valueWithPrev := func(key, val string, ts int64, prev string) *roachpb.RangeFeedEvent {
+		return makeRangeFeedEvent(&roachpb.RangeFeedValue{
+			Key:       roachpb.Key(key),
+			Value:     roachpb.Value{RawBytes: []byte(val), Timestamp: hlc.Timestamp{WallTime: ts}},
+			PrevValue: roachpb.Value{RawBytes: []byte(prev)},
+		})
+	}
+	expEvents := []*roachpb.RangeFeedEvent{
+		valueWithPrev("d", "val3", 19, "val4"),
+		valueWithPrev("d", "val2", 20, "val3"),
+		valueWithPrev("e", "val5b", 6, "val6"),
+		valueWithPrev("e", "val5", 8, "val5b"),
+		rangeFeedValue(roachpb.Key("f"), roachpb.Value{RawBytes: []byte("val7"), Timestamp: hlc.Timestamp{WallTime: 5}}),
+		rangeFeedValue(roachpb.Key("g"), roachpb.Value{RawBytes: []byte("val8")}),
+	}
+	require.Equal(t, expEvents, r.Events())
+}
+
 func TestRegistryBasic(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 
@@ -282,10 +327,10 @@ func TestRegistryBasic(t *testing.T) {
 	require.NotPanics(t, func() { reg.Disconnect(spAB) })
 	require.NotPanics(t, func() { reg.DisconnectWithErr(spAB, err1) })
 
-	rAB := newTestRegistration(spAB, hlc.Timestamp{}, nil)
-	rBC := newTestRegistration(spBC, hlc.Timestamp{}, nil)
-	rCD := newTestRegistration(spCD, hlc.Timestamp{}, nil)
-	rAC := newTestRegistration(spAC, hlc.Timestamp{}, nil)
+	rAB := newTestRegistration(spAB, hlc.Timestamp{}, nil, false /* withDiff */)
+	rBC := newTestRegistration(spBC, hlc.Timestamp{}, nil, false /* withDiff */)
+	rCD := newTestRegistration(spCD, hlc.Timestamp{}, nil, false /* withDiff */)
+	rAC := newTestRegistration(spAC, hlc.Timestamp{}, nil, false /* withDiff */)
 	go rAB.runOutputLoop(context.Background())
 	go rBC.runOutputLoop(context.Background())
 	go rCD.runOutputLoop(context.Background())
@@ -344,11 +389,51 @@ func TestRegistryBasic(t *testing.T) {
 	require.Equal(t, 0, reg.Len())
 }
 
+// TestRegistryNeedsPrevValues verifies that the registry tracks whether any
+// of its registrations want the previous values of keys.
+func TestRegistryNeedsPrevValues(t *testing.T) {
+	defer leaktest.AfterTest(t)()
+
+	reg := makeRegistry()
+	require.False(t, reg.NeedsPrevValues())
+
+	rAB := newTestRegistration(spAB, hlc.Timestamp{}, nil, true /* withDiff */)
+	rBC := newTestRegistration(spBC, hlc.Timestamp{}, nil, false /* withDiff */)
+	rCD := newTestRegistration(spCD, hlc.Timestamp{}, nil, true /* withDiff */)
+	go rAB.runOutputLoop(context.Background())
+	go rBC.runOutputLoop(context.Background())
+	go rCD.runOutputLoop(context.Background())
+	defer rAB.disconnect(nil)
+	defer rBC.disconnect(nil)
+	defer rCD.disconnect(nil)
+
+	reg.Register(&rBC.registration)
+	require.False(t, reg.NeedsPrevValues())
+	reg.Register(&rAB.registration)
+	require.True(t, reg.NeedsPrevValues())
+	reg.Register(&rCD.registration)
+	require.True(t, reg.NeedsPrevValues())
+
+	// Disconnecting a registration that wants diffs leaves another.
+	reg.Disconnect(spAB)
+	require.True(t, reg.NeedsPrevValues())
+	// Unregistering a registration that was already disconnected doesn't
+	// count it twice.
+	reg.Unregister(&rAB.registration)
+	require.True(t, reg.NeedsPrevValues())
+
+	reg.Unregister(&rCD.registration)
+	require.False(t, reg.NeedsPrevValues())
+	reg.Unregister(&rBC.registration)
+	require.False(t, reg.NeedsPrevValues())
+	require.Equal(t, 0, reg.Len())
+}
+
 func TestRegistryPublishBeneathStartTimestamp(t *testing.T) {
 	defer leaktest.AfterTest(t)()
 	reg := makeRegistry()
 
-	r := newTestRegistration(spAB, hlc.Timestamp{WallTime: 10}, nil)
+	r := newTestRegistration(spAB, hlc.Timestamp{WallTime: 10}, nil, false /* withDiff */)
 	go r.runOutputLoop(context.Background())
 	reg.Register(&r.registration)
 
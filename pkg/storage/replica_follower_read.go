@@ -80,6 +80,12 @@ func (r *Replica) canServeFollowerRead(
 	return nil
 }
 
+// GetClosedTimestamp returns the maximum closed timestamp for this range. See
+// maxClosed.
+func (r *Replica) GetClosedTimestamp(ctx context.Context) hlc.Timestamp {
+	return r.maxClosed(ctx)
+}
+
 // maxClosed returns the maximum closed timestamp for this range.
 // It is computed as the most recent of the known closed timestamp for the
 // current lease holder for this range as tracked by the closed timestamp
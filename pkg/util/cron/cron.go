@@ -0,0 +1,229 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+// Package cron parses cron expressions and computes the times that match them.
+//
+// An expression has five fields separated by spaces: minute (0-59), hour
+// (0-23), day of month (1-31), month (1-12 or JAN-DEC) and day of week (0-7 or
+// SUN-SAT, where both 0 and 7 are Sunday). Every field is a comma-separated
+// list of values, ranges (1-5) and steps (*/15, 10-50/20 or 5/10), or * for
+// every value; ? is the same as * in the day fields. If both day fields are
+// restricted, a time matches if either of them matches, as in Vixie cron.
+//
+// An expression can also be one of the shorthands @yearly (or @annually),
+// @monthly, @weekly, @daily (or @midnight) and @hourly.
+package cron
+
+import (
+	"math/bits"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cockroachdb/errors"
+)
+
+// maxSearchYears bounds how far in the future Next looks for a matching time.
+// Every valid combination of day of month and month matches at least once in
+// any 8 consecutive years, the longest gap between leap days.
+const maxSearchYears = 8
+
+var shorthands = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+var monthNames = map[string]int{
+	"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+	"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+}
+
+var dayNames = map[string]int{
+	"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+}
+
+// field describes the values of a field of an expression.
+type field struct {
+	name     string
+	min, max int
+	names    map[string]int
+	// question is set for the fields in which ? is the same as *.
+	question bool
+}
+
+var fields = [...]field{
+	{name: "minute", min: 0, max: 59},
+	{name: "hour", min: 0, max: 23},
+	{name: "day of month", min: 1, max: 31, question: true},
+	{name: "month", min: 1, max: 12, names: monthNames},
+	{name: "day of week", min: 0, max: 7, names: dayNames, question: true},
+}
+
+// Expr is a parsed cron expression.
+type Expr struct {
+	// minutes, hours, doms, months and dows are bit sets of the values of the
+	// fields of the expression.
+	minutes, hours, doms, months, dows uint64
+	// domStar and dowStar are set if the day of month or the day of week is *.
+	domStar, dowStar bool
+}
+
+// Parse parses a cron expression.
+func Parse(s string) (*Expr, error) {
+	expr := strings.TrimSpace(s)
+	if strings.HasPrefix(expr, "@") {
+		var ok bool
+		if expr, ok = shorthands[strings.ToLower(expr)]; !ok {
+			return nil, errors.Errorf("unknown cron shorthand %q", s)
+		}
+	}
+	parts := strings.Fields(expr)
+	if len(parts) != len(fields) {
+		return nil, errors.Errorf(
+			"cron expression %q must have %d fields, found %d", s, len(fields), len(parts))
+	}
+	var sets [len(fields)]uint64
+	for i, part := range parts {
+		var err error
+		if sets[i], err = parseField(part, &fields[i]); err != nil {
+			return nil, errors.Wrapf(err, "cron expression %q", s)
+		}
+	}
+	e := &Expr{
+		minutes: sets[0],
+		hours:   sets[1],
+		doms:    sets[2],
+		months:  sets[3],
+		dows:    sets[4],
+		domStar: isStar(parts[2]),
+		dowStar: isStar(parts[4]),
+	}
+	// Sunday is both 0 and 7.
+	if e.dows&(1<<7) != 0 {
+		e.dows |= 1
+	}
+	return e, nil
+}
+
+// isStar returns whether a day field is unrestricted, which, as in Vixie cron,
+// is the case if it starts with *, even with a step.
+func isStar(s string) bool {
+	return strings.HasPrefix(s, "*") || s == "?"
+}
+
+// parseField parses a field of an expression into the bit set of its values.
+func parseField(s string, f *field) (uint64, error) {
+	var set uint64
+	for _, item := range strings.Split(s, ",") {
+		rangePart, step := item, 1
+		if i := strings.IndexByte(item, '/'); i >= 0 {
+			rangePart = item[:i]
+			var err error
+			if step, err = strconv.Atoi(item[i+1:]); err != nil || step <= 0 {
+				return 0, errors.Errorf("invalid step %q in %s field", item[i+1:], f.name)
+			}
+		}
+		lo, hi := f.min, f.max
+		switch {
+		case rangePart == "*" || (rangePart == "?" && f.question):
+		case strings.IndexByte(rangePart, '-') > 0:
+			i := strings.IndexByte(rangePart, '-')
+			var err error
+			if lo, err = parseValue(rangePart[:i], f); err != nil {
+				return 0, err
+			}
+			if hi, err = parseValue(rangePart[i+1:], f); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, errors.Errorf("invalid range %q in %s field", rangePart, f.name)
+			}
+		default:
+			var err error
+			if lo, err = parseValue(rangePart, f); err != nil {
+				return 0, err
+			}
+			// A single value with a step, like 5/10, is a range up to the maximum.
+			if step == 1 {
+				hi = lo
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			set |= 1 << uint(v)
+		}
+	}
+	return set, nil
+}
+
+func parseValue(s string, f *field) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < f.min || v > f.max {
+		return 0, errors.Errorf("invalid value %q in %s field", s, f.name)
+	}
+	return v, nil
+}
+
+func has(set uint64, v int) bool {
+	return set&(1<<uint(v)) != 0
+}
+
+// dayMatches returns whether the day of t matches the day of month and day of
+// week fields.
+func (e *Expr) dayMatches(t time.Time) bool {
+	dom, dow := has(e.doms, t.Day()), has(e.dows, int(t.Weekday()))
+	switch {
+	case e.domStar && e.dowStar:
+		return true
+	case e.domStar:
+		return dow
+	case e.dowStar:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// Next returns the first time strictly after the given time that matches the
+// expression, in the location of the given time. It returns the zero time if
+// no time matches, like for February 30th.
+func (e *Expr) Next(after time.Time) time.Time {
+	loc := after.Location()
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	end := t.AddDate(maxSearchYears, 0, 0)
+	for t.Before(end) {
+		y, m, d := t.Date()
+		switch {
+		case !has(e.months, int(m)):
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !e.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case !has(e.hours, t.Hour()):
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case !has(e.minutes, t.Minute()):
+			// Skip to the next matching minute of the hour, if any.
+			if next := e.minutes >> uint(t.Minute()); next != 0 {
+				t = t.Add(time.Duration(bits.TrailingZeros64(next)) * time.Minute)
+			} else {
+				t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+			}
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
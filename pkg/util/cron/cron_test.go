@@ -0,0 +1,150 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package cron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+func TestNext(t *testing.T) {
+	// 2019-06-01 is a Saturday.
+	after := time.Date(2019, 6, 1, 10, 34, 56, 0, time.UTC)
+	for _, tc := range []struct {
+		expr     string
+		expected time.Time
+	}{
+		{"@hourly", time.Date(2019, 6, 1, 11, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"@midnight", time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"@weekly", time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"@yearly", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"@ANNUALLY", time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"* * * * *", time.Date(2019, 6, 1, 10, 35, 0, 0, time.UTC)},
+		{"*/10 * * * *", time.Date(2019, 6, 1, 10, 40, 0, 0, time.UTC)},
+		{"5/20 * * * *", time.Date(2019, 6, 1, 10, 45, 0, 0, time.UTC)},
+		{"10-30/10 * * * *", time.Date(2019, 6, 1, 11, 10, 0, 0, time.UTC)},
+		{"0,34,35 * * * *", time.Date(2019, 6, 1, 10, 35, 0, 0, time.UTC)},
+		{"34 10 * * *", time.Date(2019, 6, 2, 10, 34, 0, 0, time.UTC)},
+		{"0 9-17 * * MON-FRI", time.Date(2019, 6, 3, 9, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)},
+		{"0 0 ? * sat", time.Date(2019, 6, 8, 0, 0, 0, 0, time.UTC)},
+		{"0 0 31 * *", time.Date(2019, 7, 31, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 jan,jul *", time.Date(2019, 7, 1, 0, 0, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2020, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// The day of month and the day of week match either one.
+		{"0 0 15 * MON", time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{"0 0 2 * MON", time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC)},
+		// Never matches.
+		{"0 0 30 2 *", time.Time{}},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := e.Next(after); !next.Equal(tc.expected) {
+				t.Fatalf("expected %s, got %s", tc.expected, next)
+			}
+		})
+	}
+}
+
+// TestNextEdgeCases covers the interaction of the day of month and day of
+// week fields, steps over ranges and leap days.
+func TestNextEdgeCases(t *testing.T) {
+	date := func(y int, m time.Month, d, h, min int) time.Time {
+		return time.Date(y, m, d, h, min, 0, 0, time.UTC)
+	}
+	// 2019-06-01 is a Saturday.
+	sat := date(2019, 6, 1, 10, 34)
+	for _, tc := range []struct {
+		expr     string
+		after    time.Time
+		expected time.Time
+	}{
+		// When both the day of month and the day of week are restricted, a day
+		// matches if either does.
+		{"0 0 1,15 * FRI", sat, date(2019, 6, 7, 0, 0)},
+		{"0 0 15 * FRI", date(2019, 6, 8, 0, 0), date(2019, 6, 14, 0, 0)},
+		{"0 0 13 * FRI", date(2019, 6, 8, 0, 0), date(2019, 6, 13, 0, 0)},
+		{"0 0 1 * SUN", date(2019, 6, 30, 1, 0), date(2019, 7, 1, 0, 0)},
+		// When only one of them is restricted, only that one is checked.
+		{"0 0 1 * *", sat, date(2019, 7, 1, 0, 0)},
+		{"0 0 * * FRI", sat, date(2019, 6, 7, 0, 0)},
+		{"0 0 ? * FRI", sat, date(2019, 6, 7, 0, 0)},
+		{"0 0 1 * ?", sat, date(2019, 7, 1, 0, 0)},
+		// A field that starts with * is unrestricted even with a step.
+		{"0 0 */10 * MON", sat, date(2019, 6, 3, 0, 0)},
+		{"0 0 2 * */3", sat, date(2019, 6, 2, 0, 0)},
+
+		// Steps over ranges, in every field.
+		{"15-59/20 * * * *", sat, date(2019, 6, 1, 10, 35)},
+		{"0-20/20,50 * * * *", sat, date(2019, 6, 1, 10, 50)},
+		{"0 0-23/6 * * *", sat, date(2019, 6, 1, 12, 0)},
+		{"0 12-14/5 * * *", sat, date(2019, 6, 1, 12, 0)},
+		{"0 0 1-31/10 * *", sat, date(2019, 6, 11, 0, 0)},
+		{"0 0 * 2-12/3 *", sat, date(2019, 8, 1, 0, 0)},
+		{"0 0 * JAN-DEC/6 *", sat, date(2019, 7, 1, 0, 0)},
+		{"0 0 * * MON-FRI/2", sat, date(2019, 6, 3, 0, 0)},
+		{"0 0 * * 2-7/5", sat, date(2019, 6, 2, 0, 0)},
+
+		// Leap days.
+		{"0 0 29 2 *", date(2019, 3, 1, 0, 0), date(2020, 2, 29, 0, 0)},
+		{"0 0 29 2 *", date(2020, 2, 29, 0, 0), date(2024, 2, 29, 0, 0)},
+		{"0 0 29 * *", date(2019, 1, 30, 0, 0), date(2019, 3, 29, 0, 0)},
+		{"0 0 29 * *", date(2020, 1, 30, 0, 0), date(2020, 2, 29, 0, 0)},
+		{"0 0 29 2 MON", sat, date(2020, 2, 3, 0, 0)},
+		// 2100 is not a leap year.
+		{"0 0 29 2 *", date(2096, 2, 29, 0, 0), date(2104, 2, 29, 0, 0)},
+		{"30 23 * * *", date(2020, 2, 28, 23, 30), date(2020, 2, 29, 23, 30)},
+		{"30 23 * * *", date(2019, 2, 28, 23, 30), date(2019, 3, 1, 23, 30)},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			e, err := Parse(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if next := e.Next(tc.after); !next.Equal(tc.expected) {
+				t.Fatalf("expected %s after %s, got %s", tc.expected, tc.after, next)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, tc := range []struct {
+		expr string
+		err  string
+	}{
+		{"", `must have 5 fields, found 0`},
+		{"* * * *", `must have 5 fields, found 4`},
+		{"@never", `unknown cron shorthand "@never"`},
+		{"60 * * * *", `invalid value "60" in minute field`},
+		{"* 24 * * *", `invalid value "24" in hour field`},
+		{"* * 0 * *", `invalid value "0" in day of month field`},
+		{"* * * 13 *", `invalid value "13" in month field`},
+		{"* * * * 8", `invalid value "8" in day of week field`},
+		{"* * * foo *", `invalid value "foo" in month field`},
+		{"? * * * *", `invalid value "\?" in minute field`},
+		{"*/0 * * * *", `invalid step "0" in minute field`},
+		{"30-10 * * * *", `invalid range "30-10" in minute field`},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			if _, err := Parse(tc.expr); !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encoding
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+)
+
+// The labels of a user-defined enum type are stored as byte strings, called
+// their physical representations, which are chosen so that comparing the byte
+// strings gives the same result as comparing the labels by their position in
+// the enum's declaration. Keys containing enum values are then encoded using
+// EncodeBytesAscending or EncodeBytesDescending, which preserve the ordering of
+// the byte strings, so indexes on enum columns sort in declaration order.
+//
+// New labels can be added between existing ones (ALTER TYPE ... ADD VALUE
+// ... BEFORE), without having to re-encode any existing values, by generating a
+// byte string that sorts between those of the neighboring labels. See
+// GenEnumPhysicalRepresentationBetween.
+
+// GenEnumPhysicalRepresentations returns n byte strings in increasing order,
+// spaced evenly over the space of byte strings of the smallest width that can
+// hold n distinct values. The even spacing keeps the strings generated by later
+// calls to GenEnumPhysicalRepresentationBetween short.
+func GenEnumPhysicalRepresentations(n int) [][]byte {
+	if n == 0 {
+		return nil
+	}
+	// Find the number of bytes needed to hold n distinct non-zero values.
+	width := 1
+	space := uint64(256)
+	for space <= uint64(n) {
+		width++
+		space *= 256
+	}
+	step := space / uint64(n+1)
+	res := make([][]byte, n)
+	for i := range res {
+		v := step * uint64(i+1)
+		b := make([]byte, width)
+		for j := width - 1; j >= 0; j-- {
+			b[j] = byte(v)
+			v >>= 8
+		}
+		res[i] = b
+	}
+	return res
+}
+
+// GenEnumPhysicalRepresentationBetween returns a byte string that sorts strictly
+// between prev and next. A nil prev stands for the smallest possible byte
+// string, and a nil next for a string larger than all others. The result never
+// ends with a zero byte, so that there is always room for another value before
+// it.
+func GenEnumPhysicalRepresentationBetween(prev, next []byte) ([]byte, error) {
+	if next != nil && bytes.Compare(prev, next) >= 0 {
+		return nil, errors.Errorf("cannot generate a value between %x and %x", prev, next)
+	}
+	var res []byte
+	for i := 0; ; i++ {
+		lo := 0
+		if i < len(prev) {
+			lo = int(prev[i])
+		}
+		hi := 256
+		if next != nil {
+			if i >= len(next) {
+				// next is prev followed by zero bytes, so nothing sorts
+				// between the two.
+				return nil, errors.Errorf("cannot generate a value between %x and %x", prev, next)
+			}
+			hi = int(next[i])
+		}
+		if hi-lo > 1 {
+			// There is room for a byte between lo and hi at this position, so
+			// the result is complete.
+			return append(res, byte((lo+hi)/2)), nil
+		}
+		res = append(res, byte(lo))
+		if hi-lo == 1 {
+			// The result is now smaller than next no matter which bytes follow,
+			// so next no longer constrains the remaining positions.
+			next = nil
+		}
+	}
+}
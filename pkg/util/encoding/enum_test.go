@@ -0,0 +1,67 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package encoding
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/util/randutil"
+)
+
+func TestGenEnumPhysicalRepresentations(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 10, 255, 256, 1000, 70000} {
+		reps := GenEnumPhysicalRepresentations(n)
+		if len(reps) != n {
+			t.Fatalf("expected %d representations, got %d", n, len(reps))
+		}
+		for i := 1; i < len(reps); i++ {
+			if bytes.Compare(reps[i-1], reps[i]) >= 0 {
+				t.Fatalf("n=%d: representations %x and %x are not increasing", n, reps[i-1], reps[i])
+			}
+		}
+	}
+}
+
+func TestGenEnumPhysicalRepresentationBetween(t *testing.T) {
+	rng, _ := randutil.NewPseudoRand()
+
+	// Repeatedly insert values at random positions, and verify that the
+	// representations stay sorted.
+	reps := GenEnumPhysicalRepresentations(3)
+	for i := 0; i < 1000; i++ {
+		pos := rng.Intn(len(reps) + 1)
+		var prev, next []byte
+		if pos > 0 {
+			prev = reps[pos-1]
+		}
+		if pos < len(reps) {
+			next = reps[pos]
+		}
+		b, err := GenEnumPhysicalRepresentationBetween(prev, next)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bytes.Compare(prev, b) >= 0 || (next != nil && bytes.Compare(b, next) >= 0) {
+			t.Fatalf("%x is not between %x and %x", b, prev, next)
+		}
+		reps = append(reps, nil)
+		copy(reps[pos+1:], reps[pos:])
+		reps[pos] = b
+	}
+
+	if _, err := GenEnumPhysicalRepresentationBetween([]byte{1}, []byte{1}); err == nil {
+		t.Fatal("expected error when prev is not smaller than next")
+	}
+	if _, err := GenEnumPhysicalRepresentationBetween([]byte{1}, []byte{1, 0}); err == nil {
+		t.Fatal("expected error when there is no value between prev and next")
+	}
+}
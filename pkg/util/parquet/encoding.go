@@ -0,0 +1,394 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"math"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+)
+
+// valueDecoder decodes the values of a page.
+type valueDecoder interface {
+	next() (interface{}, error)
+}
+
+// plainDecoder decodes PLAIN encoded values, which are stored back to back in
+// little-endian order. Booleans are bit-packed and byte arrays are prefixed by
+// their 4-byte length.
+type plainDecoder struct {
+	typ        Type
+	typeLength int32
+	data       []byte
+	// bit is the position of the next boolean in the first byte of data.
+	bit uint
+}
+
+var _ valueDecoder = &plainDecoder{}
+
+func (d *plainDecoder) take(n int) ([]byte, error) {
+	if n < 0 || n > len(d.data) {
+		return nil, errors.New("ran out of PLAIN encoded data")
+	}
+	b := d.data[:n:n]
+	d.data = d.data[n:]
+	return b, nil
+}
+
+func (d *plainDecoder) next() (interface{}, error) {
+	switch d.typ {
+	case Boolean:
+		if len(d.data) == 0 {
+			return nil, errors.New("ran out of PLAIN encoded data")
+		}
+		v := d.data[0]&(1<<d.bit) != 0
+		if d.bit++; d.bit == 8 {
+			d.data, d.bit = d.data[1:], 0
+		}
+		return v, nil
+	case Int32:
+		b, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		return int32(binary.LittleEndian.Uint32(b)), nil
+	case Int64:
+		b, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return int64(binary.LittleEndian.Uint64(b)), nil
+	case Int96:
+		return d.take(12)
+	case Float:
+		b, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float32frombits(binary.LittleEndian.Uint32(b)), nil
+	case Double:
+		b, err := d.take(8)
+		if err != nil {
+			return nil, err
+		}
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), nil
+	case ByteArray:
+		b, err := d.take(4)
+		if err != nil {
+			return nil, err
+		}
+		n := binary.LittleEndian.Uint32(b)
+		if n > uint32(len(d.data)) {
+			return nil, errors.New("ran out of PLAIN encoded data")
+		}
+		return d.take(int(n))
+	case FixedLenByteArray:
+		return d.take(int(d.typeLength))
+	default:
+		return nil, errors.Errorf("unknown physical type %d", d.typ)
+	}
+}
+
+// rleDecoder decodes the RLE/bit-packing hybrid encoding of definition levels,
+// dictionary indices and booleans. The data is a sequence of runs, each
+// starting with a varint header whose lowest bit is 0 for a run of repeated
+// values, followed by the value, and 1 for a run of bit-packed values, in
+// groups of 8.
+type rleDecoder struct {
+	data     []byte
+	bitWidth uint
+	// rleCount is the number of remaining repetitions of rleValue.
+	rleCount int
+	rleValue uint64
+	// packedCount is the number of remaining bit-packed values, which start at
+	// bit packedPos of packed.
+	packedCount int
+	packed      []byte
+	packedPos   uint
+}
+
+func (d *rleDecoder) next() (uint64, error) {
+	for d.rleCount == 0 && d.packedCount == 0 {
+		if err := d.readRun(); err != nil {
+			return 0, err
+		}
+	}
+	if d.rleCount > 0 {
+		d.rleCount--
+		return d.rleValue, nil
+	}
+	d.packedCount--
+	v := readBits(d.packed, d.packedPos, d.bitWidth)
+	d.packedPos += d.bitWidth
+	return v, nil
+}
+
+func (d *rleDecoder) readRun() error {
+	header, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		return errors.New("ran out of RLE encoded data")
+	}
+	d.data = d.data[n:]
+	count := header >> 1
+	if count > maxThriftLen {
+		return errors.Errorf("invalid RLE run length %d", count)
+	}
+	if header&1 == 0 {
+		width := int(d.bitWidth+7) / 8
+		if width > len(d.data) {
+			return errors.New("ran out of RLE encoded data")
+		}
+		d.rleValue = 0
+		for i := 0; i < width; i++ {
+			d.rleValue |= uint64(d.data[i]) << (8 * uint(i))
+		}
+		d.rleCount = int(count)
+		d.data = d.data[width:]
+		return nil
+	}
+	// The last group of bit-packed values may be truncated by some writers, in
+	// which case the values in it that are past the end are padding anyway.
+	size := int(count) * int(d.bitWidth)
+	if size > len(d.data) {
+		size = len(d.data)
+	}
+	d.packedCount = int(count) * 8
+	if d.bitWidth > 0 && size*8/int(d.bitWidth) < d.packedCount {
+		d.packedCount = size * 8 / int(d.bitWidth)
+	}
+	d.packed, d.packedPos = d.data[:size], 0
+	d.data = d.data[size:]
+	return nil
+}
+
+// readBits returns the width bits of b starting at bit pos, least significant
+// bit first.
+func readBits(b []byte, pos, width uint) uint64 {
+	var v uint64
+	for i := uint(0); i < width; {
+		shift := (pos + i) % 8
+		n := 8 - shift
+		if n > width-i {
+			n = width - i
+		}
+		v |= uint64((b[(pos+i)/8]>>shift)&(1<<n-1)) << i
+		i += n
+	}
+	return v
+}
+
+// rleBoolDecoder decodes RLE encoded booleans.
+type rleBoolDecoder struct {
+	rleDecoder
+}
+
+var _ valueDecoder = &rleBoolDecoder{}
+
+func (d *rleBoolDecoder) next() (interface{}, error) {
+	v, err := d.rleDecoder.next()
+	return v != 0, err
+}
+
+// dictDecoder decodes dictionary encoded values, which are RLE encoded
+// indices into the dictionary of the column chunk.
+type dictDecoder struct {
+	rleDecoder
+	dict []interface{}
+}
+
+var _ valueDecoder = &dictDecoder{}
+
+func (d *dictDecoder) next() (interface{}, error) {
+	i, err := d.rleDecoder.next()
+	if err != nil {
+		return nil, err
+	}
+	if i >= uint64(len(d.dict)) {
+		return nil, errors.Errorf("dictionary index %d out of range [0,%d)", i, len(d.dict))
+	}
+	return d.dict[i], nil
+}
+
+// appendRLE appends the given values, each of which fits in bitWidth bits, to
+// buf with the RLE/bit-packing hybrid encoding. Only runs of repeated values
+// are written, which is compact for definition levels.
+func appendRLE(buf []byte, values []uint64, bitWidth uint) []byte {
+	var scratch [binary.MaxVarintLen64]byte
+	width := int(bitWidth+7) / 8
+	for len(values) > 0 {
+		n := 1
+		for n < len(values) && values[n] == values[0] {
+			n++
+		}
+		buf = append(buf, scratch[:binary.PutUvarint(scratch[:], uint64(n)<<1)]...)
+		for i := 0; i < width; i++ {
+			buf = append(buf, byte(values[0]>>(8*uint(i))))
+		}
+		values = values[n:]
+	}
+	return buf
+}
+
+// plainEncoder encodes values with the PLAIN encoding.
+type plainEncoder struct {
+	buf bytes.Buffer
+	// bools are the booleans of the byte being encoded, which is written
+	// once it's full or when the encoder is flushed.
+	bools   byte
+	numBool uint
+	scratch [8]byte
+}
+
+func (e *plainEncoder) encode(typ Type, typeLength int32, v interface{}) error {
+	switch typ {
+	case Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			break
+		}
+		if b {
+			e.bools |= 1 << e.numBool
+		}
+		if e.numBool++; e.numBool == 8 {
+			e.buf.WriteByte(e.bools)
+			e.bools, e.numBool = 0, 0
+		}
+		return nil
+	case Int32:
+		i, ok := v.(int32)
+		if !ok {
+			break
+		}
+		binary.LittleEndian.PutUint32(e.scratch[:4], uint32(i))
+		e.buf.Write(e.scratch[:4])
+		return nil
+	case Int64:
+		i, ok := v.(int64)
+		if !ok {
+			break
+		}
+		binary.LittleEndian.PutUint64(e.scratch[:], uint64(i))
+		e.buf.Write(e.scratch[:])
+		return nil
+	case Float:
+		f, ok := v.(float32)
+		if !ok {
+			break
+		}
+		binary.LittleEndian.PutUint32(e.scratch[:4], math.Float32bits(f))
+		e.buf.Write(e.scratch[:4])
+		return nil
+	case Double:
+		f, ok := v.(float64)
+		if !ok {
+			break
+		}
+		binary.LittleEndian.PutUint64(e.scratch[:], math.Float64bits(f))
+		e.buf.Write(e.scratch[:])
+		return nil
+	case ByteArray:
+		b, ok := v.([]byte)
+		if !ok {
+			break
+		}
+		binary.LittleEndian.PutUint32(e.scratch[:4], uint32(len(b)))
+		e.buf.Write(e.scratch[:4])
+		e.buf.Write(b)
+		return nil
+	case Int96, FixedLenByteArray:
+		b, ok := v.([]byte)
+		if !ok {
+			break
+		}
+		n := typeLength
+		if typ == Int96 {
+			n = 12
+		}
+		if int32(len(b)) != n {
+			return errors.Errorf("expected %d bytes, got %d", n, len(b))
+		}
+		e.buf.Write(b)
+		return nil
+	default:
+		return errors.Errorf("unknown physical type %d", typ)
+	}
+	return errors.Errorf("unexpected value of type %T for physical type %s", v, typ)
+}
+
+// flush writes the pending booleans and returns the encoded values.
+func (e *plainEncoder) flush() []byte {
+	if e.numBool > 0 {
+		e.buf.WriteByte(e.bools)
+		e.bools, e.numBool = 0, 0
+	}
+	return e.buf.Bytes()
+}
+
+func (e *plainEncoder) reset() {
+	e.buf.Reset()
+	e.bools, e.numBool = 0, 0
+}
+
+// decompress decompresses the data of a page with the given codec into size
+// bytes.
+// maxCompressionRatio returns a bound of the ratio of the size of data to its
+// size once compressed with the given codec.
+func maxCompressionRatio(codec int32) int64 {
+	switch codec {
+	case codecSnappy:
+		// The densest snappy element copies 64 bytes in 3.
+		return 22
+	case codecGzip:
+		// DEFLATE encodes a match of at most 258 bytes in as few as 2 bits.
+		return 1032
+	default:
+		return 1
+	}
+}
+
+func decompress(codec int32, data []byte, size int32) ([]byte, error) {
+	var out []byte
+	switch codec {
+	case codecUncompressed:
+		out = data
+	case codecSnappy:
+		n, err := snappy.DecodedLen(data)
+		if err != nil {
+			return nil, err
+		}
+		if n != int(size) {
+			return nil, errors.Errorf("expected a page of %d bytes, got %d", size, n)
+		}
+		if out, err = snappy.Decode(make([]byte, n), data); err != nil {
+			return nil, err
+		}
+	case codecGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if out, err = ioutil.ReadAll(io.LimitReader(zr, int64(size)+1)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.Errorf("unsupported compression codec %d", codec)
+	}
+	if len(out) != int(size) {
+		return nil, errors.Errorf("expected a page of %d bytes, got %d", size, len(out))
+	}
+	return out, nil
+}
@@ -0,0 +1,639 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"fmt"
+
+	"github.com/cockroachdb/errors"
+)
+
+// This file contains the subset of the structs of parquet.thrift, the
+// definition of the metadata of Parquet files, that is needed to read and
+// write flat files. The field ids are those of parquet.thrift. Unknown fields
+// are skipped when decoding.
+
+// Type is the physical type of the values of a column.
+type Type int32
+
+// Physical types.
+const (
+	Boolean           Type = 0
+	Int32             Type = 1
+	Int64             Type = 2
+	Int96             Type = 3
+	Float             Type = 4
+	Double            Type = 5
+	ByteArray         Type = 6
+	FixedLenByteArray Type = 7
+)
+
+var typeNames = [...]string{
+	Boolean:           "BOOLEAN",
+	Int32:             "INT32",
+	Int64:             "INT64",
+	Int96:             "INT96",
+	Float:             "FLOAT",
+	Double:            "DOUBLE",
+	ByteArray:         "BYTE_ARRAY",
+	FixedLenByteArray: "FIXED_LEN_BYTE_ARRAY",
+}
+
+func (t Type) String() string {
+	if t < 0 || int(t) >= len(typeNames) {
+		return fmt.Sprintf("Type(%d)", t)
+	}
+	return typeNames[t]
+}
+
+// Repetition is whether the values of a column are required, optional or
+// repeated.
+type Repetition int32
+
+// Repetitions.
+const (
+	Required Repetition = 0
+	Optional Repetition = 1
+	Repeated Repetition = 2
+)
+
+// ConvertedType is the deprecated way of annotating the interpretation of the
+// values of a column, which is still written by most writers.
+type ConvertedType int32
+
+// Converted types.
+const (
+	// NoConvertedType is the converted type of columns without one.
+	NoConvertedType          ConvertedType = -1
+	ConvertedUTF8            ConvertedType = 0
+	ConvertedMap             ConvertedType = 1
+	ConvertedMapKeyValue     ConvertedType = 2
+	ConvertedList            ConvertedType = 3
+	ConvertedEnum            ConvertedType = 4
+	ConvertedDecimal         ConvertedType = 5
+	ConvertedDate            ConvertedType = 6
+	ConvertedTimeMillis      ConvertedType = 7
+	ConvertedTimeMicros      ConvertedType = 8
+	ConvertedTimestampMillis ConvertedType = 9
+	ConvertedTimestampMicros ConvertedType = 10
+	ConvertedUint8           ConvertedType = 11
+	ConvertedUint16          ConvertedType = 12
+	ConvertedUint32          ConvertedType = 13
+	ConvertedUint64          ConvertedType = 14
+	ConvertedInt8            ConvertedType = 15
+	ConvertedInt16           ConvertedType = 16
+	ConvertedInt32           ConvertedType = 17
+	ConvertedInt64           ConvertedType = 18
+	ConvertedJSON            ConvertedType = 19
+	ConvertedBSON            ConvertedType = 20
+	ConvertedInterval        ConvertedType = 21
+)
+
+// LogicalType is the interpretation of the values of a column. It supersedes
+// ConvertedType, but files usually have both.
+type LogicalType int
+
+// Logical types. Their field ids in the LogicalType union of parquet.thrift
+// are their values.
+const (
+	NoLogicalType    LogicalType = 0
+	LogicalString    LogicalType = 1
+	LogicalMap       LogicalType = 2
+	LogicalList      LogicalType = 3
+	LogicalEnum      LogicalType = 4
+	LogicalDecimal   LogicalType = 5
+	LogicalDate      LogicalType = 6
+	LogicalTime      LogicalType = 7
+	LogicalTimestamp LogicalType = 8
+	LogicalInteger   LogicalType = 10
+	LogicalNull      LogicalType = 11
+	LogicalJSON      LogicalType = 12
+	LogicalBSON      LogicalType = 13
+	LogicalUUID      LogicalType = 14
+)
+
+// TimeUnit is the unit of the values of LogicalTime and LogicalTimestamp
+// columns. Its values are the field ids in the TimeUnit union.
+type TimeUnit int
+
+// Time units.
+const (
+	Millis TimeUnit = 1
+	Micros TimeUnit = 2
+	Nanos  TimeUnit = 3
+)
+
+// Encodings.
+const (
+	encodingPlain           = 0
+	encodingPlainDictionary = 2
+	encodingRLE             = 3
+	encodingRLEDictionary   = 8
+)
+
+// Compression codecs.
+const (
+	codecUncompressed = 0
+	codecSnappy       = 1
+	codecGzip         = 2
+)
+
+// Page types.
+const (
+	pageData       = 0
+	pageDictionary = 2
+	pageDataV2     = 3
+)
+
+type fileMetaData struct {
+	version   int32
+	schema    []schemaElement
+	numRows   int64
+	rowGroups []rowGroup
+	createdBy string
+}
+
+type schemaElement struct {
+	typ           Type
+	hasType       bool
+	typeLength    int32
+	repetition    Repetition
+	name          string
+	numChildren   int32
+	convertedType ConvertedType
+	scale         int32
+	precision     int32
+	logicalType   LogicalType
+	// timeUnit is set for logical time and timestamp types.
+	timeUnit TimeUnit
+}
+
+type rowGroup struct {
+	columns       []columnChunk
+	totalByteSize int64
+	numRows       int64
+}
+
+type columnChunk struct {
+	filePath   string
+	fileOffset int64
+	meta       columnMetaData
+}
+
+type columnMetaData struct {
+	typ                   Type
+	encodings             []int32
+	pathInSchema          []string
+	codec                 int32
+	numValues             int64
+	totalUncompressedSize int64
+	totalCompressedSize   int64
+	dataPageOffset        int64
+	// dictionaryPageOffset is zero if the column chunk has no dictionary page.
+	dictionaryPageOffset int64
+}
+
+type pageHeader struct {
+	typ              int32
+	uncompressedSize int32
+	compressedSize   int32
+	dataPage         *dataPageHeader
+	dictionaryPage   *dictionaryPageHeader
+	dataPageV2       *dataPageHeaderV2
+}
+
+type dataPageHeader struct {
+	numValues   int32
+	encoding    int32
+	defEncoding int32
+	repEncoding int32
+}
+
+type dictionaryPageHeader struct {
+	numValues int32
+	encoding  int32
+}
+
+type dataPageHeaderV2 struct {
+	numValues    int32
+	numNulls     int32
+	numRows      int32
+	encoding     int32
+	defLength    int32
+	repLength    int32
+	isCompressed bool
+}
+
+// readStructList calls fn to read every element of a list of structs.
+func (r *thriftReader) readStructList(fn func() error) error {
+	typ, n, err := r.readListHeader()
+	if err != nil {
+		return err
+	}
+	if typ != thriftStruct && n > 0 {
+		return errors.Errorf("expected a list of structs, got elements of type %d", typ)
+	}
+	for i := 0; i < n; i++ {
+		if err := fn(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *thriftReader) readFileMetaData(m *fileMetaData) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			m.version, err = r.readI32()
+		case id == 2 && typ == thriftList:
+			err = r.readStructList(func() error {
+				m.schema = append(m.schema, schemaElement{convertedType: NoConvertedType})
+				return r.readSchemaElement(&m.schema[len(m.schema)-1])
+			})
+		case id == 3 && typ == thriftI64:
+			m.numRows, err = r.readI64()
+		case id == 4 && typ == thriftList:
+			err = r.readStructList(func() error {
+				m.rowGroups = append(m.rowGroups, rowGroup{})
+				return r.readRowGroup(&m.rowGroups[len(m.rowGroups)-1])
+			})
+		case id == 6 && typ == thriftBinary:
+			m.createdBy, err = r.readString()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readSchemaElement(e *schemaElement) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		var v int32
+		switch {
+		case id == 1 && typ == thriftI32:
+			v, err = r.readI32()
+			e.typ, e.hasType = Type(v), true
+		case id == 2 && typ == thriftI32:
+			e.typeLength, err = r.readI32()
+		case id == 3 && typ == thriftI32:
+			v, err = r.readI32()
+			e.repetition = Repetition(v)
+		case id == 4 && typ == thriftBinary:
+			e.name, err = r.readString()
+		case id == 5 && typ == thriftI32:
+			e.numChildren, err = r.readI32()
+		case id == 6 && typ == thriftI32:
+			v, err = r.readI32()
+			e.convertedType = ConvertedType(v)
+		case id == 7 && typ == thriftI32:
+			e.scale, err = r.readI32()
+		case id == 8 && typ == thriftI32:
+			e.precision, err = r.readI32()
+		case id == 10 && typ == thriftStruct:
+			err = r.readLogicalType(e)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+// readLogicalType reads the LogicalType union into the schema element. Unknown
+// logical types are ignored, like unknown fields.
+func (r *thriftReader) readLogicalType(e *schemaElement) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		if typ != thriftStruct {
+			return r.skip(typ)
+		}
+		switch lt := LogicalType(id); lt {
+		case LogicalDecimal:
+			e.logicalType = lt
+			return r.readStruct(func(id int16, typ byte) error {
+				var err error
+				switch {
+				case id == 1 && typ == thriftI32:
+					e.scale, err = r.readI32()
+				case id == 2 && typ == thriftI32:
+					e.precision, err = r.readI32()
+				default:
+					err = r.skip(typ)
+				}
+				return err
+			})
+		case LogicalTime, LogicalTimestamp:
+			e.logicalType = lt
+			return r.readStruct(func(id int16, typ byte) error {
+				if id == 2 && typ == thriftStruct {
+					return r.readStruct(func(id int16, typ byte) error {
+						e.timeUnit = TimeUnit(id)
+						return r.skip(typ)
+					})
+				}
+				return r.skip(typ)
+			})
+		case LogicalString, LogicalMap, LogicalList, LogicalEnum, LogicalDate,
+			LogicalInteger, LogicalNull, LogicalJSON, LogicalBSON, LogicalUUID:
+			e.logicalType = lt
+		}
+		return r.skip(typ)
+	})
+}
+
+func (r *thriftReader) readRowGroup(g *rowGroup) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftList:
+			err = r.readStructList(func() error {
+				g.columns = append(g.columns, columnChunk{})
+				return r.readColumnChunk(&g.columns[len(g.columns)-1])
+			})
+		case id == 2 && typ == thriftI64:
+			g.totalByteSize, err = r.readI64()
+		case id == 3 && typ == thriftI64:
+			g.numRows, err = r.readI64()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readColumnChunk(c *columnChunk) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftBinary:
+			c.filePath, err = r.readString()
+		case id == 2 && typ == thriftI64:
+			c.fileOffset, err = r.readI64()
+		case id == 3 && typ == thriftStruct:
+			err = r.readColumnMetaData(&c.meta)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readColumnMetaData(m *columnMetaData) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		var v int32
+		switch {
+		case id == 1 && typ == thriftI32:
+			v, err = r.readI32()
+			m.typ = Type(v)
+		case id == 2 && typ == thriftList:
+			var elemTyp byte
+			var n int
+			if elemTyp, n, err = r.readListHeader(); err != nil {
+				return err
+			}
+			for i := 0; i < n; i++ {
+				if elemTyp != thriftI32 {
+					if err := r.skipElem(elemTyp); err != nil {
+						return err
+					}
+					continue
+				}
+				if v, err = r.readI32(); err != nil {
+					return err
+				}
+				m.encodings = append(m.encodings, v)
+			}
+		case id == 3 && typ == thriftList:
+			var elemTyp byte
+			var n int
+			if elemTyp, n, err = r.readListHeader(); err != nil {
+				return err
+			}
+			for i := 0; i < n; i++ {
+				if elemTyp != thriftBinary {
+					return errors.Errorf("expected a list of strings, got elements of type %d", elemTyp)
+				}
+				s, err := r.readString()
+				if err != nil {
+					return err
+				}
+				m.pathInSchema = append(m.pathInSchema, s)
+			}
+		case id == 4 && typ == thriftI32:
+			m.codec, err = r.readI32()
+		case id == 5 && typ == thriftI64:
+			m.numValues, err = r.readI64()
+		case id == 6 && typ == thriftI64:
+			m.totalUncompressedSize, err = r.readI64()
+		case id == 7 && typ == thriftI64:
+			m.totalCompressedSize, err = r.readI64()
+		case id == 9 && typ == thriftI64:
+			m.dataPageOffset, err = r.readI64()
+		case id == 11 && typ == thriftI64:
+			m.dictionaryPageOffset, err = r.readI64()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readPageHeader(h *pageHeader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			h.typ, err = r.readI32()
+		case id == 2 && typ == thriftI32:
+			h.uncompressedSize, err = r.readI32()
+		case id == 3 && typ == thriftI32:
+			h.compressedSize, err = r.readI32()
+		case id == 5 && typ == thriftStruct:
+			h.dataPage = &dataPageHeader{}
+			err = r.readDataPageHeader(h.dataPage)
+		case id == 7 && typ == thriftStruct:
+			h.dictionaryPage = &dictionaryPageHeader{}
+			err = r.readDictionaryPageHeader(h.dictionaryPage)
+		case id == 8 && typ == thriftStruct:
+			h.dataPageV2 = &dataPageHeaderV2{isCompressed: true}
+			err = r.readDataPageHeaderV2(h.dataPageV2)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readDataPageHeader(h *dataPageHeader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			h.numValues, err = r.readI32()
+		case id == 2 && typ == thriftI32:
+			h.encoding, err = r.readI32()
+		case id == 3 && typ == thriftI32:
+			h.defEncoding, err = r.readI32()
+		case id == 4 && typ == thriftI32:
+			h.repEncoding, err = r.readI32()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readDictionaryPageHeader(h *dictionaryPageHeader) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			h.numValues, err = r.readI32()
+		case id == 2 && typ == thriftI32:
+			h.encoding, err = r.readI32()
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (r *thriftReader) readDataPageHeaderV2(h *dataPageHeaderV2) error {
+	return r.readStruct(func(id int16, typ byte) error {
+		var err error
+		switch {
+		case id == 1 && typ == thriftI32:
+			h.numValues, err = r.readI32()
+		case id == 2 && typ == thriftI32:
+			h.numNulls, err = r.readI32()
+		case id == 3 && typ == thriftI32:
+			h.numRows, err = r.readI32()
+		case id == 4 && typ == thriftI32:
+			h.encoding, err = r.readI32()
+		case id == 5 && typ == thriftI32:
+			h.defLength, err = r.readI32()
+		case id == 6 && typ == thriftI32:
+			h.repLength, err = r.readI32()
+		case id == 7 && (typ == thriftTrue || typ == thriftFalse):
+			h.isCompressed = readBool(typ)
+		default:
+			err = r.skip(typ)
+		}
+		return err
+	})
+}
+
+func (w *thriftWriter) writeFileMetaData(m *fileMetaData) {
+	w.structBegin()
+	w.writeI32Field(1, m.version)
+	w.fieldBegin(2, thriftList)
+	w.writeListHeader(thriftStruct, len(m.schema))
+	for i := range m.schema {
+		w.writeSchemaElement(&m.schema[i])
+	}
+	w.writeI64Field(3, m.numRows)
+	w.fieldBegin(4, thriftList)
+	w.writeListHeader(thriftStruct, len(m.rowGroups))
+	for i := range m.rowGroups {
+		w.writeRowGroup(&m.rowGroups[i])
+	}
+	w.writeStringField(6, m.createdBy)
+	w.structEnd()
+}
+
+// writeSchemaElement writes a schema element. Only the parts of the schema
+// produced by Writer are supported: logical types are not written, since all
+// the types it writes have an equivalent converted type.
+func (w *thriftWriter) writeSchemaElement(e *schemaElement) {
+	w.structBegin()
+	if e.hasType {
+		w.writeI32Field(1, int32(e.typ))
+		if e.typ == FixedLenByteArray {
+			w.writeI32Field(2, e.typeLength)
+		}
+		w.writeI32Field(3, int32(e.repetition))
+	}
+	w.writeStringField(4, e.name)
+	if !e.hasType {
+		w.writeI32Field(5, e.numChildren)
+	}
+	if e.convertedType != NoConvertedType {
+		w.writeI32Field(6, int32(e.convertedType))
+		if e.convertedType == ConvertedDecimal {
+			w.writeI32Field(7, e.scale)
+			w.writeI32Field(8, e.precision)
+		}
+	}
+	w.structEnd()
+}
+
+func (w *thriftWriter) writeRowGroup(g *rowGroup) {
+	w.structBegin()
+	w.fieldBegin(1, thriftList)
+	w.writeListHeader(thriftStruct, len(g.columns))
+	for i := range g.columns {
+		w.writeColumnChunk(&g.columns[i])
+	}
+	w.writeI64Field(2, g.totalByteSize)
+	w.writeI64Field(3, g.numRows)
+	w.structEnd()
+}
+
+func (w *thriftWriter) writeColumnChunk(c *columnChunk) {
+	w.structBegin()
+	w.writeI64Field(2, c.fileOffset)
+	w.fieldBegin(3, thriftStruct)
+	w.writeColumnMetaData(&c.meta)
+	w.structEnd()
+}
+
+func (w *thriftWriter) writeColumnMetaData(m *columnMetaData) {
+	w.structBegin()
+	w.writeI32Field(1, int32(m.typ))
+	w.fieldBegin(2, thriftList)
+	w.writeListHeader(thriftI32, len(m.encodings))
+	for _, e := range m.encodings {
+		w.writeVarint(int64(e))
+	}
+	w.fieldBegin(3, thriftList)
+	w.writeListHeader(thriftBinary, len(m.pathInSchema))
+	for _, s := range m.pathInSchema {
+		w.writeBinary([]byte(s))
+	}
+	w.writeI32Field(4, m.codec)
+	w.writeI64Field(5, m.numValues)
+	w.writeI64Field(6, m.totalUncompressedSize)
+	w.writeI64Field(7, m.totalCompressedSize)
+	w.writeI64Field(9, m.dataPageOffset)
+	if m.dictionaryPageOffset > 0 {
+		w.writeI64Field(11, m.dictionaryPageOffset)
+	}
+	w.structEnd()
+}
+
+// writePageHeader writes the header of a data page, the only kind of page
+// produced by Writer.
+func (w *thriftWriter) writePageHeader(h *pageHeader) {
+	w.structBegin()
+	w.writeI32Field(1, h.typ)
+	w.writeI32Field(2, h.uncompressedSize)
+	w.writeI32Field(3, h.compressedSize)
+	w.fieldBegin(5, thriftStruct)
+	w.structBegin()
+	w.writeI32Field(1, h.dataPage.numValues)
+	w.writeI32Field(2, h.dataPage.encoding)
+	w.writeI32Field(3, h.dataPage.defEncoding)
+	w.writeI32Field(4, h.dataPage.repEncoding)
+	w.structEnd()
+	w.structEnd()
+}
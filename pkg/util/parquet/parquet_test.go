@@ -0,0 +1,521 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"testing"
+
+	"github.com/cockroachdb/cockroach/pkg/testutils"
+)
+
+// readAll reads every value of every column of a file, by row.
+func readAll(t *testing.T, data []byte) ([]Column, [][]interface{}) {
+	t.Helper()
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows [][]interface{}
+	for g := 0; g < r.NumRowGroups(); g++ {
+		crs := make([]*ColumnReader, len(r.Columns()))
+		for i := range crs {
+			if crs[i], err = r.ColumnReader(g, i); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for n := int64(0); n < r.RowGroupNumRows(g); n++ {
+			row := make([]interface{}, len(crs))
+			for i, cr := range crs {
+				if row[i], err = cr.Next(); err != nil {
+					t.Fatal(err)
+				}
+			}
+			rows = append(rows, row)
+		}
+		for _, cr := range crs {
+			if _, err := cr.Next(); err != io.EOF {
+				t.Fatalf("expected EOF after the last row, got %v", err)
+			}
+		}
+	}
+	if int64(len(rows)) != r.NumRows() {
+		t.Fatalf("expected %d rows, got %d", r.NumRows(), len(rows))
+	}
+	return r.Columns(), rows
+}
+
+func TestWriteRead(t *testing.T) {
+	cols := []Column{
+		{Path: []string{"b"}, Type: Boolean, Repetition: Optional, ConvertedType: NoConvertedType},
+		{Path: []string{"i32"}, Type: Int32, Repetition: Required, ConvertedType: ConvertedDate},
+		{Path: []string{"i64"}, Type: Int64, Repetition: Optional, ConvertedType: NoConvertedType},
+		{Path: []string{"f"}, Type: Float, Repetition: Optional, ConvertedType: NoConvertedType},
+		{Path: []string{"d"}, Type: Double, Repetition: Optional, ConvertedType: NoConvertedType},
+		{Path: []string{"s"}, Type: ByteArray, Repetition: Optional, ConvertedType: ConvertedUTF8},
+		{
+			Path: []string{"dec"}, Type: FixedLenByteArray, TypeLength: 2, Repetition: Optional,
+			ConvertedType: ConvertedDecimal, Scale: 2, Precision: 4,
+		},
+	}
+	var rows [][]interface{}
+	for i := 0; i < 20; i++ {
+		row := []interface{}{
+			i%3 == 0, int32(i), int64(-i), float32(i) / 2, float64(i) / 4,
+			bytes.Repeat([]byte("x"), i), []byte{byte(i), 0xff},
+		}
+		if i%4 == 1 {
+			for j := range row {
+				if cols[j].Repetition == Optional {
+					row[j] = nil
+				}
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, row := range rows {
+		if err := w.AddRow(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	readCols, readRows := readAll(t, buf.Bytes())
+	for i := range readCols {
+		readCols[i].maxDef, cols[i].maxDef = 0, 0
+	}
+	if !reflect.DeepEqual(cols, readCols) {
+		t.Errorf("expected columns\n%+v\ngot\n%+v", cols, readCols)
+	}
+	if !reflect.DeepEqual(rows, readRows) {
+		t.Errorf("expected rows\n%v\ngot\n%v", rows, readRows)
+	}
+
+	// Writers can't be used after an error, so every error uses a new one.
+	for _, tc := range []struct {
+		row []interface{}
+		err string
+	}{
+		{row: []interface{}{true}, err: `expected 7 values, got 1`},
+		{row: []interface{}{true, nil, nil, nil, nil, nil, nil}, err: `NULL value in a required column`},
+		{
+			row: []interface{}{true, "a", nil, nil, nil, nil, nil},
+			err: `unexpected value of type string for physical type INT32`,
+		},
+	} {
+		w, err := NewWriter(ioutil.Discard, cols)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddRow(tc.row); !testutils.IsError(err, tc.err) {
+			t.Errorf("expected error %q, got %v", tc.err, err)
+		}
+	}
+}
+
+func TestRLEDecoder(t *testing.T) {
+	data := []byte{
+		// A group of 8 bit-packed values 0 to 7, with a bit width of 3.
+		0x03, 0x88, 0xc6, 0xfa,
+		// 4 repetitions of 5.
+		0x08, 0x05,
+	}
+	d := rleDecoder{data: data, bitWidth: 3}
+	var values []uint64
+	for i := 0; i < 12; i++ {
+		v, err := d.next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, v)
+	}
+	if expected := []uint64{0, 1, 2, 3, 4, 5, 6, 7, 5, 5, 5, 5}; !reflect.DeepEqual(expected, values) {
+		t.Fatalf("expected %v, got %v", expected, values)
+	}
+	if _, err := d.next(); err == nil {
+		t.Fatal("expected an error after the last value")
+	}
+}
+
+// TestReadDictionaryPageV2 reads a file in a form that Writer doesn't write:
+// a gzip-compressed column chunk with a dictionary page and a V2 data page of
+// dictionary encoded values.
+func TestReadDictionaryPageV2(t *testing.T) {
+	compress := func(b []byte) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(b); err != nil {
+			t.Fatal(err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return buf.Bytes()
+	}
+
+	var file bytes.Buffer
+	file.WriteString(magic)
+	var tw thriftWriter
+
+	// The dictionary is "a", "bc".
+	var dict plainEncoder
+	for _, s := range []string{"a", "bc"} {
+		if err := dict.encode(ByteArray, 0, []byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dictPage := compress(dict.flush())
+	tw.structBegin()
+	tw.writeI32Field(1, pageDictionary)
+	tw.writeI32Field(2, int32(dict.buf.Len()))
+	tw.writeI32Field(3, int32(len(dictPage)))
+	tw.fieldBegin(7, thriftStruct)
+	tw.structBegin()
+	tw.writeI32Field(1, 2)
+	tw.writeI32Field(2, encodingPlainDictionary)
+	tw.structEnd()
+	tw.structEnd()
+	dictOffset := int64(file.Len())
+	file.Write(tw.buf.Bytes())
+	file.Write(dictPage)
+
+	// The rows are "bc", NULL, "a", "bc", "bc".
+	defs := appendRLE(nil, []uint64{1, 0, 1, 1, 1}, 1)
+	indices := []byte{1 /* bit width */, 0x03 /* 1 group */, 0x0d /* 1, 0, 1, 1 */}
+	values := compress(indices)
+	tw.buf.Reset()
+	tw.structBegin()
+	tw.writeI32Field(1, pageDataV2)
+	tw.writeI32Field(2, int32(len(defs)+len(indices)))
+	tw.writeI32Field(3, int32(len(defs)+len(values)))
+	tw.fieldBegin(8, thriftStruct)
+	tw.structBegin()
+	tw.writeI32Field(1, 5)
+	tw.writeI32Field(2, 1)
+	tw.writeI32Field(3, 5)
+	tw.writeI32Field(4, encodingRLEDictionary)
+	tw.writeI32Field(5, int32(len(defs)))
+	tw.writeI32Field(6, 0)
+	tw.structEnd()
+	tw.structEnd()
+	dataOffset := int64(file.Len())
+	file.Write(tw.buf.Bytes())
+	file.Write(defs)
+	file.Write(values)
+	chunkLen := int64(file.Len()) - dictOffset
+
+	tw.buf.Reset()
+	tw.writeFileMetaData(&fileMetaData{
+		version: 1,
+		schema: []schemaElement{
+			{name: "schema", numChildren: 1, convertedType: NoConvertedType},
+			{
+				name: "s", typ: ByteArray, hasType: true, repetition: Optional,
+				convertedType: ConvertedUTF8,
+			},
+		},
+		numRows: 5,
+		rowGroups: []rowGroup{{
+			numRows: 5,
+			columns: []columnChunk{{meta: columnMetaData{
+				typ:                  ByteArray,
+				pathInSchema:         []string{"s"},
+				codec:                codecGzip,
+				numValues:            5,
+				totalCompressedSize:  chunkLen,
+				dataPageOffset:       dataOffset,
+				dictionaryPageOffset: dictOffset,
+			}}},
+		}},
+	})
+	meta := tw.buf.Bytes()
+	var footer [footerLen]byte
+	binary.LittleEndian.PutUint32(footer[:4], uint32(len(meta)))
+	copy(footer[4:], magic)
+	file.Write(meta)
+	file.Write(footer[:])
+
+	r, err := NewReader(bytes.NewReader(file.Bytes()), int64(file.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cr, err := r.ColumnReader(0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var rows []interface{}
+	for {
+		v, err := cr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		rows = append(rows, v)
+	}
+	expected := []interface{}{[]byte("bc"), nil, []byte("a"), []byte("bc"), []byte("bc")}
+	if !reflect.DeepEqual(expected, rows) {
+		t.Fatalf("expected %q, got %q", expected, rows)
+	}
+}
+
+// readAllErr reads every value of every column of a file and returns the first
+// error, if any.
+func readAllErr(data []byte) error {
+	r, err := NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	for g := 0; g < r.NumRowGroups(); g++ {
+		for i := range r.Columns() {
+			cr, err := r.ColumnReader(g, i)
+			if err != nil {
+				return err
+			}
+			for {
+				if _, err := cr.Next(); err == io.EOF {
+					break
+				} else if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// writeTestFile writes a file with a single required INT32 column, stored in a
+// single row group with the given column chunk.
+func writeTestFile(codec int32, numValues int64, chunk []byte) []byte {
+	var file bytes.Buffer
+	file.WriteString(magic)
+	offset := int64(file.Len())
+	file.Write(chunk)
+
+	var tw thriftWriter
+	tw.writeFileMetaData(&fileMetaData{
+		version: 1,
+		schema: []schemaElement{
+			{name: "schema", numChildren: 1, convertedType: NoConvertedType},
+			{name: "i", typ: Int32, hasType: true, repetition: Required, convertedType: NoConvertedType},
+		},
+		numRows: numValues,
+		rowGroups: []rowGroup{{
+			numRows: numValues,
+			columns: []columnChunk{{meta: columnMetaData{
+				typ:                 Int32,
+				pathInSchema:        []string{"i"},
+				codec:               codec,
+				numValues:           numValues,
+				totalCompressedSize: int64(len(chunk)),
+				dataPageOffset:      offset,
+			}}},
+		}},
+	})
+	meta := tw.buf.Bytes()
+	var footer [footerLen]byte
+	binary.LittleEndian.PutUint32(footer[:4], uint32(len(meta)))
+	copy(footer[4:], magic)
+	file.Write(meta)
+	file.Write(footer[:])
+	return file.Bytes()
+}
+
+// TestReadInvalidPageSizes verifies that the sizes in page headers are checked
+// against the column chunk before anything is allocated for the page.
+func TestReadInvalidPageSizes(t *testing.T) {
+	values := []byte{1, 0, 0, 0, 2, 0, 0, 0}
+	page := func(codec int32, uncompressedSize, compressedSize int32) []byte {
+		var tw thriftWriter
+		tw.writePageHeader(&pageHeader{
+			typ:              pageData,
+			uncompressedSize: uncompressedSize,
+			compressedSize:   compressedSize,
+			dataPage:         &dataPageHeader{numValues: 2, encoding: encodingPlain},
+		})
+		return append(tw.buf.Bytes(), values...)
+	}
+	n := int32(len(values))
+	valid := page(codecUncompressed, n, n)
+
+	for _, tc := range []struct {
+		name  string
+		codec int32
+		chunk []byte
+		err   string
+	}{
+		{name: "valid", codec: codecUncompressed, chunk: valid},
+		{
+			name:  "compressed size past the chunk",
+			codec: codecUncompressed,
+			chunk: page(codecUncompressed, n, 1<<30),
+			err:   `page of 1073741824 bytes overruns the column chunk, which has 8 bytes left`,
+		},
+		{
+			name:  "compressed size past a truncated chunk",
+			codec: codecUncompressed,
+			chunk: valid[:len(valid)-4],
+			err:   `page of 8 bytes overruns the column chunk, which has 4 bytes left`,
+		},
+		{
+			name:  "uncompressed size of an uncompressed page",
+			codec: codecUncompressed,
+			chunk: page(codecUncompressed, 1<<30, n),
+			err:   `invalid uncompressed size 1073741824 of a page of 8 bytes`,
+		},
+		{
+			name:  "uncompressed size of a snappy page",
+			codec: codecSnappy,
+			chunk: page(codecSnappy, 1<<30, n),
+			err:   `invalid uncompressed size 1073741824 of a page of 8 bytes`,
+		},
+		{
+			name:  "negative size",
+			codec: codecUncompressed,
+			chunk: page(codecUncompressed, n, -1),
+			err:   `invalid page size`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := readAllErr(writeTestFile(tc.codec, 2, tc.chunk))
+			if tc.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+			} else if !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// TestReadTruncatedMetadata verifies that decoding every truncation of the
+// metadata of a file fails, and that corrupt lengths in the metadata are
+// checked against its size before anything is allocated for them.
+func TestReadTruncatedMetadata(t *testing.T) {
+	var tw thriftWriter
+	tw.writeFileMetaData(&fileMetaData{
+		version: 1,
+		schema: []schemaElement{
+			{name: "schema", numChildren: 1, convertedType: NoConvertedType},
+			{name: "s", typ: ByteArray, hasType: true, repetition: Optional, convertedType: ConvertedUTF8},
+		},
+		numRows: 1,
+		rowGroups: []rowGroup{{
+			numRows: 1,
+			columns: []columnChunk{{meta: columnMetaData{
+				typ:          ByteArray,
+				pathInSchema: []string{"s"},
+				codec:        codecSnappy,
+				numValues:    1,
+			}}},
+		}},
+	})
+	meta := tw.buf.Bytes()
+	for n := 0; n < len(meta); n++ {
+		var m fileMetaData
+		tr := thriftReader{r: bytes.NewReader(meta[:n]), size: int64(n)}
+		if err := tr.readFileMetaData(&m); err == nil {
+			t.Errorf("expected an error decoding %d of %d bytes", n, len(meta))
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		data []byte
+		read func(r *thriftReader) error
+		err  string
+	}{
+		{
+			name: "binary",
+			data: []byte{0x80, 0x80, 0x80, 0x02, 'a'},
+			read: func(r *thriftReader) error { _, err := r.readBinary(); return err },
+			err:  `invalid binary length 4194304`,
+		},
+		{
+			name: "list",
+			data: []byte{0xf5, 0x80, 0x80, 0x80, 0x02, 0x01},
+			read: func(r *thriftReader) error { _, _, err := r.readListHeader(); return err },
+			err:  `invalid list length 4194304`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			r := thriftReader{r: bytes.NewReader(tc.data), size: int64(len(tc.data))}
+			if err := tc.read(&r); !testutils.IsError(err, tc.err) {
+				t.Fatalf("expected error %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+// TestReadCorrupt reads every file obtained by overwriting a byte of a valid
+// file, which must fail or succeed but never panic.
+func TestReadCorrupt(t *testing.T) {
+	cols := []Column{
+		{Path: []string{"i"}, Type: Int64, Repetition: Optional, ConvertedType: NoConvertedType},
+		{Path: []string{"s"}, Type: ByteArray, Repetition: Required, ConvertedType: ConvertedUTF8},
+	}
+	var buf bytes.Buffer
+	w, err := NewWriter(&buf, cols)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 10; i++ {
+		var v interface{}
+		if i%3 != 0 {
+			v = int64(i)
+		}
+		if err := w.AddRow([]interface{}{v, bytes.Repeat([]byte("x"), i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if err := readAllErr(data); err != nil {
+		t.Fatal(err)
+	}
+
+	corrupt := make([]byte, len(data))
+	for i := range data {
+		for _, b := range []byte{0x00, 0x7f, 0xff, data[i] ^ 0x80} {
+			copy(corrupt, data)
+			corrupt[i] = b
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						t.Fatalf("reading the file with byte %d set to %#x panicked: %v", i, b, r)
+					}
+				}()
+				_ = readAllErr(corrupt)
+			}()
+		}
+	}
+	for n := 0; n < len(data); n++ {
+		if err := readAllErr(data[:n]); err == nil {
+			t.Errorf("expected an error reading %d of %d bytes", n, len(data))
+		}
+	}
+}
@@ -0,0 +1,424 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"math/bits"
+
+	"github.com/cockroachdb/errors"
+)
+
+// magic is at the start and at the end of every Parquet file.
+const magic = "PAR1"
+
+// footerLen is the length of the end of a Parquet file after its metadata:
+// the length of the metadata and the magic.
+const footerLen = 8
+
+// Column is a leaf column of the schema of a Parquet file, which holds values.
+type Column struct {
+	// Path is the names of the groups that contain the column, outermost
+	// first, followed by the name of the column.
+	Path       []string
+	Type       Type
+	TypeLength int32
+	Repetition Repetition
+	// ConvertedType and LogicalType are the interpretation of the values of
+	// the column, if any.
+	ConvertedType ConvertedType
+	LogicalType   LogicalType
+	// TimeUnit is the unit of LogicalTime and LogicalTimestamp columns.
+	TimeUnit TimeUnit
+	// Scale and Precision are those of decimal columns.
+	Scale     int32
+	Precision int32
+
+	// maxDef is the definition level of non-NULL values.
+	maxDef int16
+	// repeated is set if the column or any of the groups that contain it are
+	// repeated.
+	repeated bool
+}
+
+// Reader reads a Parquet file. The metadata of a Parquet file is at its end and
+// points at the data of every column, which is read on demand, so the file is
+// accessed with an io.ReaderAt.
+//
+// Only columns that are neither repeated nor contained in repeated groups can
+// be read.
+type Reader struct {
+	r    io.ReaderAt
+	meta fileMetaData
+	cols []Column
+}
+
+// NewReader reads the metadata of the Parquet file of the given size.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	if size < int64(len(magic)+footerLen) {
+		return nil, errors.Errorf("file of %d bytes is too small to be a Parquet file", size)
+	}
+	var head [len(magic)]byte
+	if _, err := r.ReadAt(head[:], 0); err != nil {
+		return nil, err
+	}
+	var foot [footerLen]byte
+	if _, err := r.ReadAt(foot[:], size-footerLen); err != nil {
+		return nil, err
+	}
+	if string(head[:]) != magic || string(foot[4:]) != magic {
+		return nil, errors.New("not a Parquet file or an encrypted one")
+	}
+	metaLen := int64(binary.LittleEndian.Uint32(foot[:4]))
+	if metaLen > size-int64(len(magic)+footerLen) {
+		return nil, errors.Errorf("invalid metadata length %d", metaLen)
+	}
+	buf := make([]byte, metaLen)
+	if _, err := r.ReadAt(buf, size-footerLen-metaLen); err != nil {
+		return nil, err
+	}
+
+	pr := &Reader{r: r}
+	tr := thriftReader{r: bytes.NewReader(buf), size: metaLen}
+	if err := tr.readFileMetaData(&pr.meta); err != nil {
+		return nil, errors.Wrap(err, "reading Parquet file metadata")
+	}
+	if err := pr.initColumns(); err != nil {
+		return nil, err
+	}
+	for i := range pr.meta.rowGroups {
+		if n := len(pr.meta.rowGroups[i].columns); n != len(pr.cols) {
+			return nil, errors.Errorf("row group %d has %d columns, expected %d", i, n, len(pr.cols))
+		}
+	}
+	return pr, nil
+}
+
+// initColumns computes the leaf columns of the schema, which is the depth-first
+// traversal of the tree of groups and columns.
+func (r *Reader) initColumns() error {
+	schema := r.meta.schema
+	if len(schema) == 0 {
+		return errors.New("Parquet file has no schema")
+	}
+	next := 1
+	var walk func(path []string, numChildren int32, maxDef int16, repeated bool) error
+	walk = func(path []string, numChildren int32, maxDef int16, repeated bool) error {
+		for i := int32(0); i < numChildren; i++ {
+			if next >= len(schema) {
+				return errors.New("Parquet file has an invalid schema")
+			}
+			e := &schema[next]
+			next++
+			def, rep := maxDef, repeated
+			switch e.repetition {
+			case Optional:
+				def++
+			case Repeated:
+				def, rep = def+1, true
+			}
+			p := append(path[:len(path):len(path)], e.name)
+			if !e.hasType {
+				if err := walk(p, e.numChildren, def, rep); err != nil {
+					return err
+				}
+				continue
+			}
+			r.cols = append(r.cols, Column{
+				Path:          p,
+				Type:          e.typ,
+				TypeLength:    e.typeLength,
+				Repetition:    e.repetition,
+				ConvertedType: e.convertedType,
+				LogicalType:   e.logicalType,
+				TimeUnit:      e.timeUnit,
+				Scale:         e.scale,
+				Precision:     e.precision,
+				maxDef:        def,
+				repeated:      rep,
+			})
+		}
+		return nil
+	}
+	if err := walk(nil, schema[0].numChildren, 0, false); err != nil {
+		return err
+	}
+	if next != len(schema) {
+		return errors.New("Parquet file has an invalid schema")
+	}
+	return nil
+}
+
+// Columns returns the leaf columns of the file, in the order of the schema.
+func (r *Reader) Columns() []Column {
+	return r.cols
+}
+
+// NumRows returns the number of rows of the file.
+func (r *Reader) NumRows() int64 {
+	return r.meta.numRows
+}
+
+// NumRowGroups returns the number of row groups of the file, which store the
+// values of consecutive rows.
+func (r *Reader) NumRowGroups() int {
+	return len(r.meta.rowGroups)
+}
+
+// RowGroupNumRows returns the number of rows of the given row group.
+func (r *Reader) RowGroupNumRows(rowGroup int) int64 {
+	return r.meta.rowGroups[rowGroup].numRows
+}
+
+// ColumnReader returns a reader of the values of the given column in the given
+// row group.
+func (r *Reader) ColumnReader(rowGroup, col int) (*ColumnReader, error) {
+	c := &r.cols[col]
+	if c.repeated {
+		return nil, errors.Errorf("reading repeated column %q is not supported", c.Path)
+	}
+	chunk := &r.meta.rowGroups[rowGroup].columns[col]
+	if chunk.filePath != "" {
+		return nil, errors.Errorf("column %q is stored in another file", c.Path)
+	}
+	meta := &chunk.meta
+	if meta.typ != c.Type {
+		return nil, errors.Errorf("column %q has values of type %s, expected %s", c.Path, meta.typ, c.Type)
+	}
+	offset := meta.dataPageOffset
+	if meta.dictionaryPageOffset > 0 && meta.dictionaryPageOffset < offset {
+		offset = meta.dictionaryPageOffset
+	}
+	if offset < 0 || meta.totalCompressedSize < 0 {
+		return nil, errors.Errorf("column %q has invalid offsets", c.Path)
+	}
+	sr := io.NewSectionReader(r.r, offset, meta.totalCompressedSize)
+	return &ColumnReader{
+		col:       c,
+		codec:     meta.codec,
+		chunk:     sr,
+		r:         bufio.NewReader(sr),
+		remaining: meta.numValues,
+		defWidth:  uint(bits.Len16(uint16(c.maxDef))),
+	}, nil
+}
+
+// ColumnReader reads the values of a column in a row group.
+type ColumnReader struct {
+	col   *Column
+	codec int32
+	// chunk is the column chunk, which is read through r.
+	chunk *io.SectionReader
+	r     *bufio.Reader
+	// remaining is the number of values, including NULLs, left to read in
+	// the column chunk and pageRemaining is the number left in the current
+	// page.
+	remaining     int64
+	pageRemaining int32
+	dict          []interface{}
+	defWidth      uint
+	defs          rleDecoder
+	values        valueDecoder
+}
+
+// Next returns the next value of the column, or nil if it is NULL. The values
+// have the Go type of the physical type of the column: bool, int32, int64,
+// float32, float64 or []byte. Next returns io.EOF after the last value.
+func (c *ColumnReader) Next() (interface{}, error) {
+	if c.remaining <= 0 {
+		return nil, io.EOF
+	}
+	for c.pageRemaining <= 0 {
+		if err := c.readPage(); err != nil {
+			return nil, errors.Wrapf(err, "reading column %q", c.col.Path)
+		}
+	}
+	c.remaining--
+	c.pageRemaining--
+	if c.col.maxDef > 0 {
+		def, err := c.defs.next()
+		if err != nil {
+			return nil, errors.Wrapf(err, "reading column %q", c.col.Path)
+		}
+		if def < uint64(c.col.maxDef) {
+			return nil, nil
+		}
+	}
+	v, err := c.values.next()
+	if err != nil {
+		return nil, errors.Wrapf(err, "reading column %q", c.col.Path)
+	}
+	return v, nil
+}
+
+// readPage reads the next page of the column chunk. Dictionary pages are
+// stored in the dictionary of the reader and pages of other types are skipped,
+// so it only leaves pageRemaining set once it reads a data page.
+func (c *ColumnReader) readPage() error {
+	var h pageHeader
+	tr := thriftReader{r: c.r, size: c.chunkRemaining()}
+	if err := tr.readPageHeader(&h); err != nil {
+		return errors.Wrap(err, "reading page header")
+	}
+	// Check the sizes of the page before allocating anything for it, so that
+	// a corrupt header can't exhaust memory.
+	if h.compressedSize < 0 || h.uncompressedSize < 0 {
+		return errors.New("invalid page size")
+	}
+	if n := c.chunkRemaining(); int64(h.compressedSize) > n {
+		return errors.Errorf("page of %d bytes overruns the column chunk, which has %d bytes left",
+			h.compressedSize, n)
+	}
+	if int64(h.uncompressedSize) > int64(h.compressedSize)*maxCompressionRatio(c.codec) {
+		return errors.Errorf("invalid uncompressed size %d of a page of %d bytes",
+			h.uncompressedSize, h.compressedSize)
+	}
+	data := make([]byte, h.compressedSize)
+	if _, err := io.ReadFull(c.r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return err
+	}
+
+	switch h.typ {
+	case pageDictionary:
+		if h.dictionaryPage == nil {
+			return errors.New("dictionary page without a header")
+		}
+		if enc := h.dictionaryPage.encoding; enc != encodingPlain && enc != encodingPlainDictionary {
+			return errors.Errorf("unsupported dictionary encoding %d", enc)
+		}
+		data, err := decompress(c.codec, data, h.uncompressedSize)
+		if err != nil {
+			return err
+		}
+		if n := h.dictionaryPage.numValues; n < 0 || int(n) > len(data)*8 {
+			return errors.Errorf("invalid number of dictionary values %d", n)
+		}
+		d := plainDecoder{typ: c.col.Type, typeLength: c.col.TypeLength, data: data}
+		c.dict = make([]interface{}, h.dictionaryPage.numValues)
+		for i := range c.dict {
+			if c.dict[i], err = d.next(); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case pageData:
+		if h.dataPage == nil {
+			return errors.New("data page without a header")
+		}
+		data, err := decompress(c.codec, data, h.uncompressedSize)
+		if err != nil {
+			return err
+		}
+		if c.col.maxDef > 0 {
+			// Definition levels are prefixed by their 4-byte length.
+			if enc := h.dataPage.defEncoding; enc != encodingRLE {
+				return errors.Errorf("unsupported definition level encoding %d", enc)
+			}
+			if len(data) < 4 {
+				return errors.New("ran out of definition levels")
+			}
+			n := binary.LittleEndian.Uint32(data)
+			if n > uint32(len(data)-4) {
+				return errors.New("ran out of definition levels")
+			}
+			c.defs = rleDecoder{data: data[4 : 4+n], bitWidth: c.defWidth}
+			data = data[4+n:]
+		}
+		if err := c.initValues(h.dataPage.encoding, data); err != nil {
+			return err
+		}
+		c.pageRemaining = h.dataPage.numValues
+		return nil
+
+	case pageDataV2:
+		h2 := h.dataPageV2
+		if h2 == nil {
+			return errors.New("data page without a header")
+		}
+		// Repetition and definition levels are not compressed and their lengths
+		// are in the header.
+		if h2.repLength < 0 || h2.defLength < 0 ||
+			int64(h2.repLength)+int64(h2.defLength) > int64(len(data)) {
+			return errors.New("invalid repetition or definition levels length")
+		}
+		defs := data[h2.repLength : h2.repLength+h2.defLength]
+		data = data[h2.repLength+h2.defLength:]
+		if h2.isCompressed {
+			var err error
+			size := h.uncompressedSize - h2.repLength - h2.defLength
+			if data, err = decompress(c.codec, data, size); err != nil {
+				return err
+			}
+		}
+		if c.col.maxDef > 0 {
+			c.defs = rleDecoder{data: defs, bitWidth: c.defWidth}
+		}
+		if err := c.initValues(h2.encoding, data); err != nil {
+			return err
+		}
+		c.pageRemaining = h2.numValues
+		return nil
+
+	default:
+		return nil
+	}
+}
+
+// chunkRemaining returns the number of bytes left to read in the column chunk.
+func (c *ColumnReader) chunkRemaining() int64 {
+	// Seeking a SectionReader relative to its current offset never fails.
+	pos, _ := c.chunk.Seek(0, io.SeekCurrent)
+	return c.chunk.Size() - pos + int64(c.r.Buffered())
+}
+
+// initValues prepares the decoding of the values of a data page with the
+// given encoding.
+func (c *ColumnReader) initValues(encoding int32, data []byte) error {
+	switch encoding {
+	case encodingPlain:
+		c.values = &plainDecoder{typ: c.col.Type, typeLength: c.col.TypeLength, data: data}
+	case encodingPlainDictionary, encodingRLEDictionary:
+		if c.dict == nil {
+			return errors.New("dictionary encoded page without a dictionary")
+		}
+		// The indices are prefixed by their bit width.
+		if len(data) < 1 || data[0] > 32 {
+			return errors.New("invalid dictionary index bit width")
+		}
+		c.values = &dictDecoder{
+			rleDecoder: rleDecoder{data: data[1:], bitWidth: uint(data[0])},
+			dict:       c.dict,
+		}
+	case encodingRLE:
+		if c.col.Type != Boolean {
+			return errors.Errorf("unsupported RLE encoding of %s values", c.col.Type)
+		}
+		// RLE encoded booleans are prefixed by their 4-byte length.
+		if len(data) < 4 {
+			return errors.New("ran out of RLE encoded data")
+		}
+		n := binary.LittleEndian.Uint32(data)
+		if n > uint32(len(data)-4) {
+			return errors.New("ran out of RLE encoded data")
+		}
+		c.values = &rleBoolDecoder{rleDecoder{data: data[4 : 4+n], bitWidth: 1}}
+	default:
+		return errors.Errorf("unsupported encoding %d", encoding)
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"github.com/cockroachdb/errors"
+)
+
+// The metadata of Parquet files is serialized with the Thrift compact
+// protocol. Only the parts of the protocol needed by the structs in
+// metadata.go are implemented here.
+
+// Thrift compact protocol type ids.
+const (
+	thriftStop   = 0
+	thriftTrue   = 1
+	thriftFalse  = 2
+	thriftByte   = 3
+	thriftI16    = 4
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftDouble = 7
+	thriftBinary = 8
+	thriftList   = 9
+	thriftSet    = 10
+	thriftMap    = 11
+	thriftStruct = 12
+)
+
+const (
+	// maxThriftDepth bounds the nesting of the structs that are decoded.
+	maxThriftDepth = 64
+	// maxThriftLen bounds the length of the binary values and the lists that
+	// are decoded, so that a corrupt length doesn't exhaust memory.
+	maxThriftLen = 1 << 30
+)
+
+// thriftReader decodes Thrift compact protocol structs.
+type thriftReader struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+	// size is the number of bytes that r can return. Every binary value and
+	// every element of a list takes at least a byte, so their lengths are
+	// checked against it before anything is allocated for them.
+	size  int64
+	depth int
+}
+
+func (r *thriftReader) readByte() (byte, error) {
+	b, err := r.r.ReadByte()
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return b, err
+}
+
+func (r *thriftReader) readUvarint() (uint64, error) {
+	v, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func (r *thriftReader) readVarint() (int64, error) {
+	v, err := binary.ReadVarint(r.r)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return v, err
+}
+
+func (r *thriftReader) readI32() (int32, error) {
+	v, err := r.readVarint()
+	return int32(v), err
+}
+
+func (r *thriftReader) readI64() (int64, error) {
+	return r.readVarint()
+}
+
+func (r *thriftReader) readBinary() ([]byte, error) {
+	n, err := r.readUvarint()
+	if err != nil {
+		return nil, err
+	}
+	if n > maxThriftLen || n > uint64(r.size) {
+		return nil, errors.Errorf("invalid binary length %d", n)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r.r, b); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
+func (r *thriftReader) readString() (string, error) {
+	b, err := r.readBinary()
+	return string(b), err
+}
+
+// readListHeader returns the type and the number of the elements of a list or
+// set.
+func (r *thriftReader) readListHeader() (byte, int, error) {
+	b, err := r.readByte()
+	if err != nil {
+		return 0, 0, err
+	}
+	n := uint64(b >> 4)
+	if n == 15 {
+		if n, err = r.readUvarint(); err != nil {
+			return 0, 0, err
+		}
+	}
+	if n > maxThriftLen || n > uint64(r.size) {
+		return 0, 0, errors.Errorf("invalid list length %d", n)
+	}
+	return b & 0x0f, int(n), nil
+}
+
+// readStruct calls fn with the id and type of every field of a struct, which
+// must read the value of the field or skip it.
+func (r *thriftReader) readStruct(fn func(id int16, typ byte) error) error {
+	if r.depth++; r.depth > maxThriftDepth {
+		return errors.New("thrift struct nested too deeply")
+	}
+	defer func() { r.depth-- }()
+	var lastID int16
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		typ := b & 0x0f
+		if typ == thriftStop {
+			return nil
+		}
+		id := lastID + int16(b>>4)
+		if b>>4 == 0 {
+			v, err := r.readVarint()
+			if err != nil {
+				return err
+			}
+			id = int16(v)
+		}
+		lastID = id
+		if err := fn(id, typ); err != nil {
+			return err
+		}
+	}
+}
+
+// readBool returns the value of a boolean field, which is part of its type.
+func readBool(typ byte) bool {
+	return typ == thriftTrue
+}
+
+// skip skips over a value of the given type.
+func (r *thriftReader) skip(typ byte) error {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		return nil
+	case thriftByte:
+		_, err := r.readByte()
+		return err
+	case thriftI16, thriftI32, thriftI64:
+		_, err := r.readVarint()
+		return err
+	case thriftDouble:
+		var b [8]byte
+		_, err := io.ReadFull(r.r, b[:])
+		return err
+	case thriftBinary:
+		_, err := r.readBinary()
+		return err
+	case thriftList, thriftSet:
+		elemTyp, n, err := r.readListHeader()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < n; i++ {
+			if err := r.skipElem(elemTyp); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftMap:
+		n, err := r.readUvarint()
+		if err != nil || n == 0 {
+			return err
+		}
+		types, err := r.readByte()
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < n; i++ {
+			if err := r.skipElem(types >> 4); err != nil {
+				return err
+			}
+			if err := r.skipElem(types & 0x0f); err != nil {
+				return err
+			}
+		}
+		return nil
+	case thriftStruct:
+		return r.readStruct(func(_ int16, typ byte) error { return r.skip(typ) })
+	default:
+		return errors.Errorf("unknown thrift type %d", typ)
+	}
+}
+
+// skipElem skips over an element of a collection. Unlike fields, boolean
+// elements take up a byte.
+func (r *thriftReader) skipElem(typ byte) error {
+	if typ == thriftTrue || typ == thriftFalse {
+		_, err := r.readByte()
+		return err
+	}
+	return r.skip(typ)
+}
+
+// thriftWriter encodes Thrift compact protocol structs.
+type thriftWriter struct {
+	buf     bytes.Buffer
+	scratch [binary.MaxVarintLen64]byte
+	// lastIDs is the id of the last field written in every struct being
+	// written, innermost last.
+	lastIDs []int16
+}
+
+func (w *thriftWriter) writeUvarint(v uint64) {
+	n := binary.PutUvarint(w.scratch[:], v)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *thriftWriter) writeVarint(v int64) {
+	n := binary.PutVarint(w.scratch[:], v)
+	w.buf.Write(w.scratch[:n])
+}
+
+func (w *thriftWriter) structBegin() {
+	w.lastIDs = append(w.lastIDs, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf.WriteByte(thriftStop)
+	w.lastIDs = w.lastIDs[:len(w.lastIDs)-1]
+}
+
+func (w *thriftWriter) fieldBegin(id int16, typ byte) {
+	last := &w.lastIDs[len(w.lastIDs)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta<<4) | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.writeVarint(int64(id))
+	}
+	*last = id
+}
+
+func (w *thriftWriter) writeI32Field(id int16, v int32) {
+	w.fieldBegin(id, thriftI32)
+	w.writeVarint(int64(v))
+}
+
+func (w *thriftWriter) writeI64Field(id int16, v int64) {
+	w.fieldBegin(id, thriftI64)
+	w.writeVarint(v)
+}
+
+func (w *thriftWriter) writeBinary(b []byte) {
+	w.writeUvarint(uint64(len(b)))
+	w.buf.Write(b)
+}
+
+func (w *thriftWriter) writeStringField(id int16, s string) {
+	w.fieldBegin(id, thriftBinary)
+	w.writeUvarint(uint64(len(s)))
+	w.buf.WriteString(s)
+}
+
+func (w *thriftWriter) writeListHeader(elemTyp byte, n int) {
+	if n < 15 {
+		w.buf.WriteByte(byte(n<<4) | elemTyp)
+		return
+	}
+	w.buf.WriteByte(0xf0 | elemTyp)
+	w.writeUvarint(uint64(n))
+}
@@ -0,0 +1,211 @@
+// Copyright 2019 The Cockroach Authors.
+//
+// Use of this software is governed by the Business Source License
+// included in the file licenses/BSL.txt.
+//
+// As of the Change Date specified in that file, in accordance with
+// the Business Source License, use of this software will be governed
+// by the Apache License, Version 2.0, included in the file
+// licenses/APL.txt.
+
+package parquet
+
+import (
+	"encoding/binary"
+	"io"
+
+	"github.com/cockroachdb/errors"
+	"github.com/golang/snappy"
+)
+
+// rowGroupSize is the size of the encoded values that a Writer buffers before
+// it writes them as a row group.
+const rowGroupSize = 64 << 20
+
+// createdBy is the name of the application that wrote a file, which is stored
+// in its metadata.
+const createdBy = "cockroach"
+
+// Writer writes rows into a Parquet file. Every column chunk is written as a
+// single snappy-compressed data page of PLAIN encoded values.
+type Writer struct {
+	w      io.Writer
+	offset int64
+	cols   []columnWriter
+	meta   fileMetaData
+	// numRows and size are the number of rows and the size of the encoded
+	// values buffered for the current row group.
+	numRows int64
+	size    int
+	thrift  thriftWriter
+}
+
+// columnWriter buffers the values of a column for the current row group.
+type columnWriter struct {
+	col    *Column
+	defs   []uint64
+	values plainEncoder
+}
+
+// NewWriter returns a writer of a Parquet file with the given columns, which
+// can't be nested or repeated. Only the name, type, repetition, converted type
+// and, for decimals, the scale and precision of the columns are used.
+func NewWriter(w io.Writer, cols []Column) (*Writer, error) {
+	cols = append([]Column(nil), cols...)
+	pw := &Writer{w: w, cols: make([]columnWriter, len(cols))}
+	pw.meta = fileMetaData{
+		version:   1,
+		schema:    make([]schemaElement, 0, len(cols)+1),
+		createdBy: createdBy,
+	}
+	pw.meta.schema = append(pw.meta.schema, schemaElement{
+		name:          "schema",
+		numChildren:   int32(len(cols)),
+		convertedType: NoConvertedType,
+	})
+	for i := range cols {
+		c := &cols[i]
+		if len(c.Path) != 1 {
+			return nil, errors.Errorf("column %q: nested columns are not supported", c.Path)
+		}
+		if c.Repetition == Repeated {
+			return nil, errors.Errorf("column %q: repeated columns are not supported", c.Path)
+		}
+		if c.Repetition == Optional {
+			c.maxDef = 1
+		}
+		pw.cols[i].col = c
+		pw.meta.schema = append(pw.meta.schema, schemaElement{
+			typ:           c.Type,
+			hasType:       true,
+			typeLength:    c.TypeLength,
+			repetition:    c.Repetition,
+			name:          c.Path[0],
+			convertedType: c.ConvertedType,
+			scale:         c.Scale,
+			precision:     c.Precision,
+		})
+	}
+	if _, err := io.WriteString(w, magic); err != nil {
+		return nil, err
+	}
+	pw.offset = int64(len(magic))
+	return pw, nil
+}
+
+// AddRow adds a row, which has a value for every column. The values must have
+// the Go type of the physical type of their column, as returned by
+// ColumnReader.Next, or be nil for NULL. The Writer can't be used after an
+// error.
+func (w *Writer) AddRow(row []interface{}) error {
+	if len(row) != len(w.cols) {
+		return errors.Errorf("expected %d values, got %d", len(w.cols), len(row))
+	}
+	for i, v := range row {
+		c := &w.cols[i]
+		if v == nil {
+			if c.col.maxDef == 0 {
+				return errors.Errorf("column %q: NULL value in a required column", c.col.Path)
+			}
+			c.defs = append(c.defs, 0)
+			continue
+		}
+		if c.col.maxDef > 0 {
+			c.defs = append(c.defs, 1)
+		}
+		before := c.values.buf.Len()
+		if err := c.values.encode(c.col.Type, c.col.TypeLength, v); err != nil {
+			return errors.Wrapf(err, "column %q", c.col.Path)
+		}
+		w.size += c.values.buf.Len() - before
+	}
+	w.numRows++
+	if w.size >= rowGroupSize {
+		return w.flushRowGroup()
+	}
+	return nil
+}
+
+// flushRowGroup writes the buffered values as a row group.
+func (w *Writer) flushRowGroup() error {
+	if w.numRows == 0 {
+		return nil
+	}
+	rg := rowGroup{numRows: w.numRows, columns: make([]columnChunk, len(w.cols))}
+	var page []byte
+	for i := range w.cols {
+		c := &w.cols[i]
+		page = page[:0]
+		if c.col.maxDef > 0 {
+			// Definition levels are prefixed by their 4-byte length.
+			page = append(page, 0, 0, 0, 0)
+			page = appendRLE(page, c.defs, 1 /* bitWidth */)
+			binary.LittleEndian.PutUint32(page, uint32(len(page)-4))
+		}
+		page = append(page, c.values.flush()...)
+		compressed := snappy.Encode(nil, page)
+
+		w.thrift.buf.Reset()
+		w.thrift.writePageHeader(&pageHeader{
+			typ:              pageData,
+			uncompressedSize: int32(len(page)),
+			compressedSize:   int32(len(compressed)),
+			dataPage: &dataPageHeader{
+				numValues:   int32(w.numRows),
+				encoding:    encodingPlain,
+				defEncoding: encodingRLE,
+				repEncoding: encodingRLE,
+			},
+		})
+		headerLen := int64(w.thrift.buf.Len())
+		rg.columns[i] = columnChunk{
+			fileOffset: w.offset,
+			meta: columnMetaData{
+				typ:                   c.col.Type,
+				encodings:             []int32{encodingPlain, encodingRLE},
+				pathInSchema:          c.col.Path,
+				codec:                 codecSnappy,
+				numValues:             w.numRows,
+				totalUncompressedSize: headerLen + int64(len(page)),
+				totalCompressedSize:   headerLen + int64(len(compressed)),
+				dataPageOffset:        w.offset,
+			},
+		}
+		if err := w.write(w.thrift.buf.Bytes()); err != nil {
+			return err
+		}
+		if err := w.write(compressed); err != nil {
+			return err
+		}
+		rg.totalByteSize += headerLen + int64(len(page))
+		c.defs = c.defs[:0]
+		c.values.reset()
+	}
+	w.meta.rowGroups = append(w.meta.rowGroups, rg)
+	w.meta.numRows += w.numRows
+	w.numRows, w.size = 0, 0
+	return nil
+}
+
+func (w *Writer) write(b []byte) error {
+	n, err := w.w.Write(b)
+	w.offset += int64(n)
+	return err
+}
+
+// Close writes the buffered rows and the metadata of the file. It doesn't
+// close the underlying writer.
+func (w *Writer) Close() error {
+	if err := w.flushRowGroup(); err != nil {
+		return err
+	}
+	w.thrift.buf.Reset()
+	w.thrift.writeFileMetaData(&w.meta)
+	var footer [footerLen]byte
+	binary.LittleEndian.PutUint32(footer[:4], uint32(w.thrift.buf.Len()))
+	copy(footer[4:], magic)
+	if err := w.write(w.thrift.buf.Bytes()); err != nil {
+		return err
+	}
+	return w.write(footer[:])
+}